@@ -0,0 +1,239 @@
+package main
+
+import (
+	// stdlib
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/kevinburke/go.uuid"
+	"go.opencensus.io/trace"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/services/frontend"
+	"github.com/basvanbeek/opencensus-gokit-example/services/frontend/bulk"
+)
+
+// defaultChunkSize stays well below the event service import limit.
+const defaultChunkSize = 500
+
+// exportPageSize sets the amount of events requested per export call.
+const exportPageSize = 500
+
+var errImportRows = errors.New("import has row errors")
+
+// runCommand executes one of our bulk sub commands.
+func runCommand(
+	ctx context.Context, client frontend.Service, tenantID uuid.UUID,
+	cmd string, args []string, logger log.Logger,
+) error {
+	switch cmd {
+	case "import":
+		return runImport(ctx, client, tenantID, args, logger)
+	case "export":
+		return runExport(ctx, client, tenantID, args)
+	default:
+		return fmt.Errorf("unknown command %q, expected import or export", cmd)
+	}
+}
+
+// runImport imports events from a CSV or JSON Lines file. Events are sent in
+// chunks, each imported in a single transaction. After a failing chunk the
+// import can be resumed from its first row.
+func runImport(
+	ctx context.Context, client frontend.Service, tenantID uuid.UUID,
+	args []string, logger log.Logger,
+) error {
+	var (
+		fs     = flag.NewFlagSet("import", flag.ExitOnError)
+		format = fs.String("format", "", "payload format: csv or jsonl (default: by file extension)")
+		dryRun = fs.Bool("dry-run", false, "only validate the events")
+		chunk  = fs.Int("chunk", defaultChunkSize, "events per transaction, 0 imports all events at once")
+		resume = fs.Int("resume", 1, "row to resume the import from")
+	)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: cli import [flags] <file>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("missing import file")
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if *format == "" {
+		*format = formatFromExtension(fs.Arg(0))
+	}
+	bulkFormat, err := bulk.ParseFormat(*format)
+	if err != nil {
+		return err
+	}
+	events, err := bulk.ReadEvents(f, bulkFormat)
+	if err != nil {
+		return err
+	}
+	if *resume < 1 || *resume > len(events)+1 {
+		return fmt.Errorf("resume row must be between 1 and %d", len(events)+1)
+	}
+	if *chunk <= 0 {
+		*chunk = len(events)
+	}
+
+	// each chunk is validated in its own transaction, so duplicates across
+	// chunks need to be found before importing any of them
+	var (
+		created, failed int
+		duplicates      = make(map[int]bool)
+	)
+	if dups := bulk.Duplicates(events[*resume-1:], *resume); len(dups) > 0 {
+		for _, rowErr := range dups {
+			fmt.Fprintf(os.Stderr, "row %d: %s\n", rowErr.Row, rowErr.Error)
+			duplicates[rowErr.Row] = true
+		}
+		failed += len(dups)
+
+		if !*dryRun {
+			level.Info(logger).Log(
+				"msg", "import aborted", "errors", failed, "resume", *resume,
+			)
+			return errImportRows
+		}
+	}
+
+	for start := *resume - 1; start < len(events); start += *chunk {
+		end := start + *chunk
+		if end > len(events) {
+			end = len(events)
+		}
+
+		ctx, span := trace.StartSpan(ctx, "Do EventImport")
+		span.AddAttributes(
+			trace.Int64Attribute("import.row", int64(start+1)),
+			trace.Int64Attribute("import.rows", int64(end-start)),
+			trace.BoolAttribute("import.dry_run", *dryRun),
+		)
+		result, err := client.EventImport(ctx, tenantID, events[start:end], *dryRun)
+		if err != nil {
+			span.SetStatus(trace.Status{
+				Code:    trace.StatusCodeUnknown,
+				Message: err.Error(),
+			})
+			span.End()
+			return fmt.Errorf("rows %d-%d: %v (resume with -resume %d)", start+1, end, err, start+1)
+		}
+		span.SetStatus(trace.Status{Code: trace.StatusCodeOK})
+		span.End()
+
+		// row numbers are relative to the chunk, duplicates within the chunk
+		// were reported already
+		for _, rowErr := range result.Errors {
+			if duplicates[start+rowErr.Row] {
+				continue
+			}
+			fmt.Fprintf(os.Stderr, "row %d: %s\n", start+rowErr.Row, rowErr.Error)
+			failed++
+		}
+		created += len(result.Created)
+
+		if len(result.Errors) > 0 && !*dryRun {
+			level.Info(logger).Log(
+				"msg", "import halted", "created", created, "resume", start+1,
+			)
+			return errImportRows
+		}
+	}
+
+	level.Info(logger).Log(
+		"msg", "import done", "rows", len(events)-(*resume-1), "created", created,
+		"errors", failed, "dry_run", *dryRun,
+	)
+	if failed > 0 {
+		return errImportRows
+	}
+	return nil
+}
+
+// runExport writes all events of the tenant to stdout or the provided file.
+// Events are requested a page at a time and written as they arrive.
+func runExport(
+	ctx context.Context, client frontend.Service, tenantID uuid.UUID,
+	args []string,
+) error {
+	var (
+		fs     = flag.NewFlagSet("export", flag.ExitOnError)
+		format = fs.String("format", "", "payload format: csv or jsonl (default: by file extension)")
+		out    = fs.String("o", "", "output file (default: stdout)")
+	)
+	fs.Parse(args)
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+		if *format == "" {
+			*format = formatFromExtension(*out)
+		}
+	}
+	bulkFormat, err := bulk.ParseFormat(*format)
+	if err != nil {
+		return err
+	}
+	writer, err := bulk.NewWriter(w, bulkFormat)
+	if err != nil {
+		return err
+	}
+
+	ctx, span := trace.StartSpan(ctx, "Do EventExport")
+	defer span.End()
+
+	var (
+		after  uuid.UUID
+		events []*frontend.Event
+	)
+	for {
+		if events, err = client.EventExport(ctx, tenantID, after, exportPageSize); err != nil {
+			span.SetStatus(trace.Status{
+				Code:    trace.StatusCodeUnknown,
+				Message: err.Error(),
+			})
+			return err
+		}
+		for _, evt := range events {
+			if err = writer.Write(evt); err != nil {
+				return err
+			}
+		}
+		if len(events) < exportPageSize {
+			break
+		}
+		after = events[len(events)-1].ID
+	}
+	span.SetStatus(trace.Status{Code: trace.StatusCodeOK})
+
+	return writer.Flush()
+}
+
+func formatFromExtension(filename string) string {
+	if strings.EqualFold(filepath.Ext(filename), ".csv") {
+		return string(bulk.CSV)
+	}
+	return ""
+}
@@ -3,6 +3,7 @@ package main
 import (
 	// stdlib
 	"context"
+	"flag"
 	"fmt"
 	"os"
 
@@ -11,14 +12,16 @@ import (
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 	"github.com/go-kit/kit/sd"
-	"github.com/go-kit/kit/sd/etcd"
 	"github.com/kevinburke/go.uuid"
 	"go.opencensus.io/trace"
 
 	// project
 	feclient "github.com/basvanbeek/opencensus-gokit-example/clients/frontend"
 	"github.com/basvanbeek/opencensus-gokit-example/services/frontend"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/factory"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/mtls"
 	"github.com/basvanbeek/opencensus-gokit-example/shared/oc"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/registry"
 )
 
 const (
@@ -26,6 +29,12 @@ const (
 )
 
 func main() {
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: cli [import|export] [flags]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
 	var (
 		err      error
 		instance = uuid.NewV4()
@@ -51,10 +60,11 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	var sdc etcd.Client
+	// Create our service registry for Service Discovery, configured using
+	// OCG_REGISTRY and defaulting to etcd on localhost
+	var reg registry.Registry
 	{
-		// create our Go kit etcd client
-		sdc, err = etcd.NewClient(ctx, []string{"http://localhost:2379"}, etcd.ClientOptions{})
+		reg, err = registry.New(ctx, os.Getenv("OCG_REGISTRY"), logger)
 		if err != nil {
 			level.Error(logger).Log("exit", err)
 			os.Exit(-1)
@@ -64,12 +74,28 @@ func main() {
 	var client frontend.Service
 	{
 		var instancer sd.Instancer
-		instancer, err = etcd.NewInstancer(sdc, "/services/"+frontend.ServiceName+"/http", logger)
+		instancer, err = reg.Instancer(frontend.ServiceName, "http")
 		if err != nil {
 			level.Error(logger).Log("exit", err)
 		}
 
-		client = feclient.NewHTTPClient(instancer, logger)
+		// route our requests and call the frontend using mutual TLS if
+		// configured, see the README for their configuration
+		routing, err := registry.Routing()
+		if err != nil {
+			level.Error(logger).Log("exit", err)
+			os.Exit(-1)
+		}
+		tlsSource, err := mtls.FromEnv(logger)
+		if err != nil {
+			level.Error(logger).Log("exit", err)
+			os.Exit(-1)
+		}
+		defer tlsSource.Close()
+		client = feclient.NewHTTPClient(
+			instancer, logger,
+			factory.WithRouting(routing...), factory.WithMTLS(tlsSource),
+		)
 	}
 
 	var tenantID uuid.UUID
@@ -91,6 +117,15 @@ func main() {
 		tenantID = details.TenantID
 	}
 
+	// run the requested bulk command instead of our demo calls
+	if cmd := flag.Arg(0); cmd != "" {
+		if err = runCommand(ctx, client, tenantID, cmd, flag.Args()[1:], logger); err != nil {
+			level.Error(logger).Log("cmd", cmd, "exit", err)
+			os.Exit(-1)
+		}
+		return
+	}
+
 	{
 		if err != nil {
 			level.Error(logger).Log("exit", err)
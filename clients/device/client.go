@@ -6,7 +6,7 @@ import (
 
 	// external
 	"github.com/go-kit/kit/log"
-	"github.com/go-kit/kit/sd"
+	kitsd "github.com/go-kit/kit/sd"
 	"github.com/kevinburke/go.uuid"
 
 	// project
@@ -14,25 +14,41 @@ import (
 	"github.com/basvanbeek/opencensus-gokit-example/clients/device/http"
 	"github.com/basvanbeek/opencensus-gokit-example/services/device"
 	"github.com/basvanbeek/opencensus-gokit-example/services/device/transport"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/factory"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/sd"
 )
 
 // NewHTTPClient returns a new device client using the HTTP transport.
-func NewHTTPClient(instancer sd.Instancer, logger log.Logger) device.Service {
+func NewHTTPClient(
+	instancer kitsd.Instancer, logger log.Logger, opts ...factory.Option,
+) device.Service {
 	return &client{
-		endpoints: http.InitEndpoints(instancer, logger),
+		endpoints: http.InitEndpoints(instancer, logger, opts...),
 		logger:    logger,
 	}
 }
 
 // NewGRPCClient returns a new device client using the gRPC transport
-func NewGRPCClient(instancer sd.Instancer, logger log.Logger) device.Service {
+func NewGRPCClient(
+	instancer kitsd.Instancer, logger log.Logger, opts ...factory.Option,
+) device.Service {
 	return &client{
-		endpoints: grpc.InitEndpoints(instancer, logger),
+		endpoints: grpc.InitEndpoints(instancer, logger, opts...),
 		logger:    logger,
 	}
 
 }
 
+// TenantKey is a factory.KeyFunc keying requests by their tenant. Requests not
+// holding their tenant, e.g. Unlock, are keyed by the key the caller stored
+// with sd.NewKeyContext, if any.
+func TenantKey(ctx context.Context, request interface{}) string {
+	if req, ok := request.(transport.CloneDevicesRequest); ok {
+		return req.TenantID.String()
+	}
+	return sd.KeyFromContext(ctx)
+}
+
 type client struct {
 	endpoints transport.Endpoints
 	logger    log.Logger
@@ -52,11 +68,55 @@ func (c client) Unlock(ctx context.Context, eventID, deviceID uuid.UUID, code st
 	response := res.(transport.UnlockResponse)
 	if response.Err != nil {
 		// business logic error
-		return nil, err
+		return nil, response.Err
 	}
 
 	return &device.Session{
+		TenantID:      response.TenantID,
 		EventCaption:  response.EventCaption,
 		DeviceCaption: response.DeviceCaption,
 	}, nil
 }
+
+func (c client) CloneDevices(
+	ctx context.Context, tenantID, fromEventID, toEventID uuid.UUID,
+) (int, error) {
+	res, err := c.endpoints.CloneDevices(ctx, transport.CloneDevicesRequest{
+		TenantID:    tenantID,
+		FromEventID: fromEventID,
+		ToEventID:   toEventID,
+	})
+	if err != nil {
+		// transport logic / unknown error
+		return 0, err
+	}
+
+	response := res.(transport.CloneDevicesResponse)
+	if response.Err != nil {
+		// business logic error
+		return 0, response.Err
+	}
+
+	return response.Count, nil
+}
+
+func (c client) DeviceTenant(
+	ctx context.Context, eventID, deviceID uuid.UUID,
+) (uuid.UUID, error) {
+	res, err := c.endpoints.DeviceTenant(ctx, transport.DeviceTenantRequest{
+		EventID:  eventID,
+		DeviceID: deviceID,
+	})
+	if err != nil {
+		// transport logic / unknown error
+		return uuid.Nil, err
+	}
+
+	response := res.(transport.DeviceTenantResponse)
+	if response.Err != nil {
+		// business logic error
+		return uuid.Nil, response.Err
+	}
+
+	return response.TenantID, nil
+}
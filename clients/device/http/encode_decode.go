@@ -4,8 +4,6 @@ import (
 	// stdlib
 	"context"
 	"encoding/json"
-	"errors"
-	"io/ioutil"
 	"net/http"
 
 	// external
@@ -13,9 +11,8 @@ import (
 	"github.com/gorilla/mux"
 
 	// project
-
-	"github.com/basvanbeek/opencensus-gokit-example/services/device"
 	"github.com/basvanbeek/opencensus-gokit-example/services/device/transport"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/problem"
 )
 
 func encodeUnlockRequest(route *mux.Route) kithttp.EncodeRequestFunc {
@@ -43,28 +40,52 @@ func encodeUnlockRequest(route *mux.Route) kithttp.EncodeRequestFunc {
 func decodeUnlockResponse(_ context.Context, response *http.Response) (interface{}, error) {
 	var res transport.UnlockResponse
 	if response.StatusCode != http.StatusOK {
-		b, err := ioutil.ReadAll(response.Body)
-		if err != nil {
-			return nil, err
+		return nil, problem.DecodeError(response)
+	}
+	dec := json.NewDecoder(response.Body)
+	if err := dec.Decode(&res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func decodeCloneDevicesResponse(_ context.Context, response *http.Response) (interface{}, error) {
+	var res transport.CloneDevicesResponse
+	if response.StatusCode != http.StatusOK {
+		return nil, problem.DecodeError(response)
+	}
+	dec := json.NewDecoder(response.Body)
+	if err := dec.Decode(&res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func encodeDeviceTenantRequest(route *mux.Route) kithttp.EncodeRequestFunc {
+	return func(_ context.Context, r *http.Request, request interface{}) error {
+		var (
+			err error
+			req = request.(transport.DeviceTenantRequest)
+		)
+
+		if r.URL, err = route.Host(r.URL.Host).URL(
+			"event_id", req.EventID.String(),
+			"device_id", req.DeviceID.String(),
+		); err != nil {
+			return err
 		}
-		errStr := string(b)
-		switch errStr {
-		case device.ErrorRequireEventID:
-			res.Err = device.ErrRequireEventID
-		case device.ErrorRequireDeviceID:
-			res.Err = device.ErrRequireDeviceID
-		case device.ErrorRequireUnlockCode:
-			res.Err = device.ErrRequireUnlockCode
-		case device.ErrorRepository:
-			res.Err = device.ErrRepository
-		case device.ErrorEventNotFound:
-			res.Err = device.ErrEventNotFound
-		case device.ErrorUnlockNotFound:
-			res.Err = device.ErrUnlockNotFound
-		default:
-			return nil, errors.New(errStr)
+		if methods, err := route.GetMethods(); err == nil {
+			r.Method = methods[0]
 		}
-		return res, nil
+
+		return nil
+	}
+}
+
+func decodeDeviceTenantResponse(_ context.Context, response *http.Response) (interface{}, error) {
+	var res transport.DeviceTenantResponse
+	if response.StatusCode != http.StatusOK {
+		return nil, problem.DecodeError(response)
 	}
 	dec := json.NewDecoder(response.Body)
 	if err := dec.Decode(&res); err != nil {
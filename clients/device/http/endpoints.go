@@ -9,19 +9,23 @@ import (
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 	"github.com/go-kit/kit/ratelimit"
-	"github.com/go-kit/kit/sd"
+	kitsd "github.com/go-kit/kit/sd"
 	"github.com/gorilla/mux"
 	"golang.org/x/time/rate"
 
 	// project
+	"github.com/basvanbeek/opencensus-gokit-example/services/device"
 	"github.com/basvanbeek/opencensus-gokit-example/services/device/transport"
 	"github.com/basvanbeek/opencensus-gokit-example/services/device/transport/http/routes"
 	"github.com/basvanbeek/opencensus-gokit-example/shared/factory"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/health"
 	"github.com/basvanbeek/opencensus-gokit-example/shared/loggermw"
 )
 
 // InitEndpoints returns an initialized set of Go kit HTTP endpoints.
-func InitEndpoints(instancer sd.Instancer, logger log.Logger) transport.Endpoints {
+func InitEndpoints(
+	instancer kitsd.Instancer, logger log.Logger, opts ...factory.Option,
+) transport.Endpoints {
 	route := routes.Initialize(mux.NewRouter())
 
 	// configure client wide rate limiter for all instances and all method
@@ -36,6 +40,12 @@ func InitEndpoints(instancer sd.Instancer, logger log.Logger) transport.Endpoint
 	// chain our service wide middlewares
 	middlewares := endpoint.Chain(lmw, rl)
 
+	opts = append([]factory.Option{
+		factory.WithHealthCheck(
+			device.ServiceName+"/http", health.HTTPCheck(factory.HealthClient(opts...)),
+		),
+	}, opts...)
+
 	// create our client endpoints
 	return transport.Endpoints{
 		Unlock: factory.CreateHTTPEndpoint(
@@ -44,6 +54,23 @@ func InitEndpoints(instancer sd.Instancer, logger log.Logger) transport.Endpoint
 			"Unlock",
 			encodeUnlockRequest(route.Unlock),
 			decodeUnlockResponse,
+			opts...,
+		),
+		CloneDevices: factory.CreateHTTPEndpoint(
+			instancer,
+			middlewares,
+			"CloneDevices",
+			factory.EncodeGenericRequest(route.CloneDevices),
+			decodeCloneDevicesResponse,
+			opts...,
+		),
+		DeviceTenant: factory.CreateHTTPEndpoint(
+			instancer,
+			middlewares,
+			"DeviceTenant",
+			encodeDeviceTenantRequest(route.DeviceTenant),
+			decodeDeviceTenantResponse,
+			opts...,
 		),
 	}
 }
@@ -2,18 +2,35 @@ package event
 
 import (
 	// stdlib
+	"context"
 	"net/http"
 
 	// external
 	"github.com/go-kit/kit/log"
-	"github.com/go-kit/kit/sd"
+	kitsd "github.com/go-kit/kit/sd"
+	"github.com/kevinburke/go.uuid"
 
 	// project
 	"github.com/basvanbeek/opencensus-gokit-example/clients/event/twirp"
 	"github.com/basvanbeek/opencensus-gokit-example/services/event"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/factory"
 )
 
 // NewTwirp returns a new event client using the Twirp transport.
-func NewTwirp(instancer sd.Instancer, client *http.Client, logger log.Logger) event.Service {
-	return twirp.NewClient(instancer, client, logger)
+func NewTwirp(
+	instancer kitsd.Instancer, client *http.Client, logger log.Logger, opts ...factory.Option,
+) event.Service {
+	return twirp.NewClient(instancer, client, logger, opts...)
+}
+
+// TenantKey is a factory.KeyFunc keying requests by their tenant, so used with
+// factory.WithConsistentHash the requests of a tenant end up at the event
+// service instance caching its events.
+func TenantKey(_ context.Context, request interface{}) string {
+	if r, ok := request.(interface{ GetTenantId() []byte }); ok {
+		if tenantID := uuid.FromBytesOrNil(r.GetTenantId()); !uuid.Equal(tenantID, uuid.Nil) {
+			return tenantID.String()
+		}
+	}
+	return ""
 }
@@ -3,54 +3,40 @@ package twirp
 import (
 	// stdlib
 	"context"
-	"errors"
+	"time"
 
 	// external
 	"github.com/go-kit/kit/log"
 	"github.com/kevinburke/go.uuid"
-	"github.com/twitchtv/twirp"
 
 	// project
 	"github.com/basvanbeek/opencensus-gokit-example/services/event"
 	"github.com/basvanbeek/opencensus-gokit-example/services/event/transport/pb"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/errcode"
 	"github.com/basvanbeek/opencensus-gokit-example/shared/sd"
 )
 
 type client struct {
-	instancer func() pb.Event
+	instancer func(ctx context.Context, request interface{}) pb.Event
 	logger    log.Logger
 }
 
 func (c client) Create(
 	ctx context.Context, tenantID uuid.UUID, evt event.Event,
 ) (*uuid.UUID, error) {
-	ci := c.instancer()
+	req := &pb.CreateRequest{
+		TenantId: tenantID.Bytes(),
+		Event:    toPB(evt),
+	}
+	ci := c.instancer(ctx, req)
 	if ci == nil {
 		return nil, sd.ErrNoClients
 	}
 
-	res, err := ci.Create(ctx, &pb.CreateRequest{
-		TenantId: tenantID.Bytes(),
-		Event: &pb.EventObj{
-			Id:   evt.ID.Bytes(),
-			Name: evt.Name,
-		},
-	})
+	res, err := ci.Create(ctx, req)
 
 	if err != nil {
-		if twErr, ok := err.(twirp.Error); ok {
-			switch twErr.Msg() {
-			case event.ErrorService:
-				return nil, event.ErrService
-			case event.ErrorUnauthorized:
-				return nil, event.ErrUnauthorized
-			case event.ErrorEventExists:
-				return nil, event.ErrEventExists
-			default:
-				return nil, errors.New(twErr.Msg())
-			}
-		}
-		return nil, err
+		return nil, errcode.FromTwirp(err)
 	}
 
 	id, err := uuid.FromBytes(res.Id)
@@ -63,125 +49,327 @@ func (c client) Create(
 func (c client) Get(
 	ctx context.Context, tenantID, id uuid.UUID,
 ) (*event.Event, error) {
-	ci := c.instancer()
+	req := &pb.GetRequest{
+		TenantId: tenantID.Bytes(),
+		Id:       id.Bytes(),
+	}
+	ci := c.instancer(ctx, req)
 	if ci == nil {
 		return nil, sd.ErrNoClients
 	}
 
-	res, err := ci.Get(ctx, &pb.GetRequest{
-		TenantId: tenantID.Bytes(),
-		Id:       id.Bytes(),
-	})
+	res, err := ci.Get(ctx, req)
 
-	if twErr, ok := err.(twirp.Error); ok {
-		switch twErr.Error() {
-		case event.ErrorService:
-			return nil, event.ErrService
-		case event.ErrorUnauthorized:
-			return nil, event.ErrUnauthorized
-		case event.ErrorNotFound:
-			return nil, event.ErrNotFound
-		}
+	if err != nil {
+		return nil, errcode.FromTwirp(err)
 	}
 
-	return &event.Event{
-		ID:   uuid.FromBytesOrNil(res.Event.Id),
-		Name: res.Event.Name,
-	}, nil
+	return fromPB(res.Event), nil
 }
 
 func (c client) Update(
 	ctx context.Context, tenantID uuid.UUID, evt event.Event,
 ) error {
-	ci := c.instancer()
+	req := &pb.UpdateRequest{
+		TenantId: tenantID.Bytes(),
+		Event:    toPB(evt),
+	}
+	ci := c.instancer(ctx, req)
 	if ci == nil {
 		return sd.ErrNoClients
 	}
 
-	_, err := ci.Update(ctx, &pb.UpdateRequest{
-		TenantId: tenantID.Bytes(),
-		Event: &pb.EventObj{
-			Id:   evt.ID.Bytes(),
-			Name: evt.Name,
-		},
-	})
+	_, err := ci.Update(ctx, req)
 
-	if err != nil {
-		if twErr, ok := err.(twirp.Error); ok {
-			switch twErr.Error() {
-			case event.ErrorService:
-				return event.ErrService
-			case event.ErrorUnauthorized:
-				return event.ErrUnauthorized
-			case event.ErrorNotFound:
-				return event.ErrNotFound
-			case event.ErrorEventExists:
-				return event.ErrEventExists
-			}
-		}
-	}
-
-	return err
+	return errcode.FromTwirp(err)
 }
 
 func (c client) Delete(
 	ctx context.Context, tenantID uuid.UUID, id uuid.UUID,
 ) error {
-	ci := c.instancer()
+	req := &pb.DeleteRequest{
+		TenantId: tenantID.Bytes(),
+		Id:       id.Bytes(),
+	}
+	ci := c.instancer(ctx, req)
 	if ci == nil {
 		return sd.ErrNoClients
 	}
 
-	_, err := ci.Delete(ctx, &pb.DeleteRequest{
+	_, err := ci.Delete(ctx, req)
+
+	return errcode.FromTwirp(err)
+}
+
+func (c client) List(
+	ctx context.Context, tenantID uuid.UUID,
+) ([]*event.Event, error) {
+	req := &pb.ListRequest{
+		TenantId: tenantID.Bytes(),
+	}
+	ci := c.instancer(ctx, req)
+	if ci == nil {
+		return nil, sd.ErrNoClients
+	}
+
+	pbListResponse, err := ci.List(ctx, req)
+
+	if err != nil {
+		return nil, errcode.FromTwirp(err)
+	}
+
+	events := make([]*event.Event, 0, len(pbListResponse.Events))
+	for _, evt := range pbListResponse.Events {
+		events = append(events, fromPB(evt))
+	}
+	return events, nil
+}
+
+func (c client) Import(
+	ctx context.Context, tenantID uuid.UUID, events []event.Event, dryRun bool,
+) (*event.ImportResult, error) {
+	req := &pb.ImportRequest{
+		TenantId: tenantID.Bytes(),
+		Events:   make([]*pb.EventObj, 0, len(events)),
+		DryRun:   dryRun,
+	}
+	for _, evt := range events {
+		req.Events = append(req.Events, toPB(evt))
+	}
+	ci := c.instancer(ctx, req)
+	if ci == nil {
+		return nil, sd.ErrNoClients
+	}
+
+	res, err := ci.Import(ctx, req)
+	if err != nil {
+		return nil, errcode.FromTwirp(err)
+	}
+
+	result := &event.ImportResult{
+		Rows:    int(res.Rows),
+		Created: make([]uuid.UUID, 0, len(res.Created)),
+		Errors:  make([]event.ImportError, 0, len(res.Errors)),
+	}
+	for _, id := range res.Created {
+		result.Created = append(result.Created, uuid.FromBytesOrNil(id))
+	}
+	for _, rowErr := range res.Errors {
+		result.Errors = append(result.Errors, event.ImportError{
+			Row: int(rowErr.Row),
+			Err: errcode.Decode(rowErr.Code, errcode.InvalidArgument, rowErr.Error),
+		})
+	}
+	return result, nil
+}
+
+func (c client) Export(
+	ctx context.Context, tenantID, after uuid.UUID, limit int,
+) ([]*event.Event, error) {
+	req := &pb.ExportRequest{
+		TenantId: tenantID.Bytes(),
+		After:    after.Bytes(),
+		Limit:    int32(limit),
+	}
+	ci := c.instancer(ctx, req)
+	if ci == nil {
+		return nil, sd.ErrNoClients
+	}
+
+	res, err := ci.Export(ctx, req)
+
+	if err != nil {
+		return nil, errcode.FromTwirp(err)
+	}
+
+	events := make([]*event.Event, 0, len(res.Events))
+	for _, evt := range res.Events {
+		events = append(events, fromPB(evt))
+	}
+	return events, nil
+}
+
+func (c client) Clone(
+	ctx context.Context, tenantID, id uuid.UUID, name string, start time.Time,
+) (*uuid.UUID, error) {
+	req := &pb.CloneRequest{
 		TenantId: tenantID.Bytes(),
 		Id:       id.Bytes(),
-	})
+		Name:     name,
+	}
+	if !start.IsZero() {
+		req.StartsAt = start.Unix()
+	}
+	ci := c.instancer(ctx, req)
+	if ci == nil {
+		return nil, sd.ErrNoClients
+	}
 
+	res, err := ci.Clone(ctx, req)
 	if err != nil {
-		if twErr, ok := err.(twirp.Error); ok {
-			switch twErr.Error() {
-			case event.ErrorService:
-				return event.ErrService
-			case event.ErrorUnauthorized:
-				return event.ErrUnauthorized
-			case event.ErrorNotFound:
-				return event.ErrNotFound
-			}
-		}
+		return nil, errcode.FromTwirp(err)
 	}
 
-	return err
+	cloneID, err := uuid.FromBytes(res.Id)
+	if err != nil {
+		return nil, err
+	}
+	return &cloneID, nil
 }
 
-func (c client) List(
+func (c client) CreateTemplate(
+	ctx context.Context, tenantID uuid.UUID, t event.Template,
+) (*uuid.UUID, error) {
+	req := &pb.CreateTemplateRequest{
+		TenantId: tenantID.Bytes(),
+		Template: templateToPB(t),
+	}
+	ci := c.instancer(ctx, req)
+	if ci == nil {
+		return nil, sd.ErrNoClients
+	}
+
+	res, err := ci.CreateTemplate(ctx, req)
+	if err != nil {
+		return nil, errcode.FromTwirp(err)
+	}
+
+	id, err := uuid.FromBytes(res.Id)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+func (c client) ListTemplates(
 	ctx context.Context, tenantID uuid.UUID,
-) ([]*event.Event, error) {
-	ci := c.instancer()
+) ([]*event.Template, error) {
+	req := &pb.ListTemplatesRequest{
+		TenantId: tenantID.Bytes(),
+	}
+	ci := c.instancer(ctx, req)
 	if ci == nil {
 		return nil, sd.ErrNoClients
 	}
 
-	pbListResponse, err := ci.List(ctx, &pb.ListRequest{
+	res, err := ci.ListTemplates(ctx, req)
+	if err != nil {
+		return nil, errcode.FromTwirp(err)
+	}
+
+	templates := make([]*event.Template, 0, len(res.Templates))
+	for _, t := range res.Templates {
+		templates = append(templates, templateFromPB(t))
+	}
+	return templates, nil
+}
+
+func (c client) DeleteTemplate(
+	ctx context.Context, tenantID, id uuid.UUID,
+) error {
+	req := &pb.DeleteTemplateRequest{
 		TenantId: tenantID.Bytes(),
-	})
+		Id:       id.Bytes(),
+	}
+	ci := c.instancer(ctx, req)
+	if ci == nil {
+		return sd.ErrNoClients
+	}
+
+	_, err := ci.DeleteTemplate(ctx, req)
+
+	return errcode.FromTwirp(err)
+}
 
+func (c client) Materialize(
+	ctx context.Context, tenantID, id uuid.UUID, until time.Time,
+) ([]*event.Event, error) {
+	req := &pb.MaterializeRequest{
+		TenantId: tenantID.Bytes(),
+		Id:       id.Bytes(),
+		Until:    until.Unix(),
+	}
+	ci := c.instancer(ctx, req)
+	if ci == nil {
+		return nil, sd.ErrNoClients
+	}
+
+	res, err := ci.Materialize(ctx, req)
 	if err != nil {
-		if twErr, ok := err.(twirp.Error); ok {
-			switch twErr.Error() {
-			case event.ErrorService:
-				return nil, event.ErrService
-			case event.ErrorUnauthorized:
-				return nil, event.ErrUnauthorized
-			}
-		}
+		return nil, errcode.FromTwirp(err)
 	}
 
-	events := make([]*event.Event, 0, len(pbListResponse.Events))
-	for _, evt := range pbListResponse.Events {
-		events = append(events, &event.Event{
-			ID:   uuid.FromBytesOrNil(evt.Id),
-			Name: evt.Name,
-		})
+	events := make([]*event.Event, 0, len(res.Events))
+	for _, evt := range res.Events {
+		events = append(events, fromPB(evt))
 	}
 	return events, nil
 }
+
+func toPB(evt event.Event) *pb.EventObj {
+	obj := &pb.EventObj{
+		Id:       evt.ID.Bytes(),
+		Name:     evt.Name,
+		Timezone: evt.Timezone,
+	}
+	if !evt.Start.IsZero() {
+		obj.StartsAt = evt.Start.Unix()
+	}
+	if !evt.End.IsZero() {
+		obj.EndsAt = evt.End.Unix()
+	}
+	return obj
+}
+
+func fromPB(obj *pb.EventObj) *event.Event {
+	evt := &event.Event{
+		ID:       uuid.FromBytesOrNil(obj.Id),
+		TenantID: uuid.FromBytesOrNil(obj.TenantId),
+		Name:     obj.Name,
+		Timezone: obj.Timezone,
+	}
+	if obj.StartsAt != 0 {
+		evt.Start = time.Unix(obj.StartsAt, 0).UTC()
+	}
+	if obj.EndsAt != 0 {
+		evt.End = time.Unix(obj.EndsAt, 0).UTC()
+	}
+	return evt
+}
+
+func templateToPB(t event.Template) *pb.TemplateObj {
+	obj := &pb.TemplateObj{
+		Id:       t.ID.Bytes(),
+		Name:     t.Name,
+		Rrule:    t.RRule,
+		Timezone: t.Timezone,
+	}
+	if !t.Start.IsZero() {
+		obj.StartsAt = t.Start.Unix()
+	}
+	if !t.End.IsZero() {
+		obj.EndsAt = t.End.Unix()
+	}
+	return obj
+}
+
+func templateFromPB(obj *pb.TemplateObj) *event.Template {
+	t := &event.Template{
+		ID:       uuid.FromBytesOrNil(obj.Id),
+		TenantID: uuid.FromBytesOrNil(obj.TenantId),
+		Name:     obj.Name,
+		RRule:    obj.Rrule,
+		Timezone: obj.Timezone,
+		Failure:  obj.Failure,
+	}
+	if obj.StartsAt != 0 {
+		t.Start = time.Unix(obj.StartsAt, 0).UTC()
+	}
+	if obj.EndsAt != 0 {
+		t.End = time.Unix(obj.EndsAt, 0).UTC()
+	}
+	if obj.MaterializedUntil != 0 {
+		t.MaterializedUntil = time.Unix(obj.MaterializedUntil, 0).UTC()
+	}
+	return t
+}
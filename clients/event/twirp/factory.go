@@ -2,6 +2,7 @@ package twirp
 
 import (
 	// stdlib
+	"context"
 	"io"
 	"net/http"
 
@@ -12,26 +13,49 @@ import (
 	// project
 	"github.com/basvanbeek/opencensus-gokit-example/services/event"
 	"github.com/basvanbeek/opencensus-gokit-example/services/event/transport/pb"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/factory"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/health"
 	"github.com/basvanbeek/opencensus-gokit-example/shared/sd"
 )
 
-// NewClient returns a new event client using the Twirp transport.
-func NewClient(instancer kitsd.Instancer, c *http.Client, logger log.Logger) event.Service {
+// NewClient returns a new event client using the Twirp transport. The routing
+// options are applied to the discovered instances, a version split is not
+// supported by our round robin balancer. WithConsistentHash replaces the round
+// robin balancer, its KeyFunc receives the Twirp request messages. With mutual
+// TLS, c must use the transport of the mtls.Source.
+func NewClient(
+	instancer kitsd.Instancer, c *http.Client, logger log.Logger, opts ...factory.Option,
+) event.Service {
 	return &client{
-		instancer: factory(instancer, c, logger),
+		instancer: newBalancer(instancer, c, logger, opts),
 		logger:    logger,
 	}
 }
 
-func factory(instancer kitsd.Instancer, client *http.Client, logger log.Logger) func() pb.Event {
+func newBalancer(
+	instancer kitsd.Instancer, client *http.Client, logger log.Logger, opts []factory.Option,
+) func(ctx context.Context, request interface{}) pb.Event {
 	factoryFunc := func(instance string) (interface{}, io.Closer, error) {
 		return pb.NewEventProtobufClient(instance, client), nil, nil
 	}
-	clientInstancer := sd.NewClientInstancer(instancer, factoryFunc, logger)
-	balancer := sd.NewRoundRobin(clientInstancer)
+	// health checks use an untraced client to keep them out of our traces
+	opts = append([]factory.Option{
+		factory.WithHealthCheck(
+			event.ServiceName+"/twirp", health.HTTPCheck(factory.HealthClient(opts...)),
+		),
+	}, opts...)
 
-	return func() pb.Event {
-		client, err := balancer.Client()
+	pick := factory.NewKeyedClient(instancer, factoryFunc, logger, opts...)
+	if pick == nil {
+		clientInstancer := factory.NewClientInstancer(instancer, factoryFunc, logger, opts...)
+		balancer := sd.NewRoundRobin(clientInstancer)
+		pick = func(context.Context, interface{}) (interface{}, error) {
+			return balancer.Client()
+		}
+	}
+
+	return func(ctx context.Context, request interface{}) pb.Event {
+		client, err := pick(ctx, request)
 		if err != nil {
 			logger.Log("err", err)
 			return nil
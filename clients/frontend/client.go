@@ -7,19 +7,22 @@ import (
 	// external
 
 	"github.com/go-kit/kit/log"
-	"github.com/go-kit/kit/sd"
+	kitsd "github.com/go-kit/kit/sd"
 	"github.com/kevinburke/go.uuid"
 
 	// project
 	"github.com/basvanbeek/opencensus-gokit-example/clients/frontend/http"
 	"github.com/basvanbeek/opencensus-gokit-example/services/frontend"
 	"github.com/basvanbeek/opencensus-gokit-example/services/frontend/transport"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/factory"
 )
 
 // NewHTTPClient returns a new frontend client using the HTTP transport.
-func NewHTTPClient(instancer sd.Instancer, logger log.Logger) frontend.Service {
+func NewHTTPClient(
+	instancer kitsd.Instancer, logger log.Logger, opts ...factory.Option,
+) frontend.Service {
 	return &client{
-		endpoints: http.InitEndpoints(instancer, logger),
+		endpoints: http.InitEndpoints(instancer, logger, opts...),
 		logger:    logger,
 	}
 }
@@ -129,6 +132,40 @@ func (c *client) EventList(ctx context.Context, tenantID uuid.UUID) ([]*frontend
 	return res.Events, nil
 }
 
+func (c *client) EventImport(ctx context.Context, tenantID uuid.UUID, events []frontend.Event, dryRun bool) (*frontend.ImportResult, error) {
+	response, err := c.endpoints.EventImport(
+		ctx,
+		transport.EventImportRequest{
+			TenantID: tenantID,
+			Events:   events,
+			DryRun:   dryRun,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	res := response.(transport.EventImportResponse)
+	return res.Result, nil
+}
+
+func (c *client) EventExport(ctx context.Context, tenantID, after uuid.UUID, limit int) ([]*frontend.Event, error) {
+	response, err := c.endpoints.EventExport(
+		ctx,
+		transport.EventExportRequest{
+			TenantID: tenantID,
+			After:    after,
+			Limit:    limit,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	res := response.(transport.EventExportResponse)
+	return res.Events, nil
+}
+
 func (c *client) UnlockDevice(ctx context.Context, eventID, deviceID uuid.UUID, unlockCode string) (*frontend.Session, error) {
 	response, err := c.endpoints.UnlockDevice(
 		ctx,
@@ -164,3 +201,142 @@ func (c *client) GenerateQR(ctx context.Context, eventID, deviceID uuid.UUID, un
 
 	return res.QR, nil
 }
+
+func (c *client) EventCalendarToken(ctx context.Context, tenantID uuid.UUID) (string, error) {
+	response, err := c.endpoints.EventCalendarToken(
+		ctx,
+		transport.EventCalendarTokenRequest{
+			TenantID: tenantID,
+		},
+	)
+	if err != nil {
+		return "", err
+	}
+
+	res := response.(transport.EventCalendarTokenResponse)
+	return res.Token, nil
+}
+
+func (c *client) EventCalendar(ctx context.Context, token string) ([]byte, error) {
+	response, err := c.endpoints.EventCalendar(
+		ctx,
+		transport.EventCalendarRequest{
+			Token: token,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	res := response.(transport.EventCalendarResponse)
+	return res.Calendar, nil
+}
+
+func (c *client) EventClone(ctx context.Context, tenantID, eventID uuid.UUID, clone frontend.Clone) (*uuid.UUID, error) {
+	response, err := c.endpoints.EventClone(
+		ctx,
+		transport.EventCloneRequest{
+			TenantID: tenantID,
+			EventID:  eventID,
+			Clone:    clone,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	res := response.(transport.EventCloneResponse)
+	return res.EventID, res.Err
+}
+
+func (c *client) EventTemplateCreate(ctx context.Context, tenantID uuid.UUID, template frontend.Template) (*uuid.UUID, error) {
+	response, err := c.endpoints.EventTemplateCreate(
+		ctx,
+		transport.EventTemplateCreateRequest{
+			TenantID: tenantID,
+			Template: template,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	res := response.(transport.EventTemplateCreateResponse)
+	return res.TemplateID, res.Err
+}
+
+func (c *client) EventTemplateList(ctx context.Context, tenantID uuid.UUID) ([]*frontend.Template, error) {
+	response, err := c.endpoints.EventTemplateList(
+		ctx,
+		transport.EventTemplateListRequest{
+			TenantID: tenantID,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	res := response.(transport.EventTemplateListResponse)
+	return res.Templates, res.Err
+}
+
+func (c *client) EventTemplateDelete(ctx context.Context, tenantID, templateID uuid.UUID) error {
+	response, err := c.endpoints.EventTemplateDelete(
+		ctx,
+		transport.EventTemplateDeleteRequest{
+			TenantID:   tenantID,
+			TemplateID: templateID,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return response.(transport.EventTemplateDeleteResponse).Failed()
+}
+
+func (c *client) WebhookCreate(ctx context.Context, tenantID uuid.UUID, webhook frontend.Webhook) (*uuid.UUID, error) {
+	response, err := c.endpoints.WebhookCreate(
+		ctx,
+		transport.WebhookCreateRequest{
+			TenantID: tenantID,
+			Webhook:  webhook,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	res := response.(transport.WebhookCreateResponse)
+	return res.WebhookID, nil
+}
+
+func (c *client) WebhookDelete(ctx context.Context, tenantID, webhookID uuid.UUID) error {
+	response, err := c.endpoints.WebhookDelete(
+		ctx,
+		transport.WebhookDeleteRequest{
+			TenantID:  tenantID,
+			WebhookID: webhookID,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return response.(transport.WebhookDeleteResponse).Failed()
+}
+
+func (c *client) WebhookList(ctx context.Context, tenantID uuid.UUID) ([]*frontend.Webhook, error) {
+	response, err := c.endpoints.WebhookList(
+		ctx,
+		transport.WebhookListRequest{
+			TenantID: tenantID,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	res := response.(transport.WebhookListResponse)
+	return res.Webhooks, nil
+}
@@ -5,179 +5,499 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"errors"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strconv"
 
-	// project
+	// external
+	kithttp "github.com/go-kit/kit/transport/http"
+	"github.com/gorilla/mux"
+	"github.com/kevinburke/go.uuid"
 
+	// project
 	"github.com/basvanbeek/opencensus-gokit-example/services/frontend"
+	"github.com/basvanbeek/opencensus-gokit-example/services/frontend/bulk"
 	"github.com/basvanbeek/opencensus-gokit-example/services/frontend/transport"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/problem"
 )
 
+// encodeRouteRequest encodes the outgoing Go kit payload to the HTTP payload,
+// including the route parameters returned by params.
+func encodeRouteRequest(
+	route *mux.Route, params func(request interface{}) []string,
+) kithttp.EncodeRequestFunc {
+	return func(_ context.Context, r *http.Request, request interface{}) error {
+		var err error
+
+		if r.URL, err = route.Host(r.URL.Host).URL(params(request)...); err != nil {
+			return err
+		}
+		if methods, err := route.GetMethods(); err == nil {
+			r.Method = methods[0]
+		}
+
+		var buf bytes.Buffer
+		if err = json.NewEncoder(&buf).Encode(request); err != nil {
+			return err
+		}
+		r.Body = ioutil.NopCloser(&buf)
+		return nil
+	}
+}
+
+// eventParams returns the route parameters of event requests.
+func eventParams(request interface{}) []string {
+	var eventID uuid.UUID
+	switch req := request.(type) {
+	case transport.EventGetRequest:
+		eventID = req.EventID
+	case transport.EventUpdateRequest:
+		eventID = req.Event.ID
+	case transport.EventDeleteRequest:
+		eventID = req.EventID
+	}
+	return []string{"event_id", eventID.String()}
+}
+
+// deviceParams returns the route parameters of device requests.
+func deviceParams(request interface{}) []string {
+	var (
+		eventID, deviceID uuid.UUID
+		code              string
+	)
+	switch req := request.(type) {
+	case transport.UnlockDeviceRequest:
+		eventID, deviceID, code = req.EventID, req.DeviceID, req.UnlockCode
+	case transport.GenerateQRRequest:
+		eventID, deviceID, code = req.EventID, req.DeviceID, req.UnlockCode
+	}
+	return []string{
+		"event_id", eventID.String(), "device_id", deviceID.String(), "code", code,
+	}
+}
+
 // decodeLoginResponse decodes the incoming HTTP payload to the Go kit payload
 func decodeLoginResponse(_ context.Context, r *http.Response) (interface{}, error) {
 	var resp transport.LoginResponse
 
-	switch r.StatusCode {
-	case http.StatusOK:
-		if err := json.NewDecoder(r.Body).Decode(&resp); err != nil {
-			return nil, err
-		}
-		return resp, nil
-	case http.StatusBadRequest:
-		body := decodeErrorResponse(r)
-		switch body {
-		case frontend.ErrorUserPassRequired:
-			resp.Err = frontend.ErrUserPassRequired
-		case frontend.ErrorUserPassUnknown:
-			resp.Err = frontend.ErrUserPassUnknown
-		default:
-			return nil, errors.New(body)
-		}
-		return resp, nil
-	default:
-		return nil, errors.New(decodeErrorResponse(r))
+	if r.StatusCode != http.StatusOK {
+		return nil, problem.DecodeError(r)
+	}
+	if err := json.NewDecoder(r.Body).Decode(&resp); err != nil {
+		return nil, err
 	}
+	return resp, nil
 }
 
 // decodeEventCreateResponse decodes the incoming HTTP payload to the Go kit payload
 func decodeEventCreateResponse(_ context.Context, r *http.Response) (interface{}, error) {
 	var resp transport.EventCreateResponse
 
-	switch r.StatusCode {
-	case http.StatusOK:
-		if err := json.NewDecoder(r.Body).Decode(&resp); err != nil {
-			return nil, err
-		}
-		return resp, nil
-	case http.StatusConflict:
-		body := decodeErrorResponse(r)
-		switch body {
-		case frontend.ErrorEventExists:
-			resp.Err = frontend.ErrEventExists
-		default:
-			return nil, errors.New(body)
-		}
-		return resp, nil
-	default:
-		return nil, errors.New(decodeErrorResponse(r))
+	if r.StatusCode != http.StatusOK {
+		return nil, problem.DecodeError(r)
 	}
+	if err := json.NewDecoder(r.Body).Decode(&resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
 }
 
 // decodeEventGetResponse decodes the incoming HTTP payload to the Go kit payload
 func decodeEventGetResponse(_ context.Context, r *http.Response) (interface{}, error) {
 	var resp transport.EventGetResponse
 
-	switch r.StatusCode {
-	case http.StatusOK:
-		if err := json.NewDecoder(r.Body).Decode(&resp); err != nil {
-			return nil, err
-		}
-		return resp, nil
-	default:
-		return nil, errors.New(decodeErrorResponse(r))
+	if r.StatusCode != http.StatusOK {
+		return nil, problem.DecodeError(r)
 	}
+	if err := json.NewDecoder(r.Body).Decode(&resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
 }
 
 // decodeEventUpdateResponse decodes the incoming HTTP payload to the Go kit payload
 func decodeEventUpdateResponse(_ context.Context, r *http.Response) (interface{}, error) {
 	var resp transport.EventUpdateResponse
 
-	switch r.StatusCode {
-	case http.StatusOK:
-		if err := json.NewDecoder(r.Body).Decode(&resp); err != nil {
-			return nil, err
-		}
-		return resp, nil
-	default:
-		return nil, errors.New(decodeErrorResponse(r))
+	if r.StatusCode != http.StatusOK {
+		return nil, problem.DecodeError(r)
 	}
+	if err := json.NewDecoder(r.Body).Decode(&resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
 }
 
 // decodeEventDeleteResponse decodes the incoming HTTP payload to the Go kit payload
 func decodeEventDeleteResponse(_ context.Context, r *http.Response) (interface{}, error) {
 	var resp transport.EventDeleteResponse
 
-	switch r.StatusCode {
-	case http.StatusOK:
-		if err := json.NewDecoder(r.Body).Decode(&resp); err != nil {
-			return nil, err
-		}
-		return resp, nil
-	default:
-		return nil, errors.New(decodeErrorResponse(r))
+	if r.StatusCode != http.StatusOK {
+		return nil, problem.DecodeError(r)
 	}
+	if err := json.NewDecoder(r.Body).Decode(&resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
 }
 
 // decodeEventListResponse decodes the incoming HTTP payload to the Go kit payload
 func decodeEventListResponse(_ context.Context, r *http.Response) (interface{}, error) {
 	var resp transport.EventListResponse
 
-	switch r.StatusCode {
-	case http.StatusOK:
-		if err := json.NewDecoder(r.Body).Decode(&resp); err != nil {
-			return nil, err
+	if r.StatusCode != http.StatusOK {
+		return nil, problem.DecodeError(r)
+	}
+	if err := json.NewDecoder(r.Body).Decode(&resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// encodeEventImportRequest encodes the outgoing Go kit payload to the HTTP
+// payload. Events are sent as JSON Lines, the other parameters are passed in
+// the query string.
+func encodeEventImportRequest(route *mux.Route) kithttp.EncodeRequestFunc {
+	return func(_ context.Context, r *http.Request, request interface{}) error {
+		var (
+			err error
+			req = request.(transport.EventImportRequest)
+		)
+
+		if r.URL, err = route.Host(r.URL.Host).URL(); err != nil {
+			return err
+		}
+		if methods, err := route.GetMethods(); err == nil {
+			r.Method = methods[0]
+		}
+		r.URL.RawQuery = url.Values{
+			"tenant_id": {req.TenantID.String()},
+			"dry_run":   {strconv.FormatBool(req.DryRun)},
+			"format":    {string(bulk.JSONL)},
+		}.Encode()
+
+		var buf bytes.Buffer
+		writer, err := bulk.NewWriter(&buf, bulk.JSONL)
+		if err != nil {
+			return err
+		}
+		for idx := range req.Events {
+			if err = writer.Write(&req.Events[idx]); err != nil {
+				return err
+			}
+		}
+		if err = writer.Flush(); err != nil {
+			return err
+		}
+		r.Header.Set("Content-Type", bulk.JSONL.ContentType())
+		r.ContentLength = int64(buf.Len())
+		r.Body = ioutil.NopCloser(&buf)
+		return nil
+	}
+}
+
+// decodeEventImportResponse decodes the incoming HTTP payload to the Go kit payload
+func decodeEventImportResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	var resp transport.EventImportResponse
+
+	if r.StatusCode != http.StatusOK {
+		return nil, problem.DecodeError(r)
+	}
+	if err := json.NewDecoder(r.Body).Decode(&resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// encodeEventExportRequest encodes the outgoing Go kit payload to the HTTP
+// query string. The requested page is exported as JSON Lines.
+func encodeEventExportRequest(route *mux.Route) kithttp.EncodeRequestFunc {
+	return func(_ context.Context, r *http.Request, request interface{}) error {
+		var (
+			err error
+			req = request.(transport.EventExportRequest)
+		)
+
+		if r.URL, err = route.Host(r.URL.Host).URL(); err != nil {
+			return err
+		}
+		if methods, err := route.GetMethods(); err == nil {
+			r.Method = methods[0]
 		}
-		return resp, nil
-	default:
-		return nil, errors.New(decodeErrorResponse(r))
+		r.URL.RawQuery = url.Values{
+			"tenant_id": {req.TenantID.String()},
+			"after":     {req.After.String()},
+			"limit":     {strconv.Itoa(req.Limit)},
+			"format":    {string(bulk.JSONL)},
+		}.Encode()
+		return nil
+	}
+}
+
+// decodeEventExportResponse decodes the incoming JSON Lines payload to the Go
+// kit payload
+func decodeEventExportResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	if r.StatusCode != http.StatusOK {
+		return nil, problem.DecodeError(r)
+	}
+	events, err := bulk.ReadEvents(r.Body, bulk.JSONL)
+	if err != nil {
+		return nil, err
+	}
+	resp := transport.EventExportResponse{
+		Events: make([]*frontend.Event, 0, len(events)),
 	}
+	for idx := range events {
+		resp.Events = append(resp.Events, &events[idx])
+	}
+	return resp, nil
 }
 
 // decodeUnlockDeviceResponse decodes the incoming HTTP payload to the Go kit payload
 func decodeUnlockDeviceResponse(_ context.Context, r *http.Response) (interface{}, error) {
 	var resp transport.UnlockDeviceResponse
 
-	switch r.StatusCode {
-	case http.StatusOK:
-		if err := json.NewDecoder(r.Body).Decode(&resp); err != nil {
-			return nil, err
-		}
-		return resp, nil
-	default:
-		return nil, errors.New(decodeErrorResponse(r))
+	if r.StatusCode != http.StatusOK {
+		return nil, problem.DecodeError(r)
 	}
+	if err := json.NewDecoder(r.Body).Decode(&resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
 }
 
 // decodeGenerateQRResponse decodes the incoming HTTP payload to the Go kit payload
 func decodeGenerateQRResponse(_ context.Context, r *http.Response) (interface{}, error) {
 	var resp transport.GenerateQRResponse
 
-	switch r.StatusCode {
-	case http.StatusOK:
-		if err := json.NewDecoder(r.Body).Decode(&resp); err != nil {
-			return nil, err
+	if r.StatusCode != http.StatusOK {
+		return nil, problem.DecodeError(r)
+	}
+	var err error
+	if resp.QR, err = ioutil.ReadAll(r.Body); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// decodeEventCalendarTokenResponse decodes the incoming HTTP payload to the Go kit payload
+func decodeEventCalendarTokenResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	var resp transport.EventCalendarTokenResponse
+
+	if r.StatusCode != http.StatusOK {
+		return nil, problem.DecodeError(r)
+	}
+	if err := json.NewDecoder(r.Body).Decode(&resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// encodeEventCalendarRequest encodes the outgoing Go kit payload to the HTTP
+// payload, passing the feed token as query parameter.
+func encodeEventCalendarRequest(route *mux.Route) kithttp.EncodeRequestFunc {
+	return func(_ context.Context, r *http.Request, request interface{}) error {
+		var (
+			err error
+			req = request.(transport.EventCalendarRequest)
+		)
+
+		if r.URL, err = route.Host(r.URL.Host).URL("token", req.Token); err != nil {
+			return err
+		}
+		if methods, err := route.GetMethods(); err == nil {
+			r.Method = methods[0]
+		}
+		return nil
+	}
+}
+
+// decodeEventCalendarResponse decodes the incoming HTTP payload to the Go kit payload
+func decodeEventCalendarResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	var resp transport.EventCalendarResponse
+
+	if r.StatusCode != http.StatusOK {
+		return nil, problem.DecodeError(r)
+	}
+	var err error
+	if resp.Calendar, err = ioutil.ReadAll(r.Body); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// encodeEventCloneRequest encodes the outgoing Go kit payload to the HTTP
+// payload, including the event id route parameter.
+func encodeEventCloneRequest(route *mux.Route) kithttp.EncodeRequestFunc {
+	return func(_ context.Context, r *http.Request, request interface{}) error {
+		var (
+			err error
+			req = request.(transport.EventCloneRequest)
+		)
+
+		if r.URL, err = route.Host(r.URL.Host).URL(
+			"event_id", req.EventID.String(),
+		); err != nil {
+			return err
 		}
-		return resp, nil
-	case http.StatusBadRequest:
-		body := decodeErrorResponse(r)
-		switch body {
-		case frontend.ErrorInvalidQRParams:
-			resp.Err = frontend.ErrInvalidQRParams
-		default:
-			return nil, errors.New(body)
+		if methods, err := route.GetMethods(); err == nil {
+			r.Method = methods[0]
 		}
-		return resp, nil
-	case http.StatusServiceUnavailable:
-		body := decodeErrorResponse(r)
-		switch body {
-		case frontend.ErrorQRGenerate:
-			return nil, frontend.ErrQRGenerate
-		default:
-			return nil, errors.New(body)
+
+		var buf bytes.Buffer
+		if err = json.NewEncoder(&buf).Encode(req); err != nil {
+			return err
 		}
+		r.Body = ioutil.NopCloser(&buf)
+		return nil
+	}
+}
+
+// decodeEventCloneResponse decodes the incoming HTTP payload to the Go kit payload
+func decodeEventCloneResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	var resp transport.EventCloneResponse
 
-	default:
-		return nil, errors.New(decodeErrorResponse(r))
+	if r.StatusCode != http.StatusOK {
+		return nil, problem.DecodeError(r)
 	}
+	if err := json.NewDecoder(r.Body).Decode(&resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
 }
 
-func decodeErrorResponse(r *http.Response) string {
-	b, err := ioutil.ReadAll(r.Body)
-	if err != nil {
-		return err.Error()
+// decodeEventTemplateCreateResponse decodes the incoming HTTP payload to the
+// Go kit payload
+func decodeEventTemplateCreateResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	var resp transport.EventTemplateCreateResponse
+
+	if r.StatusCode != http.StatusOK {
+		return nil, problem.DecodeError(r)
+	}
+	if err := json.NewDecoder(r.Body).Decode(&resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// decodeEventTemplateListResponse decodes the incoming HTTP payload to the Go
+// kit payload
+func decodeEventTemplateListResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	var resp transport.EventTemplateListResponse
+
+	if r.StatusCode != http.StatusOK {
+		return nil, problem.DecodeError(r)
+	}
+	if err := json.NewDecoder(r.Body).Decode(&resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// encodeEventTemplateDeleteRequest encodes the outgoing Go kit payload to the
+// HTTP payload, including the template id route parameter.
+func encodeEventTemplateDeleteRequest(route *mux.Route) kithttp.EncodeRequestFunc {
+	return func(_ context.Context, r *http.Request, request interface{}) error {
+		var (
+			err error
+			req = request.(transport.EventTemplateDeleteRequest)
+		)
+
+		if r.URL, err = route.Host(r.URL.Host).URL(
+			"template_id", req.TemplateID.String(),
+		); err != nil {
+			return err
+		}
+		if methods, err := route.GetMethods(); err == nil {
+			r.Method = methods[0]
+		}
+
+		var buf bytes.Buffer
+		if err = json.NewEncoder(&buf).Encode(req); err != nil {
+			return err
+		}
+		r.Body = ioutil.NopCloser(&buf)
+		return nil
+	}
+}
+
+// decodeEventTemplateDeleteResponse decodes the incoming HTTP payload to the
+// Go kit payload
+func decodeEventTemplateDeleteResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	var resp transport.EventTemplateDeleteResponse
+
+	if r.StatusCode != http.StatusOK {
+		return nil, problem.DecodeError(r)
+	}
+	if err := json.NewDecoder(r.Body).Decode(&resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// encodeWebhookDeleteRequest encodes the outgoing Go kit payload to the HTTP
+// payload, including the webhook id route parameter.
+func encodeWebhookDeleteRequest(route *mux.Route) kithttp.EncodeRequestFunc {
+	return func(_ context.Context, r *http.Request, request interface{}) error {
+		var (
+			err error
+			req = request.(transport.WebhookDeleteRequest)
+		)
+
+		if r.URL, err = route.Host(r.URL.Host).URL(
+			"webhook_id", req.WebhookID.String(),
+		); err != nil {
+			return err
+		}
+		if methods, err := route.GetMethods(); err == nil {
+			r.Method = methods[0]
+		}
+
+		var buf bytes.Buffer
+		if err = json.NewEncoder(&buf).Encode(req); err != nil {
+			return err
+		}
+		r.Body = ioutil.NopCloser(&buf)
+		return nil
+	}
+}
+
+// decodeWebhookCreateResponse decodes the incoming HTTP payload to the Go kit payload
+func decodeWebhookCreateResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	var resp transport.WebhookCreateResponse
+
+	if r.StatusCode != http.StatusOK {
+		return nil, problem.DecodeError(r)
+	}
+	if err := json.NewDecoder(r.Body).Decode(&resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// decodeWebhookDeleteResponse decodes the incoming HTTP payload to the Go kit payload
+func decodeWebhookDeleteResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	var resp transport.WebhookDeleteResponse
+
+	if r.StatusCode != http.StatusOK {
+		return nil, problem.DecodeError(r)
+	}
+	if err := json.NewDecoder(r.Body).Decode(&resp); err != nil {
+		return nil, err
 	}
+	return resp, nil
+}
+
+// decodeWebhookListResponse decodes the incoming HTTP payload to the Go kit payload
+func decodeWebhookListResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	var resp transport.WebhookListResponse
 
-	return string(bytes.Trim(b, "\r\n\t "))
+	if r.StatusCode != http.StatusOK {
+		return nil, problem.DecodeError(r)
+	}
+	if err := json.NewDecoder(r.Body).Decode(&resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
 }
@@ -9,19 +9,23 @@ import (
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 	"github.com/go-kit/kit/ratelimit"
-	"github.com/go-kit/kit/sd"
+	kitsd "github.com/go-kit/kit/sd"
 	"github.com/gorilla/mux"
 	"golang.org/x/time/rate"
 
 	// project
+	"github.com/basvanbeek/opencensus-gokit-example/services/frontend"
 	"github.com/basvanbeek/opencensus-gokit-example/services/frontend/transport"
 	"github.com/basvanbeek/opencensus-gokit-example/services/frontend/transport/http/routes"
 	"github.com/basvanbeek/opencensus-gokit-example/shared/factory"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/health"
 	"github.com/basvanbeek/opencensus-gokit-example/shared/loggermw"
 )
 
 // InitEndpoints returns an initialized set of Go kit HTTP endpoints.
-func InitEndpoints(instancer sd.Instancer, logger log.Logger) transport.Endpoints {
+func InitEndpoints(
+	instancer kitsd.Instancer, logger log.Logger, opts ...factory.Option,
+) transport.Endpoints {
 	route := routes.Initialize(mux.NewRouter())
 
 	// configure client wide rate limiter for all instances and all method
@@ -36,6 +40,12 @@ func InitEndpoints(instancer sd.Instancer, logger log.Logger) transport.Endpoint
 	// chain our service wide middlewares
 	middlewares := endpoint.Chain(lmw, rl)
 
+	opts = append([]factory.Option{
+		factory.WithHealthCheck(
+			frontend.ServiceName+"/http", health.HTTPCheck(factory.HealthClient(opts...)),
+		),
+	}, opts...)
+
 	// create our client endpoints
 	return transport.Endpoints{
 		Login: factory.CreateHTTPEndpoint(
@@ -44,6 +54,7 @@ func InitEndpoints(instancer sd.Instancer, logger log.Logger) transport.Endpoint
 			"Login",
 			factory.EncodeGenericRequest(route.Login),
 			decodeLoginResponse,
+			opts...,
 		),
 		EventCreate: factory.CreateHTTPEndpoint(
 			instancer,
@@ -51,27 +62,31 @@ func InitEndpoints(instancer sd.Instancer, logger log.Logger) transport.Endpoint
 			"EventCreate",
 			factory.EncodeGenericRequest(route.EventCreate),
 			decodeEventCreateResponse,
+			opts...,
 		),
 		EventGet: factory.CreateHTTPEndpoint(
 			instancer,
 			middlewares,
 			"EventGet",
-			factory.EncodeGenericRequest(route.EventGet),
+			encodeRouteRequest(route.EventGet, eventParams),
 			decodeEventGetResponse,
+			opts...,
 		),
 		EventUpdate: factory.CreateHTTPEndpoint(
 			instancer,
 			middlewares,
 			"EventUpdate",
-			factory.EncodeGenericRequest(route.EventUpdate),
+			encodeRouteRequest(route.EventUpdate, eventParams),
 			decodeEventUpdateResponse,
+			opts...,
 		),
 		EventDelete: factory.CreateHTTPEndpoint(
 			instancer,
 			middlewares,
 			"EventDelete",
-			factory.EncodeGenericRequest(route.EventDelete),
+			encodeRouteRequest(route.EventDelete, eventParams),
 			decodeEventDeleteResponse,
+			opts...,
 		),
 		EventList: factory.CreateHTTPEndpoint(
 			instancer,
@@ -79,20 +94,111 @@ func InitEndpoints(instancer sd.Instancer, logger log.Logger) transport.Endpoint
 			"EventList",
 			factory.EncodeGenericRequest(route.EventList),
 			decodeEventListResponse,
+			opts...,
+		),
+		EventImport: factory.CreateHTTPEndpoint(
+			instancer,
+			middlewares,
+			"EventImport",
+			encodeEventImportRequest(route.EventImport),
+			decodeEventImportResponse,
+			opts...,
+		),
+		EventExport: factory.CreateHTTPEndpoint(
+			instancer,
+			middlewares,
+			"EventExport",
+			encodeEventExportRequest(route.EventExport),
+			decodeEventExportResponse,
+			opts...,
 		),
 		UnlockDevice: factory.CreateHTTPEndpoint(
 			instancer,
 			middlewares,
 			"UnlockDevice",
-			factory.EncodeGenericRequest(route.UnlockDevice),
+			encodeRouteRequest(route.UnlockDevice, deviceParams),
 			decodeUnlockDeviceResponse,
+			opts...,
 		),
 		GenerateQR: factory.CreateHTTPEndpoint(
 			instancer,
 			middlewares,
 			"GenerateQR",
-			factory.EncodeGenericRequest(route.GenerateQR),
+			encodeRouteRequest(route.GenerateQR, deviceParams),
 			decodeGenerateQRResponse,
+			opts...,
+		),
+		EventCalendarToken: factory.CreateHTTPEndpoint(
+			instancer,
+			middlewares,
+			"EventCalendarToken",
+			factory.EncodeGenericRequest(route.EventCalendarToken),
+			decodeEventCalendarTokenResponse,
+			opts...,
+		),
+		EventCalendar: factory.CreateHTTPEndpoint(
+			instancer,
+			middlewares,
+			"EventCalendar",
+			encodeEventCalendarRequest(route.EventCalendar),
+			decodeEventCalendarResponse,
+			opts...,
+		),
+		EventClone: factory.CreateHTTPEndpoint(
+			instancer,
+			middlewares,
+			"EventClone",
+			encodeEventCloneRequest(route.EventClone),
+			decodeEventCloneResponse,
+			opts...,
+		),
+		EventTemplateCreate: factory.CreateHTTPEndpoint(
+			instancer,
+			middlewares,
+			"EventTemplateCreate",
+			factory.EncodeGenericRequest(route.EventTemplateCreate),
+			decodeEventTemplateCreateResponse,
+			opts...,
+		),
+		EventTemplateList: factory.CreateHTTPEndpoint(
+			instancer,
+			middlewares,
+			"EventTemplateList",
+			factory.EncodeGenericRequest(route.EventTemplateList),
+			decodeEventTemplateListResponse,
+			opts...,
+		),
+		EventTemplateDelete: factory.CreateHTTPEndpoint(
+			instancer,
+			middlewares,
+			"EventTemplateDelete",
+			encodeEventTemplateDeleteRequest(route.EventTemplateDelete),
+			decodeEventTemplateDeleteResponse,
+			opts...,
+		),
+		WebhookCreate: factory.CreateHTTPEndpoint(
+			instancer,
+			middlewares,
+			"WebhookCreate",
+			factory.EncodeGenericRequest(route.WebhookCreate),
+			decodeWebhookCreateResponse,
+			opts...,
+		),
+		WebhookDelete: factory.CreateHTTPEndpoint(
+			instancer,
+			middlewares,
+			"WebhookDelete",
+			encodeWebhookDeleteRequest(route.WebhookDelete),
+			decodeWebhookDeleteResponse,
+			opts...,
+		),
+		WebhookList: factory.CreateHTTPEndpoint(
+			instancer,
+			middlewares,
+			"WebhookList",
+			factory.EncodeGenericRequest(route.WebhookList),
+			decodeWebhookListResponse,
+			opts...,
 		),
 	}
 }
@@ -6,18 +6,21 @@ import (
 
 	// external
 	"github.com/go-kit/kit/log"
-	"github.com/go-kit/kit/sd"
+	kitsd "github.com/go-kit/kit/sd"
 
 	//project
 	"github.com/basvanbeek/opencensus-gokit-example/clients/qr/grpc"
 	"github.com/basvanbeek/opencensus-gokit-example/services/qr"
 	"github.com/basvanbeek/opencensus-gokit-example/services/qr/transport"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/factory"
 )
 
 // NewGRPCClient returns a new qr client using the gRPC transport.
-func NewGRPCClient(instancer sd.Instancer, logger log.Logger) qr.Service {
+func NewGRPCClient(
+	instancer kitsd.Instancer, logger log.Logger, opts ...factory.Option,
+) qr.Service {
 	return &client{
-		endpoints: grpc.InitEndpoints(instancer, logger),
+		endpoints: grpc.InitEndpoints(instancer, logger, opts...),
 		logger:    logger,
 	}
 }
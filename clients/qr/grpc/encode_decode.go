@@ -3,15 +3,8 @@ package grpc
 import (
 	// stdlib
 	"context"
-	"errors"
-
-	// external
-	"github.com/go-kit/kit/endpoint"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 
 	// project
-	"github.com/basvanbeek/opencensus-gokit-example/services/qr"
 	"github.com/basvanbeek/opencensus-gokit-example/services/qr/transport"
 	"github.com/basvanbeek/opencensus-gokit-example/services/qr/transport/pb"
 )
@@ -31,42 +24,3 @@ func decodeGenerateResponse(_ context.Context, response interface{}) (interface{
 	resp := response.(*pb.GenerateResponse)
 	return transport.GenerateResponse{QR: resp.Image}, nil
 }
-
-func decodeGenerateError() endpoint.Middleware {
-	return func(e endpoint.Endpoint) endpoint.Endpoint {
-		return func(ctx context.Context, request interface{}) (interface{}, error) {
-			// call our gRPC client endpoint
-			response, err := e(ctx, request)
-			// check error response
-			st, _ := status.FromError(err)
-			switch st.Code() {
-			case codes.OK:
-				// no error encountered... proceed with regular response payload
-				return response, nil
-			case codes.InvalidArgument, codes.FailedPrecondition:
-				// business logic error which should not be retried or trigger
-				// the circuitbreaker.
-				switch st.Message() {
-				case qr.ErrorInvalidRecoveryLevel:
-					err = qr.ErrInvalidRecoveryLevel
-				case qr.ErrorInvalidSize:
-					err = qr.ErrInvalidSize
-				case qr.ErrorNoContent:
-					err = qr.ErrNoContent
-				case qr.ErrorContentTooLarge:
-					err = qr.ErrContentTooLarge
-				}
-				return transport.GenerateResponse{Err: err}, nil
-			default:
-				// error which might invoke a retry or trigger a circuitbreaker
-				switch st.Message() {
-				case qr.ErrorGenerate:
-					err = qr.ErrGenerate
-				default:
-					err = errors.New(st.Message())
-				}
-				return nil, err
-			}
-		}
-	}
-}
@@ -9,22 +9,24 @@ import (
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 	"github.com/go-kit/kit/ratelimit"
-	"github.com/go-kit/kit/sd"
+	kitsd "github.com/go-kit/kit/sd"
 	"golang.org/x/time/rate"
-	"google.golang.org/grpc"
 
 	// project
+	"github.com/basvanbeek/opencensus-gokit-example/services/qr"
 	"github.com/basvanbeek/opencensus-gokit-example/services/qr/transport"
 	"github.com/basvanbeek/opencensus-gokit-example/services/qr/transport/pb"
 	"github.com/basvanbeek/opencensus-gokit-example/shared/factory"
-	"github.com/basvanbeek/opencensus-gokit-example/shared/grpcconn"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/health"
 	"github.com/basvanbeek/opencensus-gokit-example/shared/loggermw"
 )
 
 // InitEndpoints returns an initialized set of Go kit gRPC endpoints
-func InitEndpoints(instancer sd.Instancer, logger log.Logger) transport.Endpoints {
+func InitEndpoints(
+	instancer kitsd.Instancer, logger log.Logger, opts ...factory.Option,
+) transport.Endpoints {
 	// initialize our gRPC host mapper helper
-	hm := grpcconn.NewHostMapper(grpc.WithInsecure())
+	hm := factory.NewHostMapper(opts...)
 
 	// configure client wide rate limiter for all instances and all method
 	// endpoints
@@ -38,6 +40,12 @@ func InitEndpoints(instancer sd.Instancer, logger log.Logger) transport.Endpoint
 	// chain our service wide middlewares
 	middlewares := endpoint.Chain(lmw, rl)
 
+	opts = append([]factory.Option{
+		factory.WithHealthCheck(
+			qr.ServiceName+"/grpc", health.GRPCCheck(hm, "pb.QR"),
+		),
+	}, opts...)
+
 	return transport.Endpoints{
 		Generate: factory.CreateGRPCEndpoint(
 			instancer,
@@ -48,7 +56,7 @@ func InitEndpoints(instancer sd.Instancer, logger log.Logger) transport.Endpoint
 			pb.GenerateResponse{},
 			encodeGenerateRequest,
 			decodeGenerateResponse,
-			decodeGenerateError(),
+			opts...,
 		),
 	}
 }
@@ -0,0 +1,99 @@
+package webhook
+
+import (
+	// stdlib
+	"context"
+
+	// external
+	"github.com/go-kit/kit/log"
+	kitsd "github.com/go-kit/kit/sd"
+	"github.com/kevinburke/go.uuid"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/clients/webhook/http"
+	"github.com/basvanbeek/opencensus-gokit-example/services/webhook"
+	"github.com/basvanbeek/opencensus-gokit-example/services/webhook/transport"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/factory"
+)
+
+// NewHTTPClient returns a new webhook client using the HTTP transport.
+func NewHTTPClient(
+	instancer kitsd.Instancer, logger log.Logger, opts ...factory.Option,
+) webhook.Service {
+	return &client{
+		endpoints: http.InitEndpoints(instancer, logger, opts...),
+		logger:    logger,
+	}
+}
+
+type client struct {
+	endpoints transport.Endpoints
+	logger    log.Logger
+}
+
+func (c *client) Subscribe(
+	ctx context.Context, tenantID uuid.UUID, subscription webhook.Subscription,
+) (*uuid.UUID, error) {
+	response, err := c.endpoints.Subscribe(
+		ctx,
+		transport.SubscribeRequest{
+			TenantID:     tenantID,
+			Subscription: subscription,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	res := response.(transport.SubscribeResponse)
+	return res.ID, nil
+}
+
+func (c *client) Unsubscribe(ctx context.Context, tenantID, id uuid.UUID) error {
+	response, err := c.endpoints.Unsubscribe(
+		ctx,
+		transport.UnsubscribeRequest{
+			TenantID: tenantID,
+			ID:       id,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return response.(transport.UnsubscribeResponse).Failed()
+}
+
+func (c *client) Subscriptions(
+	ctx context.Context, tenantID uuid.UUID,
+) ([]*webhook.Subscription, error) {
+	response, err := c.endpoints.Subscriptions(
+		ctx,
+		transport.SubscriptionsRequest{
+			TenantID: tenantID,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	res := response.(transport.SubscriptionsResponse)
+	return res.Subscriptions, nil
+}
+
+func (c *client) Publish(
+	ctx context.Context, tenantID uuid.UUID, notification webhook.Notification,
+) error {
+	response, err := c.endpoints.Publish(
+		ctx,
+		transport.PublishRequest{
+			TenantID:     tenantID,
+			Notification: notification,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return response.(transport.PublishResponse).Failed()
+}
@@ -0,0 +1,64 @@
+package http
+
+import (
+	// stdlib
+	"context"
+	"encoding/json"
+	"net/http"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/services/webhook/transport"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/problem"
+)
+
+// decodeSubscribeResponse decodes the incoming HTTP payload to the Go kit payload
+func decodeSubscribeResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	var resp transport.SubscribeResponse
+
+	if r.StatusCode != http.StatusOK {
+		return nil, problem.DecodeError(r)
+	}
+	if err := json.NewDecoder(r.Body).Decode(&resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// decodeUnsubscribeResponse decodes the incoming HTTP payload to the Go kit payload
+func decodeUnsubscribeResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	var resp transport.UnsubscribeResponse
+
+	if r.StatusCode != http.StatusOK {
+		return nil, problem.DecodeError(r)
+	}
+	if err := json.NewDecoder(r.Body).Decode(&resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// decodeSubscriptionsResponse decodes the incoming HTTP payload to the Go kit payload
+func decodeSubscriptionsResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	var resp transport.SubscriptionsResponse
+
+	if r.StatusCode != http.StatusOK {
+		return nil, problem.DecodeError(r)
+	}
+	if err := json.NewDecoder(r.Body).Decode(&resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// decodePublishResponse decodes the incoming HTTP payload to the Go kit payload
+func decodePublishResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	var resp transport.PublishResponse
+
+	if r.StatusCode != http.StatusOK {
+		return nil, problem.DecodeError(r)
+	}
+	if err := json.NewDecoder(r.Body).Decode(&resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
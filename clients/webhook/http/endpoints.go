@@ -0,0 +1,84 @@
+package http
+
+import (
+	// stdlib
+	"time"
+
+	// external
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/go-kit/kit/ratelimit"
+	kitsd "github.com/go-kit/kit/sd"
+	"github.com/gorilla/mux"
+	"golang.org/x/time/rate"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/services/webhook"
+	"github.com/basvanbeek/opencensus-gokit-example/services/webhook/transport"
+	"github.com/basvanbeek/opencensus-gokit-example/services/webhook/transport/http/routes"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/factory"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/health"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/loggermw"
+)
+
+// InitEndpoints returns an initialized set of Go kit HTTP endpoints.
+func InitEndpoints(
+	instancer kitsd.Instancer, logger log.Logger, opts ...factory.Option,
+) transport.Endpoints {
+	route := routes.Initialize(mux.NewRouter())
+
+	// configure client wide rate limiter for all instances and all method
+	// endpoints
+	rl := ratelimit.NewErroringLimiter(
+		rate.NewLimiter(rate.Every(time.Second), 1000),
+	)
+
+	// debug logging middleware
+	lmw := loggermw.LoggerMiddleware(level.Debug(logger))
+
+	// chain our service wide middlewares
+	middlewares := endpoint.Chain(lmw, rl)
+
+	opts = append([]factory.Option{
+		factory.WithHealthCheck(
+			webhook.ServiceName+"/http", health.HTTPCheck(factory.HealthClient(opts...)),
+		),
+	}, opts...)
+
+	// create our client endpoints
+	return transport.Endpoints{
+		Subscribe: factory.CreateHTTPEndpoint(
+			instancer,
+			middlewares,
+			"Subscribe",
+			factory.EncodeGenericRequest(route.Subscribe),
+			decodeSubscribeResponse,
+			opts...,
+		),
+		Unsubscribe: factory.CreateHTTPEndpoint(
+			instancer,
+			middlewares,
+			"Unsubscribe",
+			factory.EncodeGenericRequest(route.Unsubscribe),
+			decodeUnsubscribeResponse,
+			opts...,
+		),
+		Subscriptions: factory.CreateHTTPEndpoint(
+			instancer,
+			middlewares,
+			"Subscriptions",
+			factory.EncodeGenericRequest(route.Subscriptions),
+			decodeSubscriptionsResponse,
+			opts...,
+		),
+		Publish: factory.CreateHTTPEndpoint(
+			instancer,
+			middlewares,
+			"Publish",
+			factory.EncodeGenericRequest(route.Publish),
+			decodePublishResponse,
+			opts...,
+		),
+	}
+}
@@ -0,0 +1,114 @@
+package e2e
+
+import (
+	// stdlib
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	// external
+	"github.com/kevinburke/go.uuid"
+	"golang.org/x/crypto/bcrypt"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/services/frontend"
+)
+
+// TestFrontend runs the calls of our cli and the unlock of a device against
+// our services, reaching each of them through its client transport.
+func TestFrontend(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	s := start(t)
+	defer s.close()
+	client := s.client(t)
+
+	login, err := client.Login(ctx, "john", "doe")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// event service over Twirp
+	id, err := client.EventCreate(ctx, login.TenantID, frontend.Event{
+		Name: "Marine Corps Marathon",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	evt, err := client.EventGet(ctx, login.TenantID, *id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, have := "Marine Corps Marathon", evt.Name; want != have {
+		t.Errorf("want event %q, have %q", want, have)
+	}
+	events, err := client.EventList(ctx, login.TenantID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, have := 1, len(events); want != have {
+		t.Fatalf("want %d events, have %d", want, have)
+	}
+	if want, have := *id, events[0].ID; !uuid.Equal(want, have) {
+		t.Errorf("want event %s, have %s", want, have)
+	}
+
+	// device service over HTTP, with a device provisioned for our event
+	s.reconcile(ctx, t)
+	var (
+		deviceID = uuid.NewV4()
+		code     = "1234"
+	)
+	hash, err := bcrypt.GenerateFromPassword([]byte(code), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = s.devices.AddDevice(ctx, *id, deviceID, "Gate 1", hash); err != nil {
+		t.Fatal(err)
+	}
+	session, err := client.UnlockDevice(ctx, *id, deviceID, code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, have := "Gate 1", session.DeviceCaption; want != have {
+		t.Errorf("want device %q, have %q", want, have)
+	}
+	if want, have := "Marine Corps Marathon", session.EventCaption; want != have {
+		t.Errorf("want event %q, have %q", want, have)
+	}
+	// business errors keep their identity across both hops
+	if _, err = client.UnlockDevice(ctx, *id, deviceID, "4321"); err != frontend.ErrUnlockNotFound {
+		t.Errorf("want %v, have %v", frontend.ErrUnlockNotFound, err)
+	}
+
+	// QR service over gRPC
+	png, err := client.GenerateQR(ctx, *id, deviceID, code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(png, []byte("\x89PNG")) {
+		t.Error("want PNG image")
+	}
+
+	// webhook service over HTTP
+	whID, err := client.WebhookCreate(ctx, login.TenantID, frontend.Webhook{
+		URL:    "https://192.0.2.10/ocg",
+		Secret: "s3cr3t",
+		Types:  []string{"event.created"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	webhooks, err := client.WebhookList(ctx, login.TenantID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, have := 1, len(webhooks); want != have {
+		t.Fatalf("want %d webhooks, have %d", want, have)
+	}
+	if want, have := *whID, webhooks[0].ID; !uuid.Equal(want, have) {
+		t.Errorf("want webhook %s, have %s", want, have)
+	}
+}
@@ -0,0 +1,218 @@
+// Package e2e runs our services, wired with their real transports and
+// clients, in a single process discovering each other through a shared
+// in-memory registry.
+package e2e
+
+import (
+	// stdlib
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+	"github.com/jmoiron/sqlx"
+	"github.com/kevinburke/go.uuid"
+	_ "github.com/mattn/go-sqlite3"
+	"google.golang.org/grpc"
+
+	// project
+	devclient "github.com/basvanbeek/opencensus-gokit-example/clients/device"
+	evtclient "github.com/basvanbeek/opencensus-gokit-example/clients/event"
+	feclient "github.com/basvanbeek/opencensus-gokit-example/clients/frontend"
+	qrclient "github.com/basvanbeek/opencensus-gokit-example/clients/qr"
+	whclient "github.com/basvanbeek/opencensus-gokit-example/clients/webhook"
+	"github.com/basvanbeek/opencensus-gokit-example/services/device"
+	devinmemory "github.com/basvanbeek/opencensus-gokit-example/services/device/database/inmemory"
+	"github.com/basvanbeek/opencensus-gokit-example/services/device/eventsync"
+	devimplementation "github.com/basvanbeek/opencensus-gokit-example/services/device/implementation"
+	devtransport "github.com/basvanbeek/opencensus-gokit-example/services/device/transport"
+	devhttp "github.com/basvanbeek/opencensus-gokit-example/services/device/transport/http"
+	"github.com/basvanbeek/opencensus-gokit-example/services/event"
+	evtinmemory "github.com/basvanbeek/opencensus-gokit-example/services/event/database/inmemory"
+	evtimplementation "github.com/basvanbeek/opencensus-gokit-example/services/event/implementation"
+	evtpb "github.com/basvanbeek/opencensus-gokit-example/services/event/transport/pb"
+	evttwirp "github.com/basvanbeek/opencensus-gokit-example/services/event/transport/twirp"
+	"github.com/basvanbeek/opencensus-gokit-example/services/frontend"
+	feimplementation "github.com/basvanbeek/opencensus-gokit-example/services/frontend/implementation"
+	fetransport "github.com/basvanbeek/opencensus-gokit-example/services/frontend/transport"
+	fehttp "github.com/basvanbeek/opencensus-gokit-example/services/frontend/transport/http"
+	"github.com/basvanbeek/opencensus-gokit-example/services/qr"
+	qrimplementation "github.com/basvanbeek/opencensus-gokit-example/services/qr/implementation"
+	qrtransport "github.com/basvanbeek/opencensus-gokit-example/services/qr/transport"
+	qrgrpc "github.com/basvanbeek/opencensus-gokit-example/services/qr/transport/grpc"
+	qrpb "github.com/basvanbeek/opencensus-gokit-example/services/qr/transport/pb"
+	"github.com/basvanbeek/opencensus-gokit-example/services/webhook"
+	whsqlite "github.com/basvanbeek/opencensus-gokit-example/services/webhook/database/sqlite"
+	whimplementation "github.com/basvanbeek/opencensus-gokit-example/services/webhook/implementation"
+	whtransport "github.com/basvanbeek/opencensus-gokit-example/services/webhook/transport"
+	whhttp "github.com/basvanbeek/opencensus-gokit-example/services/webhook/transport/http"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/registry"
+)
+
+// system holds our services running in this process.
+type system struct {
+	registry registry.Registry
+	logger   log.Logger
+	dir      string
+	closers  []func()
+
+	// the device read model, allowing tests to provision devices
+	devices    *devinmemory.Repository
+	reconciler *eventsync.Reconciler
+}
+
+// start runs all our services. Each service registers itself in a shared
+// in-memory registry and discovers the services it calls from it.
+func start(t *testing.T) *system {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "e2e")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &system{
+		registry: registry.NewMemory(log.NewNopLogger()),
+		logger:   log.NewNopLogger(),
+		dir:      dir,
+		devices:  devinmemory.New(),
+	}
+	s.onClose(func() { os.RemoveAll(dir) })
+
+	s.startWebhook(t)
+	s.startEvent(t)
+	s.startDevice(t)
+	s.startQR(t)
+	s.startFrontend(t)
+	return s
+}
+
+// close stops our services in reverse order of their start.
+func (s *system) close() {
+	for i := len(s.closers) - 1; i >= 0; i-- {
+		s.closers[i]()
+	}
+}
+
+func (s *system) onClose(f func()) {
+	s.closers = append(s.closers, f)
+}
+
+// serveHTTP serves handler as transport of service and registers it.
+func (s *system) serveHTTP(service, transport string, handler http.Handler) {
+	srv := httptest.NewServer(handler)
+	registrar := s.registry.Registrar(service, transport, uuid.NewV4().String(), srv.URL)
+	registrar.Register()
+	s.onClose(func() {
+		registrar.Deregister()
+		srv.Close()
+	})
+}
+
+// instancer returns an instancer of the transport of service, stopped on
+// close.
+func (s *system) instancer(t *testing.T, service, transport string) registry.Instancer {
+	t.Helper()
+	instancer, err := s.registry.Instancer(service, transport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.onClose(instancer.Stop)
+	return instancer
+}
+
+func (s *system) startWebhook(t *testing.T) {
+	db, err := sqlx.Open("sqlite3", filepath.Join(s.dir, "webhook.db")+"?_journal_mode=WAL")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.onClose(func() { db.Close() })
+	repository, err := whsqlite.New(db, s.logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := whimplementation.NewService(repository, s.logger)
+	endpoints := whtransport.MakeEndpoints(svc)
+	s.serveHTTP(webhook.ServiceName, "http", whhttp.NewService(endpoints, nil, s.logger))
+}
+
+func (s *system) startEvent(t *testing.T) {
+	whClient := whclient.NewHTTPClient(s.instancer(t, webhook.ServiceName, "http"), s.logger)
+
+	svc := evtimplementation.NewService(evtinmemory.New(), s.logger)
+	svc = evtimplementation.NotifyMiddleware(whClient, s.logger)(svc)
+
+	router := mux.NewRouter()
+	router.PathPrefix(evtpb.EventPathPrefix).Handler(
+		evtpb.NewEventServer(evttwirp.NewService(svc, s.logger), nil),
+	)
+	s.serveHTTP(event.ServiceName, "twirp", router)
+}
+
+func (s *system) startDevice(t *testing.T) {
+	whClient := whclient.NewHTTPClient(s.instancer(t, webhook.ServiceName, "http"), s.logger)
+
+	svc := devimplementation.NewService(s.devices, s.logger)
+	svc = devimplementation.NotifyMiddleware(whClient, s.logger)(svc)
+	endpoints := devtransport.MakeEndpoints(svc)
+	s.serveHTTP(device.ServiceName, "http", devhttp.NewService(endpoints, nil, s.logger))
+
+	evtClient := evtclient.NewTwirp(
+		s.instancer(t, event.ServiceName, "twirp"), http.DefaultClient, s.logger,
+	)
+	s.reconciler = eventsync.NewReconciler(evtClient, s.devices, s.logger)
+}
+
+func (s *system) startQR(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	endpoints := qrtransport.MakeEndpoints(qrimplementation.NewService(s.logger))
+	grpcServer := grpc.NewServer()
+	qrpb.RegisterQRServer(grpcServer, qrgrpc.NewGRPCServer(endpoints, nil, s.logger))
+	go grpcServer.Serve(listener)
+
+	registrar := s.registry.Registrar(
+		qr.ServiceName, "grpc", uuid.NewV4().String(), listener.Addr().String(),
+	)
+	registrar.Register()
+	s.onClose(func() {
+		registrar.Deregister()
+		grpcServer.Stop()
+	})
+}
+
+func (s *system) startFrontend(t *testing.T) {
+	var (
+		evtClient = evtclient.NewTwirp(
+			s.instancer(t, event.ServiceName, "twirp"), http.DefaultClient, s.logger,
+		)
+		devClient = devclient.NewHTTPClient(s.instancer(t, device.ServiceName, "http"), s.logger)
+		qrClient  = qrclient.NewGRPCClient(s.instancer(t, qr.ServiceName, "grpc"), s.logger)
+		whClient  = whclient.NewHTTPClient(s.instancer(t, webhook.ServiceName, "http"), s.logger)
+	)
+	svc := feimplementation.NewService(
+		evtClient, devClient, qrClient, whClient, []byte("e2e feed key"), s.logger,
+	)
+	endpoints := fetransport.MakeEndpoints(svc)
+	s.serveHTTP(frontend.ServiceName, "http", fehttp.NewService(endpoints, nil, s.logger))
+}
+
+// client returns a frontend client discovering the frontend like our cli.
+func (s *system) client(t *testing.T) frontend.Service {
+	return feclient.NewHTTPClient(s.instancer(t, frontend.ServiceName, "http"), s.logger)
+}
+
+// reconcile synchronizes the event read model of the device service.
+func (s *system) reconcile(ctx context.Context, t *testing.T) {
+	t.Helper()
+	if _, _, err := s.reconciler.Reconcile(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
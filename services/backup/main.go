@@ -0,0 +1,171 @@
+// Command backup creates, verifies and restores online backups of the SQLite
+// databases of our services. Restores are refused while the owning service is
+// registered in our service registry.
+package main
+
+import (
+	// stdlib
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/go-kit/kit/sd"
+	"go.opencensus.io/stats/view"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/services/device"
+	"github.com/basvanbeek/opencensus-gokit-example/services/event"
+	"github.com/basvanbeek/opencensus-gokit-example/services/frontend"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/oc"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/registry"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/sqlitebackup"
+)
+
+const serviceName = "backup"
+
+// services maps our SQLite database files to the service registering itself
+// while using it.
+var services = map[string]string{
+	"event.db":    event.ServiceName,
+	"device.db":   device.ServiceName,
+	"monolith.db": frontend.ServiceName, // the monolith registers as frontend
+}
+
+// transports maps our services to the transport used to check their
+// registrations.
+var transports = map[string]string{
+	event.ServiceName:    "twirp",
+	device.ServiceName:   "grpc",
+	frontend.ServiceName: "http",
+}
+
+func main() {
+	var (
+		dbFile  = flag.String("db", "", "SQLite database file, e.g. event.db")
+		dir     = flag.String("dir", "backups", "directory to store backups in")
+		from    = flag.String("from", "", "backup file to restore or verify")
+		service = flag.String("service", "", "service using the database, derived from the database file name if omitted")
+		reg     = flag.String("registry", os.Getenv("OCG_REGISTRY"), "service registry used to check service registrations")
+	)
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: ocg-backup [flags] backup|restore|verify")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	// allow flags to follow the command as well
+	command := flag.Arg(0)
+	if flag.NArg() > 1 {
+		flag.CommandLine.Parse(flag.Args()[1:])
+	}
+
+	// initialize our OpenCensus configuration and defer a clean-up
+	defer oc.Setup(serviceName).Close()
+
+	// initialize our structured logger for the command
+	var logger log.Logger
+	{
+		logger = log.NewLogfmtLogger(os.Stderr)
+		logger = log.NewSyncLogger(logger)
+		logger = log.With(logger,
+			"cmd", serviceName,
+			"ts", log.DefaultTimestampUTC,
+		)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	var err error
+	switch command {
+	case "backup":
+		if *dbFile == "" {
+			err = fmt.Errorf("backup requires -db")
+			break
+		}
+		if err = view.Register(sqlitebackup.DurationView, sqlitebackup.SizeView); err != nil {
+			break
+		}
+		var result *sqlitebackup.Result
+		if result, err = sqlitebackup.Backup(ctx, *dbFile, *dir); err != nil {
+			break
+		}
+		fmt.Printf("%s: %d bytes in %s\n", result.Path, result.Size, result.Duration)
+	case "verify":
+		if *from == "" {
+			err = fmt.Errorf("verify requires -from")
+			break
+		}
+		if err = sqlitebackup.Verify(ctx, *from); err != nil {
+			break
+		}
+		fmt.Printf("%s: ok\n", *from)
+	case "restore":
+		if *dbFile == "" || *from == "" {
+			err = fmt.Errorf("restore requires -db and -from")
+			break
+		}
+		if *service == "" {
+			*service = services[filepath.Base(*dbFile)]
+		}
+		if *service == "" {
+			err = fmt.Errorf("unable to derive service from %s, provide -service", *dbFile)
+			break
+		}
+		var r registry.Registry
+		if r, err = registry.New(ctx, *reg, logger); err != nil {
+			err = fmt.Errorf("unable to check %s registrations: %v", *service, err)
+			break
+		}
+		if err = checkRegistrations(r, *service); err != nil {
+			break
+		}
+		if err = sqlitebackup.Restore(ctx, *from, *dbFile); err != nil {
+			break
+		}
+		fmt.Printf("%s: restored from %s\n", *dbFile, *from)
+	default:
+		flag.Usage()
+		os.Exit(-1)
+	}
+	if err != nil {
+		level.Error(logger).Log("exit", err)
+		os.Exit(-1)
+	}
+}
+
+// checkRegistrations returns an error if instances of the service are
+// registered in r or if the registrations can't be checked.
+func checkRegistrations(r registry.Registry, service string) error {
+	transport, ok := transports[service]
+	if !ok {
+		return fmt.Errorf("unable to check %s registrations: unknown service", service)
+	}
+	instancer, err := r.Instancer(service, transport)
+	if err != nil {
+		return fmt.Errorf("unable to check %s registrations: %v", service, err)
+	}
+	defer instancer.Stop()
+
+	// instancers push their current state on registration
+	ch := make(chan sd.Event, 1)
+	instancer.Register(ch)
+	defer instancer.Deregister(ch)
+	event := <-ch
+	if event.Err != nil {
+		return fmt.Errorf("unable to check %s registrations: %v", service, event.Err)
+	}
+	if len(event.Instances) > 0 {
+		return fmt.Errorf(
+			"refusing to restore, %d %s instance(s) registered: %s",
+			len(event.Instances), service, strings.Join(event.Instances, ", "),
+		)
+	}
+	return nil
+}
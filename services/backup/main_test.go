@@ -0,0 +1,43 @@
+package main
+
+import (
+	// stdlib
+	"strings"
+	"testing"
+
+	// external
+	"github.com/go-kit/kit/log"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/services/device"
+	"github.com/basvanbeek/opencensus-gokit-example/services/event"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/registry"
+)
+
+func TestCheckRegistrations(t *testing.T) {
+	r := registry.NewMemory(log.NewNopLogger())
+
+	if err := checkRegistrations(r, event.ServiceName); err != nil {
+		t.Fatalf("want restore allowed without instances, have %v", err)
+	}
+
+	// registrations of the service refuse the restore
+	registrar := r.Registrar(event.ServiceName, "twirp", "1", "http://127.0.0.1:8000")
+	registrar.Register()
+	err := checkRegistrations(r, event.ServiceName)
+	if err == nil || !strings.Contains(err.Error(), "http://127.0.0.1:8000") {
+		t.Fatalf("want restore refused naming the instance, have %v", err)
+	}
+
+	// other services and transports don't
+	r.Registrar(event.ServiceName, "grpc", "2", "127.0.0.1:8001").Register()
+	r.Registrar(device.ServiceName, "http", "3", "http://127.0.0.1:8002").Register()
+	registrar.Deregister()
+	if err = checkRegistrations(r, event.ServiceName); err != nil {
+		t.Errorf("want restore allowed after deregistration, have %v", err)
+	}
+
+	if err = checkRegistrations(r, "qr"); err == nil {
+		t.Error("want unknown service refused")
+	}
+}
@@ -0,0 +1,59 @@
+// Command devca creates a development CA and issues the certificates of our
+// services for running them with mutual TLS locally. The CA certificate is
+// written to <dir>/ca.pem, the certificate and key of each service to
+// <dir>/<service>.pem and <dir>/<service>-key.pem.
+package main
+
+import (
+	// stdlib
+	"flag"
+	"fmt"
+	"os"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/services/device"
+	"github.com/basvanbeek/opencensus-gokit-example/services/event"
+	"github.com/basvanbeek/opencensus-gokit-example/services/frontend"
+	"github.com/basvanbeek/opencensus-gokit-example/services/qr"
+	"github.com/basvanbeek/opencensus-gokit-example/services/webhook"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/mtls"
+)
+
+// services holds the identities issued if none are provided.
+var services = []string{
+	device.ServiceName,
+	event.ServiceName,
+	frontend.ServiceName,
+	qr.ServiceName,
+	webhook.ServiceName,
+	"cli",
+}
+
+func main() {
+	var (
+		dir         = flag.String("dir", "certs", "directory to write the CA and certificates to")
+		trustDomain = flag.String("trust-domain", mtls.DefaultTrustDomain, "trust domain of the service identities")
+	)
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: ocg-devca [flags] [service ...]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() > 0 {
+		services = flag.Args()
+	}
+
+	ca, err := mtls.NewDevCA(*trustDomain)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(-1)
+	}
+	if err = ca.WriteFiles(*dir, services...); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(-1)
+	}
+	for _, service := range services {
+		id := mtls.Identity{TrustDomain: *trustDomain, Service: service}
+		fmt.Printf("%s: %s/%s.pem\n", id, *dir, service)
+	}
+}
@@ -0,0 +1,149 @@
+// Command backfill populates the device service's event read model from the
+// event service in a single pass. Run it once against an existing device
+// database before starting upgraded device service instances.
+package main
+
+import (
+	// stdlib
+	"context"
+	"net/http"
+	"os"
+	"time"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+	"go.opencensus.io/plugin/ochttp"
+
+	// project
+	evtclient "github.com/basvanbeek/opencensus-gokit-example/clients/event"
+	"github.com/basvanbeek/opencensus-gokit-example/services/device"
+	"github.com/basvanbeek/opencensus-gokit-example/services/device/database"
+	"github.com/basvanbeek/opencensus-gokit-example/services/device/database/postgres"
+	"github.com/basvanbeek/opencensus-gokit-example/services/device/database/sqlite"
+	"github.com/basvanbeek/opencensus-gokit-example/services/device/eventsync"
+	"github.com/basvanbeek/opencensus-gokit-example/services/event"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/factory"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/mtls"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/registry"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/sd"
+)
+
+func main() {
+	var err error
+
+	// initialize our structured logger for the command
+	var logger log.Logger
+	{
+		logger = log.NewLogfmtLogger(os.Stderr)
+		logger = log.NewSyncLogger(logger)
+		logger = level.NewFilter(logger, level.AllowDebug())
+		logger = log.With(logger,
+			"svc", device.ServiceName,
+			"cmd", "backfill",
+			"ts", log.DefaultTimestampUTC,
+			"clr", log.DefaultCaller,
+		)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	// Create our service registry for Service Discovery, configured using
+	// OCG_REGISTRY and defaulting to etcd on localhost
+	var reg registry.Registry
+	{
+		reg, err = registry.New(ctx, os.Getenv("OCG_REGISTRY"), logger)
+		if err != nil {
+			level.Error(logger).Log("exit", err)
+			os.Exit(-1)
+		}
+	}
+
+	// Create the routing of our client requests, see the README for its
+	// configuration
+	var routing []sd.Option
+	{
+		if routing, err = registry.Routing(); err != nil {
+			level.Error(logger).Log("exit", err)
+			os.Exit(-1)
+		}
+	}
+
+	// Create the certificate source for mutual TLS between our services and
+	// the options of our clients, see the README for their configuration
+	var (
+		tlsSource  *mtls.Source
+		clientOpts []factory.Option
+	)
+	{
+		if tlsSource, err = mtls.FromEnv(logger); err != nil {
+			level.Error(logger).Log("exit", err)
+			os.Exit(-1)
+		}
+		// stop watching our certificate files on shutdown
+		defer tlsSource.Close()
+		clientOpts = []factory.Option{
+			factory.WithRouting(routing...),
+			factory.WithMTLS(tlsSource),
+		}
+	}
+
+	// Create our DB Connection Driver, using the same database selection as the
+	// device service
+	var (
+		db  *sqlx.DB
+		dsn = os.Getenv("OCG_DEVICE_DB")
+	)
+	{
+		if dsn != "" {
+			db, err = sqlx.Open("postgres", dsn)
+		} else {
+			db, err = sqlx.Open("sqlite3", "device.db?_journal_mode=WAL")
+		}
+		if err != nil {
+			level.Error(logger).Log("exit", err)
+			os.Exit(-1)
+		}
+		defer db.Close()
+	}
+
+	// Create our Event read model Reconciler
+	var reconciler *eventsync.Reconciler
+	{
+		// opening the repository migrates the schema if needed
+		var repository database.Repository
+		if dsn != "" {
+			repository, err = postgres.New(db, logger)
+		} else {
+			repository, err = sqlite.New(db, logger)
+		}
+		if err != nil {
+			level.Error(logger).Log("exit", err)
+			os.Exit(-1)
+		}
+
+		evtInstancer, err := reg.Instancer(event.ServiceName, "twirp")
+		if err != nil {
+			level.Error(logger).Log("exit", err)
+			os.Exit(-1)
+		}
+		defer evtInstancer.Stop()
+		httpClient := &http.Client{
+			Transport: &ochttp.Transport{Base: tlsSource.HTTPTransport()},
+		}
+		evtClient := evtclient.NewTwirp(evtInstancer, httpClient, logger, clientOpts...)
+
+		reconciler = eventsync.NewReconciler(evtClient, repository, logger)
+	}
+
+	updated, removed, err := reconciler.Reconcile(ctx)
+	if err != nil {
+		level.Error(logger).Log("exit", err)
+		os.Exit(-1)
+	}
+	level.Info(logger).Log("msg", "backfill done", "updated", updated, "removed", removed)
+}
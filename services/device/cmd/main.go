@@ -4,7 +4,6 @@ import (
 	// stdlib
 	"context"
 	"fmt"
-	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -14,29 +13,49 @@ import (
 	// external
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
-	"github.com/go-kit/kit/sd/etcd"
 	kitoc "github.com/go-kit/kit/tracing/opencensus"
 	kitgrpc "github.com/go-kit/kit/transport/grpc"
 	kithttp "github.com/go-kit/kit/transport/http"
 	"github.com/jmoiron/sqlx"
 	"github.com/kevinburke/go.uuid"
+	_ "github.com/lib/pq"
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/oklog/run"
 	"github.com/opencensus-integrations/ocsql"
+	"go.opencensus.io/plugin/ochttp"
 	"google.golang.org/grpc"
 
 	// project
+	evtclient "github.com/basvanbeek/opencensus-gokit-example/clients/event"
+	whclient "github.com/basvanbeek/opencensus-gokit-example/clients/webhook"
 	"github.com/basvanbeek/opencensus-gokit-example/services/device"
+	"github.com/basvanbeek/opencensus-gokit-example/services/device/database"
+	"github.com/basvanbeek/opencensus-gokit-example/services/device/database/postgres"
 	"github.com/basvanbeek/opencensus-gokit-example/services/device/database/sqlite"
+	"github.com/basvanbeek/opencensus-gokit-example/services/device/eventsync"
 	"github.com/basvanbeek/opencensus-gokit-example/services/device/implementation"
 	"github.com/basvanbeek/opencensus-gokit-example/services/device/transport"
 	grpctransport "github.com/basvanbeek/opencensus-gokit-example/services/device/transport/grpc"
 	httptransport "github.com/basvanbeek/opencensus-gokit-example/services/device/transport/http"
 	"github.com/basvanbeek/opencensus-gokit-example/services/device/transport/pb"
-	"github.com/basvanbeek/opencensus-gokit-example/shared/network"
+	"github.com/basvanbeek/opencensus-gokit-example/services/event"
+	"github.com/basvanbeek/opencensus-gokit-example/services/frontend"
+	"github.com/basvanbeek/opencensus-gokit-example/services/webhook"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/admin"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/factory"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/health"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/mtls"
 	"github.com/basvanbeek/opencensus-gokit-example/shared/oc"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/quota"
+	qsqlite "github.com/basvanbeek/opencensus-gokit-example/shared/quota/sqlite"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/registry"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/sd"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/sqlitebackup"
 )
 
+// dbFile holds our SQLite database file
+const dbFile = "device.db"
+
 func main() {
 	var (
 		err      error
@@ -63,53 +82,101 @@ func main() {
 	level.Info(logger).Log("msg", "service started")
 	defer level.Info(logger).Log("msg", "service ended")
 
-	// find our host IP to advertise
-	var bindIP string
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Create our service registry for Service Discovery, configured using
+	// OCG_REGISTRY and defaulting to etcd on localhost
+	var reg registry.Registry
 	{
-		if bindIP, err = network.HostIP(); err != nil {
+		reg, err = registry.New(ctx, os.Getenv("OCG_REGISTRY"), logger)
+		if err != nil {
 			level.Error(logger).Log("exit", err)
 			os.Exit(-1)
 		}
 	}
 
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	// Create the metadata announced with our instances and the routing of our
+	// client requests, see the README for their configuration
+	var (
+		md      sd.Metadata
+		routing []sd.Option
+	)
+	{
+		if md, err = registry.LocalMetadata("grpc", "http"); err != nil {
+			level.Error(logger).Log("exit", err)
+			os.Exit(-1)
+		}
+		if routing, err = registry.Routing(); err != nil {
+			level.Error(logger).Log("exit", err)
+			os.Exit(-1)
+		}
+	}
 
-	// Create our etcd client for Service Discovery
-	//
-	// we could have used the v3 client but then we must vendor or suffer the
-	// following issue originating from gRPC init:
-	// panic: http: multiple registrations for /debug/requests
-	var sdc etcd.Client
+	// Create the certificate source for mutual TLS between our services, the
+	// options of our clients and the services allowed to call us, see the
+	// README for the configuration of mutual TLS
+	var (
+		tlsSource  *mtls.Source
+		clientOpts []factory.Option
+		callers    = mtls.AllowServices(frontend.ServiceName)
+	)
 	{
-		// create our Go kit etcd client
-		sdc, err = etcd.NewClient(
-			ctx, []string{"http://localhost:2379"}, etcd.ClientOptions{},
-		)
-		if err != nil {
+		if tlsSource, err = mtls.FromEnv(logger); err != nil {
 			level.Error(logger).Log("exit", err)
 			os.Exit(-1)
 		}
+		// stop watching our certificate files on shutdown
+		defer tlsSource.Close()
+		clientOpts = []factory.Option{
+			factory.WithRouting(routing...),
+			factory.WithMTLS(tlsSource),
+		}
 	}
 
-	// Create our DB Connection Driver
-	var db *sqlx.DB
+	// Create our DB Connection Driver. Instances share a PostgreSQL database if
+	// OCG_DEVICE_DB holds its connection string, else each instance uses a local
+	// SQLite database.
+	var (
+		db  *sqlx.DB
+		dsn = os.Getenv("OCG_DEVICE_DB")
+	)
 	{
-		// create our ocsql instrumented sqlite3 driver
+		// create our ocsql instrumented database driver
+		driver, source := "sqlite3", dbFile+"?_journal_mode=WAL"
+		if dsn != "" {
+			driver, source = "postgres", dsn
+		}
 		var driverName string
-		driverName, err = ocsql.Register("sqlite3", ocsql.WithOptions(ocsql.AllTraceOptions))
+		driverName, err = ocsql.Register(driver, ocsql.WithOptions(ocsql.AllTraceOptions))
 		if err != nil {
 			level.Error(logger).Log("exit", err)
 			os.Exit(-1)
 		}
-		db, err = sqlx.Open(driverName, "device.db?_journal_mode=WAL")
+		db, err = sqlx.Open(driverName, source)
 		if err != nil {
 			level.Error(logger).Log("exit", err)
 			os.Exit(-1)
 		}
 
-		// make sure the DB is in WAL mode
-		if _, err = db.Exec(`PRAGMA journal_mode=wal`); err != nil {
+		// make sure the SQLite DB is in WAL mode
+		if dsn == "" {
+			if _, err = db.Exec(`PRAGMA journal_mode=wal`); err != nil {
+				level.Error(logger).Log("exit", err)
+				os.Exit(-1)
+			}
+		}
+	}
+
+	// Create our Device Repository
+	var repository database.Repository
+	{
+		if dsn != "" {
+			repository, err = postgres.New(db, logger)
+		} else {
+			repository, err = sqlite.New(db, logger)
+		}
+		if err != nil {
 			level.Error(logger).Log("exit", err)
 			os.Exit(-1)
 		}
@@ -118,13 +185,67 @@ func main() {
 	// Create our Device Service
 	var svc device.Service
 	{
-		repository, err := sqlite.New(db, logger)
+		svc = implementation.NewService(repository, logger)
+		// add service level middlewares here
+
+		// create an instancer for the webhook client
+		whInstancer, err := reg.Instancer(webhook.ServiceName, "http")
 		if err != nil {
 			level.Error(logger).Log("exit", err)
 			os.Exit(-1)
 		}
-		svc = implementation.NewService(repository, logger)
-		// add service level middlewares here
+		whClient := whclient.NewHTTPClient(whInstancer, logger, clientOpts...)
+
+		// notify webhook subscribers of device unlocks
+		svc = implementation.NotifyMiddleware(whClient, logger)(svc)
+	}
+
+	// Create our Event read model Reconciler
+	var reconciler *eventsync.Reconciler
+	{
+		// create an instancer for the event client
+		evtInstancer, err := reg.Instancer(event.ServiceName, "twirp")
+		if err != nil {
+			level.Error(logger).Log("exit", err)
+			os.Exit(-1)
+		}
+		httpClient := &http.Client{
+			Transport: &ochttp.Transport{Base: tlsSource.HTTPTransport()},
+		}
+		// keep the requests of a tenant on the event instance caching them
+		evtOpts := append(
+			[]factory.Option{factory.WithConsistentHash(evtclient.TenantKey)}, clientOpts...,
+		)
+		evtClient := evtclient.NewTwirp(evtInstancer, httpClient, logger, evtOpts...)
+
+		reconciler = eventsync.NewReconciler(evtClient, repository, logger)
+	}
+
+	// Create our quota Meter enforcing the daily device and unlock attempt
+	// limits of our tenants. Limits are configured with OCG_QUOTAS, e.g.
+	// "devices=100,unlock_attempts=50".
+	// Instances on the same host share their usage through the SQLite
+	// database in OCG_QUOTA_DB if set.
+	var meter *quota.Meter
+	{
+		limits, err := quota.ParseLimits(os.Getenv("OCG_QUOTAS"))
+		if err != nil {
+			level.Error(logger).Log("exit", err)
+			os.Exit(-1)
+		}
+		var store quota.Store = quota.NewMemoryStore()
+		if path := os.Getenv("OCG_QUOTA_DB"); path != "" {
+			db, err := sqlx.Open("sqlite3", path+"?_journal_mode=WAL&_busy_timeout=5000")
+			if err != nil {
+				level.Error(logger).Log("exit", err)
+				os.Exit(-1)
+			}
+			if store, err = qsqlite.New(db, logger); err != nil {
+				level.Error(logger).Log("exit", err)
+				os.Exit(-1)
+			}
+		}
+		meter = quota.NewMeter(store, limits)
 	}
 
 	// Create our Go kit endpoints for the Device Service
@@ -132,7 +253,21 @@ func main() {
 	{
 		endpoints = transport.MakeEndpoints(svc)
 		// add endpoint level middlewares here
+		endpoints.Unlock = quota.Middleware(
+			meter, transport.UnlockQuota(svc.DeviceTenant), device.ErrQuotaExceeded, logger,
+		)(endpoints.Unlock)
+		endpoints.CloneDevices = quota.Middleware(
+			meter, transport.CloneDevicesQuota(), device.ErrQuotaExceeded, logger,
+		)(endpoints.CloneDevices)
+		// DeviceTenant discloses the tenant of any device, only the frontend
+		// may call it and only over mutual TLS
+		endpoints.DeviceTenant = mtls.EndpointMiddleware(
+			mtls.AllowServices(frontend.ServiceName),
+		)(endpoints.DeviceTenant)
+
 		endpoints.Unlock = oc.ServerEndpoint("UnlockEndpoint")(endpoints.Unlock)
+		endpoints.CloneDevices = oc.ServerEndpoint("CloneDevicesEndpoint")(endpoints.CloneDevices)
+		endpoints.DeviceTenant = oc.ServerEndpoint("DeviceTenantEndpoint")(endpoints.DeviceTenant)
 	}
 
 	// run.Group manages our goroutine lifecycles
@@ -142,47 +277,84 @@ func main() {
 		// set-up our ZPages handler
 		oc.ZPages(g, logger)
 	}
+	{
+		// set-up our admin endpoints serving our daily usage report and online
+		// SQLite backups; PostgreSQL databases are backed up using their own
+		// tooling
+		router := http.NewServeMux()
+		router.Handle("/admin/usage", quota.NewHandler(meter, logger))
+		if dsn == "" {
+			if err := sqlitebackup.Handle(router, dbFile, logger); err != nil {
+				level.Error(logger).Log("exit", err)
+				os.Exit(-1)
+			}
+		}
+		if err := admin.Serve(&g, router, logger); err != nil {
+			level.Error(logger).Log("exit", err)
+			os.Exit(-1)
+		}
+	}
+	{
+		// keep our event read model in sync with the event service
+		ctx, cancel := context.WithCancel(ctx)
+		g.Add(func() error {
+			return reconciler.Run(ctx, 30*time.Second)
+		}, func(error) {
+			cancel()
+		})
+	}
 	{
 		// set-up our grpc transport
+		listener, err := registry.Listen(device.ServiceName, "grpc")
+		if err != nil {
+			level.Error(logger).Log("exit", err)
+			os.Exit(-1)
+		}
 		var (
 			ocTracing     = kitoc.GRPCServerTrace()
 			serverOptions = []kitgrpc.ServerOption{ocTracing}
 			service       = grpctransport.NewService(endpoints, serverOptions, logger)
-			listener, _   = net.Listen("tcp", bindIP+":0") // dynamic port assignment
-			svcInstance   = fmt.Sprintf("/services/%s/grpc/%s/", device.ServiceName, instance)
-			addr          = listener.Addr().String()
-			ttl           = etcd.NewTTLOption(3*time.Second, 10*time.Second)
-			serviceEntry  = etcd.Service{Key: svcInstance, Value: addr, TTL: ttl}
-			registrar     = etcd.NewRegistrar(sdc, serviceEntry, logger)
-			grpcServer    = grpc.NewServer()
+			addr          = sd.FormatInstance(listener.Addr().String(), md)
+			registrar     = reg.Registrar(device.ServiceName, "grpc", instance.String(), addr)
+			grpcServer    = grpc.NewServer(tlsSource.GRPCServerOptions(callers)...)
 		)
 		pb.RegisterDeviceServer(grpcServer, service)
+		healthServer := health.RegisterGRPC(grpcServer, "pb.Device")
 
 		g.Add(func() error {
 			registrar.Register()
 			return grpcServer.Serve(listener)
 		}, func(error) {
 			registrar.Deregister()
+			healthServer.Shutdown()
 			listener.Close()
 		})
 	}
 	{
 		// set-up our http transport
+		listener, err := registry.Listen(device.ServiceName, "http")
+		if err != nil {
+			level.Error(logger).Log("exit", err)
+			os.Exit(-1)
+		}
 		var (
 			ocTracing     = kitoc.HTTPServerTrace()
 			serverOptions = []kithttp.ServerOption{ocTracing}
 			service       = httptransport.NewService(endpoints, serverOptions, logger)
-			listener, _   = net.Listen("tcp", bindIP+":0") // dynamic port assignment
-			svcInstance   = fmt.Sprintf("/services/%s/http/%s/", device.ServiceName, instance)
-			addr          = "http://" + listener.Addr().String()
-			ttl           = etcd.NewTTLOption(3*time.Second, 10*time.Second)
-			serviceEntry  = etcd.Service{Key: svcInstance, Value: addr, TTL: ttl}
-			registrar     = etcd.NewRegistrar(sdc, serviceEntry, logger)
+			addr          = sd.FormatInstance("http://"+listener.Addr().String(), md)
+			registrar     = reg.Registrar(device.ServiceName, "http", instance.String(), addr)
+			router        = http.NewServeMux()
 		)
 
+		// serve our health endpoint next to our endpoints
+		router.Handle(health.Path, health.Handler())
+		router.Handle("/", service)
+
 		g.Add(func() error {
 			registrar.Register()
-			return http.Serve(listener, service)
+			return http.Serve(
+				tlsSource.Listener(listener), tlsSource.HTTPHandler(callers, router),
+			)
 		}, func(error) {
 			registrar.Deregister()
 			listener.Close()
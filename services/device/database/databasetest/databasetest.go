@@ -0,0 +1,207 @@
+// Package databasetest holds the conformance checks every device Repository
+// implementation must pass. Each check works on freshly generated tenants and
+// events so the checks can run against a shared, non-empty database.
+package databasetest
+
+import (
+	// stdlib
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	// external
+	"github.com/kevinburke/go.uuid"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/services/device/database"
+)
+
+// AddDevice stores a device for an event. The Repository has no method to add
+// devices so each backend provides its own.
+type AddDevice func(ctx context.Context, eventID, deviceID uuid.UUID, name string, hash []byte) error
+
+// Check is a single named conformance check.
+type Check struct {
+	Name string
+	Run  func(ctx context.Context, repo database.Repository, add AddDevice) error
+}
+
+// Checks holds our Repository conformance checks.
+var Checks = []Check{
+	{"upsert and list events", upsertAndList},
+	{"get device", getDevice},
+	{"clone devices", cloneDevices},
+}
+
+// Run runs all checks against the repository as subtests of t.
+func Run(t *testing.T, repo database.Repository, add AddDevice) {
+	for _, check := range Checks {
+		check := check
+		t.Run(check.Name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			if err := check.Run(ctx, repo, add); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func upsertAndList(ctx context.Context, repo database.Repository, _ AddDevice) error {
+	event := database.Event{
+		ID: uuid.NewV4(), TenantID: uuid.NewV4(), Name: "Meetup",
+		Status: database.EventActive,
+	}
+	if err := repo.UpsertEvent(ctx, event); err != nil {
+		return err
+	}
+	if err := listed(ctx, repo, event); err != nil {
+		return err
+	}
+
+	// upserting an existing event replaces its details
+	event.Name, event.Status = "Meetup (moved)", database.EventRemoved
+	if err := repo.UpsertEvent(ctx, event); err != nil {
+		return err
+	}
+	return listed(ctx, repo, event)
+}
+
+func getDevice(ctx context.Context, repo database.Repository, add AddDevice) error {
+	event := database.Event{
+		ID: uuid.NewV4(), TenantID: uuid.NewV4(), Name: "Conference",
+		Status: database.EventActive,
+	}
+	if err := repo.UpsertEvent(ctx, event); err != nil {
+		return err
+	}
+	deviceID, hash := uuid.NewV4(), []byte("unlock-hash")
+	if err := add(ctx, event.ID, deviceID, "Entrance", hash); err != nil {
+		return err
+	}
+
+	session, err := repo.GetDevice(ctx, event.ID, deviceID)
+	if err != nil {
+		return err
+	}
+	if !uuid.Equal(session.TenantID, event.TenantID) ||
+		session.EventCaption != event.Name || session.DeviceCaption != "Entrance" ||
+		!bytes.Equal(session.UnlockHash, hash) {
+		return fmt.Errorf("get device: have %+v", *session)
+	}
+
+	_, err = repo.GetDevice(ctx, uuid.NewV4(), deviceID)
+	if err = expect("get device of unknown event", err, database.ErrNotFound); err != nil {
+		return err
+	}
+
+	// devices are only found through their own event
+	other := database.Event{
+		ID: uuid.NewV4(), TenantID: uuid.NewV4(), Name: "Other",
+		Status: database.EventActive,
+	}
+	if err = repo.UpsertEvent(ctx, other); err != nil {
+		return err
+	}
+	_, err = repo.GetDevice(ctx, other.ID, deviceID)
+	if err = expect("get device through other event", err, database.ErrNotFound); err != nil {
+		return err
+	}
+
+	// devices of removed events are no longer available
+	event.Status = database.EventRemoved
+	if err = repo.UpsertEvent(ctx, event); err != nil {
+		return err
+	}
+	_, err = repo.GetDevice(ctx, event.ID, deviceID)
+	return expect("get device of removed event", err, database.ErrNotFound)
+}
+
+func cloneDevices(ctx context.Context, repo database.Repository, add AddDevice) error {
+	tenantID := uuid.NewV4()
+	from := database.Event{
+		ID: uuid.NewV4(), TenantID: tenantID, Name: "Original",
+		Status: database.EventActive,
+	}
+	to := database.Event{
+		ID: uuid.NewV4(), TenantID: tenantID, Name: "Copy",
+		Status: database.EventActive,
+	}
+	for _, event := range []database.Event{from, to} {
+		if err := repo.UpsertEvent(ctx, event); err != nil {
+			return err
+		}
+	}
+	deviceIDs := []uuid.UUID{uuid.NewV4(), uuid.NewV4()}
+	for idx, deviceID := range deviceIDs {
+		name := fmt.Sprintf("Door %d", idx+1)
+		if err := add(ctx, from.ID, deviceID, name, []byte(name)); err != nil {
+			return err
+		}
+	}
+
+	_, err := repo.CloneDevices(ctx, uuid.NewV4(), from.ID, to.ID)
+	if err = expect("clone devices of other tenant", err, database.ErrNotFound); err != nil {
+		return err
+	}
+
+	cnt, err := repo.CloneDevices(ctx, tenantID, from.ID, to.ID)
+	if err != nil {
+		return err
+	}
+	if cnt != len(deviceIDs) {
+		return fmt.Errorf("clone devices: have %d devices, want %d", cnt, len(deviceIDs))
+	}
+
+	// the source devices are left untouched
+	for _, deviceID := range deviceIDs {
+		if _, err = repo.GetDevice(ctx, from.ID, deviceID); err != nil {
+			return fmt.Errorf("get source device: %v", err)
+		}
+	}
+
+	// cloning an event without devices is not an error
+	empty := database.Event{
+		ID: uuid.NewV4(), TenantID: tenantID, Name: "Empty",
+		Status: database.EventActive,
+	}
+	if err = repo.UpsertEvent(ctx, empty); err != nil {
+		return err
+	}
+	cnt, err = repo.CloneDevices(ctx, tenantID, empty.ID, to.ID)
+	if err != nil {
+		return err
+	}
+	if cnt != 0 {
+		return fmt.Errorf("clone without devices: have %d devices, want 0", cnt)
+	}
+	return nil
+}
+
+// expect returns an error if err is not the wanted error.
+func expect(op string, err, want error) error {
+	if err != want {
+		return fmt.Errorf("%s: have error %v, want %v", op, err, want)
+	}
+	return nil
+}
+
+// listed returns an error if the event is not in the read model as provided.
+func listed(ctx context.Context, repo database.Repository, want database.Event) error {
+	events, err := repo.ListEvents(ctx)
+	if err != nil {
+		return err
+	}
+	for _, event := range events {
+		if uuid.Equal(event.ID, want.ID) {
+			if *event != want {
+				return fmt.Errorf("list events: have %+v, want %+v", *event, want)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("list events: event %s not found", want.ID)
+}
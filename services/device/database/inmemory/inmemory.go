@@ -0,0 +1,134 @@
+// Package inmemory implements a thread-safe device Repository holding its data
+// in memory. It is meant for tests of the layers above the repository and
+// for running the service without a database file.
+package inmemory
+
+import (
+	// stdlib
+	"bytes"
+	"context"
+	"sort"
+	"sync"
+
+	// external
+	"github.com/kevinburke/go.uuid"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/services/device/database"
+)
+
+type device struct {
+	eventID uuid.UUID
+	name    string
+	hash    []byte
+}
+
+// Repository is an in-memory database.Repository.
+type Repository struct {
+	mtx     sync.RWMutex
+	devices map[uuid.UUID]device
+	events  map[uuid.UUID]database.Event
+}
+
+// New returns a new empty in-memory Repository
+func New() *Repository {
+	return &Repository{
+		devices: make(map[uuid.UUID]device),
+		events:  make(map[uuid.UUID]database.Event),
+	}
+}
+
+// AddDevice stores a device for an event. Devices are provisioned outside of
+// the Repository interface, this allows seeding them.
+func (r *Repository) AddDevice(
+	_ context.Context, eventID, deviceID uuid.UUID, name string, hash []byte,
+) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if _, ok := r.devices[deviceID]; ok {
+		return database.ErrRepository
+	}
+	r.devices[deviceID] = device{
+		eventID: eventID,
+		name:    name,
+		hash:    append([]byte(nil), hash...),
+	}
+	return nil
+}
+
+// GetDevice implements database.Repository
+func (r *Repository) GetDevice(
+	_ context.Context, eventID, deviceID uuid.UUID,
+) (*database.Session, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	d, ok := r.devices[deviceID]
+	if !ok || !uuid.Equal(d.eventID, eventID) {
+		return nil, database.ErrNotFound
+	}
+	event, ok := r.events[eventID]
+	if !ok || event.Status != database.EventActive {
+		return nil, database.ErrNotFound
+	}
+
+	return &database.Session{
+		TenantID:      event.TenantID,
+		EventCaption:  event.Name,
+		DeviceCaption: d.name,
+		UnlockHash:    append([]byte(nil), d.hash...),
+	}, nil
+}
+
+// CloneDevices implements database.Repository
+func (r *Repository) CloneDevices(
+	_ context.Context, tenantID, fromEventID, toEventID uuid.UUID,
+) (int, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	event, ok := r.events[fromEventID]
+	if !ok || !uuid.Equal(event.TenantID, tenantID) {
+		return 0, database.ErrNotFound
+	}
+
+	var clones []device
+	for _, d := range r.devices {
+		if uuid.Equal(d.eventID, fromEventID) {
+			d.eventID = toEventID
+			clones = append(clones, d)
+		}
+	}
+	for _, d := range clones {
+		r.devices[uuid.NewV4()] = d
+	}
+
+	return len(clones), nil
+}
+
+// UpsertEvent implements database.Repository
+func (r *Repository) UpsertEvent(_ context.Context, event database.Event) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	r.events[event.ID] = event
+	return nil
+}
+
+// ListEvents implements database.Repository
+func (r *Repository) ListEvents(_ context.Context) ([]*database.Event, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	var events []*database.Event
+	for _, event := range r.events {
+		event := event
+		events = append(events, &event)
+	}
+	sort.Slice(events, func(i, j int) bool {
+		return bytes.Compare(events[i].ID.Bytes(), events[j].ID.Bytes()) < 0
+	})
+
+	return events, nil
+}
@@ -0,0 +1,14 @@
+package inmemory
+
+import (
+	// stdlib
+	"testing"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/services/device/database/databasetest"
+)
+
+func TestConformance(t *testing.T) {
+	repo := New()
+	databasetest.Run(t, repo, repo.AddDevice)
+}
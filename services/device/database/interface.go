@@ -18,11 +18,37 @@ var (
 // Repository describes the resource methods needed for this service.
 type Repository interface {
 	GetDevice(ctx context.Context, eventID, deviceID uuid.UUID) (*Session, error)
+	// CloneDevices copies the devices of an event owned by the tenant to
+	// another event, including their unlock codes. It returns ErrNotFound if
+	// the tenant does not own the source event.
+	CloneDevices(ctx context.Context, tenantID, fromEventID, toEventID uuid.UUID) (int, error)
+
+	// Our local read model of the events owned by the event service.
+	UpsertEvent(ctx context.Context, event Event) error
+	ListEvents(ctx context.Context) ([]*Event, error)
 }
 
+// EventStatus describes the state of an event in our local read model.
+type EventStatus string
+
+// Available EventStatus values
+const (
+	EventActive  EventStatus = "active"
+	EventRemoved EventStatus = "removed"
+)
+
 // Session holds session details
 type Session struct {
+	TenantID      uuid.UUID
 	EventCaption  string
 	DeviceCaption string
 	UnlockHash    []byte
 }
+
+// Event holds the details of an event as replicated from the event service.
+type Event struct {
+	ID       uuid.UUID
+	TenantID uuid.UUID
+	Name     string
+	Status   EventStatus
+}
@@ -0,0 +1,191 @@
+package postgres
+
+import (
+	// stdlib
+	"context"
+	"database/sql"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/jmoiron/sqlx"
+	"github.com/kevinburke/go.uuid"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/services/device/database"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/migrate"
+)
+
+type postgres struct {
+	db     *sqlx.DB
+	logger log.Logger
+}
+
+// New returns a new Repository backed by PostgreSQL
+func New(db *sqlx.DB, logger log.Logger) (database.Repository, error) {
+	// run our embedded database migrations
+	if err := migrate.Up(
+		context.Background(), db, migrate.Postgres, Schema, logger,
+	); err != nil {
+		return nil, err
+	}
+
+	// return our repository
+	return &postgres{
+		db:     db,
+		logger: log.With(logger, "rep", "postgres"),
+	}, nil
+}
+
+// GetDevice retrieves device information
+func (s *postgres) GetDevice(ctx context.Context, eventID, deviceID uuid.UUID) (*database.Session, error) {
+	var session = &database.Session{}
+
+	if err := s.db.QueryRowContext(
+		ctx,
+		`
+		SELECT e.tenant_id, e.name as event_caption, d.name as device_caption, d.hash
+	    FROM device_event e INNER JOIN device d ON e.id = d.event_id
+	    WHERE d.event_id = $1 AND d.id = $2 AND e.status = $3;
+	  	`,
+		eventID, deviceID, database.EventActive,
+	).Scan(
+		&session.TenantID, &session.EventCaption, &session.DeviceCaption,
+		&session.UnlockHash,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, database.ErrNotFound
+		}
+		level.Error(s.logger).Log("err", err.Error())
+		return nil, database.ErrRepository
+	}
+
+	return session, nil
+}
+
+// CloneDevices copies the devices of an event to another event
+func (s *postgres) CloneDevices(
+	ctx context.Context, tenantID, fromEventID, toEventID uuid.UUID,
+) (int, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		level.Error(s.logger).Log("err", err.Error())
+		return 0, database.ErrRepository
+	}
+	defer tx.Rollback()
+
+	var found int
+	if err = tx.QueryRowContext(
+		ctx,
+		`SELECT count(*) FROM device_event WHERE id = $1 AND tenant_id = $2;`,
+		fromEventID, tenantID,
+	).Scan(&found); err != nil {
+		level.Error(s.logger).Log("err", err.Error())
+		return 0, database.ErrRepository
+	}
+	if found == 0 {
+		return 0, database.ErrNotFound
+	}
+
+	rows, err := tx.QueryContext(
+		ctx,
+		`SELECT name, hash FROM device WHERE event_id = $1;`,
+		fromEventID,
+	)
+	if err != nil {
+		level.Error(s.logger).Log("err", err.Error())
+		return 0, database.ErrRepository
+	}
+	type device struct {
+		name string
+		hash []byte
+	}
+	var devices []device
+	for rows.Next() {
+		var d device
+		if err = rows.Scan(&d.name, &d.hash); err != nil {
+			rows.Close()
+			level.Error(s.logger).Log("err", err.Error())
+			return 0, database.ErrRepository
+		}
+		devices = append(devices, d)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		level.Error(s.logger).Log("err", err.Error())
+		return 0, database.ErrRepository
+	}
+
+	for _, d := range devices {
+		if _, err = tx.ExecContext(
+			ctx,
+			`INSERT INTO device (id, event_id, name, hash) VALUES ($1, $2, $3, $4);`,
+			uuid.NewV4(), toEventID, d.name, d.hash,
+		); err != nil {
+			level.Error(s.logger).Log("err", err.Error())
+			return 0, database.ErrRepository
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		level.Error(s.logger).Log("err", err.Error())
+		return 0, database.ErrRepository
+	}
+	return len(devices), nil
+}
+
+// UpsertEvent stores or updates an event in our local read model
+func (s *postgres) UpsertEvent(ctx context.Context, event database.Event) error {
+	if _, err := s.db.ExecContext(
+		ctx,
+		`
+		INSERT INTO device_event (id, tenant_id, name, status)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (id) DO UPDATE SET
+		  tenant_id = excluded.tenant_id, name = excluded.name,
+		  status = excluded.status;
+		`,
+		event.ID, event.TenantID, event.Name, event.Status,
+	); err != nil {
+		level.Error(s.logger).Log("err", err.Error())
+		return database.ErrRepository
+	}
+
+	return nil
+}
+
+// ListEvents returns all events from our local read model
+func (s *postgres) ListEvents(ctx context.Context) ([]*database.Event, error) {
+	rows, err := s.db.QueryContext(
+		ctx,
+		`SELECT id, tenant_id, name, status FROM device_event;`,
+	)
+	if err != nil {
+		level.Error(s.logger).Log("err", err.Error())
+		return nil, database.ErrRepository
+	}
+	defer rows.Close()
+
+	var events []*database.Event
+	for rows.Next() {
+		event := &database.Event{}
+		if err = rows.Scan(
+			&event.ID, &event.TenantID, &event.Name, &event.Status,
+		); err != nil {
+			level.Error(s.logger).Log("err", err.Error())
+			return nil, database.ErrRepository
+		}
+		events = append(events, event)
+	}
+	if err = rows.Err(); err != nil {
+		level.Error(s.logger).Log("err", err.Error())
+		return nil, database.ErrRepository
+	}
+
+	return events, nil
+}
+
+// Close implements io.Closer
+func (s *postgres) Close() error {
+	return s.db.Close()
+}
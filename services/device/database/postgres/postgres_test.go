@@ -0,0 +1,53 @@
+package postgres
+
+import (
+	// stdlib
+	"context"
+	"os"
+	"testing"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/jmoiron/sqlx"
+	"github.com/kevinburke/go.uuid"
+	_ "github.com/lib/pq"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/services/device/database/databasetest"
+)
+
+// TestConformance runs the Repository conformance checks against the
+// PostgreSQL database in OCG_POSTGRES_DSN. The checks create their own
+// tenants, events and devices, only point it to a scratch database.
+func TestConformance(t *testing.T) {
+	dsn := os.Getenv("OCG_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("OCG_POSTGRES_DSN not set")
+	}
+
+	db, err := sqlx.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// repository logging is silenced as the checks trigger errors on purpose
+	repo, err := New(db, log.NewNopLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	databasetest.Run(t, repo, addDevice(db))
+}
+
+// addDevice adds devices to the device table.
+func addDevice(db *sqlx.DB) databasetest.AddDevice {
+	return func(ctx context.Context, eventID, deviceID uuid.UUID, name string, hash []byte) error {
+		_, err := db.ExecContext(
+			ctx,
+			`INSERT INTO device (id, event_id, name, hash) VALUES ($1, $2, $3, $4);`,
+			deviceID, eventID, name, hash,
+		)
+		return err
+	}
+}
@@ -0,0 +1,59 @@
+package postgres
+
+import (
+	// external
+	"github.com/jmoiron/sqlx"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/shared/migrate"
+)
+
+// Schema holds the device schema migrations. The versions mirror the SQLite
+// migrations so both backends report the same schema version.
+var Schema = migrate.Schema{
+	Name: "ocgokitexample.device",
+	Migrations: []migrate.Migration{
+		{
+			Version: 1, Description: "add device table",
+			Creates: "device", Up: v1,
+		},
+		{
+			Version: 2, Description: "add event read model",
+			Creates: "device_event", Up: v2, Down: v2Down,
+		},
+	},
+}
+
+func v1(tx *sqlx.Tx) (err error) {
+	// add device table
+	if _, err = tx.Exec(`
+    CREATE TABLE device (
+      id UUID NOT NULL, event_id UUID NOT NULL, name TEXT NOT NULL,
+      hash BYTEA NOT NULL, CONSTRAINT device_pkey PRIMARY KEY (id)
+    );
+  `); err != nil {
+		return
+	}
+
+	return nil
+}
+
+func v2(tx *sqlx.Tx) (err error) {
+	// add our read model of the event service's events; the table is prefixed
+	// as the event service may share the database
+	if _, err = tx.Exec(`
+    CREATE TABLE device_event (
+      id UUID NOT NULL, tenant_id UUID NOT NULL, name TEXT NOT NULL,
+      status TEXT NOT NULL, CONSTRAINT device_event_pkey PRIMARY KEY (id)
+    );
+  `); err != nil {
+		return
+	}
+
+	return nil
+}
+
+func v2Down(tx *sqlx.Tx) (err error) {
+	_, err = tx.Exec(`DROP TABLE device_event;`)
+	return
+}
@@ -3,8 +3,26 @@ package sqlite
 import (
 	// external
 	"github.com/jmoiron/sqlx"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/shared/migrate"
 )
 
+// Schema holds the device schema migrations.
+var Schema = migrate.Schema{
+	Name: "ocgokitexample.device",
+	Migrations: []migrate.Migration{
+		{
+			Version: 1, Description: "add device table",
+			Creates: "device", Up: v1,
+		},
+		{
+			Version: 2, Description: "add event read model",
+			Creates: "device_event", Up: v2, Down: v2Down,
+		},
+	},
+}
+
 func v1(tx *sqlx.Tx) (err error) {
 	// add device table
 	if _, err = tx.Exec(`
@@ -18,3 +36,23 @@ func v1(tx *sqlx.Tx) (err error) {
 
 	return nil
 }
+
+func v2(tx *sqlx.Tx) (err error) {
+	// add our read model of the event service's events; the table is prefixed
+	// as the elegant monolith shares its database with the event service
+	if _, err = tx.Exec(`
+    CREATE TABLE device_event (
+      id BLOB NOT NULL, tenant_id BLOB NOT NULL, name TEXT NOT NULL,
+      status TEXT NOT NULL, PRIMARY KEY(id)
+    ) WITHOUT ROWID;
+  `); err != nil {
+		return
+	}
+
+	return nil
+}
+
+func v2Down(tx *sqlx.Tx) (err error) {
+	_, err = tx.Exec(`DROP TABLE device_event;`)
+	return
+}
@@ -0,0 +1,53 @@
+package sqlite
+
+import (
+	// stdlib
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/jmoiron/sqlx"
+	"github.com/kevinburke/go.uuid"
+	_ "github.com/mattn/go-sqlite3"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/services/device/database/databasetest"
+)
+
+func TestConformance(t *testing.T) {
+	dir, err := ioutil.TempDir("", "device")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	db, err := sqlx.Open("sqlite3", filepath.Join(dir, "device.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// repository logging is silenced as the checks trigger errors on purpose
+	repo, err := New(db, log.NewNopLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	databasetest.Run(t, repo, addDevice(db))
+}
+
+// addDevice adds devices to the device table.
+func addDevice(db *sqlx.DB) databasetest.AddDevice {
+	return func(ctx context.Context, eventID, deviceID uuid.UUID, name string, hash []byte) error {
+		_, err := db.ExecContext(
+			ctx,
+			`INSERT INTO device (id, event_id, name, hash) VALUES (?1, ?2, ?3, ?4);`,
+			deviceID.Bytes(), eventID.Bytes(), name, hash,
+		)
+		return err
+	}
+}
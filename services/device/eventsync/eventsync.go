@@ -0,0 +1,114 @@
+// Package eventsync keeps the device service's local read model of events in
+// sync with the event service, which owns the event data.
+package eventsync
+
+import (
+	// stdlib
+	"context"
+	"time"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/kevinburke/go.uuid"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/services/device/database"
+	"github.com/basvanbeek/opencensus-gokit-example/services/event"
+)
+
+// Reconciler periodically pulls all events from the event service and
+// updates the device service's read model accordingly. Events which no longer
+// exist at the event service are kept but marked as removed.
+type Reconciler struct {
+	evtClient  event.Service
+	repository database.Repository
+	logger     log.Logger
+}
+
+// NewReconciler returns a new event read model Reconciler.
+func NewReconciler(
+	evtClient event.Service, rep database.Repository, logger log.Logger,
+) *Reconciler {
+	return &Reconciler{
+		evtClient:  evtClient,
+		repository: rep,
+		logger:     log.With(logger, "component", "eventsync"),
+	}
+}
+
+// Reconcile runs a single synchronization pass and returns the amount of
+// events updated and removed in our read model.
+func (r *Reconciler) Reconcile(ctx context.Context) (updated, removed int, err error) {
+	// a nil tenant ID lists the events of all tenants
+	events, err := r.evtClient.List(ctx, uuid.Nil)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	local, err := r.repository.ListEvents(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	known := make(map[uuid.UUID]*database.Event, len(local))
+	for _, evt := range local {
+		known[evt.ID] = evt
+	}
+
+	for _, evt := range events {
+		current := database.Event{
+			ID:       evt.ID,
+			TenantID: evt.TenantID,
+			Name:     evt.Name,
+			Status:   database.EventActive,
+		}
+		if prev, ok := known[evt.ID]; ok {
+			delete(known, evt.ID)
+			if *prev == current {
+				continue
+			}
+		}
+		if err = r.repository.UpsertEvent(ctx, current); err != nil {
+			return updated, removed, err
+		}
+		updated++
+	}
+
+	// whatever is left in known is no longer served by the event service
+	for _, evt := range known {
+		if evt.Status == database.EventRemoved {
+			continue
+		}
+		evt.Status = database.EventRemoved
+		if err = r.repository.UpsertEvent(ctx, *evt); err != nil {
+			return updated, removed, err
+		}
+		removed++
+	}
+
+	return updated, removed, nil
+}
+
+// Run reconciles our read model every interval until the provided context is
+// canceled. Failing passes are logged and retried on the next interval.
+func (r *Reconciler) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		updated, removed, err := r.Reconcile(ctx)
+		if err != nil {
+			level.Warn(r.logger).Log("msg", "reconciliation failed", "err", err)
+		} else if updated > 0 || removed > 0 {
+			level.Debug(r.logger).Log(
+				"msg", "reconciled events", "updated", updated, "removed", removed,
+			)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package implementation
+
+import (
+	// stdlib
+	"context"
+	"encoding/json"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/kevinburke/go.uuid"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/services/device"
+	"github.com/basvanbeek/opencensus-gokit-example/services/webhook"
+)
+
+type notifier struct {
+	device.Service
+	publisher webhook.Publisher
+	logger    log.Logger
+}
+
+// NotifyMiddleware publishes webhook notifications for successful device
+// unlocks. Failing to publish is logged but does not fail the unlock.
+func NotifyMiddleware(publisher webhook.Publisher, logger log.Logger) device.Middleware {
+	return func(next device.Service) device.Service {
+		return &notifier{
+			Service:   next,
+			publisher: publisher,
+			logger:    log.With(logger, "middleware", "notify"),
+		}
+	}
+}
+
+func (n *notifier) Unlock(
+	ctx context.Context, eventID, deviceID uuid.UUID, code string,
+) (*device.Session, error) {
+	session, err := n.Service.Unlock(ctx, eventID, deviceID, code)
+	if err != nil {
+		return session, err
+	}
+
+	payload, err := json.Marshal(struct {
+		EventID       uuid.UUID `json:"event_id"`
+		DeviceID      uuid.UUID `json:"device_id"`
+		EventCaption  string    `json:"event_caption"`
+		DeviceCaption string    `json:"device_caption"`
+	}{
+		EventID:       eventID,
+		DeviceID:      deviceID,
+		EventCaption:  session.EventCaption,
+		DeviceCaption: session.DeviceCaption,
+	})
+	if err == nil {
+		err = n.publisher.Publish(ctx, session.TenantID, webhook.Notification{
+			Type:    webhook.DeviceUnlocked,
+			Payload: payload,
+		})
+	}
+	if err != nil {
+		level.Warn(n.logger).Log("type", webhook.DeviceUnlocked, "err", err)
+	}
+	return session, nil
+}
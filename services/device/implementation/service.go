@@ -3,7 +3,6 @@ package implementation
 import (
 	// stdlib
 	"context"
-	"database/sql"
 
 	// external
 	"github.com/go-kit/kit/log"
@@ -38,7 +37,7 @@ func (s *service) Unlock(
 
 	details, err := s.repository.GetDevice(ctx, eventID, deviceID)
 	if err != nil {
-		if err != sql.ErrNoRows {
+		if err != database.ErrNotFound {
 			level.Error(logger).Log("err", err)
 			return nil, device.ErrRepository
 		}
@@ -53,7 +52,63 @@ func (s *service) Unlock(
 	}
 
 	return &device.Session{
+		TenantID:      details.TenantID,
 		EventCaption:  details.EventCaption,
 		DeviceCaption: details.DeviceCaption,
 	}, nil
 }
+
+// CloneDevices copies the devices of an event to a cloned event. The clone
+// does not need to be present in our event read model yet.
+func (s *service) CloneDevices(
+	ctx context.Context, tenantID, fromEventID, toEventID uuid.UUID,
+) (int, error) {
+	logger := log.With(s.logger, "method", "CloneDevices")
+
+	switch {
+	case uuid.Equal(tenantID, uuid.Nil):
+		return 0, device.ErrRequireTenantID
+	case uuid.Equal(fromEventID, uuid.Nil), uuid.Equal(toEventID, uuid.Nil):
+		return 0, device.ErrRequireEventID
+	}
+
+	count, err := s.repository.CloneDevices(ctx, tenantID, fromEventID, toEventID)
+	switch err {
+	case nil:
+		return count, nil
+	case database.ErrNotFound:
+		level.Debug(logger).Log("err", err)
+		return 0, device.ErrEventNotFound
+	default:
+		level.Error(logger).Log("err", err)
+		return 0, device.ErrRepository
+	}
+}
+
+// DeviceTenant returns the tenant owning the device of an event. Unlike
+// Unlock it doesn't need the unlock code, allowing callers to enforce quotas
+// on requests about a device.
+func (s *service) DeviceTenant(
+	ctx context.Context, eventID, deviceID uuid.UUID,
+) (uuid.UUID, error) {
+	logger := log.With(s.logger, "method", "DeviceTenant")
+
+	switch {
+	case uuid.Equal(eventID, uuid.Nil):
+		return uuid.Nil, device.ErrRequireEventID
+	case uuid.Equal(deviceID, uuid.Nil):
+		return uuid.Nil, device.ErrRequireDeviceID
+	}
+
+	details, err := s.repository.GetDevice(ctx, eventID, deviceID)
+	switch err {
+	case nil:
+		return details.TenantID, nil
+	case database.ErrNotFound:
+		level.Debug(logger).Log("err", err)
+		return uuid.Nil, device.ErrDeviceNotFound
+	default:
+		level.Error(logger).Log("err", err)
+		return uuid.Nil, device.ErrRepository
+	}
+}
@@ -0,0 +1,58 @@
+package implementation
+
+import (
+	// stdlib
+	"context"
+	"testing"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/kevinburke/go.uuid"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/services/device"
+	"github.com/basvanbeek/opencensus-gokit-example/services/device/database"
+	"github.com/basvanbeek/opencensus-gokit-example/services/device/database/inmemory"
+)
+
+func TestDeviceTenant(t *testing.T) {
+	var (
+		ctx      = context.Background()
+		repo     = inmemory.New()
+		svc      = NewService(repo, log.NewNopLogger())
+		tenantID = uuid.NewV4()
+		eventID  = uuid.NewV4()
+		deviceID = uuid.NewV4()
+	)
+	if err := repo.UpsertEvent(ctx, database.Event{
+		ID: eventID, TenantID: tenantID, Name: "Meetup", Status: database.EventActive,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.AddDevice(ctx, eventID, deviceID, "Entrance", []byte("unlock-hash")); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name              string
+		eventID, deviceID uuid.UUID
+		tenantID          uuid.UUID
+		err               error
+	}{
+		{"known device", eventID, deviceID, tenantID, nil},
+		{"unknown device", eventID, uuid.NewV4(), uuid.Nil, device.ErrDeviceNotFound},
+		{"unknown event", uuid.NewV4(), deviceID, uuid.Nil, device.ErrDeviceNotFound},
+		{"missing event", uuid.Nil, deviceID, uuid.Nil, device.ErrRequireEventID},
+		{"missing device", eventID, uuid.Nil, uuid.Nil, device.ErrRequireDeviceID},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			have, err := svc.DeviceTenant(ctx, tc.eventID, tc.deviceID)
+			if err != tc.err {
+				t.Fatalf("want error %v, have %v", tc.err, err)
+			}
+			if !uuid.Equal(have, tc.tenantID) {
+				t.Errorf("want tenant %s, have %s", tc.tenantID, have)
+			}
+		})
+	}
+}
@@ -3,10 +3,12 @@ package device
 import (
 	// stdlib
 	"context"
-	"errors"
 
 	// external
 	"github.com/kevinburke/go.uuid"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/shared/errcode"
 )
 
 // ServiceName of this service.
@@ -15,8 +17,13 @@ const ServiceName = "device"
 // Service describes our Device service.
 type Service interface {
 	Unlock(ctx context.Context, eventID, deviceID uuid.UUID, code string) (*Session, error)
+	CloneDevices(ctx context.Context, tenantID, fromEventID, toEventID uuid.UUID) (int, error)
+	DeviceTenant(ctx context.Context, eventID, deviceID uuid.UUID) (uuid.UUID, error)
 }
 
+// Middleware describes a service middleware.
+type Middleware func(Service) Service
+
 // Device Service Error descriptions
 const (
 	ErrorRequireEventID    = "missing required event id"
@@ -25,21 +32,28 @@ const (
 	ErrorRepository        = "unable to query repository"
 	ErrorEventNotFound     = "event not found"
 	ErrorUnlockNotFound    = "device / unlock code combination not found"
+	ErrorRequireTenantID   = "missing required tenant id"
+	ErrorQuotaExceeded     = "quota exceeded"
+	ErrorDeviceNotFound    = "device not found"
 )
 
 // Device Service Errors
 var (
-	ErrRequireEventID    = errors.New(ErrorRequireEventID)
-	ErrRequireDeviceID   = errors.New(ErrorRequireDeviceID)
-	ErrRequireUnlockCode = errors.New(ErrorRequireUnlockCode)
-	ErrRepository        = errors.New(ErrorRepository)
-	ErrEventNotFound     = errors.New(ErrorEventNotFound)
-	ErrUnlockNotFound    = errors.New(ErrorUnlockNotFound)
+	ErrRequireEventID    = errcode.New("device.require_event_id", errcode.InvalidArgument, ErrorRequireEventID)
+	ErrRequireDeviceID   = errcode.New("device.require_device_id", errcode.InvalidArgument, ErrorRequireDeviceID)
+	ErrRequireUnlockCode = errcode.New("device.require_unlock_code", errcode.InvalidArgument, ErrorRequireUnlockCode)
+	ErrRepository        = errcode.New("device.repository", errcode.Internal, ErrorRepository)
+	ErrEventNotFound     = errcode.New("device.event_not_found", errcode.NotFound, ErrorEventNotFound)
+	ErrUnlockNotFound    = errcode.New("device.unlock_not_found", errcode.Unauthenticated, ErrorUnlockNotFound)
+	ErrRequireTenantID   = errcode.New("device.require_tenant_id", errcode.InvalidArgument, ErrorRequireTenantID)
+	ErrQuotaExceeded     = errcode.New("device.quota_exceeded", errcode.ResourceExhausted, ErrorQuotaExceeded)
+	ErrDeviceNotFound    = errcode.New("device.device_not_found", errcode.NotFound, ErrorDeviceNotFound)
 )
 
 // Session holds session details
 type Session struct {
-	EventCaption  string `json:"event_caption,omitempty"`
-	DeviceCaption string `json:"device_caption,omitempty"`
-	Token         string `json:"token,omitempty"`
+	TenantID      uuid.UUID `json:"-"`
+	EventCaption  string    `json:"event_caption,omitempty"`
+	DeviceCaption string    `json:"device_caption,omitempty"`
+	Token         string    `json:"token,omitempty"`
 }
@@ -13,13 +13,17 @@ import (
 
 // Endpoints holds all Go kit endpoints for the service.
 type Endpoints struct {
-	Unlock endpoint.Endpoint
+	Unlock       endpoint.Endpoint
+	CloneDevices endpoint.Endpoint
+	DeviceTenant endpoint.Endpoint
 }
 
 // MakeEndpoints initializes all Go kit endpoints for the service.
 func MakeEndpoints(s device.Service) Endpoints {
 	return Endpoints{
-		Unlock: makeUnlockEndpoint(s),
+		Unlock:       makeUnlockEndpoint(s),
+		CloneDevices: makeCloneDevicesEndpoint(s),
+		DeviceTenant: makeDeviceTenantEndpoint(s),
 	}
 }
 
@@ -30,6 +34,26 @@ func makeUnlockEndpoint(s device.Service) endpoint.Endpoint {
 		if err != nil {
 			return nil, err
 		}
-		return UnlockResponse{EventCaption: res.DeviceCaption, DeviceCaption: res.DeviceCaption}, nil
+		return UnlockResponse{
+			TenantID:      res.TenantID,
+			EventCaption:  res.EventCaption,
+			DeviceCaption: res.DeviceCaption,
+		}, nil
+	}
+}
+
+func makeCloneDevicesEndpoint(s device.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(CloneDevicesRequest)
+		count, err := s.CloneDevices(ctx, req.TenantID, req.FromEventID, req.ToEventID)
+		return CloneDevicesResponse{Count: count, Err: err}, nil
+	}
+}
+
+func makeDeviceTenantEndpoint(s device.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(DeviceTenantRequest)
+		tenantID, err := s.DeviceTenant(ctx, req.EventID, req.DeviceID)
+		return DeviceTenantResponse{TenantID: tenantID, Err: err}, nil
 	}
 }
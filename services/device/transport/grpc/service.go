@@ -9,19 +9,19 @@ import (
 	kitgrpc "github.com/go-kit/kit/transport/grpc"
 	"github.com/kevinburke/go.uuid"
 	oldcontext "golang.org/x/net/context"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 
 	// project
-	"github.com/basvanbeek/opencensus-gokit-example/services/device"
 	"github.com/basvanbeek/opencensus-gokit-example/services/device/transport"
 	"github.com/basvanbeek/opencensus-gokit-example/services/device/transport/pb"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/errcode"
 )
 
 // grpc transport service for QR service.
 type grpcServer struct {
-	unlock kitgrpc.Handler
-	logger log.Logger
+	unlock       kitgrpc.Handler
+	cloneDevices kitgrpc.Handler
+	deviceTenant kitgrpc.Handler
+	logger       log.Logger
 }
 
 // NewService returns a new gRPC service for the provided Go kit endpoints
@@ -39,6 +39,14 @@ func NewService(
 		unlock: kitgrpc.NewServer(
 			endpoints.Unlock, decodeUnlockRequest, encodeUnlockResponse, options...,
 		),
+		cloneDevices: kitgrpc.NewServer(
+			endpoints.CloneDevices, decodeCloneDevicesRequest,
+			encodeCloneDevicesResponse, options...,
+		),
+		deviceTenant: kitgrpc.NewServer(
+			endpoints.DeviceTenant, decodeDeviceTenantRequest,
+			encodeDeviceTenantResponse, options...,
+		),
 		logger: logger,
 	}
 }
@@ -47,7 +55,7 @@ func NewService(
 func (s *grpcServer) Unlock(ctx oldcontext.Context, req *pb.UnlockRequest) (*pb.UnlockResponse, error) {
 	_, rep, err := s.unlock.ServeGRPC(ctx, req)
 	if err != nil {
-		return nil, err
+		return nil, errcode.GRPCError(err)
 	}
 	return rep.(*pb.UnlockResponse), nil
 }
@@ -65,17 +73,71 @@ func decodeUnlockRequest(_ context.Context, request interface{}) (interface{}, e
 // encodeUnlockResponse encodes the outgoing go kit payload to the grpc payload
 func encodeUnlockResponse(_ context.Context, response interface{}) (interface{}, error) {
 	res := response.(transport.UnlockResponse)
-	switch res.Err {
-	case nil:
-		return &pb.UnlockResponse{
-			EventCaption:  res.EventCaption,
-			DeviceCaption: res.DeviceCaption,
-		}, nil
-	case device.ErrRequireEventID, device.ErrRequireDeviceID, device.ErrRequireUnlockCode:
-		return nil, status.Error(codes.InvalidArgument, res.Err.Error())
-	case device.ErrEventNotFound, device.ErrUnlockNotFound:
-		return nil, status.Error(codes.Unauthenticated, res.Err.Error())
-	default:
-		return nil, status.Error(codes.Unknown, res.Err.Error())
+	if res.Err != nil {
+		return nil, errcode.GRPCError(res.Err)
+	}
+	return &pb.UnlockResponse{
+		EventCaption:  res.EventCaption,
+		DeviceCaption: res.DeviceCaption,
+		TenantId:      res.TenantID.Bytes(),
+	}, nil
+}
+
+// CloneDevices glues the gRPC method to the Go kit service method
+func (s *grpcServer) CloneDevices(ctx oldcontext.Context, req *pb.CloneDevicesRequest) (*pb.CloneDevicesResponse, error) {
+	_, rep, err := s.cloneDevices.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, errcode.GRPCError(err)
+	}
+	return rep.(*pb.CloneDevicesResponse), nil
+}
+
+// decodeCloneDevicesRequest decodes the incoming grpc payload to our go kit
+// payload
+func decodeCloneDevicesRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(*pb.CloneDevicesRequest)
+	return transport.CloneDevicesRequest{
+		TenantID:    uuid.FromBytesOrNil(req.TenantId),
+		FromEventID: uuid.FromBytesOrNil(req.FromEventId),
+		ToEventID:   uuid.FromBytesOrNil(req.ToEventId),
+	}, nil
+}
+
+// encodeCloneDevicesResponse encodes the outgoing go kit payload to the grpc
+// payload
+func encodeCloneDevicesResponse(_ context.Context, response interface{}) (interface{}, error) {
+	res := response.(transport.CloneDevicesResponse)
+	if res.Err != nil {
+		return nil, errcode.GRPCError(res.Err)
+	}
+	return &pb.CloneDevicesResponse{Count: int32(res.Count)}, nil
+}
+
+// DeviceTenant glues the gRPC method to the Go kit service method
+func (s *grpcServer) DeviceTenant(ctx oldcontext.Context, req *pb.DeviceTenantRequest) (*pb.DeviceTenantResponse, error) {
+	_, rep, err := s.deviceTenant.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, errcode.GRPCError(err)
+	}
+	return rep.(*pb.DeviceTenantResponse), nil
+}
+
+// decodeDeviceTenantRequest decodes the incoming grpc payload to our go kit
+// payload
+func decodeDeviceTenantRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(*pb.DeviceTenantRequest)
+	return transport.DeviceTenantRequest{
+		EventID:  uuid.FromBytesOrNil(req.EventId),
+		DeviceID: uuid.FromBytesOrNil(req.DeviceId),
+	}, nil
+}
+
+// encodeDeviceTenantResponse encodes the outgoing go kit payload to the grpc
+// payload
+func encodeDeviceTenantResponse(_ context.Context, response interface{}) (interface{}, error) {
+	res := response.(transport.DeviceTenantResponse)
+	if res.Err != nil {
+		return nil, errcode.GRPCError(res.Err)
 	}
+	return &pb.DeviceTenantResponse{TenantId: res.TenantID.Bytes()}, nil
 }
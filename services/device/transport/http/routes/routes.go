@@ -7,7 +7,9 @@ import (
 
 // Endpoints holds all available HTTP endpoints for our service.
 type Endpoints struct {
-	Unlock *mux.Route
+	Unlock       *mux.Route
+	CloneDevices *mux.Route
+	DeviceTenant *mux.Route
 }
 
 // Initialize wires the HTTP endpoints to our Go kit service endpoints.
@@ -18,5 +20,13 @@ func Initialize(router *mux.Router) Endpoints {
 			Path("/unlock/{event_id}/{device_id}").
 			Queries("code", "{code}").
 			Name("unlock"),
+		CloneDevices: router.
+			Methods("POST").
+			Path("/devices/clone").
+			Name("clone_devices"),
+		DeviceTenant: router.
+			Methods("GET").
+			Path("/tenant/{event_id}/{device_id}").
+			Name("device_tenant"),
 	}
 }
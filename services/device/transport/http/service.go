@@ -7,15 +7,16 @@ import (
 	"net/http"
 
 	// external
-
+	"github.com/go-kit/kit/endpoint"
 	"github.com/go-kit/kit/log"
 	kithttp "github.com/go-kit/kit/transport/http"
 	"github.com/gorilla/mux"
+	"github.com/kevinburke/go.uuid"
 
 	// project
-	"github.com/basvanbeek/opencensus-gokit-example/services/device"
 	"github.com/basvanbeek/opencensus-gokit-example/services/device/transport"
 	"github.com/basvanbeek/opencensus-gokit-example/services/device/transport/http/routes"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/problem"
 )
 
 // NewService wires our Go kit endpoints to the HTTP transport.
@@ -28,10 +29,12 @@ func NewService(
 		router       = mux.NewRouter()
 		route        = routes.Initialize(router)
 		errorLogger  = kithttp.ServerErrorLogger(logger)
-		errorEncoder = kithttp.ServerErrorEncoder(encodeErrorResponse)
+		errorEncoder = kithttp.ServerErrorEncoder(problem.EncodeError)
 	)
 
 	options = append(options, errorLogger, errorEncoder)
+	router.NotFoundHandler = problem.NotFoundHandler()
+	router.MethodNotAllowedHandler = problem.MethodNotAllowedHandler()
 
 	// wire our Go kit handlers to the http endpoints
 	route.Unlock.Handler(kithttp.NewServer(
@@ -39,6 +42,16 @@ func NewService(
 		options...,
 	))
 
+	route.CloneDevices.Handler(kithttp.NewServer(
+		svcEndpoints.CloneDevices, decodeCloneDevicesRequest,
+		encodeCloneDevicesResponse, options...,
+	))
+
+	route.DeviceTenant.Handler(kithttp.NewServer(
+		svcEndpoints.DeviceTenant, decodeDeviceTenantRequest,
+		encodeDeviceTenantResponse, options...,
+	))
+
 	// return our router as http handler
 	return router
 }
@@ -47,25 +60,64 @@ func NewService(
 // Go kit request_response payloads.
 
 func decodeUnlockRequest(_ context.Context, r *http.Request) (interface{}, error) {
-	var req transport.UnlockRequest
-	err := json.NewDecoder(r.Body).Decode(&req)
-	return req, err
+	var (
+		err  error
+		req  transport.UnlockRequest
+		vars = mux.Vars(r)
+	)
+	if req.EventID, err = uuid.FromString(vars["event_id"]); err != nil {
+		return nil, problem.InvalidField("event_id", err)
+	}
+	if req.DeviceID, err = uuid.FromString(vars["device_id"]); err != nil {
+		return nil, problem.InvalidField("device_id", err)
+	}
+	req.Code = vars["code"]
+	return req, nil
 }
 
 func encodeUnlockResponse(_ context.Context, w http.ResponseWriter, response interface{}) error {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := response.(endpoint.Failer).Failed(); err != nil {
+		return err
+	}
+	return json.NewEncoder(w).Encode(response)
+}
+
+func decodeCloneDevicesRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req transport.CloneDevicesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, problem.InvalidField("body", err)
+	}
+	return req, nil
+}
+
+func encodeCloneDevicesResponse(_ context.Context, w http.ResponseWriter, response interface{}) error {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := response.(endpoint.Failer).Failed(); err != nil {
+		return err
+	}
 	return json.NewEncoder(w).Encode(response)
 }
 
-func encodeErrorResponse(_ context.Context, err error, w http.ResponseWriter) {
-	var code int
-	switch err {
-	case device.ErrRequireEventID, device.ErrRequireDeviceID, device.ErrRequireUnlockCode:
-		code = http.StatusBadRequest
-	case device.ErrEventNotFound, device.ErrUnlockNotFound:
-		code = http.StatusUnauthorized
-	default:
-		code = http.StatusInternalServerError
+func decodeDeviceTenantRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var (
+		err  error
+		req  transport.DeviceTenantRequest
+		vars = mux.Vars(r)
+	)
+	if req.EventID, err = uuid.FromString(vars["event_id"]); err != nil {
+		return nil, problem.InvalidField("event_id", err)
 	}
-	http.Error(w, err.Error(), code)
+	if req.DeviceID, err = uuid.FromString(vars["device_id"]); err != nil {
+		return nil, problem.InvalidField("device_id", err)
+	}
+	return req, nil
+}
+
+func encodeDeviceTenantResponse(_ context.Context, w http.ResponseWriter, response interface{}) error {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := response.(endpoint.Failer).Failed(); err != nil {
+		return err
+	}
+	return json.NewEncoder(w).Encode(response)
 }
@@ -10,6 +10,10 @@ It is generated from these files:
 It has these top-level messages:
 	UnlockRequest
 	UnlockResponse
+	CloneDevicesRequest
+	CloneDevicesResponse
+	DeviceTenantRequest
+	DeviceTenantResponse
 */
 package pb
 
@@ -68,6 +72,7 @@ func (m *UnlockRequest) GetCode() string {
 type UnlockResponse struct {
 	EventCaption  string `protobuf:"bytes,1,opt,name=event_caption,json=eventCaption" json:"event_caption,omitempty"`
 	DeviceCaption string `protobuf:"bytes,2,opt,name=device_caption,json=deviceCaption" json:"device_caption,omitempty"`
+	TenantId      []byte `protobuf:"bytes,3,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
 }
 
 func (m *UnlockResponse) Reset()                    { *m = UnlockResponse{} }
@@ -89,9 +94,108 @@ func (m *UnlockResponse) GetDeviceCaption() string {
 	return ""
 }
 
+func (m *UnlockResponse) GetTenantId() []byte {
+	if m != nil {
+		return m.TenantId
+	}
+	return nil
+}
+
+type CloneDevicesRequest struct {
+	TenantId    []byte `protobuf:"bytes,1,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+	FromEventId []byte `protobuf:"bytes,2,opt,name=from_event_id,json=fromEventId,proto3" json:"from_event_id,omitempty"`
+	ToEventId   []byte `protobuf:"bytes,3,opt,name=to_event_id,json=toEventId,proto3" json:"to_event_id,omitempty"`
+}
+
+func (m *CloneDevicesRequest) Reset()                    { *m = CloneDevicesRequest{} }
+func (m *CloneDevicesRequest) String() string            { return proto.CompactTextString(m) }
+func (*CloneDevicesRequest) ProtoMessage()               {}
+func (*CloneDevicesRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+
+func (m *CloneDevicesRequest) GetTenantId() []byte {
+	if m != nil {
+		return m.TenantId
+	}
+	return nil
+}
+
+func (m *CloneDevicesRequest) GetFromEventId() []byte {
+	if m != nil {
+		return m.FromEventId
+	}
+	return nil
+}
+
+func (m *CloneDevicesRequest) GetToEventId() []byte {
+	if m != nil {
+		return m.ToEventId
+	}
+	return nil
+}
+
+type CloneDevicesResponse struct {
+	Count int32 `protobuf:"varint,1,opt,name=count" json:"count,omitempty"`
+}
+
+func (m *CloneDevicesResponse) Reset()                    { *m = CloneDevicesResponse{} }
+func (m *CloneDevicesResponse) String() string            { return proto.CompactTextString(m) }
+func (*CloneDevicesResponse) ProtoMessage()               {}
+func (*CloneDevicesResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
+func (m *CloneDevicesResponse) GetCount() int32 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+type DeviceTenantRequest struct {
+	EventId  []byte `protobuf:"bytes,1,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	DeviceId []byte `protobuf:"bytes,2,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+}
+
+func (m *DeviceTenantRequest) Reset()                    { *m = DeviceTenantRequest{} }
+func (m *DeviceTenantRequest) String() string            { return proto.CompactTextString(m) }
+func (*DeviceTenantRequest) ProtoMessage()               {}
+func (*DeviceTenantRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+
+func (m *DeviceTenantRequest) GetEventId() []byte {
+	if m != nil {
+		return m.EventId
+	}
+	return nil
+}
+
+func (m *DeviceTenantRequest) GetDeviceId() []byte {
+	if m != nil {
+		return m.DeviceId
+	}
+	return nil
+}
+
+type DeviceTenantResponse struct {
+	TenantId []byte `protobuf:"bytes,1,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+}
+
+func (m *DeviceTenantResponse) Reset()                    { *m = DeviceTenantResponse{} }
+func (m *DeviceTenantResponse) String() string            { return proto.CompactTextString(m) }
+func (*DeviceTenantResponse) ProtoMessage()               {}
+func (*DeviceTenantResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+
+func (m *DeviceTenantResponse) GetTenantId() []byte {
+	if m != nil {
+		return m.TenantId
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*UnlockRequest)(nil), "pb.UnlockRequest")
 	proto.RegisterType((*UnlockResponse)(nil), "pb.UnlockResponse")
+	proto.RegisterType((*CloneDevicesRequest)(nil), "pb.CloneDevicesRequest")
+	proto.RegisterType((*CloneDevicesResponse)(nil), "pb.CloneDevicesResponse")
+	proto.RegisterType((*DeviceTenantRequest)(nil), "pb.DeviceTenantRequest")
+	proto.RegisterType((*DeviceTenantResponse)(nil), "pb.DeviceTenantResponse")
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -106,6 +210,8 @@ const _ = grpc.SupportPackageIsVersion4
 
 type DeviceClient interface {
 	Unlock(ctx context.Context, in *UnlockRequest, opts ...grpc.CallOption) (*UnlockResponse, error)
+	CloneDevices(ctx context.Context, in *CloneDevicesRequest, opts ...grpc.CallOption) (*CloneDevicesResponse, error)
+	DeviceTenant(ctx context.Context, in *DeviceTenantRequest, opts ...grpc.CallOption) (*DeviceTenantResponse, error)
 }
 
 type deviceClient struct {
@@ -125,10 +231,30 @@ func (c *deviceClient) Unlock(ctx context.Context, in *UnlockRequest, opts ...gr
 	return out, nil
 }
 
+func (c *deviceClient) CloneDevices(ctx context.Context, in *CloneDevicesRequest, opts ...grpc.CallOption) (*CloneDevicesResponse, error) {
+	out := new(CloneDevicesResponse)
+	err := grpc.Invoke(ctx, "/pb.Device/CloneDevices", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deviceClient) DeviceTenant(ctx context.Context, in *DeviceTenantRequest, opts ...grpc.CallOption) (*DeviceTenantResponse, error) {
+	out := new(DeviceTenantResponse)
+	err := grpc.Invoke(ctx, "/pb.Device/DeviceTenant", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for Device service
 
 type DeviceServer interface {
 	Unlock(context.Context, *UnlockRequest) (*UnlockResponse, error)
+	CloneDevices(context.Context, *CloneDevicesRequest) (*CloneDevicesResponse, error)
+	DeviceTenant(context.Context, *DeviceTenantRequest) (*DeviceTenantResponse, error)
 }
 
 func RegisterDeviceServer(s *grpc.Server, srv DeviceServer) {
@@ -153,6 +279,42 @@ func _Device_Unlock_Handler(srv interface{}, ctx context.Context, dec func(inter
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Device_CloneDevices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CloneDevicesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceServer).CloneDevices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Device/CloneDevices",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceServer).CloneDevices(ctx, req.(*CloneDevicesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Device_DeviceTenant_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeviceTenantRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceServer).DeviceTenant(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Device/DeviceTenant",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceServer).DeviceTenant(ctx, req.(*DeviceTenantRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Device_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "pb.Device",
 	HandlerType: (*DeviceServer)(nil),
@@ -161,6 +323,14 @@ var _Device_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Unlock",
 			Handler:    _Device_Unlock_Handler,
 		},
+		{
+			MethodName: "CloneDevices",
+			Handler:    _Device_CloneDevices_Handler,
+		},
+		{
+			MethodName: "DeviceTenant",
+			Handler:    _Device_DeviceTenant_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "services/device/transport/pb/svcdevice.proto",
@@ -169,19 +339,28 @@ var _Device_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("services/device/transport/pb/svcdevice.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 222 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x54, 0x90, 0x31, 0x4b, 0x04, 0x31,
-	0x10, 0x85, 0xdd, 0x55, 0xd6, 0xdb, 0xe1, 0xf6, 0xc0, 0xa9, 0x4e, 0x6d, 0x8e, 0x15, 0xe1, 0x0a,
-	0xd9, 0xa0, 0x96, 0x96, 0xda, 0x5c, 0x1b, 0xb0, 0x52, 0x90, 0x4b, 0x32, 0xc5, 0xa2, 0x24, 0x31,
-	0x89, 0xfb, 0xfb, 0xe5, 0x66, 0x6e, 0x41, 0xab, 0x24, 0xdf, 0x7b, 0x79, 0x2f, 0x19, 0xb8, 0xcb,
-	0x94, 0xa6, 0xd1, 0x52, 0x56, 0x8e, 0x0e, 0xab, 0x2a, 0x69, 0xef, 0x73, 0x0c, 0xa9, 0xa8, 0x68,
-	0x54, 0x9e, 0xac, 0xe0, 0x21, 0xa6, 0x50, 0x02, 0xd6, 0xd1, 0xf4, 0x6f, 0xd0, 0xbd, 0xfa, 0xaf,
-	0x60, 0x3f, 0x35, 0x7d, 0xff, 0x50, 0x2e, 0x78, 0x09, 0x0b, 0x9a, 0xc8, 0x97, 0x8f, 0xd1, 0xad,
-	0xab, 0x4d, 0xb5, 0x5d, 0xea, 0x73, 0x3e, 0xef, 0x1c, 0x5e, 0x43, 0x2b, 0xf7, 0x0f, 0x5a, 0xcd,
-	0xda, 0x42, 0xc0, 0xce, 0x21, 0xc2, 0x99, 0x0d, 0x8e, 0xd6, 0xa7, 0x9b, 0x6a, 0xdb, 0x6a, 0xde,
-	0xf7, 0xef, 0xb0, 0x9a, 0xc3, 0x73, 0x0c, 0x3e, 0x13, 0xde, 0x40, 0x27, 0xe9, 0x76, 0x1f, 0xcb,
-	0x18, 0x3c, 0x57, 0xb4, 0x7a, 0xc9, 0xf0, 0x59, 0x18, 0xde, 0xc2, 0xea, 0xd8, 0x33, 0xbb, 0x6a,
-	0x76, 0x75, 0x42, 0x8f, 0xb6, 0x87, 0x27, 0x68, 0x5e, 0x18, 0xe0, 0x3d, 0x34, 0xd2, 0x83, 0x17,
-	0x43, 0x34, 0xc3, 0xbf, 0x0f, 0x5d, 0xe1, 0x5f, 0x24, 0xcf, 0xe8, 0x4f, 0x4c, 0xc3, 0x23, 0x78,
-	0xfc, 0x0d, 0x00, 0x00, 0xff, 0xff, 0xeb, 0x91, 0x4b, 0xd9, 0x32, 0x01, 0x00, 0x00,
+	// 356 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x52, 0xc1, 0x4e, 0xc2, 0x40,
+	0x10, 0xb5, 0x20, 0x48, 0x07, 0x4a, 0xe2, 0x40, 0x62, 0x85, 0xc4, 0x90, 0x35, 0x26, 0x1c, 0x08,
+	0x8d, 0xf2, 0x09, 0xe8, 0x81, 0x83, 0x97, 0x46, 0x4f, 0x1e, 0x08, 0x6d, 0xd7, 0x84, 0x88, 0xbb,
+	0x6b, 0x77, 0x69, 0xe2, 0x07, 0xfa, 0x5f, 0xa6, 0x3b, 0xad, 0xb6, 0x81, 0x78, 0xf1, 0xd4, 0xee,
+	0x9b, 0x37, 0xef, 0xcd, 0x9b, 0x5d, 0x98, 0x69, 0x9e, 0x66, 0xdb, 0x98, 0xeb, 0x20, 0xe1, 0xf9,
+	0x37, 0x30, 0xe9, 0x46, 0x68, 0x25, 0x53, 0x13, 0xa8, 0x28, 0xd0, 0x59, 0x4c, 0xf0, 0x5c, 0xa5,
+	0xd2, 0x48, 0x6c, 0xa8, 0x88, 0xbd, 0x80, 0xf7, 0x2c, 0x76, 0x32, 0x7e, 0x0b, 0xf9, 0xc7, 0x9e,
+	0x6b, 0x83, 0x97, 0xd0, 0xe1, 0x19, 0x17, 0x66, 0xbd, 0x4d, 0x7c, 0x67, 0xe2, 0x4c, 0x7b, 0xe1,
+	0x99, 0x3d, 0xaf, 0x12, 0x1c, 0x83, 0x4b, 0xfd, 0x79, 0xad, 0x61, 0x6b, 0x1d, 0x02, 0x56, 0x09,
+	0x22, 0x9c, 0xc6, 0x32, 0xe1, 0x7e, 0x73, 0xe2, 0x4c, 0xdd, 0xd0, 0xfe, 0xb3, 0x4f, 0xe8, 0x97,
+	0xe2, 0x5a, 0x49, 0xa1, 0x39, 0x5e, 0x83, 0x47, 0xea, 0xf1, 0x46, 0x99, 0xad, 0x14, 0xd6, 0xc2,
+	0x0d, 0x7b, 0x16, 0x5c, 0x12, 0x86, 0x37, 0xd0, 0x2f, 0x7c, 0x4a, 0x56, 0xc3, 0xb2, 0x3c, 0x42,
+	0x4b, 0xda, 0x18, 0x5c, 0xc3, 0xc5, 0x86, 0x46, 0x6d, 0xd2, 0x38, 0x04, 0xac, 0x12, 0x96, 0xc1,
+	0x60, 0xb9, 0x93, 0x82, 0xdf, 0xdb, 0x16, 0x5d, 0xa6, 0xab, 0xf5, 0x38, 0xf5, 0x1e, 0x64, 0xe0,
+	0xbd, 0xa6, 0xf2, 0x7d, 0xfd, 0x93, 0x9f, 0x32, 0x76, 0x73, 0xf0, 0xa1, 0xd8, 0xc1, 0x15, 0x74,
+	0x8d, 0xfc, 0x65, 0x90, 0xad, 0x6b, 0x64, 0x51, 0x67, 0x33, 0x18, 0xd6, 0x7d, 0x8b, 0xe0, 0x43,
+	0x68, 0xc5, 0x72, 0x2f, 0x8c, 0x35, 0x6d, 0x85, 0x74, 0x60, 0x8f, 0x30, 0x20, 0xe2, 0x93, 0x9d,
+	0xe1, 0x9f, 0x77, 0xc0, 0x16, 0x30, 0xac, 0xcb, 0x15, 0xe6, 0x7f, 0xa5, 0xbe, 0xfb, 0x72, 0xa0,
+	0x4d, 0x5d, 0x78, 0x0b, 0x6d, 0xba, 0x2f, 0x3c, 0x9f, 0xab, 0x68, 0x5e, 0x7b, 0x18, 0x23, 0xac,
+	0x42, 0x24, 0xcc, 0x4e, 0x70, 0x09, 0xbd, 0x6a, 0x5e, 0xbc, 0xc8, 0x59, 0x47, 0x36, 0x3f, 0xf2,
+	0x0f, 0x0b, 0x55, 0x91, 0xea, 0xdc, 0x24, 0x72, 0x64, 0x31, 0x23, 0xff, 0xb0, 0x50, 0x8a, 0x44,
+	0x6d, 0xfb, 0xa8, 0x17, 0xdf, 0x00, 0x00, 0x00, 0xff, 0xff, 0x03, 0x00, 0xa5, 0xc9, 0x00, 0x81,
+	0x04, 0x03, 0x00, 0x00,
 }
@@ -0,0 +1,54 @@
+package transport
+
+import (
+	// stdlib
+	"context"
+
+	// external
+	"github.com/kevinburke/go.uuid"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/services/device"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/quota"
+)
+
+// TenantFunc returns the tenant owning the device of an event, e.g. the
+// DeviceTenant method of our service.
+type TenantFunc func(ctx context.Context, eventID, deviceID uuid.UUID) (uuid.UUID, error)
+
+// UnlockQuota meters each attempt to unlock a known device against the quota
+// of the tenant owning the device. Attempts on unknown devices are not
+// metered as they are rejected by the service.
+func UnlockQuota(tenant TenantFunc) quota.Rule {
+	return quota.Rule{
+		Resource: quota.UnlockAttempts,
+		Request: func(ctx context.Context, request interface{}) (uuid.UUID, int64, error) {
+			req := request.(UnlockRequest)
+			tenantID, err := tenant(ctx, req.EventID, req.DeviceID)
+			switch err {
+			case nil:
+				return tenantID, 1, nil
+			case device.ErrRequireEventID, device.ErrRequireDeviceID, device.ErrDeviceNotFound:
+				return uuid.Nil, 0, nil
+			default:
+				return uuid.Nil, 0, err
+			}
+		},
+		Attempts: true,
+	}
+}
+
+// CloneDevicesQuota meters the devices created by cloning. The amount of
+// devices is only known afterwards, so clones are allowed while the tenant is
+// below its limit.
+func CloneDevicesQuota() quota.Rule {
+	return quota.Rule{
+		Resource: quota.Devices,
+		Request: func(_ context.Context, request interface{}) (uuid.UUID, int64, error) {
+			return request.(CloneDevicesRequest).TenantID, 0, nil
+		},
+		Response: func(response interface{}) int64 {
+			return int64(response.(CloneDevicesResponse).Count)
+		},
+	}
+}
@@ -8,6 +8,8 @@ import (
 
 var (
 	_ endpoint.Failer = UnlockResponse{}
+	_ endpoint.Failer = CloneDevicesResponse{}
+	_ endpoint.Failer = DeviceTenantResponse{}
 )
 
 // UnlockRequest holds the request parameters for the Unlock method.
@@ -19,6 +21,7 @@ type UnlockRequest struct {
 
 // UnlockResponse holds the response values for the Unlock method.
 type UnlockResponse struct {
+	TenantID      uuid.UUID
 	EventCaption  string
 	DeviceCaption string
 	Err           error
@@ -26,3 +29,36 @@ type UnlockResponse struct {
 
 // Failed implements Failer
 func (r UnlockResponse) Failed() error { return r.Err }
+
+// CloneDevicesRequest holds the request parameters for the CloneDevices
+// method.
+type CloneDevicesRequest struct {
+	TenantID    uuid.UUID `json:"tenant_id"`
+	FromEventID uuid.UUID `json:"from_event_id"`
+	ToEventID   uuid.UUID `json:"to_event_id"`
+}
+
+// CloneDevicesResponse holds the response values for the CloneDevices method.
+type CloneDevicesResponse struct {
+	Count int   `json:"count"`
+	Err   error `json:"-"`
+}
+
+// Failed implements Failer
+func (r CloneDevicesResponse) Failed() error { return r.Err }
+
+// DeviceTenantRequest holds the request parameters for the DeviceTenant
+// method.
+type DeviceTenantRequest struct {
+	EventID  uuid.UUID
+	DeviceID uuid.UUID
+}
+
+// DeviceTenantResponse holds the response values for the DeviceTenant method.
+type DeviceTenantResponse struct {
+	TenantID uuid.UUID `json:"tenant_id"`
+	Err      error     `json:"-"`
+}
+
+// Failed implements Failer
+func (r DeviceTenantResponse) Failed() error { return r.Err }
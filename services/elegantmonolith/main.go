@@ -3,8 +3,8 @@ package main
 import (
 	// stdlib
 	"context"
+	"crypto/rand"
 	"fmt"
-	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -14,19 +14,22 @@ import (
 	// external
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
-	"github.com/go-kit/kit/sd/etcd"
 	kitoc "github.com/go-kit/kit/tracing/opencensus"
 	kithttp "github.com/go-kit/kit/transport/http"
+	"github.com/gorilla/mux"
 	"github.com/jmoiron/sqlx"
 	"github.com/kevinburke/go.uuid"
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/oklog/run"
+	"go.opencensus.io/stats/view"
 
 	// project
 	"github.com/basvanbeek/opencensus-gokit-example/services/device"
 	devsql "github.com/basvanbeek/opencensus-gokit-example/services/device/database/sqlite"
+	"github.com/basvanbeek/opencensus-gokit-example/services/device/eventsync"
 	devimplementation "github.com/basvanbeek/opencensus-gokit-example/services/device/implementation"
 	"github.com/basvanbeek/opencensus-gokit-example/services/event"
+	evtcache "github.com/basvanbeek/opencensus-gokit-example/services/event/cache"
 	evtsql "github.com/basvanbeek/opencensus-gokit-example/services/event/database/sqlite"
 	evtimplementation "github.com/basvanbeek/opencensus-gokit-example/services/event/implementation"
 	"github.com/basvanbeek/opencensus-gokit-example/services/frontend"
@@ -35,13 +38,29 @@ import (
 	httptransport "github.com/basvanbeek/opencensus-gokit-example/services/frontend/transport/http"
 	"github.com/basvanbeek/opencensus-gokit-example/services/qr"
 	qrimplementation "github.com/basvanbeek/opencensus-gokit-example/services/qr/implementation"
-	"github.com/basvanbeek/opencensus-gokit-example/shared/network"
+	"github.com/basvanbeek/opencensus-gokit-example/services/webhook"
+	whsql "github.com/basvanbeek/opencensus-gokit-example/services/webhook/database/sqlite"
+	"github.com/basvanbeek/opencensus-gokit-example/services/webhook/delivery"
+	whimplementation "github.com/basvanbeek/opencensus-gokit-example/services/webhook/implementation"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/admin"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/health"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/mtls"
 	"github.com/basvanbeek/opencensus-gokit-example/shared/oc"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/quota"
+	qsqlite "github.com/basvanbeek/opencensus-gokit-example/shared/quota/sqlite"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/ratelimit"
+	rlsqlite "github.com/basvanbeek/opencensus-gokit-example/shared/ratelimit/sqlite"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/registry"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/sd"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/sqlitebackup"
 )
 
 // ServiceName of this service.
 const serviceName = "ElegantMonolith"
 
+// dbFile holds our SQLite database file
+const dbFile = "monolith.db"
+
 func main() {
 	var (
 		err      error
@@ -71,25 +90,43 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	// Create our etcd client for Service Discovery
-	//
-	// we could have used the v3 client but then we must vendor or suffer the
-	// following issue originating from gRPC init:
-	// panic: http: multiple registrations for /debug/requests
-	var sdc etcd.Client
+	// Create our service registry for Service Discovery, configured using
+	// OCG_REGISTRY and defaulting to etcd on localhost
+	var reg registry.Registry
 	{
-		// create our Go kit etcd client
-		sdc, err = etcd.NewClient(ctx, []string{"http://localhost:2379"}, etcd.ClientOptions{})
+		reg, err = registry.New(ctx, os.Getenv("OCG_REGISTRY"), logger)
 		if err != nil {
 			level.Error(logger).Log("exit", err)
 			os.Exit(-1)
 		}
 	}
 
+	// Create the metadata announced with our instances, see the README for its
+	// configuration
+	var md sd.Metadata
+	{
+		if md, err = registry.LocalMetadata("http"); err != nil {
+			level.Error(logger).Log("exit", err)
+			os.Exit(-1)
+		}
+	}
+
+	// Create the certificate source serving our frontend with TLS, see the
+	// README for its configuration
+	var tlsSource *mtls.Source
+	{
+		if tlsSource, err = mtls.FromEnv(logger); err != nil {
+			level.Error(logger).Log("exit", err)
+			os.Exit(-1)
+		}
+		// stop watching our certificate files on shutdown
+		defer tlsSource.Close()
+	}
+
 	// Create our DB Connection Driver
 	var db *sqlx.DB
 	{
-		db, err = sqlx.Open("sqlite3", "monolith.db?_journal_mode=WAL")
+		db, err = sqlx.Open("sqlite3", dbFile+"?_journal_mode=WAL")
 		if err != nil {
 			level.Error(logger).Log("exit", err)
 			os.Exit(-1)
@@ -102,8 +139,30 @@ func main() {
 		}
 	}
 
+	// Create our Webhook service component
+	var (
+		webhookService webhook.Service
+		worker         *delivery.Worker
+	)
+	{
+		var logger = log.With(logger, "component", webhook.ServiceName)
+
+		repository, err := whsql.New(db, logger)
+		if err != nil {
+			level.Error(logger).Log("exit", err)
+			os.Exit(-1)
+		}
+		webhookService = whimplementation.NewService(repository, logger)
+		// add service level middlewares here
+
+		worker = delivery.NewWorker(repository, logger)
+	}
+
 	// Create our Event service component
-	var eventService event.Service
+	var (
+		eventService event.Service
+		scheduler    *evtimplementation.Scheduler
+	)
 	{
 		var logger = log.With(logger, "component", event.ServiceName)
 
@@ -114,11 +173,18 @@ func main() {
 		}
 		eventService = evtimplementation.NewService(repository, logger)
 		// add service level middlewares here
+		eventService = evtimplementation.NotifyMiddleware(webhookService, logger)(eventService)
+		eventService = evtcache.Middleware(30*time.Second, 5*time.Second, logger)(eventService)
 
+		// materialize recurring event instances 90 days ahead
+		scheduler = evtimplementation.NewScheduler(eventService, 90*24*time.Hour, logger)
 	}
 
 	// Create our Device service component
-	var deviceService device.Service
+	var (
+		deviceService device.Service
+		reconciler    *eventsync.Reconciler
+	)
 	{
 		var logger = log.With(logger, "component", device.ServiceName)
 
@@ -129,6 +195,10 @@ func main() {
 		}
 		deviceService = devimplementation.NewService(repository, logger)
 		// add service level middlewares here
+		deviceService = devimplementation.NotifyMiddleware(webhookService, logger)(deviceService)
+
+		// the device component keeps its own read model of events
+		reconciler = eventsync.NewReconciler(eventService, repository, logger)
 	}
 
 	// Create our QR service component
@@ -140,20 +210,83 @@ func main() {
 		// add service level middlewares here
 	}
 
+	// Calendar feed tokens are signed with a key which needs to be shared by
+	// all Frontend instances.
+	var feedKey []byte
+	{
+		if feedKey = []byte(os.Getenv("OCG_FEED_KEY")); len(feedKey) == 0 {
+			level.Warn(logger).Log(
+				"msg", "OCG_FEED_KEY not set, calendar feed tokens will not survive a restart",
+			)
+			feedKey = make([]byte, 32)
+			if _, err = rand.Read(feedKey); err != nil {
+				level.Error(logger).Log("exit", err)
+				os.Exit(-1)
+			}
+		}
+	}
+
 	// Create our frontend service component
 	var frontendService frontend.Service
 	{
 		var logger = log.With(logger, "component", frontend.ServiceName)
 
 		frontendService = feimplementation.NewService(
-			eventService, deviceService, qrService, logger,
+			eventService, deviceService, qrService, webhookService, feedKey,
+			logger,
 		)
 		// add service level middlewares here
 	}
 
+	// Create our quota Meter enforcing the daily limits of our tenants. Limits
+	// are configured with OCG_QUOTAS, e.g. "events=100,unlock_attempts=50".
+	// Instances on the same host share their usage through the SQLite
+	// database in OCG_QUOTA_DB if set.
+	var meter *quota.Meter
+	{
+		limits, err := quota.ParseLimits(os.Getenv("OCG_QUOTAS"))
+		if err != nil {
+			level.Error(logger).Log("exit", err)
+			os.Exit(-1)
+		}
+		var store quota.Store = quota.NewMemoryStore()
+		if path := os.Getenv("OCG_QUOTA_DB"); path != "" {
+			db, err := sqlx.Open("sqlite3", path+"?_journal_mode=WAL&_busy_timeout=5000")
+			if err != nil {
+				level.Error(logger).Log("exit", err)
+				os.Exit(-1)
+			}
+			if store, err = qsqlite.New(db, logger); err != nil {
+				level.Error(logger).Log("exit", err)
+				os.Exit(-1)
+			}
+		}
+		meter = quota.NewMeter(store, limits)
+	}
+
 	var endpoints transport.Endpoints
 	{
 		endpoints = transport.MakeEndpoints(frontendService)
+
+		// enforce the daily quotas of our tenants; devices cloned in process
+		// are not metered
+		exceeded := frontend.ErrQuotaExceeded
+		endpoints.EventCreate = quota.Middleware(
+			meter, transport.EventCreateQuota(), exceeded, logger,
+		)(endpoints.EventCreate)
+		endpoints.EventImport = quota.Middleware(
+			meter, transport.EventImportQuota(), exceeded, logger,
+		)(endpoints.EventImport)
+		endpoints.EventClone = quota.Middleware(
+			meter, transport.EventCloneQuota(), exceeded, logger,
+		)(endpoints.EventClone)
+		endpoints.UnlockDevice = quota.Middleware(
+			meter, transport.UnlockDeviceQuota(deviceService.DeviceTenant), exceeded, logger,
+		)(endpoints.UnlockDevice)
+		endpoints.GenerateQR = quota.Middleware(
+			meter, transport.GenerateQRQuota(deviceService.DeviceTenant), exceeded, logger,
+		)(endpoints.GenerateQR)
+
 		// trace our server side endpoints
 		endpoints = transport.Endpoints{
 			Login:        oc.ServerEndpoint("Login")(endpoints.Login),
@@ -162,11 +295,55 @@ func main() {
 			EventUpdate:  oc.ServerEndpoint("EventUpdate")(endpoints.EventUpdate),
 			EventDelete:  oc.ServerEndpoint("EventDelete")(endpoints.EventDelete),
 			EventList:    oc.ServerEndpoint("EventList")(endpoints.EventList),
+			EventImport:  oc.ServerEndpoint("EventImport")(endpoints.EventImport),
+			EventExport:  oc.ServerEndpoint("EventExport")(endpoints.EventExport),
 			UnlockDevice: oc.ServerEndpoint("UnlockDevice")(endpoints.UnlockDevice),
 			GenerateQR:   oc.ServerEndpoint("GenerateQR")(endpoints.GenerateQR),
+
+			EventCalendarToken: oc.ServerEndpoint("EventCalendarToken")(endpoints.EventCalendarToken),
+			EventCalendar:      oc.ServerEndpoint("EventCalendar")(endpoints.EventCalendar),
+			EventClone:         oc.ServerEndpoint("EventClone")(endpoints.EventClone),
+
+			EventTemplateCreate: oc.ServerEndpoint("EventTemplateCreate")(endpoints.EventTemplateCreate),
+			EventTemplateList:   oc.ServerEndpoint("EventTemplateList")(endpoints.EventTemplateList),
+			EventTemplateDelete: oc.ServerEndpoint("EventTemplateDelete")(endpoints.EventTemplateDelete),
+
+			WebhookCreate: oc.ServerEndpoint("WebhookCreate")(endpoints.WebhookCreate),
+			WebhookDelete: oc.ServerEndpoint("WebhookDelete")(endpoints.WebhookDelete),
+			WebhookList:   oc.ServerEndpoint("WebhookList")(endpoints.WebhookList),
 		}
 	}
 
+	// Create our rate limiting middleware for the HTTP API. Limits are
+	// configured with OCG_RATELIMIT, e.g. "tenant=100/m,event_import.tenant=10/h".
+	// Instances on the same host share their buckets through the SQLite
+	// database in OCG_RATELIMIT_DB if set.
+	var rateLimiter mux.MiddlewareFunc
+	{
+		config, err := ratelimit.ParseConfig(os.Getenv("OCG_RATELIMIT"))
+		if err != nil {
+			level.Error(logger).Log("exit", err)
+			os.Exit(-1)
+		}
+		var store ratelimit.Store = ratelimit.NewMemoryStore()
+		if path := os.Getenv("OCG_RATELIMIT_DB"); path != "" {
+			db, err := sqlx.Open("sqlite3", path+"?_journal_mode=WAL&_busy_timeout=5000")
+			if err != nil {
+				level.Error(logger).Log("exit", err)
+				os.Exit(-1)
+			}
+			if store, err = rlsqlite.New(db, logger); err != nil {
+				level.Error(logger).Log("exit", err)
+				os.Exit(-1)
+			}
+		}
+		if err = view.Register(ratelimit.RejectionsView); err != nil {
+			level.Error(logger).Log("exit", err)
+			os.Exit(-1)
+		}
+		rateLimiter = ratelimit.Middleware(store, config, httptransport.RequestTenant, logger)
+	}
+
 	// run.Group manages our goroutine lifecycles
 	// see: https://www.youtube.com/watch?v=LHe1Cb_Ud_M&t=15m45s
 	var g run.Group
@@ -175,23 +352,75 @@ func main() {
 		oc.ZPages(g, logger)
 	}
 	{
-		// set-up our http transport
+		// set-up our admin endpoints serving our daily usage report and online
+		// SQLite backups
+		router := http.NewServeMux()
+		router.Handle("/admin/usage", quota.NewHandler(meter, logger))
+		if err := sqlitebackup.Handle(router, dbFile, logger); err != nil {
+			level.Error(logger).Log("exit", err)
+			os.Exit(-1)
+		}
+		if err := admin.Serve(&g, router, logger); err != nil {
+			level.Error(logger).Log("exit", err)
+			os.Exit(-1)
+		}
+	}
+	{
+		// keep the device component's event read model in sync
+		ctx, cancel := context.WithCancel(ctx)
+		g.Add(func() error {
+			return reconciler.Run(ctx, 30*time.Second)
+		}, func(error) {
+			cancel()
+		})
+	}
+	{
+		// materialize our recurring event templates
+		ctx, cancel := context.WithCancel(ctx)
+		g.Add(func() error {
+			return scheduler.Run(ctx, time.Minute)
+		}, func(error) {
+			cancel()
+		})
+	}
+	{
+		// deliver our queued webhook notifications
+		ctx, cancel := context.WithCancel(ctx)
+		g.Add(func() error {
+			return worker.Run(ctx, time.Second)
+		}, func(error) {
+			cancel()
+		})
+	}
+	{
+		// set-up our http transport, the monolith is basically the frontend
+		// service with all micro service backend logic embedded so it registers
+		// as such
+		listener, err := registry.Listen(frontend.ServiceName, "http")
+		if err != nil {
+			level.Error(logger).Log("exit", err)
+			os.Exit(-1)
+		}
 		var (
-			bindIP, _     = network.HostIP()
-			listener, _   = net.Listen("tcp", bindIP+":0")                                       // dynamic port assignment
-			svcInstance   = fmt.Sprintf("/services/%s/http/%s/", frontend.ServiceName, instance) // monolith is basically frontend service but with all micro service backend logic embedded
-			addr          = "http://" + listener.Addr().String()
-			ttl           = etcd.NewTTLOption(3*time.Second, 10*time.Second)
-			service       = etcd.Service{Key: svcInstance, Value: addr, TTL: ttl}
-			registrar     = etcd.NewRegistrar(sdc, service, logger)
+			addr          = sd.FormatInstance("http://"+listener.Addr().String(), md)
+			registrar     = reg.Registrar(frontend.ServiceName, "http", instance.String(), addr)
 			ocTracing     = kitoc.HTTPServerTrace()
 			serverOptions = []kithttp.ServerOption{ocTracing}
-			feService     = httptransport.NewService(endpoints, serverOptions, logger)
+			feService     = httptransport.NewService(endpoints, serverOptions, logger, rateLimiter)
+			router        = http.NewServeMux()
 		)
 
+		// serve our health endpoint next to our endpoints
+		router.Handle(health.Path, health.Handler())
+		router.Handle("/", feService)
+
+		// our users call the frontend directly, so callers are not required to
+		// present a service identity
 		g.Add(func() error {
 			registrar.Register()
-			return http.Serve(listener, feService)
+			return http.Serve(
+				tlsSource.Listener(listener), tlsSource.HTTPHandler(nil, router),
+			)
 		}, func(error) {
 			registrar.Deregister()
 			listener.Close()
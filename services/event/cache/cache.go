@@ -0,0 +1,292 @@
+// Package cache implements a read-through caching middleware for the event
+// service. It can be used on both sides of the wire: in front of an event
+// client or in front of the event service implementation.
+package cache
+
+import (
+	// stdlib
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/kevinburke/go.uuid"
+	"go.opencensus.io/trace"
+	"golang.org/x/sync/singleflight"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/services/event"
+)
+
+// loadTimeout bounds the calls to the next Service loading a cache entry. As
+// loads are shared by concurrent callers they don't run with the deadline of
+// the first caller.
+const loadTimeout = 10 * time.Second
+
+// entry holds a cached Get or List result.
+type entry struct {
+	event   *event.Event
+	events  []*event.Event
+	err     error
+	expires time.Time
+}
+
+// tenant holds the cached results of a single tenant. Invalidation drops the
+// tenant, its next results are held by a new tenant of a later generation so
+// results loaded before a mutation are never stored after it.
+type tenant struct {
+	generation uint64
+	entries    map[string]entry
+}
+
+type cache struct {
+	event.Service
+	ttl         time.Duration
+	negativeTTL time.Duration
+	logger      log.Logger
+
+	mtx        sync.Mutex
+	tenants    map[uuid.UUID]*tenant
+	generation uint64
+	lastSweep  time.Time
+	group      singleflight.Group
+}
+
+// Middleware caches the results of Get and List calls per tenant for ttl and
+// Get calls resulting in ErrNotFound for negativeTTL. A negativeTTL of 0
+// disables negative caching. Concurrent identical calls are collapsed into a
+// single call to the next Service.
+//
+// Create, Update, Delete, Import, Clone and Materialize invalidate all cached
+// results of the tenant. Only mutations passing through this middleware are
+// seen, so results may be stale for up to ttl if other instances mutate the
+// tenant's events.
+func Middleware(ttl, negativeTTL time.Duration, logger log.Logger) event.Middleware {
+	return func(next event.Service) event.Service {
+		return &cache{
+			Service:     next,
+			ttl:         ttl,
+			negativeTTL: negativeTTL,
+			logger:      log.With(logger, "middleware", "cache"),
+			tenants:     make(map[uuid.UUID]*tenant),
+			lastSweep:   time.Now(),
+		}
+	}
+}
+
+func (c *cache) Get(ctx context.Context, tenantID, id uuid.UUID) (*event.Event, error) {
+	e, err := c.load(ctx, "Get", tenantID, "get/"+id.String(), func(ctx context.Context) entry {
+		evt, err := c.Service.Get(ctx, tenantID, id)
+		return entry{event: evt, err: err}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return e.event, e.err
+}
+
+func (c *cache) List(ctx context.Context, tenantID uuid.UUID) ([]*event.Event, error) {
+	e, err := c.load(ctx, "List", tenantID, "list", func(ctx context.Context) entry {
+		events, err := c.Service.List(ctx, tenantID)
+		return entry{events: events, err: err}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return e.events, e.err
+}
+
+func (c *cache) Create(
+	ctx context.Context, tenantID uuid.UUID, evt event.Event,
+) (*uuid.UUID, error) {
+	defer c.invalidate(tenantID)
+	return c.Service.Create(ctx, tenantID, evt)
+}
+
+func (c *cache) Update(ctx context.Context, tenantID uuid.UUID, evt event.Event) error {
+	defer c.invalidate(tenantID)
+	return c.Service.Update(ctx, tenantID, evt)
+}
+
+func (c *cache) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
+	defer c.invalidate(tenantID)
+	return c.Service.Delete(ctx, tenantID, id)
+}
+
+func (c *cache) Import(
+	ctx context.Context, tenantID uuid.UUID, events []event.Event, dryRun bool,
+) (*event.ImportResult, error) {
+	if !dryRun {
+		defer c.invalidate(tenantID)
+	}
+	return c.Service.Import(ctx, tenantID, events, dryRun)
+}
+
+func (c *cache) Clone(
+	ctx context.Context, tenantID, id uuid.UUID, name string, start time.Time,
+) (*uuid.UUID, error) {
+	defer c.invalidate(tenantID)
+	return c.Service.Clone(ctx, tenantID, id, name, start)
+}
+
+func (c *cache) Materialize(
+	ctx context.Context, tenantID, id uuid.UUID, until time.Time,
+) ([]*event.Event, error) {
+	defer c.invalidate(tenantID)
+	return c.Service.Materialize(ctx, tenantID, id, until)
+}
+
+// load returns the cached entry for key or calls fn to load it. The returned
+// error is only set if the call was abandoned, business errors are part of the
+// entry. fn runs detached from the cancellation of ctx, bound by loadTimeout,
+// so callers sharing the load don't fail with the caller who started it.
+func (c *cache) load(
+	ctx context.Context, method string, tenantID uuid.UUID, key string,
+	fn func(ctx context.Context) entry,
+) (entry, error) {
+	c.mtx.Lock()
+	t := c.tenant(tenantID)
+	generation := t.generation
+	e, ok := t.entries[key]
+	c.mtx.Unlock()
+
+	if ok && time.Now().Before(e.expires) {
+		annotate(ctx, method, "hit")
+		return clone(e), nil
+	}
+
+	// include the generation in the call key so callers arriving after a
+	// mutation never join a call started before it
+	ch := c.group.DoChan(
+		tenantID.String()+"/"+strconv.FormatUint(generation, 10)+"/"+key,
+		func() (interface{}, error) {
+			ctx, cancel := context.WithTimeout(detached{ctx}, loadTimeout)
+			defer cancel()
+
+			e := fn(ctx)
+			c.store(tenantID, generation, key, e)
+			return e, nil
+		},
+	)
+	select {
+	case res := <-ch:
+		if res.Shared {
+			annotate(ctx, method, "shared")
+		} else {
+			annotate(ctx, method, "miss")
+		}
+		return clone(res.Val.(entry)), nil
+	case <-ctx.Done():
+		return entry{}, ctx.Err()
+	}
+}
+
+// store caches the entry unless the tenant was invalidated or swept since
+// generation.
+func (c *cache) store(tenantID uuid.UUID, generation uint64, key string, e entry) {
+	now := time.Now()
+	switch e.err {
+	case nil:
+		e.expires = now.Add(c.ttl)
+	case event.ErrNotFound:
+		if c.negativeTTL <= 0 {
+			return
+		}
+		e.expires = now.Add(c.negativeTTL)
+	default:
+		// don't cache failures
+		return
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	t, ok := c.tenants[tenantID]
+	if !ok || t.generation != generation {
+		level.Debug(c.logger).Log("tenant", tenantID, "key", key, "msg", "discarded stale result")
+		return
+	}
+	t.entries[key] = e
+
+	c.sweep(now)
+}
+
+// invalidate drops all cached results of the tenant.
+func (c *cache) invalidate(tenantID uuid.UUID) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	delete(c.tenants, tenantID)
+}
+
+// tenant returns the cached results of the tenant, starting a new generation
+// if it has none. Callers must hold mtx.
+func (c *cache) tenant(tenantID uuid.UUID) *tenant {
+	t, ok := c.tenants[tenantID]
+	if !ok {
+		c.generation++
+		t = &tenant{generation: c.generation, entries: make(map[string]entry)}
+		c.tenants[tenantID] = t
+	}
+	return t
+}
+
+// sweep drops expired entries and tenants left without entries at most once
+// per ttl. Loads in flight for a dropped tenant are not cached. Callers must
+// hold mtx.
+func (c *cache) sweep(now time.Time) {
+	if now.Sub(c.lastSweep) < c.ttl {
+		return
+	}
+	c.lastSweep = now
+	for tenantID, t := range c.tenants {
+		for key, e := range t.entries {
+			if !now.Before(e.expires) {
+				delete(t.entries, key)
+			}
+		}
+		if len(t.entries) == 0 {
+			delete(c.tenants, tenantID)
+		}
+	}
+}
+
+// detached holds the values of a context, like its trace span, without its
+// deadline and cancellation.
+type detached struct {
+	context.Context
+}
+
+func (detached) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detached) Done() <-chan struct{}       { return nil }
+func (detached) Err() error                  { return nil }
+
+// annotate records the cache outcome on the current span.
+func annotate(ctx context.Context, method, outcome string) {
+	if span := trace.FromContext(ctx); span != nil {
+		span.Annotate([]trace.Attribute{
+			trace.StringAttribute("event.cache.method", method),
+			trace.StringAttribute("event.cache", outcome),
+		}, "event cache "+outcome)
+	}
+}
+
+// clone copies the cached events so callers can't modify the cache.
+func clone(e entry) entry {
+	if e.event != nil {
+		evt := *e.event
+		e.event = &evt
+	}
+	if e.events != nil {
+		events := make([]*event.Event, 0, len(e.events))
+		for _, evt := range e.events {
+			evt := *evt
+			events = append(events, &evt)
+		}
+		e.events = events
+	}
+	return e
+}
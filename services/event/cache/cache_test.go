@@ -0,0 +1,108 @@
+package cache
+
+import (
+	// stdlib
+	"context"
+	"testing"
+	"time"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/kevinburke/go.uuid"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/services/event"
+)
+
+// service is an event.Service only implementing Get and Create.
+type service struct {
+	event.Service
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *service) Get(ctx context.Context, _, id uuid.UUID) (*event.Event, error) {
+	if s.started != nil {
+		s.started <- struct{}{}
+		<-s.release
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return &event.Event{ID: id, Name: "Meetup"}, nil
+}
+
+func (s *service) Create(context.Context, uuid.UUID, event.Event) (*uuid.UUID, error) {
+	id := uuid.NewV4()
+	return &id, nil
+}
+
+func TestLoadOutlivesFirstCaller(t *testing.T) {
+	next := &service{started: make(chan struct{}), release: make(chan struct{})}
+	svc := Middleware(time.Minute, time.Minute, log.NewNopLogger())(next)
+	tenantID, id := uuid.NewV4(), uuid.NewV4()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := svc.Get(ctx, tenantID, id)
+		first <- err
+	}()
+	<-next.started
+
+	second := make(chan error, 1)
+	go func() {
+		evt, err := svc.Get(context.Background(), tenantID, id)
+		if err == nil && !uuid.Equal(evt.ID, id) {
+			t.Errorf("want event %s, have %s", id, evt.ID)
+		}
+		second <- err
+	}()
+
+	// the first caller gives up while its load is shared by the second
+	cancel()
+	if err := <-first; err != context.Canceled {
+		t.Errorf("want first caller error %v, have %v", context.Canceled, err)
+	}
+	close(next.release)
+	if err := <-second; err != nil {
+		t.Errorf("want second caller to get the event, have error %v", err)
+	}
+}
+
+func TestInvalidateDropsTenant(t *testing.T) {
+	c := Middleware(time.Minute, time.Minute, log.NewNopLogger())(&service{}).(*cache)
+	tenantID := uuid.NewV4()
+
+	if _, err := c.Get(context.Background(), tenantID, uuid.NewV4()); err != nil {
+		t.Fatal(err)
+	}
+	if want, have := 1, len(c.tenants); want != have {
+		t.Fatalf("want %d cached tenants, have %d", want, have)
+	}
+	if _, err := c.Create(context.Background(), tenantID, event.Event{}); err != nil {
+		t.Fatal(err)
+	}
+	if want, have := 0, len(c.tenants); want != have {
+		t.Errorf("want %d cached tenants, have %d", want, have)
+	}
+}
+
+func TestSweepDropsEmptyTenants(t *testing.T) {
+	c := Middleware(time.Millisecond, 0, log.NewNopLogger())(&service{}).(*cache)
+
+	for i := 0; i < 3; i++ {
+		if _, err := c.Get(context.Background(), uuid.NewV4(), uuid.NewV4()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	c.mtx.Lock()
+	c.sweep(time.Now())
+	have := len(c.tenants)
+	c.mtx.Unlock()
+	if have != 0 {
+		t.Errorf("want expired tenants dropped, have %d cached tenants", have)
+	}
+}
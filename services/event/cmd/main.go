@@ -4,7 +4,6 @@ import (
 	// stdlib
 	"context"
 	"fmt"
-	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -14,25 +13,41 @@ import (
 	// external
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
-	"github.com/go-kit/kit/sd/etcd"
 	"github.com/gorilla/mux"
 	"github.com/jmoiron/sqlx"
 	"github.com/kevinburke/go.uuid"
+	_ "github.com/lib/pq"
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/oklog/run"
 	"github.com/opencensus-integrations/ocsql"
 	"go.opencensus.io/plugin/ochttp"
 
 	// project
+	whclient "github.com/basvanbeek/opencensus-gokit-example/clients/webhook"
+	"github.com/basvanbeek/opencensus-gokit-example/services/device"
 	"github.com/basvanbeek/opencensus-gokit-example/services/event"
+	"github.com/basvanbeek/opencensus-gokit-example/services/event/cache"
+	"github.com/basvanbeek/opencensus-gokit-example/services/event/database"
+	"github.com/basvanbeek/opencensus-gokit-example/services/event/database/postgres"
 	"github.com/basvanbeek/opencensus-gokit-example/services/event/database/sqlite"
 	"github.com/basvanbeek/opencensus-gokit-example/services/event/implementation"
 	"github.com/basvanbeek/opencensus-gokit-example/services/event/transport/pb"
 	transporttwirp "github.com/basvanbeek/opencensus-gokit-example/services/event/transport/twirp"
-	"github.com/basvanbeek/opencensus-gokit-example/shared/network"
+	"github.com/basvanbeek/opencensus-gokit-example/services/frontend"
+	"github.com/basvanbeek/opencensus-gokit-example/services/webhook"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/admin"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/factory"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/health"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/mtls"
 	"github.com/basvanbeek/opencensus-gokit-example/shared/oc"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/registry"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/sd"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/sqlitebackup"
 )
 
+// dbFile holds our SQLite database file
+const dbFile = "event.db"
+
 func main() {
 	var (
 		err      error
@@ -62,48 +77,121 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	// Create our etcd client for Service Discovery
-	//
-	// we could have used the v3 client but then we must vendor or suffer the
-	// following issue originating from gRPC init:
-	// panic: http: multiple registrations for /debug/requests
-	var sdc etcd.Client
+	// Create our service registry for Service Discovery, configured using
+	// OCG_REGISTRY and defaulting to etcd on localhost
+	var reg registry.Registry
 	{
-		// create our Go kit etcd client
-		sdc, err = etcd.NewClient(ctx, []string{"http://localhost:2379"}, etcd.ClientOptions{})
+		reg, err = registry.New(ctx, os.Getenv("OCG_REGISTRY"), logger)
 		if err != nil {
 			level.Error(logger).Log("exit", err)
 			os.Exit(-1)
 		}
 	}
 
-	// Create our DB Connection Driver
-	var db *sqlx.DB
+	// Create the metadata announced with our instances and the routing of our
+	// client requests, see the README for their configuration
+	var (
+		md      sd.Metadata
+		routing []sd.Option
+	)
 	{
+		if md, err = registry.LocalMetadata("twirp"); err != nil {
+			level.Error(logger).Log("exit", err)
+			os.Exit(-1)
+		}
+		if routing, err = registry.Routing(); err != nil {
+			level.Error(logger).Log("exit", err)
+			os.Exit(-1)
+		}
+	}
+
+	// Create the certificate source for mutual TLS between our services, the
+	// options of our clients and the services allowed to call us, see the
+	// README for the configuration of mutual TLS
+	var (
+		tlsSource  *mtls.Source
+		clientOpts []factory.Option
+		callers    = mtls.AllowServices(frontend.ServiceName, device.ServiceName)
+	)
+	{
+		if tlsSource, err = mtls.FromEnv(logger); err != nil {
+			level.Error(logger).Log("exit", err)
+			os.Exit(-1)
+		}
+		// stop watching our certificate files on shutdown
+		defer tlsSource.Close()
+		clientOpts = []factory.Option{
+			factory.WithRouting(routing...),
+			factory.WithMTLS(tlsSource),
+		}
+	}
+
+	// Create our DB Connection Driver. Instances share a PostgreSQL database if
+	// OCG_EVENT_DB holds its connection string, else each instance uses a local
+	// SQLite database.
+	var (
+		db  *sqlx.DB
+		dsn = os.Getenv("OCG_EVENT_DB")
+	)
+	{
+		driver, source := "sqlite3", dbFile+"?_journal_mode=WAL"
+		if dsn != "" {
+			driver, source = "postgres", dsn
+		}
 		var driverName string
-		driverName, err = ocsql.Register("sqlite3", ocsql.WithOptions(ocsql.AllTraceOptions))
+		driverName, err = ocsql.Register(driver, ocsql.WithOptions(ocsql.AllTraceOptions))
 		if err != nil {
 			level.Error(logger).Log("exit", err)
 			os.Exit(-1)
 		}
-		db, err = sqlx.Open(driverName, "event.db?_journal_mode=WAL")
+		db, err = sqlx.Open(driverName, source)
 		if err != nil {
 			level.Error(logger).Log("exit", err)
 			os.Exit(-1)
 		}
 	}
 
-	// Create our Event Service
-	var svc event.Service
+	// Create our Event Repository
+	var repository database.Repository
 	{
-		repository, err := sqlite.New(db, logger)
+		if dsn != "" {
+			repository, err = postgres.New(db, logger)
+		} else {
+			repository, err = sqlite.New(db, logger)
+		}
 		if err != nil {
 			level.Error(logger).Log("exit", err)
 			os.Exit(-1)
 		}
+	}
+
+	// Create our Event Service
+	var svc event.Service
+	{
 		svc = implementation.NewService(repository, logger)
 		// add service level middlewares here
 
+		// create an instancer for the webhook client
+		whInstancer, err := reg.Instancer(webhook.ServiceName, "http")
+		if err != nil {
+			level.Error(logger).Log("exit", err)
+			os.Exit(-1)
+		}
+		whClient := whclient.NewHTTPClient(whInstancer, logger, clientOpts...)
+
+		// notify webhook subscribers of event changes
+		svc = implementation.NotifyMiddleware(whClient, logger)(svc)
+
+		// cache event reads; instances sharing a PostgreSQL database only
+		// see their own mutations so keep the TTLs short
+		svc = cache.Middleware(5*time.Second, time.Second, logger)(svc)
+	}
+
+	// Create our recurring event template Scheduler, materializing event
+	// instances 90 days ahead
+	var scheduler *implementation.Scheduler
+	{
+		scheduler = implementation.NewScheduler(svc, 90*24*time.Hour, logger)
 	}
 
 	// run.Group manages our goroutine lifecycles
@@ -113,31 +201,54 @@ func main() {
 		// set-up our ZPages handler
 		oc.ZPages(g, logger)
 	}
+	if dsn == "" {
+		// set-up our admin endpoint for online SQLite backups; PostgreSQL
+		// databases are backed up using their own tooling
+		router := http.NewServeMux()
+		if err := sqlitebackup.Handle(router, dbFile, logger); err != nil {
+			level.Error(logger).Log("exit", err)
+			os.Exit(-1)
+		}
+		if err := admin.Serve(&g, router, logger); err != nil {
+			level.Error(logger).Log("exit", err)
+			os.Exit(-1)
+		}
+	}
+	{
+		// materialize our recurring event templates
+		ctx, cancel := context.WithCancel(ctx)
+		g.Add(func() error {
+			return scheduler.Run(ctx, time.Minute)
+		}, func(error) {
+			cancel()
+		})
+	}
 	{
 		// set-up our twirp transport
+		listener, err := registry.Listen(event.ServiceName, "twirp")
+		if err != nil {
+			level.Error(logger).Log("exit", err)
+			os.Exit(-1)
+		}
 		var (
-			bindIP, _    = network.HostIP()
 			eventService = transporttwirp.NewService(svc, logger)
-			listener, _  = net.Listen("tcp", bindIP+":0") // dynamic port assignment
-			svcInstance  = "/services/" + event.ServiceName + "/twirp/" + instance.String()
-			addr         = "http://" + listener.Addr().String()
-			ttl          = etcd.NewTTLOption(3*time.Second, 10*time.Second)
-			service      = etcd.Service{Key: svcInstance, Value: addr, TTL: ttl}
-			registrar    = etcd.NewRegistrar(sdc, service, logger)
+			addr         = sd.FormatInstance("http://"+listener.Addr().String(), md)
+			registrar    = reg.Registrar(event.ServiceName, "twirp", instance.String(), addr)
 			twirpHandler = pb.NewEventServer(eventService, nil)
 			router       = mux.NewRouter()
 		)
 
 		router.PathPrefix(pb.EventPathPrefix).Handler(twirpHandler)
+		router.Path(health.Path).Handler(health.Handler())
 
 		// add default ochttp handler for TWIRP
 		handler := &ochttp.Handler{
-			Handler: router,
+			Handler: tlsSource.HTTPHandler(callers, router),
 		}
 
 		g.Add(func() error {
 			registrar.Register()
-			return http.Serve(listener, handler)
+			return http.Serve(tlsSource.Listener(listener), handler)
 		}, func(error) {
 			registrar.Deregister()
 			listener.Close()
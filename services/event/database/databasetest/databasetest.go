@@ -0,0 +1,571 @@
+// Package databasetest holds the conformance checks every event Repository
+// implementation must pass. Each check works on freshly generated tenants so
+// the checks can run against a shared, non-empty database.
+package databasetest
+
+import (
+	// stdlib
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	// external
+	"github.com/kevinburke/go.uuid"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/services/event/database"
+)
+
+// Check is a single named conformance check.
+type Check struct {
+	Name string
+	Run  func(ctx context.Context, repo database.Repository) error
+}
+
+// Checks holds our Repository conformance checks.
+var Checks = []Check{
+	{"create and get", createAndGet},
+	{"unique names", uniqueNames},
+	{"unique ids", uniqueIDs},
+	{"update", update},
+	{"delete", remove},
+	{"list", list},
+	{"list page", listPage},
+	{"create batch", createBatch},
+	{"templates", templates},
+	{"materialize template", materializeTemplate},
+	{"template failure", templateFailure},
+	{"template tenant isolation", templateIsolation},
+}
+
+// Run runs all checks against the repository as subtests of t.
+func Run(t *testing.T, repo database.Repository) {
+	for _, check := range Checks {
+		check := check
+		t.Run(check.Name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			if err := check.Run(ctx, repo); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+var (
+	start = time.Date(2030, 6, 1, 9, 0, 0, 0, time.UTC)
+	end   = start.Add(2 * time.Hour)
+)
+
+func createAndGet(ctx context.Context, repo database.Repository) error {
+	want := database.Event{
+		TenantID: uuid.NewV4(), Name: "Kick-off", Start: start, End: end,
+		Timezone: "Europe/Amsterdam",
+	}
+	id, err := repo.Create(ctx, want)
+	if err != nil {
+		return err
+	}
+	want.ID = *id
+
+	have, err := repo.Get(ctx, *id)
+	if err != nil {
+		return err
+	}
+	if err = equalEvent(*have, want); err != nil {
+		return err
+	}
+
+	// dates are optional
+	id, err = repo.Create(ctx, database.Event{TenantID: want.TenantID, Name: "Undated"})
+	if err != nil {
+		return err
+	}
+	if have, err = repo.Get(ctx, *id); err != nil {
+		return err
+	}
+	if !have.Start.IsZero() || !have.End.IsZero() {
+		return fmt.Errorf("undated event: have dates %s - %s", have.Start, have.End)
+	}
+
+	_, err = repo.Get(ctx, uuid.NewV4())
+	return expect("get unknown event", err, database.ErrNotFound)
+}
+
+func uniqueNames(ctx context.Context, repo database.Repository) error {
+	tenantID := uuid.NewV4()
+	if _, err := repo.Create(ctx, database.Event{TenantID: tenantID, Name: "Meetup"}); err != nil {
+		return err
+	}
+
+	// names are unique per tenant, regardless of case
+	_, err := repo.Create(ctx, database.Event{TenantID: tenantID, Name: "MEETUP"})
+	if err = expect("create duplicate name", err, database.ErrNameExists); err != nil {
+		return err
+	}
+
+	_, err = repo.Create(ctx, database.Event{TenantID: uuid.NewV4(), Name: "Meetup"})
+	return expect("create name of other tenant", err, nil)
+}
+
+func uniqueIDs(ctx context.Context, repo database.Repository) error {
+	id := uuid.NewV4()
+	if _, err := repo.Create(ctx, database.Event{ID: id, TenantID: uuid.NewV4(), Name: "First"}); err != nil {
+		return err
+	}
+
+	_, err := repo.Create(ctx, database.Event{ID: id, TenantID: uuid.NewV4(), Name: "Second"})
+	return expect("create duplicate id", err, database.ErrIDExists)
+}
+
+func update(ctx context.Context, repo database.Repository) error {
+	tenantID := uuid.NewV4()
+	id, err := repo.Create(ctx, database.Event{TenantID: tenantID, Name: "Draft"})
+	if err != nil {
+		return err
+	}
+	if _, err = repo.Create(ctx, database.Event{TenantID: tenantID, Name: "Taken"}); err != nil {
+		return err
+	}
+
+	want := database.Event{
+		ID: *id, TenantID: tenantID, Name: "Final", Start: start, End: end,
+		Timezone: "UTC",
+	}
+	if err = repo.Update(ctx, want); err != nil {
+		return err
+	}
+	have, err := repo.Get(ctx, *id)
+	if err != nil {
+		return err
+	}
+	if err = equalEvent(*have, want); err != nil {
+		return err
+	}
+
+	err = repo.Update(ctx, database.Event{ID: *id, TenantID: tenantID, Name: "taken"})
+	if err = expect("update to duplicate name", err, database.ErrNameExists); err != nil {
+		return err
+	}
+
+	err = repo.Update(ctx, database.Event{ID: *id, TenantID: uuid.NewV4(), Name: "Hijack"})
+	if err = expect("update event of other tenant", err, database.ErrNotFound); err != nil {
+		return err
+	}
+
+	err = repo.Update(ctx, database.Event{ID: uuid.NewV4(), TenantID: tenantID, Name: "Unknown"})
+	return expect("update unknown event", err, database.ErrNotFound)
+}
+
+func remove(ctx context.Context, repo database.Repository) error {
+	tenantID := uuid.NewV4()
+	id, err := repo.Create(ctx, database.Event{TenantID: tenantID, Name: "Gone"})
+	if err != nil {
+		return err
+	}
+
+	// deleting an event of another tenant is a no-op
+	if err = repo.Delete(ctx, uuid.NewV4(), *id); err != nil {
+		return err
+	}
+	if _, err = repo.Get(ctx, *id); err != nil {
+		return fmt.Errorf("delete by other tenant: %v", err)
+	}
+
+	if err = repo.Delete(ctx, tenantID, *id); err != nil {
+		return err
+	}
+	_, err = repo.Get(ctx, *id)
+	return expect("get deleted event", err, database.ErrNotFound)
+}
+
+func list(ctx context.Context, repo database.Repository) error {
+	tenantID := uuid.NewV4()
+	for _, name := range []string{"Charlie", "Alpha", "Bravo"} {
+		if _, err := repo.Create(ctx, database.Event{TenantID: tenantID, Name: name}); err != nil {
+			return err
+		}
+	}
+	if _, err := repo.Create(ctx, database.Event{TenantID: uuid.NewV4(), Name: "Other"}); err != nil {
+		return err
+	}
+
+	events, err := repo.List(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	if err = equalNames(events, "Alpha", "Bravo", "Charlie"); err != nil {
+		return err
+	}
+
+	// listing all events includes our tenant's events
+	all, err := repo.List(ctx, uuid.Nil)
+	if err != nil {
+		return err
+	}
+	var found int
+	for _, event := range all {
+		if uuid.Equal(event.TenantID, tenantID) {
+			found++
+		}
+	}
+	if found != 3 {
+		return fmt.Errorf("list all events: have %d events of tenant, want 3", found)
+	}
+
+	events, err = repo.List(ctx, uuid.NewV4())
+	if err != nil {
+		return err
+	}
+	return equalNames(events)
+}
+
+func listPage(ctx context.Context, repo database.Repository) error {
+	var (
+		tenantID = uuid.NewV4()
+		ids      []uuid.UUID
+	)
+	for _, name := range []string{"Alpha", "Bravo", "Charlie", "Delta", "Echo"} {
+		id, err := repo.Create(ctx, database.Event{TenantID: tenantID, Name: name})
+		if err != nil {
+			return err
+		}
+		ids = append(ids, *id)
+	}
+	if _, err := repo.Create(ctx, database.Event{TenantID: uuid.NewV4(), Name: "Other"}); err != nil {
+		return err
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return bytes.Compare(ids[i].Bytes(), ids[j].Bytes()) < 0
+	})
+
+	// pages are ordered by id and continue after the last id of the previous
+	// page until a short page is returned
+	var (
+		after uuid.UUID
+		have  []uuid.UUID
+	)
+	for pages := 0; ; pages++ {
+		if pages > len(ids) {
+			return fmt.Errorf("list page: no short page after %d pages", pages)
+		}
+		events, err := repo.ListPage(ctx, tenantID, after, 2)
+		if err != nil {
+			return err
+		}
+		for _, event := range events {
+			have = append(have, event.ID)
+		}
+		if len(events) < 2 {
+			break
+		}
+		after = events[len(events)-1].ID
+	}
+	if fmt.Sprint(have) != fmt.Sprint(ids) {
+		return fmt.Errorf("list page: have %v, want %v", have, ids)
+	}
+
+	events, err := repo.ListPage(ctx, uuid.NewV4(), uuid.Nil, 2)
+	if err != nil {
+		return err
+	}
+	return equalNames(events)
+}
+
+func createBatch(ctx context.Context, repo database.Repository) error {
+	tenantID := uuid.NewV4()
+	if _, err := repo.Create(ctx, database.Event{TenantID: tenantID, Name: "Existing"}); err != nil {
+		return err
+	}
+
+	// conflicts are reported per event and nothing is stored
+	rowErrs, err := repo.CreateBatch(ctx, []database.Event{
+		{TenantID: tenantID, Name: "One"},
+		{TenantID: tenantID, Name: "existing"},
+		{TenantID: tenantID, Name: "Two"},
+		{TenantID: tenantID, Name: "two"},
+	}, false)
+	if err != nil {
+		return err
+	}
+	if len(rowErrs) != 2 || rowErrs[1] != database.ErrNameExists ||
+		rowErrs[3] != database.ErrNameExists {
+		return fmt.Errorf("create batch with conflicts: have %v", rowErrs)
+	}
+	if err = listed(ctx, repo, tenantID, "Existing"); err != nil {
+		return err
+	}
+
+	// dry runs validate without storing
+	batch := []database.Event{
+		{TenantID: tenantID, Name: "One", Start: start, End: end},
+		{TenantID: tenantID, Name: "Two"},
+	}
+	if rowErrs, err = repo.CreateBatch(ctx, batch, true); err != nil {
+		return err
+	}
+	if len(rowErrs) != 0 {
+		return fmt.Errorf("create batch dry run: have %v", rowErrs)
+	}
+	if err = listed(ctx, repo, tenantID, "Existing"); err != nil {
+		return err
+	}
+
+	if rowErrs, err = repo.CreateBatch(ctx, batch, false); err != nil {
+		return err
+	}
+	if len(rowErrs) != 0 {
+		return fmt.Errorf("create batch: have %v", rowErrs)
+	}
+	return listed(ctx, repo, tenantID, "Existing", "One", "Two")
+}
+
+func templates(ctx context.Context, repo database.Repository) error {
+	tenantID := uuid.NewV4()
+	want := database.Template{
+		TenantID: tenantID, Name: "Standup", RRule: "FREQ=DAILY",
+		Start: start, End: end, Timezone: "Europe/Amsterdam",
+	}
+	id, err := repo.CreateTemplate(ctx, want)
+	if err != nil {
+		return err
+	}
+	want.ID = *id
+
+	have, err := repo.GetTemplate(ctx, *id)
+	if err != nil {
+		return err
+	}
+	if err = equalTemplate(*have, want); err != nil {
+		return err
+	}
+
+	_, err = repo.CreateTemplate(ctx, database.Template{
+		TenantID: tenantID, Name: "STANDUP", RRule: "FREQ=WEEKLY", Start: start,
+	})
+	if err = expect("create duplicate template name", err, database.ErrNameExists); err != nil {
+		return err
+	}
+
+	list, err := repo.ListTemplates(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	if len(list) != 1 {
+		return fmt.Errorf("list templates: have %d templates, want 1", len(list))
+	}
+	if err = equalTemplate(*list[0], want); err != nil {
+		return err
+	}
+
+	if err = repo.DeleteTemplate(ctx, tenantID, *id); err != nil {
+		return err
+	}
+	_, err = repo.GetTemplate(ctx, *id)
+	return expect("get deleted template", err, database.ErrNotFound)
+}
+
+func materializeTemplate(ctx context.Context, repo database.Repository) error {
+	tenantID := uuid.NewV4()
+	id, err := repo.CreateTemplate(ctx, database.Template{
+		TenantID: tenantID, Name: "Weekly", RRule: "FREQ=WEEKLY", Start: start,
+	})
+	if err != nil {
+		return err
+	}
+
+	until := start.AddDate(0, 0, 7)
+	if err = repo.MaterializeTemplate(ctx, *id, []database.Event{
+		{TenantID: tenantID, Name: "Weekly 1", Start: start},
+		{TenantID: tenantID, Name: "Weekly 2", Start: until},
+	}, until); err != nil {
+		return err
+	}
+	if err = listed(ctx, repo, tenantID, "Weekly 1", "Weekly 2"); err != nil {
+		return err
+	}
+	template, err := repo.GetTemplate(ctx, *id)
+	if err != nil {
+		return err
+	}
+	if !template.MaterializedUntil.Equal(until) {
+		return fmt.Errorf(
+			"materialized until: have %s, want %s", template.MaterializedUntil, until,
+		)
+	}
+
+	// a conflict stores nothing and keeps the materialized until time
+	err = repo.MaterializeTemplate(ctx, *id, []database.Event{
+		{TenantID: tenantID, Name: "Weekly 3"},
+		{TenantID: tenantID, Name: "Weekly 2"},
+	}, until.AddDate(0, 0, 7))
+	if err = expect("materialize conflict", err, database.ErrNameExists); err != nil {
+		return err
+	}
+	if err = listed(ctx, repo, tenantID, "Weekly 1", "Weekly 2"); err != nil {
+		return err
+	}
+	if template, err = repo.GetTemplate(ctx, *id); err != nil {
+		return err
+	}
+	if !template.MaterializedUntil.Equal(until) {
+		return fmt.Errorf(
+			"materialized until after conflict: have %s, want %s",
+			template.MaterializedUntil, until,
+		)
+	}
+
+	err = repo.MaterializeTemplate(ctx, uuid.NewV4(), nil, until)
+	return expect("materialize unknown template", err, database.ErrNotFound)
+}
+
+func templateFailure(ctx context.Context, repo database.Repository) error {
+	tenantID := uuid.NewV4()
+	id, err := repo.CreateTemplate(ctx, database.Template{
+		TenantID: tenantID, Name: "Daily", RRule: "FREQ=DAILY", Start: start,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err = repo.FailTemplate(ctx, *id, "conflict"); err != nil {
+		return err
+	}
+	template, err := repo.GetTemplate(ctx, *id)
+	if err != nil {
+		return err
+	}
+	if template.Failure != "conflict" {
+		return fmt.Errorf("failure: have %q, want %q", template.Failure, "conflict")
+	}
+	templates, err := repo.ListTemplates(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	if len(templates) != 1 || templates[0].Failure != "conflict" {
+		return fmt.Errorf("listed failure: have %+v, want %q", templates, "conflict")
+	}
+
+	// materializing again clears the failure
+	if err = repo.MaterializeTemplate(ctx, *id, []database.Event{
+		{TenantID: tenantID, Name: "Daily 1", Start: start},
+	}, start); err != nil {
+		return err
+	}
+	if template, err = repo.GetTemplate(ctx, *id); err != nil {
+		return err
+	}
+	if template.Failure != "" {
+		return fmt.Errorf("failure after materialize: have %q, want none", template.Failure)
+	}
+
+	err = repo.FailTemplate(ctx, uuid.NewV4(), "conflict")
+	return expect("fail unknown template", err, database.ErrNotFound)
+}
+
+func templateIsolation(ctx context.Context, repo database.Repository) error {
+	tenantA, tenantB := uuid.NewV4(), uuid.NewV4()
+	idA, err := repo.CreateTemplate(ctx, database.Template{
+		TenantID: tenantA, Name: "Retro", RRule: "FREQ=MONTHLY", Start: start,
+	})
+	if err != nil {
+		return err
+	}
+
+	// template names are unique per tenant only
+	idB, err := repo.CreateTemplate(ctx, database.Template{
+		TenantID: tenantB, Name: "Retro", RRule: "FREQ=MONTHLY", Start: start,
+	})
+	if err != nil {
+		return fmt.Errorf("create template name of other tenant: %v", err)
+	}
+
+	list, err := repo.ListTemplates(ctx, tenantA)
+	if err != nil {
+		return err
+	}
+	if len(list) != 1 || !uuid.Equal(list[0].ID, *idA) {
+		return fmt.Errorf("list templates: have %d templates, want 1", len(list))
+	}
+
+	// listing all templates includes both tenants
+	all, err := repo.ListTemplates(ctx, uuid.Nil)
+	if err != nil {
+		return err
+	}
+	var found int
+	for _, template := range all {
+		if uuid.Equal(template.ID, *idA) || uuid.Equal(template.ID, *idB) {
+			found++
+		}
+	}
+	if found != 2 {
+		return fmt.Errorf("list all templates: have %d of our templates, want 2", found)
+	}
+
+	// deleting a template of another tenant is a no-op
+	if err = repo.DeleteTemplate(ctx, tenantB, *idA); err != nil {
+		return err
+	}
+	if _, err = repo.GetTemplate(ctx, *idA); err != nil {
+		return fmt.Errorf("delete template by other tenant: %v", err)
+	}
+
+	_, err = repo.GetTemplate(ctx, uuid.NewV4())
+	return expect("get unknown template", err, database.ErrNotFound)
+}
+
+// expect returns an error if err is not the wanted error.
+func expect(op string, err, want error) error {
+	if err != want {
+		return fmt.Errorf("%s: have error %v, want %v", op, err, want)
+	}
+	return nil
+}
+
+// listed returns an error if the tenant's events do not match names.
+func listed(
+	ctx context.Context, repo database.Repository, tenantID uuid.UUID,
+	names ...string,
+) error {
+	events, err := repo.List(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	return equalNames(events, names...)
+}
+
+func equalNames(events []*database.Event, names ...string) error {
+	have := make([]string, 0, len(events))
+	for _, event := range events {
+		have = append(have, event.Name)
+	}
+	if fmt.Sprint(have) != fmt.Sprint(names) {
+		return fmt.Errorf("list: have %v, want %v", have, names)
+	}
+	return nil
+}
+
+func equalEvent(have, want database.Event) error {
+	if !uuid.Equal(have.ID, want.ID) || !uuid.Equal(have.TenantID, want.TenantID) ||
+		have.Name != want.Name || !have.Start.Equal(want.Start) ||
+		!have.End.Equal(want.End) || have.Timezone != want.Timezone {
+		return fmt.Errorf("event: have %+v, want %+v", have, want)
+	}
+	return nil
+}
+
+func equalTemplate(have, want database.Template) error {
+	if !uuid.Equal(have.ID, want.ID) || !uuid.Equal(have.TenantID, want.TenantID) ||
+		have.Name != want.Name || have.RRule != want.RRule ||
+		!have.Start.Equal(want.Start) || !have.End.Equal(want.End) ||
+		have.Timezone != want.Timezone ||
+		!have.MaterializedUntil.Equal(want.MaterializedUntil) {
+		return fmt.Errorf("template: have %+v, want %+v", have, want)
+	}
+	return nil
+}
@@ -0,0 +1,349 @@
+// Package inmemory implements a thread-safe event Repository holding its data
+// in memory. It is meant for tests of the layers above the repository and
+// for running the service without a database file.
+package inmemory
+
+import (
+	// stdlib
+	"bytes"
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	// external
+	"github.com/kevinburke/go.uuid"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/services/event/database"
+)
+
+// Repository is an in-memory database.Repository.
+type Repository struct {
+	mtx       sync.RWMutex
+	events    map[uuid.UUID]database.Event
+	templates map[uuid.UUID]database.Template
+}
+
+// New returns a new empty in-memory Repository
+func New() *Repository {
+	return &Repository{
+		events:    make(map[uuid.UUID]database.Event),
+		templates: make(map[uuid.UUID]database.Template),
+	}
+}
+
+// Create implements database.Repository
+func (r *Repository) Create(
+	_ context.Context, event database.Event,
+) (*uuid.UUID, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	// check if we need to create a new UUID
+	if uuid.Equal(event.ID, uuid.Nil) {
+		event.ID = uuid.NewV4()
+	}
+	if err := checkEvent(r.events, event); err != nil {
+		return nil, err
+	}
+	r.events[event.ID] = normalizeEvent(event)
+
+	return &event.ID, nil
+}
+
+// Get implements database.Repository
+func (r *Repository) Get(_ context.Context, id uuid.UUID) (*database.Event, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	event, ok := r.events[id]
+	if !ok {
+		return nil, database.ErrNotFound
+	}
+	return &event, nil
+}
+
+// Update implements database.Repository
+func (r *Repository) Update(_ context.Context, event database.Event) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	current, ok := r.events[event.ID]
+	if !ok || !uuid.Equal(current.TenantID, event.TenantID) {
+		return database.ErrNotFound
+	}
+	for id, other := range r.events {
+		if !uuid.Equal(id, event.ID) && sameName(other.TenantID, other.Name, event.TenantID, event.Name) {
+			return database.ErrNameExists
+		}
+	}
+	r.events[event.ID] = normalizeEvent(event)
+
+	return nil
+}
+
+// Delete implements database.Repository
+func (r *Repository) Delete(_ context.Context, tenantID, id uuid.UUID) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if event, ok := r.events[id]; ok && uuid.Equal(event.TenantID, tenantID) {
+		delete(r.events, id)
+	}
+	return nil
+}
+
+// List implements database.Repository
+func (r *Repository) List(
+	_ context.Context, tenantID uuid.UUID,
+) ([]*database.Event, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	var events []*database.Event
+	for _, event := range r.events {
+		if uuid.Equal(tenantID, uuid.Nil) || uuid.Equal(event.TenantID, tenantID) {
+			event := event
+			events = append(events, &event)
+		}
+	}
+	sort.Slice(events, func(i, j int) bool {
+		return less(events[i].TenantID, events[i].Name, events[j].TenantID, events[j].Name)
+	})
+
+	return events, nil
+}
+
+// ListPage implements database.Repository
+func (r *Repository) ListPage(
+	_ context.Context, tenantID, after uuid.UUID, limit int,
+) ([]*database.Event, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	events := make([]*database.Event, 0, limit)
+	for _, event := range r.events {
+		if uuid.Equal(event.TenantID, tenantID) && bytes.Compare(event.ID.Bytes(), after.Bytes()) > 0 {
+			event := event
+			events = append(events, &event)
+		}
+	}
+	sort.Slice(events, func(i, j int) bool {
+		return bytes.Compare(events[i].ID.Bytes(), events[j].ID.Bytes()) < 0
+	})
+	if len(events) > limit {
+		events = events[:limit]
+	}
+
+	return events, nil
+}
+
+// CreateBatch implements database.Repository
+func (r *Repository) CreateBatch(
+	_ context.Context, events []database.Event, dryRun bool,
+) (map[int]error, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	// validate against a copy so conflicts within the batch are detected too
+	pending := r.copyEvents()
+	rowErrs := make(map[int]error)
+	for idx := range events {
+		// check if we need to create a new UUID
+		if uuid.Equal(events[idx].ID, uuid.Nil) {
+			events[idx].ID = uuid.NewV4()
+		}
+		if err := checkEvent(pending, events[idx]); err != nil {
+			rowErrs[idx] = err
+			continue
+		}
+		pending[events[idx].ID] = normalizeEvent(events[idx])
+	}
+
+	if !dryRun && len(rowErrs) == 0 {
+		r.events = pending
+	}
+	return rowErrs, nil
+}
+
+// CreateTemplate implements database.Repository
+func (r *Repository) CreateTemplate(
+	_ context.Context, template database.Template,
+) (*uuid.UUID, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	// check if we need to create a new UUID
+	if uuid.Equal(template.ID, uuid.Nil) {
+		template.ID = uuid.NewV4()
+	}
+	if _, ok := r.templates[template.ID]; ok {
+		return nil, database.ErrIDExists
+	}
+	for _, other := range r.templates {
+		if sameName(other.TenantID, other.Name, template.TenantID, template.Name) {
+			return nil, database.ErrNameExists
+		}
+	}
+	template.Start, template.End = normalize(template.Start), normalize(template.End)
+	// materialization starts from scratch
+	template.MaterializedUntil = time.Time{}
+	template.Failure = ""
+	r.templates[template.ID] = template
+
+	return &template.ID, nil
+}
+
+// GetTemplate implements database.Repository
+func (r *Repository) GetTemplate(
+	_ context.Context, id uuid.UUID,
+) (*database.Template, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	template, ok := r.templates[id]
+	if !ok {
+		return nil, database.ErrNotFound
+	}
+	return &template, nil
+}
+
+// ListTemplates implements database.Repository
+func (r *Repository) ListTemplates(
+	_ context.Context, tenantID uuid.UUID,
+) ([]*database.Template, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	var templates []*database.Template
+	for _, template := range r.templates {
+		if uuid.Equal(tenantID, uuid.Nil) || uuid.Equal(template.TenantID, tenantID) {
+			template := template
+			templates = append(templates, &template)
+		}
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		return less(
+			templates[i].TenantID, templates[i].Name,
+			templates[j].TenantID, templates[j].Name,
+		)
+	})
+
+	return templates, nil
+}
+
+// DeleteTemplate implements database.Repository
+func (r *Repository) DeleteTemplate(_ context.Context, tenantID, id uuid.UUID) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if template, ok := r.templates[id]; ok && uuid.Equal(template.TenantID, tenantID) {
+		delete(r.templates, id)
+	}
+	return nil
+}
+
+// MaterializeTemplate implements database.Repository
+func (r *Repository) MaterializeTemplate(
+	_ context.Context, templateID uuid.UUID, events []database.Event,
+	until time.Time,
+) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	template, ok := r.templates[templateID]
+	if !ok {
+		return database.ErrNotFound
+	}
+
+	pending := r.copyEvents()
+	for idx := range events {
+		// check if we need to create a new UUID
+		if uuid.Equal(events[idx].ID, uuid.Nil) {
+			events[idx].ID = uuid.NewV4()
+		}
+		if err := checkEvent(pending, events[idx]); err != nil {
+			return err
+		}
+		pending[events[idx].ID] = normalizeEvent(events[idx])
+	}
+
+	template.MaterializedUntil = normalize(until)
+	template.Failure = ""
+	r.templates[templateID] = template
+	r.events = pending
+
+	return nil
+}
+
+// FailTemplate implements database.Repository
+func (r *Repository) FailTemplate(
+	_ context.Context, templateID uuid.UUID, failure string,
+) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	template, ok := r.templates[templateID]
+	if !ok {
+		return database.ErrNotFound
+	}
+	template.Failure = failure
+	r.templates[templateID] = template
+
+	return nil
+}
+
+// checkEvent returns the constraint violation if event were added to events.
+func checkEvent(
+	events map[uuid.UUID]database.Event, event database.Event,
+) error {
+	if _, ok := events[event.ID]; ok {
+		return database.ErrIDExists
+	}
+	for _, other := range events {
+		if sameName(other.TenantID, other.Name, event.TenantID, event.Name) {
+			return database.ErrNameExists
+		}
+	}
+	return nil
+}
+
+// copyEvents returns a copy of our events to stage changes on.
+func (r *Repository) copyEvents() map[uuid.UUID]database.Event {
+	events := make(map[uuid.UUID]database.Event, len(r.events))
+	for id, event := range r.events {
+		events[id] = event
+	}
+	return events
+}
+
+// sameName mirrors the unique (tenant_id, lower(name)) indexes of our SQL
+// backends.
+func sameName(tenantA uuid.UUID, nameA string, tenantB uuid.UUID, nameB string) bool {
+	return uuid.Equal(tenantA, tenantB) && strings.ToLower(nameA) == strings.ToLower(nameB)
+}
+
+// less orders by tenant and name, as our SQL backends do.
+func less(tenantA uuid.UUID, nameA string, tenantB uuid.UUID, nameB string) bool {
+	if c := bytes.Compare(tenantA.Bytes(), tenantB.Bytes()); c != 0 {
+		return c < 0
+	}
+	return nameA < nameB
+}
+
+// normalizeEvent stores the event dates the way our SQL backends do.
+func normalizeEvent(event database.Event) database.Event {
+	event.Start, event.End = normalize(event.Start), normalize(event.End)
+	return event
+}
+
+// normalize returns t in UTC truncated to seconds, matching the unix seconds
+// stored by our SQL backends.
+func normalize(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return time.Unix(t.Unix(), 0).UTC()
+}
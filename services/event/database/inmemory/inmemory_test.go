@@ -0,0 +1,13 @@
+package inmemory
+
+import (
+	// stdlib
+	"testing"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/services/event/database/databasetest"
+)
+
+func TestConformance(t *testing.T) {
+	databasetest.Run(t, New())
+}
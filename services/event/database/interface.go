@@ -4,6 +4,7 @@ import (
 	// stdlib
 	"context"
 	"errors"
+	"time"
 
 	// external
 	"github.com/kevinburke/go.uuid"
@@ -24,6 +25,27 @@ type Repository interface {
 	Update(ctx context.Context, event Event) error
 	Delete(ctx context.Context, tenantID uuid.UUID, id uuid.UUID) error
 	List(ctx context.Context, tenantID uuid.UUID) ([]*Event, error)
+	// ListPage returns up to limit events of the tenant ordered by id,
+	// starting after the provided id. Use uuid.Nil to start at the first
+	// event.
+	ListPage(ctx context.Context, tenantID, after uuid.UUID, limit int) ([]*Event, error)
+	// CreateBatch creates the provided events in a single transaction. Per
+	// event errors are returned keyed by slice index. The transaction is only
+	// committed if no event failed and dryRun is false.
+	CreateBatch(ctx context.Context, events []Event, dryRun bool) (map[int]error, error)
+
+	CreateTemplate(ctx context.Context, template Template) (*uuid.UUID, error)
+	GetTemplate(ctx context.Context, id uuid.UUID) (*Template, error)
+	ListTemplates(ctx context.Context, tenantID uuid.UUID) ([]*Template, error)
+	DeleteTemplate(ctx context.Context, tenantID uuid.UUID, id uuid.UUID) error
+	// MaterializeTemplate creates the template instances and advances the
+	// template's materialized until time in a single transaction. If any of
+	// the events fails to be created nothing is stored.
+	MaterializeTemplate(ctx context.Context, templateID uuid.UUID, events []Event, until time.Time) error
+	// FailTemplate records why the template's instances could not be
+	// materialized. The failure is cleared by the next successful
+	// MaterializeTemplate call.
+	FailTemplate(ctx context.Context, templateID uuid.UUID, failure string) error
 }
 
 // Event holds event details
@@ -31,4 +53,20 @@ type Event struct {
 	ID       uuid.UUID
 	TenantID uuid.UUID
 	Name     string
+	Start    time.Time
+	End      time.Time
+	Timezone string
+}
+
+// Template holds recurring event template details
+type Template struct {
+	ID                uuid.UUID
+	TenantID          uuid.UUID
+	Name              string
+	RRule             string
+	Start             time.Time
+	End               time.Time
+	Timezone          string
+	MaterializedUntil time.Time
+	Failure           string
 }
@@ -0,0 +1,528 @@
+package postgres
+
+import (
+	// stdlib
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/jmoiron/sqlx"
+	"github.com/kevinburke/go.uuid"
+	"github.com/lib/pq"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/services/event/database"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/migrate"
+)
+
+// uniqueViolation is the PostgreSQL error code for unique constraint
+// violations.
+const uniqueViolation = "23505"
+
+type postgres struct {
+	db     *sqlx.DB
+	logger log.Logger
+}
+
+// New returns a new Repository backed by PostgreSQL
+func New(db *sqlx.DB, logger log.Logger) (database.Repository, error) {
+	// run our embedded database migrations
+	if err := migrate.Up(
+		context.Background(), db, migrate.Postgres, Schema, logger,
+	); err != nil {
+		return nil, err
+	}
+
+	// return our repository
+	return &postgres{
+		db:     db,
+		logger: log.With(logger, "rep", "postgres"),
+	}, nil
+}
+
+func (s *postgres) Create(
+	ctx context.Context, event database.Event,
+) (id *uuid.UUID, err error) {
+	// check if we need to create a new UUID
+	if uuid.Equal(event.ID, uuid.Nil) {
+		event.ID = uuid.NewV4()
+	}
+
+	switch err = insertEvent(ctx, s.db, event); err {
+	case nil:
+	case database.ErrNameExists, database.ErrIDExists:
+		level.Debug(s.logger).Log("err", err)
+		return nil, err
+	default:
+		level.Error(s.logger).Log("err", err)
+		return nil, database.ErrRepository
+	}
+
+	return &event.ID, nil
+}
+
+func (s *postgres) Get(ctx context.Context, id uuid.UUID) (*database.Event, error) {
+	var (
+		event      = database.Event{ID: id}
+		start, end int64
+	)
+
+	if err := s.db.QueryRowContext(
+		ctx,
+		`SELECT tenant_id, name, starts_at, ends_at, timezone FROM event
+		WHERE id = $1`,
+		id,
+	).Scan(
+		&event.TenantID, &event.Name, &start, &end, &event.Timezone,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			level.Debug(s.logger).Log("err", err)
+			return nil, database.ErrNotFound
+		}
+		level.Error(s.logger).Log("err", err)
+		return nil, database.ErrRepository
+	}
+	event.Start, event.End = fromUnix(start), fromUnix(end)
+
+	return &event, nil
+}
+
+func (s *postgres) Update(ctx context.Context, event database.Event) (err error) {
+	var (
+		res sql.Result
+		cnt int64
+	)
+
+	res, err = s.db.ExecContext(
+		ctx,
+		`UPDATE event SET name = $1, starts_at = $2, ends_at = $3, timezone = $4
+		WHERE tenant_id = $5 AND id = $6`,
+		event.Name, toUnix(event.Start), toUnix(event.End), event.Timezone,
+		event.TenantID, event.ID,
+	)
+	if err != nil {
+		if constraintError(err) == database.ErrNameExists {
+			level.Debug(s.logger).Log("err", err)
+			return database.ErrNameExists
+		}
+		level.Error(s.logger).Log("err", err)
+		return database.ErrRepository
+	}
+
+	cnt, err = res.RowsAffected()
+	if err != nil {
+		level.Error(s.logger).Log("err", err)
+		return database.ErrRepository
+	}
+
+	if cnt == 0 {
+		level.Debug(s.logger).Log("err", err)
+		return database.ErrNotFound
+	}
+
+	return
+}
+
+func (s *postgres) Delete(
+	ctx context.Context, tenantID uuid.UUID, id uuid.UUID,
+) (err error) {
+	if _, err = s.db.ExecContext(
+		ctx,
+		`DELETE FROM event WHERE tenant_id = $1 AND id = $2`,
+		tenantID, id,
+	); err != nil {
+		level.Error(s.logger).Log("err", err)
+		return database.ErrRepository
+	}
+
+	return
+}
+
+func (s *postgres) List(
+	ctx context.Context, tenantID uuid.UUID,
+) (events []*database.Event, err error) {
+	var rows *sql.Rows
+
+	if uuid.Equal(tenantID, uuid.Nil) {
+		// listing all events
+		rows, err = s.db.QueryContext(
+			ctx,
+			`SELECT id, tenant_id, name, starts_at, ends_at, timezone FROM event
+			ORDER BY tenant_id, name`,
+		)
+	} else {
+		// listing owned events
+		rows, err = s.db.QueryContext(
+			ctx,
+			`SELECT id, tenant_id, name, starts_at, ends_at, timezone FROM event
+			WHERE tenant_id = $1 ORDER BY name`,
+			tenantID,
+		)
+	}
+	if err != nil {
+		level.Error(s.logger).Log("err", err)
+		return nil, database.ErrRepository
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			event      database.Event
+			start, end int64
+		)
+		if err = rows.Scan(
+			&event.ID, &event.TenantID, &event.Name, &start, &end,
+			&event.Timezone,
+		); err != nil {
+			level.Error(s.logger).Log("err", err)
+			return nil, database.ErrRepository
+		}
+		event.Start, event.End = fromUnix(start), fromUnix(end)
+		events = append(events, &event)
+	}
+	if err = rows.Err(); err != nil {
+		level.Error(s.logger).Log("err", err)
+		return nil, database.ErrRepository
+	}
+
+	return events, nil
+}
+
+func (s *postgres) ListPage(
+	ctx context.Context, tenantID, after uuid.UUID, limit int,
+) ([]*database.Event, error) {
+	rows, err := s.db.QueryContext(
+		ctx,
+		`SELECT id, tenant_id, name, starts_at, ends_at, timezone FROM event
+		WHERE tenant_id = $1 AND id > $2 ORDER BY id LIMIT $3`,
+		tenantID, after, limit,
+	)
+	if err != nil {
+		level.Error(s.logger).Log("err", err)
+		return nil, database.ErrRepository
+	}
+	defer rows.Close()
+
+	events := make([]*database.Event, 0, limit)
+	for rows.Next() {
+		var (
+			event      database.Event
+			start, end int64
+		)
+		if err = rows.Scan(
+			&event.ID, &event.TenantID, &event.Name, &start, &end,
+			&event.Timezone,
+		); err != nil {
+			level.Error(s.logger).Log("err", err)
+			return nil, database.ErrRepository
+		}
+		event.Start, event.End = fromUnix(start), fromUnix(end)
+		events = append(events, &event)
+	}
+	if err = rows.Err(); err != nil {
+		level.Error(s.logger).Log("err", err)
+		return nil, database.ErrRepository
+	}
+
+	return events, nil
+}
+
+func (s *postgres) CreateBatch(
+	ctx context.Context, events []database.Event, dryRun bool,
+) (map[int]error, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		level.Error(s.logger).Log("err", err)
+		return nil, database.ErrRepository
+	}
+	defer tx.Rollback()
+
+	rowErrs := make(map[int]error)
+	for idx := range events {
+		// check if we need to create a new UUID
+		if uuid.Equal(events[idx].ID, uuid.Nil) {
+			events[idx].ID = uuid.NewV4()
+		}
+
+		// a failing statement aborts the whole PostgreSQL transaction, so each
+		// insert runs within a savepoint we can roll back to on constraint
+		// violations and continue validating the remaining events.
+		if _, err = tx.ExecContext(ctx, `SAVEPOINT batch_event`); err != nil {
+			level.Error(s.logger).Log("err", err)
+			return nil, database.ErrRepository
+		}
+		switch err = insertEvent(ctx, tx, events[idx]); err {
+		case nil:
+			_, err = tx.ExecContext(ctx, `RELEASE SAVEPOINT batch_event`)
+		case database.ErrNameExists, database.ErrIDExists:
+			rowErrs[idx] = err
+			_, err = tx.ExecContext(ctx, `ROLLBACK TO SAVEPOINT batch_event`)
+		}
+		if err != nil {
+			level.Error(s.logger).Log("err", err)
+			return nil, database.ErrRepository
+		}
+	}
+
+	if dryRun || len(rowErrs) > 0 {
+		return rowErrs, nil
+	}
+
+	if err = tx.Commit(); err != nil {
+		level.Error(s.logger).Log("err", err)
+		return nil, database.ErrRepository
+	}
+	return rowErrs, nil
+}
+
+func (s *postgres) CreateTemplate(
+	ctx context.Context, template database.Template,
+) (*uuid.UUID, error) {
+	// check if we need to create a new UUID
+	if uuid.Equal(template.ID, uuid.Nil) {
+		template.ID = uuid.NewV4()
+	}
+
+	if _, err := s.db.ExecContext(
+		ctx,
+		`INSERT INTO event_template (
+			id, tenant_id, name, rrule, starts_at, ends_at, timezone
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		template.ID, template.TenantID, template.Name, template.RRule,
+		toUnix(template.Start), toUnix(template.End), template.Timezone,
+	); err != nil {
+		switch cErr := constraintError(err); cErr {
+		case database.ErrNameExists, database.ErrIDExists:
+			level.Debug(s.logger).Log("err", err)
+			return nil, cErr
+		}
+		level.Error(s.logger).Log("err", err)
+		return nil, database.ErrRepository
+	}
+
+	return &template.ID, nil
+}
+
+func (s *postgres) GetTemplate(
+	ctx context.Context, id uuid.UUID,
+) (*database.Template, error) {
+	var (
+		template          = database.Template{ID: id}
+		start, end, until int64
+	)
+
+	if err := s.db.QueryRowContext(
+		ctx,
+		`SELECT tenant_id, name, rrule, starts_at, ends_at, timezone,
+		materialized_until, failure FROM event_template WHERE id = $1`,
+		id,
+	).Scan(
+		&template.TenantID, &template.Name, &template.RRule, &start, &end,
+		&template.Timezone, &until, &template.Failure,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			level.Debug(s.logger).Log("err", err)
+			return nil, database.ErrNotFound
+		}
+		level.Error(s.logger).Log("err", err)
+		return nil, database.ErrRepository
+	}
+	template.Start, template.End = fromUnix(start), fromUnix(end)
+	template.MaterializedUntil = fromUnix(until)
+
+	return &template, nil
+}
+
+func (s *postgres) ListTemplates(
+	ctx context.Context, tenantID uuid.UUID,
+) (templates []*database.Template, err error) {
+	var rows *sql.Rows
+
+	if uuid.Equal(tenantID, uuid.Nil) {
+		// listing all templates
+		rows, err = s.db.QueryContext(
+			ctx,
+			`SELECT id, tenant_id, name, rrule, starts_at, ends_at, timezone,
+			materialized_until, failure FROM event_template
+			ORDER BY tenant_id, name`,
+		)
+	} else {
+		// listing owned templates
+		rows, err = s.db.QueryContext(
+			ctx,
+			`SELECT id, tenant_id, name, rrule, starts_at, ends_at, timezone,
+			materialized_until, failure FROM event_template WHERE tenant_id = $1
+			ORDER BY name`,
+			tenantID,
+		)
+	}
+	if err != nil {
+		level.Error(s.logger).Log("err", err)
+		return nil, database.ErrRepository
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			template          database.Template
+			start, end, until int64
+		)
+		if err = rows.Scan(
+			&template.ID, &template.TenantID, &template.Name, &template.RRule,
+			&start, &end, &template.Timezone, &until, &template.Failure,
+		); err != nil {
+			level.Error(s.logger).Log("err", err)
+			return nil, database.ErrRepository
+		}
+		template.Start, template.End = fromUnix(start), fromUnix(end)
+		template.MaterializedUntil = fromUnix(until)
+		templates = append(templates, &template)
+	}
+	if err = rows.Err(); err != nil {
+		level.Error(s.logger).Log("err", err)
+		return nil, database.ErrRepository
+	}
+
+	return templates, nil
+}
+
+func (s *postgres) DeleteTemplate(
+	ctx context.Context, tenantID uuid.UUID, id uuid.UUID,
+) (err error) {
+	if _, err = s.db.ExecContext(
+		ctx,
+		`DELETE FROM event_template WHERE tenant_id = $1 AND id = $2`,
+		tenantID, id,
+	); err != nil {
+		level.Error(s.logger).Log("err", err)
+		return database.ErrRepository
+	}
+
+	return
+}
+
+func (s *postgres) MaterializeTemplate(
+	ctx context.Context, templateID uuid.UUID, events []database.Event,
+	until time.Time,
+) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		level.Error(s.logger).Log("err", err)
+		return database.ErrRepository
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(
+		ctx,
+		`UPDATE event_template SET materialized_until = $1, failure = ''
+		WHERE id = $2`,
+		toUnix(until), templateID,
+	)
+	if err != nil {
+		level.Error(s.logger).Log("err", err)
+		return database.ErrRepository
+	}
+	if cnt, err := res.RowsAffected(); err != nil || cnt == 0 {
+		if err != nil {
+			level.Error(s.logger).Log("err", err)
+			return database.ErrRepository
+		}
+		return database.ErrNotFound
+	}
+
+	for idx := range events {
+		// check if we need to create a new UUID
+		if uuid.Equal(events[idx].ID, uuid.Nil) {
+			events[idx].ID = uuid.NewV4()
+		}
+		switch err = insertEvent(ctx, tx, events[idx]); err {
+		case nil:
+		case database.ErrNameExists, database.ErrIDExists:
+			level.Debug(s.logger).Log("err", err)
+			return err
+		default:
+			level.Error(s.logger).Log("err", err)
+			return database.ErrRepository
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		level.Error(s.logger).Log("err", err)
+		return database.ErrRepository
+	}
+	return nil
+}
+
+func (s *postgres) FailTemplate(
+	ctx context.Context, templateID uuid.UUID, failure string,
+) error {
+	res, err := s.db.ExecContext(
+		ctx,
+		`UPDATE event_template SET failure = $1 WHERE id = $2`,
+		failure, templateID,
+	)
+	if err != nil {
+		level.Error(s.logger).Log("err", err)
+		return database.ErrRepository
+	}
+	if cnt, err := res.RowsAffected(); err != nil || cnt == 0 {
+		if err != nil {
+			level.Error(s.logger).Log("err", err)
+			return database.ErrRepository
+		}
+		return database.ErrNotFound
+	}
+
+	return nil
+}
+
+// insertEvent inserts the event. Constraint violations are mapped to their
+// repository errors, other errors are returned as is.
+func insertEvent(
+	ctx context.Context, execer sqlx.ExecerContext, event database.Event,
+) error {
+	if _, err := execer.ExecContext(
+		ctx,
+		`INSERT INTO event (id, tenant_id, name, starts_at, ends_at, timezone)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		event.ID, event.TenantID, event.Name,
+		toUnix(event.Start), toUnix(event.End), event.Timezone,
+	); err != nil {
+		return constraintError(err)
+	}
+	return nil
+}
+
+// constraintError maps unique violations to their repository errors. Primary
+// key violations become ErrIDExists, violations of our name indexes become
+// ErrNameExists. Other errors are returned as is.
+func constraintError(err error) error {
+	pqErr, ok := err.(*pq.Error)
+	if !ok || pqErr.Code != uniqueViolation {
+		return err
+	}
+	if strings.HasSuffix(pqErr.Constraint, "_pkey") {
+		return database.ErrIDExists
+	}
+	return database.ErrNameExists
+}
+
+// toUnix returns the unix seconds of t or 0 if t is not set.
+func toUnix(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+// fromUnix returns the UTC time for the unix seconds or the zero time for 0.
+func fromUnix(sec int64) time.Time {
+	if sec == 0 {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0).UTC()
+}
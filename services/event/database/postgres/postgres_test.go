@@ -0,0 +1,38 @@
+package postgres
+
+import (
+	// stdlib
+	"os"
+	"testing"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/jmoiron/sqlx"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/services/event/database/databasetest"
+)
+
+// TestConformance runs the Repository conformance checks against the
+// PostgreSQL database in OCG_POSTGRES_DSN. The checks create their own
+// tenants, only point it to a scratch database.
+func TestConformance(t *testing.T) {
+	dsn := os.Getenv("OCG_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("OCG_POSTGRES_DSN not set")
+	}
+
+	db, err := sqlx.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// repository logging is silenced as the checks trigger errors on purpose
+	repo, err := New(db, log.NewNopLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	databasetest.Run(t, repo)
+}
@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	// external
+	"github.com/jmoiron/sqlx"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/shared/migrate"
+)
+
+// Schema holds the event schema migrations. The versions mirror the SQLite
+// migrations so both backends report the same schema version.
+var Schema = migrate.Schema{
+	Name: "ocgokitexample.event",
+	Migrations: []migrate.Migration{
+		{
+			Version: 1, Description: "add event table",
+			Creates: "event", Up: v1,
+		},
+		{
+			Version: 2, Description: "add event dates",
+			Creates: "event.starts_at", Up: v2, Down: v2Down,
+		},
+		{
+			Version: 3, Description: "add recurring event templates",
+			Creates: "event_template", Up: v3, Down: v3Down,
+		},
+		{
+			Version: 4, Description: "add event template failures",
+			Creates: "event_template.failure", Up: v4, Down: v4Down,
+		},
+	},
+}
+
+func v1(tx *sqlx.Tx) (err error) {
+	// add event table
+	if _, err = tx.Exec(`
+		CREATE TABLE event (
+			id UUID NOT NULL, tenant_id UUID NOT NULL, name TEXT NOT NULL,
+			CONSTRAINT event_pkey PRIMARY KEY (id)
+		);`,
+	); err != nil {
+		return
+	}
+
+	if _, err = tx.Exec(
+		`CREATE UNIQUE INDEX uidx_event_name ON event (tenant_id, lower(name));`,
+	); err != nil {
+		return
+	}
+
+	return
+}
+
+func v2(tx *sqlx.Tx) (err error) {
+	// add event dates, stored as unix seconds with 0 meaning not set
+	if _, err = tx.Exec(`
+		ALTER TABLE event
+			ADD COLUMN starts_at BIGINT NOT NULL DEFAULT 0,
+			ADD COLUMN ends_at BIGINT NOT NULL DEFAULT 0,
+			ADD COLUMN timezone TEXT NOT NULL DEFAULT '';`,
+	); err != nil {
+		return
+	}
+
+	return
+}
+
+func v3(tx *sqlx.Tx) (err error) {
+	// add recurring event templates
+	if _, err = tx.Exec(`
+		CREATE TABLE event_template (
+			id UUID NOT NULL, tenant_id UUID NOT NULL, name TEXT NOT NULL,
+			rrule TEXT NOT NULL, starts_at BIGINT NOT NULL,
+			ends_at BIGINT NOT NULL DEFAULT 0, timezone TEXT NOT NULL DEFAULT '',
+			materialized_until BIGINT NOT NULL DEFAULT 0,
+			CONSTRAINT event_template_pkey PRIMARY KEY (id)
+		);`,
+	); err != nil {
+		return
+	}
+
+	if _, err = tx.Exec(
+		`CREATE UNIQUE INDEX uidx_event_template_name
+		ON event_template (tenant_id, lower(name));`,
+	); err != nil {
+		return
+	}
+
+	return
+}
+
+func v4(tx *sqlx.Tx) (err error) {
+	// add the reason the last materialization of a template failed
+	_, err = tx.Exec(
+		`ALTER TABLE event_template ADD COLUMN failure TEXT NOT NULL DEFAULT ''`,
+	)
+	return
+}
+
+func v2Down(tx *sqlx.Tx) (err error) {
+	_, err = tx.Exec(`
+		ALTER TABLE event
+			DROP COLUMN starts_at, DROP COLUMN ends_at, DROP COLUMN timezone;`,
+	)
+	return
+}
+
+func v3Down(tx *sqlx.Tx) (err error) {
+	_, err = tx.Exec(`DROP TABLE event_template;`)
+	return
+}
+
+func v4Down(tx *sqlx.Tx) (err error) {
+	_, err = tx.Exec(`ALTER TABLE event_template DROP COLUMN failure;`)
+	return
+}
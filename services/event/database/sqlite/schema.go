@@ -3,8 +3,34 @@ package sqlite
 import (
 	// external
 	"github.com/jmoiron/sqlx"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/shared/migrate"
 )
 
+// Schema holds the event schema migrations.
+var Schema = migrate.Schema{
+	Name: "ocgokitexample.event",
+	Migrations: []migrate.Migration{
+		{
+			Version: 1, Description: "add event table",
+			Creates: "event", Up: v1,
+		},
+		{
+			Version: 2, Description: "add event dates",
+			Creates: "event.starts_at", Up: v2, Down: v2Down,
+		},
+		{
+			Version: 3, Description: "add recurring event templates",
+			Creates: "event_template", Up: v3, Down: v3Down,
+		},
+		{
+			Version: 4, Description: "add event template failures",
+			Creates: "event_template.failure", Up: v4, Down: v4Down,
+		},
+	},
+}
+
 func v1(tx *sqlx.Tx) (err error) {
 	// add event table
 	if _, err = tx.Exec(`
@@ -24,3 +50,72 @@ func v1(tx *sqlx.Tx) (err error) {
 
 	return
 }
+
+func v2(tx *sqlx.Tx) (err error) {
+	// add event dates, stored as unix seconds with 0 meaning not set
+	for _, column := range []string{
+		`starts_at INTEGER NOT NULL DEFAULT 0`,
+		`ends_at INTEGER NOT NULL DEFAULT 0`,
+		`timezone TEXT NOT NULL DEFAULT ''`,
+	} {
+		if _, err = tx.Exec(`ALTER TABLE event ADD COLUMN ` + column); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func v3(tx *sqlx.Tx) (err error) {
+	// add recurring event templates
+	if _, err = tx.Exec(`
+		CREATE TABLE event_template (
+			id BLOB NOT NULL, tenant_id BLOB NOT NULL, name TEXT NOT NULL,
+			rrule TEXT NOT NULL, starts_at INTEGER NOT NULL,
+			ends_at INTEGER NOT NULL DEFAULT 0, timezone TEXT NOT NULL DEFAULT '',
+			materialized_until INTEGER NOT NULL DEFAULT 0,
+			PRIMARY KEY(id)
+		) WITHOUT ROWID;`,
+	); err != nil {
+		return
+	}
+
+	if _, err = tx.Exec(
+		`CREATE UNIQUE INDEX uidx_event_template_name
+		ON event_template (tenant_id, lower(name));`,
+	); err != nil {
+		return
+	}
+
+	return
+}
+
+func v4(tx *sqlx.Tx) (err error) {
+	// add the reason the last materialization of a template failed
+	_, err = tx.Exec(
+		`ALTER TABLE event_template ADD COLUMN failure TEXT NOT NULL DEFAULT ''`,
+	)
+	return
+}
+
+func v2Down(tx *sqlx.Tx) (err error) {
+	// requires SQLite 3.35 or later
+	for _, column := range []string{"starts_at", "ends_at", "timezone"} {
+		if _, err = tx.Exec(`ALTER TABLE event DROP COLUMN ` + column); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func v3Down(tx *sqlx.Tx) (err error) {
+	_, err = tx.Exec(`DROP TABLE event_template;`)
+	return
+}
+
+func v4Down(tx *sqlx.Tx) (err error) {
+	// requires SQLite 3.35 or later
+	_, err = tx.Exec(`ALTER TABLE event_template DROP COLUMN failure`)
+	return
+}
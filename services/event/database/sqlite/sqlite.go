@@ -4,6 +4,7 @@ import (
 	// stdlib
 	"context"
 	"database/sql"
+	"time"
 
 	// external
 	"github.com/go-kit/kit/log"
@@ -11,10 +12,10 @@ import (
 	"github.com/jmoiron/sqlx"
 	"github.com/kevinburke/go.uuid"
 	"github.com/mattn/go-sqlite3"
-	"github.com/openxact/versioning"
 
 	// project
 	"github.com/basvanbeek/opencensus-gokit-example/services/event/database"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/migrate"
 )
 
 type sqlite struct {
@@ -24,15 +25,10 @@ type sqlite struct {
 
 // New returns a new Repository backed by SQLite
 func New(db *sqlx.DB, logger log.Logger) (database.Repository, error) {
-	// run our embedded database versioning logic
-	versioner, err := versioning.New(
-		db, "ocgokitexample.event", level.Debug(logger), false,
-	)
-	if err != nil {
-		return nil, err
-	}
-	versioner.Add(1, v1)
-	if _, err = versioner.Run(); err != nil {
+	// run our embedded database migrations
+	if err := migrate.Up(
+		context.Background(), db, migrate.SQLite, Schema, logger,
+	); err != nil {
 		return nil, err
 	}
 
@@ -48,21 +44,12 @@ func (s *sqlite) Create(
 		event.ID = uuid.NewV4()
 	}
 
-	if _, err = s.db.ExecContext(
-		ctx,
-		`INSERT INTO event (id, tenant_id, name) VALUES (?, ?, ?)`,
-		event.ID.Bytes(), event.TenantID.Bytes(), event.Name,
-	); err != nil {
-		if sqlErr, ok := err.(sqlite3.Error); ok {
-			switch sqlErr.ExtendedCode {
-			case sqlite3.ErrConstraintUnique:
-				level.Debug(s.logger).Log("err", err)
-				return nil, database.ErrNameExists
-			case sqlite3.ErrConstraintPrimaryKey:
-				level.Debug(s.logger).Log("err", err)
-				return nil, database.ErrIDExists
-			}
-		}
+	switch err = insertEvent(ctx, s.db, event); err {
+	case nil:
+	case database.ErrNameExists, database.ErrIDExists:
+		level.Debug(s.logger).Log("err", err)
+		return nil, err
+	default:
 		level.Error(s.logger).Log("err", err)
 		return nil, database.ErrRepository
 	}
@@ -71,12 +58,19 @@ func (s *sqlite) Create(
 }
 
 func (s *sqlite) Get(ctx context.Context, id uuid.UUID) (*database.Event, error) {
-	event := database.Event{ID: id}
+	var (
+		event      = database.Event{ID: id}
+		start, end int64
+	)
 
 	if err := s.db.QueryRowContext(
 		ctx,
-		`SELECT tenant_id, name FROM event WHERE id = ?`, id.Bytes(),
-	).Scan(&event.TenantID, &event.Name); err != nil {
+		`SELECT tenant_id, name, starts_at, ends_at, timezone FROM event
+		WHERE id = ?`,
+		id.Bytes(),
+	).Scan(
+		&event.TenantID, &event.Name, &start, &end, &event.Timezone,
+	); err != nil {
 		if err == sql.ErrNoRows {
 			level.Debug(s.logger).Log("err", err)
 			return nil, database.ErrNotFound
@@ -84,6 +78,7 @@ func (s *sqlite) Get(ctx context.Context, id uuid.UUID) (*database.Event, error)
 		level.Error(s.logger).Log("err", err)
 		return nil, database.ErrRepository
 	}
+	event.Start, event.End = fromUnix(start), fromUnix(end)
 
 	return &event, nil
 }
@@ -96,8 +91,10 @@ func (s *sqlite) Update(ctx context.Context, event database.Event) (err error) {
 
 	res, err = s.db.ExecContext(
 		ctx,
-		`UPDATE event SET name = ? WHERE tenant_id = ? AND id = ?`,
-		event.Name, event.TenantID.Bytes(), event.ID.Bytes(),
+		`UPDATE event SET name = ?, starts_at = ?, ends_at = ?, timezone = ?
+		WHERE tenant_id = ? AND id = ?`,
+		event.Name, toUnix(event.Start), toUnix(event.End), event.Timezone,
+		event.TenantID.Bytes(), event.ID.Bytes(),
 	)
 	if err != nil {
 		if sqlErr, ok := err.(sqlite3.Error); ok {
@@ -148,13 +145,15 @@ func (s *sqlite) List(
 		// listing all events
 		rows, err = s.db.QueryContext(
 			ctx,
-			`SELECT id, tenant_id, name FROM event ORDER BY tenant_id, name`,
+			`SELECT id, tenant_id, name, starts_at, ends_at, timezone FROM event
+			ORDER BY tenant_id, name`,
 		)
 	} else {
 		// listing owned events
 		rows, err = s.db.QueryContext(
 			ctx,
-			`SELECT id, tenant_id, name FROM event WHERE tenant_id = ? ORDER BY name`,
+			`SELECT id, tenant_id, name, starts_at, ends_at, timezone FROM event
+			WHERE tenant_id = ? ORDER BY name`,
 			tenantID.Bytes(),
 		)
 	}
@@ -168,15 +167,347 @@ func (s *sqlite) List(
 	defer rows.Close()
 
 	for rows.Next() {
-		var event database.Event
+		var (
+			event      database.Event
+			start, end int64
+		)
 		if err = rows.Scan(
-			&event.ID, &event.TenantID, &event.Name,
+			&event.ID, &event.TenantID, &event.Name, &start, &end,
+			&event.Timezone,
 		); err != nil {
 			level.Error(s.logger).Log("err", err)
 			return nil, database.ErrRepository
 		}
+		event.Start, event.End = fromUnix(start), fromUnix(end)
 		events = append(events, &event)
 	}
 
 	return events, nil
 }
+
+func (s *sqlite) ListPage(
+	ctx context.Context, tenantID, after uuid.UUID, limit int,
+) ([]*database.Event, error) {
+	rows, err := s.db.QueryContext(
+		ctx,
+		`SELECT id, tenant_id, name, starts_at, ends_at, timezone FROM event
+		WHERE tenant_id = ? AND id > ? ORDER BY id LIMIT ?`,
+		tenantID.Bytes(), after.Bytes(), limit,
+	)
+	if err != nil {
+		level.Error(s.logger).Log("err", err)
+		return nil, database.ErrRepository
+	}
+	defer rows.Close()
+
+	events := make([]*database.Event, 0, limit)
+	for rows.Next() {
+		var (
+			event      database.Event
+			start, end int64
+		)
+		if err = rows.Scan(
+			&event.ID, &event.TenantID, &event.Name, &start, &end,
+			&event.Timezone,
+		); err != nil {
+			level.Error(s.logger).Log("err", err)
+			return nil, database.ErrRepository
+		}
+		event.Start, event.End = fromUnix(start), fromUnix(end)
+		events = append(events, &event)
+	}
+	if err = rows.Err(); err != nil {
+		level.Error(s.logger).Log("err", err)
+		return nil, database.ErrRepository
+	}
+
+	return events, nil
+}
+
+func (s *sqlite) CreateBatch(
+	ctx context.Context, events []database.Event, dryRun bool,
+) (map[int]error, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		level.Error(s.logger).Log("err", err)
+		return nil, database.ErrRepository
+	}
+	defer tx.Rollback()
+
+	rowErrs := make(map[int]error)
+	for idx := range events {
+		// check if we need to create a new UUID
+		if uuid.Equal(events[idx].ID, uuid.Nil) {
+			events[idx].ID = uuid.NewV4()
+		}
+
+		// constraint violations only abort the failing statement, so we can
+		// continue validating the remaining events within the transaction.
+		switch err = insertEvent(ctx, tx, events[idx]); err {
+		case nil:
+		case database.ErrNameExists, database.ErrIDExists:
+			rowErrs[idx] = err
+		default:
+			level.Error(s.logger).Log("err", err)
+			return nil, database.ErrRepository
+		}
+	}
+
+	if dryRun || len(rowErrs) > 0 {
+		return rowErrs, nil
+	}
+
+	if err = tx.Commit(); err != nil {
+		level.Error(s.logger).Log("err", err)
+		return nil, database.ErrRepository
+	}
+	return rowErrs, nil
+}
+
+func (s *sqlite) CreateTemplate(
+	ctx context.Context, template database.Template,
+) (*uuid.UUID, error) {
+	// check if we need to create a new UUID
+	if uuid.Equal(template.ID, uuid.Nil) {
+		template.ID = uuid.NewV4()
+	}
+
+	if _, err := s.db.ExecContext(
+		ctx,
+		`INSERT INTO event_template (
+			id, tenant_id, name, rrule, starts_at, ends_at, timezone
+		) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		template.ID.Bytes(), template.TenantID.Bytes(), template.Name,
+		template.RRule, toUnix(template.Start), toUnix(template.End),
+		template.Timezone,
+	); err != nil {
+		if sqlErr, ok := err.(sqlite3.Error); ok {
+			switch sqlErr.ExtendedCode {
+			case sqlite3.ErrConstraintUnique:
+				level.Debug(s.logger).Log("err", err)
+				return nil, database.ErrNameExists
+			case sqlite3.ErrConstraintPrimaryKey:
+				level.Debug(s.logger).Log("err", err)
+				return nil, database.ErrIDExists
+			}
+		}
+		level.Error(s.logger).Log("err", err)
+		return nil, database.ErrRepository
+	}
+
+	return &template.ID, nil
+}
+
+func (s *sqlite) GetTemplate(
+	ctx context.Context, id uuid.UUID,
+) (*database.Template, error) {
+	var (
+		template          = database.Template{ID: id}
+		start, end, until int64
+	)
+
+	if err := s.db.QueryRowContext(
+		ctx,
+		`SELECT tenant_id, name, rrule, starts_at, ends_at, timezone,
+		materialized_until, failure FROM event_template WHERE id = ?`,
+		id.Bytes(),
+	).Scan(
+		&template.TenantID, &template.Name, &template.RRule, &start, &end,
+		&template.Timezone, &until, &template.Failure,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			level.Debug(s.logger).Log("err", err)
+			return nil, database.ErrNotFound
+		}
+		level.Error(s.logger).Log("err", err)
+		return nil, database.ErrRepository
+	}
+	template.Start, template.End = fromUnix(start), fromUnix(end)
+	template.MaterializedUntil = fromUnix(until)
+
+	return &template, nil
+}
+
+func (s *sqlite) ListTemplates(
+	ctx context.Context, tenantID uuid.UUID,
+) (templates []*database.Template, err error) {
+	var rows *sql.Rows
+
+	if uuid.Equal(tenantID, uuid.Nil) {
+		// listing all templates
+		rows, err = s.db.QueryContext(
+			ctx,
+			`SELECT id, tenant_id, name, rrule, starts_at, ends_at, timezone,
+			materialized_until, failure FROM event_template
+			ORDER BY tenant_id, name`,
+		)
+	} else {
+		// listing owned templates
+		rows, err = s.db.QueryContext(
+			ctx,
+			`SELECT id, tenant_id, name, rrule, starts_at, ends_at, timezone,
+			materialized_until, failure FROM event_template WHERE tenant_id = ?
+			ORDER BY name`,
+			tenantID.Bytes(),
+		)
+	}
+	if err != nil {
+		level.Error(s.logger).Log("err", err)
+		return nil, database.ErrRepository
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			template          database.Template
+			start, end, until int64
+		)
+		if err = rows.Scan(
+			&template.ID, &template.TenantID, &template.Name, &template.RRule,
+			&start, &end, &template.Timezone, &until, &template.Failure,
+		); err != nil {
+			level.Error(s.logger).Log("err", err)
+			return nil, database.ErrRepository
+		}
+		template.Start, template.End = fromUnix(start), fromUnix(end)
+		template.MaterializedUntil = fromUnix(until)
+		templates = append(templates, &template)
+	}
+	if err = rows.Err(); err != nil {
+		level.Error(s.logger).Log("err", err)
+		return nil, database.ErrRepository
+	}
+
+	return templates, nil
+}
+
+func (s *sqlite) DeleteTemplate(
+	ctx context.Context, tenantID uuid.UUID, id uuid.UUID,
+) (err error) {
+	if _, err = s.db.ExecContext(
+		ctx,
+		`DELETE FROM event_template WHERE tenant_id = ? AND id = ?`,
+		tenantID.Bytes(), id.Bytes(),
+	); err != nil {
+		level.Error(s.logger).Log("err", err)
+		return database.ErrRepository
+	}
+
+	return
+}
+
+func (s *sqlite) MaterializeTemplate(
+	ctx context.Context, templateID uuid.UUID, events []database.Event,
+	until time.Time,
+) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		level.Error(s.logger).Log("err", err)
+		return database.ErrRepository
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(
+		ctx,
+		`UPDATE event_template SET materialized_until = ?, failure = ''
+		WHERE id = ?`,
+		toUnix(until), templateID.Bytes(),
+	)
+	if err != nil {
+		level.Error(s.logger).Log("err", err)
+		return database.ErrRepository
+	}
+	if cnt, err := res.RowsAffected(); err != nil || cnt == 0 {
+		if err != nil {
+			level.Error(s.logger).Log("err", err)
+			return database.ErrRepository
+		}
+		return database.ErrNotFound
+	}
+
+	for idx := range events {
+		// check if we need to create a new UUID
+		if uuid.Equal(events[idx].ID, uuid.Nil) {
+			events[idx].ID = uuid.NewV4()
+		}
+		switch err = insertEvent(ctx, tx, events[idx]); err {
+		case nil:
+		case database.ErrNameExists, database.ErrIDExists:
+			level.Debug(s.logger).Log("err", err)
+			return err
+		default:
+			level.Error(s.logger).Log("err", err)
+			return database.ErrRepository
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		level.Error(s.logger).Log("err", err)
+		return database.ErrRepository
+	}
+	return nil
+}
+
+func (s *sqlite) FailTemplate(
+	ctx context.Context, templateID uuid.UUID, failure string,
+) error {
+	res, err := s.db.ExecContext(
+		ctx,
+		`UPDATE event_template SET failure = ? WHERE id = ?`,
+		failure, templateID.Bytes(),
+	)
+	if err != nil {
+		level.Error(s.logger).Log("err", err)
+		return database.ErrRepository
+	}
+	if cnt, err := res.RowsAffected(); err != nil || cnt == 0 {
+		if err != nil {
+			level.Error(s.logger).Log("err", err)
+			return database.ErrRepository
+		}
+		return database.ErrNotFound
+	}
+
+	return nil
+}
+
+// insertEvent inserts the event. Constraint violations are mapped to their
+// repository errors, other errors are returned as is.
+func insertEvent(
+	ctx context.Context, execer sqlx.ExecerContext, event database.Event,
+) error {
+	if _, err := execer.ExecContext(
+		ctx,
+		`INSERT INTO event (id, tenant_id, name, starts_at, ends_at, timezone)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		event.ID.Bytes(), event.TenantID.Bytes(), event.Name,
+		toUnix(event.Start), toUnix(event.End), event.Timezone,
+	); err != nil {
+		if sqlErr, ok := err.(sqlite3.Error); ok {
+			switch sqlErr.ExtendedCode {
+			case sqlite3.ErrConstraintUnique:
+				return database.ErrNameExists
+			case sqlite3.ErrConstraintPrimaryKey:
+				return database.ErrIDExists
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+// toUnix returns the unix seconds of t or 0 if t is not set.
+func toUnix(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+// fromUnix returns the UTC time for the unix seconds or the zero time for 0.
+func fromUnix(sec int64) time.Time {
+	if sec == 0 {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0).UTC()
+}
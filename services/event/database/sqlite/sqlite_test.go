@@ -0,0 +1,38 @@
+package sqlite
+
+import (
+	// stdlib
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/jmoiron/sqlx"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/services/event/database/databasetest"
+)
+
+func TestConformance(t *testing.T) {
+	dir, err := ioutil.TempDir("", "event")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	db, err := sqlx.Open("sqlite3", filepath.Join(dir, "event.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// repository logging is silenced as the checks trigger errors on purpose
+	repo, err := New(db, log.NewNopLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	databasetest.Run(t, repo)
+}
@@ -0,0 +1,119 @@
+package implementation
+
+import (
+	// stdlib
+	"context"
+	"encoding/json"
+	"time"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/kevinburke/go.uuid"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/services/event"
+	"github.com/basvanbeek/opencensus-gokit-example/services/webhook"
+)
+
+type notifier struct {
+	event.Service
+	publisher webhook.Publisher
+	logger    log.Logger
+}
+
+// NotifyMiddleware publishes webhook notifications for successful event
+// mutations. Failing to publish is logged but does not fail the mutation.
+func NotifyMiddleware(publisher webhook.Publisher, logger log.Logger) event.Middleware {
+	return func(next event.Service) event.Service {
+		return &notifier{
+			Service:   next,
+			publisher: publisher,
+			logger:    log.With(logger, "middleware", "notify"),
+		}
+	}
+}
+
+func (n *notifier) Create(
+	ctx context.Context, tenantID uuid.UUID, e event.Event,
+) (*uuid.UUID, error) {
+	id, err := n.Service.Create(ctx, tenantID, e)
+	if err == nil {
+		e.ID, e.TenantID = *id, tenantID
+		n.publish(ctx, tenantID, webhook.EventCreated, e)
+	}
+	return id, err
+}
+
+func (n *notifier) Update(ctx context.Context, tenantID uuid.UUID, e event.Event) error {
+	err := n.Service.Update(ctx, tenantID, e)
+	if err == nil {
+		e.TenantID = tenantID
+		n.publish(ctx, tenantID, webhook.EventUpdated, e)
+	}
+	return err
+}
+
+func (n *notifier) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
+	err := n.Service.Delete(ctx, tenantID, id)
+	if err == nil {
+		n.publish(ctx, tenantID, webhook.EventDeleted, event.Event{
+			ID: id, TenantID: tenantID,
+		})
+	}
+	return err
+}
+
+func (n *notifier) Import(
+	ctx context.Context, tenantID uuid.UUID, events []event.Event, dryRun bool,
+) (*event.ImportResult, error) {
+	res, err := n.Service.Import(ctx, tenantID, events, dryRun)
+	if err == nil {
+		// created ids are in row order as imports are all or nothing
+		for idx, id := range res.Created {
+			n.publish(ctx, tenantID, webhook.EventCreated, event.Event{
+				ID: id, TenantID: tenantID, Name: events[idx].Name,
+			})
+		}
+	}
+	return res, err
+}
+
+func (n *notifier) Clone(
+	ctx context.Context, tenantID, id uuid.UUID, name string, start time.Time,
+) (*uuid.UUID, error) {
+	cloneID, err := n.Service.Clone(ctx, tenantID, id, name, start)
+	if err == nil {
+		n.publish(ctx, tenantID, webhook.EventCreated, event.Event{
+			ID: *cloneID, TenantID: tenantID, Name: name,
+		})
+	}
+	return cloneID, err
+}
+
+func (n *notifier) Materialize(
+	ctx context.Context, tenantID, id uuid.UUID, until time.Time,
+) ([]*event.Event, error) {
+	events, err := n.Service.Materialize(ctx, tenantID, id, until)
+	if err == nil {
+		for _, e := range events {
+			n.publish(ctx, tenantID, webhook.EventCreated, *e)
+		}
+	}
+	return events, err
+}
+
+func (n *notifier) publish(
+	ctx context.Context, tenantID uuid.UUID, notificationType string, e event.Event,
+) {
+	payload, err := json.Marshal(e)
+	if err == nil {
+		err = n.publisher.Publish(ctx, tenantID, webhook.Notification{
+			Type:    notificationType,
+			Payload: payload,
+		})
+	}
+	if err != nil {
+		level.Warn(n.logger).Log("type", notificationType, "err", err)
+	}
+}
@@ -0,0 +1,196 @@
+package implementation
+
+import (
+	// stdlib
+	"strconv"
+	"strings"
+	"time"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/services/event"
+)
+
+// supported recurrence frequencies
+const (
+	freqDaily   = "DAILY"
+	freqWeekly  = "WEEKLY"
+	freqMonthly = "MONTHLY"
+	freqYearly  = "YEARLY"
+)
+
+var weekdays = map[string]time.Weekday{
+	"MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// rrule holds the subset of RFC 5545 recurrence rules we support: FREQ,
+// INTERVAL, COUNT, UNTIL and, for weekly rules, BYDAY without ordinals.
+type rrule struct {
+	freq      string
+	interval  int
+	count     int
+	until     time.Time
+	untilDate bool
+	byDay     []time.Weekday
+}
+
+// parseRRule parses rules like "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR". An
+// optional "RRULE:" prefix is accepted.
+func parseRRule(s string) (*rrule, error) {
+	s = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(s)), "RRULE:")
+	if s == "" {
+		return nil, event.ErrInvalidRule
+	}
+
+	r := &rrule{interval: 1}
+	for _, part := range strings.Split(s, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 || kv[1] == "" {
+			return nil, event.ErrInvalidRule
+		}
+		var err error
+		switch kv[0] {
+		case "FREQ":
+			switch kv[1] {
+			case freqDaily, freqWeekly, freqMonthly, freqYearly:
+				r.freq = kv[1]
+			default:
+				return nil, event.ErrInvalidRule
+			}
+		case "INTERVAL":
+			if r.interval, err = strconv.Atoi(kv[1]); err != nil || r.interval < 1 {
+				return nil, event.ErrInvalidRule
+			}
+		case "COUNT":
+			if r.count, err = strconv.Atoi(kv[1]); err != nil || r.count < 1 {
+				return nil, event.ErrInvalidRule
+			}
+		case "UNTIL":
+			if r.until, err = time.Parse("20060102T150405Z", kv[1]); err == nil {
+				break
+			}
+			if r.until, err = time.Parse("20060102", kv[1]); err != nil {
+				return nil, event.ErrInvalidRule
+			}
+			r.untilDate = true
+		case "BYDAY":
+			for _, day := range strings.Split(kv[1], ",") {
+				wd, ok := weekdays[day]
+				if !ok {
+					return nil, event.ErrInvalidRule
+				}
+				r.byDay = append(r.byDay, wd)
+			}
+		default:
+			return nil, event.ErrInvalidRule
+		}
+	}
+	// COUNT and UNTIL are mutually exclusive and BYDAY is only supported for
+	// weekly rules
+	if r.freq == "" || (r.count > 0 && !r.until.IsZero()) ||
+		(len(r.byDay) > 0 && r.freq != freqWeekly) {
+		return nil, event.ErrInvalidRule
+	}
+	return r, nil
+}
+
+// occurrences returns the start times of the instances after "after" up to and
+// including "until", returning at most limit instances. The rule is expanded
+// in local time of loc so instances keep their wall clock time across daylight
+// saving time transitions.
+func (r *rrule) occurrences(
+	dtStart time.Time, loc *time.Location, after, until time.Time, limit int,
+) []time.Time {
+	dtStart = dtStart.In(loc)
+	if !r.until.IsZero() {
+		last := r.until
+		if r.untilDate {
+			// a date includes all instances on that day
+			y, m, d := r.until.Date()
+			last = time.Date(y, m, d, 23, 59, 59, 0, loc)
+		}
+		if last.Before(until) {
+			until = last
+		}
+	}
+
+	var (
+		result []time.Time
+		count  int
+	)
+	// emit reports whether expansion should continue
+	emit := func(t time.Time) bool {
+		if t.After(until) {
+			return false
+		}
+		count++
+		if r.count > 0 && count > r.count {
+			return false
+		}
+		if t.After(after) {
+			result = append(result, t)
+		}
+		return len(result) < limit
+	}
+
+	var (
+		y, m, d = dtStart.Date()
+		hh      = dtStart.Hour()
+		mm      = dtStart.Minute()
+		ss      = dtStart.Second()
+	)
+	for n := 0; ; n++ {
+		step := n * r.interval
+		switch r.freq {
+		case freqDaily:
+			if !emit(time.Date(y, m, d+step, hh, mm, ss, 0, loc)) {
+				return result
+			}
+		case freqWeekly:
+			if len(r.byDay) == 0 {
+				if !emit(time.Date(y, m, d+7*step, hh, mm, ss, 0, loc)) {
+					return result
+				}
+				continue
+			}
+			// weeks start on Monday
+			monday := d - (int(dtStart.Weekday())+6)%7 + 7*step
+			for offset := 0; offset < 7; offset++ {
+				t := time.Date(y, m, monday+offset, hh, mm, ss, 0, loc)
+				if t.Before(dtStart) || !hasWeekday(r.byDay, t.Weekday()) {
+					continue
+				}
+				if !emit(t) {
+					return result
+				}
+			}
+		case freqMonthly, freqYearly:
+			months := step
+			if r.freq == freqYearly {
+				months *= 12
+			}
+			t := time.Date(y, m+time.Month(months), d, hh, mm, ss, 0, loc)
+			if t.Day() != d {
+				// the month has no such day, e.g. February 30th
+				first := time.Date(y, m+time.Month(months), 1, hh, mm, ss, 0, loc)
+				if first.After(until) {
+					return result
+				}
+				continue
+			}
+			if !emit(t) {
+				return result
+			}
+		}
+	}
+}
+
+func hasWeekday(days []time.Weekday, wd time.Weekday) bool {
+	for _, day := range days {
+		if day == wd {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package implementation
+
+import (
+	// stdlib
+	"context"
+	"time"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/kevinburke/go.uuid"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/services/event"
+)
+
+// Scheduler materializes the instances of all recurring event templates a
+// fixed horizon ahead of time.
+type Scheduler struct {
+	svc     event.Service
+	horizon time.Duration
+	logger  log.Logger
+	timeNow func() time.Time
+}
+
+// NewScheduler returns a new Scheduler. The provided service should include
+// the notify middleware so materialized instances are published.
+func NewScheduler(svc event.Service, horizon time.Duration, logger log.Logger) *Scheduler {
+	return &Scheduler{
+		svc:     svc,
+		horizon: horizon,
+		logger:  log.With(logger, "component", "scheduler"),
+		timeNow: time.Now,
+	}
+}
+
+// Run materializes templates every interval until the provided context is
+// canceled.
+func (s *Scheduler) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.MaterializeAll(ctx); err != nil {
+			level.Warn(s.logger).Log("msg", "materialize pass failed", "err", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// MaterializeAll materializes the instances of all templates and returns the
+// amount of events created. Templates failing to materialize, e.g. due to a
+// name conflict, report the failure in their template details and are retried
+// on the next pass.
+func (s *Scheduler) MaterializeAll(ctx context.Context) (int, error) {
+	templates, err := s.svc.ListTemplates(ctx, uuid.Nil)
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		created int
+		until   = s.timeNow().Add(s.horizon)
+	)
+	for _, t := range templates {
+		events, err := s.svc.Materialize(ctx, t.TenantID, t.ID, until)
+		if err != nil {
+			level.Warn(s.logger).Log("template", t.ID, "err", err)
+			continue
+		}
+		created += len(events)
+	}
+	return created, nil
+}
@@ -3,6 +3,9 @@ package implementation
 import (
 	// stdlib
 	"context"
+	"sort"
+	"strings"
+	"time"
 
 	// external
 	"github.com/go-kit/kit/log"
@@ -35,9 +38,16 @@ func (s *service) Create(
 ) (*uuid.UUID, error) {
 	logger := log.With(s.logger, "method", "Create")
 
+	if err := validateDates(&e); err != nil {
+		return nil, err
+	}
+
 	dbEvent := database.Event{
 		TenantID: tenantID,
 		Name:     e.Name,
+		Start:    e.Start,
+		End:      e.End,
+		Timezone: e.Timezone,
 	}
 	id, err := s.repository.Create(ctx, dbEvent)
 	switch err {
@@ -67,7 +77,14 @@ func (s *service) Get(
 			// let's not leak event id's from other tenants.
 			return nil, event.ErrNotFound
 		}
-		return &event.Event{ID: dbEvent.ID, Name: dbEvent.Name}, nil
+		return &event.Event{
+			ID:       dbEvent.ID,
+			TenantID: dbEvent.TenantID,
+			Name:     dbEvent.Name,
+			Start:    dbEvent.Start,
+			End:      dbEvent.End,
+			Timezone: dbEvent.Timezone,
+		}, nil
 	case database.ErrRepository:
 		level.Error(logger).Log("err", err)
 		return nil, event.ErrService
@@ -83,12 +100,19 @@ func (s *service) Get(
 func (s *service) Update(ctx context.Context, tenantID uuid.UUID, e event.Event) error {
 	logger := log.With(s.logger, "method", "Update")
 
+	if err := validateDates(&e); err != nil {
+		return err
+	}
+
 	err := s.repository.Update(
 		ctx,
 		database.Event{
 			ID:       e.ID,
 			TenantID: tenantID,
 			Name:     e.Name,
+			Start:    e.Start,
+			End:      e.End,
+			Timezone: e.Timezone,
 		},
 	)
 
@@ -133,10 +157,173 @@ func (s *service) List(ctx context.Context, tenantID uuid.UUID) ([]*event.Event,
 		events = append(
 			events,
 			&event.Event{
-				ID:   dbEvent.ID,
-				Name: dbEvent.Name,
+				ID:       dbEvent.ID,
+				TenantID: dbEvent.TenantID,
+				Name:     dbEvent.Name,
+				Start:    dbEvent.Start,
+				End:      dbEvent.End,
+				Timezone: dbEvent.Timezone,
 			},
 		)
 	}
 	return events, nil
 }
+
+func (s *service) Import(
+	ctx context.Context, tenantID uuid.UUID, events []event.Event, dryRun bool,
+) (*event.ImportResult, error) {
+	logger := log.With(s.logger, "method", "Import")
+
+	if len(events) > event.MaxImportRows {
+		return nil, event.ErrImportSize
+	}
+
+	var (
+		result   = &event.ImportResult{Rows: len(events)}
+		dbEvents = make([]database.Event, 0, len(events))
+		rows     = make([]int, 0, len(events))
+	)
+	for idx, e := range events {
+		name := strings.Trim(e.Name, "\r\n\t ")
+		if name == "" {
+			result.Errors = append(result.Errors, event.ImportError{
+				Row: idx + 1, Err: event.ErrRequireName,
+			})
+			continue
+		}
+		if err := validateDates(&e); err != nil {
+			result.Errors = append(result.Errors, event.ImportError{
+				Row: idx + 1, Err: err,
+			})
+			continue
+		}
+		dbEvents = append(dbEvents, database.Event{
+			ID:       e.ID,
+			TenantID: tenantID,
+			Name:     name,
+			Start:    e.Start,
+			End:      e.End,
+			Timezone: e.Timezone,
+		})
+		rows = append(rows, idx+1)
+	}
+
+	// rows failing validation still need the remaining rows to be checked
+	// against the repository, but nothing may be stored.
+	rowErrs, err := s.repository.CreateBatch(
+		ctx, dbEvents, dryRun || len(result.Errors) > 0,
+	)
+	if err != nil {
+		level.Error(logger).Log("err", err)
+		return nil, event.ErrService
+	}
+	for idx, rowErr := range rowErrs {
+		switch rowErr {
+		case database.ErrNameExists, database.ErrIDExists:
+			result.Errors = append(result.Errors, event.ImportError{
+				Row: rows[idx], Err: event.ErrEventExists,
+			})
+		default:
+			level.Error(logger).Log("row", rows[idx], "err", rowErr)
+			result.Errors = append(result.Errors, event.ImportError{
+				Row: rows[idx], Err: event.ErrService,
+			})
+		}
+	}
+	if len(result.Errors) > 0 {
+		sort.Slice(result.Errors, func(i, j int) bool {
+			return result.Errors[i].Row < result.Errors[j].Row
+		})
+		level.Debug(logger).Log("rows", result.Rows, "errors", len(result.Errors))
+		return result, nil
+	}
+
+	if !dryRun {
+		result.Created = make([]uuid.UUID, 0, len(dbEvents))
+		for _, e := range dbEvents {
+			result.Created = append(result.Created, e.ID)
+		}
+	}
+	return result, nil
+}
+
+func (s *service) Export(
+	ctx context.Context, tenantID, after uuid.UUID, limit int,
+) ([]*event.Event, error) {
+	logger := log.With(s.logger, "method", "Export")
+
+	if limit <= 0 || limit > event.MaxExportRows {
+		limit = event.MaxExportRows
+	}
+
+	dbEvents, err := s.repository.ListPage(ctx, tenantID, after, limit)
+	if err != nil {
+		level.Error(logger).Log("err", err)
+		return nil, event.ErrService
+	}
+	events := make([]*event.Event, 0, len(dbEvents))
+	for _, dbEvent := range dbEvents {
+		events = append(
+			events,
+			&event.Event{
+				ID:       dbEvent.ID,
+				TenantID: dbEvent.TenantID,
+				Name:     dbEvent.Name,
+				Start:    dbEvent.Start,
+				End:      dbEvent.End,
+				Timezone: dbEvent.Timezone,
+			},
+		)
+	}
+	return events, nil
+}
+
+func (s *service) Clone(
+	ctx context.Context, tenantID, id uuid.UUID, name string, start time.Time,
+) (*uuid.UUID, error) {
+	name = strings.Trim(name, "\r\n\t ")
+	if name == "" {
+		return nil, event.ErrRequireName
+	}
+
+	source, err := s.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	clone := event.Event{
+		Name:     name,
+		Start:    source.Start,
+		End:      source.End,
+		Timezone: source.Timezone,
+	}
+	if !start.IsZero() {
+		// move the clone to the new start, keeping the event's duration
+		if !source.End.IsZero() {
+			clone.End = start.Add(source.End.Sub(source.Start))
+		}
+		clone.Start = start
+	}
+
+	return s.Create(ctx, tenantID, clone)
+}
+
+// validateDates checks the event's dates and time zone and normalizes them.
+func validateDates(e *event.Event) error {
+	if e.Timezone != "" {
+		if _, err := time.LoadLocation(e.Timezone); err != nil {
+			return event.ErrInvalidZone
+		}
+	}
+	if !e.Start.IsZero() {
+		e.Start = e.Start.UTC()
+	}
+	if !e.End.IsZero() {
+		e.End = e.End.UTC()
+	}
+	// an end without a start or before the start makes no sense
+	if !e.End.IsZero() && (e.Start.IsZero() || e.End.Before(e.Start)) {
+		return event.ErrInvalidDates
+	}
+	return nil
+}
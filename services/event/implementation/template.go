@@ -0,0 +1,220 @@
+package implementation
+
+import (
+	// stdlib
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/kevinburke/go.uuid"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/services/event"
+	"github.com/basvanbeek/opencensus-gokit-example/services/event/database"
+)
+
+// instanceDate is appended to the template name to create unique names for
+// its instances.
+const instanceDate = "2006-01-02"
+
+func (s *service) CreateTemplate(
+	ctx context.Context, tenantID uuid.UUID, t event.Template,
+) (*uuid.UUID, error) {
+	logger := log.With(s.logger, "method", "CreateTemplate")
+
+	name := strings.Trim(t.Name, "\r\n\t ")
+	if name == "" {
+		return nil, event.ErrRequireName
+	}
+	if t.Start.IsZero() {
+		// instances are scheduled relative to the first start
+		return nil, event.ErrInvalidDates
+	}
+	first := event.Event{Start: t.Start, End: t.End, Timezone: t.Timezone}
+	if err := validateDates(&first); err != nil {
+		return nil, err
+	}
+	if _, err := parseRRule(t.RRule); err != nil {
+		return nil, err
+	}
+
+	id, err := s.repository.CreateTemplate(ctx, database.Template{
+		TenantID: tenantID,
+		Name:     name,
+		RRule:    strings.ToUpper(strings.TrimSpace(t.RRule)),
+		Start:    first.Start,
+		End:      first.End,
+		Timezone: first.Timezone,
+	})
+	switch err {
+	case nil:
+		return id, nil
+	case database.ErrNameExists:
+		level.Debug(logger).Log("err", err)
+		return nil, event.ErrTemplateName
+	default:
+		level.Error(logger).Log("err", err)
+		return nil, event.ErrService
+	}
+}
+
+func (s *service) ListTemplates(
+	ctx context.Context, tenantID uuid.UUID,
+) ([]*event.Template, error) {
+	logger := log.With(s.logger, "method", "ListTemplates")
+
+	dbTemplates, err := s.repository.ListTemplates(ctx, tenantID)
+	if err != nil {
+		level.Error(logger).Log("err", err)
+		return nil, event.ErrService
+	}
+	templates := make([]*event.Template, 0, len(dbTemplates))
+	for _, t := range dbTemplates {
+		templates = append(templates, &event.Template{
+			ID:                t.ID,
+			TenantID:          t.TenantID,
+			Name:              t.Name,
+			RRule:             t.RRule,
+			Start:             t.Start,
+			End:               t.End,
+			Timezone:          t.Timezone,
+			MaterializedUntil: t.MaterializedUntil,
+			Failure:           t.Failure,
+		})
+	}
+	return templates, nil
+}
+
+func (s *service) DeleteTemplate(ctx context.Context, tenantID, id uuid.UUID) error {
+	logger := log.With(s.logger, "method", "DeleteTemplate")
+
+	// already materialized instances are regular events and are kept
+	if err := s.repository.DeleteTemplate(ctx, tenantID, id); err != nil {
+		level.Error(logger).Log("err", err)
+		return event.ErrService
+	}
+	return nil
+}
+
+// Materialize creates the template instances starting after the previously
+// materialized instances up to and including until. If the name of any of the
+// instances is already taken ErrEventExists is returned, no instances are
+// created and the conflict is recorded as the template's failure until a
+// later call succeeds. The created events are returned in chronological
+// order.
+func (s *service) Materialize(
+	ctx context.Context, tenantID, id uuid.UUID, until time.Time,
+) ([]*event.Event, error) {
+	logger := log.With(s.logger, "method", "Materialize", "template", id)
+
+	t, err := s.repository.GetTemplate(ctx, id)
+	switch err {
+	case nil:
+		if !uuid.Equal(t.TenantID, tenantID) {
+			return nil, event.ErrNoTemplate
+		}
+	case database.ErrNotFound:
+		level.Debug(logger).Log("err", err)
+		return nil, event.ErrNoTemplate
+	default:
+		level.Error(logger).Log("err", err)
+		return nil, event.ErrService
+	}
+
+	rule, err := parseRRule(t.RRule)
+	if err != nil {
+		level.Error(logger).Log("rrule", t.RRule, "err", err)
+		return nil, event.ErrService
+	}
+	loc := time.UTC
+	if t.Timezone != "" {
+		if loc, err = time.LoadLocation(t.Timezone); err != nil {
+			level.Error(logger).Log("timezone", t.Timezone, "err", err)
+			return nil, event.ErrService
+		}
+	}
+
+	after := t.MaterializedUntil
+	if after.IsZero() {
+		// nothing materialized yet, include the first instance
+		after = t.Start.Add(-time.Second)
+	}
+	starts := rule.occurrences(
+		t.Start, loc, after, until, event.MaxMaterializeInstances,
+	)
+	if len(starts) == 0 {
+		return nil, nil
+	}
+	if len(starts) == event.MaxMaterializeInstances {
+		// continue from the last instance on the next call
+		until = starts[len(starts)-1]
+	}
+
+	events := make([]database.Event, 0, len(starts))
+	for _, start := range starts {
+		e := database.Event{
+			TenantID: t.TenantID,
+			Name:     fmt.Sprintf("%s %s", t.Name, start.Format(instanceDate)),
+			Start:    start.UTC(),
+			Timezone: t.Timezone,
+		}
+		if !t.End.IsZero() {
+			e.End = start.Add(t.End.Sub(t.Start)).UTC()
+		}
+		events = append(events, e)
+	}
+
+	switch err = s.repository.MaterializeTemplate(ctx, t.ID, events, until); err {
+	case nil:
+	case database.ErrNameExists, database.ErrIDExists:
+		failure := s.conflict(ctx, t.TenantID, events)
+		level.Debug(logger).Log("err", err, "failure", failure)
+		if err = s.repository.FailTemplate(ctx, t.ID, failure); err != nil {
+			level.Error(logger).Log("err", err)
+		}
+		return nil, event.ErrEventExists
+	case database.ErrNotFound:
+		level.Debug(logger).Log("err", err)
+		return nil, event.ErrNoTemplate
+	default:
+		level.Error(logger).Log("err", err)
+		return nil, event.ErrService
+	}
+
+	created := make([]*event.Event, 0, len(events))
+	for _, e := range events {
+		created = append(created, &event.Event{
+			ID:       e.ID,
+			TenantID: e.TenantID,
+			Name:     e.Name,
+			Start:    e.Start,
+			End:      e.End,
+			Timezone: e.Timezone,
+		})
+	}
+	return created, nil
+}
+
+// conflict describes which of the template instances collides with an
+// existing event of the tenant.
+func (s *service) conflict(
+	ctx context.Context, tenantID uuid.UUID, instances []database.Event,
+) string {
+	existing, err := s.repository.List(ctx, tenantID)
+	if err == nil {
+		names := make(map[string]bool, len(existing))
+		for _, e := range existing {
+			names[strings.ToLower(e.Name)] = true
+		}
+		for _, e := range instances {
+			if names[strings.ToLower(e.Name)] {
+				return fmt.Sprintf("instance %q conflicts with an existing event", e.Name)
+			}
+		}
+	}
+	return "an instance conflicts with an existing event"
+}
@@ -0,0 +1,68 @@
+package implementation
+
+import (
+	// stdlib
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/kevinburke/go.uuid"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/services/event"
+	"github.com/basvanbeek/opencensus-gokit-example/services/event/database/inmemory"
+)
+
+func TestMaterializeConflict(t *testing.T) {
+	var (
+		ctx      = context.Background()
+		tenantID = uuid.NewV4()
+		start    = time.Date(2030, 6, 3, 9, 0, 0, 0, time.UTC)
+		svc      = NewService(inmemory.New(), log.NewNopLogger())
+	)
+
+	id, err := svc.CreateTemplate(ctx, tenantID, event.Template{
+		Name: "Standup", RRule: "FREQ=DAILY", Start: start,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	conflictID, err := svc.Create(ctx, tenantID, event.Event{Name: "standup 2030-06-04"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the conflict is reported to the caller and recorded on the template
+	until := start.AddDate(0, 0, 2)
+	if _, err = svc.Materialize(ctx, tenantID, *id, until); err != event.ErrEventExists {
+		t.Fatalf("want error %v, have %v", event.ErrEventExists, err)
+	}
+	templates, err := svc.ListTemplates(ctx, tenantID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(templates) != 1 || !strings.Contains(templates[0].Failure, `"Standup 2030-06-04"`) {
+		t.Fatalf("want failure naming the conflicting instance, have %+v", templates)
+	}
+
+	// resolving the conflict lets the template resume and clears the failure
+	if err = svc.Delete(ctx, tenantID, *conflictID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	events, err := svc.Materialize(ctx, tenantID, *id, until)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events) != 3 {
+		t.Errorf("want 3 instances, have %d", len(events))
+	}
+	if templates, err = svc.ListTemplates(ctx, tenantID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if templates[0].Failure != "" {
+		t.Errorf("want failure cleared, have %q", templates[0].Failure)
+	}
+}
@@ -3,10 +3,13 @@ package event
 import (
 	// stdlib
 	"context"
-	"errors"
+	"time"
 
-	"github.com/kevinburke/go.uuid"
 	// external
+	"github.com/kevinburke/go.uuid"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/shared/errcode"
 )
 
 // ServiceName of this service.
@@ -19,26 +22,102 @@ type Service interface {
 	Update(ctx context.Context, tenantID uuid.UUID, event Event) error
 	Delete(ctx context.Context, tenantID uuid.UUID, id uuid.UUID) error
 	List(ctx context.Context, tenantID uuid.UUID) ([]*Event, error)
+	Import(ctx context.Context, tenantID uuid.UUID, events []Event, dryRun bool) (*ImportResult, error)
+	Export(ctx context.Context, tenantID, after uuid.UUID, limit int) ([]*Event, error)
+	Clone(ctx context.Context, tenantID, id uuid.UUID, name string, start time.Time) (*uuid.UUID, error)
+	CreateTemplate(ctx context.Context, tenantID uuid.UUID, template Template) (*uuid.UUID, error)
+	ListTemplates(ctx context.Context, tenantID uuid.UUID) ([]*Template, error)
+	DeleteTemplate(ctx context.Context, tenantID, id uuid.UUID) error
+	Materialize(ctx context.Context, tenantID, id uuid.UUID, until time.Time) ([]*Event, error)
 }
 
+// MaxImportRows is the maximum amount of events accepted by a single Import
+// call. Larger data sets need to be imported in chunks.
+const MaxImportRows = 1000
+
+// MaxExportRows is the maximum amount of events returned by a single Export
+// call. Export pages through the events of a tenant ordered by id, the next
+// page starts after the id of the last event returned. A page holding less
+// events than requested is the last one.
+const MaxExportRows = 1000
+
+// MaxMaterializeInstances is the maximum amount of events created by a single
+// Materialize call. Remaining instances are created by subsequent calls.
+const MaxMaterializeInstances = 500
+
+// Middleware describes a service middleware.
+type Middleware func(Service) Service
+
 // Event Service Error descriptions
 const (
 	ErrorService      = "internal service error"
 	ErrorUnauthorized = "unauthorized"
 	ErrorNotFound     = "event not found"
 	ErrorEventExists  = "event already exists"
+	ErrorRequireName  = "missing required event name"
+	ErrorImportSize   = "too many events in a single import"
+	ErrorInvalidDates = "invalid event start and end dates"
+	ErrorInvalidZone  = "unknown event time zone"
+	ErrorInvalidRule  = "invalid recurrence rule"
+	ErrorNoTemplate   = "event template not found"
+	ErrorTemplateName = "event template already exists"
 )
 
 // Event Service Errors
 var (
-	ErrService      = errors.New(ErrorService)
-	ErrUnauthorized = errors.New(ErrorUnauthorized)
-	ErrNotFound     = errors.New(ErrorNotFound)
-	ErrEventExists  = errors.New(ErrorEventExists)
+	ErrService      = errcode.New("event.service", errcode.Internal, ErrorService)
+	ErrUnauthorized = errcode.New("event.unauthorized", errcode.Unauthenticated, ErrorUnauthorized)
+	ErrNotFound     = errcode.New("event.not_found", errcode.NotFound, ErrorNotFound)
+	ErrEventExists  = errcode.New("event.event_exists", errcode.AlreadyExists, ErrorEventExists)
+	ErrRequireName  = errcode.New("event.require_name", errcode.InvalidArgument, ErrorRequireName)
+	ErrImportSize   = errcode.New("event.import_size", errcode.InvalidArgument, ErrorImportSize)
+	ErrInvalidDates = errcode.New("event.invalid_dates", errcode.InvalidArgument, ErrorInvalidDates)
+	ErrInvalidZone  = errcode.New("event.invalid_zone", errcode.InvalidArgument, ErrorInvalidZone)
+	ErrInvalidRule  = errcode.New("event.invalid_rule", errcode.InvalidArgument, ErrorInvalidRule)
+	ErrNoTemplate   = errcode.New("event.no_template", errcode.NotFound, ErrorNoTemplate)
+	ErrTemplateName = errcode.New("event.template_name", errcode.AlreadyExists, ErrorTemplateName)
 )
 
-// Event data
+// Event data. Start and End are optional, Timezone holds the IANA time zone
+// name the event takes place in and defaults to UTC.
 type Event struct {
-	ID   uuid.UUID `json:"id"`
-	Name string    `json:"name"`
+	ID       uuid.UUID `json:"id"`
+	TenantID uuid.UUID `json:"tenant_id"`
+	Name     string    `json:"name"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	Timezone string    `json:"timezone,omitempty"`
+}
+
+// Template describes a recurring event. Start, End and Timezone describe the
+// first instance, RRule holds an RFC 5545 style recurrence rule such as
+// "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10". Instances are materialized as regular
+// events named after the template and the local date of the instance.
+// Failure holds why the last materialization failed, e.g. an instance name
+// taken by another event. It is cleared once instances materialize again.
+type Template struct {
+	ID                uuid.UUID `json:"id"`
+	TenantID          uuid.UUID `json:"tenant_id"`
+	Name              string    `json:"name"`
+	RRule             string    `json:"rrule"`
+	Start             time.Time `json:"start"`
+	End               time.Time `json:"end"`
+	Timezone          string    `json:"timezone,omitempty"`
+	MaterializedUntil time.Time `json:"materialized_until"`
+	Failure           string    `json:"failure,omitempty"`
+}
+
+// ImportResult holds the outcome of an Import call. Imports are all or
+// nothing: if any row fails validation no events are created.
+type ImportResult struct {
+	Rows    int           `json:"rows"`
+	Created []uuid.UUID   `json:"created,omitempty"`
+	Errors  []ImportError `json:"errors,omitempty"`
+}
+
+// ImportError holds the validation error of a single import row. Rows are
+// numbered starting at 1.
+type ImportError struct {
+	Row int   `json:"row"`
+	Err error `json:"-"`
 }
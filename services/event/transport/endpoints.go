@@ -18,6 +18,14 @@ type Endpoints struct {
 	Update endpoint.Endpoint
 	Delete endpoint.Endpoint
 	List   endpoint.Endpoint
+	Import endpoint.Endpoint
+	Export endpoint.Endpoint
+	Clone  endpoint.Endpoint
+
+	CreateTemplate endpoint.Endpoint
+	ListTemplates  endpoint.Endpoint
+	DeleteTemplate endpoint.Endpoint
+	Materialize    endpoint.Endpoint
 }
 
 // MakeEndpoints initializes all Go kit endpoints for the service.
@@ -28,6 +36,14 @@ func MakeEndpoints(s event.Service) Endpoints {
 		Update: makeUpdateEndpoint(s),
 		Delete: makeDeleteEndpoint(s),
 		List:   makeListEndpoint(s),
+		Import: makeImportEndpoint(s),
+		Export: makeExportEndpoint(s),
+		Clone:  makeCloneEndpoint(s),
+
+		CreateTemplate: makeCreateTemplateEndpoint(s),
+		ListTemplates:  makeListTemplatesEndpoint(s),
+		DeleteTemplate: makeDeleteTemplateEndpoint(s),
+		Materialize:    makeMaterializeEndpoint(s),
 	}
 }
 
@@ -70,3 +86,59 @@ func makeListEndpoint(s event.Service) endpoint.Endpoint {
 		return ListResponse{Events: events, Err: err}, nil
 	}
 }
+
+func makeImportEndpoint(s event.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(ImportRequest)
+		result, err := s.Import(ctx, req.TenantID, req.Events, req.DryRun)
+		return ImportResponse{Result: result, Err: err}, nil
+	}
+}
+
+func makeExportEndpoint(s event.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(ExportRequest)
+		events, err := s.Export(ctx, req.TenantID, req.After, req.Limit)
+		return ExportResponse{Events: events, Err: err}, nil
+	}
+}
+
+func makeCloneEndpoint(s event.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(CloneRequest)
+		id, err := s.Clone(ctx, req.TenantID, req.ID, req.Name, req.Start)
+		return CloneResponse{ID: id, Err: err}, nil
+	}
+}
+
+func makeCreateTemplateEndpoint(s event.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(CreateTemplateRequest)
+		id, err := s.CreateTemplate(ctx, req.TenantID, req.Template)
+		return CreateTemplateResponse{ID: id, Err: err}, nil
+	}
+}
+
+func makeListTemplatesEndpoint(s event.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(ListTemplatesRequest)
+		templates, err := s.ListTemplates(ctx, req.TenantID)
+		return ListTemplatesResponse{Templates: templates, Err: err}, nil
+	}
+}
+
+func makeDeleteTemplateEndpoint(s event.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(DeleteTemplateRequest)
+		err := s.DeleteTemplate(ctx, req.TenantID, req.ID)
+		return DeleteTemplateResponse{Err: err}, nil
+	}
+}
+
+func makeMaterializeEndpoint(s event.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(MaterializeRequest)
+		events, err := s.Materialize(ctx, req.TenantID, req.ID, req.Until)
+		return MaterializeResponse{Events: events, Err: err}, nil
+	}
+}
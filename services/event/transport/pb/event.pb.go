@@ -19,6 +19,22 @@ It has these top-level messages:
 	DeleteResponse
 	ListRequest
 	ListResponse
+	ImportError
+	ImportRequest
+	ImportResponse
+	ExportRequest
+	ExportResponse
+	CloneRequest
+	CloneResponse
+	TemplateObj
+	CreateTemplateRequest
+	CreateTemplateResponse
+	ListTemplatesRequest
+	ListTemplatesResponse
+	DeleteTemplateRequest
+	DeleteTemplateResponse
+	MaterializeRequest
+	MaterializeResponse
 */
 package pb
 
@@ -43,8 +59,12 @@ var _ = math.Inf
 const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 
 type EventObj struct {
-	Id   []byte `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Name string `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
+	Id       []byte `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name     string `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
+	TenantId []byte `protobuf:"bytes,3,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+	StartsAt int64  `protobuf:"varint,4,opt,name=starts_at,json=startsAt" json:"starts_at,omitempty"`
+	EndsAt   int64  `protobuf:"varint,5,opt,name=ends_at,json=endsAt" json:"ends_at,omitempty"`
+	Timezone string `protobuf:"bytes,6,opt,name=timezone" json:"timezone,omitempty"`
 }
 
 func (m *EventObj) Reset()                    { *m = EventObj{} }
@@ -66,6 +86,34 @@ func (m *EventObj) GetName() string {
 	return ""
 }
 
+func (m *EventObj) GetTenantId() []byte {
+	if m != nil {
+		return m.TenantId
+	}
+	return nil
+}
+
+func (m *EventObj) GetStartsAt() int64 {
+	if m != nil {
+		return m.StartsAt
+	}
+	return 0
+}
+
+func (m *EventObj) GetEndsAt() int64 {
+	if m != nil {
+		return m.EndsAt
+	}
+	return 0
+}
+
+func (m *EventObj) GetTimezone() string {
+	if m != nil {
+		return m.Timezone
+	}
+	return ""
+}
+
 type CreateRequest struct {
 	TenantId []byte    `protobuf:"bytes,1,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
 	Event    *EventObj `protobuf:"bytes,2,opt,name=event" json:"event,omitempty"`
@@ -242,6 +290,438 @@ func (m *ListResponse) GetEvents() []*EventObj {
 	return nil
 }
 
+type ImportError struct {
+	Row   int32  `protobuf:"varint,1,opt,name=row" json:"row,omitempty"`
+	Error string `protobuf:"bytes,2,opt,name=error" json:"error,omitempty"`
+	Code  string `protobuf:"bytes,3,opt,name=code" json:"code,omitempty"`
+}
+
+func (m *ImportError) Reset()                    { *m = ImportError{} }
+func (m *ImportError) String() string            { return proto.CompactTextString(m) }
+func (*ImportError) ProtoMessage()               {}
+func (*ImportError) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
+
+func (m *ImportError) GetRow() int32 {
+	if m != nil {
+		return m.Row
+	}
+	return 0
+}
+
+func (m *ImportError) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func (m *ImportError) GetCode() string {
+	if m != nil {
+		return m.Code
+	}
+	return ""
+}
+
+type ImportRequest struct {
+	TenantId []byte      `protobuf:"bytes,1,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+	Events   []*EventObj `protobuf:"bytes,2,rep,name=events" json:"events,omitempty"`
+	DryRun   bool        `protobuf:"varint,3,opt,name=dry_run,json=dryRun" json:"dry_run,omitempty"`
+}
+
+func (m *ImportRequest) Reset()                    { *m = ImportRequest{} }
+func (m *ImportRequest) String() string            { return proto.CompactTextString(m) }
+func (*ImportRequest) ProtoMessage()               {}
+func (*ImportRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
+
+func (m *ImportRequest) GetTenantId() []byte {
+	if m != nil {
+		return m.TenantId
+	}
+	return nil
+}
+
+func (m *ImportRequest) GetEvents() []*EventObj {
+	if m != nil {
+		return m.Events
+	}
+	return nil
+}
+
+func (m *ImportRequest) GetDryRun() bool {
+	if m != nil {
+		return m.DryRun
+	}
+	return false
+}
+
+type ImportResponse struct {
+	Rows    int32          `protobuf:"varint,1,opt,name=rows" json:"rows,omitempty"`
+	Created [][]byte       `protobuf:"bytes,2,rep,name=created,proto3" json:"created,omitempty"`
+	Errors  []*ImportError `protobuf:"bytes,3,rep,name=errors" json:"errors,omitempty"`
+}
+
+func (m *ImportResponse) Reset()                    { *m = ImportResponse{} }
+func (m *ImportResponse) String() string            { return proto.CompactTextString(m) }
+func (*ImportResponse) ProtoMessage()               {}
+func (*ImportResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
+
+func (m *ImportResponse) GetRows() int32 {
+	if m != nil {
+		return m.Rows
+	}
+	return 0
+}
+
+func (m *ImportResponse) GetCreated() [][]byte {
+	if m != nil {
+		return m.Created
+	}
+	return nil
+}
+
+func (m *ImportResponse) GetErrors() []*ImportError {
+	if m != nil {
+		return m.Errors
+	}
+	return nil
+}
+
+type ExportRequest struct {
+	TenantId []byte `protobuf:"bytes,1,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+	After    []byte `protobuf:"bytes,2,opt,name=after,proto3" json:"after,omitempty"`
+	Limit    int32  `protobuf:"varint,3,opt,name=limit" json:"limit,omitempty"`
+}
+
+func (m *ExportRequest) Reset()                    { *m = ExportRequest{} }
+func (m *ExportRequest) String() string            { return proto.CompactTextString(m) }
+func (*ExportRequest) ProtoMessage()               {}
+func (*ExportRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
+
+func (m *ExportRequest) GetTenantId() []byte {
+	if m != nil {
+		return m.TenantId
+	}
+	return nil
+}
+
+func (m *ExportRequest) GetAfter() []byte {
+	if m != nil {
+		return m.After
+	}
+	return nil
+}
+
+func (m *ExportRequest) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+type ExportResponse struct {
+	Events []*EventObj `protobuf:"bytes,1,rep,name=events" json:"events,omitempty"`
+}
+
+func (m *ExportResponse) Reset()                    { *m = ExportResponse{} }
+func (m *ExportResponse) String() string            { return proto.CompactTextString(m) }
+func (*ExportResponse) ProtoMessage()               {}
+func (*ExportResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
+
+func (m *ExportResponse) GetEvents() []*EventObj {
+	if m != nil {
+		return m.Events
+	}
+	return nil
+}
+
+type CloneRequest struct {
+	TenantId []byte `protobuf:"bytes,1,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+	Id       []byte `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	Name     string `protobuf:"bytes,3,opt,name=name" json:"name,omitempty"`
+	StartsAt int64  `protobuf:"varint,4,opt,name=starts_at,json=startsAt" json:"starts_at,omitempty"`
+}
+
+func (m *CloneRequest) Reset()                    { *m = CloneRequest{} }
+func (m *CloneRequest) String() string            { return proto.CompactTextString(m) }
+func (*CloneRequest) ProtoMessage()               {}
+func (*CloneRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
+
+func (m *CloneRequest) GetTenantId() []byte {
+	if m != nil {
+		return m.TenantId
+	}
+	return nil
+}
+
+func (m *CloneRequest) GetId() []byte {
+	if m != nil {
+		return m.Id
+	}
+	return nil
+}
+
+func (m *CloneRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *CloneRequest) GetStartsAt() int64 {
+	if m != nil {
+		return m.StartsAt
+	}
+	return 0
+}
+
+type CloneResponse struct {
+	Id []byte `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (m *CloneResponse) Reset()                    { *m = CloneResponse{} }
+func (m *CloneResponse) String() string            { return proto.CompactTextString(m) }
+func (*CloneResponse) ProtoMessage()               {}
+func (*CloneResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{17} }
+
+func (m *CloneResponse) GetId() []byte {
+	if m != nil {
+		return m.Id
+	}
+	return nil
+}
+
+type TemplateObj struct {
+	Id                []byte `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	TenantId          []byte `protobuf:"bytes,2,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+	Name              string `protobuf:"bytes,3,opt,name=name" json:"name,omitempty"`
+	Rrule             string `protobuf:"bytes,4,opt,name=rrule" json:"rrule,omitempty"`
+	StartsAt          int64  `protobuf:"varint,5,opt,name=starts_at,json=startsAt" json:"starts_at,omitempty"`
+	EndsAt            int64  `protobuf:"varint,6,opt,name=ends_at,json=endsAt" json:"ends_at,omitempty"`
+	Timezone          string `protobuf:"bytes,7,opt,name=timezone" json:"timezone,omitempty"`
+	MaterializedUntil int64  `protobuf:"varint,8,opt,name=materialized_until,json=materializedUntil" json:"materialized_until,omitempty"`
+	Failure           string `protobuf:"bytes,9,opt,name=failure" json:"failure,omitempty"`
+}
+
+func (m *TemplateObj) Reset()                    { *m = TemplateObj{} }
+func (m *TemplateObj) String() string            { return proto.CompactTextString(m) }
+func (*TemplateObj) ProtoMessage()               {}
+func (*TemplateObj) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{18} }
+
+func (m *TemplateObj) GetId() []byte {
+	if m != nil {
+		return m.Id
+	}
+	return nil
+}
+
+func (m *TemplateObj) GetTenantId() []byte {
+	if m != nil {
+		return m.TenantId
+	}
+	return nil
+}
+
+func (m *TemplateObj) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *TemplateObj) GetRrule() string {
+	if m != nil {
+		return m.Rrule
+	}
+	return ""
+}
+
+func (m *TemplateObj) GetStartsAt() int64 {
+	if m != nil {
+		return m.StartsAt
+	}
+	return 0
+}
+
+func (m *TemplateObj) GetEndsAt() int64 {
+	if m != nil {
+		return m.EndsAt
+	}
+	return 0
+}
+
+func (m *TemplateObj) GetTimezone() string {
+	if m != nil {
+		return m.Timezone
+	}
+	return ""
+}
+
+func (m *TemplateObj) GetMaterializedUntil() int64 {
+	if m != nil {
+		return m.MaterializedUntil
+	}
+	return 0
+}
+
+func (m *TemplateObj) GetFailure() string {
+	if m != nil {
+		return m.Failure
+	}
+	return ""
+}
+
+type CreateTemplateRequest struct {
+	TenantId []byte       `protobuf:"bytes,1,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+	Template *TemplateObj `protobuf:"bytes,2,opt,name=template" json:"template,omitempty"`
+}
+
+func (m *CreateTemplateRequest) Reset()                    { *m = CreateTemplateRequest{} }
+func (m *CreateTemplateRequest) String() string            { return proto.CompactTextString(m) }
+func (*CreateTemplateRequest) ProtoMessage()               {}
+func (*CreateTemplateRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{19} }
+
+func (m *CreateTemplateRequest) GetTenantId() []byte {
+	if m != nil {
+		return m.TenantId
+	}
+	return nil
+}
+
+func (m *CreateTemplateRequest) GetTemplate() *TemplateObj {
+	if m != nil {
+		return m.Template
+	}
+	return nil
+}
+
+type CreateTemplateResponse struct {
+	Id []byte `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (m *CreateTemplateResponse) Reset()                    { *m = CreateTemplateResponse{} }
+func (m *CreateTemplateResponse) String() string            { return proto.CompactTextString(m) }
+func (*CreateTemplateResponse) ProtoMessage()               {}
+func (*CreateTemplateResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{20} }
+
+func (m *CreateTemplateResponse) GetId() []byte {
+	if m != nil {
+		return m.Id
+	}
+	return nil
+}
+
+type ListTemplatesRequest struct {
+	TenantId []byte `protobuf:"bytes,1,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+}
+
+func (m *ListTemplatesRequest) Reset()                    { *m = ListTemplatesRequest{} }
+func (m *ListTemplatesRequest) String() string            { return proto.CompactTextString(m) }
+func (*ListTemplatesRequest) ProtoMessage()               {}
+func (*ListTemplatesRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{21} }
+
+func (m *ListTemplatesRequest) GetTenantId() []byte {
+	if m != nil {
+		return m.TenantId
+	}
+	return nil
+}
+
+type ListTemplatesResponse struct {
+	Templates []*TemplateObj `protobuf:"bytes,1,rep,name=templates" json:"templates,omitempty"`
+}
+
+func (m *ListTemplatesResponse) Reset()                    { *m = ListTemplatesResponse{} }
+func (m *ListTemplatesResponse) String() string            { return proto.CompactTextString(m) }
+func (*ListTemplatesResponse) ProtoMessage()               {}
+func (*ListTemplatesResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{22} }
+
+func (m *ListTemplatesResponse) GetTemplates() []*TemplateObj {
+	if m != nil {
+		return m.Templates
+	}
+	return nil
+}
+
+type DeleteTemplateRequest struct {
+	TenantId []byte `protobuf:"bytes,1,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+	Id       []byte `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (m *DeleteTemplateRequest) Reset()                    { *m = DeleteTemplateRequest{} }
+func (m *DeleteTemplateRequest) String() string            { return proto.CompactTextString(m) }
+func (*DeleteTemplateRequest) ProtoMessage()               {}
+func (*DeleteTemplateRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{23} }
+
+func (m *DeleteTemplateRequest) GetTenantId() []byte {
+	if m != nil {
+		return m.TenantId
+	}
+	return nil
+}
+
+func (m *DeleteTemplateRequest) GetId() []byte {
+	if m != nil {
+		return m.Id
+	}
+	return nil
+}
+
+type DeleteTemplateResponse struct {
+}
+
+func (m *DeleteTemplateResponse) Reset()                    { *m = DeleteTemplateResponse{} }
+func (m *DeleteTemplateResponse) String() string            { return proto.CompactTextString(m) }
+func (*DeleteTemplateResponse) ProtoMessage()               {}
+func (*DeleteTemplateResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{24} }
+
+type MaterializeRequest struct {
+	TenantId []byte `protobuf:"bytes,1,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+	Id       []byte `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	Until    int64  `protobuf:"varint,3,opt,name=until" json:"until,omitempty"`
+}
+
+func (m *MaterializeRequest) Reset()                    { *m = MaterializeRequest{} }
+func (m *MaterializeRequest) String() string            { return proto.CompactTextString(m) }
+func (*MaterializeRequest) ProtoMessage()               {}
+func (*MaterializeRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{25} }
+
+func (m *MaterializeRequest) GetTenantId() []byte {
+	if m != nil {
+		return m.TenantId
+	}
+	return nil
+}
+
+func (m *MaterializeRequest) GetId() []byte {
+	if m != nil {
+		return m.Id
+	}
+	return nil
+}
+
+func (m *MaterializeRequest) GetUntil() int64 {
+	if m != nil {
+		return m.Until
+	}
+	return 0
+}
+
+type MaterializeResponse struct {
+	Events []*EventObj `protobuf:"bytes,1,rep,name=events" json:"events,omitempty"`
+}
+
+func (m *MaterializeResponse) Reset()                    { *m = MaterializeResponse{} }
+func (m *MaterializeResponse) String() string            { return proto.CompactTextString(m) }
+func (*MaterializeResponse) ProtoMessage()               {}
+func (*MaterializeResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{26} }
+
+func (m *MaterializeResponse) GetEvents() []*EventObj {
+	if m != nil {
+		return m.Events
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*EventObj)(nil), "pb.eventObj")
 	proto.RegisterType((*CreateRequest)(nil), "pb.CreateRequest")
@@ -254,6 +734,22 @@ func init() {
 	proto.RegisterType((*DeleteResponse)(nil), "pb.DeleteResponse")
 	proto.RegisterType((*ListRequest)(nil), "pb.ListRequest")
 	proto.RegisterType((*ListResponse)(nil), "pb.ListResponse")
+	proto.RegisterType((*ImportError)(nil), "pb.importError")
+	proto.RegisterType((*ImportRequest)(nil), "pb.ImportRequest")
+	proto.RegisterType((*ImportResponse)(nil), "pb.ImportResponse")
+	proto.RegisterType((*ExportRequest)(nil), "pb.ExportRequest")
+	proto.RegisterType((*ExportResponse)(nil), "pb.ExportResponse")
+	proto.RegisterType((*CloneRequest)(nil), "pb.CloneRequest")
+	proto.RegisterType((*CloneResponse)(nil), "pb.CloneResponse")
+	proto.RegisterType((*TemplateObj)(nil), "pb.templateObj")
+	proto.RegisterType((*CreateTemplateRequest)(nil), "pb.CreateTemplateRequest")
+	proto.RegisterType((*CreateTemplateResponse)(nil), "pb.CreateTemplateResponse")
+	proto.RegisterType((*ListTemplatesRequest)(nil), "pb.ListTemplatesRequest")
+	proto.RegisterType((*ListTemplatesResponse)(nil), "pb.ListTemplatesResponse")
+	proto.RegisterType((*DeleteTemplateRequest)(nil), "pb.DeleteTemplateRequest")
+	proto.RegisterType((*DeleteTemplateResponse)(nil), "pb.DeleteTemplateResponse")
+	proto.RegisterType((*MaterializeRequest)(nil), "pb.MaterializeRequest")
+	proto.RegisterType((*MaterializeResponse)(nil), "pb.MaterializeResponse")
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -272,6 +768,13 @@ type EventClient interface {
 	Update(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*UpdateResponse, error)
 	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error)
 	List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error)
+	Import(ctx context.Context, in *ImportRequest, opts ...grpc.CallOption) (*ImportResponse, error)
+	Export(ctx context.Context, in *ExportRequest, opts ...grpc.CallOption) (*ExportResponse, error)
+	Clone(ctx context.Context, in *CloneRequest, opts ...grpc.CallOption) (*CloneResponse, error)
+	CreateTemplate(ctx context.Context, in *CreateTemplateRequest, opts ...grpc.CallOption) (*CreateTemplateResponse, error)
+	ListTemplates(ctx context.Context, in *ListTemplatesRequest, opts ...grpc.CallOption) (*ListTemplatesResponse, error)
+	DeleteTemplate(ctx context.Context, in *DeleteTemplateRequest, opts ...grpc.CallOption) (*DeleteTemplateResponse, error)
+	Materialize(ctx context.Context, in *MaterializeRequest, opts ...grpc.CallOption) (*MaterializeResponse, error)
 }
 
 type eventClient struct {
@@ -327,6 +830,69 @@ func (c *eventClient) List(ctx context.Context, in *ListRequest, opts ...grpc.Ca
 	return out, nil
 }
 
+func (c *eventClient) Import(ctx context.Context, in *ImportRequest, opts ...grpc.CallOption) (*ImportResponse, error) {
+	out := new(ImportResponse)
+	err := grpc.Invoke(ctx, "/pb.Event/Import", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventClient) Export(ctx context.Context, in *ExportRequest, opts ...grpc.CallOption) (*ExportResponse, error) {
+	out := new(ExportResponse)
+	err := grpc.Invoke(ctx, "/pb.Event/Export", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventClient) Clone(ctx context.Context, in *CloneRequest, opts ...grpc.CallOption) (*CloneResponse, error) {
+	out := new(CloneResponse)
+	err := grpc.Invoke(ctx, "/pb.Event/Clone", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventClient) CreateTemplate(ctx context.Context, in *CreateTemplateRequest, opts ...grpc.CallOption) (*CreateTemplateResponse, error) {
+	out := new(CreateTemplateResponse)
+	err := grpc.Invoke(ctx, "/pb.Event/CreateTemplate", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventClient) ListTemplates(ctx context.Context, in *ListTemplatesRequest, opts ...grpc.CallOption) (*ListTemplatesResponse, error) {
+	out := new(ListTemplatesResponse)
+	err := grpc.Invoke(ctx, "/pb.Event/ListTemplates", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventClient) DeleteTemplate(ctx context.Context, in *DeleteTemplateRequest, opts ...grpc.CallOption) (*DeleteTemplateResponse, error) {
+	out := new(DeleteTemplateResponse)
+	err := grpc.Invoke(ctx, "/pb.Event/DeleteTemplate", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventClient) Materialize(ctx context.Context, in *MaterializeRequest, opts ...grpc.CallOption) (*MaterializeResponse, error) {
+	out := new(MaterializeResponse)
+	err := grpc.Invoke(ctx, "/pb.Event/Materialize", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for Event service
 
 type EventServer interface {
@@ -335,6 +901,13 @@ type EventServer interface {
 	Update(context.Context, *UpdateRequest) (*UpdateResponse, error)
 	Delete(context.Context, *DeleteRequest) (*DeleteResponse, error)
 	List(context.Context, *ListRequest) (*ListResponse, error)
+	Import(context.Context, *ImportRequest) (*ImportResponse, error)
+	Export(context.Context, *ExportRequest) (*ExportResponse, error)
+	Clone(context.Context, *CloneRequest) (*CloneResponse, error)
+	CreateTemplate(context.Context, *CreateTemplateRequest) (*CreateTemplateResponse, error)
+	ListTemplates(context.Context, *ListTemplatesRequest) (*ListTemplatesResponse, error)
+	DeleteTemplate(context.Context, *DeleteTemplateRequest) (*DeleteTemplateResponse, error)
+	Materialize(context.Context, *MaterializeRequest) (*MaterializeResponse, error)
 }
 
 func RegisterEventServer(s *grpc.Server, srv EventServer) {
@@ -431,6 +1004,132 @@ func _Event_List_Handler(srv interface{}, ctx context.Context, dec func(interfac
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Event_Import_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventServer).Import(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Event/Import",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventServer).Import(ctx, req.(*ImportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Event_Export_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventServer).Export(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Event/Export",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventServer).Export(ctx, req.(*ExportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Event_Clone_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CloneRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventServer).Clone(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Event/Clone",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventServer).Clone(ctx, req.(*CloneRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Event_CreateTemplate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateTemplateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventServer).CreateTemplate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Event/CreateTemplate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventServer).CreateTemplate(ctx, req.(*CreateTemplateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Event_ListTemplates_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTemplatesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventServer).ListTemplates(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Event/ListTemplates",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventServer).ListTemplates(ctx, req.(*ListTemplatesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Event_DeleteTemplate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteTemplateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventServer).DeleteTemplate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Event/DeleteTemplate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventServer).DeleteTemplate(ctx, req.(*DeleteTemplateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Event_Materialize_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MaterializeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventServer).Materialize(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Event/Materialize",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventServer).Materialize(ctx, req.(*MaterializeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Event_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "pb.Event",
 	HandlerType: (*EventServer)(nil),
@@ -455,6 +1154,34 @@ var _Event_serviceDesc = grpc.ServiceDesc{
 			MethodName: "List",
 			Handler:    _Event_List_Handler,
 		},
+		{
+			MethodName: "Import",
+			Handler:    _Event_Import_Handler,
+		},
+		{
+			MethodName: "Export",
+			Handler:    _Event_Export_Handler,
+		},
+		{
+			MethodName: "Clone",
+			Handler:    _Event_Clone_Handler,
+		},
+		{
+			MethodName: "CreateTemplate",
+			Handler:    _Event_CreateTemplate_Handler,
+		},
+		{
+			MethodName: "ListTemplates",
+			Handler:    _Event_ListTemplates_Handler,
+		},
+		{
+			MethodName: "DeleteTemplate",
+			Handler:    _Event_DeleteTemplate_Handler,
+		},
+		{
+			MethodName: "Materialize",
+			Handler:    _Event_Materialize_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "services/event/transport/pb/event.proto",
@@ -463,27 +1190,61 @@ var _Event_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("services/event/transport/pb/event.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 349 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x53, 0xc1, 0x4a, 0xc3, 0x40,
-	0x10, 0x6d, 0xd2, 0x36, 0xb4, 0xd3, 0x36, 0xd6, 0x39, 0x95, 0x7a, 0x09, 0x8b, 0x60, 0x51, 0x48,
-	0x69, 0xf5, 0x22, 0x78, 0x53, 0x29, 0x82, 0x60, 0x09, 0x78, 0x96, 0xc4, 0xcc, 0x21, 0xa2, 0xc9,
-	0x9a, 0x5d, 0xfb, 0xeb, 0x5e, 0x25, 0x3b, 0x49, 0xda, 0x46, 0x84, 0x1e, 0xbc, 0x6d, 0xdf, 0xcc,
-	0x7b, 0xf3, 0xe6, 0x4d, 0x03, 0x67, 0x8a, 0xf2, 0x4d, 0xf2, 0x4a, 0x6a, 0x4e, 0x1b, 0x4a, 0xf5,
-	0x5c, 0xe7, 0x61, 0xaa, 0x64, 0x96, 0xeb, 0xb9, 0x8c, 0x18, 0xf2, 0x65, 0x9e, 0xe9, 0x0c, 0x6d,
-	0x19, 0x09, 0x1f, 0x7a, 0x06, 0x7a, 0x8a, 0xde, 0xd0, 0x05, 0x3b, 0x89, 0x27, 0x96, 0x67, 0xcd,
-	0x86, 0x81, 0x9d, 0xc4, 0x88, 0xd0, 0x49, 0xc3, 0x0f, 0x9a, 0xd8, 0x9e, 0x35, 0xeb, 0x07, 0xe6,
-	0x2d, 0xd6, 0x30, 0xba, 0xcd, 0x29, 0xd4, 0x14, 0xd0, 0xe7, 0x17, 0x29, 0x8d, 0x27, 0xd0, 0xd7,
-	0x94, 0x86, 0xa9, 0x7e, 0xa9, 0xb9, 0x3d, 0x06, 0x1e, 0x62, 0x14, 0xd0, 0x35, 0xea, 0x46, 0x62,
-	0xb0, 0x1c, 0xfa, 0x32, 0xf2, 0xab, 0x71, 0x01, 0x97, 0x84, 0x07, 0x6e, 0xa5, 0xa8, 0x64, 0x96,
-	0x2a, 0x6a, 0xfa, 0x10, 0xd7, 0x00, 0x2b, 0xd2, 0x07, 0x0d, 0x64, 0xaa, 0x5d, 0x53, 0x17, 0x30,
-	0x30, 0xd4, 0x52, 0xb9, 0xf6, 0x63, 0xfd, 0xed, 0x67, 0x0d, 0xa3, 0x67, 0x19, 0xff, 0xe7, 0x86,
-	0x63, 0x70, 0x2b, 0x45, 0xf6, 0x21, 0x6e, 0x60, 0x74, 0x47, 0xef, 0x74, 0xe0, 0x8c, 0xe6, 0x52,
-	0x63, 0x70, 0x2b, 0x76, 0xa9, 0x77, 0x0e, 0x83, 0xc7, 0x44, 0x1d, 0x14, 0x91, 0xb8, 0x82, 0x21,
-	0xf7, 0x96, 0x99, 0x9c, 0x82, 0x63, 0x6c, 0xaa, 0x89, 0xe5, 0xb5, 0x7f, 0xad, 0x50, 0xd6, 0x96,
-	0xdf, 0x16, 0x74, 0xef, 0x8b, 0x27, 0x2e, 0xc0, 0xe1, 0x7b, 0xe1, 0x71, 0xd1, 0xb9, 0xf7, 0x6f,
-	0x98, 0xe2, 0x2e, 0x54, 0x9a, 0x6b, 0xe1, 0x0c, 0xda, 0x2b, 0xd2, 0xe8, 0x16, 0xc5, 0xed, 0x25,
-	0xa7, 0x47, 0xf5, 0xef, 0xba, 0x73, 0x01, 0x0e, 0x47, 0xc5, 0xe2, 0x7b, 0x87, 0x60, 0xf1, 0x46,
-	0x92, 0x86, 0xc2, 0x69, 0x30, 0x65, 0x2f, 0x57, 0xa6, 0x34, 0xc2, 0x6a, 0xe1, 0x05, 0x74, 0x8a,
-	0x08, 0xd0, 0x18, 0xd8, 0x09, 0x6e, 0x3a, 0xde, 0x02, 0x55, 0x73, 0xe4, 0x98, 0x8f, 0xe5, 0xf2,
-	0x27, 0x00, 0x00, 0xff, 0xff, 0x25, 0x75, 0x29, 0x7e, 0x57, 0x03, 0x00, 0x00,
+	// 884 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x56, 0xdd, 0x4e, 0xe3, 0x46,
+	0x14, 0xc6, 0x71, 0x6c, 0x92, 0x93, 0x1f, 0xc2, 0x34, 0x80, 0x71, 0x2f, 0x1a, 0x8d, 0x2a, 0x11,
+	0x15, 0x11, 0x04, 0x54, 0x95, 0xaa, 0xf6, 0xa2, 0x08, 0x02, 0x8a, 0xd4, 0xaa, 0xc8, 0x2a, 0x6a,
+	0xef, 0x22, 0x07, 0x0f, 0x95, 0x5b, 0xc7, 0xf6, 0x8e, 0x27, 0x10, 0x78, 0x97, 0x7d, 0x84, 0x7d,
+	0xc6, 0x5d, 0xcd, 0x8f, 0x1d, 0xdb, 0x49, 0xa4, 0x80, 0xf6, 0xce, 0xe7, 0xff, 0x3b, 0x67, 0xce,
+	0x7c, 0x63, 0x38, 0x4a, 0x08, 0x7d, 0xf2, 0x1f, 0x48, 0x72, 0x4a, 0x9e, 0x48, 0xc8, 0x4e, 0x19,
+	0x75, 0xc3, 0x24, 0x8e, 0x28, 0x3b, 0x8d, 0x27, 0x52, 0x35, 0x88, 0x69, 0xc4, 0x22, 0x54, 0x89,
+	0x27, 0xf8, 0xa3, 0x06, 0x35, 0xa1, 0xfb, 0x73, 0xf2, 0x1f, 0x6a, 0x43, 0xc5, 0xf7, 0x2c, 0xad,
+	0xa7, 0xf5, 0x9b, 0x4e, 0xc5, 0xf7, 0x10, 0x82, 0x6a, 0xe8, 0x4e, 0x89, 0x55, 0xe9, 0x69, 0xfd,
+	0xba, 0x23, 0xbe, 0xd1, 0xb7, 0x50, 0x67, 0x24, 0x74, 0x43, 0x36, 0xf6, 0x3d, 0x4b, 0x17, 0xae,
+	0x35, 0xa9, 0x18, 0x79, 0xdc, 0x98, 0x30, 0x97, 0xb2, 0x64, 0xec, 0x32, 0xab, 0xda, 0xd3, 0xfa,
+	0xba, 0x53, 0x93, 0x8a, 0x4b, 0x86, 0x0e, 0x60, 0x9b, 0x84, 0x9e, 0x30, 0x19, 0xc2, 0x64, 0x72,
+	0xf1, 0x92, 0x21, 0x1b, 0x6a, 0xcc, 0x9f, 0x92, 0xd7, 0x28, 0x24, 0x96, 0x29, 0x4a, 0x65, 0x32,
+	0xbe, 0x83, 0xd6, 0x15, 0x25, 0x2e, 0x23, 0x0e, 0xf9, 0x30, 0x23, 0x09, 0x2b, 0xd6, 0xd7, 0x4a,
+	0xf5, 0x31, 0x18, 0xa2, 0x19, 0x81, 0xb8, 0x71, 0xde, 0x1c, 0xc4, 0x93, 0x41, 0xda, 0x9d, 0x23,
+	0x4d, 0xb8, 0x07, 0xed, 0x34, 0x63, 0x12, 0x47, 0x61, 0x42, 0xca, 0x6d, 0xe3, 0x9f, 0x01, 0x6e,
+	0x09, 0xdb, 0xa8, 0xa0, 0x0c, 0xad, 0x64, 0xa1, 0x67, 0xd0, 0x10, 0xa1, 0x2a, 0x73, 0x86, 0x47,
+	0x5b, 0x8f, 0xe7, 0x0e, 0x5a, 0xf7, 0xb1, 0xf7, 0x35, 0x3b, 0xec, 0x40, 0x3b, 0xcd, 0x28, 0x71,
+	0xe0, 0x5f, 0xa1, 0x75, 0x4d, 0x02, 0xc2, 0xc8, 0xbb, 0x9a, 0xea, 0x40, 0x3b, 0x8d, 0x56, 0xf9,
+	0x7e, 0x80, 0xc6, 0xef, 0x7e, 0xb2, 0xd1, 0x88, 0xf0, 0x8f, 0xd0, 0x94, 0xbe, 0x6a, 0x26, 0xdf,
+	0x83, 0x29, 0x60, 0x26, 0x96, 0xd6, 0xd3, 0x97, 0x5a, 0x50, 0x36, 0x3c, 0x82, 0x86, 0x3f, 0xe5,
+	0x4b, 0x3b, 0xa4, 0x34, 0xa2, 0xa8, 0x03, 0x3a, 0x8d, 0x9e, 0x45, 0x6e, 0xc3, 0xe1, 0x9f, 0xa8,
+	0x0b, 0x06, 0xe1, 0x26, 0xb5, 0x9c, 0x52, 0xe0, 0x1b, 0xfb, 0x10, 0x79, 0x44, 0x2c, 0x66, 0xdd,
+	0x11, 0xdf, 0xf8, 0x7f, 0x68, 0x8d, 0x44, 0xaa, 0x8d, 0x9a, 0x5f, 0xc0, 0xab, 0xac, 0x87, 0xc7,
+	0x77, 0xd9, 0xa3, 0x2f, 0x63, 0x3a, 0x0b, 0x45, 0xa9, 0x9a, 0x63, 0x7a, 0xf4, 0xc5, 0x99, 0x85,
+	0xf8, 0x5f, 0x68, 0xa7, 0xc5, 0x54, 0xbf, 0x08, 0xaa, 0x34, 0x7a, 0x4e, 0x14, 0x76, 0xf1, 0x8d,
+	0x2c, 0xd8, 0x7e, 0x10, 0x3b, 0xe8, 0x89, 0x2a, 0x4d, 0x27, 0x15, 0xd1, 0x11, 0x98, 0xa2, 0x93,
+	0xc4, 0xd2, 0x45, 0xf9, 0x1d, 0x5e, 0x3e, 0x37, 0x09, 0x47, 0x99, 0xf1, 0x3f, 0xd0, 0x1a, 0xce,
+	0x37, 0xee, 0xaa, 0x0b, 0x86, 0xfb, 0xc8, 0x08, 0x55, 0xa7, 0x2a, 0x05, 0xae, 0x0d, 0xfc, 0xa9,
+	0xcf, 0x44, 0x0f, 0x86, 0x23, 0x05, 0xfc, 0x13, 0xb4, 0x87, 0xf3, 0x42, 0x0b, 0x9b, 0x1d, 0x59,
+	0x00, 0xcd, 0xab, 0x20, 0x0a, 0xdf, 0xb5, 0x63, 0x19, 0xd5, 0xe8, 0x45, 0xaa, 0x59, 0xcb, 0x26,
+	0xf8, 0x3b, 0x68, 0xa9, 0x6a, 0x6b, 0x6e, 0xf1, 0x67, 0x0d, 0x1a, 0x8c, 0x4c, 0xe3, 0xc0, 0x65,
+	0x64, 0x15, 0xb9, 0x15, 0xe0, 0x55, 0x4a, 0xf0, 0x56, 0xc1, 0xe9, 0x82, 0x41, 0xe9, 0x2c, 0x20,
+	0x02, 0x4a, 0xdd, 0x91, 0x42, 0x11, 0xa4, 0xb1, 0x9e, 0xf2, 0xcc, 0xb5, 0x94, 0xb7, 0x5d, 0xa4,
+	0x3c, 0x74, 0x02, 0x68, 0xea, 0x32, 0x42, 0x7d, 0x37, 0xf0, 0x5f, 0x89, 0x37, 0x9e, 0x85, 0xcc,
+	0x0f, 0xac, 0x9a, 0x88, 0xdf, 0xcd, 0x5b, 0xee, 0xb9, 0x81, 0xef, 0xd2, 0xa3, 0xeb, 0x07, 0x33,
+	0x4a, 0xac, 0xba, 0xc8, 0x94, 0x8a, 0xd8, 0x85, 0x3d, 0xc9, 0x74, 0x7f, 0xa9, 0x31, 0x6c, 0x74,
+	0x32, 0xc7, 0x50, 0x4b, 0xc7, 0xa6, 0x48, 0x46, 0xec, 0x60, 0x6e, 0x94, 0x4e, 0xe6, 0x80, 0xfb,
+	0xb0, 0x5f, 0x2e, 0xb1, 0xe6, 0x38, 0x2e, 0xa0, 0xcb, 0x69, 0x20, 0xf5, 0x4b, 0x36, 0xe2, 0x8e,
+	0x1b, 0xd8, 0x2b, 0x05, 0xa9, 0xec, 0x27, 0x3c, 0x4a, 0x29, 0xd5, 0x52, 0x2e, 0xa1, 0x5c, 0x78,
+	0xe0, 0x6b, 0xd8, 0x93, 0x0c, 0xf6, 0xa6, 0x49, 0x94, 0x79, 0xd0, 0x82, 0xfd, 0x72, 0x16, 0xc5,
+	0x87, 0x7f, 0x03, 0xfa, 0x63, 0x71, 0x30, 0xef, 0xba, 0x00, 0x5d, 0x30, 0xe4, 0x41, 0xeb, 0xe2,
+	0xa0, 0xa5, 0x80, 0x7f, 0x81, 0x6f, 0x0a, 0x89, 0xdf, 0x72, 0x21, 0xcf, 0x3f, 0x19, 0x60, 0x0c,
+	0xf9, 0x27, 0x3a, 0x03, 0x53, 0x1e, 0x13, 0xda, 0xe5, 0x9e, 0x85, 0x17, 0xd5, 0x46, 0x79, 0x95,
+	0x6a, 0x68, 0x0b, 0xf5, 0x41, 0xbf, 0x25, 0x0c, 0xb5, 0xb9, 0x71, 0xf1, 0x1a, 0xda, 0x3b, 0x99,
+	0x9c, 0x79, 0x9e, 0x81, 0x29, 0x9f, 0x1b, 0x99, 0xbc, 0xf0, 0x98, 0xd9, 0x28, 0xaf, 0xca, 0x87,
+	0xc8, 0x49, 0xca, 0x90, 0xc2, 0xdb, 0x64, 0xa3, 0xbc, 0x2a, 0x0b, 0x39, 0x86, 0x2a, 0x5f, 0x05,
+	0x24, 0x00, 0xe4, 0x1e, 0x1f, 0xbb, 0xb3, 0x50, 0xe4, 0xf3, 0x4b, 0x16, 0x96, 0xf9, 0x0b, 0xf4,
+	0x6f, 0xa3, 0xbc, 0x2a, 0x1f, 0x32, 0x9c, 0x2f, 0x42, 0x86, 0xf3, 0xa5, 0x90, 0x22, 0x29, 0xe2,
+	0x2d, 0x34, 0x00, 0x43, 0x50, 0x10, 0x12, 0x10, 0xf2, 0xdc, 0x67, 0xef, 0xe6, 0x34, 0x99, 0xff,
+	0x28, 0xfd, 0xf3, 0x48, 0xf7, 0x07, 0x1d, 0x2e, 0x46, 0x5f, 0xda, 0x4c, 0xdb, 0x5e, 0x65, 0xca,
+	0x52, 0xdd, 0x40, 0xab, 0x70, 0x31, 0x90, 0x95, 0x4e, 0xa1, 0x7c, 0xc1, 0xec, 0xc3, 0x15, 0x96,
+	0x3c, 0xa4, 0xe2, 0x4a, 0x4b, 0x48, 0x2b, 0x2f, 0x8b, 0x6d, 0xaf, 0x32, 0x65, 0xa9, 0x7e, 0x83,
+	0x46, 0x6e, 0x55, 0xd1, 0x3e, 0x77, 0x5e, 0xbe, 0x14, 0xf6, 0xc1, 0x92, 0x3e, 0xcd, 0x30, 0x31,
+	0xc5, 0x6f, 0xe9, 0xc5, 0x17, 0x00, 0x00, 0x00, 0xff, 0xff, 0x03, 0x00, 0x82, 0x50, 0x26, 0xac,
+	0xc1, 0x0a, 0x00, 0x00,
 }
@@ -42,6 +42,20 @@ type Event interface {
 	Delete(context.Context, *DeleteRequest) (*DeleteResponse, error)
 
 	List(context.Context, *ListRequest) (*ListResponse, error)
+
+	Import(context.Context, *ImportRequest) (*ImportResponse, error)
+
+	Export(context.Context, *ExportRequest) (*ExportResponse, error)
+
+	Clone(context.Context, *CloneRequest) (*CloneResponse, error)
+
+	CreateTemplate(context.Context, *CreateTemplateRequest) (*CreateTemplateResponse, error)
+
+	ListTemplates(context.Context, *ListTemplatesRequest) (*ListTemplatesResponse, error)
+
+	DeleteTemplate(context.Context, *DeleteTemplateRequest) (*DeleteTemplateResponse, error)
+
+	Materialize(context.Context, *MaterializeRequest) (*MaterializeResponse, error)
 }
 
 // =====================
@@ -50,19 +64,26 @@ type Event interface {
 
 type eventProtobufClient struct {
 	client HTTPClient
-	urls   [5]string
+	urls   [12]string
 }
 
 // NewEventProtobufClient creates a Protobuf client that implements the Event interface.
 // It communicates using Protobuf and can be configured with a custom HTTPClient.
 func NewEventProtobufClient(addr string, client HTTPClient) Event {
 	prefix := urlBase(addr) + EventPathPrefix
-	urls := [5]string{
+	urls := [12]string{
 		prefix + "Create",
 		prefix + "Get",
 		prefix + "Update",
 		prefix + "Delete",
 		prefix + "List",
+		prefix + "Import",
+		prefix + "Export",
+		prefix + "Clone",
+		prefix + "CreateTemplate",
+		prefix + "ListTemplates",
+		prefix + "DeleteTemplate",
+		prefix + "Materialize",
 	}
 	if httpClient, ok := client.(*http.Client); ok {
 		return &eventProtobufClient{
@@ -136,25 +157,116 @@ func (c *eventProtobufClient) List(ctx context.Context, in *ListRequest) (*ListR
 	return out, nil
 }
 
+func (c *eventProtobufClient) Import(ctx context.Context, in *ImportRequest) (*ImportResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "pb")
+	ctx = ctxsetters.WithServiceName(ctx, "Event")
+	ctx = ctxsetters.WithMethodName(ctx, "Import")
+	out := new(ImportResponse)
+	err := doProtobufRequest(ctx, c.client, c.urls[5], in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventProtobufClient) Export(ctx context.Context, in *ExportRequest) (*ExportResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "pb")
+	ctx = ctxsetters.WithServiceName(ctx, "Event")
+	ctx = ctxsetters.WithMethodName(ctx, "Export")
+	out := new(ExportResponse)
+	err := doProtobufRequest(ctx, c.client, c.urls[6], in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventProtobufClient) Clone(ctx context.Context, in *CloneRequest) (*CloneResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "pb")
+	ctx = ctxsetters.WithServiceName(ctx, "Event")
+	ctx = ctxsetters.WithMethodName(ctx, "Clone")
+	out := new(CloneResponse)
+	err := doProtobufRequest(ctx, c.client, c.urls[7], in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventProtobufClient) CreateTemplate(ctx context.Context, in *CreateTemplateRequest) (*CreateTemplateResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "pb")
+	ctx = ctxsetters.WithServiceName(ctx, "Event")
+	ctx = ctxsetters.WithMethodName(ctx, "CreateTemplate")
+	out := new(CreateTemplateResponse)
+	err := doProtobufRequest(ctx, c.client, c.urls[8], in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventProtobufClient) ListTemplates(ctx context.Context, in *ListTemplatesRequest) (*ListTemplatesResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "pb")
+	ctx = ctxsetters.WithServiceName(ctx, "Event")
+	ctx = ctxsetters.WithMethodName(ctx, "ListTemplates")
+	out := new(ListTemplatesResponse)
+	err := doProtobufRequest(ctx, c.client, c.urls[9], in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventProtobufClient) DeleteTemplate(ctx context.Context, in *DeleteTemplateRequest) (*DeleteTemplateResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "pb")
+	ctx = ctxsetters.WithServiceName(ctx, "Event")
+	ctx = ctxsetters.WithMethodName(ctx, "DeleteTemplate")
+	out := new(DeleteTemplateResponse)
+	err := doProtobufRequest(ctx, c.client, c.urls[10], in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventProtobufClient) Materialize(ctx context.Context, in *MaterializeRequest) (*MaterializeResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "pb")
+	ctx = ctxsetters.WithServiceName(ctx, "Event")
+	ctx = ctxsetters.WithMethodName(ctx, "Materialize")
+	out := new(MaterializeResponse)
+	err := doProtobufRequest(ctx, c.client, c.urls[11], in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // =================
 // Event JSON Client
 // =================
 
 type eventJSONClient struct {
 	client HTTPClient
-	urls   [5]string
+	urls   [12]string
 }
 
 // NewEventJSONClient creates a JSON client that implements the Event interface.
 // It communicates using JSON and can be configured with a custom HTTPClient.
 func NewEventJSONClient(addr string, client HTTPClient) Event {
 	prefix := urlBase(addr) + EventPathPrefix
-	urls := [5]string{
+	urls := [12]string{
 		prefix + "Create",
 		prefix + "Get",
 		prefix + "Update",
 		prefix + "Delete",
 		prefix + "List",
+		prefix + "Import",
+		prefix + "Export",
+		prefix + "Clone",
+		prefix + "CreateTemplate",
+		prefix + "ListTemplates",
+		prefix + "DeleteTemplate",
+		prefix + "Materialize",
 	}
 	if httpClient, ok := client.(*http.Client); ok {
 		return &eventJSONClient{
@@ -228,6 +340,90 @@ func (c *eventJSONClient) List(ctx context.Context, in *ListRequest) (*ListRespo
 	return out, nil
 }
 
+func (c *eventJSONClient) Import(ctx context.Context, in *ImportRequest) (*ImportResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "pb")
+	ctx = ctxsetters.WithServiceName(ctx, "Event")
+	ctx = ctxsetters.WithMethodName(ctx, "Import")
+	out := new(ImportResponse)
+	err := doJSONRequest(ctx, c.client, c.urls[5], in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventJSONClient) Export(ctx context.Context, in *ExportRequest) (*ExportResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "pb")
+	ctx = ctxsetters.WithServiceName(ctx, "Event")
+	ctx = ctxsetters.WithMethodName(ctx, "Export")
+	out := new(ExportResponse)
+	err := doJSONRequest(ctx, c.client, c.urls[6], in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventJSONClient) Clone(ctx context.Context, in *CloneRequest) (*CloneResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "pb")
+	ctx = ctxsetters.WithServiceName(ctx, "Event")
+	ctx = ctxsetters.WithMethodName(ctx, "Clone")
+	out := new(CloneResponse)
+	err := doJSONRequest(ctx, c.client, c.urls[7], in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventJSONClient) CreateTemplate(ctx context.Context, in *CreateTemplateRequest) (*CreateTemplateResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "pb")
+	ctx = ctxsetters.WithServiceName(ctx, "Event")
+	ctx = ctxsetters.WithMethodName(ctx, "CreateTemplate")
+	out := new(CreateTemplateResponse)
+	err := doJSONRequest(ctx, c.client, c.urls[8], in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventJSONClient) ListTemplates(ctx context.Context, in *ListTemplatesRequest) (*ListTemplatesResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "pb")
+	ctx = ctxsetters.WithServiceName(ctx, "Event")
+	ctx = ctxsetters.WithMethodName(ctx, "ListTemplates")
+	out := new(ListTemplatesResponse)
+	err := doJSONRequest(ctx, c.client, c.urls[9], in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventJSONClient) DeleteTemplate(ctx context.Context, in *DeleteTemplateRequest) (*DeleteTemplateResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "pb")
+	ctx = ctxsetters.WithServiceName(ctx, "Event")
+	ctx = ctxsetters.WithMethodName(ctx, "DeleteTemplate")
+	out := new(DeleteTemplateResponse)
+	err := doJSONRequest(ctx, c.client, c.urls[10], in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventJSONClient) Materialize(ctx context.Context, in *MaterializeRequest) (*MaterializeResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "pb")
+	ctx = ctxsetters.WithServiceName(ctx, "Event")
+	ctx = ctxsetters.WithMethodName(ctx, "Materialize")
+	out := new(MaterializeResponse)
+	err := doJSONRequest(ctx, c.client, c.urls[11], in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ====================
 // Event Server Handler
 // ====================
@@ -291,6 +487,27 @@ func (s *eventServer) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
 	case "/twirp/pb.Event/List":
 		s.serveList(ctx, resp, req)
 		return
+	case "/twirp/pb.Event/Import":
+		s.serveImport(ctx, resp, req)
+		return
+	case "/twirp/pb.Event/Export":
+		s.serveExport(ctx, resp, req)
+		return
+	case "/twirp/pb.Event/Clone":
+		s.serveClone(ctx, resp, req)
+		return
+	case "/twirp/pb.Event/CreateTemplate":
+		s.serveCreateTemplate(ctx, resp, req)
+		return
+	case "/twirp/pb.Event/ListTemplates":
+		s.serveListTemplates(ctx, resp, req)
+		return
+	case "/twirp/pb.Event/DeleteTemplate":
+		s.serveDeleteTemplate(ctx, resp, req)
+		return
+	case "/twirp/pb.Event/Materialize":
+		s.serveMaterialize(ctx, resp, req)
+		return
 	default:
 		msg := fmt.Sprintf("no handler for path %q", req.URL.Path)
 		err = badRouteError(msg, req.Method, req.URL.Path)
@@ -1019,6 +1236,1014 @@ func (s *eventServer) serveListProtobuf(ctx context.Context, resp http.ResponseW
 	callResponseSent(ctx, s.hooks)
 }
 
+func (s *eventServer) serveImport(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveImportJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveImportProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *eventServer) serveImportJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "Import")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	reqContent := new(ImportRequest)
+	unmarshaler := jsonpb.Unmarshaler{AllowUnknownFields: true}
+	if err = unmarshaler.Unmarshal(req.Body, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request json")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *ImportResponse
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.Import(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ImportResponse and nil error while calling Import. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	var buf bytes.Buffer
+	marshaler := &jsonpb.Marshaler{OrigName: true}
+	if err = marshaler.Marshal(&buf, respContent); err != nil {
+		err = wrapErr(err, "failed to marshal json response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(http.StatusOK)
+
+	respBytes := buf.Bytes()
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *eventServer) serveImportProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "Import")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		err = wrapErr(err, "failed to read request body")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+	reqContent := new(ImportRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request proto")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *ImportResponse
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.Import(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ImportResponse and nil error while calling Import. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		err = wrapErr(err, "failed to marshal proto response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *eventServer) serveExport(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveExportJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveExportProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *eventServer) serveExportJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "Export")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	reqContent := new(ExportRequest)
+	unmarshaler := jsonpb.Unmarshaler{AllowUnknownFields: true}
+	if err = unmarshaler.Unmarshal(req.Body, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request json")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *ExportResponse
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.Export(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ExportResponse and nil error while calling Export. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	var buf bytes.Buffer
+	marshaler := &jsonpb.Marshaler{OrigName: true}
+	if err = marshaler.Marshal(&buf, respContent); err != nil {
+		err = wrapErr(err, "failed to marshal json response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(http.StatusOK)
+
+	respBytes := buf.Bytes()
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *eventServer) serveExportProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "Export")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		err = wrapErr(err, "failed to read request body")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+	reqContent := new(ExportRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request proto")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *ExportResponse
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.Export(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ExportResponse and nil error while calling Export. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		err = wrapErr(err, "failed to marshal proto response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *eventServer) serveClone(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveCloneJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveCloneProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *eventServer) serveCloneJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "Clone")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	reqContent := new(CloneRequest)
+	unmarshaler := jsonpb.Unmarshaler{AllowUnknownFields: true}
+	if err = unmarshaler.Unmarshal(req.Body, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request json")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *CloneResponse
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.Clone(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *CloneResponse and nil error while calling Clone. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	var buf bytes.Buffer
+	marshaler := &jsonpb.Marshaler{OrigName: true}
+	if err = marshaler.Marshal(&buf, respContent); err != nil {
+		err = wrapErr(err, "failed to marshal json response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(http.StatusOK)
+
+	respBytes := buf.Bytes()
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *eventServer) serveCloneProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "Clone")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		err = wrapErr(err, "failed to read request body")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+	reqContent := new(CloneRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request proto")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *CloneResponse
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.Clone(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *CloneResponse and nil error while calling Clone. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		err = wrapErr(err, "failed to marshal proto response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *eventServer) serveCreateTemplate(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveCreateTemplateJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveCreateTemplateProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *eventServer) serveCreateTemplateJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "CreateTemplate")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	reqContent := new(CreateTemplateRequest)
+	unmarshaler := jsonpb.Unmarshaler{AllowUnknownFields: true}
+	if err = unmarshaler.Unmarshal(req.Body, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request json")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *CreateTemplateResponse
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.CreateTemplate(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *CreateTemplateResponse and nil error while calling CreateTemplate. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	var buf bytes.Buffer
+	marshaler := &jsonpb.Marshaler{OrigName: true}
+	if err = marshaler.Marshal(&buf, respContent); err != nil {
+		err = wrapErr(err, "failed to marshal json response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(http.StatusOK)
+
+	respBytes := buf.Bytes()
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *eventServer) serveCreateTemplateProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "CreateTemplate")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		err = wrapErr(err, "failed to read request body")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+	reqContent := new(CreateTemplateRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request proto")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *CreateTemplateResponse
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.CreateTemplate(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *CreateTemplateResponse and nil error while calling CreateTemplate. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		err = wrapErr(err, "failed to marshal proto response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *eventServer) serveListTemplates(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveListTemplatesJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveListTemplatesProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *eventServer) serveListTemplatesJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ListTemplates")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	reqContent := new(ListTemplatesRequest)
+	unmarshaler := jsonpb.Unmarshaler{AllowUnknownFields: true}
+	if err = unmarshaler.Unmarshal(req.Body, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request json")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *ListTemplatesResponse
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.ListTemplates(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ListTemplatesResponse and nil error while calling ListTemplates. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	var buf bytes.Buffer
+	marshaler := &jsonpb.Marshaler{OrigName: true}
+	if err = marshaler.Marshal(&buf, respContent); err != nil {
+		err = wrapErr(err, "failed to marshal json response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(http.StatusOK)
+
+	respBytes := buf.Bytes()
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *eventServer) serveListTemplatesProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ListTemplates")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		err = wrapErr(err, "failed to read request body")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+	reqContent := new(ListTemplatesRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request proto")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *ListTemplatesResponse
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.ListTemplates(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ListTemplatesResponse and nil error while calling ListTemplates. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		err = wrapErr(err, "failed to marshal proto response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *eventServer) serveDeleteTemplate(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveDeleteTemplateJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveDeleteTemplateProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *eventServer) serveDeleteTemplateJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "DeleteTemplate")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	reqContent := new(DeleteTemplateRequest)
+	unmarshaler := jsonpb.Unmarshaler{AllowUnknownFields: true}
+	if err = unmarshaler.Unmarshal(req.Body, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request json")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *DeleteTemplateResponse
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.DeleteTemplate(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *DeleteTemplateResponse and nil error while calling DeleteTemplate. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	var buf bytes.Buffer
+	marshaler := &jsonpb.Marshaler{OrigName: true}
+	if err = marshaler.Marshal(&buf, respContent); err != nil {
+		err = wrapErr(err, "failed to marshal json response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(http.StatusOK)
+
+	respBytes := buf.Bytes()
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *eventServer) serveDeleteTemplateProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "DeleteTemplate")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		err = wrapErr(err, "failed to read request body")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+	reqContent := new(DeleteTemplateRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request proto")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *DeleteTemplateResponse
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.DeleteTemplate(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *DeleteTemplateResponse and nil error while calling DeleteTemplate. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		err = wrapErr(err, "failed to marshal proto response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *eventServer) serveMaterialize(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveMaterializeJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveMaterializeProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *eventServer) serveMaterializeJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "Materialize")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	reqContent := new(MaterializeRequest)
+	unmarshaler := jsonpb.Unmarshaler{AllowUnknownFields: true}
+	if err = unmarshaler.Unmarshal(req.Body, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request json")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *MaterializeResponse
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.Materialize(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *MaterializeResponse and nil error while calling Materialize. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	var buf bytes.Buffer
+	marshaler := &jsonpb.Marshaler{OrigName: true}
+	if err = marshaler.Marshal(&buf, respContent); err != nil {
+		err = wrapErr(err, "failed to marshal json response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(http.StatusOK)
+
+	respBytes := buf.Bytes()
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *eventServer) serveMaterializeProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "Materialize")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		err = wrapErr(err, "failed to read request body")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+	reqContent := new(MaterializeRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request proto")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *MaterializeResponse
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.Materialize(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *MaterializeResponse and nil error while calling Materialize. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		err = wrapErr(err, "failed to marshal proto response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
 func (s *eventServer) ServiceDescriptor() ([]byte, int) {
 	return twirpFileDescriptor0, 0
 }
@@ -1448,27 +2673,61 @@ func callError(ctx context.Context, h *twirp.ServerHooks, err twirp.Error) conte
 }
 
 var twirpFileDescriptor0 = []byte{
-	// 349 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x53, 0xc1, 0x4a, 0xc3, 0x40,
-	0x10, 0x6d, 0xd2, 0x36, 0xb4, 0xd3, 0x36, 0xd6, 0x39, 0x95, 0x7a, 0x09, 0x8b, 0x60, 0x51, 0x48,
-	0x69, 0xf5, 0x22, 0x78, 0x53, 0x29, 0x82, 0x60, 0x09, 0x78, 0x96, 0xc4, 0xcc, 0x21, 0xa2, 0xc9,
-	0x9a, 0x5d, 0xfb, 0xeb, 0x5e, 0x25, 0x3b, 0x49, 0xda, 0x46, 0x84, 0x1e, 0xbc, 0x6d, 0xdf, 0xcc,
-	0x7b, 0xf3, 0xe6, 0x4d, 0x03, 0x67, 0x8a, 0xf2, 0x4d, 0xf2, 0x4a, 0x6a, 0x4e, 0x1b, 0x4a, 0xf5,
-	0x5c, 0xe7, 0x61, 0xaa, 0x64, 0x96, 0xeb, 0xb9, 0x8c, 0x18, 0xf2, 0x65, 0x9e, 0xe9, 0x0c, 0x6d,
-	0x19, 0x09, 0x1f, 0x7a, 0x06, 0x7a, 0x8a, 0xde, 0xd0, 0x05, 0x3b, 0x89, 0x27, 0x96, 0x67, 0xcd,
-	0x86, 0x81, 0x9d, 0xc4, 0x88, 0xd0, 0x49, 0xc3, 0x0f, 0x9a, 0xd8, 0x9e, 0x35, 0xeb, 0x07, 0xe6,
-	0x2d, 0xd6, 0x30, 0xba, 0xcd, 0x29, 0xd4, 0x14, 0xd0, 0xe7, 0x17, 0x29, 0x8d, 0x27, 0xd0, 0xd7,
-	0x94, 0x86, 0xa9, 0x7e, 0xa9, 0xb9, 0x3d, 0x06, 0x1e, 0x62, 0x14, 0xd0, 0x35, 0xea, 0x46, 0x62,
-	0xb0, 0x1c, 0xfa, 0x32, 0xf2, 0xab, 0x71, 0x01, 0x97, 0x84, 0x07, 0x6e, 0xa5, 0xa8, 0x64, 0x96,
-	0x2a, 0x6a, 0xfa, 0x10, 0xd7, 0x00, 0x2b, 0xd2, 0x07, 0x0d, 0x64, 0xaa, 0x5d, 0x53, 0x17, 0x30,
-	0x30, 0xd4, 0x52, 0xb9, 0xf6, 0x63, 0xfd, 0xed, 0x67, 0x0d, 0xa3, 0x67, 0x19, 0xff, 0xe7, 0x86,
-	0x63, 0x70, 0x2b, 0x45, 0xf6, 0x21, 0x6e, 0x60, 0x74, 0x47, 0xef, 0x74, 0xe0, 0x8c, 0xe6, 0x52,
-	0x63, 0x70, 0x2b, 0x76, 0xa9, 0x77, 0x0e, 0x83, 0xc7, 0x44, 0x1d, 0x14, 0x91, 0xb8, 0x82, 0x21,
-	0xf7, 0x96, 0x99, 0x9c, 0x82, 0x63, 0x6c, 0xaa, 0x89, 0xe5, 0xb5, 0x7f, 0xad, 0x50, 0xd6, 0x96,
-	0xdf, 0x16, 0x74, 0xef, 0x8b, 0x27, 0x2e, 0xc0, 0xe1, 0x7b, 0xe1, 0x71, 0xd1, 0xb9, 0xf7, 0x6f,
-	0x98, 0xe2, 0x2e, 0x54, 0x9a, 0x6b, 0xe1, 0x0c, 0xda, 0x2b, 0xd2, 0xe8, 0x16, 0xc5, 0xed, 0x25,
-	0xa7, 0x47, 0xf5, 0xef, 0xba, 0x73, 0x01, 0x0e, 0x47, 0xc5, 0xe2, 0x7b, 0x87, 0x60, 0xf1, 0x46,
-	0x92, 0x86, 0xc2, 0x69, 0x30, 0x65, 0x2f, 0x57, 0xa6, 0x34, 0xc2, 0x6a, 0xe1, 0x05, 0x74, 0x8a,
-	0x08, 0xd0, 0x18, 0xd8, 0x09, 0x6e, 0x3a, 0xde, 0x02, 0x55, 0x73, 0xe4, 0x98, 0x8f, 0xe5, 0xf2,
-	0x27, 0x00, 0x00, 0xff, 0xff, 0x25, 0x75, 0x29, 0x7e, 0x57, 0x03, 0x00, 0x00,
+	// 884 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x56, 0xdd, 0x4e, 0xe3, 0x46,
+	0x14, 0xc6, 0x71, 0x6c, 0x92, 0x93, 0x1f, 0xc2, 0x34, 0x80, 0x71, 0x2f, 0x1a, 0x8d, 0x2a, 0x11,
+	0x15, 0x11, 0x04, 0x54, 0x95, 0xaa, 0xf6, 0xa2, 0x08, 0x02, 0x8a, 0xd4, 0xaa, 0xc8, 0x2a, 0x6a,
+	0xef, 0x22, 0x07, 0x0f, 0x95, 0x5b, 0xc7, 0xf6, 0x8e, 0x27, 0x10, 0x78, 0x97, 0x7d, 0x84, 0x7d,
+	0xc6, 0x5d, 0xcd, 0x8f, 0x1d, 0xdb, 0x49, 0xa4, 0x80, 0xf6, 0xce, 0xe7, 0xff, 0x3b, 0x67, 0xce,
+	0x7c, 0x63, 0x38, 0x4a, 0x08, 0x7d, 0xf2, 0x1f, 0x48, 0x72, 0x4a, 0x9e, 0x48, 0xc8, 0x4e, 0x19,
+	0x75, 0xc3, 0x24, 0x8e, 0x28, 0x3b, 0x8d, 0x27, 0x52, 0x35, 0x88, 0x69, 0xc4, 0x22, 0x54, 0x89,
+	0x27, 0xf8, 0xa3, 0x06, 0x35, 0xa1, 0xfb, 0x73, 0xf2, 0x1f, 0x6a, 0x43, 0xc5, 0xf7, 0x2c, 0xad,
+	0xa7, 0xf5, 0x9b, 0x4e, 0xc5, 0xf7, 0x10, 0x82, 0x6a, 0xe8, 0x4e, 0x89, 0x55, 0xe9, 0x69, 0xfd,
+	0xba, 0x23, 0xbe, 0xd1, 0xb7, 0x50, 0x67, 0x24, 0x74, 0x43, 0x36, 0xf6, 0x3d, 0x4b, 0x17, 0xae,
+	0x35, 0xa9, 0x18, 0x79, 0xdc, 0x98, 0x30, 0x97, 0xb2, 0x64, 0xec, 0x32, 0xab, 0xda, 0xd3, 0xfa,
+	0xba, 0x53, 0x93, 0x8a, 0x4b, 0x86, 0x0e, 0x60, 0x9b, 0x84, 0x9e, 0x30, 0x19, 0xc2, 0x64, 0x72,
+	0xf1, 0x92, 0x21, 0x1b, 0x6a, 0xcc, 0x9f, 0x92, 0xd7, 0x28, 0x24, 0x96, 0x29, 0x4a, 0x65, 0x32,
+	0xbe, 0x83, 0xd6, 0x15, 0x25, 0x2e, 0x23, 0x0e, 0xf9, 0x30, 0x23, 0x09, 0x2b, 0xd6, 0xd7, 0x4a,
+	0xf5, 0x31, 0x18, 0xa2, 0x19, 0x81, 0xb8, 0x71, 0xde, 0x1c, 0xc4, 0x93, 0x41, 0xda, 0x9d, 0x23,
+	0x4d, 0xb8, 0x07, 0xed, 0x34, 0x63, 0x12, 0x47, 0x61, 0x42, 0xca, 0x6d, 0xe3, 0x9f, 0x01, 0x6e,
+	0x09, 0xdb, 0xa8, 0xa0, 0x0c, 0xad, 0x64, 0xa1, 0x67, 0xd0, 0x10, 0xa1, 0x2a, 0x73, 0x86, 0x47,
+	0x5b, 0x8f, 0xe7, 0x0e, 0x5a, 0xf7, 0xb1, 0xf7, 0x35, 0x3b, 0xec, 0x40, 0x3b, 0xcd, 0x28, 0x71,
+	0xe0, 0x5f, 0xa1, 0x75, 0x4d, 0x02, 0xc2, 0xc8, 0xbb, 0x9a, 0xea, 0x40, 0x3b, 0x8d, 0x56, 0xf9,
+	0x7e, 0x80, 0xc6, 0xef, 0x7e, 0xb2, 0xd1, 0x88, 0xf0, 0x8f, 0xd0, 0x94, 0xbe, 0x6a, 0x26, 0xdf,
+	0x83, 0x29, 0x60, 0x26, 0x96, 0xd6, 0xd3, 0x97, 0x5a, 0x50, 0x36, 0x3c, 0x82, 0x86, 0x3f, 0xe5,
+	0x4b, 0x3b, 0xa4, 0x34, 0xa2, 0xa8, 0x03, 0x3a, 0x8d, 0x9e, 0x45, 0x6e, 0xc3, 0xe1, 0x9f, 0xa8,
+	0x0b, 0x06, 0xe1, 0x26, 0xb5, 0x9c, 0x52, 0xe0, 0x1b, 0xfb, 0x10, 0x79, 0x44, 0x2c, 0x66, 0xdd,
+	0x11, 0xdf, 0xf8, 0x7f, 0x68, 0x8d, 0x44, 0xaa, 0x8d, 0x9a, 0x5f, 0xc0, 0xab, 0xac, 0x87, 0xc7,
+	0x77, 0xd9, 0xa3, 0x2f, 0x63, 0x3a, 0x0b, 0x45, 0xa9, 0x9a, 0x63, 0x7a, 0xf4, 0xc5, 0x99, 0x85,
+	0xf8, 0x5f, 0x68, 0xa7, 0xc5, 0x54, 0xbf, 0x08, 0xaa, 0x34, 0x7a, 0x4e, 0x14, 0x76, 0xf1, 0x8d,
+	0x2c, 0xd8, 0x7e, 0x10, 0x3b, 0xe8, 0x89, 0x2a, 0x4d, 0x27, 0x15, 0xd1, 0x11, 0x98, 0xa2, 0x93,
+	0xc4, 0xd2, 0x45, 0xf9, 0x1d, 0x5e, 0x3e, 0x37, 0x09, 0x47, 0x99, 0xf1, 0x3f, 0xd0, 0x1a, 0xce,
+	0x37, 0xee, 0xaa, 0x0b, 0x86, 0xfb, 0xc8, 0x08, 0x55, 0xa7, 0x2a, 0x05, 0xae, 0x0d, 0xfc, 0xa9,
+	0xcf, 0x44, 0x0f, 0x86, 0x23, 0x05, 0xfc, 0x13, 0xb4, 0x87, 0xf3, 0x42, 0x0b, 0x9b, 0x1d, 0x59,
+	0x00, 0xcd, 0xab, 0x20, 0x0a, 0xdf, 0xb5, 0x63, 0x19, 0xd5, 0xe8, 0x45, 0xaa, 0x59, 0xcb, 0x26,
+	0xf8, 0x3b, 0x68, 0xa9, 0x6a, 0x6b, 0x6e, 0xf1, 0x67, 0x0d, 0x1a, 0x8c, 0x4c, 0xe3, 0xc0, 0x65,
+	0x64, 0x15, 0xb9, 0x15, 0xe0, 0x55, 0x4a, 0xf0, 0x56, 0xc1, 0xe9, 0x82, 0x41, 0xe9, 0x2c, 0x20,
+	0x02, 0x4a, 0xdd, 0x91, 0x42, 0x11, 0xa4, 0xb1, 0x9e, 0xf2, 0xcc, 0xb5, 0x94, 0xb7, 0x5d, 0xa4,
+	0x3c, 0x74, 0x02, 0x68, 0xea, 0x32, 0x42, 0x7d, 0x37, 0xf0, 0x5f, 0x89, 0x37, 0x9e, 0x85, 0xcc,
+	0x0f, 0xac, 0x9a, 0x88, 0xdf, 0xcd, 0x5b, 0xee, 0xb9, 0x81, 0xef, 0xd2, 0xa3, 0xeb, 0x07, 0x33,
+	0x4a, 0xac, 0xba, 0xc8, 0x94, 0x8a, 0xd8, 0x85, 0x3d, 0xc9, 0x74, 0x7f, 0xa9, 0x31, 0x6c, 0x74,
+	0x32, 0xc7, 0x50, 0x4b, 0xc7, 0xa6, 0x48, 0x46, 0xec, 0x60, 0x6e, 0x94, 0x4e, 0xe6, 0x80, 0xfb,
+	0xb0, 0x5f, 0x2e, 0xb1, 0xe6, 0x38, 0x2e, 0xa0, 0xcb, 0x69, 0x20, 0xf5, 0x4b, 0x36, 0xe2, 0x8e,
+	0x1b, 0xd8, 0x2b, 0x05, 0xa9, 0xec, 0x27, 0x3c, 0x4a, 0x29, 0xd5, 0x52, 0x2e, 0xa1, 0x5c, 0x78,
+	0xe0, 0x6b, 0xd8, 0x93, 0x0c, 0xf6, 0xa6, 0x49, 0x94, 0x79, 0xd0, 0x82, 0xfd, 0x72, 0x16, 0xc5,
+	0x87, 0x7f, 0x03, 0xfa, 0x63, 0x71, 0x30, 0xef, 0xba, 0x00, 0x5d, 0x30, 0xe4, 0x41, 0xeb, 0xe2,
+	0xa0, 0xa5, 0x80, 0x7f, 0x81, 0x6f, 0x0a, 0x89, 0xdf, 0x72, 0x21, 0xcf, 0x3f, 0x19, 0x60, 0x0c,
+	0xf9, 0x27, 0x3a, 0x03, 0x53, 0x1e, 0x13, 0xda, 0xe5, 0x9e, 0x85, 0x17, 0xd5, 0x46, 0x79, 0x95,
+	0x6a, 0x68, 0x0b, 0xf5, 0x41, 0xbf, 0x25, 0x0c, 0xb5, 0xb9, 0x71, 0xf1, 0x1a, 0xda, 0x3b, 0x99,
+	0x9c, 0x79, 0x9e, 0x81, 0x29, 0x9f, 0x1b, 0x99, 0xbc, 0xf0, 0x98, 0xd9, 0x28, 0xaf, 0xca, 0x87,
+	0xc8, 0x49, 0xca, 0x90, 0xc2, 0xdb, 0x64, 0xa3, 0xbc, 0x2a, 0x0b, 0x39, 0x86, 0x2a, 0x5f, 0x05,
+	0x24, 0x00, 0xe4, 0x1e, 0x1f, 0xbb, 0xb3, 0x50, 0xe4, 0xf3, 0x4b, 0x16, 0x96, 0xf9, 0x0b, 0xf4,
+	0x6f, 0xa3, 0xbc, 0x2a, 0x1f, 0x32, 0x9c, 0x2f, 0x42, 0x86, 0xf3, 0xa5, 0x90, 0x22, 0x29, 0xe2,
+	0x2d, 0x34, 0x00, 0x43, 0x50, 0x10, 0x12, 0x10, 0xf2, 0xdc, 0x67, 0xef, 0xe6, 0x34, 0x99, 0xff,
+	0x28, 0xfd, 0xf3, 0x48, 0xf7, 0x07, 0x1d, 0x2e, 0x46, 0x5f, 0xda, 0x4c, 0xdb, 0x5e, 0x65, 0xca,
+	0x52, 0xdd, 0x40, 0xab, 0x70, 0x31, 0x90, 0x95, 0x4e, 0xa1, 0x7c, 0xc1, 0xec, 0xc3, 0x15, 0x96,
+	0x3c, 0xa4, 0xe2, 0x4a, 0x4b, 0x48, 0x2b, 0x2f, 0x8b, 0x6d, 0xaf, 0x32, 0x65, 0xa9, 0x7e, 0x83,
+	0x46, 0x6e, 0x55, 0xd1, 0x3e, 0x77, 0x5e, 0xbe, 0x14, 0xf6, 0xc1, 0x92, 0x3e, 0xcd, 0x30, 0x31,
+	0xc5, 0x6f, 0xe9, 0xc5, 0x17, 0x00, 0x00, 0x00, 0xff, 0xff, 0x03, 0x00, 0x82, 0x50, 0x26, 0xac,
+	0xc1, 0x0a, 0x00, 0x00,
 }
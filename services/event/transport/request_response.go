@@ -1,6 +1,9 @@
 package transport
 
 import (
+	// stdlib
+	"time"
+
 	// external
 	"github.com/go-kit/kit/endpoint"
 	"github.com/kevinburke/go.uuid"
@@ -15,6 +18,13 @@ var (
 	_ endpoint.Failer = UpdateResponse{}
 	_ endpoint.Failer = DeleteResponse{}
 	_ endpoint.Failer = ListResponse{}
+	_ endpoint.Failer = ImportResponse{}
+	_ endpoint.Failer = ExportResponse{}
+	_ endpoint.Failer = CloneResponse{}
+	_ endpoint.Failer = CreateTemplateResponse{}
+	_ endpoint.Failer = ListTemplatesResponse{}
+	_ endpoint.Failer = DeleteTemplateResponse{}
+	_ endpoint.Failer = MaterializeResponse{}
 )
 
 // CreateRequest holds the request parameters for the Create method.
@@ -88,3 +98,117 @@ type ListResponse struct {
 
 // Failed implements Failer
 func (r ListResponse) Failed() error { return r.Err }
+
+// ImportRequest holds the request parameters for the Import method.
+type ImportRequest struct {
+	TenantID uuid.UUID
+	Events   []event.Event
+	DryRun   bool
+}
+
+// ImportResponse holds the response values for the Import method.
+type ImportResponse struct {
+	Result *event.ImportResult
+	Err    error
+}
+
+// Failed implements Failer
+func (r ImportResponse) Failed() error { return r.Err }
+
+// ExportRequest holds the request parameters for the Export method.
+type ExportRequest struct {
+	TenantID uuid.UUID
+	After    uuid.UUID
+	Limit    int
+}
+
+// ExportResponse holds the response values for the Export method.
+type ExportResponse struct {
+	Events []*event.Event
+	Err    error
+}
+
+// Failed implements Failer
+func (r ExportResponse) Failed() error { return r.Err }
+
+// CloneRequest holds the request parameters for the Clone method.
+type CloneRequest struct {
+	TenantID uuid.UUID
+	ID       uuid.UUID
+	Name     string
+	Start    time.Time
+}
+
+// CloneResponse holds the response values for the Clone method.
+type CloneResponse struct {
+	ID  *uuid.UUID
+	Err error
+}
+
+// Failed implements Failer
+func (r CloneResponse) Failed() error { return r.Err }
+
+// CreateTemplateRequest holds the request parameters for the CreateTemplate
+// method.
+type CreateTemplateRequest struct {
+	TenantID uuid.UUID
+	Template event.Template
+}
+
+// CreateTemplateResponse holds the response values for the CreateTemplate
+// method.
+type CreateTemplateResponse struct {
+	ID  *uuid.UUID
+	Err error
+}
+
+// Failed implements Failer
+func (r CreateTemplateResponse) Failed() error { return r.Err }
+
+// ListTemplatesRequest holds the request parameters for the ListTemplates
+// method.
+type ListTemplatesRequest struct {
+	TenantID uuid.UUID
+}
+
+// ListTemplatesResponse holds the response values for the ListTemplates
+// method.
+type ListTemplatesResponse struct {
+	Templates []*event.Template
+	Err       error
+}
+
+// Failed implements Failer
+func (r ListTemplatesResponse) Failed() error { return r.Err }
+
+// DeleteTemplateRequest holds the request parameters for the DeleteTemplate
+// method.
+type DeleteTemplateRequest struct {
+	TenantID uuid.UUID
+	ID       uuid.UUID
+}
+
+// DeleteTemplateResponse holds the response values for the DeleteTemplate
+// method.
+type DeleteTemplateResponse struct {
+	Err error
+}
+
+// Failed implements Failer
+func (r DeleteTemplateResponse) Failed() error { return r.Err }
+
+// MaterializeRequest holds the request parameters for the Materialize method.
+type MaterializeRequest struct {
+	TenantID uuid.UUID
+	ID       uuid.UUID
+	Until    time.Time
+}
+
+// MaterializeResponse holds the response values for the Materialize method.
+type MaterializeResponse struct {
+	Events []*event.Event
+	Err    error
+}
+
+// Failed implements Failer
+func (r MaterializeResponse) Failed() error { return r.Err }
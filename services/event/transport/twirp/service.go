@@ -3,15 +3,16 @@ package twirp
 import (
 	// stdlib
 	"context"
+	"time"
 
 	// external
 	"github.com/go-kit/kit/log"
 	"github.com/kevinburke/go.uuid"
-	"github.com/twitchtv/twirp"
 
 	// project
 	"github.com/basvanbeek/opencensus-gokit-example/services/event"
 	"github.com/basvanbeek/opencensus-gokit-example/services/event/transport/pb"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/errcode"
 )
 
 type server struct {
@@ -31,20 +32,13 @@ func (s *server) Create(ctx context.Context, r *pb.CreateRequest) (*pb.CreateRes
 	id, err := s.svc.Create(
 		ctx,
 		uuid.FromBytesOrNil(r.TenantId),
-		event.Event{
-			ID:   uuid.FromBytesOrNil(r.Event.Id),
-			Name: r.Event.Name,
-		},
+		fromPB(r.Event),
 	)
 
-	switch err {
-	case nil:
-		return &pb.CreateResponse{Id: id.Bytes()}, nil
-	case event.ErrEventExists:
-		return nil, twirp.NewError(twirp.AlreadyExists, err.Error())
-	default:
-		return nil, twirp.InternalErrorWith(err)
+	if err != nil {
+		return nil, errcode.TwirpError(err)
 	}
+	return &pb.CreateResponse{Id: id.Bytes()}, nil
 }
 
 func (s *server) Get(ctx context.Context, r *pb.GetRequest) (*pb.GetResponse, error) {
@@ -54,38 +48,23 @@ func (s *server) Get(ctx context.Context, r *pb.GetRequest) (*pb.GetResponse, er
 		uuid.FromBytesOrNil(r.Id),
 	)
 
-	switch err {
-	case nil:
-		return &pb.GetResponse{
-			Event: &pb.EventObj{Id: evt.ID.Bytes(), Name: evt.Name},
-		}, nil
-	case event.ErrNotFound:
-		return nil, twirp.NotFoundError(err.Error())
-	default:
-		return nil, twirp.InternalErrorWith(err)
+	if err != nil {
+		return nil, errcode.TwirpError(err)
 	}
+	return &pb.GetResponse{Event: toPB(evt)}, nil
 }
 
 func (s *server) Update(ctx context.Context, r *pb.UpdateRequest) (*pb.UpdateResponse, error) {
 	err := s.svc.Update(
 		ctx,
 		uuid.FromBytesOrNil(r.TenantId),
-		event.Event{
-			ID:   uuid.FromBytesOrNil(r.Event.Id),
-			Name: r.Event.Name,
-		},
+		fromPB(r.Event),
 	)
 
-	switch err {
-	case nil:
-		return &pb.UpdateResponse{}, nil
-	case event.ErrNotFound:
-		return nil, twirp.NotFoundError(err.Error())
-	case event.ErrEventExists:
-		return nil, twirp.NewError(twirp.AlreadyExists, err.Error())
-	default:
-		return nil, twirp.InternalErrorWith(err)
+	if err != nil {
+		return nil, errcode.TwirpError(err)
 	}
+	return &pb.UpdateResponse{}, nil
 }
 
 func (s *server) Delete(ctx context.Context, r *pb.DeleteRequest) (*pb.DeleteResponse, error) {
@@ -95,28 +74,223 @@ func (s *server) Delete(ctx context.Context, r *pb.DeleteRequest) (*pb.DeleteRes
 		uuid.FromBytesOrNil(r.Id),
 	)
 
-	switch err {
-	case nil:
-		return &pb.DeleteResponse{}, nil
-	case event.ErrNotFound:
-		return nil, twirp.NotFoundError(err.Error())
-	default:
-		return nil, twirp.InternalErrorWith(err)
+	if err != nil {
+		return nil, errcode.TwirpError(err)
 	}
+	return &pb.DeleteResponse{}, nil
 }
 
 func (s *server) List(ctx context.Context, r *pb.ListRequest) (*pb.ListResponse, error) {
 	events, err := s.svc.List(ctx, uuid.FromBytesOrNil(r.TenantId))
 	if err != nil {
-		return nil, twirp.InternalErrorWith(err)
+		return nil, errcode.TwirpError(err)
 	}
 	pbEvents := make([]*pb.EventObj, 0, len(events))
 	for _, event := range events {
-		pbEvent := &pb.EventObj{
-			Id:   event.ID.Bytes(),
-			Name: event.Name,
-		}
-		pbEvents = append(pbEvents, pbEvent)
+		pbEvents = append(pbEvents, toPB(event))
 	}
 	return &pb.ListResponse{Events: pbEvents}, nil
 }
+
+func (s *server) Import(ctx context.Context, r *pb.ImportRequest) (*pb.ImportResponse, error) {
+	events := make([]event.Event, 0, len(r.Events))
+	for _, evt := range r.Events {
+		events = append(events, fromPB(evt))
+	}
+
+	result, err := s.svc.Import(ctx, uuid.FromBytesOrNil(r.TenantId), events, r.DryRun)
+
+	if err != nil {
+		return nil, errcode.TwirpError(err)
+	}
+	res := &pb.ImportResponse{
+		Rows:    int32(result.Rows),
+		Created: make([][]byte, 0, len(result.Created)),
+		Errors:  make([]*pb.ImportError, 0, len(result.Errors)),
+	}
+	for _, id := range result.Created {
+		res.Created = append(res.Created, id.Bytes())
+	}
+	for _, rowErr := range result.Errors {
+		res.Errors = append(res.Errors, &pb.ImportError{
+			Row:   int32(rowErr.Row),
+			Error: rowErr.Err.Error(),
+			Code:  errcode.As(rowErr.Err).Code(),
+		})
+	}
+	return res, nil
+}
+
+func (s *server) Export(ctx context.Context, r *pb.ExportRequest) (*pb.ExportResponse, error) {
+	events, err := s.svc.Export(
+		ctx,
+		uuid.FromBytesOrNil(r.TenantId),
+		uuid.FromBytesOrNil(r.After),
+		int(r.Limit),
+	)
+	if err != nil {
+		return nil, errcode.TwirpError(err)
+	}
+	pbEvents := make([]*pb.EventObj, 0, len(events))
+	for _, event := range events {
+		pbEvents = append(pbEvents, toPB(event))
+	}
+	return &pb.ExportResponse{Events: pbEvents}, nil
+}
+
+func (s *server) Clone(ctx context.Context, r *pb.CloneRequest) (*pb.CloneResponse, error) {
+	var start time.Time
+	if r.StartsAt != 0 {
+		start = time.Unix(r.StartsAt, 0).UTC()
+	}
+
+	id, err := s.svc.Clone(
+		ctx,
+		uuid.FromBytesOrNil(r.TenantId),
+		uuid.FromBytesOrNil(r.Id),
+		r.Name,
+		start,
+	)
+
+	if err != nil {
+		return nil, errcode.TwirpError(err)
+	}
+	return &pb.CloneResponse{Id: id.Bytes()}, nil
+}
+
+func (s *server) CreateTemplate(
+	ctx context.Context, r *pb.CreateTemplateRequest,
+) (*pb.CreateTemplateResponse, error) {
+	id, err := s.svc.CreateTemplate(
+		ctx,
+		uuid.FromBytesOrNil(r.TenantId),
+		templateFromPB(r.Template),
+	)
+
+	if err != nil {
+		return nil, errcode.TwirpError(err)
+	}
+	return &pb.CreateTemplateResponse{Id: id.Bytes()}, nil
+}
+
+func (s *server) ListTemplates(
+	ctx context.Context, r *pb.ListTemplatesRequest,
+) (*pb.ListTemplatesResponse, error) {
+	templates, err := s.svc.ListTemplates(ctx, uuid.FromBytesOrNil(r.TenantId))
+	if err != nil {
+		return nil, errcode.TwirpError(err)
+	}
+	pbTemplates := make([]*pb.TemplateObj, 0, len(templates))
+	for _, template := range templates {
+		pbTemplates = append(pbTemplates, templateToPB(template))
+	}
+	return &pb.ListTemplatesResponse{Templates: pbTemplates}, nil
+}
+
+func (s *server) DeleteTemplate(
+	ctx context.Context, r *pb.DeleteTemplateRequest,
+) (*pb.DeleteTemplateResponse, error) {
+	if err := s.svc.DeleteTemplate(
+		ctx,
+		uuid.FromBytesOrNil(r.TenantId),
+		uuid.FromBytesOrNil(r.Id),
+	); err != nil {
+		return nil, errcode.TwirpError(err)
+	}
+	return &pb.DeleteTemplateResponse{}, nil
+}
+
+func (s *server) Materialize(
+	ctx context.Context, r *pb.MaterializeRequest,
+) (*pb.MaterializeResponse, error) {
+	events, err := s.svc.Materialize(
+		ctx,
+		uuid.FromBytesOrNil(r.TenantId),
+		uuid.FromBytesOrNil(r.Id),
+		time.Unix(r.Until, 0).UTC(),
+	)
+
+	if err != nil {
+		return nil, errcode.TwirpError(err)
+	}
+	pbEvents := make([]*pb.EventObj, 0, len(events))
+	for _, event := range events {
+		pbEvents = append(pbEvents, toPB(event))
+	}
+	return &pb.MaterializeResponse{Events: pbEvents}, nil
+}
+
+func toPB(evt *event.Event) *pb.EventObj {
+	obj := &pb.EventObj{
+		Id:       evt.ID.Bytes(),
+		Name:     evt.Name,
+		TenantId: evt.TenantID.Bytes(),
+		Timezone: evt.Timezone,
+	}
+	if !evt.Start.IsZero() {
+		obj.StartsAt = evt.Start.Unix()
+	}
+	if !evt.End.IsZero() {
+		obj.EndsAt = evt.End.Unix()
+	}
+	return obj
+}
+
+func fromPB(obj *pb.EventObj) event.Event {
+	evt := event.Event{
+		ID:       uuid.FromBytesOrNil(obj.Id),
+		Name:     obj.Name,
+		Timezone: obj.Timezone,
+	}
+	if obj.StartsAt != 0 {
+		evt.Start = time.Unix(obj.StartsAt, 0).UTC()
+	}
+	if obj.EndsAt != 0 {
+		evt.End = time.Unix(obj.EndsAt, 0).UTC()
+	}
+	return evt
+}
+
+func templateToPB(t *event.Template) *pb.TemplateObj {
+	obj := &pb.TemplateObj{
+		Id:       t.ID.Bytes(),
+		TenantId: t.TenantID.Bytes(),
+		Name:     t.Name,
+		Rrule:    t.RRule,
+		Timezone: t.Timezone,
+		Failure:  t.Failure,
+	}
+	if !t.Start.IsZero() {
+		obj.StartsAt = t.Start.Unix()
+	}
+	if !t.End.IsZero() {
+		obj.EndsAt = t.End.Unix()
+	}
+	if !t.MaterializedUntil.IsZero() {
+		obj.MaterializedUntil = t.MaterializedUntil.Unix()
+	}
+	return obj
+}
+
+func templateFromPB(obj *pb.TemplateObj) event.Template {
+	if obj == nil {
+		return event.Template{}
+	}
+	t := event.Template{
+		ID:       uuid.FromBytesOrNil(obj.Id),
+		TenantID: uuid.FromBytesOrNil(obj.TenantId),
+		Name:     obj.Name,
+		RRule:    obj.Rrule,
+		Timezone: obj.Timezone,
+	}
+	if obj.StartsAt != 0 {
+		t.Start = time.Unix(obj.StartsAt, 0).UTC()
+	}
+	if obj.EndsAt != 0 {
+		t.End = time.Unix(obj.EndsAt, 0).UTC()
+	}
+	if obj.MaterializedUntil != 0 {
+		t.MaterializedUntil = time.Unix(obj.MaterializedUntil, 0).UTC()
+	}
+	return t
+}
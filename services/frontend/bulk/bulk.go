@@ -0,0 +1,272 @@
+// Package bulk implements the CSV and JSON Lines encodings used for importing
+// and exporting events in bulk.
+package bulk
+
+import (
+	// stdlib
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	// external
+	"github.com/kevinburke/go.uuid"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/services/frontend"
+)
+
+// Format of a bulk payload.
+type Format string
+
+// Available formats
+const (
+	CSV   Format = "csv"
+	JSONL Format = "jsonl"
+)
+
+// Errors
+var (
+	ErrUnknownFormat = errors.New("unknown bulk format")
+	ErrMissingName   = errors.New("csv header is missing the name column")
+)
+
+// csv columns, dates are formatted as RFC 3339
+const (
+	columnID       = "id"
+	columnName     = "name"
+	columnStart    = "start"
+	columnEnd      = "end"
+	columnTimezone = "timezone"
+)
+
+var csvHeader = []string{columnID, columnName, columnStart, columnEnd, columnTimezone}
+
+// ParseFormat returns the Format for the provided name. An empty name results
+// in JSON Lines.
+func ParseFormat(name string) (Format, error) {
+	switch Format(strings.ToLower(name)) {
+	case "", JSONL, "ndjson":
+		return JSONL, nil
+	case CSV:
+		return CSV, nil
+	default:
+		return "", ErrUnknownFormat
+	}
+}
+
+// ContentType returns the MIME type of the Format.
+func (f Format) ContentType() string {
+	if f == CSV {
+		return "text/csv; charset=utf-8"
+	}
+	return "application/x-ndjson; charset=utf-8"
+}
+
+// ReadEvents decodes all events found in r. Errors hold the offending row
+// number, starting at 1 for the first event.
+func ReadEvents(r io.Reader, format Format) ([]frontend.Event, error) {
+	switch format {
+	case CSV:
+		return readCSV(r)
+	case JSONL:
+		return readJSONL(r)
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+func readCSV(r io.Reader) ([]frontend.Event, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]int)
+	for idx, column := range header {
+		columns[strings.ToLower(strings.TrimSpace(column))] = idx
+	}
+	if _, ok := columns[columnName]; !ok {
+		return nil, ErrMissingName
+	}
+	// value returns the record's value for an optional column
+	value := func(record []string, column string) string {
+		if idx, ok := columns[column]; ok {
+			return strings.TrimSpace(record[idx])
+		}
+		return ""
+	}
+
+	var events []frontend.Event
+	for row := 1; ; row++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return events, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %v", row, err)
+		}
+		evt := frontend.Event{
+			Name:     record[columns[columnName]],
+			Timezone: value(record, columnTimezone),
+		}
+		if id := value(record, columnID); id != "" {
+			if evt.ID, err = uuid.FromString(id); err != nil {
+				return nil, fmt.Errorf("row %d: %v", row, err)
+			}
+		}
+		if evt.Start, err = parseTime(value(record, columnStart)); err != nil {
+			return nil, fmt.Errorf("row %d: %v", row, err)
+		}
+		if evt.End, err = parseTime(value(record, columnEnd)); err != nil {
+			return nil, fmt.Errorf("row %d: %v", row, err)
+		}
+		events = append(events, evt)
+	}
+}
+
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func readJSONL(r io.Reader) ([]frontend.Event, error) {
+	var (
+		events  []frontend.Event
+		scanner = bufio.NewScanner(r)
+	)
+	for row := 1; scanner.Scan(); {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var evt frontend.Event
+		if err := json.Unmarshal([]byte(line), &evt); err != nil {
+			return nil, fmt.Errorf("row %d: %v", row, err)
+		}
+		events = append(events, evt)
+		row++
+	}
+	return events, scanner.Err()
+}
+
+// Duplicates returns an error for each event sharing its id or name with an
+// earlier event. Names are compared like the event service does: trimmed and
+// case insensitive. Rows are numbered starting at first.
+//
+// Events imported in chunks are validated per chunk by the event service, so
+// duplicates spread over chunks are only found by checking the whole import.
+func Duplicates(events []frontend.Event, first int) []frontend.ImportError {
+	var (
+		errs  []frontend.ImportError
+		ids   = make(map[uuid.UUID]int)
+		names = make(map[string]int)
+	)
+	for idx, evt := range events {
+		row := first + idx
+		if !uuid.Equal(evt.ID, uuid.Nil) {
+			if prev, ok := ids[evt.ID]; ok {
+				errs = append(errs, duplicate(row, prev))
+				continue
+			}
+			ids[evt.ID] = row
+		}
+		name := strings.ToLower(strings.Trim(evt.Name, "\r\n\t "))
+		if name == "" {
+			continue
+		}
+		if prev, ok := names[name]; ok {
+			errs = append(errs, duplicate(row, prev))
+			continue
+		}
+		names[name] = row
+	}
+	return errs
+}
+
+func duplicate(row, prev int) frontend.ImportError {
+	return frontend.ImportError{
+		Row:   row,
+		Error: fmt.Sprintf("%s: duplicate of row %d", frontend.ErrorEventExists, prev),
+	}
+}
+
+// Writer encodes events one at a time so large exports can be streamed.
+type Writer interface {
+	Write(evt *frontend.Event) error
+	Flush() error
+}
+
+// NewWriter returns a Writer encoding events to w in the provided format.
+func NewWriter(w io.Writer, format Format) (Writer, error) {
+	switch format {
+	case CSV:
+		return &csvWriter{w: csv.NewWriter(w)}, nil
+	case JSONL:
+		bw := bufio.NewWriter(w)
+		return &jsonlWriter{w: bw, enc: json.NewEncoder(bw)}, nil
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+type csvWriter struct {
+	w      *csv.Writer
+	header bool
+}
+
+func (c *csvWriter) Write(evt *frontend.Event) error {
+	if !c.header {
+		if err := c.w.Write(csvHeader); err != nil {
+			return err
+		}
+		c.header = true
+	}
+	return c.w.Write([]string{
+		evt.ID.String(), evt.Name, formatTime(evt.Start), formatTime(evt.End),
+		evt.Timezone,
+	})
+}
+
+func (c *csvWriter) Flush() error {
+	if !c.header {
+		// always emit the header so empty exports can be imported again
+		if err := c.w.Write(csvHeader); err != nil {
+			return err
+		}
+		c.header = true
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+type jsonlWriter struct {
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+func (j *jsonlWriter) Write(evt *frontend.Event) error {
+	return j.enc.Encode(evt)
+}
+
+func (j *jsonlWriter) Flush() error {
+	return j.w.Flush()
+}
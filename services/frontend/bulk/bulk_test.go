@@ -0,0 +1,41 @@
+package bulk
+
+import (
+	// stdlib
+	"testing"
+
+	// external
+	"github.com/kevinburke/go.uuid"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/services/frontend"
+)
+
+func TestDuplicates(t *testing.T) {
+	id := uuid.NewV4()
+	events := []frontend.Event{
+		{Name: "Marathon"},
+		{ID: id, Name: "Half Marathon"},
+		{Name: "5K"},
+		{Name: " marathon\t"},
+		{ID: id, Name: "10K"},
+		{Name: ""},
+		{Name: ""},
+	}
+
+	// rows are numbered as if the import was resumed from row 11
+	errs := Duplicates(events, 11)
+
+	want := map[int]string{
+		14: frontend.ErrorEventExists + ": duplicate of row 11",
+		15: frontend.ErrorEventExists + ": duplicate of row 12",
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("want %d duplicates, have %+v", len(want), errs)
+	}
+	for _, rowErr := range errs {
+		if want[rowErr.Row] != rowErr.Error {
+			t.Errorf("row %d: want %q, have %q", rowErr.Row, want[rowErr.Row], rowErr.Error)
+		}
+	}
+}
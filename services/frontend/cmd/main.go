@@ -3,8 +3,8 @@ package main
 import (
 	// stdlib
 	"context"
+	"crypto/rand"
 	"fmt"
-	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -14,26 +14,41 @@ import (
 	// external
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
-	"github.com/go-kit/kit/sd/etcd"
 	kitoc "github.com/go-kit/kit/tracing/opencensus"
 	kithttp "github.com/go-kit/kit/transport/http"
+	"github.com/gorilla/mux"
+	"github.com/jmoiron/sqlx"
 	"github.com/kevinburke/go.uuid"
+	_ "github.com/mattn/go-sqlite3"
 	"github.com/oklog/run"
 	"go.opencensus.io/plugin/ochttp"
+	"go.opencensus.io/stats/view"
 
 	// project
 	devclient "github.com/basvanbeek/opencensus-gokit-example/clients/device"
 	evtclient "github.com/basvanbeek/opencensus-gokit-example/clients/event"
 	qrclient "github.com/basvanbeek/opencensus-gokit-example/clients/qr"
+	whclient "github.com/basvanbeek/opencensus-gokit-example/clients/webhook"
 	"github.com/basvanbeek/opencensus-gokit-example/services/device"
 	"github.com/basvanbeek/opencensus-gokit-example/services/event"
+	evtcache "github.com/basvanbeek/opencensus-gokit-example/services/event/cache"
 	"github.com/basvanbeek/opencensus-gokit-example/services/frontend"
 	"github.com/basvanbeek/opencensus-gokit-example/services/frontend/implementation"
 	"github.com/basvanbeek/opencensus-gokit-example/services/frontend/transport"
 	httptransport "github.com/basvanbeek/opencensus-gokit-example/services/frontend/transport/http"
 	"github.com/basvanbeek/opencensus-gokit-example/services/qr"
-	"github.com/basvanbeek/opencensus-gokit-example/shared/network"
+	"github.com/basvanbeek/opencensus-gokit-example/services/webhook"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/admin"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/factory"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/health"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/mtls"
 	"github.com/basvanbeek/opencensus-gokit-example/shared/oc"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/quota"
+	qsqlite "github.com/basvanbeek/opencensus-gokit-example/shared/quota/sqlite"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/ratelimit"
+	rlsqlite "github.com/basvanbeek/opencensus-gokit-example/shared/ratelimit/sqlite"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/registry"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/sd"
 )
 
 func main() {
@@ -65,50 +80,150 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	// Create our etcd client for Service Discovery
-	//
-	// we could have used the v3 client but then we must vendor or suffer the
-	// following issue originating from gRPC init:
-	// panic: http: multiple registrations for /debug/requests
-	var sdc etcd.Client
+	// Create our service registry for Service Discovery, configured using
+	// OCG_REGISTRY and defaulting to etcd on localhost
+	var reg registry.Registry
 	{
-		// create our Go kit etcd client
-		sdc, err = etcd.NewClient(ctx, []string{"http://localhost:2379"}, etcd.ClientOptions{})
+		reg, err = registry.New(ctx, os.Getenv("OCG_REGISTRY"), logger)
 		if err != nil {
 			level.Error(logger).Log("exit", err)
 			os.Exit(-1)
 		}
 	}
 
-	var svc frontend.Service
+	// Create the metadata announced with our instances and the routing of our
+	// client requests, see the README for their configuration
+	var (
+		md      sd.Metadata
+		routing []sd.Option
+	)
+	{
+		if md, err = registry.LocalMetadata("http"); err != nil {
+			level.Error(logger).Log("exit", err)
+			os.Exit(-1)
+		}
+		if routing, err = registry.Routing(); err != nil {
+			level.Error(logger).Log("exit", err)
+			os.Exit(-1)
+		}
+	}
+
+	// Create the certificate source for mutual TLS between our services and
+	// the options of our clients, see the README for their configuration
+	var (
+		tlsSource  *mtls.Source
+		clientOpts []factory.Option
+	)
+	{
+		if tlsSource, err = mtls.FromEnv(logger); err != nil {
+			level.Error(logger).Log("exit", err)
+			os.Exit(-1)
+		}
+		// stop watching our certificate files on shutdown
+		defer tlsSource.Close()
+		clientOpts = []factory.Option{
+			factory.WithRouting(routing...),
+			factory.WithMTLS(tlsSource),
+		}
+	}
+
+	// Calendar feed tokens are signed with a key which needs to be shared by
+	// all Frontend instances.
+	var feedKey []byte
+	{
+		if feedKey = []byte(os.Getenv("OCG_FEED_KEY")); len(feedKey) == 0 {
+			level.Warn(logger).Log(
+				"msg", "OCG_FEED_KEY not set, calendar feed tokens only work on this instance until it restarts",
+			)
+			feedKey = make([]byte, 32)
+			if _, err = rand.Read(feedKey); err != nil {
+				level.Error(logger).Log("exit", err)
+				os.Exit(-1)
+			}
+		}
+	}
+
+	// Create our quota Meter enforcing the daily event and QR generation limits
+	// of our tenants. Limits are configured with OCG_QUOTAS, e.g.
+	// "events=100,qr_generations=50".
+	// Instances on the same host share their usage through the SQLite
+	// database in OCG_QUOTA_DB if set.
+	var meter *quota.Meter
+	{
+		limits, err := quota.ParseLimits(os.Getenv("OCG_QUOTAS"))
+		if err != nil {
+			level.Error(logger).Log("exit", err)
+			os.Exit(-1)
+		}
+		var store quota.Store = quota.NewMemoryStore()
+		if path := os.Getenv("OCG_QUOTA_DB"); path != "" {
+			db, err := sqlx.Open("sqlite3", path+"?_journal_mode=WAL&_busy_timeout=5000")
+			if err != nil {
+				level.Error(logger).Log("exit", err)
+				os.Exit(-1)
+			}
+			if store, err = qsqlite.New(db, logger); err != nil {
+				level.Error(logger).Log("exit", err)
+				os.Exit(-1)
+			}
+		}
+		meter = quota.NewMeter(store, limits)
+	}
+
+	var (
+		svc       frontend.Service
+		devClient device.Service
+	)
 	{
 		// create an instancer for the event client
-		evtInstancer, err := etcd.NewInstancer(sdc, "/services/"+event.ServiceName+"/twirp", logger)
+		evtInstancer, err := reg.Instancer(event.ServiceName, "twirp")
 		if err != nil {
 			level.Error(logger).Log("exit", err)
 		}
-		httpClient := &http.Client{Transport: &ochttp.Transport{}}
-		evtClient := evtclient.NewTwirp(evtInstancer, httpClient, logger)
+		httpClient := &http.Client{
+			Transport: &ochttp.Transport{Base: tlsSource.HTTPTransport()},
+		}
+		// keep the requests of a tenant on the event instance caching them
+		evtOpts := append(
+			[]factory.Option{factory.WithConsistentHash(evtclient.TenantKey)}, clientOpts...,
+		)
+		evtClient := evtclient.NewTwirp(evtInstancer, httpClient, logger, evtOpts...)
+		// cache event reads, saving round trips to the event service
+		evtClient = evtcache.Middleware(5*time.Second, time.Second, logger)(evtClient)
 
 		// create an instancer for the device client
-		devInstancer, err := etcd.NewInstancer(sdc, "/services/"+device.ServiceName+"/http", logger)
+		devInstancer, err := reg.Instancer(device.ServiceName, "http")
 		if err != nil {
 			level.Error(logger).Log("exit", err)
 		}
 
-		// initialize our Device client using http transport
-		devClient := devclient.NewHTTPClient(devInstancer, logger)
+		// initialize our Device client using http transport, keeping the
+		// requests of a tenant on the device instance metering its quota
+		devOpts := append(
+			[]factory.Option{factory.WithConsistentHash(devclient.TenantKey)}, clientOpts...,
+		)
+		devClient = devclient.NewHTTPClient(devInstancer, logger, devOpts...)
 
 		// create an instancer for the QR client
-		qrInstancer, err := etcd.NewInstancer(sdc, "/services/"+qr.ServiceName+"/grpc", logger)
+		qrInstancer, err := reg.Instancer(qr.ServiceName, "grpc")
 		if err != nil {
 			level.Error(logger).Log("exit", err)
 		}
 		// initialize QR client
-		qrClient := qrclient.NewGRPCClient(qrInstancer, logger)
+		qrClient := qrclient.NewGRPCClient(qrInstancer, logger, clientOpts...)
+
+		// create an instancer for the webhook client
+		whInstancer, err := reg.Instancer(webhook.ServiceName, "http")
+		if err != nil {
+			level.Error(logger).Log("exit", err)
+		}
+		// initialize webhook client
+		whClient := whclient.NewHTTPClient(whInstancer, logger, clientOpts...)
 
 		// create our frontend service
-		svc = implementation.NewService(evtClient, devClient, qrClient, logger)
+		svc = implementation.NewService(
+			evtClient, devClient, qrClient, whClient, feedKey, logger,
+		)
 		// add service level middlewares here
 	}
 
@@ -116,6 +231,27 @@ func main() {
 	{
 		endpoints = transport.MakeEndpoints(svc)
 
+		// enforce the daily quotas of our tenants
+		exceeded := frontend.ErrQuotaExceeded
+		endpoints.EventCreate = quota.Middleware(
+			meter, transport.EventCreateQuota(), exceeded, logger,
+		)(endpoints.EventCreate)
+		endpoints.EventImport = quota.Middleware(
+			meter, transport.EventImportQuota(), exceeded, logger,
+		)(endpoints.EventImport)
+		endpoints.EventClone = quota.Middleware(
+			meter, transport.EventCloneQuota(), exceeded, logger,
+		)(endpoints.EventClone)
+		// the device service only tells us the tenant owning a device over
+		// mutual TLS
+		if tlsSource != nil {
+			endpoints.GenerateQR = quota.Middleware(
+				meter, transport.GenerateQRQuota(devClient.DeviceTenant), exceeded, logger,
+			)(endpoints.GenerateQR)
+		} else {
+			level.Warn(logger).Log("msg", "mutual TLS not configured, generated QR codes are not metered")
+		}
+
 		// trace our server side endpoints
 		endpoints = transport.Endpoints{
 			Login:        oc.ServerEndpoint("Login")(endpoints.Login),
@@ -124,9 +260,53 @@ func main() {
 			EventUpdate:  oc.ServerEndpoint("EventUpdate")(endpoints.EventUpdate),
 			EventDelete:  oc.ServerEndpoint("EventDelete")(endpoints.EventDelete),
 			EventList:    oc.ServerEndpoint("EventList")(endpoints.EventList),
+			EventImport:  oc.ServerEndpoint("EventImport")(endpoints.EventImport),
+			EventExport:  oc.ServerEndpoint("EventExport")(endpoints.EventExport),
 			UnlockDevice: oc.ServerEndpoint("UnlockDevice")(endpoints.UnlockDevice),
 			GenerateQR:   oc.ServerEndpoint("GenerateQR")(endpoints.GenerateQR),
+
+			EventCalendarToken: oc.ServerEndpoint("EventCalendarToken")(endpoints.EventCalendarToken),
+			EventCalendar:      oc.ServerEndpoint("EventCalendar")(endpoints.EventCalendar),
+			EventClone:         oc.ServerEndpoint("EventClone")(endpoints.EventClone),
+
+			EventTemplateCreate: oc.ServerEndpoint("EventTemplateCreate")(endpoints.EventTemplateCreate),
+			EventTemplateList:   oc.ServerEndpoint("EventTemplateList")(endpoints.EventTemplateList),
+			EventTemplateDelete: oc.ServerEndpoint("EventTemplateDelete")(endpoints.EventTemplateDelete),
+
+			WebhookCreate: oc.ServerEndpoint("WebhookCreate")(endpoints.WebhookCreate),
+			WebhookDelete: oc.ServerEndpoint("WebhookDelete")(endpoints.WebhookDelete),
+			WebhookList:   oc.ServerEndpoint("WebhookList")(endpoints.WebhookList),
+		}
+	}
+
+	// Create our rate limiting middleware for the HTTP API. Limits are
+	// configured with OCG_RATELIMIT, e.g. "tenant=100/m,event_import.tenant=10/h".
+	// Instances on the same host share their buckets through the SQLite
+	// database in OCG_RATELIMIT_DB if set.
+	var rateLimiter mux.MiddlewareFunc
+	{
+		config, err := ratelimit.ParseConfig(os.Getenv("OCG_RATELIMIT"))
+		if err != nil {
+			level.Error(logger).Log("exit", err)
+			os.Exit(-1)
+		}
+		var store ratelimit.Store = ratelimit.NewMemoryStore()
+		if path := os.Getenv("OCG_RATELIMIT_DB"); path != "" {
+			db, err := sqlx.Open("sqlite3", path+"?_journal_mode=WAL&_busy_timeout=5000")
+			if err != nil {
+				level.Error(logger).Log("exit", err)
+				os.Exit(-1)
+			}
+			if store, err = rlsqlite.New(db, logger); err != nil {
+				level.Error(logger).Log("exit", err)
+				os.Exit(-1)
+			}
 		}
+		if err = view.Register(ratelimit.RejectionsView); err != nil {
+			level.Error(logger).Log("exit", err)
+			os.Exit(-1)
+		}
+		rateLimiter = ratelimit.Middleware(store, config, httptransport.RequestTenant, logger)
 	}
 
 	// run.Group manages our goroutine lifecycles
@@ -136,24 +316,42 @@ func main() {
 		// set-up our ZPages handler
 		oc.ZPages(g, logger)
 	}
+	{
+		// set-up our admin endpoint serving our daily usage report
+		router := http.NewServeMux()
+		router.Handle("/admin/usage", quota.NewHandler(meter, logger))
+		if err := admin.Serve(&g, router, logger); err != nil {
+			level.Error(logger).Log("exit", err)
+			os.Exit(-1)
+		}
+	}
 	{
 		// set-up our http transport
+		listener, err := registry.Listen(frontend.ServiceName, "http")
+		if err != nil {
+			level.Error(logger).Log("exit", err)
+			os.Exit(-1)
+		}
 		var (
-			bindIP, _     = network.HostIP()
-			listener, _   = net.Listen("tcp", bindIP+":0") // dynamic port assignment
-			svcInstance   = fmt.Sprintf("/services/%s/http/%s/", frontend.ServiceName, instance)
-			addr          = "http://" + listener.Addr().String()
-			ttl           = etcd.NewTTLOption(3*time.Second, 10*time.Second)
-			service       = etcd.Service{Key: svcInstance, Value: addr, TTL: ttl}
-			registrar     = etcd.NewRegistrar(sdc, service, logger)
+			addr          = sd.FormatInstance("http://"+listener.Addr().String(), md)
+			registrar     = reg.Registrar(frontend.ServiceName, "http", instance.String(), addr)
 			ocTracing     = kitoc.HTTPServerTrace()
 			serverOptions = []kithttp.ServerOption{ocTracing}
-			feService     = httptransport.NewService(endpoints, serverOptions, logger)
+			feService     = httptransport.NewService(endpoints, serverOptions, logger, rateLimiter)
+			router        = http.NewServeMux()
 		)
 
+		// serve our health endpoint next to our endpoints
+		router.Handle(health.Path, health.Handler())
+		router.Handle("/", feService)
+
+		// our users call the frontend directly, so callers are not required to
+		// present a service identity
 		g.Add(func() error {
 			registrar.Register()
-			return http.Serve(listener, feService)
+			return http.Serve(
+				tlsSource.Listener(listener), tlsSource.HTTPHandler(nil, router),
+			)
 		}, func(error) {
 			registrar.Deregister()
 			listener.Close()
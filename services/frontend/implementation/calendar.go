@@ -0,0 +1,97 @@
+package implementation
+
+import (
+	// stdlib
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"time"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/kevinburke/go.uuid"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/services/frontend"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/ical"
+)
+
+// feedMACSize is the amount of HMAC bytes included in a calendar feed token.
+const feedMACSize = 16
+
+// EventCalendarToken returns the token granting access to the calendar feed
+// of the tenant.
+func (s *service) EventCalendarToken(_ context.Context, tenantID uuid.UUID) (string, error) {
+	if uuid.Equal(tenantID, uuid.Nil) {
+		return "", frontend.ErrUnauthorized
+	}
+	token := append(tenantID.Bytes(), s.feedMAC(tenantID)...)
+	return base64.RawURLEncoding.EncodeToString(token), nil
+}
+
+// EventCalendar returns the iCalendar feed holding the dated events of the
+// tenant the token belongs to.
+func (s *service) EventCalendar(ctx context.Context, token string) ([]byte, error) {
+	logger := log.With(s.logger, "method", "EventCalendar")
+
+	tenantID, ok := s.feedTenant(token)
+	if !ok {
+		level.Debug(logger).Log("err", frontend.ErrCalendarNotFound)
+		return nil, frontend.ErrCalendarNotFound
+	}
+
+	evts, err := s.evtClient.List(ctx, tenantID)
+	if err != nil {
+		level.Error(logger).Log("err", err)
+		return nil, frontend.ErrService
+	}
+
+	calendar := ical.Calendar{
+		ProdID: "-//opencensus-gokit-example//events//EN",
+		Name:   "Events",
+		Events: make([]ical.Event, 0, len(evts)),
+	}
+	for _, evt := range evts {
+		if evt.Start.IsZero() {
+			// undated events can't be shown in a calendar
+			continue
+		}
+		calendar.Events = append(calendar.Events, ical.Event{
+			// UIDs need to be stable so calendar apps update existing entries
+			UID:      evt.ID.String() + "@opencensus-gokit-example",
+			Summary:  evt.Name,
+			Start:    evt.Start,
+			End:      evt.End,
+			Timezone: evt.Timezone,
+		})
+	}
+
+	var buf bytes.Buffer
+	if err = calendar.Encode(&buf, time.Now()); err != nil {
+		level.Error(logger).Log("err", err)
+		return nil, frontend.ErrService
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *service) feedMAC(tenantID uuid.UUID) []byte {
+	mac := hmac.New(sha256.New, s.feedKey)
+	mac.Write(tenantID.Bytes())
+	return mac.Sum(nil)[:feedMACSize]
+}
+
+// feedTenant validates the calendar feed token and returns its tenant.
+func (s *service) feedTenant(token string) (uuid.UUID, bool) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(b) != uuid.Size+feedMACSize {
+		return uuid.Nil, false
+	}
+	tenantID, err := uuid.FromBytes(b[:uuid.Size])
+	if err != nil || !hmac.Equal(b[uuid.Size:], s.feedMAC(tenantID)) {
+		return uuid.Nil, false
+	}
+	return tenantID, true
+}
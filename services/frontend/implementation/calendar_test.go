@@ -0,0 +1,77 @@
+package implementation
+
+import (
+	// stdlib
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/kevinburke/go.uuid"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/services/event"
+	evtinmemory "github.com/basvanbeek/opencensus-gokit-example/services/event/database/inmemory"
+	evtimplementation "github.com/basvanbeek/opencensus-gokit-example/services/event/implementation"
+	"github.com/basvanbeek/opencensus-gokit-example/services/frontend"
+)
+
+func TestEventCalendarToken(t *testing.T) {
+	var (
+		ctx       = context.Background()
+		logger    = log.NewNopLogger()
+		evtClient = evtimplementation.NewService(evtinmemory.New(), logger)
+		svc       = NewService(evtClient, nil, nil, nil, []byte("feed key"), logger)
+		other     = NewService(evtClient, nil, nil, nil, []byte("other key"), logger)
+		tenantID  = uuid.NewV4()
+		start     = time.Date(2030, 6, 3, 9, 0, 0, 0, time.UTC)
+	)
+	if _, err := evtClient.Create(ctx, tenantID, event.Event{Name: "Meetup", Start: start}); err != nil {
+		t.Fatal(err)
+	}
+	token, err := svc.EventCalendarToken(ctx, tenantID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	calendar, err := svc.EventCalendar(ctx, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(calendar), "SUMMARY:Meetup\r\n") {
+		t.Errorf("want the events of the tenant, have\n%s", calendar)
+	}
+
+	// tokens of another tenant, altered tokens and tokens signed with another
+	// key are rejected
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged := append(uuid.NewV4().Bytes(), b[uuid.Size:]...)
+	tampered := append([]byte{}, b...)
+	tampered[len(tampered)-1] ^= 1
+	otherToken, err := other.EventCalendarToken(ctx, tenantID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, token := range map[string]string{
+		"other tenant": base64.RawURLEncoding.EncodeToString(forged),
+		"tampered mac": base64.RawURLEncoding.EncodeToString(tampered),
+		"truncated":    base64.RawURLEncoding.EncodeToString(b[:len(b)-1]),
+		"other key":    otherToken,
+		"not base64":   "not a token!",
+		"empty":        "",
+	} {
+		if _, err = svc.EventCalendar(ctx, token); err != frontend.ErrCalendarNotFound {
+			t.Errorf("%s: want %v, have %v", name, frontend.ErrCalendarNotFound, err)
+		}
+	}
+
+	if _, err = svc.EventCalendarToken(ctx, uuid.Nil); err != frontend.ErrUnauthorized {
+		t.Errorf("want %v, have %v", frontend.ErrUnauthorized, err)
+	}
+}
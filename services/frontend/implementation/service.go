@@ -15,6 +15,7 @@ import (
 	"github.com/basvanbeek/opencensus-gokit-example/services/event"
 	"github.com/basvanbeek/opencensus-gokit-example/services/frontend"
 	"github.com/basvanbeek/opencensus-gokit-example/services/qr"
+	"github.com/basvanbeek/opencensus-gokit-example/services/webhook"
 )
 
 // service implements frontend.Service
@@ -22,15 +23,24 @@ type service struct {
 	evtClient event.Service
 	devClient device.Service
 	qrClient  qr.Service
+	whClient  webhook.Service
+	feedKey   []byte
 	logger    log.Logger
 }
 
-// NewService creates and returns a new Frontend service instance
-func NewService(evtClient event.Service, devClient device.Service, qrClient qr.Service, logger log.Logger) frontend.Service {
+// NewService creates and returns a new Frontend service instance. The feedKey
+// is used to sign the calendar feed tokens and needs to be shared by all
+// Frontend instances.
+func NewService(
+	evtClient event.Service, devClient device.Service, qrClient qr.Service,
+	whClient webhook.Service, feedKey []byte, logger log.Logger,
+) frontend.Service {
 	return &service{
 		evtClient: evtClient,
 		devClient: devClient,
 		qrClient:  qrClient,
+		whClient:  whClient,
+		feedKey:   feedKey,
 		logger:    logger,
 	}
 }
@@ -64,13 +74,17 @@ func (s *service) Login(ctx context.Context, user, pass string) (*frontend.Login
 }
 
 func (s *service) EventCreate(ctx context.Context, tenantID uuid.UUID, evt frontend.Event) (*uuid.UUID, error) {
-	id, err := s.evtClient.Create(ctx, tenantID, event.Event(evt))
+	id, err := s.evtClient.Create(ctx, tenantID, toEvent(evt))
 
 	switch err {
 	case nil:
 		return id, nil
 	case event.ErrEventExists:
 		return nil, frontend.ErrEventExists
+	case event.ErrInvalidDates:
+		return nil, frontend.ErrInvalidEventDates
+	case event.ErrInvalidZone:
+		return nil, frontend.ErrInvalidTimezone
 	default:
 		return nil, frontend.ErrService
 	}
@@ -81,7 +95,7 @@ func (s *service) EventGet(ctx context.Context, tenantID, id uuid.UUID) (*fronte
 
 	switch err {
 	case nil:
-		return (*frontend.Event)(evt), nil
+		return fromEvent(evt), nil
 	case event.ErrNotFound:
 		return nil, frontend.ErrEventNotFound
 	default:
@@ -90,7 +104,7 @@ func (s *service) EventGet(ctx context.Context, tenantID, id uuid.UUID) (*fronte
 }
 
 func (s *service) EventUpdate(ctx context.Context, tenantID uuid.UUID, evt frontend.Event) error {
-	err := s.evtClient.Update(ctx, tenantID, event.Event(evt))
+	err := s.evtClient.Update(ctx, tenantID, toEvent(evt))
 
 	switch err {
 	case nil:
@@ -99,6 +113,10 @@ func (s *service) EventUpdate(ctx context.Context, tenantID uuid.UUID, evt front
 		return frontend.ErrEventExists
 	case event.ErrNotFound:
 		return frontend.ErrEventNotFound
+	case event.ErrInvalidDates:
+		return frontend.ErrInvalidEventDates
+	case event.ErrInvalidZone:
+		return frontend.ErrInvalidTimezone
 	default:
 		return frontend.ErrService
 	}
@@ -118,11 +136,117 @@ func (s *service) EventList(ctx context.Context, tenantID uuid.UUID) ([]*fronten
 	}
 	events := make([]*frontend.Event, 0, len(evts))
 	for _, e := range evts {
-		events = append(events, (*frontend.Event)(e))
+		events = append(events, fromEvent(e))
 	}
 	return events, nil
 }
 
+// EventExport returns a page of up to limit events ordered by id, starting
+// after the provided event id.
+func (s *service) EventExport(ctx context.Context, tenantID, after uuid.UUID, limit int) ([]*frontend.Event, error) {
+	evts, err := s.evtClient.Export(ctx, tenantID, after, limit)
+	if err != nil {
+		return nil, frontend.ErrService
+	}
+	events := make([]*frontend.Event, 0, len(evts))
+	for _, e := range evts {
+		events = append(events, fromEvent(e))
+	}
+	return events, nil
+}
+
+// EventImport creates events in bulk. With dryRun set the events are only
+// validated.
+func (s *service) EventImport(ctx context.Context, tenantID uuid.UUID, evts []frontend.Event, dryRun bool) (*frontend.ImportResult, error) {
+	events := make([]event.Event, 0, len(evts))
+	for _, e := range evts {
+		events = append(events, toEvent(e))
+	}
+
+	res, err := s.evtClient.Import(ctx, tenantID, events, dryRun)
+
+	switch err {
+	case nil:
+	case event.ErrImportSize:
+		return nil, frontend.ErrImportSize
+	default:
+		return nil, frontend.ErrService
+	}
+
+	result := &frontend.ImportResult{
+		Rows:    res.Rows,
+		Created: res.Created,
+		Errors:  make([]frontend.ImportError, 0, len(res.Errors)),
+	}
+	for _, rowErr := range res.Errors {
+		var description string
+		switch rowErr.Err {
+		case event.ErrEventExists:
+			description = frontend.ErrorEventExists
+		case event.ErrRequireName:
+			description = frontend.ErrorRequireEventName
+		case event.ErrInvalidDates:
+			description = frontend.ErrorInvalidEventDates
+		case event.ErrInvalidZone:
+			description = frontend.ErrorInvalidTimezone
+		default:
+			description = frontend.ErrorService
+		}
+		result.Errors = append(result.Errors, frontend.ImportError{
+			Row: rowErr.Row, Error: description,
+		})
+	}
+	return result, nil
+}
+
+// EventClone creates a copy of an event under a new name. If devices are
+// cloned as well and that fails, the cloned event is removed again.
+func (s *service) EventClone(ctx context.Context, tenantID, eventID uuid.UUID, clone frontend.Clone) (*uuid.UUID, error) {
+	logger := log.With(s.logger, "method", "EventClone")
+
+	id, err := s.evtClient.Clone(ctx, tenantID, eventID, clone.Name, clone.Start)
+
+	switch err {
+	case nil:
+	case event.ErrNotFound:
+		return nil, frontend.ErrEventNotFound
+	case event.ErrEventExists:
+		return nil, frontend.ErrEventExists
+	case event.ErrRequireName:
+		return nil, frontend.ErrRequireEventName
+	case event.ErrInvalidDates:
+		return nil, frontend.ErrInvalidEventDates
+	case event.ErrInvalidZone:
+		return nil, frontend.ErrInvalidTimezone
+	default:
+		return nil, frontend.ErrService
+	}
+
+	if !clone.WithDevices {
+		return id, nil
+	}
+
+	count, err := s.devClient.CloneDevices(ctx, tenantID, eventID, *id)
+	switch err {
+	case nil:
+		level.Debug(logger).Log("event", *id, "devices", count)
+	case device.ErrEventNotFound:
+		// the device service has not seen the source event yet, so it can't
+		// have any devices
+		level.Debug(logger).Log("event", *id, "devices", 0)
+	default:
+		level.Error(logger).Log("err", err)
+		if dErr := s.evtClient.Delete(ctx, tenantID, *id); dErr != nil {
+			level.Error(logger).Log("msg", "unable to remove cloned event", "event", *id, "err", dErr)
+		}
+		if err == device.ErrQuotaExceeded {
+			return nil, frontend.ErrQuotaExceeded
+		}
+		return nil, frontend.ErrService
+	}
+	return id, nil
+}
+
 // Unlockdevice returns a new session for allowing device to check-in participants.
 func (s *service) UnlockDevice(ctx context.Context, eventID, deviceID uuid.UUID, unlockCode string) (*frontend.Session, error) {
 	logger := log.With(s.logger, "method", "UnlockDevice")
@@ -155,6 +279,8 @@ func (s *service) UnlockDevice(ctx context.Context, eventID, deviceID uuid.UUID,
 		}, nil
 	case device.ErrUnlockNotFound:
 		return nil, frontend.ErrUnlockNotFound
+	case device.ErrQuotaExceeded:
+		return nil, frontend.ErrQuotaExceeded
 	default:
 		return nil, frontend.ErrService
 	}
@@ -195,3 +321,73 @@ func (s *service) GenerateQR(ctx context.Context, eventID, deviceID uuid.UUID, u
 		return nil, frontend.ErrService
 	}
 }
+
+// WebhookCreate subscribes a webhook to notifications of the tenant.
+func (s *service) WebhookCreate(ctx context.Context, tenantID uuid.UUID, wh frontend.Webhook) (*uuid.UUID, error) {
+	id, err := s.whClient.Subscribe(ctx, tenantID, webhook.Subscription{
+		URL:    wh.URL,
+		Secret: wh.Secret,
+		Types:  wh.Types,
+	})
+
+	switch err {
+	case nil:
+		return id, nil
+	case webhook.ErrRequireURL, webhook.ErrInvalidURL, webhook.ErrPrivateURL,
+		webhook.ErrRequireSecret, webhook.ErrInvalidType, webhook.ErrRequireTenantID:
+		return nil, frontend.ErrInvalidWebhook
+	default:
+		return nil, frontend.ErrService
+	}
+}
+
+// WebhookDelete removes a webhook subscription of the tenant.
+func (s *service) WebhookDelete(ctx context.Context, tenantID, id uuid.UUID) error {
+	err := s.whClient.Unsubscribe(ctx, tenantID, id)
+
+	switch err {
+	case nil:
+		return nil
+	case webhook.ErrNotFound:
+		return frontend.ErrWebhookNotFound
+	default:
+		return frontend.ErrService
+	}
+}
+
+// WebhookList returns the webhook subscriptions of the tenant.
+func (s *service) WebhookList(ctx context.Context, tenantID uuid.UUID) ([]*frontend.Webhook, error) {
+	subscriptions, err := s.whClient.Subscriptions(ctx, tenantID)
+	if err != nil {
+		return nil, frontend.ErrService
+	}
+	webhooks := make([]*frontend.Webhook, 0, len(subscriptions))
+	for _, sub := range subscriptions {
+		webhooks = append(webhooks, &frontend.Webhook{
+			ID:    sub.ID,
+			URL:   sub.URL,
+			Types: sub.Types,
+		})
+	}
+	return webhooks, nil
+}
+
+func toEvent(evt frontend.Event) event.Event {
+	return event.Event{
+		ID:       evt.ID,
+		Name:     evt.Name,
+		Start:    evt.Start,
+		End:      evt.End,
+		Timezone: evt.Timezone,
+	}
+}
+
+func fromEvent(evt *event.Event) *frontend.Event {
+	return &frontend.Event{
+		ID:       evt.ID,
+		Name:     evt.Name,
+		Start:    evt.Start,
+		End:      evt.End,
+		Timezone: evt.Timezone,
+	}
+}
@@ -0,0 +1,72 @@
+package implementation
+
+import (
+	// stdlib
+	"context"
+
+	// external
+	"github.com/kevinburke/go.uuid"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/services/event"
+	"github.com/basvanbeek/opencensus-gokit-example/services/frontend"
+)
+
+// EventTemplateCreate creates a recurring event template. Its instances are
+// materialized as regular events by the event service.
+func (s *service) EventTemplateCreate(ctx context.Context, tenantID uuid.UUID, template frontend.Template) (*uuid.UUID, error) {
+	id, err := s.evtClient.CreateTemplate(ctx, tenantID, event.Template{
+		Name:     template.Name,
+		RRule:    template.RRule,
+		Start:    template.Start,
+		End:      template.End,
+		Timezone: template.Timezone,
+	})
+
+	switch err {
+	case nil:
+		return id, nil
+	case event.ErrTemplateName:
+		return nil, frontend.ErrTemplateExists
+	case event.ErrRequireName:
+		return nil, frontend.ErrRequireEventName
+	case event.ErrInvalidRule:
+		return nil, frontend.ErrInvalidRule
+	case event.ErrInvalidDates:
+		return nil, frontend.ErrInvalidEventDates
+	case event.ErrInvalidZone:
+		return nil, frontend.ErrInvalidTimezone
+	default:
+		return nil, frontend.ErrService
+	}
+}
+
+func (s *service) EventTemplateList(ctx context.Context, tenantID uuid.UUID) ([]*frontend.Template, error) {
+	tpls, err := s.evtClient.ListTemplates(ctx, tenantID)
+	if err != nil {
+		return nil, frontend.ErrService
+	}
+	templates := make([]*frontend.Template, 0, len(tpls))
+	for _, t := range tpls {
+		templates = append(templates, &frontend.Template{
+			ID:                t.ID,
+			Name:              t.Name,
+			RRule:             t.RRule,
+			Start:             t.Start,
+			End:               t.End,
+			Timezone:          t.Timezone,
+			MaterializedUntil: t.MaterializedUntil,
+			Failure:           t.Failure,
+		})
+	}
+	return templates, nil
+}
+
+// EventTemplateDelete stops a template from creating new instances. Events
+// already materialized are kept.
+func (s *service) EventTemplateDelete(ctx context.Context, tenantID, templateID uuid.UUID) error {
+	if err := s.evtClient.DeleteTemplate(ctx, tenantID, templateID); err != nil {
+		return frontend.ErrService
+	}
+	return nil
+}
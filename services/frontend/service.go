@@ -3,10 +3,13 @@ package frontend
 import (
 	// stdlib
 	"context"
-	"errors"
+	"time"
 
 	// external
 	"github.com/kevinburke/go.uuid"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/shared/errcode"
 )
 
 // ServiceName of this service.
@@ -21,10 +24,23 @@ type Service interface {
 	EventUpdate(ctx context.Context, tenantID uuid.UUID, event Event) error
 	EventDelete(ctx context.Context, tenantID, eventID uuid.UUID) error
 	EventList(ctx context.Context, tenantID uuid.UUID) ([]*Event, error)
+	EventImport(ctx context.Context, tenantID uuid.UUID, events []Event, dryRun bool) (*ImportResult, error)
+	EventExport(ctx context.Context, tenantID, after uuid.UUID, limit int) ([]*Event, error)
+	EventCalendarToken(ctx context.Context, tenantID uuid.UUID) (string, error)
+	EventCalendar(ctx context.Context, token string) ([]byte, error)
+	EventClone(ctx context.Context, tenantID, eventID uuid.UUID, clone Clone) (*uuid.UUID, error)
+
+	EventTemplateCreate(ctx context.Context, tenantID uuid.UUID, template Template) (*uuid.UUID, error)
+	EventTemplateList(ctx context.Context, tenantID uuid.UUID) ([]*Template, error)
+	EventTemplateDelete(ctx context.Context, tenantID, templateID uuid.UUID) error
 
 	UnlockDevice(ctx context.Context, eventID, deviceID uuid.UUID, unlockCode string) (*Session, error)
 
 	GenerateQR(ctx context.Context, eventID, deviceID uuid.UUID, unlockCode string) ([]byte, error)
+
+	WebhookCreate(ctx context.Context, tenantID uuid.UUID, webhook Webhook) (*uuid.UUID, error)
+	WebhookDelete(ctx context.Context, tenantID, webhookID uuid.UUID) error
+	WebhookList(ctx context.Context, tenantID uuid.UUID) ([]*Webhook, error)
 }
 
 // Frontend Service Error descriptions
@@ -41,23 +57,50 @@ const (
 	ErrorUnlockNotFound    = "device / unlock code combination not found"
 	ErrorInvalidQRParams   = "QR Code can't be generated using provided parameters"
 	ErrorQRGenerate        = "QR Code generator failed"
+	ErrorInvalidWebhook    = "invalid webhook subscription"
+	ErrorWebhookNotFound   = "webhook not found"
+	ErrorRequireEventName  = "missing required event name"
+	ErrorInvalidImport     = "invalid event import payload"
+	ErrorImportSize        = "too many events in a single import"
+	ErrorInvalidEventDates = "invalid event start and end dates"
+	ErrorInvalidTimezone   = "unknown event time zone"
+	ErrorCalendarNotFound  = "calendar not found"
+	ErrorInvalidRule       = "invalid recurrence rule"
+	ErrorTemplateExists    = "event template already exists"
+	ErrorQuotaExceeded     = "quota exceeded"
 )
 
 // Frontend Service Errors
 var (
-	ErrService           = errors.New(ErrorService)
-	ErrUnauthorized      = errors.New(ErrorUnauthorized)
-	ErrUserPassRequired  = errors.New(ErrorUserPassRequired)
-	ErrUserPassUnknown   = errors.New(ErrorUserPassUnknown)
-	ErrRequireEventID    = errors.New(ErrorRequireEventID)
-	ErrRequireDeviceID   = errors.New(ErrorRequireDeviceID)
-	ErrRequireUnlockCode = errors.New(ErrorRequireUnlockCode)
-	ErrEventNotFound     = errors.New(ErrorEventNotFound)
-	ErrEventExists       = errors.New(ErrorEventExists)
-	ErrUnlockNotFound    = errors.New(ErrorUnlockNotFound)
-
-	ErrInvalidQRParams = errors.New(ErrorInvalidQRParams)
-	ErrQRGenerate      = errors.New(ErrorQRGenerate)
+	ErrService           = errcode.New("frontend.service", errcode.Internal, ErrorService)
+	ErrUnauthorized      = errcode.New("frontend.unauthorized", errcode.Unauthenticated, ErrorUnauthorized)
+	ErrUserPassRequired  = errcode.New("frontend.user_pass_required", errcode.InvalidArgument, ErrorUserPassRequired)
+	ErrUserPassUnknown   = errcode.New("frontend.user_pass_unknown", errcode.Unauthenticated, ErrorUserPassUnknown)
+	ErrRequireEventID    = errcode.New("frontend.require_event_id", errcode.InvalidArgument, ErrorRequireEventID)
+	ErrRequireDeviceID   = errcode.New("frontend.require_device_id", errcode.InvalidArgument, ErrorRequireDeviceID)
+	ErrRequireUnlockCode = errcode.New("frontend.require_unlock_code", errcode.InvalidArgument, ErrorRequireUnlockCode)
+	ErrEventNotFound     = errcode.New("frontend.event_not_found", errcode.NotFound, ErrorEventNotFound)
+	ErrEventExists       = errcode.New("frontend.event_exists", errcode.AlreadyExists, ErrorEventExists)
+	ErrUnlockNotFound    = errcode.New("frontend.unlock_not_found", errcode.Unauthenticated, ErrorUnlockNotFound)
+
+	ErrInvalidQRParams = errcode.New("frontend.invalid_qr_params", errcode.InvalidArgument, ErrorInvalidQRParams)
+	ErrQRGenerate      = errcode.New("frontend.qr_generate", errcode.Unavailable, ErrorQRGenerate)
+
+	ErrInvalidWebhook  = errcode.New("frontend.invalid_webhook", errcode.InvalidArgument, ErrorInvalidWebhook)
+	ErrWebhookNotFound = errcode.New("frontend.webhook_not_found", errcode.NotFound, ErrorWebhookNotFound)
+
+	ErrRequireEventName = errcode.New("frontend.require_event_name", errcode.InvalidArgument, ErrorRequireEventName)
+	ErrInvalidImport    = errcode.New("frontend.invalid_import", errcode.InvalidArgument, ErrorInvalidImport)
+	ErrImportSize       = errcode.New("frontend.import_size", errcode.InvalidArgument, ErrorImportSize)
+
+	ErrInvalidEventDates = errcode.New("frontend.invalid_event_dates", errcode.InvalidArgument, ErrorInvalidEventDates)
+	ErrInvalidTimezone   = errcode.New("frontend.invalid_timezone", errcode.InvalidArgument, ErrorInvalidTimezone)
+	ErrCalendarNotFound  = errcode.New("frontend.calendar_not_found", errcode.NotFound, ErrorCalendarNotFound)
+
+	ErrInvalidRule    = errcode.New("frontend.invalid_rule", errcode.InvalidArgument, ErrorInvalidRule)
+	ErrTemplateExists = errcode.New("frontend.template_exists", errcode.AlreadyExists, ErrorTemplateExists)
+
+	ErrQuotaExceeded = errcode.New("frontend.quota_exceeded", errcode.ResourceExhausted, ErrorQuotaExceeded)
 )
 
 // Login holds login details
@@ -68,10 +111,55 @@ type Login struct {
 	TenantName string
 }
 
-// Event holds event details
+// Event holds event details. Start and End are optional, Timezone holds the
+// IANA time zone name the event takes place in.
 type Event struct {
-	ID   uuid.UUID `json:"id"`
-	Name string    `json:"name"`
+	ID       uuid.UUID `json:"id"`
+	Name     string    `json:"name"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	Timezone string    `json:"timezone,omitempty"`
+}
+
+// Clone holds the details of an event clone. A zero Start keeps the dates of
+// the source event, otherwise the clone is moved to Start keeping the
+// duration of the source event. WithDevices also clones the event's devices
+// including their unlock codes.
+type Clone struct {
+	Name        string    `json:"name"`
+	Start       time.Time `json:"start"`
+	WithDevices bool      `json:"with_devices"`
+}
+
+// Template holds the details of a recurring event template. Start, End and
+// Timezone describe the first instance, RRule holds an RFC 5545 style
+// recurrence rule such as "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10". Failure holds
+// why the template's last instances could not be created, e.g. an instance
+// name taken by another event.
+type Template struct {
+	ID                uuid.UUID `json:"id"`
+	Name              string    `json:"name"`
+	RRule             string    `json:"rrule"`
+	Start             time.Time `json:"start"`
+	End               time.Time `json:"end"`
+	Timezone          string    `json:"timezone,omitempty"`
+	MaterializedUntil time.Time `json:"materialized_until"`
+	Failure           string    `json:"failure,omitempty"`
+}
+
+// ImportResult holds the outcome of an event import. Imports are all or
+// nothing: if any row has an error no events are created.
+type ImportResult struct {
+	Rows    int           `json:"rows"`
+	Created []uuid.UUID   `json:"created,omitempty"`
+	Errors  []ImportError `json:"errors,omitempty"`
+}
+
+// ImportError holds the error of a single import row. Rows are numbered
+// starting at 1.
+type ImportError struct {
+	Row   int    `json:"row"`
+	Error string `json:"error"`
 }
 
 // Session holds session details
@@ -82,3 +170,11 @@ type Session struct {
 	DeviceCaption string    `json:"device_caption,omitempty"`
 	Token         string    `json:"token,omitempty"`
 }
+
+// Webhook holds webhook subscription details
+type Webhook struct {
+	ID     uuid.UUID `json:"id"`
+	URL    string    `json:"url"`
+	Secret string    `json:"secret,omitempty"`
+	Types  []string  `json:"types"`
+}
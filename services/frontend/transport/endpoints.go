@@ -19,8 +19,22 @@ type Endpoints struct {
 	EventUpdate  endpoint.Endpoint
 	EventDelete  endpoint.Endpoint
 	EventList    endpoint.Endpoint
+	EventImport  endpoint.Endpoint
+	EventExport  endpoint.Endpoint
 	UnlockDevice endpoint.Endpoint
 	GenerateQR   endpoint.Endpoint
+
+	EventCalendarToken endpoint.Endpoint
+	EventCalendar      endpoint.Endpoint
+	EventClone         endpoint.Endpoint
+
+	EventTemplateCreate endpoint.Endpoint
+	EventTemplateList   endpoint.Endpoint
+	EventTemplateDelete endpoint.Endpoint
+
+	WebhookCreate endpoint.Endpoint
+	WebhookDelete endpoint.Endpoint
+	WebhookList   endpoint.Endpoint
 }
 
 // MakeEndpoints initializes all Go kit endpoints for the service.
@@ -32,8 +46,22 @@ func MakeEndpoints(s frontend.Service) Endpoints {
 		EventUpdate:  makeEventUpdateEndpoint(s),
 		EventDelete:  makeEventDeleteEndpoint(s),
 		EventList:    makeEventListEndpoint(s),
+		EventImport:  makeEventImportEndpoint(s),
+		EventExport:  makeEventExportEndpoint(s),
 		UnlockDevice: makeUnlockDeviceEndpoint(s),
 		GenerateQR:   makeGenerateQREndpoint(s),
+
+		EventCalendarToken: makeEventCalendarTokenEndpoint(s),
+		EventCalendar:      makeEventCalendarEndpoint(s),
+		EventClone:         makeEventCloneEndpoint(s),
+
+		EventTemplateCreate: makeEventTemplateCreateEndpoint(s),
+		EventTemplateList:   makeEventTemplateListEndpoint(s),
+		EventTemplateDelete: makeEventTemplateDeleteEndpoint(s),
+
+		WebhookCreate: makeWebhookCreateEndpoint(s),
+		WebhookDelete: makeWebhookDeleteEndpoint(s),
+		WebhookList:   makeWebhookListEndpoint(s),
 	}
 }
 
@@ -93,6 +121,22 @@ func makeEventListEndpoint(s frontend.Service) endpoint.Endpoint {
 	}
 }
 
+func makeEventImportEndpoint(s frontend.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(EventImportRequest)
+		result, err := s.EventImport(ctx, req.TenantID, req.Events, req.DryRun)
+		return EventImportResponse{Result: result, Err: err}, nil
+	}
+}
+
+func makeEventExportEndpoint(s frontend.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(EventExportRequest)
+		events, err := s.EventExport(ctx, req.TenantID, req.After, req.Limit)
+		return EventExportResponse{Events: events, Err: err}, nil
+	}
+}
+
 func makeUnlockDeviceEndpoint(s frontend.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(UnlockDeviceRequest)
@@ -108,3 +152,75 @@ func makeGenerateQREndpoint(s frontend.Service) endpoint.Endpoint {
 		return GenerateQRResponse{QR: qr, Err: err}, nil
 	}
 }
+
+func makeEventCalendarTokenEndpoint(s frontend.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(EventCalendarTokenRequest)
+		token, err := s.EventCalendarToken(ctx, req.TenantID)
+		return EventCalendarTokenResponse{Token: token, Err: err}, nil
+	}
+}
+
+func makeEventCalendarEndpoint(s frontend.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(EventCalendarRequest)
+		calendar, err := s.EventCalendar(ctx, req.Token)
+		return EventCalendarResponse{Calendar: calendar, Err: err}, nil
+	}
+}
+
+func makeEventCloneEndpoint(s frontend.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(EventCloneRequest)
+		eventID, err := s.EventClone(ctx, req.TenantID, req.EventID, req.Clone)
+		return EventCloneResponse{EventID: eventID, Err: err}, nil
+	}
+}
+
+func makeEventTemplateCreateEndpoint(s frontend.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(EventTemplateCreateRequest)
+		templateID, err := s.EventTemplateCreate(ctx, req.TenantID, req.Template)
+		return EventTemplateCreateResponse{TemplateID: templateID, Err: err}, nil
+	}
+}
+
+func makeEventTemplateListEndpoint(s frontend.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(EventTemplateListRequest)
+		templates, err := s.EventTemplateList(ctx, req.TenantID)
+		return EventTemplateListResponse{Templates: templates, Err: err}, nil
+	}
+}
+
+func makeEventTemplateDeleteEndpoint(s frontend.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(EventTemplateDeleteRequest)
+		err := s.EventTemplateDelete(ctx, req.TenantID, req.TemplateID)
+		return EventTemplateDeleteResponse{Err: err}, nil
+	}
+}
+
+func makeWebhookCreateEndpoint(s frontend.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(WebhookCreateRequest)
+		webhookID, err := s.WebhookCreate(ctx, req.TenantID, req.Webhook)
+		return WebhookCreateResponse{WebhookID: webhookID, Err: err}, nil
+	}
+}
+
+func makeWebhookDeleteEndpoint(s frontend.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(WebhookDeleteRequest)
+		err := s.WebhookDelete(ctx, req.TenantID, req.WebhookID)
+		return WebhookDeleteResponse{Err: err}, nil
+	}
+}
+
+func makeWebhookListEndpoint(s frontend.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(WebhookListRequest)
+		webhooks, err := s.WebhookList(ctx, req.TenantID)
+		return WebhookListResponse{Webhooks: webhooks, Err: err}, nil
+	}
+}
@@ -0,0 +1,280 @@
+// Package openapi generates the OpenAPI 3 document of our frontend HTTP API
+// from its route definitions and the transport request and response payloads.
+// Generation fails if routes and the documented operations drift apart.
+package openapi
+
+import (
+	// stdlib
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	// external
+	"github.com/gorilla/mux"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/services/frontend"
+	"github.com/basvanbeek/opencensus-gokit-example/services/frontend/transport/http/routes"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/problem"
+)
+
+// Version of the OpenAPI specification our documents adhere to.
+const Version = "3.0.3"
+
+// Document is the root of an OpenAPI document.
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+// Info holds the API metadata.
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// PathItem holds the operations of a path keyed by lower case HTTP method.
+type PathItem map[string]*Operation
+
+// Operation describes a single API operation on a path.
+type Operation struct {
+	OperationID string              `json:"operationId"`
+	Summary     string              `json:"summary,omitempty"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+}
+
+// Parameter describes a single path or query parameter.
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody describes the payload of a request.
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response describes a single response of an operation.
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType holds the schema of a payload.
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components holds the schemas referenced by the operations.
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// Schema describes a payload or a parameter value.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+}
+
+// pathVar matches the variables of mux path templates.
+var pathVar = regexp.MustCompile(`\{([^}:]+)(?::[^}]*)?\}`)
+
+// Spec returns the OpenAPI document of our frontend HTTP API.
+func Spec() (*Document, error) {
+	router := mux.NewRouter()
+	routes.Initialize(router)
+	return Generate(router)
+}
+
+// Generate returns the OpenAPI document for the routes of router. Each route
+// must have a documented operation and the route variables must match the
+// documented parameters.
+func Generate(router *mux.Router) (*Document, error) {
+	g := newGenerator()
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    Info{Title: frontend.ServiceName, Version: "1.0"},
+		Paths:   make(map[string]PathItem),
+	}
+
+	documented := make(map[string]bool)
+	err := router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		name := route.GetName()
+		op, ok := operations[name]
+		if !ok {
+			return fmt.Errorf("route %q has no documented operation", name)
+		}
+		documented[name] = true
+
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return fmt.Errorf("route %q: %v", name, err)
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return fmt.Errorf("route %q: %v", name, err)
+		}
+		queries, _ := route.GetQueriesTemplates()
+
+		params, err := g.parameters(op, path, queries)
+		if err != nil {
+			return fmt.Errorf("route %q: %v", name, err)
+		}
+
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = make(PathItem)
+			doc.Paths[path] = item
+		}
+		for _, method := range methods {
+			method = strings.ToLower(method)
+			if _, ok := item[method]; ok {
+				return fmt.Errorf("route %q: duplicate %s %s", name, method, path)
+			}
+			id := name
+			if len(methods) > 1 {
+				id += "_" + method
+			}
+			item[method] = &Operation{
+				OperationID: id,
+				Summary:     op.summary,
+				Parameters:  params,
+				RequestBody: g.requestBody(op),
+				Responses:   g.responses(op),
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for name := range operations {
+		if !documented[name] {
+			return nil, fmt.Errorf("operation %q has no route", name)
+		}
+	}
+	if err = g.err(); err != nil {
+		return nil, err
+	}
+
+	doc.Components.Schemas = g.schemas
+	return doc, nil
+}
+
+// Marshal returns the JSON encoding of doc as served by Handler.
+func Marshal(doc *Document) ([]byte, error) {
+	b, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+// Handler serves our OpenAPI document.
+func Handler() http.Handler {
+	doc, err := Spec()
+	var b []byte
+	if err == nil {
+		b, err = Marshal(doc)
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err != nil {
+			problem.Error(w, r, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Write(b)
+	})
+}
+
+// parameters returns the documented parameters of op, checking them against
+// the variables of the route path and queries.
+func (g *generator) parameters(
+	op operation, path string, queries []string,
+) ([]Parameter, error) {
+	routeVars := make(map[string]string)
+	for _, m := range pathVar.FindAllStringSubmatch(path, -1) {
+		routeVars[m[1]] = "path"
+	}
+	for _, q := range queries {
+		routeVars[strings.SplitN(q, "=", 2)[0]] = "query"
+	}
+
+	params := make([]Parameter, 0, len(op.params))
+	for _, p := range op.params {
+		in, ok := routeVars[p.name]
+		if p.in == "path" && !ok {
+			return nil, fmt.Errorf("path parameter %q not found in %s", p.name, path)
+		}
+		if ok && in != p.in {
+			return nil, fmt.Errorf("parameter %q is a %s parameter", p.name, in)
+		}
+		delete(routeVars, p.name)
+		schema := g.schema(p.value)
+		schema.Enum = p.enum
+		params = append(params, Parameter{
+			Name:        p.name,
+			In:          p.in,
+			Description: p.description,
+			Required:    ok || p.required,
+			Schema:      schema,
+		})
+	}
+
+	if len(routeVars) > 0 {
+		missing := make([]string, 0, len(routeVars))
+		for name := range routeVars {
+			missing = append(missing, name)
+		}
+		sort.Strings(missing)
+		return nil, fmt.Errorf("undocumented parameters %v", missing)
+	}
+	return params, nil
+}
+
+func (g *generator) requestBody(op operation) *RequestBody {
+	if len(op.request) == 0 {
+		return nil
+	}
+	return &RequestBody{Required: true, Content: g.content(op.request)}
+}
+
+func (g *generator) responses(op operation) map[string]Response {
+	return map[string]Response{
+		"200": {Description: "OK", Content: g.content(op.response)},
+		"default": {
+			Description: "Error",
+			Content: map[string]MediaType{
+				problem.ContentType: {Schema: g.schema(problem.Problem{})},
+			},
+		},
+	}
+}
+
+func (g *generator) content(payloads map[string]interface{}) map[string]MediaType {
+	if len(payloads) == 0 {
+		return nil
+	}
+	content := make(map[string]MediaType, len(payloads))
+	for mediaType, value := range payloads {
+		content[mediaType] = MediaType{Schema: g.schema(value)}
+	}
+	return content
+}
@@ -0,0 +1,195 @@
+package openapi
+
+import (
+	// external
+	"github.com/kevinburke/go.uuid"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/services/frontend/bulk"
+	"github.com/basvanbeek/opencensus-gokit-example/services/frontend/transport"
+)
+
+// media types of our payloads
+const (
+	mediaJSON     = "application/json"
+	mediaCSV      = "text/csv"
+	mediaJSONL    = "application/x-ndjson"
+	mediaPNG      = "image/png"
+	mediaCalendar = "text/calendar"
+)
+
+// operation documents the route of the same name. Payloads are keyed by media
+// type and hold a value of the type the transport decodes or encodes.
+type operation struct {
+	summary  string
+	params   []param
+	request  map[string]interface{}
+	response map[string]interface{}
+}
+
+// param documents a path or query parameter. Route variables are required,
+// other query parameters only if required is set.
+type param struct {
+	name        string
+	in          string
+	description string
+	required    bool
+	value       interface{}
+	enum        []string
+}
+
+var (
+	tenantQuery = param{
+		name: "tenant_id", in: "query", required: true, value: uuid.UUID{},
+	}
+	formatQuery = param{
+		name: "format", in: "query", description: "bulk format, defaults to jsonl",
+		value: bulk.Format(""), enum: []string{string(bulk.JSONL), string(bulk.CSV)},
+	}
+	eventPath    = param{name: "event_id", in: "path", value: uuid.UUID{}}
+	devicePath   = param{name: "device_id", in: "path", value: uuid.UUID{}}
+	templatePath = param{name: "template_id", in: "path", value: uuid.UUID{}}
+	webhookPath  = param{name: "webhook_id", in: "path", value: uuid.UUID{}}
+	unlockQuery  = param{name: "code", in: "query", description: "unlock code", value: ""}
+)
+
+func jsonPayload(value interface{}) map[string]interface{} {
+	return map[string]interface{}{mediaJSON: value}
+}
+
+// operations holds the documentation of our routes keyed by route name.
+var operations = map[string]operation{
+	"login": {
+		summary:  "Log in a user",
+		request:  jsonPayload(transport.LoginRequest{}),
+		response: jsonPayload(transport.LoginResponse{}),
+	},
+	"event_create": {
+		summary:  "Create an event",
+		request:  jsonPayload(transport.EventCreateRequest{}),
+		response: jsonPayload(transport.EventCreateResponse{}),
+	},
+	"event_get": {
+		summary:  "Get an event",
+		params:   []param{eventPath},
+		request:  jsonPayload(transport.EventGetRequest{}),
+		response: jsonPayload(transport.EventGetResponse{}),
+	},
+	"event_update": {
+		summary:  "Update an event",
+		params:   []param{eventPath},
+		request:  jsonPayload(transport.EventUpdateRequest{}),
+		response: jsonPayload(transport.EventUpdateResponse{}),
+	},
+	"event_delete": {
+		summary:  "Delete an event",
+		params:   []param{eventPath},
+		request:  jsonPayload(transport.EventDeleteRequest{}),
+		response: jsonPayload(transport.EventDeleteResponse{}),
+	},
+	"event_list": {
+		summary:  "List the events of a tenant",
+		request:  jsonPayload(transport.EventListRequest{}),
+		response: jsonPayload(transport.EventListResponse{}),
+	},
+	"event_import": {
+		summary: "Import events in bulk",
+		params: []param{
+			tenantQuery,
+			formatQuery,
+			{
+				name: "dry_run", in: "query", value: false,
+				description: "validate the events without creating them",
+			},
+		},
+		request: map[string]interface{}{
+			mediaJSONL: binary{},
+			mediaCSV:   binary{},
+		},
+		response: jsonPayload(transport.EventImportResponse{}),
+	},
+	"event_export": {
+		summary: "Export the events of a tenant in bulk, ordered by id",
+		params: []param{
+			tenantQuery,
+			formatQuery,
+			{
+				name: "after", in: "query", value: uuid.UUID{},
+				description: "resume the export after the event with this id",
+			},
+			{
+				name: "limit", in: "query", value: 0,
+				description: "maximum amount of events to export, defaults to all",
+			},
+		},
+		response: map[string]interface{}{
+			mediaJSONL: binary{},
+			mediaCSV:   binary{},
+		},
+	},
+	"event_calendar_token": {
+		summary:  "Get the calendar feed token of a tenant",
+		request:  jsonPayload(transport.EventCalendarTokenRequest{}),
+		response: jsonPayload(transport.EventCalendarTokenResponse{}),
+	},
+	"event_calendar": {
+		summary: "Get the iCalendar feed of a tenant",
+		params: []param{
+			{name: "token", in: "query", description: "calendar feed token", value: ""},
+		},
+		response: map[string]interface{}{mediaCalendar: binary{}},
+	},
+	"event_clone": {
+		summary:  "Clone an event",
+		params:   []param{eventPath},
+		request:  jsonPayload(transport.EventCloneRequest{}),
+		response: jsonPayload(transport.EventCloneResponse{}),
+	},
+	"event_template_create": {
+		summary:  "Create a recurring event template",
+		request:  jsonPayload(transport.EventTemplateCreateRequest{}),
+		response: jsonPayload(transport.EventTemplateCreateResponse{}),
+	},
+	"event_template_list": {
+		summary:  "List the event templates of a tenant",
+		request:  jsonPayload(transport.EventTemplateListRequest{}),
+		response: jsonPayload(transport.EventTemplateListResponse{}),
+	},
+	"event_template_delete": {
+		summary:  "Delete an event template",
+		params:   []param{templatePath},
+		request:  jsonPayload(transport.EventTemplateDeleteRequest{}),
+		response: jsonPayload(transport.EventTemplateDeleteResponse{}),
+	},
+	"unlock_device": {
+		summary:  "Unlock a device for an event",
+		params:   []param{eventPath, devicePath, unlockQuery},
+		request:  jsonPayload(transport.UnlockDeviceRequest{}),
+		response: jsonPayload(transport.UnlockDeviceResponse{}),
+	},
+	"generate_qr": {
+		summary:  "Generate the unlock QR code of a device",
+		params:   []param{eventPath, devicePath, unlockQuery},
+		response: map[string]interface{}{mediaPNG: binary{}},
+	},
+	"webhook_create": {
+		summary:  "Register a webhook",
+		request:  jsonPayload(transport.WebhookCreateRequest{}),
+		response: jsonPayload(transport.WebhookCreateResponse{}),
+	},
+	"webhook_delete": {
+		summary:  "Delete a webhook",
+		params:   []param{webhookPath},
+		request:  jsonPayload(transport.WebhookDeleteRequest{}),
+		response: jsonPayload(transport.WebhookDeleteResponse{}),
+	},
+	"webhook_list": {
+		summary:  "List the webhooks of a tenant",
+		request:  jsonPayload(transport.WebhookListRequest{}),
+		response: jsonPayload(transport.WebhookListResponse{}),
+	},
+	"openapi": {
+		summary:  "Get this OpenAPI document",
+		response: jsonPayload(map[string]interface{}{}),
+	},
+}
@@ -0,0 +1,167 @@
+package openapi
+
+import (
+	// stdlib
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	// external
+	"github.com/kevinburke/go.uuid"
+)
+
+// binary documents payloads which are not JSON encoded, e.g. images.
+type binary struct{}
+
+var (
+	binaryType        = reflect.TypeOf(binary{})
+	timeType          = reflect.TypeOf(time.Time{})
+	uuidType          = reflect.TypeOf(uuid.UUID{})
+	errorType         = reflect.TypeOf((*error)(nil)).Elem()
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// generator derives schemas from Go values following the encoding/json rules.
+// Named struct types end up in the schema components.
+type generator struct {
+	schemas map[string]*Schema
+	types   map[string]reflect.Type
+	errs    []string
+}
+
+func newGenerator() *generator {
+	return &generator{
+		schemas: make(map[string]*Schema),
+		types:   make(map[string]reflect.Type),
+	}
+}
+
+// err returns the problems found while generating schemas.
+func (g *generator) err() error {
+	if len(g.errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("schemas: %s", strings.Join(g.errs, ", "))
+}
+
+// schema returns the schema of the type of value.
+func (g *generator) schema(value interface{}) *Schema {
+	return g.schemaOf(reflect.TypeOf(value))
+}
+
+func (g *generator) schemaOf(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t {
+	case binaryType:
+		return &Schema{Type: "string", Format: "binary"}
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case uuidType:
+		return &Schema{Type: "string", Format: "uuid"}
+	}
+	if implements(t, jsonMarshalerType) {
+		// custom encodings can't be derived, allow any value
+		return &Schema{}
+	}
+	if implements(t, textMarshalerType) {
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		return g.ref(t)
+	case reflect.Interface:
+		return &Schema{}
+	default:
+		g.errs = append(g.errs, fmt.Sprintf("unsupported type %s", t))
+		return &Schema{}
+	}
+}
+
+// ref adds the schema of the named struct type t to the components and returns
+// a reference to it.
+func (g *generator) ref(t reflect.Type) *Schema {
+	name := t.Name()
+	if seen, ok := g.types[name]; !ok {
+		g.types[name] = t
+		g.schemas[name] = nil // guards against recursive types
+		g.schemas[name] = g.object(t)
+	} else if seen != t {
+		g.errs = append(g.errs, fmt.Sprintf(
+			"schema name %s used by %s and %s", name, seen, t,
+		))
+	}
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// object returns the schema of struct type t.
+func (g *generator) object(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	g.fields(t, s)
+	return s
+}
+
+func (g *generator) fields(t reflect.Type, s *Schema) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" || (f.PkgPath != "" && !f.Anonymous) {
+			continue
+		}
+		// service errors are carried by problem responses, on success they
+		// always hold nil
+		if f.Type == errorType {
+			continue
+		}
+
+		name := strings.Split(tag, ",")[0]
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.fields(ft, s)
+				continue
+			}
+		}
+		if name == "" {
+			name = f.Name
+		}
+		s.Properties[name] = g.schemaOf(f.Type)
+	}
+}
+
+func implements(t, iface reflect.Type) bool {
+	return t.Implements(iface) || reflect.PtrTo(t).Implements(iface)
+}
@@ -0,0 +1,315 @@
+package http
+
+import (
+	// stdlib
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	// external
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+	"github.com/kevinburke/go.uuid"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/services/frontend"
+	"github.com/basvanbeek/opencensus-gokit-example/services/frontend/transport"
+	"github.com/basvanbeek/opencensus-gokit-example/services/frontend/transport/http/openapi"
+)
+
+var update = flag.Bool("update", false, "update the committed OpenAPI document")
+
+// committedSpec is the OpenAPI document we commit for client teams.
+const committedSpec = "openapi/openapi.json"
+
+var errStub = errors.New("stub")
+
+// stubService fails all calls, so our endpoints return their response
+// payloads without us having to construct them.
+type stubService struct{}
+
+func (stubService) Login(context.Context, string, string) (*frontend.Login, error) {
+	return nil, errStub
+}
+
+func (stubService) EventCreate(context.Context, uuid.UUID, frontend.Event) (*uuid.UUID, error) {
+	return nil, errStub
+}
+
+func (stubService) EventGet(context.Context, uuid.UUID, uuid.UUID) (*frontend.Event, error) {
+	return nil, errStub
+}
+
+func (stubService) EventUpdate(context.Context, uuid.UUID, frontend.Event) error {
+	return errStub
+}
+
+func (stubService) EventDelete(context.Context, uuid.UUID, uuid.UUID) error {
+	return errStub
+}
+
+func (stubService) EventList(context.Context, uuid.UUID) ([]*frontend.Event, error) {
+	return nil, errStub
+}
+
+func (stubService) EventImport(context.Context, uuid.UUID, []frontend.Event, bool) (*frontend.ImportResult, error) {
+	return nil, errStub
+}
+
+func (stubService) EventExport(context.Context, uuid.UUID, uuid.UUID, int) ([]*frontend.Event, error) {
+	return nil, errStub
+}
+
+func (stubService) EventCalendarToken(context.Context, uuid.UUID) (string, error) {
+	return "", errStub
+}
+
+func (stubService) EventCalendar(context.Context, string) ([]byte, error) {
+	return nil, errStub
+}
+
+func (stubService) EventClone(context.Context, uuid.UUID, uuid.UUID, frontend.Clone) (*uuid.UUID, error) {
+	return nil, errStub
+}
+
+func (stubService) EventTemplateCreate(context.Context, uuid.UUID, frontend.Template) (*uuid.UUID, error) {
+	return nil, errStub
+}
+
+func (stubService) EventTemplateList(context.Context, uuid.UUID) ([]*frontend.Template, error) {
+	return nil, errStub
+}
+
+func (stubService) EventTemplateDelete(context.Context, uuid.UUID, uuid.UUID) error {
+	return errStub
+}
+
+func (stubService) UnlockDevice(context.Context, uuid.UUID, uuid.UUID, string) (*frontend.Session, error) {
+	return nil, errStub
+}
+
+func (stubService) GenerateQR(context.Context, uuid.UUID, uuid.UUID, string) ([]byte, error) {
+	return nil, errStub
+}
+
+func (stubService) WebhookCreate(context.Context, uuid.UUID, frontend.Webhook) (*uuid.UUID, error) {
+	return nil, errStub
+}
+
+func (stubService) WebhookDelete(context.Context, uuid.UUID, uuid.UUID) error {
+	return errStub
+}
+
+func (stubService) WebhookList(context.Context, uuid.UUID) ([]*frontend.Webhook, error) {
+	return nil, errStub
+}
+
+// recorder holds the payloads of the last endpoint called.
+type recorder struct {
+	request, response interface{}
+}
+
+// endpoints returns our endpoints backed by stubService, recording their
+// payloads in r.
+func (r *recorder) endpoints() transport.Endpoints {
+	endpoints := transport.MakeEndpoints(stubService{})
+	v := reflect.ValueOf(&endpoints).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		next := v.Field(i).Interface().(endpoint.Endpoint)
+		v.Field(i).Set(reflect.ValueOf(endpoint.Endpoint(
+			func(ctx context.Context, request interface{}) (interface{}, error) {
+				r.request = request
+				response, err := next(ctx, request)
+				r.response = response
+				return response, err
+			},
+		)))
+	}
+	return endpoints
+}
+
+// samples holds request bodies our decoders accept, keyed by media type.
+var samples = map[string]string{
+	"application/json":     `{}`,
+	"application/x-ndjson": `{"name":"event"}` + "\n",
+	"text/csv":             "name\nevent\n",
+}
+
+var pathVar = regexp.MustCompile(`\{([^}:]+)(?::[^}]*)?\}`)
+
+// TestOpenAPIDocument checks the committed and served OpenAPI documents match
+// the routes wired by NewService. Run with -update to update the committed
+// document.
+func TestOpenAPIDocument(t *testing.T) {
+	handler := NewService((&recorder{}).endpoints(), nil, log.NewNopLogger())
+	router := handler.(*mux.Router)
+
+	err := router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		if route.GetHandler() == nil {
+			t.Errorf("route %q has no handler", route.GetName())
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doc, err := openapi.Generate(router)
+	if err != nil {
+		t.Fatal(err)
+	}
+	generated, err := openapi.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if *update {
+		if err = ioutil.WriteFile(committedSpec, generated, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	committed, err := ioutil.ReadFile(committedSpec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(committed, generated) {
+		t.Errorf("%s is out of date, run go test -update", committedSpec)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/openapi.json", nil))
+	if want, have := http.StatusOK, rec.Code; want != have {
+		t.Fatalf("want status %d, have %d", want, have)
+	}
+	if !bytes.Equal(generated, rec.Body.Bytes()) {
+		t.Error("served OpenAPI document differs from the routes")
+	}
+}
+
+// TestOpenAPIPayloads sends a request for each documented operation through
+// NewService and checks our decoders and endpoints use the documented request
+// and response payloads.
+func TestOpenAPIPayloads(t *testing.T) {
+	doc, err := openapi.Spec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &recorder{}
+	handler := NewService(r.endpoints(), nil, log.NewNopLogger())
+
+	var paths []string
+	for path := range doc.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		for method, op := range doc.Paths[path] {
+			if op.OperationID == "openapi" {
+				// served by TestOpenAPIDocument
+				continue
+			}
+			t.Run(op.OperationID, func(t *testing.T) {
+				*r = recorder{}
+				handler.ServeHTTP(httptest.NewRecorder(), request(method, path, op))
+
+				if r.request == nil {
+					t.Fatal("request did not reach the endpoint")
+				}
+				if op.RequestBody != nil {
+					if want, have := ref(op.RequestBody.Content), name(r.request); want != "" && want != have {
+						t.Errorf("want request %s, have %s", want, have)
+					}
+				}
+				if want, have := ref(op.Responses["200"].Content), name(r.response); want != "" && want != have {
+					t.Errorf("want response %s, have %s", want, have)
+				}
+			})
+		}
+	}
+}
+
+// request returns a request for op filling in all of its parameters.
+func request(method, path string, op *openapi.Operation) *http.Request {
+	values := make(map[string]string)
+	query := make(url.Values)
+	for _, param := range op.Parameters {
+		values[param.Name] = sample(param.Schema)
+		if param.In == "query" {
+			query.Set(param.Name, values[param.Name])
+		}
+	}
+	path = pathVar.ReplaceAllStringFunc(path, func(v string) string {
+		return values[pathVar.FindStringSubmatch(v)[1]]
+	})
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	var (
+		body      string
+		mediaType string
+	)
+	if op.RequestBody != nil {
+		// the first media type in order matches the first format enum value
+		var mediaTypes []string
+		for mediaType := range op.RequestBody.Content {
+			mediaTypes = append(mediaTypes, mediaType)
+		}
+		sort.Strings(mediaTypes)
+		mediaType, body = mediaTypes[0], samples[mediaTypes[0]]
+	}
+	req := httptest.NewRequest(strings.ToUpper(method), path, strings.NewReader(body))
+	if mediaType != "" {
+		req.Header.Set("Content-Type", mediaType)
+	}
+	return req
+}
+
+// sample returns a valid value for a parameter of schema.
+func sample(schema *openapi.Schema) string {
+	switch {
+	case len(schema.Enum) > 0:
+		return schema.Enum[0]
+	case schema.Format == "uuid":
+		return uuid.NewV4().String()
+	case schema.Type == "boolean":
+		return "false"
+	case schema.Type == "integer":
+		return "1"
+	default:
+		return "sample"
+	}
+}
+
+// ref returns the name of the schema documented for JSON payloads.
+func ref(content map[string]openapi.MediaType) string {
+	media, ok := content["application/json"]
+	if !ok || media.Schema == nil {
+		return ""
+	}
+	return strings.TrimPrefix(media.Schema.Ref, "#/components/schemas/")
+}
+
+// name returns the type name of payload.
+func name(payload interface{}) string {
+	t := reflect.TypeOf(payload)
+	if t == nil {
+		return ""
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Name()
+}
@@ -13,8 +13,24 @@ type Endpoints struct {
 	EventUpdate  *mux.Route
 	EventDelete  *mux.Route
 	EventList    *mux.Route
+	EventImport  *mux.Route
+	EventExport  *mux.Route
 	UnlockDevice *mux.Route
 	GenerateQR   *mux.Route
+
+	EventCalendarToken *mux.Route
+	EventCalendar      *mux.Route
+	EventClone         *mux.Route
+
+	EventTemplateCreate *mux.Route
+	EventTemplateList   *mux.Route
+	EventTemplateDelete *mux.Route
+
+	WebhookCreate *mux.Route
+	WebhookDelete *mux.Route
+	WebhookList   *mux.Route
+
+	OpenAPI *mux.Route
 }
 
 // Initialize wires the HTTP endpoints to our Go kit service endpoints.
@@ -28,6 +44,37 @@ func Initialize(router *mux.Router) Endpoints {
 			Methods("POST").
 			Path("/event").
 			Name("event_create"),
+		EventImport: router.
+			Methods("POST").
+			Path("/event/import").
+			Name("event_import"),
+		EventTemplateCreate: router.
+			Methods("POST").
+			Path("/event/template").
+			Name("event_template_create"),
+		EventTemplateDelete: router.
+			Methods("DELETE").
+			Path("/event/template/{template_id}").
+			Name("event_template_delete"),
+		// the following GET routes need to be registered before event_get or
+		// the latter will match
+		EventExport: router.
+			Methods("GET").
+			Path("/event/export").
+			Name("event_export"),
+		EventCalendar: router.
+			Methods("GET").
+			Path("/event/calendar.ics").
+			Queries("token", "{token}").
+			Name("event_calendar"),
+		EventCalendarToken: router.
+			Methods("GET").
+			Path("/event/calendar/token").
+			Name("event_calendar_token"),
+		EventTemplateList: router.
+			Methods("GET").
+			Path("/event/template").
+			Name("event_template_list"),
 		EventGet: router.
 			Methods("GET").
 			Path("/event/{event_id}").
@@ -40,6 +87,10 @@ func Initialize(router *mux.Router) Endpoints {
 			Methods("DELETE").
 			Path("/event/{event_id}").
 			Name("event_delete"),
+		EventClone: router.
+			Methods("POST").
+			Path("/event/{event_id}/clone").
+			Name("event_clone"),
 		EventList: router.
 			Methods("GET").
 			Path("/event").
@@ -54,5 +105,21 @@ func Initialize(router *mux.Router) Endpoints {
 			Path("/generate_qr/{event_id}/{device_id}").
 			Queries("code", "{code}").
 			Name("generate_qr"),
+		WebhookCreate: router.
+			Methods("POST").
+			Path("/webhook").
+			Name("webhook_create"),
+		WebhookDelete: router.
+			Methods("DELETE").
+			Path("/webhook/{webhook_id}").
+			Name("webhook_delete"),
+		WebhookList: router.
+			Methods("GET").
+			Path("/webhook").
+			Name("webhook_list"),
+		OpenAPI: router.
+			Methods("GET").
+			Path("/openapi.json").
+			Name("openapi"),
 	}
 }
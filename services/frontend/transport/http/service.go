@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"strconv"
 
 	// external
 	"github.com/go-kit/kit/endpoint"
@@ -15,24 +16,50 @@ import (
 
 	// project
 	"github.com/basvanbeek/opencensus-gokit-example/services/frontend"
+	"github.com/basvanbeek/opencensus-gokit-example/services/frontend/bulk"
 	"github.com/basvanbeek/opencensus-gokit-example/services/frontend/transport"
+	"github.com/basvanbeek/opencensus-gokit-example/services/frontend/transport/http/openapi"
 	"github.com/basvanbeek/opencensus-gokit-example/services/frontend/transport/http/routes"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/ical"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/problem"
 )
 
-// NewService wires our Go kit endpoints to the HTTP transport.
+// exportPageRows sets the amount of events requested from the EventExport
+// endpoint at a time. Each page is flushed to the client before requesting
+// the next one.
+const exportPageRows = 500
+
+type contextKey int
+
+const formatContextKey contextKey = iota
+
+// invalidImport is returned when an import payload can't be decoded. It keeps
+// the decoding details for the client.
+func invalidImport(field string, err error) error {
+	return problem.FieldsError{
+		Err:    frontend.ErrInvalidImport,
+		Fields: []problem.FieldError{{Field: field, Message: err.Error()}},
+	}
+}
+
+// NewService wires our Go kit endpoints to the HTTP transport. The optional
+// middlewares are run for each matched route, e.g. for rate limiting.
 func NewService(
 	svcEndpoints transport.Endpoints, options []kithttp.ServerOption,
-	logger log.Logger,
+	logger log.Logger, middlewares ...mux.MiddlewareFunc,
 ) http.Handler {
 	// set-up router and initialize http endpoints
 	var (
 		router       = mux.NewRouter()
 		route        = routes.Initialize(router)
 		errorLogger  = kithttp.ServerErrorLogger(logger)
-		errorEncoder = kithttp.ServerErrorEncoder(encodeErrorResponse)
+		errorEncoder = kithttp.ServerErrorEncoder(problem.EncodeError)
 	)
 
 	options = append(options, errorLogger, errorEncoder)
+	router.NotFoundHandler = problem.NotFoundHandler()
+	router.MethodNotAllowedHandler = problem.MethodNotAllowedHandler()
+	router.Use(middlewares...)
 
 	// wire our Go kit handlers to the http endpoints
 	route.Login.Handler(kithttp.NewServer(
@@ -65,6 +92,19 @@ func NewService(
 		options...,
 	))
 
+	route.EventImport.Handler(kithttp.NewServer(
+		svcEndpoints.EventImport, decodeEventImportRequest, encodeEventImportResponse,
+		options...,
+	))
+
+	// exports page through the EventExport endpoint while streaming the
+	// response, so we never hold all events of a tenant in memory.
+	route.EventExport.Handler(kithttp.NewServer(
+		makeEventExportStream(svcEndpoints.EventExport),
+		decodeEventExportRequest, encodeEventExportResponse,
+		append(options, kithttp.ServerBefore(formatToContext))...,
+	))
+
 	route.UnlockDevice.Handler(kithttp.NewServer(
 		svcEndpoints.UnlockDevice, decodeUnlockDeviceRequest, encodeUnlockDeviceResponse,
 		options...,
@@ -75,6 +115,54 @@ func NewService(
 		options...,
 	))
 
+	route.EventCalendarToken.Handler(kithttp.NewServer(
+		svcEndpoints.EventCalendarToken, decodeEventCalendarTokenRequest,
+		encodeEventCalendarTokenResponse, options...,
+	))
+
+	route.EventCalendar.Handler(kithttp.NewServer(
+		svcEndpoints.EventCalendar, decodeEventCalendarRequest,
+		encodeEventCalendarResponse, options...,
+	))
+
+	route.EventClone.Handler(kithttp.NewServer(
+		svcEndpoints.EventClone, decodeEventCloneRequest, encodeEventCloneResponse,
+		options...,
+	))
+
+	route.EventTemplateCreate.Handler(kithttp.NewServer(
+		svcEndpoints.EventTemplateCreate, decodeEventTemplateCreateRequest,
+		encodeEventTemplateCreateResponse, options...,
+	))
+
+	route.EventTemplateList.Handler(kithttp.NewServer(
+		svcEndpoints.EventTemplateList, decodeEventTemplateListRequest,
+		encodeEventTemplateListResponse, options...,
+	))
+
+	route.EventTemplateDelete.Handler(kithttp.NewServer(
+		svcEndpoints.EventTemplateDelete, decodeEventTemplateDeleteRequest,
+		encodeEventTemplateDeleteResponse, options...,
+	))
+
+	route.WebhookCreate.Handler(kithttp.NewServer(
+		svcEndpoints.WebhookCreate, decodeWebhookCreateRequest, encodeWebhookCreateResponse,
+		options...,
+	))
+
+	route.WebhookDelete.Handler(kithttp.NewServer(
+		svcEndpoints.WebhookDelete, decodeWebhookDeleteRequest, encodeWebhookDeleteResponse,
+		options...,
+	))
+
+	route.WebhookList.Handler(kithttp.NewServer(
+		svcEndpoints.WebhookList, decodeWebhookListRequest, encodeWebhookListResponse,
+		options...,
+	))
+
+	// serve the OpenAPI document of our routes
+	route.OpenAPI.Handler(openapi.Handler())
+
 	// return our router as http handler
 	return router
 }
@@ -84,8 +172,10 @@ func NewService(
 
 func decodeLoginRequest(_ context.Context, r *http.Request) (interface{}, error) {
 	var req transport.LoginRequest
-	err := json.NewDecoder(r.Body).Decode(&req)
-	return req, err
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, problem.InvalidField("body", err)
+	}
+	return req, nil
 }
 
 func encodeLoginResponse(_ context.Context, w http.ResponseWriter, response interface{}) error {
@@ -98,8 +188,10 @@ func encodeLoginResponse(_ context.Context, w http.ResponseWriter, response inte
 
 func decodeEventCreateRequest(_ context.Context, r *http.Request) (interface{}, error) {
 	var req transport.EventCreateRequest
-	err := json.NewDecoder(r.Body).Decode(&req)
-	return req, err
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, problem.InvalidField("body", err)
+	}
+	return req, nil
 }
 
 func encodeEventCreateResponse(_ context.Context, w http.ResponseWriter, response interface{}) error {
@@ -111,8 +203,10 @@ func encodeEventCreateResponse(_ context.Context, w http.ResponseWriter, respons
 }
 func decodeEventGetRequest(_ context.Context, r *http.Request) (interface{}, error) {
 	var req transport.EventGetRequest
-	err := json.NewDecoder(r.Body).Decode(&req)
-	return req, err
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, problem.InvalidField("body", err)
+	}
+	return req, nil
 }
 
 func encodeEventGetResponse(_ context.Context, w http.ResponseWriter, response interface{}) error {
@@ -124,8 +218,10 @@ func encodeEventGetResponse(_ context.Context, w http.ResponseWriter, response i
 }
 func decodeEventUpdateRequest(_ context.Context, r *http.Request) (interface{}, error) {
 	var req transport.EventUpdateRequest
-	err := json.NewDecoder(r.Body).Decode(&req)
-	return req, err
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, problem.InvalidField("body", err)
+	}
+	return req, nil
 }
 
 func encodeEventUpdateResponse(_ context.Context, w http.ResponseWriter, response interface{}) error {
@@ -137,8 +233,10 @@ func encodeEventUpdateResponse(_ context.Context, w http.ResponseWriter, respons
 }
 func decodeEventDeleteRequest(_ context.Context, r *http.Request) (interface{}, error) {
 	var req transport.EventDeleteRequest
-	err := json.NewDecoder(r.Body).Decode(&req)
-	return req, err
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, problem.InvalidField("body", err)
+	}
+	return req, nil
 }
 
 func encodeEventDeleteResponse(_ context.Context, w http.ResponseWriter, response interface{}) error {
@@ -150,8 +248,10 @@ func encodeEventDeleteResponse(_ context.Context, w http.ResponseWriter, respons
 }
 func decodeEventListRequest(_ context.Context, r *http.Request) (interface{}, error) {
 	var req transport.EventListRequest
-	err := json.NewDecoder(r.Body).Decode(&req)
-	return req, err
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, problem.InvalidField("body", err)
+	}
+	return req, nil
 }
 
 func encodeEventListResponse(_ context.Context, w http.ResponseWriter, response interface{}) error {
@@ -162,10 +262,163 @@ func encodeEventListResponse(_ context.Context, w http.ResponseWriter, response
 	return json.NewEncoder(w).Encode(response)
 }
 
+func decodeEventImportRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var (
+		err    error
+		req    transport.EventImportRequest
+		format bulk.Format
+		query  = r.URL.Query()
+	)
+	if req.TenantID, err = uuid.FromString(query.Get("tenant_id")); err != nil {
+		return nil, problem.InvalidField("tenant_id", err)
+	}
+	if req.DryRun, err = parseBool(query.Get("dry_run")); err != nil {
+		return nil, invalidImport("dry_run", err)
+	}
+	if format, err = bulk.ParseFormat(query.Get("format")); err != nil {
+		return nil, invalidImport("format", err)
+	}
+	if req.Events, err = bulk.ReadEvents(r.Body, format); err != nil {
+		return nil, invalidImport("body", err)
+	}
+	return req, nil
+}
+
+func encodeEventImportResponse(_ context.Context, w http.ResponseWriter, response interface{}) error {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := response.(endpoint.Failer).Failed(); err != nil {
+		return err
+	}
+	return json.NewEncoder(w).Encode(response)
+}
+
+func decodeEventExportRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var (
+		err   error
+		req   transport.EventExportRequest
+		query = r.URL.Query()
+	)
+	if req.TenantID, err = uuid.FromString(query.Get("tenant_id")); err != nil {
+		return nil, problem.InvalidField("tenant_id", err)
+	}
+	if after := query.Get("after"); after != "" {
+		if req.After, err = uuid.FromString(after); err != nil {
+			return nil, problem.InvalidField("after", err)
+		}
+	}
+	if limit := query.Get("limit"); limit != "" {
+		if req.Limit, err = strconv.Atoi(limit); err != nil || req.Limit < 0 {
+			return nil, problem.InvalidField("limit", strconv.ErrSyntax)
+		}
+	}
+	return req, nil
+}
+
+// eventExportStream holds the first page of an export. The remaining pages
+// are requested while the response is written.
+type eventExportStream struct {
+	transport.EventExportResponse
+	next      transport.EventExportRequest
+	remaining int // events left to export after this page, -1 if unlimited
+	endpoint  endpoint.Endpoint
+}
+
+// makeEventExportStream returns an endpoint requesting the first page of an
+// export from next. The Limit of the request caps the total amount of
+// exported events, 0 exports all events.
+func makeEventExportStream(next endpoint.Endpoint) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transport.EventExportRequest)
+		stream := eventExportStream{next: req, remaining: -1, endpoint: next}
+		if req.Limit > 0 {
+			stream.remaining = req.Limit
+		}
+		if err := stream.fetch(ctx); err != nil {
+			return nil, err
+		}
+		return stream, nil
+	}
+}
+
+// more reports if the current page may be followed by another one.
+func (s *eventExportStream) more() bool {
+	return s.remaining != 0 && len(s.Events) == s.next.Limit
+}
+
+// fetch replaces the current page with the next one.
+func (s *eventExportStream) fetch(ctx context.Context) error {
+	s.next.Limit = exportPageRows
+	if s.remaining >= 0 && s.remaining < exportPageRows {
+		s.next.Limit = s.remaining
+	}
+	response, err := s.endpoint(ctx, s.next)
+	if err != nil {
+		return err
+	}
+	s.EventExportResponse = response.(transport.EventExportResponse)
+	if s.Err != nil {
+		return nil
+	}
+	if s.remaining >= 0 {
+		s.remaining -= len(s.Events)
+	}
+	if n := len(s.Events); n > 0 {
+		s.next.After = s.Events[n-1].ID
+	}
+	return nil
+}
+
+// encodeEventExportResponse streams the events in the requested bulk format,
+// fetching the next page after each page is flushed to the client. Once the
+// first page is written the status code has been sent, so failures fetching
+// later pages can't be reported by status code.
+func encodeEventExportResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	stream := response.(eventExportStream)
+	if err := stream.Failed(); err != nil {
+		return err
+	}
+
+	format, _ := ctx.Value(formatContextKey).(bulk.Format)
+	writer, err := bulk.NewWriter(w, format)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set(
+		"Content-Disposition", `attachment; filename="events.`+string(format)+`"`,
+	)
+
+	flusher, _ := w.(http.Flusher)
+	for {
+		for _, evt := range stream.Events {
+			if err = writer.Write(evt); err != nil {
+				return err
+			}
+		}
+		if err = writer.Flush(); err != nil {
+			return err
+		}
+		if !stream.more() {
+			return nil
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		if err = stream.fetch(ctx); err != nil {
+			return err
+		}
+		if err = stream.Failed(); err != nil {
+			return err
+		}
+	}
+}
+
 func decodeUnlockDeviceRequest(_ context.Context, r *http.Request) (interface{}, error) {
 	var req transport.UnlockDeviceRequest
-	err := json.NewDecoder(r.Body).Decode(&req)
-	return req, err
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, problem.InvalidField("body", err)
+	}
+	return req, nil
 }
 
 func encodeUnlockDeviceResponse(_ context.Context, w http.ResponseWriter, response interface{}) error {
@@ -183,11 +436,11 @@ func decodeGenerateQRRequest(_ context.Context, r *http.Request) (interface{}, e
 	)
 	v := mux.Vars(r)
 	if req.EventID, err = uuid.FromString(v["event_id"]); err != nil {
-		return nil, err
+		return nil, problem.InvalidField("event_id", err)
 	}
 
 	if req.DeviceID, err = uuid.FromString(v["device_id"]); err != nil {
-		return nil, err
+		return nil, problem.InvalidField("device_id", err)
 	}
 	req.UnlockCode = v["code"]
 	return req, nil
@@ -195,40 +448,189 @@ func decodeGenerateQRRequest(_ context.Context, r *http.Request) (interface{}, e
 
 func encodeGenerateQRResponse(_ context.Context, w http.ResponseWriter, response interface{}) error {
 	res := response.(transport.GenerateQRResponse)
-	if res.Failed() != nil {
-		// TODO: add logic ex. auth
-		w.Header().Set("Content-Type", "application/json; charset=utf-8")
-		w.WriteHeader(http.StatusInternalServerError)
-		b, err := json.Marshal(res.Failed().Error())
-		if err != nil {
-			return err
-		}
-		w.Write(b)
-		return nil
+	if err := res.Failed(); err != nil {
+		return err
 	}
 	w.Header().Set("Content-Type", "image/png")
-	w.Write(res.QR)
-	return nil
+	_, err := w.Write(res.QR)
+	return err
+}
+
+func decodeEventCalendarTokenRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req transport.EventCalendarTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, problem.InvalidField("body", err)
+	}
+	return req, nil
+}
+
+func encodeEventCalendarTokenResponse(_ context.Context, w http.ResponseWriter, response interface{}) error {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := response.(endpoint.Failer).Failed(); err != nil {
+		return err
+	}
+	return json.NewEncoder(w).Encode(response)
+}
+
+func decodeEventCalendarRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	return transport.EventCalendarRequest{Token: mux.Vars(r)["token"]}, nil
+}
+
+func encodeEventCalendarResponse(_ context.Context, w http.ResponseWriter, response interface{}) error {
+	res := response.(transport.EventCalendarResponse)
+	if err := res.Failed(); err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", ical.ContentType)
+	// calendar apps poll the feed, allow them to cache it for a bit
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	_, err := w.Write(res.Calendar)
+	return err
+}
+
+func decodeEventCloneRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var (
+		err error
+		req transport.EventCloneRequest
+	)
+	if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, problem.InvalidField("body", err)
+	}
+	if req.EventID, err = uuid.FromString(mux.Vars(r)["event_id"]); err != nil {
+		return nil, problem.InvalidField("event_id", err)
+	}
+	return req, nil
+}
+
+func encodeEventCloneResponse(_ context.Context, w http.ResponseWriter, response interface{}) error {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := response.(endpoint.Failer).Failed(); err != nil {
+		return err
+	}
+	return json.NewEncoder(w).Encode(response)
+}
+
+func decodeEventTemplateCreateRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req transport.EventTemplateCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, problem.InvalidField("body", err)
+	}
+	return req, nil
+}
+
+func encodeEventTemplateCreateResponse(_ context.Context, w http.ResponseWriter, response interface{}) error {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := response.(endpoint.Failer).Failed(); err != nil {
+		return err
+	}
+	return json.NewEncoder(w).Encode(response)
+}
+
+func decodeEventTemplateListRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req transport.EventTemplateListRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, problem.InvalidField("body", err)
+	}
+	return req, nil
+}
+
+func encodeEventTemplateListResponse(_ context.Context, w http.ResponseWriter, response interface{}) error {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := response.(endpoint.Failer).Failed(); err != nil {
+		return err
+	}
+	return json.NewEncoder(w).Encode(response)
 }
 
-func encodeErrorResponse(_ context.Context, err error, w http.ResponseWriter) {
-	var code int
-	switch err {
-	case frontend.ErrUserPassRequired, frontend.ErrRequireEventID,
-		frontend.ErrRequireDeviceID, frontend.ErrRequireUnlockCode,
-		frontend.ErrInvalidQRParams:
-		code = http.StatusBadRequest
-	case frontend.ErrEventExists:
-		code = http.StatusConflict
-	case frontend.ErrEventNotFound:
-		code = http.StatusNotFound
-	case frontend.ErrUserPassUnknown, frontend.ErrUnlockNotFound,
-		frontend.ErrUnauthorized:
-		code = http.StatusUnauthorized
-	case frontend.ErrQRGenerate:
-		code = http.StatusServiceUnavailable
-	default:
-		code = http.StatusInternalServerError
-	}
-	http.Error(w, err.Error(), code)
+func decodeEventTemplateDeleteRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var (
+		err error
+		req transport.EventTemplateDeleteRequest
+	)
+	if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, problem.InvalidField("body", err)
+	}
+	if req.TemplateID, err = uuid.FromString(mux.Vars(r)["template_id"]); err != nil {
+		return nil, problem.InvalidField("template_id", err)
+	}
+	return req, nil
+}
+
+func encodeEventTemplateDeleteResponse(_ context.Context, w http.ResponseWriter, response interface{}) error {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := response.(endpoint.Failer).Failed(); err != nil {
+		return err
+	}
+	return json.NewEncoder(w).Encode(response)
+}
+
+func decodeWebhookCreateRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req transport.WebhookCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, problem.InvalidField("body", err)
+	}
+	return req, nil
+}
+
+func encodeWebhookCreateResponse(_ context.Context, w http.ResponseWriter, response interface{}) error {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := response.(endpoint.Failer).Failed(); err != nil {
+		return err
+	}
+	return json.NewEncoder(w).Encode(response)
+}
+
+func decodeWebhookDeleteRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var (
+		err error
+		req transport.WebhookDeleteRequest
+	)
+	if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, problem.InvalidField("body", err)
+	}
+	if req.WebhookID, err = uuid.FromString(mux.Vars(r)["webhook_id"]); err != nil {
+		return nil, problem.InvalidField("webhook_id", err)
+	}
+	return req, nil
+}
+
+func encodeWebhookDeleteResponse(_ context.Context, w http.ResponseWriter, response interface{}) error {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := response.(endpoint.Failer).Failed(); err != nil {
+		return err
+	}
+	return json.NewEncoder(w).Encode(response)
+}
+
+func decodeWebhookListRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req transport.WebhookListRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, problem.InvalidField("body", err)
+	}
+	return req, nil
+}
+
+func encodeWebhookListResponse(_ context.Context, w http.ResponseWriter, response interface{}) error {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := response.(endpoint.Failer).Failed(); err != nil {
+		return err
+	}
+	return json.NewEncoder(w).Encode(response)
+}
+
+// formatToContext stores the requested bulk format for use by the response
+// encoder. Unknown formats fall back to the default.
+func formatToContext(ctx context.Context, r *http.Request) context.Context {
+	format, err := bulk.ParseFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		format = bulk.JSONL
+	}
+	return context.WithValue(ctx, formatContextKey, format)
+}
+
+func parseBool(s string) (bool, error) {
+	if s == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(s)
 }
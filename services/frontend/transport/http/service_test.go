@@ -0,0 +1,111 @@
+package http
+
+import (
+	// stdlib
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/kevinburke/go.uuid"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/services/frontend"
+	"github.com/basvanbeek/opencensus-gokit-example/services/frontend/bulk"
+	"github.com/basvanbeek/opencensus-gokit-example/services/frontend/transport"
+)
+
+// exportPages serves the EventExport endpoint from events and records the
+// page sizes requested.
+type exportPages struct {
+	events []*frontend.Event
+	limits []int
+}
+
+func (p *exportPages) endpoint(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(transport.EventExportRequest)
+	p.limits = append(p.limits, req.Limit)
+
+	var page []*frontend.Event
+	for _, evt := range p.events {
+		if bytes.Compare(evt.ID.Bytes(), req.After.Bytes()) > 0 && len(page) < req.Limit {
+			page = append(page, evt)
+		}
+	}
+	return transport.EventExportResponse{Events: page}, nil
+}
+
+func TestEventExport(t *testing.T) {
+	tenantID := uuid.NewV4()
+	pages := &exportPages{}
+	for i := 0; i < 2*exportPageRows+10; i++ {
+		pages.events = append(pages.events, &frontend.Event{ID: uuid.NewV4(), Name: "event"})
+	}
+	sort.Slice(pages.events, func(i, j int) bool {
+		return bytes.Compare(pages.events[i].ID.Bytes(), pages.events[j].ID.Bytes()) < 0
+	})
+	handler := NewService(
+		transport.Endpoints{EventExport: pages.endpoint}, nil, log.NewNopLogger(),
+	)
+
+	for _, tc := range []struct {
+		name   string
+		query  string
+		events []*frontend.Event
+		limits []int
+	}{
+		{
+			name:   "all",
+			events: pages.events,
+			limits: []int{exportPageRows, exportPageRows, exportPageRows},
+		},
+		{
+			name:   "after",
+			query:  "&after=" + pages.events[exportPageRows+4].ID.String(),
+			events: pages.events[exportPageRows+5:],
+			limits: []int{exportPageRows, exportPageRows},
+		},
+		{
+			name:   "limit",
+			query:  "&limit=510",
+			events: pages.events[:510],
+			limits: []int{exportPageRows, 10},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			pages.limits = nil
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(
+				"GET", "/event/export?tenant_id="+tenantID.String()+tc.query, nil,
+			))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("want status %d, have %d: %s", http.StatusOK, rec.Code, rec.Body)
+			}
+
+			events, err := bulk.ReadEvents(rec.Body, bulk.JSONL)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(events) != len(tc.events) {
+				t.Fatalf("want %d events, have %d", len(tc.events), len(events))
+			}
+			for idx := range events {
+				if !uuid.Equal(events[idx].ID, tc.events[idx].ID) {
+					t.Fatalf("event %d: want %s, have %s", idx, tc.events[idx].ID, events[idx].ID)
+				}
+			}
+			if len(pages.limits) != len(tc.limits) {
+				t.Fatalf("want page sizes %v, have %v", tc.limits, pages.limits)
+			}
+			for idx := range tc.limits {
+				if pages.limits[idx] != tc.limits[idx] {
+					t.Errorf("want page sizes %v, have %v", tc.limits, pages.limits)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package http
+
+import (
+	// stdlib
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	// external
+	"github.com/kevinburke/go.uuid"
+)
+
+// maxTenantPeek is the maximum amount of JSON payload read to find the tenant
+// of a request.
+const maxTenantPeek = 64 << 10
+
+// RequestTenant returns the tenant of a request from the tenant_id query
+// parameter or from the tenant_id field of small JSON payloads, leaving the
+// payload intact for the request decoders. It returns an empty string if the
+// request holds no valid tenant id.
+func RequestTenant(r *http.Request) string {
+	if id := r.URL.Query().Get("tenant_id"); id != "" {
+		return canonicalTenant(id)
+	}
+	if r.Body == nil || r.Body == http.NoBody {
+		return ""
+	}
+
+	peek, err := ioutil.ReadAll(io.LimitReader(r.Body, maxTenantPeek))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(peek), r.Body), r.Body}
+	if err != nil {
+		return ""
+	}
+
+	var payload struct {
+		TenantID string `json:"tenant_id"`
+	}
+	if err = json.Unmarshal(peek, &payload); err != nil {
+		return ""
+	}
+	return canonicalTenant(payload.TenantID)
+}
+
+func canonicalTenant(id string) string {
+	tenantID, err := uuid.FromString(id)
+	if err != nil || uuid.Equal(tenantID, uuid.Nil) {
+		return ""
+	}
+	return tenantID.String()
+}
@@ -0,0 +1,103 @@
+package transport
+
+import (
+	// stdlib
+	"context"
+	"strings"
+
+	// external
+	"github.com/kevinburke/go.uuid"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/services/device"
+	"github.com/basvanbeek/opencensus-gokit-example/services/frontend"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/quota"
+)
+
+// TenantFunc returns the tenant owning the device of an event, e.g. the
+// DeviceTenant method of the device service or its client.
+type TenantFunc func(ctx context.Context, eventID, deviceID uuid.UUID) (uuid.UUID, error)
+
+// deviceTenant returns the tenant owning the device using tenant. Requests
+// missing details are not metered as they are rejected by our service, unknown
+// devices result in ErrUnlockNotFound.
+func deviceTenant(ctx context.Context, tenant TenantFunc, eventID, deviceID uuid.UUID) (uuid.UUID, error) {
+	tenantID, err := tenant(ctx, eventID, deviceID)
+	switch err {
+	case nil:
+		return tenantID, nil
+	case device.ErrRequireEventID, device.ErrRequireDeviceID:
+		return uuid.Nil, nil
+	case device.ErrDeviceNotFound:
+		return uuid.Nil, frontend.ErrUnlockNotFound
+	default:
+		return uuid.Nil, frontend.ErrService
+	}
+}
+
+// EventCreateQuota meters created events.
+func EventCreateQuota() quota.Rule {
+	return quota.Rule{
+		Resource: quota.Events,
+		Request: func(_ context.Context, request interface{}) (uuid.UUID, int64, error) {
+			return request.(EventCreateRequest).TenantID, 1, nil
+		},
+	}
+}
+
+// EventImportQuota meters imported events. Dry runs are not metered.
+func EventImportQuota() quota.Rule {
+	return quota.Rule{
+		Resource: quota.Events,
+		Request: func(_ context.Context, request interface{}) (uuid.UUID, int64, error) {
+			req := request.(EventImportRequest)
+			if req.DryRun {
+				return uuid.Nil, 0, nil
+			}
+			return req.TenantID, int64(len(req.Events)), nil
+		},
+	}
+}
+
+// EventCloneQuota meters cloned events.
+func EventCloneQuota() quota.Rule {
+	return quota.Rule{
+		Resource: quota.Events,
+		Request: func(_ context.Context, request interface{}) (uuid.UUID, int64, error) {
+			return request.(EventCloneRequest).TenantID, 1, nil
+		},
+	}
+}
+
+// GenerateQRQuota meters generated QR codes against the quota of the tenant
+// owning the device. Requests missing details are not metered as they are
+// rejected by the service.
+func GenerateQRQuota(tenant TenantFunc) quota.Rule {
+	return quota.Rule{
+		Resource: quota.QRGenerations,
+		Request: func(ctx context.Context, request interface{}) (uuid.UUID, int64, error) {
+			req := request.(GenerateQRRequest)
+			if uuid.Equal(req.EventID, uuid.Nil) || uuid.Equal(req.DeviceID, uuid.Nil) ||
+				strings.TrimSpace(req.UnlockCode) == "" {
+				return uuid.Nil, 0, nil
+			}
+			tenantID, err := deviceTenant(ctx, tenant, req.EventID, req.DeviceID)
+			return tenantID, 1, err
+		},
+	}
+}
+
+// UnlockDeviceQuota meters each attempt to unlock a known device against the
+// quota of the tenant owning the device. It is only needed if the device
+// service doesn't meter unlock attempts itself, e.g. in the monolith.
+func UnlockDeviceQuota(tenant TenantFunc) quota.Rule {
+	return quota.Rule{
+		Resource: quota.UnlockAttempts,
+		Request: func(ctx context.Context, request interface{}) (uuid.UUID, int64, error) {
+			req := request.(UnlockDeviceRequest)
+			tenantID, err := deviceTenant(ctx, tenant, req.EventID, req.DeviceID)
+			return tenantID, 1, err
+		},
+		Attempts: true,
+	}
+}
@@ -16,8 +16,19 @@ var (
 	_ endpoint.Failer = EventUpdateResponse{}
 	_ endpoint.Failer = EventDeleteResponse{}
 	_ endpoint.Failer = EventListResponse{}
+	_ endpoint.Failer = EventImportResponse{}
+	_ endpoint.Failer = EventExportResponse{}
 	_ endpoint.Failer = UnlockDeviceResponse{}
 	_ endpoint.Failer = GenerateQRResponse{}
+	_ endpoint.Failer = EventCalendarTokenResponse{}
+	_ endpoint.Failer = EventCalendarResponse{}
+	_ endpoint.Failer = EventCloneResponse{}
+	_ endpoint.Failer = EventTemplateCreateResponse{}
+	_ endpoint.Failer = EventTemplateListResponse{}
+	_ endpoint.Failer = EventTemplateDeleteResponse{}
+	_ endpoint.Failer = WebhookCreateResponse{}
+	_ endpoint.Failer = WebhookDeleteResponse{}
+	_ endpoint.Failer = WebhookListResponse{}
 )
 
 // LoginRequest holds the request parameters for the Login method.
@@ -110,6 +121,38 @@ type EventListResponse struct {
 // Failed implements Failer.
 func (r EventListResponse) Failed() error { return r.Err }
 
+// EventImportRequest holds the request parameters for the EventImport method.
+type EventImportRequest struct {
+	TenantID uuid.UUID        `json:"tenant_id"`
+	Events   []frontend.Event `json:"events"`
+	DryRun   bool             `json:"dry_run"`
+}
+
+// EventImportResponse holds the response values for the EventImport method.
+type EventImportResponse struct {
+	Result *frontend.ImportResult `json:"result,omitempty"`
+	Err    error
+}
+
+// Failed implements Failer.
+func (r EventImportResponse) Failed() error { return r.Err }
+
+// EventExportRequest holds the request parameters for the EventExport method.
+type EventExportRequest struct {
+	TenantID uuid.UUID `json:"tenant_id"`
+	After    uuid.UUID `json:"after"`
+	Limit    int       `json:"limit"`
+}
+
+// EventExportResponse holds the response values for the EventExport method.
+type EventExportResponse struct {
+	Events []*frontend.Event `json:"events,omitempty"`
+	Err    error
+}
+
+// Failed implements Failer.
+func (r EventExportResponse) Failed() error { return r.Err }
+
 // UnlockDeviceRequest holds the request parameters for the UnlockDevice method.
 type UnlockDeviceRequest struct {
 	EventID    uuid.UUID `json:"event_id"`
@@ -141,3 +184,147 @@ type GenerateQRResponse struct {
 
 // Failed implements Failer.
 func (r GenerateQRResponse) Failed() error { return r.Err }
+
+// EventCalendarTokenRequest holds the request parameters for the
+// EventCalendarToken method.
+type EventCalendarTokenRequest struct {
+	TenantID uuid.UUID `json:"tenant_id"`
+}
+
+// EventCalendarTokenResponse holds the response values for the
+// EventCalendarToken method.
+type EventCalendarTokenResponse struct {
+	Token string `json:"token,omitempty"`
+	Err   error
+}
+
+// Failed implements Failer.
+func (r EventCalendarTokenResponse) Failed() error { return r.Err }
+
+// EventCalendarRequest holds the request parameters for the EventCalendar
+// method.
+type EventCalendarRequest struct {
+	Token string
+}
+
+// EventCalendarResponse holds the response values for the EventCalendar
+// method.
+type EventCalendarResponse struct {
+	Calendar []byte
+	Err      error
+}
+
+// Failed implements Failer.
+func (r EventCalendarResponse) Failed() error { return r.Err }
+
+// EventCloneRequest holds the request parameters for the EventClone method.
+type EventCloneRequest struct {
+	TenantID uuid.UUID      `json:"tenant_id"`
+	EventID  uuid.UUID      `json:"event_id"`
+	Clone    frontend.Clone `json:"clone"`
+}
+
+// EventCloneResponse holds the response values for the EventClone method.
+type EventCloneResponse struct {
+	EventID *uuid.UUID `json:"event_id,omitempty"`
+	Err     error
+}
+
+// Failed implements Failer.
+func (r EventCloneResponse) Failed() error { return r.Err }
+
+// EventTemplateCreateRequest holds the request parameters for the
+// EventTemplateCreate method.
+type EventTemplateCreateRequest struct {
+	TenantID uuid.UUID         `json:"tenant_id"`
+	Template frontend.Template `json:"template"`
+}
+
+// EventTemplateCreateResponse holds the response values for the
+// EventTemplateCreate method.
+type EventTemplateCreateResponse struct {
+	TemplateID *uuid.UUID `json:"template_id,omitempty"`
+	Err        error
+}
+
+// Failed implements Failer.
+func (r EventTemplateCreateResponse) Failed() error { return r.Err }
+
+// EventTemplateListRequest holds the request parameters for the
+// EventTemplateList method.
+type EventTemplateListRequest struct {
+	TenantID uuid.UUID `json:"tenant_id"`
+}
+
+// EventTemplateListResponse holds the response values for the
+// EventTemplateList method.
+type EventTemplateListResponse struct {
+	Templates []*frontend.Template `json:"templates,omitempty"`
+	Err       error
+}
+
+// Failed implements Failer.
+func (r EventTemplateListResponse) Failed() error { return r.Err }
+
+// EventTemplateDeleteRequest holds the request parameters for the
+// EventTemplateDelete method.
+type EventTemplateDeleteRequest struct {
+	TenantID   uuid.UUID `json:"tenant_id"`
+	TemplateID uuid.UUID `json:"template_id"`
+}
+
+// EventTemplateDeleteResponse holds the response values for the
+// EventTemplateDelete method.
+type EventTemplateDeleteResponse struct {
+	Err error
+}
+
+// Failed implements Failer.
+func (r EventTemplateDeleteResponse) Failed() error { return r.Err }
+
+// WebhookCreateRequest holds the request parameters for the WebhookCreate
+// method.
+type WebhookCreateRequest struct {
+	TenantID uuid.UUID        `json:"tenant_id"`
+	Webhook  frontend.Webhook `json:"webhook"`
+}
+
+// WebhookCreateResponse holds the response values for the WebhookCreate
+// method.
+type WebhookCreateResponse struct {
+	WebhookID *uuid.UUID `json:"webhook_id,omitempty"`
+	Err       error
+}
+
+// Failed implements Failer.
+func (r WebhookCreateResponse) Failed() error { return r.Err }
+
+// WebhookDeleteRequest holds the request parameters for the WebhookDelete
+// method.
+type WebhookDeleteRequest struct {
+	TenantID  uuid.UUID `json:"tenant_id"`
+	WebhookID uuid.UUID `json:"webhook_id"`
+}
+
+// WebhookDeleteResponse holds the response values for the WebhookDelete
+// method.
+type WebhookDeleteResponse struct {
+	Err error
+}
+
+// Failed implements Failer.
+func (r WebhookDeleteResponse) Failed() error { return r.Err }
+
+// WebhookListRequest holds the request parameters for the WebhookList method.
+type WebhookListRequest struct {
+	TenantID uuid.UUID `json:"tenant_id"`
+}
+
+// WebhookListResponse holds the response values for the WebhookList method.
+type WebhookListResponse struct {
+	Webhooks []*frontend.Webhook `json:"webhooks,omitempty"`
+	Err      error
+}
+
+// Failed implements Failer.
+func (r WebhookListResponse) Failed() error { return r.Err }
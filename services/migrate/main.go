@@ -0,0 +1,208 @@
+// Command migrate inspects and migrates the database schemas of our services.
+// It shows the schema versions, plans and applies pending migrations and runs
+// down migrations where defined, for every supported repository backend.
+package main
+
+import (
+	// stdlib
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/services/device"
+	devpostgres "github.com/basvanbeek/opencensus-gokit-example/services/device/database/postgres"
+	devsqlite "github.com/basvanbeek/opencensus-gokit-example/services/device/database/sqlite"
+	"github.com/basvanbeek/opencensus-gokit-example/services/event"
+	evtpostgres "github.com/basvanbeek/opencensus-gokit-example/services/event/database/postgres"
+	evtsqlite "github.com/basvanbeek/opencensus-gokit-example/services/event/database/sqlite"
+	"github.com/basvanbeek/opencensus-gokit-example/services/webhook"
+	whsqlite "github.com/basvanbeek/opencensus-gokit-example/services/webhook/database/sqlite"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/migrate"
+)
+
+// schemas holds the schemas of each service per supported dialect.
+var schemas = []struct {
+	service  string
+	sqlite   migrate.Schema
+	postgres *migrate.Schema
+}{
+	{event.ServiceName, evtsqlite.Schema, &evtpostgres.Schema},
+	{device.ServiceName, devsqlite.Schema, &devpostgres.Schema},
+	{webhook.ServiceName, whsqlite.Schema, nil},
+}
+
+func main() {
+	var (
+		service  = flag.String("service", "all", "service to migrate: event, device, webhook or all")
+		target   = flag.Int("to", migrate.Latest, "target schema version, latest if omitted")
+		sqlite   = flag.String("sqlite", "", "SQLite database file to use instead of <service>.db, e.g. monolith.db")
+		postgres = flag.String("postgres", "", "PostgreSQL connection string, defaults to OCG_<SERVICE>_DB")
+	)
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: ocg-migrate [flags] status|plan|up|down|force")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	// allow flags to follow the command as well
+	command := flag.Arg(0)
+	if flag.NArg() > 1 {
+		flag.CommandLine.Parse(flag.Args()[1:])
+	}
+
+	// initialize our structured logger for the command
+	var logger log.Logger
+	{
+		logger = log.NewLogfmtLogger(os.Stderr)
+		logger = log.NewSyncLogger(logger)
+		logger = log.With(logger,
+			"cmd", "migrate",
+			"ts", log.DefaultTimestampUTC,
+		)
+	}
+
+	switch command {
+	case "status", "plan", "up":
+	case "down", "force":
+		if *target < 0 {
+			level.Error(logger).Log("exit", command+" requires a target version")
+			os.Exit(-1)
+		}
+	default:
+		flag.Usage()
+		os.Exit(-1)
+	}
+
+	ctx := context.Background()
+
+	var found bool
+	for _, s := range schemas {
+		if *service != "all" && *service != s.service {
+			continue
+		}
+		found = true
+
+		// select the backend the same way the services do
+		var (
+			dialect = migrate.SQLite
+			schema  = s.sqlite
+			driver  = "sqlite3"
+			source  = s.service + ".db"
+		)
+		dsn := *postgres
+		if dsn == "" {
+			dsn = os.Getenv("OCG_" + strings.ToUpper(s.service) + "_DB")
+		}
+		switch {
+		case dsn != "" && s.postgres != nil:
+			dialect, schema, driver, source = migrate.Postgres, *s.postgres, "postgres", dsn
+		case *postgres != "" && *service != "all":
+			level.Error(logger).Log("exit", s.service+" has no PostgreSQL backend")
+			os.Exit(-1)
+		case *postgres != "":
+			level.Info(logger).Log("service", s.service, "msg", "skipped, no PostgreSQL backend")
+			continue
+		case *sqlite != "":
+			source = *sqlite
+		}
+
+		if err := run(ctx, command, *target, s.service, driver, source, dialect, schema, logger); err != nil {
+			level.Error(logger).Log("service", s.service, "exit", err)
+			os.Exit(-1)
+		}
+	}
+	if !found {
+		level.Error(logger).Log("exit", "unknown service "+*service)
+		os.Exit(-1)
+	}
+}
+
+func run(
+	ctx context.Context, command string, target int, service, driver,
+	source string, dialect migrate.Dialect, schema migrate.Schema,
+	logger log.Logger,
+) error {
+	if driver == "sqlite3" && command != "up" && command != "force" {
+		// don't create database files when only inspecting
+		if _, err := os.Stat(source); os.IsNotExist(err) {
+			fmt.Printf("%-8s %s: no database\n", service, source)
+			return nil
+		}
+	}
+
+	db, err := sqlx.Open(driver, source)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	m := migrate.New(db, dialect, schema, logger)
+	switch command {
+	case "status":
+		current, err := m.Version(ctx)
+		if err != nil {
+			return err
+		}
+		state := "up to date"
+		switch {
+		case current > schema.Latest():
+			state = "newer than supported"
+		case current < schema.Latest():
+			state = fmt.Sprintf("%d pending", schema.Latest()-current)
+		}
+		fmt.Printf("%-8s %s: version %d of %d, %s\n",
+			service, driver, current, schema.Latest(), state,
+		)
+	case "plan":
+		steps, err := m.Plan(ctx, target)
+		if err != nil {
+			return err
+		}
+		printSteps(service, "nothing to do", steps)
+	case "up", "down":
+		// only migrate in the requested direction
+		steps, err := m.Plan(ctx, target)
+		if err != nil {
+			return err
+		}
+		if len(steps) > 0 && steps[0].Revert != (command == "down") {
+			return fmt.Errorf("target version requires migrating %s", direction(steps[0]))
+		}
+		if steps, err = m.Migrate(ctx, target); err != nil {
+			return err
+		}
+		printSteps(service, "up to date", steps)
+	case "force":
+		if err := m.Force(ctx, target); err != nil {
+			return err
+		}
+		fmt.Printf("%-8s version set to %d\n", service, target)
+	}
+
+	return nil
+}
+
+func printSteps(service, none string, steps []migrate.Step) {
+	if len(steps) == 0 {
+		fmt.Printf("%-8s %s\n", service, none)
+	}
+	for _, step := range steps {
+		fmt.Printf("%-8s %s\n", service, step)
+	}
+}
+
+func direction(step migrate.Step) string {
+	if step.Revert {
+		return "down"
+	}
+	return "up"
+}
@@ -4,16 +4,13 @@ import (
 	// stdlib
 	"context"
 	"fmt"
-	"net"
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
 
 	// external
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
-	"github.com/go-kit/kit/sd/etcd"
 	kitoc "github.com/go-kit/kit/tracing/opencensus"
 	kitgrpc "github.com/go-kit/kit/transport/grpc"
 	"github.com/kevinburke/go.uuid"
@@ -21,13 +18,17 @@ import (
 	"google.golang.org/grpc"
 
 	// project
+	"github.com/basvanbeek/opencensus-gokit-example/services/frontend"
 	"github.com/basvanbeek/opencensus-gokit-example/services/qr"
 	"github.com/basvanbeek/opencensus-gokit-example/services/qr/implementation"
 	"github.com/basvanbeek/opencensus-gokit-example/services/qr/transport"
 	grpctransport "github.com/basvanbeek/opencensus-gokit-example/services/qr/transport/grpc"
 	"github.com/basvanbeek/opencensus-gokit-example/services/qr/transport/pb"
-	"github.com/basvanbeek/opencensus-gokit-example/shared/network"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/health"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/mtls"
 	"github.com/basvanbeek/opencensus-gokit-example/shared/oc"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/registry"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/sd"
 )
 
 func main() {
@@ -59,21 +60,42 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	// Create our etcd client for Service Discovery
-	//
-	// we could have used the v3 client but then we must vendor or suffer the
-	// following issue originating from gRPC init:
-	// panic: http: multiple registrations for /debug/requests
-	var sdc etcd.Client
+	// Create our service registry for Service Discovery, configured using
+	// OCG_REGISTRY and defaulting to etcd on localhost
+	var reg registry.Registry
 	{
-		// create our Go kit etcd client
-		sdc, err = etcd.NewClient(ctx, []string{"http://localhost:2379"}, etcd.ClientOptions{})
+		reg, err = registry.New(ctx, os.Getenv("OCG_REGISTRY"), logger)
 		if err != nil {
 			level.Error(logger).Log("exit", err)
 			os.Exit(-1)
 		}
 	}
 
+	// Create the metadata announced with our instances, see the README for its
+	// configuration
+	var md sd.Metadata
+	{
+		if md, err = registry.LocalMetadata("grpc"); err != nil {
+			level.Error(logger).Log("exit", err)
+			os.Exit(-1)
+		}
+	}
+
+	// Create the certificate source for mutual TLS between our services and
+	// the services allowed to call us, see the README for its configuration
+	var (
+		tlsSource *mtls.Source
+		callers   = mtls.AllowServices(frontend.ServiceName)
+	)
+	{
+		if tlsSource, err = mtls.FromEnv(logger); err != nil {
+			level.Error(logger).Log("exit", err)
+			os.Exit(-1)
+		}
+		// stop watching our certificate files on shutdown
+		defer tlsSource.Close()
+	}
+
 	// Create our QR Service
 	var svc qr.Service
 	{
@@ -100,26 +122,28 @@ func main() {
 	}
 	{
 		// set-up our grpc transport
+		listener, err := registry.Listen(qr.ServiceName, "grpc")
+		if err != nil {
+			level.Error(logger).Log("exit", err)
+			os.Exit(-1)
+		}
 		var (
-			bindIP, _     = network.HostIP()
 			ocTracing     = kitoc.GRPCServerTrace()
 			serverOptions = []kitgrpc.ServerOption{ocTracing}
 			qrService     = grpctransport.NewGRPCServer(endpoints, serverOptions, logger)
-			listener, _   = net.Listen("tcp", bindIP+":0") // dynamic port assignment
-			svcInstance   = fmt.Sprintf("/services/%s/grpc/%s/", qr.ServiceName, instance)
-			addr          = listener.Addr().String()
-			ttl           = etcd.NewTTLOption(3*time.Second, 10*time.Second)
-			serviceEntry  = etcd.Service{Key: svcInstance, Value: addr, TTL: ttl}
-			registrar     = etcd.NewRegistrar(sdc, serviceEntry, logger)
-			grpcServer    = grpc.NewServer()
+			addr          = sd.FormatInstance(listener.Addr().String(), md)
+			registrar     = reg.Registrar(qr.ServiceName, "grpc", instance.String(), addr)
+			grpcServer    = grpc.NewServer(tlsSource.GRPCServerOptions(callers)...)
 		)
 		pb.RegisterQRServer(grpcServer, qrService)
+		healthServer := health.RegisterGRPC(grpcServer, "pb.QR")
 
 		g.Add(func() error {
 			registrar.Register()
 			return grpcServer.Serve(listener)
 		}, func(error) {
 			registrar.Deregister()
+			healthServer.Shutdown()
 			listener.Close()
 		})
 	}
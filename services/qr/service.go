@@ -3,7 +3,9 @@ package qr
 import (
 	// stdlib
 	"context"
-	"errors"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/shared/errcode"
 )
 
 // ServiceName of this service.
@@ -25,11 +27,11 @@ const (
 
 // QR Service Errors
 var (
-	ErrInvalidRecoveryLevel = errors.New(ErrorInvalidRecoveryLevel)
-	ErrInvalidSize          = errors.New(ErrorInvalidSize)
-	ErrNoContent            = errors.New(ErrorNoContent)
-	ErrContentTooLarge      = errors.New(ErrorContentTooLarge)
-	ErrGenerate             = errors.New(ErrorGenerate)
+	ErrInvalidRecoveryLevel = errcode.New("qr.invalid_recovery_level", errcode.InvalidArgument, ErrorInvalidRecoveryLevel)
+	ErrInvalidSize          = errcode.New("qr.invalid_size", errcode.InvalidArgument, ErrorInvalidSize)
+	ErrNoContent            = errcode.New("qr.no_content", errcode.InvalidArgument, ErrorNoContent)
+	ErrContentTooLarge      = errcode.New("qr.content_too_large", errcode.FailedPrecondition, ErrorContentTooLarge)
+	ErrGenerate             = errcode.New("qr.generate", errcode.Internal, ErrorGenerate)
 )
 
 // RecoveryLevel : Error detection/recovery capacity.
@@ -8,13 +8,12 @@ import (
 	"github.com/go-kit/kit/log"
 	kitgrpc "github.com/go-kit/kit/transport/grpc"
 	oldcontext "golang.org/x/net/context"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 
 	// project
 	"github.com/basvanbeek/opencensus-gokit-example/services/qr"
 	"github.com/basvanbeek/opencensus-gokit-example/services/qr/transport"
 	"github.com/basvanbeek/opencensus-gokit-example/services/qr/transport/pb"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/errcode"
 )
 
 // grpc transport service for QR service.
@@ -43,7 +42,7 @@ func NewGRPCServer(
 func (s *grpcServer) Generate(ctx oldcontext.Context, req *pb.GenerateRequest) (*pb.GenerateResponse, error) {
 	_, rep, err := s.generate.ServeGRPC(ctx, req)
 	if err != nil {
-		return nil, err
+		return nil, errcode.GRPCError(err)
 	}
 	return rep.(*pb.GenerateResponse), nil
 }
@@ -61,18 +60,8 @@ func decodeGenerateRequest(_ context.Context, request interface{}) (interface{},
 // encodeGenerateResponse encodes the outgoing go kit payload to the grpc payload
 func encodeGenerateResponse(_ context.Context, response interface{}) (interface{}, error) {
 	res := response.(transport.GenerateResponse)
-	err := res.Failed()
-
-	switch err {
-	case nil:
-		return &pb.GenerateResponse{Image: res.QR}, nil
-	case qr.ErrInvalidRecoveryLevel, qr.ErrInvalidSize, qr.ErrNoContent:
-		return nil, status.Error(codes.InvalidArgument, err.Error())
-	case qr.ErrContentTooLarge:
-		return nil, status.Error(codes.FailedPrecondition, err.Error())
-	case qr.ErrGenerate:
-		return nil, status.Error(codes.Internal, err.Error())
-	default:
-		return nil, status.Error(codes.Unknown, err.Error())
+	if err := res.Failed(); err != nil {
+		return nil, errcode.GRPCError(err)
 	}
+	return &pb.GenerateResponse{Image: res.QR}, nil
 }
@@ -0,0 +1,21 @@
+package webhook
+
+import (
+	// stdlib
+	"net"
+)
+
+// sharedAddressSpace holds the carrier grade NAT range (RFC 6598), which some
+// cloud providers use for their metadata endpoints.
+var sharedAddressSpace = &net.IPNet{
+	IP:   net.IPv4(100, 64, 0, 0),
+	Mask: net.CIDRMask(10, 32),
+}
+
+// PublicIP returns true if webhooks may be delivered to ip. Tenants choose the
+// webhook URLs we POST to, so we refuse loopback, link-local (including cloud
+// metadata endpoints like 169.254.169.254), private and other addresses not
+// routed on the internet, which would expose our own infrastructure.
+func PublicIP(ip net.IP) bool {
+	return ip.IsGlobalUnicast() && !ip.IsPrivate() && !sharedAddressSpace.Contains(ip)
+}
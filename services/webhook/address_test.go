@@ -0,0 +1,38 @@
+package webhook
+
+import (
+	// stdlib
+	"net"
+	"testing"
+)
+
+func TestPublicIP(t *testing.T) {
+	for addr, want := range map[string]bool{
+		"93.184.216.34":          true,
+		"2606:2800:220:1::1":     true,
+		"127.0.0.1":              false,
+		"127.1.2.3":              false,
+		"::1":                    false,
+		"::ffff:127.0.0.1":       false,
+		"0.0.0.0":                false,
+		"::":                     false,
+		"169.254.169.254":        false,
+		"fe80::1":                false,
+		"fd00:ec2::254":          false,
+		"10.0.0.1":               false,
+		"172.16.0.1":             false,
+		"192.168.1.1":            false,
+		"100.100.100.200":        false,
+		"224.0.0.1":              false,
+		"255.255.255.255":        false,
+		"::ffff:169.254.169.254": false,
+		"::ffff:93.184.216.34":   true,
+	} {
+		if have := PublicIP(net.ParseIP(addr)); want != have {
+			t.Errorf("%s: want %t, have %t", addr, want, have)
+		}
+	}
+	if PublicIP(nil) {
+		t.Error("want invalid address refused")
+	}
+}
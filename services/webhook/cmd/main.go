@@ -0,0 +1,235 @@
+package main
+
+import (
+	// stdlib
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	kitoc "github.com/go-kit/kit/tracing/opencensus"
+	kithttp "github.com/go-kit/kit/transport/http"
+	"github.com/jmoiron/sqlx"
+	"github.com/kevinburke/go.uuid"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/oklog/run"
+	"github.com/opencensus-integrations/ocsql"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/services/device"
+	"github.com/basvanbeek/opencensus-gokit-example/services/event"
+	"github.com/basvanbeek/opencensus-gokit-example/services/frontend"
+	"github.com/basvanbeek/opencensus-gokit-example/services/webhook"
+	"github.com/basvanbeek/opencensus-gokit-example/services/webhook/database"
+	"github.com/basvanbeek/opencensus-gokit-example/services/webhook/database/sqlite"
+	"github.com/basvanbeek/opencensus-gokit-example/services/webhook/delivery"
+	"github.com/basvanbeek/opencensus-gokit-example/services/webhook/implementation"
+	"github.com/basvanbeek/opencensus-gokit-example/services/webhook/transport"
+	httptransport "github.com/basvanbeek/opencensus-gokit-example/services/webhook/transport/http"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/health"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/mtls"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/oc"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/registry"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/sd"
+)
+
+func main() {
+	var (
+		err      error
+		instance = uuid.NewV4()
+	)
+
+	// initialize our OpenCensus configuration and defer a clean-up
+	defer oc.Setup(webhook.ServiceName).Close()
+
+	// initialize our structured logger for the service
+	var logger log.Logger
+	{
+		logger = log.NewLogfmtLogger(os.Stderr)
+		logger = log.NewSyncLogger(logger)
+		logger = level.NewFilter(logger, level.AllowDebug())
+		logger = log.With(logger,
+			"svc", webhook.ServiceName,
+			"instance", instance,
+			"ts", log.DefaultTimestampUTC,
+			"clr", log.DefaultCaller,
+		)
+	}
+
+	level.Info(logger).Log("msg", "service started")
+	defer level.Info(logger).Log("msg", "service ended")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Create our service registry for Service Discovery, configured using
+	// OCG_REGISTRY and defaulting to etcd on localhost
+	var reg registry.Registry
+	{
+		reg, err = registry.New(ctx, os.Getenv("OCG_REGISTRY"), logger)
+		if err != nil {
+			level.Error(logger).Log("exit", err)
+			os.Exit(-1)
+		}
+	}
+
+	// Create the metadata announced with our instances, see the README for its
+	// configuration
+	var md sd.Metadata
+	{
+		if md, err = registry.LocalMetadata("http"); err != nil {
+			level.Error(logger).Log("exit", err)
+			os.Exit(-1)
+		}
+	}
+
+	// Create the certificate source for mutual TLS between our services and
+	// the services allowed to call us, see the README for its configuration
+	var (
+		tlsSource *mtls.Source
+		callers   = mtls.AllowServices(frontend.ServiceName, device.ServiceName, event.ServiceName)
+	)
+	{
+		if tlsSource, err = mtls.FromEnv(logger); err != nil {
+			level.Error(logger).Log("exit", err)
+			os.Exit(-1)
+		}
+		// stop watching our certificate files on shutdown
+		defer tlsSource.Close()
+	}
+
+	// Create our DB Connection Driver
+	var db *sqlx.DB
+	{
+		// create our ocsql instrumented sqlite3 driver
+		var driverName string
+		driverName, err = ocsql.Register("sqlite3", ocsql.WithOptions(ocsql.AllTraceOptions))
+		if err != nil {
+			level.Error(logger).Log("exit", err)
+			os.Exit(-1)
+		}
+		db, err = sqlx.Open(driverName, "webhook.db?_journal_mode=WAL")
+		if err != nil {
+			level.Error(logger).Log("exit", err)
+			os.Exit(-1)
+		}
+
+		// make sure the DB is in WAL mode
+		if _, err = db.Exec(`PRAGMA journal_mode=wal`); err != nil {
+			level.Error(logger).Log("exit", err)
+			os.Exit(-1)
+		}
+	}
+
+	// Create our Webhook Repository
+	var repository database.Repository
+	{
+		repository, err = sqlite.New(db, logger)
+		if err != nil {
+			level.Error(logger).Log("exit", err)
+			os.Exit(-1)
+		}
+	}
+
+	// Create our Webhook Service
+	var svc webhook.Service
+	{
+		svc = implementation.NewService(repository, logger)
+		// add service level middlewares here
+	}
+
+	// Create our Go kit endpoints for the Webhook Service
+	var endpoints transport.Endpoints
+	{
+		endpoints = transport.MakeEndpoints(svc)
+		// add endpoint level middlewares here
+		endpoints = transport.Endpoints{
+			Subscribe:     oc.ServerEndpoint("Subscribe")(endpoints.Subscribe),
+			Unsubscribe:   oc.ServerEndpoint("Unsubscribe")(endpoints.Unsubscribe),
+			Subscriptions: oc.ServerEndpoint("Subscriptions")(endpoints.Subscriptions),
+			Publish:       oc.ServerEndpoint("Publish")(endpoints.Publish),
+		}
+	}
+
+	// Create our Webhook delivery Worker
+	var worker *delivery.Worker
+	{
+		worker = delivery.NewWorker(repository, logger)
+	}
+
+	// run.Group manages our goroutine lifecycles
+	// see: https://www.youtube.com/watch?v=LHe1Cb_Ud_M&t=15m45s
+	var g run.Group
+	{
+		// set-up our ZPages handler
+		oc.ZPages(g, logger)
+	}
+	{
+		// deliver our queued webhook notifications
+		ctx, cancel := context.WithCancel(ctx)
+		g.Add(func() error {
+			return worker.Run(ctx, time.Second)
+		}, func(error) {
+			cancel()
+		})
+	}
+	{
+		// set-up our http transport
+		listener, err := registry.Listen(webhook.ServiceName, "http")
+		if err != nil {
+			level.Error(logger).Log("exit", err)
+			os.Exit(-1)
+		}
+		var (
+			ocTracing     = kitoc.HTTPServerTrace()
+			serverOptions = []kithttp.ServerOption{ocTracing}
+			service       = httptransport.NewService(endpoints, serverOptions, logger)
+			addr          = sd.FormatInstance("http://"+listener.Addr().String(), md)
+			registrar     = reg.Registrar(webhook.ServiceName, "http", instance.String(), addr)
+			router        = http.NewServeMux()
+		)
+
+		// serve our health endpoint next to our endpoints
+		router.Handle(health.Path, health.Handler())
+		router.Handle("/", service)
+
+		g.Add(func() error {
+			registrar.Register()
+			return http.Serve(
+				tlsSource.Listener(listener), tlsSource.HTTPHandler(callers, router),
+			)
+		}, func(error) {
+			registrar.Deregister()
+			listener.Close()
+		})
+	}
+	{
+		// set-up our signal handler
+		var (
+			cancelInterrupt = make(chan struct{})
+			c               = make(chan os.Signal, 2)
+		)
+		defer close(c)
+
+		g.Add(func() error {
+			signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
+			select {
+			case sig := <-c:
+				return fmt.Errorf("received signal %s", sig)
+			case <-cancelInterrupt:
+				return nil
+			}
+		}, func(error) {
+			close(cancelInterrupt)
+		})
+	}
+
+	// spawn our goroutines and wait for shutdown
+	level.Error(logger).Log("exit", g.Run())
+}
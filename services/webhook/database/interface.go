@@ -0,0 +1,75 @@
+package database
+
+import (
+	// stdlib
+	"context"
+	"errors"
+	"time"
+
+	// external
+	"github.com/kevinburke/go.uuid"
+)
+
+// Common Errors
+var (
+	ErrRepository = errors.New("unable to handle request")
+	ErrNotFound   = errors.New("subscription not found")
+)
+
+// Repository describes the resource methods needed for this service.
+type Repository interface {
+	CreateSubscription(ctx context.Context, subscription Subscription) (*uuid.UUID, error)
+	DeleteSubscription(ctx context.Context, tenantID, id uuid.UUID) error
+	ListSubscriptions(ctx context.Context, tenantID uuid.UUID) ([]*Subscription, error)
+
+	// Our delivery queue.
+	CreateDeliveries(ctx context.Context, deliveries []Delivery) error
+	PendingDeliveries(ctx context.Context, before time.Time, limit int) ([]*Delivery, error)
+	RecordAttempt(ctx context.Context, delivery Delivery, attempt Attempt) error
+}
+
+// DeliveryStatus describes the state of a delivery.
+type DeliveryStatus string
+
+// Available DeliveryStatus values
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	DeliveryFailed    DeliveryStatus = "failed"
+)
+
+// Subscription holds the details of a webhook subscription.
+type Subscription struct {
+	ID       uuid.UUID
+	TenantID uuid.UUID
+	URL      string
+	Secret   string
+	Types    []string
+}
+
+// Delivery holds a queued notification for a single subscription. URL and
+// Secret are joined in from the subscription when listing pending deliveries.
+type Delivery struct {
+	ID             uuid.UUID
+	SubscriptionID uuid.UUID
+	TenantID       uuid.UUID
+	Type           string
+	Payload        []byte
+	TraceContext   []byte
+	Status         DeliveryStatus
+	Attempts       int
+	NextAttemptAt  time.Time
+	CreatedAt      time.Time
+	URL            string
+	Secret         string
+}
+
+// Attempt holds the outcome of a single delivery attempt.
+type Attempt struct {
+	DeliveryID  uuid.UUID
+	Number      int
+	StatusCode  int
+	Error       string
+	Duration    time.Duration
+	AttemptedAt time.Time
+}
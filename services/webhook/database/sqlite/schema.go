@@ -0,0 +1,65 @@
+package sqlite
+
+import (
+	// external
+	"github.com/jmoiron/sqlx"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/shared/migrate"
+)
+
+// Schema holds the webhook schema migrations.
+var Schema = migrate.Schema{
+	Name: "ocgokitexample.webhook",
+	Migrations: []migrate.Migration{
+		{
+			Version: 1, Description: "add webhook subscriptions and deliveries",
+			Creates: "webhook_subscription", Up: v1,
+		},
+	},
+}
+
+func v1(tx *sqlx.Tx) (err error) {
+	// add webhook subscription table
+	if _, err = tx.Exec(`
+		CREATE TABLE webhook_subscription (
+			id BLOB NOT NULL, tenant_id BLOB NOT NULL, url TEXT NOT NULL,
+			secret TEXT NOT NULL, types TEXT NOT NULL, PRIMARY KEY(id)
+		) WITHOUT ROWID;`,
+	); err != nil {
+		return
+	}
+
+	// add webhook delivery queue table
+	if _, err = tx.Exec(`
+		CREATE TABLE webhook_delivery (
+			id BLOB NOT NULL, subscription_id BLOB NOT NULL,
+			tenant_id BLOB NOT NULL, type TEXT NOT NULL, payload BLOB NOT NULL,
+			trace_context BLOB, status TEXT NOT NULL, attempts INTEGER NOT NULL,
+			next_attempt_at INTEGER NOT NULL, created_at INTEGER NOT NULL,
+			PRIMARY KEY(id)
+		) WITHOUT ROWID;`,
+	); err != nil {
+		return
+	}
+
+	if _, err = tx.Exec(
+		`CREATE INDEX idx_webhook_delivery_next ON webhook_delivery (status, next_attempt_at);`,
+	); err != nil {
+		return
+	}
+
+	// add webhook delivery attempt log table
+	if _, err = tx.Exec(`
+		CREATE TABLE webhook_attempt (
+			delivery_id BLOB NOT NULL, number INTEGER NOT NULL,
+			status_code INTEGER NOT NULL, error TEXT NOT NULL,
+			duration_ms INTEGER NOT NULL, attempted_at INTEGER NOT NULL,
+			PRIMARY KEY(delivery_id, number)
+		) WITHOUT ROWID;`,
+	); err != nil {
+		return
+	}
+
+	return
+}
@@ -0,0 +1,273 @@
+package sqlite
+
+import (
+	// stdlib
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/jmoiron/sqlx"
+	"github.com/kevinburke/go.uuid"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/services/webhook/database"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/migrate"
+)
+
+type sqlite struct {
+	db     *sqlx.DB
+	logger log.Logger
+}
+
+// New returns a new Repository backed by SQLite
+func New(db *sqlx.DB, logger log.Logger) (database.Repository, error) {
+	// run our embedded database migrations
+	if err := migrate.Up(
+		context.Background(), db, migrate.SQLite, Schema, logger,
+	); err != nil {
+		return nil, err
+	}
+
+	// return our repository
+	return &sqlite{
+		db:     db,
+		logger: log.With(logger, "rep", "sqlite"),
+	}, nil
+}
+
+func (s *sqlite) CreateSubscription(
+	ctx context.Context, subscription database.Subscription,
+) (*uuid.UUID, error) {
+	// check if we need to create a new UUID
+	if uuid.Equal(subscription.ID, uuid.Nil) {
+		subscription.ID = uuid.NewV4()
+	}
+
+	if _, err := s.db.ExecContext(
+		ctx,
+		`INSERT INTO webhook_subscription (id, tenant_id, url, secret, types)
+		VALUES (?, ?, ?, ?, ?)`,
+		subscription.ID.Bytes(), subscription.TenantID.Bytes(),
+		subscription.URL, subscription.Secret,
+		strings.Join(subscription.Types, ","),
+	); err != nil {
+		level.Error(s.logger).Log("err", err)
+		return nil, database.ErrRepository
+	}
+
+	return &subscription.ID, nil
+}
+
+func (s *sqlite) DeleteSubscription(ctx context.Context, tenantID, id uuid.UUID) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		level.Error(s.logger).Log("err", err)
+		return database.ErrRepository
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(
+		ctx,
+		`DELETE FROM webhook_subscription WHERE id = ? AND tenant_id = ?`,
+		id.Bytes(), tenantID.Bytes(),
+	)
+	if err != nil {
+		level.Error(s.logger).Log("err", err)
+		return database.ErrRepository
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return database.ErrNotFound
+	}
+
+	// pending deliveries for the removed subscription can be dropped
+	if _, err = tx.ExecContext(
+		ctx,
+		`DELETE FROM webhook_delivery WHERE subscription_id = ? AND status = ?`,
+		id.Bytes(), database.DeliveryPending,
+	); err != nil {
+		level.Error(s.logger).Log("err", err)
+		return database.ErrRepository
+	}
+
+	if err = tx.Commit(); err != nil {
+		level.Error(s.logger).Log("err", err)
+		return database.ErrRepository
+	}
+	return nil
+}
+
+func (s *sqlite) ListSubscriptions(
+	ctx context.Context, tenantID uuid.UUID,
+) ([]*database.Subscription, error) {
+	rows, err := s.db.QueryContext(
+		ctx,
+		`SELECT id, tenant_id, url, secret, types FROM webhook_subscription
+		WHERE tenant_id = ? ORDER BY url`,
+		tenantID.Bytes(),
+	)
+	if err != nil {
+		level.Error(s.logger).Log("err", err)
+		return nil, database.ErrRepository
+	}
+	defer rows.Close()
+
+	var subscriptions []*database.Subscription
+	for rows.Next() {
+		var (
+			subscription database.Subscription
+			types        string
+		)
+		if err = rows.Scan(
+			&subscription.ID, &subscription.TenantID, &subscription.URL,
+			&subscription.Secret, &types,
+		); err != nil {
+			level.Error(s.logger).Log("err", err)
+			return nil, database.ErrRepository
+		}
+		subscription.Types = strings.Split(types, ",")
+		subscriptions = append(subscriptions, &subscription)
+	}
+	if err = rows.Err(); err != nil {
+		level.Error(s.logger).Log("err", err)
+		return nil, database.ErrRepository
+	}
+
+	return subscriptions, nil
+}
+
+func (s *sqlite) CreateDeliveries(ctx context.Context, deliveries []database.Delivery) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		level.Error(s.logger).Log("err", err)
+		return database.ErrRepository
+	}
+	defer tx.Rollback()
+
+	for _, d := range deliveries {
+		if uuid.Equal(d.ID, uuid.Nil) {
+			d.ID = uuid.NewV4()
+		}
+		if _, err = tx.ExecContext(
+			ctx,
+			`INSERT INTO webhook_delivery (
+				id, subscription_id, tenant_id, type, payload, trace_context,
+				status, attempts, next_attempt_at, created_at
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			d.ID.Bytes(), d.SubscriptionID.Bytes(), d.TenantID.Bytes(), d.Type,
+			d.Payload, d.TraceContext, database.DeliveryPending, 0,
+			d.NextAttemptAt.UnixNano(), d.CreatedAt.UnixNano(),
+		); err != nil {
+			level.Error(s.logger).Log("err", err)
+			return database.ErrRepository
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		level.Error(s.logger).Log("err", err)
+		return database.ErrRepository
+	}
+	return nil
+}
+
+func (s *sqlite) PendingDeliveries(
+	ctx context.Context, before time.Time, limit int,
+) ([]*database.Delivery, error) {
+	rows, err := s.db.QueryContext(
+		ctx,
+		`SELECT d.id, d.subscription_id, d.tenant_id, d.type, d.payload,
+			d.trace_context, d.status, d.attempts, d.next_attempt_at,
+			d.created_at, s.url, s.secret
+		FROM webhook_delivery d
+		INNER JOIN webhook_subscription s ON s.id = d.subscription_id
+		WHERE d.status = ? AND d.next_attempt_at <= ?
+		ORDER BY d.next_attempt_at LIMIT ?`,
+		database.DeliveryPending, before.UnixNano(), limit,
+	)
+	if err != nil {
+		level.Error(s.logger).Log("err", err)
+		return nil, database.ErrRepository
+	}
+	defer rows.Close()
+
+	var deliveries []*database.Delivery
+	for rows.Next() {
+		var (
+			d                     database.Delivery
+			nextAttempt, created  int64
+			traceContext, payload []byte
+		)
+		if err = rows.Scan(
+			&d.ID, &d.SubscriptionID, &d.TenantID, &d.Type, &payload,
+			&traceContext, &d.Status, &d.Attempts, &nextAttempt, &created,
+			&d.URL, &d.Secret,
+		); err != nil {
+			level.Error(s.logger).Log("err", err)
+			return nil, database.ErrRepository
+		}
+		d.Payload = payload
+		d.TraceContext = traceContext
+		d.NextAttemptAt = time.Unix(0, nextAttempt)
+		d.CreatedAt = time.Unix(0, created)
+		deliveries = append(deliveries, &d)
+	}
+	if err = rows.Err(); err != nil {
+		level.Error(s.logger).Log("err", err)
+		return nil, database.ErrRepository
+	}
+
+	return deliveries, nil
+}
+
+func (s *sqlite) RecordAttempt(
+	ctx context.Context, delivery database.Delivery, attempt database.Attempt,
+) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		level.Error(s.logger).Log("err", err)
+		return database.ErrRepository
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(
+		ctx,
+		`INSERT INTO webhook_attempt (
+			delivery_id, number, status_code, error, duration_ms, attempted_at
+		) VALUES (?, ?, ?, ?, ?, ?)`,
+		attempt.DeliveryID.Bytes(), attempt.Number, attempt.StatusCode,
+		attempt.Error, int64(attempt.Duration/time.Millisecond),
+		attempt.AttemptedAt.UnixNano(),
+	); err != nil {
+		level.Error(s.logger).Log("err", err)
+		return database.ErrRepository
+	}
+
+	var res sql.Result
+	if res, err = tx.ExecContext(
+		ctx,
+		`UPDATE webhook_delivery SET status = ?, attempts = ?, next_attempt_at = ?
+		WHERE id = ?`,
+		delivery.Status, delivery.Attempts, delivery.NextAttemptAt.UnixNano(),
+		delivery.ID.Bytes(),
+	); err != nil {
+		level.Error(s.logger).Log("err", err)
+		return database.ErrRepository
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return database.ErrNotFound
+	}
+
+	if err = tx.Commit(); err != nil {
+		level.Error(s.logger).Log("err", err)
+		return database.ErrRepository
+	}
+	return nil
+}
+
+// Close implements io.Closer
+func (s *sqlite) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,285 @@
+// Package delivery implements the worker POSTing queued webhook notifications
+// to their subscribers.
+package delivery
+
+import (
+	// stdlib
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"go.opencensus.io/plugin/ochttp"
+	"go.opencensus.io/plugin/ochttp/propagation/b3"
+	"go.opencensus.io/trace"
+	"go.opencensus.io/trace/propagation"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/services/webhook"
+	"github.com/basvanbeek/opencensus-gokit-example/services/webhook/database"
+)
+
+// Worker defaults
+const (
+	DefaultMaxAttempts = 8
+	DefaultBaseBackoff = 5 * time.Second
+	DefaultMaxBackoff  = time.Hour
+	DefaultBatchSize   = 50
+	DefaultTimeout     = 10 * time.Second
+)
+
+// ErrPrivateAddress is returned when dialing webhook hosts which resolve to
+// addresses we don't deliver to.
+var ErrPrivateAddress = errors.New("webhook host resolves to a non public address")
+
+// Worker delivers pending webhook notifications. Failed deliveries are
+// retried with exponential backoff until the maximum amount of attempts is
+// reached. Every attempt is recorded in the repository.
+type Worker struct {
+	repository  database.Repository
+	client      *http.Client
+	logger      log.Logger
+	maxAttempts int
+	baseBackoff time.Duration
+	maxBackoff  time.Duration
+	batchSize   int
+	timeNow     func() time.Time
+}
+
+// Option allows control of Worker behavior.
+type Option func(*Worker)
+
+// MaxAttempts sets the amount of attempts before a delivery is marked failed.
+func MaxAttempts(n int) Option {
+	return func(w *Worker) { w.maxAttempts = n }
+}
+
+// Backoff sets the initial and maximum delay between delivery attempts. The
+// delay doubles after each failed attempt.
+func Backoff(base, max time.Duration) Option {
+	return func(w *Worker) {
+		w.baseBackoff = base
+		w.maxBackoff = max
+	}
+}
+
+// BatchSize sets the maximum amount of deliveries handled per pass.
+func BatchSize(n int) Option {
+	return func(w *Worker) { w.batchSize = n }
+}
+
+// HTTPClient replaces the HTTP client used for deliveries, including its check
+// of the dialed addresses. It allows tests to deliver to local servers.
+func HTTPClient(client *http.Client) Option {
+	return func(w *Worker) { w.client = client }
+}
+
+// NewWorker returns a new delivery Worker.
+func NewWorker(rep database.Repository, logger log.Logger, options ...Option) *Worker {
+	w := &Worker{
+		repository:  rep,
+		client:      newClient(),
+		logger:      log.With(logger, "component", "delivery"),
+		maxAttempts: DefaultMaxAttempts,
+		baseBackoff: DefaultBaseBackoff,
+		maxBackoff:  DefaultMaxBackoff,
+		batchSize:   DefaultBatchSize,
+		timeNow:     time.Now,
+	}
+	for _, option := range options {
+		option(w)
+	}
+	return w
+}
+
+// Run delivers pending notifications every interval until the provided
+// context is canceled.
+func (w *Worker) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := w.DeliverPending(ctx); err != nil {
+			level.Warn(w.logger).Log("msg", "delivery pass failed", "err", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeliverPending attempts all deliveries which are due and returns the
+// amount of attempts made. If an attempt can't be recorded the pass stops,
+// the returned amount then includes the unrecorded attempt.
+func (w *Worker) DeliverPending(ctx context.Context) (int, error) {
+	deliveries, err := w.repository.PendingDeliveries(ctx, w.timeNow(), w.batchSize)
+	if err != nil {
+		return 0, err
+	}
+	for i, d := range deliveries {
+		if err = w.Deliver(ctx, d); err != nil {
+			return i + 1, err
+		}
+	}
+	return len(deliveries), nil
+}
+
+// Deliver makes a single delivery attempt and records its outcome. The
+// returned error only reflects failures to record the attempt; failed POSTs
+// are rescheduled.
+func (w *Worker) Deliver(ctx context.Context, d *database.Delivery) error {
+	var (
+		span  *trace.Span
+		name  = "webhook.Deliver"
+		attrs = []trace.Attribute{
+			trace.StringAttribute("webhook.type", d.Type),
+			trace.StringAttribute("webhook.delivery", d.ID.String()),
+			trace.Int64Attribute("webhook.attempt", int64(d.Attempts+1)),
+		}
+	)
+	// continue the trace of the publisher if we have its span context
+	if parent, ok := propagation.FromBinary(d.TraceContext); ok {
+		ctx, span = trace.StartSpanWithRemoteParent(ctx, name, parent)
+	} else {
+		ctx, span = trace.StartSpan(ctx, name)
+	}
+	defer span.End()
+	span.AddAttributes(attrs...)
+
+	start := w.timeNow()
+	statusCode, err := w.post(ctx, span, d)
+	attempt := database.Attempt{
+		DeliveryID:  d.ID,
+		Number:      d.Attempts + 1,
+		StatusCode:  statusCode,
+		Duration:    w.timeNow().Sub(start),
+		AttemptedAt: start,
+	}
+
+	d.Attempts++
+	switch {
+	case err == nil:
+		d.Status = database.DeliverySucceeded
+	case d.Attempts >= w.maxAttempts:
+		attempt.Error = err.Error()
+		d.Status = database.DeliveryFailed
+	default:
+		attempt.Error = err.Error()
+		d.NextAttemptAt = start.Add(w.backoff(d.Attempts))
+	}
+	if err != nil {
+		span.SetStatus(trace.Status{Code: trace.StatusCodeUnavailable, Message: err.Error()})
+		level.Debug(w.logger).Log(
+			"delivery", d.ID, "attempt", attempt.Number, "status", d.Status,
+			"err", err,
+		)
+	}
+
+	return w.repository.RecordAttempt(ctx, *d, attempt)
+}
+
+func (w *Worker) post(ctx context.Context, span *trace.Span, d *database.Delivery) (int, error) {
+	timestamp := w.timeNow().Unix()
+	body, err := json.Marshal(webhook.Message{
+		ID:        d.ID,
+		TenantID:  d.TenantID,
+		Type:      d.Type,
+		CreatedAt: d.CreatedAt.Unix(),
+		Payload:   json.RawMessage(d.Payload),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequest("POST", d.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set(webhook.HeaderDelivery, d.ID.String())
+	req.Header.Set(webhook.HeaderType, d.Type)
+	req.Header.Set(webhook.HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(webhook.HeaderSignature, webhook.Sign(d.Secret, timestamp, body))
+
+	// propagate our trace context regardless of the HTTP client's transport
+	(&b3.HTTPFormat{}).SpanContextToRequest(span.SpanContext(), req)
+
+	res, err := w.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	io.Copy(ioutil.Discard, io.LimitReader(res.Body, 4096))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("unexpected status code %d", res.StatusCode)
+	}
+	return res.StatusCode, nil
+}
+
+func (w *Worker) backoff(attempts int) time.Duration {
+	d := w.baseBackoff
+	for i := 1; i < attempts; i++ {
+		d *= 2
+		if d >= w.maxBackoff {
+			return w.maxBackoff
+		}
+	}
+	return d
+}
+
+// newClient returns the HTTP client for deliveries. Deliveries are made to
+// external systems, so we don't follow redirects, use proxies or wait forever.
+// The dialer checks the resolved address of each connection, so hosts passing
+// the check at subscription can't point their DNS records to our
+// infrastructure later.
+func newClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   DefaultTimeout,
+		KeepAlive: 30 * time.Second,
+		Control:   publicOnly,
+	}
+	return &http.Client{
+		Transport: &ochttp.Transport{
+			Base: &http.Transport{
+				DialContext:           dialer.DialContext,
+				MaxIdleConns:          100,
+				IdleConnTimeout:       90 * time.Second,
+				TLSHandshakeTimeout:   DefaultTimeout,
+				ExpectContinueTimeout: time.Second,
+			},
+		},
+		Timeout: DefaultTimeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// publicOnly is a net.Dialer Control function refusing connections to
+// addresses which are not public.
+func publicOnly(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if !webhook.PublicIP(net.ParseIP(host)) {
+		return ErrPrivateAddress
+	}
+	return nil
+}
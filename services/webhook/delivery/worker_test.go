@@ -0,0 +1,226 @@
+package delivery
+
+import (
+	// stdlib
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/kevinburke/go.uuid"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/services/webhook"
+	"github.com/basvanbeek/opencensus-gokit-example/services/webhook/database"
+)
+
+// recorder is a Repository only recording delivery attempts. It fails to
+// record attempts once it holds max attempts, if set.
+type recorder struct {
+	database.Repository
+	pending    []*database.Delivery
+	max        int
+	deliveries []database.Delivery
+	attempts   []database.Attempt
+}
+
+func (r *recorder) PendingDeliveries(
+	context.Context, time.Time, int,
+) ([]*database.Delivery, error) {
+	return r.pending, nil
+}
+
+func (r *recorder) RecordAttempt(
+	_ context.Context, delivery database.Delivery, attempt database.Attempt,
+) error {
+	if r.max > 0 && len(r.attempts) == r.max {
+		return errors.New("database is locked")
+	}
+	r.deliveries = append(r.deliveries, delivery)
+	r.attempts = append(r.attempts, attempt)
+	return nil
+}
+
+// receiver is a subscriber endpoint verifying the signature of deliveries the
+// way we document for our tenants.
+func receiver(t *testing.T, secret string, messages chan<- webhook.Message) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		timestamp, err := strconv.ParseInt(r.Header.Get(webhook.HeaderTimestamp), 10, 64)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if !webhook.Verify(secret, timestamp, body, r.Header.Get(webhook.HeaderSignature)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var msg webhook.Message
+		if err = json.Unmarshal(body, &msg); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if r.Header.Get(webhook.HeaderDelivery) != msg.ID.String() ||
+			r.Header.Get(webhook.HeaderType) != msg.Type {
+			t.Errorf("delivery headers do not match message %+v", msg)
+		}
+		messages <- msg
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func TestDeliverSigned(t *testing.T) {
+	messages := make(chan webhook.Message, 1)
+	srv := httptest.NewServer(receiver(t, "s3cr3t", messages))
+	defer srv.Close()
+
+	d := &database.Delivery{
+		ID:        uuid.NewV4(),
+		TenantID:  uuid.NewV4(),
+		Type:      "event.created",
+		Payload:   []byte(`{"name":"launch"}`),
+		Status:    database.DeliveryPending,
+		CreatedAt: time.Now(),
+		URL:       srv.URL,
+		Secret:    "s3cr3t",
+	}
+	rec := &recorder{}
+	w := NewWorker(rec, log.NewNopLogger(), HTTPClient(srv.Client()))
+
+	if err := w.Deliver(context.Background(), d); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	select {
+	case msg := <-messages:
+		if !uuid.Equal(msg.ID, d.ID) || !uuid.Equal(msg.TenantID, d.TenantID) {
+			t.Errorf("want delivery %s of tenant %s, have %s of %s", d.ID, d.TenantID, msg.ID, msg.TenantID)
+		}
+		if string(msg.Payload) != string(d.Payload) {
+			t.Errorf("want payload %s, have %s", d.Payload, msg.Payload)
+		}
+	default:
+		t.Fatal("receiver did not accept the delivery")
+	}
+	if want, have := database.DeliverySucceeded, rec.deliveries[0].Status; want != have {
+		t.Errorf("want status %s, have %s", want, have)
+	}
+	if want, have := http.StatusNoContent, rec.attempts[0].StatusCode; want != have {
+		t.Errorf("want status code %d, have %d", want, have)
+	}
+}
+
+func TestDeliverRejectedSignature(t *testing.T) {
+	messages := make(chan webhook.Message, 1)
+	srv := httptest.NewServer(receiver(t, "s3cr3t", messages))
+	defer srv.Close()
+
+	d := &database.Delivery{
+		ID:        uuid.NewV4(),
+		TenantID:  uuid.NewV4(),
+		Type:      "event.created",
+		Payload:   []byte(`{}`),
+		Status:    database.DeliveryPending,
+		CreatedAt: time.Now(),
+		URL:       srv.URL,
+		Secret:    "rotated",
+	}
+	rec := &recorder{}
+	w := NewWorker(rec, log.NewNopLogger(), HTTPClient(srv.Client()), Backoff(time.Second, time.Minute))
+
+	if err := w.Deliver(context.Background(), d); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(messages) != 0 {
+		t.Fatal("receiver accepted a delivery signed with the wrong secret")
+	}
+	if want, have := database.DeliveryPending, rec.deliveries[0].Status; want != have {
+		t.Errorf("want status %s, have %s", want, have)
+	}
+	if want, have := http.StatusUnauthorized, rec.attempts[0].StatusCode; want != have {
+		t.Errorf("want status code %d, have %d", want, have)
+	}
+	if rec.attempts[0].Error == "" {
+		t.Error("want the failed attempt to record an error")
+	}
+}
+
+func TestDeliverPrivateAddress(t *testing.T) {
+	messages := make(chan webhook.Message, 1)
+	srv := httptest.NewServer(receiver(t, "s3cr3t", messages))
+	defer srv.Close()
+
+	d := &database.Delivery{
+		ID:        uuid.NewV4(),
+		TenantID:  uuid.NewV4(),
+		Type:      "event.created",
+		Payload:   []byte(`{}`),
+		Status:    database.DeliveryPending,
+		CreatedAt: time.Now(),
+		URL:       srv.URL,
+		Secret:    "s3cr3t",
+	}
+	rec := &recorder{}
+	// our default client refuses to dial the loopback address of srv
+	w := NewWorker(rec, log.NewNopLogger(), Backoff(time.Second, time.Minute))
+
+	if err := w.Deliver(context.Background(), d); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(messages) != 0 {
+		t.Fatal("receiver accepted a delivery to a private address")
+	}
+	if want, have := database.DeliveryPending, rec.deliveries[0].Status; want != have {
+		t.Errorf("want status %s, have %s", want, have)
+	}
+	if want, have := ErrPrivateAddress.Error(), rec.attempts[0].Error; !strings.Contains(have, want) {
+		t.Errorf("want error %q, have %q", want, have)
+	}
+}
+
+func TestDeliverPendingRecordFailure(t *testing.T) {
+	messages := make(chan webhook.Message, 3)
+	srv := httptest.NewServer(receiver(t, "s3cr3t", messages))
+	defer srv.Close()
+
+	rec := &recorder{max: 1}
+	for i := 0; i < 3; i++ {
+		rec.pending = append(rec.pending, &database.Delivery{
+			ID:        uuid.NewV4(),
+			TenantID:  uuid.NewV4(),
+			Type:      "event.created",
+			Payload:   []byte(`{}`),
+			Status:    database.DeliveryPending,
+			CreatedAt: time.Now(),
+			URL:       srv.URL,
+			Secret:    "s3cr3t",
+		})
+	}
+	w := NewWorker(rec, log.NewNopLogger(), HTTPClient(srv.Client()))
+
+	// the pass stops at the attempt failing to be recorded
+	n, err := w.DeliverPending(context.Background())
+	if err == nil {
+		t.Fatal("want error recording the second attempt")
+	}
+	if want, have := 2, n; want != have {
+		t.Errorf("want %d attempts, have %d", want, have)
+	}
+	if want, have := 2, len(messages); want != have {
+		t.Errorf("want %d deliveries received, have %d", want, have)
+	}
+}
@@ -0,0 +1,218 @@
+package implementation
+
+import (
+	// stdlib
+	"context"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/kevinburke/go.uuid"
+	"go.opencensus.io/trace"
+	"go.opencensus.io/trace/propagation"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/services/webhook"
+	"github.com/basvanbeek/opencensus-gokit-example/services/webhook/database"
+)
+
+// service implements webhook.Service
+type service struct {
+	repository database.Repository
+	resolver   *net.Resolver
+	logger     log.Logger
+}
+
+// NewService creates and returns a new Webhook service instance
+func NewService(rep database.Repository, logger log.Logger) webhook.Service {
+	return &service{
+		repository: rep,
+		resolver:   net.DefaultResolver,
+		logger:     logger,
+	}
+}
+
+func (s *service) Subscribe(
+	ctx context.Context, tenantID uuid.UUID, sub webhook.Subscription,
+) (*uuid.UUID, error) {
+	logger := log.With(s.logger, "method", "Subscribe")
+
+	if uuid.Equal(tenantID, uuid.Nil) {
+		return nil, webhook.ErrRequireTenantID
+	}
+	sub.URL = strings.Trim(sub.URL, "\r\n\t ")
+	if sub.URL == "" {
+		return nil, webhook.ErrRequireURL
+	}
+	u, err := url.Parse(sub.URL)
+	if err != nil || u.Hostname() == "" ||
+		(u.Scheme != "http" && u.Scheme != "https") {
+		return nil, webhook.ErrInvalidURL
+	}
+	if err = s.publicHost(ctx, logger, u.Hostname()); err != nil {
+		return nil, err
+	}
+	if sub.Secret == "" {
+		return nil, webhook.ErrRequireSecret
+	}
+	if len(sub.Types) == 0 {
+		// no explicit selection means all notification types
+		sub.Types = webhook.Types
+	}
+	for _, t := range sub.Types {
+		if !validType(t) {
+			return nil, webhook.ErrInvalidType
+		}
+	}
+
+	id, err := s.repository.CreateSubscription(ctx, database.Subscription{
+		TenantID: tenantID,
+		URL:      sub.URL,
+		Secret:   sub.Secret,
+		Types:    sub.Types,
+	})
+	if err != nil {
+		level.Error(logger).Log("err", err)
+		return nil, webhook.ErrService
+	}
+	return id, nil
+}
+
+func (s *service) Unsubscribe(ctx context.Context, tenantID, id uuid.UUID) error {
+	logger := log.With(s.logger, "method", "Unsubscribe")
+
+	err := s.repository.DeleteSubscription(ctx, tenantID, id)
+	switch err {
+	case nil:
+		return nil
+	case database.ErrNotFound:
+		level.Debug(logger).Log("err", err)
+		return webhook.ErrNotFound
+	default:
+		level.Error(logger).Log("err", err)
+		return webhook.ErrService
+	}
+}
+
+func (s *service) Subscriptions(
+	ctx context.Context, tenantID uuid.UUID,
+) ([]*webhook.Subscription, error) {
+	logger := log.With(s.logger, "method", "Subscriptions")
+
+	dbSubscriptions, err := s.repository.ListSubscriptions(ctx, tenantID)
+	if err != nil {
+		level.Error(logger).Log("err", err)
+		return nil, webhook.ErrService
+	}
+	subscriptions := make([]*webhook.Subscription, 0, len(dbSubscriptions))
+	for _, sub := range dbSubscriptions {
+		// secrets are write only
+		subscriptions = append(subscriptions, &webhook.Subscription{
+			ID:    sub.ID,
+			URL:   sub.URL,
+			Types: sub.Types,
+		})
+	}
+	return subscriptions, nil
+}
+
+func (s *service) Publish(
+	ctx context.Context, tenantID uuid.UUID, n webhook.Notification,
+) error {
+	logger := log.With(s.logger, "method", "Publish")
+
+	if uuid.Equal(tenantID, uuid.Nil) {
+		return webhook.ErrRequireTenantID
+	}
+	if n.Type == "" {
+		return webhook.ErrRequireNotifyType
+	}
+	if !validType(n.Type) {
+		return webhook.ErrInvalidType
+	}
+
+	subscriptions, err := s.repository.ListSubscriptions(ctx, tenantID)
+	if err != nil {
+		level.Error(logger).Log("err", err)
+		return webhook.ErrService
+	}
+
+	// store the publisher's span context so the delivery worker can continue
+	// the trace when it eventually POSTs the notification.
+	var (
+		now          = time.Now()
+		traceContext []byte
+		deliveries   []database.Delivery
+	)
+	if span := trace.FromContext(ctx); span != nil {
+		traceContext = propagation.Binary(span.SpanContext())
+	}
+	for _, sub := range subscriptions {
+		if !subscribed(sub.Types, n.Type) {
+			continue
+		}
+		deliveries = append(deliveries, database.Delivery{
+			ID:             uuid.NewV4(),
+			SubscriptionID: sub.ID,
+			TenantID:       tenantID,
+			Type:           n.Type,
+			Payload:        n.Payload,
+			TraceContext:   traceContext,
+			NextAttemptAt:  now,
+			CreatedAt:      now,
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	if err = s.repository.CreateDeliveries(ctx, deliveries); err != nil {
+		level.Error(logger).Log("err", err)
+		return webhook.ErrService
+	}
+	return nil
+}
+
+// publicHost returns an error if host does not resolve to public addresses
+// only. The delivery worker checks the addresses again when dialing, as the
+// DNS records of the host can change after subscribing.
+func (s *service) publicHost(ctx context.Context, logger log.Logger, host string) error {
+	addrs, err := s.resolver.LookupIPAddr(ctx, host)
+	if dnsErr, ok := err.(*net.DNSError); ok && dnsErr.IsNotFound {
+		level.Debug(logger).Log("host", host, "err", err)
+		return webhook.ErrInvalidURL
+	}
+	if err != nil {
+		level.Error(logger).Log("host", host, "err", err)
+		return webhook.ErrService
+	}
+	for _, addr := range addrs {
+		if !webhook.PublicIP(addr.IP) {
+			level.Debug(logger).Log("host", host, "addr", addr.IP, "err", webhook.ErrPrivateURL)
+			return webhook.ErrPrivateURL
+		}
+	}
+	return nil
+}
+
+func validType(t string) bool {
+	for _, available := range webhook.Types {
+		if t == available {
+			return true
+		}
+	}
+	return false
+}
+
+func subscribed(types []string, t string) bool {
+	for _, subscribed := range types {
+		if subscribed == t {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package implementation
+
+import (
+	// stdlib
+	"context"
+	"testing"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/kevinburke/go.uuid"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/services/webhook"
+	"github.com/basvanbeek/opencensus-gokit-example/services/webhook/database"
+)
+
+// repository is a Repository only creating subscriptions.
+type repository struct {
+	database.Repository
+}
+
+func (repository) CreateSubscription(
+	context.Context, database.Subscription,
+) (*uuid.UUID, error) {
+	id := uuid.NewV4()
+	return &id, nil
+}
+
+func TestSubscribeURL(t *testing.T) {
+	svc := NewService(repository{}, log.NewNopLogger())
+	for url, want := range map[string]error{
+		"https://93.184.216.34/hook":         nil,
+		"https://[2606:2800:220:1::1]/hook":  nil,
+		"ftp://93.184.216.34/hook":           webhook.ErrInvalidURL,
+		"https:///hook":                      webhook.ErrInvalidURL,
+		"https://:443/hook":                  webhook.ErrInvalidURL,
+		"http://127.0.0.1:8080/hook":         webhook.ErrPrivateURL,
+		"http://localhost/hook":              webhook.ErrPrivateURL,
+		"http://[::1]/hook":                  webhook.ErrPrivateURL,
+		"http://169.254.169.254/latest/meta": webhook.ErrPrivateURL,
+		"http://[fe80::1%25eth0]/hook":       webhook.ErrPrivateURL,
+		"http://10.0.0.1/hook":               webhook.ErrPrivateURL,
+		"http://0.0.0.0/hook":                webhook.ErrPrivateURL,
+	} {
+		_, have := svc.Subscribe(context.Background(), uuid.NewV4(), webhook.Subscription{
+			URL:    url,
+			Secret: "s3cr3t",
+		})
+		if want != have {
+			t.Errorf("%s: want %v, have %v", url, want, have)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package webhook
+
+import (
+	// stdlib
+	"context"
+	"encoding/json"
+
+	// external
+	"github.com/kevinburke/go.uuid"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/shared/errcode"
+)
+
+// ServiceName of this service.
+const ServiceName = "webhook"
+
+// Service describes our Webhook service.
+type Service interface {
+	Subscribe(ctx context.Context, tenantID uuid.UUID, subscription Subscription) (*uuid.UUID, error)
+	Unsubscribe(ctx context.Context, tenantID, id uuid.UUID) error
+	Subscriptions(ctx context.Context, tenantID uuid.UUID) ([]*Subscription, error)
+
+	Publisher
+}
+
+// Publisher describes the method used by services to emit notifications to
+// the webhook subscriptions of a tenant.
+type Publisher interface {
+	Publish(ctx context.Context, tenantID uuid.UUID, notification Notification) error
+}
+
+// Notification types available for subscription.
+const (
+	EventCreated   = "event.created"
+	EventUpdated   = "event.updated"
+	EventDeleted   = "event.deleted"
+	DeviceUnlocked = "device.unlocked"
+)
+
+// Types holds all notification types available for subscription.
+var Types = []string{EventCreated, EventUpdated, EventDeleted, DeviceUnlocked}
+
+// Webhook Service Error descriptions
+const (
+	ErrorService           = "internal service error"
+	ErrorRequireURL        = "missing required webhook url"
+	ErrorInvalidURL        = "invalid webhook url"
+	ErrorPrivateURL        = "webhook url does not resolve to public addresses"
+	ErrorRequireSecret     = "missing required webhook secret"
+	ErrorInvalidType       = "unknown notification type"
+	ErrorNotFound          = "webhook subscription not found"
+	ErrorRequireTenantID   = "missing required tenant id"
+	ErrorRequireNotifyType = "missing required notification type"
+)
+
+// Webhook Service Errors
+var (
+	ErrService           = errcode.New("webhook.service", errcode.Internal, ErrorService)
+	ErrRequireURL        = errcode.New("webhook.require_url", errcode.InvalidArgument, ErrorRequireURL)
+	ErrInvalidURL        = errcode.New("webhook.invalid_url", errcode.InvalidArgument, ErrorInvalidURL)
+	ErrPrivateURL        = errcode.New("webhook.private_url", errcode.InvalidArgument, ErrorPrivateURL)
+	ErrRequireSecret     = errcode.New("webhook.require_secret", errcode.InvalidArgument, ErrorRequireSecret)
+	ErrInvalidType       = errcode.New("webhook.invalid_type", errcode.InvalidArgument, ErrorInvalidType)
+	ErrNotFound          = errcode.New("webhook.not_found", errcode.NotFound, ErrorNotFound)
+	ErrRequireTenantID   = errcode.New("webhook.require_tenant_id", errcode.InvalidArgument, ErrorRequireTenantID)
+	ErrRequireNotifyType = errcode.New("webhook.require_notify_type", errcode.InvalidArgument, ErrorRequireNotifyType)
+)
+
+// Subscription holds the details of a tenant's webhook subscription. The
+// secret is used to sign deliveries and is never returned once stored.
+type Subscription struct {
+	ID     uuid.UUID `json:"id"`
+	URL    string    `json:"url"`
+	Secret string    `json:"secret,omitempty"`
+	Types  []string  `json:"types"`
+}
+
+// Notification holds a notification to deliver to subscribed webhooks.
+type Notification struct {
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// Message is the JSON body POSTed to webhook subscribers.
+type Message struct {
+	ID        uuid.UUID       `json:"id"`
+	TenantID  uuid.UUID       `json:"tenant_id"`
+	Type      string          `json:"type"`
+	CreatedAt int64           `json:"created_at"`
+	Payload   json.RawMessage `json:"payload"`
+}
@@ -0,0 +1,38 @@
+package webhook
+
+import (
+	// stdlib
+	"strings"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	var (
+		secret    = "s3cr3t"
+		timestamp = int64(1546300800)
+		body      = []byte(`{"type":"event.created"}`)
+		signature = Sign(secret, timestamp, body)
+	)
+
+	for _, tc := range []struct {
+		name      string
+		secret    string
+		timestamp int64
+		body      []byte
+		signature string
+		valid     bool
+	}{
+		{"valid", secret, timestamp, body, signature, true},
+		{"wrong secret", "other", timestamp, body, signature, false},
+		{"replayed timestamp", secret, timestamp + 1, body, signature, false},
+		{"tampered body", secret, timestamp, []byte(`{"type":"event.deleted"}`), signature, false},
+		{"missing prefix", secret, timestamp, body, strings.TrimPrefix(signature, signaturePrefix), false},
+		{"empty signature", secret, timestamp, body, "", false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if have := Verify(tc.secret, tc.timestamp, tc.body, tc.signature); have != tc.valid {
+				t.Errorf("want valid %t, have %t", tc.valid, have)
+			}
+		})
+	}
+}
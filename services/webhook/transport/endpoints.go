@@ -0,0 +1,62 @@
+package transport
+
+import (
+	// stdlib
+	"context"
+
+	// external
+	"github.com/go-kit/kit/endpoint"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/services/webhook"
+)
+
+// Endpoints holds all Go kit endpoints for the service.
+type Endpoints struct {
+	Subscribe     endpoint.Endpoint
+	Unsubscribe   endpoint.Endpoint
+	Subscriptions endpoint.Endpoint
+	Publish       endpoint.Endpoint
+}
+
+// MakeEndpoints initializes all Go kit endpoints for the service.
+func MakeEndpoints(s webhook.Service) Endpoints {
+	return Endpoints{
+		Subscribe:     makeSubscribeEndpoint(s),
+		Unsubscribe:   makeUnsubscribeEndpoint(s),
+		Subscriptions: makeSubscriptionsEndpoint(s),
+		Publish:       makePublishEndpoint(s),
+	}
+}
+
+func makeSubscribeEndpoint(s webhook.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(SubscribeRequest)
+		id, err := s.Subscribe(ctx, req.TenantID, req.Subscription)
+		return SubscribeResponse{ID: id, Err: err}, nil
+	}
+}
+
+func makeUnsubscribeEndpoint(s webhook.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(UnsubscribeRequest)
+		err := s.Unsubscribe(ctx, req.TenantID, req.ID)
+		return UnsubscribeResponse{Err: err}, nil
+	}
+}
+
+func makeSubscriptionsEndpoint(s webhook.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(SubscriptionsRequest)
+		subscriptions, err := s.Subscriptions(ctx, req.TenantID)
+		return SubscriptionsResponse{Subscriptions: subscriptions, Err: err}, nil
+	}
+}
+
+func makePublishEndpoint(s webhook.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(PublishRequest)
+		err := s.Publish(ctx, req.TenantID, req.Notification)
+		return PublishResponse{Err: err}, nil
+	}
+}
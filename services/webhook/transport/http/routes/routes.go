@@ -0,0 +1,36 @@
+package routes
+
+import (
+	// external
+	"github.com/gorilla/mux"
+)
+
+// Endpoints holds all available HTTP endpoints for our service.
+type Endpoints struct {
+	Subscribe     *mux.Route
+	Unsubscribe   *mux.Route
+	Subscriptions *mux.Route
+	Publish       *mux.Route
+}
+
+// Initialize wires the HTTP endpoints to our Go kit service endpoints.
+func Initialize(router *mux.Router) Endpoints {
+	return Endpoints{
+		Subscribe: router.
+			Methods("POST").
+			Path("/subscription").
+			Name("subscribe"),
+		Unsubscribe: router.
+			Methods("DELETE").
+			Path("/subscription").
+			Name("unsubscribe"),
+		Subscriptions: router.
+			Methods("GET").
+			Path("/subscription").
+			Name("subscriptions"),
+		Publish: router.
+			Methods("POST").
+			Path("/publish").
+			Name("publish"),
+	}
+}
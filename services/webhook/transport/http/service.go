@@ -0,0 +1,104 @@
+package http
+
+import (
+	// stdlib
+	"context"
+	"encoding/json"
+	"net/http"
+
+	// external
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+	kithttp "github.com/go-kit/kit/transport/http"
+	"github.com/gorilla/mux"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/services/webhook/transport"
+	"github.com/basvanbeek/opencensus-gokit-example/services/webhook/transport/http/routes"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/problem"
+)
+
+// NewService wires our Go kit endpoints to the HTTP transport.
+func NewService(
+	svcEndpoints transport.Endpoints, options []kithttp.ServerOption,
+	logger log.Logger,
+) http.Handler {
+	// set-up router and initialize http endpoints
+	var (
+		router       = mux.NewRouter()
+		route        = routes.Initialize(router)
+		errorLogger  = kithttp.ServerErrorLogger(logger)
+		errorEncoder = kithttp.ServerErrorEncoder(problem.EncodeError)
+	)
+
+	options = append(options, errorLogger, errorEncoder)
+	router.NotFoundHandler = problem.NotFoundHandler()
+	router.MethodNotAllowedHandler = problem.MethodNotAllowedHandler()
+
+	// wire our Go kit handlers to the http endpoints
+	route.Subscribe.Handler(kithttp.NewServer(
+		svcEndpoints.Subscribe, decodeSubscribeRequest, encodeResponse,
+		options...,
+	))
+
+	route.Unsubscribe.Handler(kithttp.NewServer(
+		svcEndpoints.Unsubscribe, decodeUnsubscribeRequest, encodeResponse,
+		options...,
+	))
+
+	route.Subscriptions.Handler(kithttp.NewServer(
+		svcEndpoints.Subscriptions, decodeSubscriptionsRequest, encodeResponse,
+		options...,
+	))
+
+	route.Publish.Handler(kithttp.NewServer(
+		svcEndpoints.Publish, decodePublishRequest, encodeResponse,
+		options...,
+	))
+
+	// return our router as http handler
+	return router
+}
+
+// decode / encode functions for converting between http transport payloads and
+// Go kit request_response payloads.
+
+func decodeSubscribeRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req transport.SubscribeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, problem.InvalidField("body", err)
+	}
+	return req, nil
+}
+
+func decodeUnsubscribeRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req transport.UnsubscribeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, problem.InvalidField("body", err)
+	}
+	return req, nil
+}
+
+func decodeSubscriptionsRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req transport.SubscriptionsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, problem.InvalidField("body", err)
+	}
+	return req, nil
+}
+
+func decodePublishRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req transport.PublishRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, problem.InvalidField("body", err)
+	}
+	return req, nil
+}
+
+func encodeResponse(_ context.Context, w http.ResponseWriter, response interface{}) error {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := response.(endpoint.Failer).Failed(); err != nil {
+		return err
+	}
+	return json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,76 @@
+package transport
+
+import (
+	// external
+	"github.com/go-kit/kit/endpoint"
+	"github.com/kevinburke/go.uuid"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/services/webhook"
+)
+
+var (
+	_ endpoint.Failer = SubscribeResponse{}
+	_ endpoint.Failer = UnsubscribeResponse{}
+	_ endpoint.Failer = SubscriptionsResponse{}
+	_ endpoint.Failer = PublishResponse{}
+)
+
+// SubscribeRequest holds the request parameters for the Subscribe method.
+type SubscribeRequest struct {
+	TenantID     uuid.UUID            `json:"tenant_id"`
+	Subscription webhook.Subscription `json:"subscription"`
+}
+
+// SubscribeResponse holds the response values for the Subscribe method.
+type SubscribeResponse struct {
+	ID  *uuid.UUID `json:"id,omitempty"`
+	Err error
+}
+
+// Failed implements Failer.
+func (r SubscribeResponse) Failed() error { return r.Err }
+
+// UnsubscribeRequest holds the request parameters for the Unsubscribe method.
+type UnsubscribeRequest struct {
+	TenantID uuid.UUID `json:"tenant_id"`
+	ID       uuid.UUID `json:"id"`
+}
+
+// UnsubscribeResponse holds the response values for the Unsubscribe method.
+type UnsubscribeResponse struct {
+	Err error
+}
+
+// Failed implements Failer.
+func (r UnsubscribeResponse) Failed() error { return r.Err }
+
+// SubscriptionsRequest holds the request parameters for the Subscriptions
+// method.
+type SubscriptionsRequest struct {
+	TenantID uuid.UUID `json:"tenant_id"`
+}
+
+// SubscriptionsResponse holds the response values for the Subscriptions
+// method.
+type SubscriptionsResponse struct {
+	Subscriptions []*webhook.Subscription `json:"subscriptions,omitempty"`
+	Err           error
+}
+
+// Failed implements Failer.
+func (r SubscriptionsResponse) Failed() error { return r.Err }
+
+// PublishRequest holds the request parameters for the Publish method.
+type PublishRequest struct {
+	TenantID     uuid.UUID            `json:"tenant_id"`
+	Notification webhook.Notification `json:"notification"`
+}
+
+// PublishResponse holds the response values for the Publish method.
+type PublishResponse struct {
+	Err error
+}
+
+// Failed implements Failer.
+func (r PublishResponse) Failed() error { return r.Err }
@@ -0,0 +1,37 @@
+// Package admin serves the admin endpoints of our services, like online
+// backups and usage reports. The endpoints are not authenticated, so they are
+// served separate from our transports, by default on a dynamic loopback port.
+package admin
+
+import (
+	// stdlib
+	"net"
+	"net/http"
+	"os"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/oklog/run"
+)
+
+// Serve adds a server for the admin endpoints of router to g. It listens on
+// OCG_ADMIN_ADDR, by default a dynamic loopback port.
+func Serve(g *run.Group, router http.Handler, logger log.Logger) error {
+	addr := os.Getenv("OCG_ADMIN_ADDR")
+	if addr == "" {
+		addr = "127.0.0.1:0"
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	g.Add(func() error {
+		level.Info(logger).Log("msg", "admin started", "addr", "http://"+listener.Addr().String())
+		return http.Serve(listener, router)
+	}, func(error) {
+		listener.Close()
+	})
+	return nil
+}
@@ -0,0 +1,141 @@
+// Package errcode provides service errors identified by stable, typed codes.
+// The codes are carried by each of our transports in their native error
+// metadata, allowing clients to decode them back into the exact service error
+// values without matching on error descriptions.
+package errcode
+
+import (
+	// stdlib
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Domain identifies our services in transport error metadata.
+const Domain = "ocgokitexample"
+
+// Kind classifies errors. Transports derive their status codes from it.
+type Kind int
+
+// Available Kinds
+const (
+	Internal Kind = iota
+	InvalidArgument
+	NotFound
+	AlreadyExists
+	Unauthenticated
+	PermissionDenied
+	ResourceExhausted
+	FailedPrecondition
+	Unavailable
+)
+
+// String implements fmt.Stringer.
+func (k Kind) String() string {
+	switch k {
+	case InvalidArgument:
+		return "invalid_argument"
+	case NotFound:
+		return "not_found"
+	case AlreadyExists:
+		return "already_exists"
+	case Unauthenticated:
+		return "unauthenticated"
+	case PermissionDenied:
+		return "permission_denied"
+	case ResourceExhausted:
+		return "resource_exhausted"
+	case FailedPrecondition:
+		return "failed_precondition"
+	case Unavailable:
+		return "unavailable"
+	default:
+		return "internal"
+	}
+}
+
+// Business returns true for kinds caused by the request instead of a failing
+// service. Clients should not retry requests failing with a business error,
+// nor should these errors trip circuit breakers.
+func (k Kind) Business() bool {
+	return k != Internal && k != Unavailable
+}
+
+// Generic codes
+const (
+	CodeInternal = "internal"
+)
+
+// Error is a service error identified by its code.
+type Error struct {
+	code string
+	kind Kind
+	msg  string
+}
+
+// Error implements error.
+func (e *Error) Error() string {
+	return e.msg
+}
+
+// Code returns the code identifying the error.
+func (e *Error) Code() string {
+	return e.code
+}
+
+// Kind returns the kind of the error.
+func (e *Error) Kind() Kind {
+	return e.kind
+}
+
+var (
+	mtx      sync.RWMutex
+	registry = make(map[string]*Error)
+)
+
+// New registers and returns a service error. Codes are namespaced by service,
+// e.g. "event.not_found", and must be unique. New is to be used for package
+// level error values, it panics on duplicate codes.
+func New(code string, kind Kind, msg string) error {
+	mtx.Lock()
+	defer mtx.Unlock()
+
+	if _, ok := registry[code]; ok {
+		panic(fmt.Sprintf("errcode: duplicate code %q", code))
+	}
+	e := &Error{code: code, kind: kind, msg: msg}
+	registry[code] = e
+	return e
+}
+
+// Decode returns the registered error of code. Unknown codes, e.g. of errors
+// added to newer versions of a service, result in a new unregistered Error
+// holding the provided kind and message.
+func Decode(code string, kind Kind, msg string) error {
+	mtx.RLock()
+	defer mtx.RUnlock()
+
+	if e, ok := registry[code]; ok {
+		return e
+	}
+	if code == "" {
+		code = CodeInternal
+	}
+	return &Error{code: code, kind: kind, msg: msg}
+}
+
+// As returns the Error found in the chain of err. Errors without a code are
+// returned as Internal error.
+func As(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return &Error{code: CodeInternal, kind: Internal, msg: err.Error()}
+}
+
+// IsBusiness returns true if err is a business error.
+func IsBusiness(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.kind.Business()
+}
@@ -0,0 +1,245 @@
+package errcode
+
+import (
+	// stdlib
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	// external
+	"github.com/twitchtv/twirp"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var kinds = []Kind{
+	Internal,
+	InvalidArgument,
+	NotFound,
+	AlreadyExists,
+	Unauthenticated,
+	PermissionDenied,
+	ResourceExhausted,
+	FailedPrecondition,
+	Unavailable,
+}
+
+// testErrors holds a registered error of each kind.
+var testErrors = func() map[Kind]error {
+	errs := make(map[Kind]error, len(kinds))
+	for _, kind := range kinds {
+		errs[kind] = New("errcode_test."+kind.String(), kind, "test "+kind.String())
+	}
+	return errs
+}()
+
+// transports encode errors as their transport errors and decode them back.
+var transports = []struct {
+	name   string
+	encode func(error) error
+	decode func(error) error
+}{
+	{name: "grpc", encode: GRPCError, decode: FromGRPC},
+	{name: "twirp", encode: TwirpError, decode: FromTwirp},
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, tr := range transports {
+		for _, kind := range kinds {
+			err := testErrors[kind]
+
+			// registered errors decode into the exact error value
+			if want, have := err, tr.decode(tr.encode(err)); want != have {
+				t.Errorf("%s %s: want %v, have %v", tr.name, kind, want, have)
+			}
+
+			// as do wrapped errors
+			wrapped := fmt.Errorf("get: %w", err)
+			if want, have := err, tr.decode(tr.encode(wrapped)); want != have {
+				t.Errorf("%s %s wrapped: want %v, have %v", tr.name, kind, want, have)
+			}
+
+			// unknown codes keep their code, kind and message
+			unknown := &Error{code: "errcode_test.unknown", kind: kind, msg: "newer error"}
+			have := As(tr.decode(tr.encode(unknown)))
+			if have == unknown || *have != *unknown {
+				t.Errorf("%s %s unknown: want unregistered %+v, have %+v", tr.name, kind, *unknown, *have)
+			}
+		}
+
+		// errors without a code are Internal errors
+		have := As(tr.decode(tr.encode(errors.New("database closed"))))
+		if want := (Error{code: CodeInternal, kind: Internal, msg: "database closed"}); want != *have {
+			t.Errorf("%s plain: want %+v, have %+v", tr.name, want, *have)
+		}
+
+		if have := tr.encode(nil); have != nil {
+			t.Errorf("%s nil: want nil, have %v", tr.name, have)
+		}
+	}
+}
+
+func TestFromGRPC(t *testing.T) {
+	withInfo := func(c codes.Code, info *errdetails.ErrorInfo) error {
+		st, err := status.New(c, "message").WithDetails(info)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return st.Err()
+	}
+
+	for _, tc := range []struct {
+		name string
+		err  error
+		want error // nil: err is returned as is
+		kind Kind
+	}{
+		{name: "no status", err: errors.New("connection refused")},
+		{name: "unknown", err: status.Error(codes.Unknown, "panic")},
+		{name: "deadline exceeded", err: status.Error(codes.DeadlineExceeded, "timeout")},
+		{name: "canceled", err: status.Error(codes.Canceled, "canceled")},
+		{
+			name: "no detail",
+			err:  status.Error(codes.NotFound, "message"),
+			want: &Error{code: CodeInternal, kind: NotFound, msg: "message"},
+		},
+		{
+			name: "unmapped code",
+			err:  status.Error(codes.DataLoss, "message"),
+			want: &Error{code: CodeInternal, kind: Internal, msg: "message"},
+		},
+		{
+			name: "other domain",
+			err:  withInfo(codes.NotFound, &errdetails.ErrorInfo{Reason: "errcode_test.not_found", Domain: "example.com"}),
+			want: &Error{code: CodeInternal, kind: NotFound, msg: "message"},
+		},
+		{
+			name: "deadline exceeded with detail",
+			err:  withInfo(codes.DeadlineExceeded, &errdetails.ErrorInfo{Reason: "errcode_test.unknown", Domain: Domain}),
+			want: &Error{code: "errcode_test.unknown", kind: Internal, msg: "message"},
+		},
+	} {
+		have := FromGRPC(tc.err)
+		if tc.want == nil {
+			if tc.err != have {
+				t.Errorf("%s: want error returned as is, have %v", tc.name, have)
+			}
+			continue
+		}
+		if want, have := *tc.want.(*Error), *As(have); want != have {
+			t.Errorf("%s: want %+v, have %+v", tc.name, want, have)
+		}
+	}
+
+	// status errors are not encoded again
+	err := status.Error(codes.Aborted, "aborted")
+	if want, have := err, GRPCError(err); want != have {
+		t.Errorf("want status error returned as is, have %v", have)
+	}
+}
+
+func TestFromTwirp(t *testing.T) {
+	for _, err := range []error{
+		errors.New("connection refused"),
+		twirp.NewError(twirp.Malformed, "malformed response"),
+	} {
+		if want, have := err, FromTwirp(err); want != have {
+			t.Errorf("want %v returned as is, have %v", want, have)
+		}
+	}
+
+	err := twirp.NewError(twirp.DataLoss, "message").WithMeta(twirpMetaCode, "errcode_test.unknown")
+	want := Error{code: "errcode_test.unknown", kind: Internal, msg: "message"}
+	if have := *As(FromTwirp(err)); want != have {
+		t.Errorf("unmapped code: want %+v, have %+v", want, have)
+	}
+}
+
+func TestHTTPKind(t *testing.T) {
+	for _, kind := range kinds {
+		if want, have := kind, HTTPKind(kind.HTTPStatus()); want != have {
+			t.Errorf("%s: want kind %s, have %s", kind, want, have)
+		}
+	}
+	for status, want := range map[int]Kind{
+		http.StatusTeapot:             InvalidArgument,
+		http.StatusMethodNotAllowed:   InvalidArgument,
+		http.StatusBadGateway:         Internal,
+		http.StatusGatewayTimeout:     Internal,
+		http.StatusMovedPermanently:   Internal,
+		http.StatusServiceUnavailable: Unavailable,
+	} {
+		if have := HTTPKind(status); want != have {
+			t.Errorf("status %d: want kind %s, have %s", status, want, have)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	if want, have := testErrors[NotFound], Decode("errcode_test.not_found", Internal, "other"); want != have {
+		t.Errorf("want registered error, have %v", have)
+	}
+	want := Error{code: CodeInternal, kind: Unavailable, msg: "bad gateway"}
+	if have := *As(Decode("", Unavailable, "bad gateway")); want != have {
+		t.Errorf("want %+v, have %+v", want, have)
+	}
+}
+
+func TestNewDuplicate(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("want panic on duplicate code")
+		}
+	}()
+	New("errcode_test.not_found", NotFound, "duplicate")
+}
+
+func TestWrapUnwrap(t *testing.T) {
+	for _, kind := range kinds {
+		for _, err := range []error{testErrors[kind], fmt.Errorf("get: %w", testErrors[kind])} {
+			next := func(context.Context, interface{}) (interface{}, error) {
+				return nil, err
+			}
+
+			// business errors are moved into a failed response
+			response, wErr := Wrap()(next)(context.Background(), nil)
+			if kind.Business() {
+				if wErr != nil {
+					t.Errorf("%s: want business error wrapped, have %v", kind, wErr)
+				}
+				if want, have := err, response.(failed).Failed(); want != have {
+					t.Errorf("%s: want failed %v, have %v", kind, want, have)
+				}
+			} else if want, have := err, wErr; want != have {
+				t.Errorf("%s: want error %v, have %v", kind, want, have)
+			}
+
+			// and returned as endpoint error again
+			response, uErr := Unwrap()(Wrap()(next))(context.Background(), nil)
+			if response != nil {
+				t.Errorf("%s: want no response, have %v", kind, response)
+			}
+			if want, have := err, uErr; want != have {
+				t.Errorf("%s: want error %v, have %v", kind, want, have)
+			}
+			if !errors.Is(uErr, testErrors[kind]) {
+				t.Errorf("%s: want %v in chain of %v", kind, testErrors[kind], uErr)
+			}
+		}
+	}
+
+	// successful responses pass both middlewares
+	next := func(context.Context, interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+	response, err := Unwrap()(Wrap()(next))(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, have := "ok", response; want != have {
+		t.Errorf("want response %v, have %v", want, have)
+	}
+}
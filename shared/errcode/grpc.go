@@ -0,0 +1,68 @@
+package errcode
+
+import (
+	// external
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var grpcCodes = map[Kind]codes.Code{
+	Internal:           codes.Internal,
+	InvalidArgument:    codes.InvalidArgument,
+	NotFound:           codes.NotFound,
+	AlreadyExists:      codes.AlreadyExists,
+	Unauthenticated:    codes.Unauthenticated,
+	PermissionDenied:   codes.PermissionDenied,
+	ResourceExhausted:  codes.ResourceExhausted,
+	FailedPrecondition: codes.FailedPrecondition,
+	Unavailable:        codes.Unavailable,
+}
+
+// GRPCError returns err as gRPC status error. The status code is derived from
+// the error kind, the error code is added as ErrorInfo detail. Status errors
+// are returned as is.
+func GRPCError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	e := As(err)
+	st := status.New(grpcCodes[e.kind], e.msg)
+	if ds, dErr := st.WithDetails(&errdetails.ErrorInfo{
+		Reason: e.code, Domain: Domain,
+	}); dErr == nil {
+		st = ds
+	}
+	return st.Err()
+}
+
+// FromGRPC returns the service error of the gRPC status error err. Errors not
+// carrying a status, e.g. connection errors, are returned as is.
+func FromGRPC(err error) error {
+	st, ok := status.FromError(err)
+	if !ok || st.Code() == codes.OK {
+		return err
+	}
+
+	kind := Internal
+	for k, c := range grpcCodes {
+		if c == st.Code() {
+			kind = k
+			break
+		}
+	}
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.Domain == Domain {
+			return Decode(info.Reason, kind, st.Message())
+		}
+	}
+	if st.Code() == codes.Unknown || st.Code() == codes.DeadlineExceeded ||
+		st.Code() == codes.Canceled {
+		// not produced by our services
+		return err
+	}
+	return Decode("", kind, st.Message())
+}
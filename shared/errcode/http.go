@@ -0,0 +1,37 @@
+package errcode
+
+import (
+	// stdlib
+	"net/http"
+)
+
+var httpStatuses = map[Kind]int{
+	Internal:           http.StatusInternalServerError,
+	InvalidArgument:    http.StatusBadRequest,
+	NotFound:           http.StatusNotFound,
+	AlreadyExists:      http.StatusConflict,
+	Unauthenticated:    http.StatusUnauthorized,
+	PermissionDenied:   http.StatusForbidden,
+	ResourceExhausted:  http.StatusTooManyRequests,
+	FailedPrecondition: http.StatusPreconditionFailed,
+	Unavailable:        http.StatusServiceUnavailable,
+}
+
+// HTTPStatus returns the HTTP status of the kind.
+func (k Kind) HTTPStatus() int {
+	return httpStatuses[k]
+}
+
+// HTTPKind returns the kind matching the HTTP status. Unknown client error
+// statuses result in InvalidArgument, others in Internal.
+func HTTPKind(status int) Kind {
+	for k, s := range httpStatuses {
+		if s == status {
+			return k
+		}
+	}
+	if status >= 400 && status < 500 {
+		return InvalidArgument
+	}
+	return Internal
+}
@@ -0,0 +1,51 @@
+package errcode
+
+import (
+	// stdlib
+	"context"
+
+	// external
+	"github.com/go-kit/kit/endpoint"
+)
+
+// failed is a response carrying a business error past the circuit breaker and
+// retry middlewares.
+type failed struct {
+	err error
+}
+
+// Failed implements endpoint.Failer.
+func (f failed) Failed() error { return f.err }
+
+// Wrap moves business errors returned by a client endpoint into a failed
+// response. It is to be applied directly on the transport client endpoint, so
+// business errors do not trip circuit breakers or trigger retries.
+func Wrap() endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			response, err := next(ctx, request)
+			if err != nil && IsBusiness(err) {
+				return failed{err: err}, nil
+			}
+			return response, err
+		}
+	}
+}
+
+// Unwrap returns the errors of failed responses as endpoint error. It is to be
+// applied as outermost client middleware, so client methods only need to check
+// the endpoint error.
+func Unwrap() endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			response, err := next(ctx, request)
+			if err != nil {
+				return nil, err
+			}
+			if f, ok := response.(endpoint.Failer); ok && f.Failed() != nil {
+				return nil, f.Failed()
+			}
+			return response, nil
+		}
+	}
+}
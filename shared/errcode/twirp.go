@@ -0,0 +1,54 @@
+package errcode
+
+import (
+	// external
+	"github.com/twitchtv/twirp"
+)
+
+// twirpMetaCode is the Twirp error meta key holding the error code.
+const twirpMetaCode = Domain + "-code"
+
+var twirpCodes = map[Kind]twirp.ErrorCode{
+	Internal:           twirp.Internal,
+	InvalidArgument:    twirp.InvalidArgument,
+	NotFound:           twirp.NotFound,
+	AlreadyExists:      twirp.AlreadyExists,
+	Unauthenticated:    twirp.Unauthenticated,
+	PermissionDenied:   twirp.PermissionDenied,
+	ResourceExhausted:  twirp.ResourceExhausted,
+	FailedPrecondition: twirp.FailedPrecondition,
+	Unavailable:        twirp.Unavailable,
+}
+
+// TwirpError returns err as twirp.Error. The Twirp error code is derived from
+// the error kind, the error code is added as error meta.
+func TwirpError(err error) error {
+	if err == nil {
+		return nil
+	}
+	e := As(err)
+	return twirp.NewError(twirpCodes[e.kind], e.msg).WithMeta(twirpMetaCode, e.code)
+}
+
+// FromTwirp returns the service error of the twirp.Error err. Other errors are
+// returned as is.
+func FromTwirp(err error) error {
+	twErr, ok := err.(twirp.Error)
+	if !ok {
+		return err
+	}
+	code := twErr.Meta(twirpMetaCode)
+	if code == "" {
+		// not produced by our services, e.g. a malformed response
+		return err
+	}
+
+	kind := Internal
+	for k, c := range twirpCodes {
+		if c == twErr.Code() {
+			kind = k
+			break
+		}
+	}
+	return Decode(code, kind, twErr.Msg())
+}
@@ -0,0 +1,294 @@
+package factory
+
+import (
+	// stdlib
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	// external
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+	kitsd "github.com/go-kit/kit/sd"
+	"github.com/go-kit/kit/sd/lb"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/shared/grpcconn"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/health"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/mtls"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/oc"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/sd"
+)
+
+// Option configures the client endpoints created by our factories. Our client
+// constructors accept the options of their caller, e.g. WithRouting and
+// WithMTLS, and add a WithHealthCheck for the service they call.
+type Option func(*endpointOptions)
+
+// KeyFunc returns the consistent hashing key of a request.
+type KeyFunc func(ctx context.Context, request interface{}) string
+
+type endpointOptions struct {
+	balancer  oc.BalancerType
+	key       KeyFunc
+	sdOptions []sd.Option
+	tls       *mtls.Source
+}
+
+// WithBalancer sets the balancer used to spread requests over the discovered
+// instances. By default the power of two choices balancer is used.
+func WithBalancer(balancer oc.BalancerType) Option {
+	return func(o *endpointOptions) {
+		o.balancer = balancer
+		o.key = nil
+	}
+}
+
+// WithConsistentHash routes requests with the same key to the same instance,
+// e.g. to keep the requests of a tenant on the instance caching its data.
+// Requests without a key are spread over all instances.
+func WithConsistentHash(key KeyFunc) Option {
+	return func(o *endpointOptions) {
+		o.balancer = oc.ConsistentHash
+		o.key = key
+	}
+}
+
+// WithHealthCheck health checks the discovered instances, ejecting failing
+// instances until they recover. The name identifies the checked service on the
+// debug page.
+func WithHealthCheck(name string, check sd.HealthChecker) Option {
+	return func(o *endpointOptions) {
+		o.sdOptions = append(o.sdOptions, sd.HealthCheck(
+			name, check, health.Interval, health.Timeout,
+		))
+	}
+}
+
+// WithOutlierDetection overrides the default outlier detection settings. Our
+// client endpoints eject client instances with outlying failure rates or
+// latencies, so retries are not wasted on them. Outlier detection is not
+// supported by the random, round robin and consistent hash balancers.
+func WithOutlierDetection(settings sd.OutlierSettings) Option {
+	return func(o *endpointOptions) {
+		o.sdOptions = append(o.sdOptions, sd.OutlierDetection(settings))
+	}
+}
+
+// WithRouting applies routing options, e.g. sd.Filter, sd.PreferZone or
+// sd.VersionSplit, to the discovered instances. The version split is only
+// supported by the power of two choices and least outstanding balancers.
+func WithRouting(options ...sd.Option) Option {
+	return func(o *endpointOptions) {
+		o.sdOptions = append(o.sdOptions, options...)
+	}
+}
+
+// WithMTLS calls the discovered instances using mutual TLS with the
+// certificate provided by src. A nil src keeps our clients on plaintext.
+func WithMTLS(src *mtls.Source) Option {
+	return func(o *endpointOptions) {
+		o.tls = src
+	}
+}
+
+// NewHostMapper returns the gRPC host mapper of client endpoints created with
+// opts, dialing TLS if requested by WithMTLS.
+func NewHostMapper(opts ...Option) grpcconn.HostMapper {
+	o := newOptions(opts)
+	return grpcconn.NewHostMapper(o.tls.GRPCDialOption())
+}
+
+// HealthClient returns the HTTP client used to health check the instances of
+// client endpoints created with opts. It is not traced to keep health checks
+// out of our traces.
+func HealthClient(opts ...Option) *http.Client {
+	o := newOptions(opts)
+	return &http.Client{Transport: o.tls.HTTPTransport()}
+}
+
+// NewClientInstancer returns a client instancer for the clients created by
+// factory, applying the health checks, filters and zone preference of opts.
+// It allows transports without a Go kit client to share our options.
+func NewClientInstancer(
+	instancer kitsd.Instancer, factory sd.Factory, logger log.Logger, opts ...Option,
+) sd.ClientInstancer {
+	o := newOptions(opts)
+	return sd.NewClientInstancer(instancer, factory, logger, o.sdOptions...)
+}
+
+// NewKeyedClient returns a function picking the client instance created by
+// factory for each request by the consistent hashing key requested with
+// WithConsistentHash. It returns nil if opts don't request consistent hashing.
+// It allows transports without a Go kit client to share our options.
+func NewKeyedClient(
+	instancer kitsd.Instancer, factory sd.Factory, logger log.Logger, opts ...Option,
+) func(ctx context.Context, request interface{}) (interface{}, error) {
+	o := newOptions(opts)
+	if o.key == nil {
+		return nil
+	}
+	var (
+		balancer = sd.NewRendezvous(instancer, factory, logger, o.sdOptions...)
+		key      = requestKey(o.key)
+	)
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		return balancer.Client(key(ctx, request), 0)
+	}
+}
+
+// ContextKey is a KeyFunc returning the key stored in the request context
+// using sd.NewKeyContext.
+func ContextKey(ctx context.Context, _ interface{}) string {
+	return sd.KeyFromContext(ctx)
+}
+
+func newOptions(opts []Option) endpointOptions {
+	o := endpointOptions{
+		balancer:  oc.PowerOfTwoChoices,
+		sdOptions: []sd.Option{sd.OutlierDetection(sd.OutlierSettings{})},
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// newRetry returns an endpoint executing requests with retry and timeout logic
+// on the client endpoints created by factory, picked by the balancer of o.
+func newRetry(
+	o endpointOptions, instancer kitsd.Instancer, factory kitsd.Factory,
+	count int, timeout time.Duration,
+) endpoint.Endpoint {
+	if o.key == nil {
+		return lb.Retry(count, timeout, newBalancer(o, instancer, factory))
+	}
+
+	var (
+		balancer = sd.NewRendezvous(
+			instancer, clientFactory(factory), log.NewNopLogger(), o.sdOptions...,
+		)
+		key = requestKey(o.key)
+	)
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		retry := lb.Retry(count, timeout, &keyedBalancer{lb: balancer, key: key(ctx, request)})
+		return retry(ctx, request)
+	}
+}
+
+// requestKey returns a KeyFunc spreading the requests without a key over all
+// instances by handing out unique keys.
+func requestKey(key KeyFunc) KeyFunc {
+	var keyless uint64
+	return func(ctx context.Context, request interface{}) string {
+		if k := key(ctx, request); k != "" {
+			return k
+		}
+		return strconv.FormatUint(atomic.AddUint64(&keyless, 1), 10)
+	}
+}
+
+// newBalancer returns a Go kit balancer of the type requested by o for the
+// client endpoints created by factory.
+func newBalancer(
+	o endpointOptions, instancer kitsd.Instancer, factory kitsd.Factory,
+) lb.Balancer {
+	logger := log.NewNopLogger()
+
+	switch o.balancer {
+	case oc.Random:
+		endpointer := newEndpointer(o, instancer, factory)
+		return lb.NewRandom(endpointer, time.Now().UnixNano())
+	case oc.RoundRobin:
+		endpointer := newEndpointer(o, instancer, factory)
+		return lb.NewRoundRobin(endpointer)
+	case oc.LeastOutstanding:
+		return loadBalancer{
+			sd.NewLeastOutstanding(
+				instancer, clientFactory(factory), logger, o.sdOptions...,
+			),
+		}
+	default:
+		return loadBalancer{sd.NewPowerOfTwoChoices(
+			instancer, clientFactory(factory), time.Now().UnixNano(), logger,
+			o.sdOptions...,
+		)}
+	}
+}
+
+// newEndpointer returns a Go kit endpointer for the client endpoints created by
+// factory, applying the filters and zone preference of o.
+func newEndpointer(
+	o endpointOptions, instancer kitsd.Instancer, factory kitsd.Factory,
+) kitsd.Endpointer {
+	s := sd.NewClientInstancer(
+		instancer, clientFactory(factory), log.NewNopLogger(), o.sdOptions...,
+	)
+	return endpointer{s: s}
+}
+
+// endpointer adapts our client instancers to Go kit.
+type endpointer struct {
+	s sd.ClientInstancer
+}
+
+// Endpoints implements kitsd.Endpointer.
+func (e endpointer) Endpoints() ([]endpoint.Endpoint, error) {
+	clients, err := e.s.Clients()
+	if err != nil {
+		return nil, err
+	}
+	endpoints := make([]endpoint.Endpoint, 0, len(clients))
+	for _, client := range clients {
+		endpoints = append(endpoints, client.(endpoint.Endpoint))
+	}
+	return endpoints, nil
+}
+
+// clientFactory adapts a Go kit endpoint factory to our client factory.
+func clientFactory(factory kitsd.Factory) sd.Factory {
+	return func(instance string) (interface{}, io.Closer, error) {
+		return factory(instance)
+	}
+}
+
+// loadBalancer adapts our load balancers to Go kit. It reports the completion
+// of each request to the load balancer.
+type loadBalancer struct {
+	lb sd.LoadBalancer
+}
+
+// Endpoint implements lb.Balancer.
+func (b loadBalancer) Endpoint() (endpoint.Endpoint, error) {
+	client, done, err := b.lb.Client()
+	if err != nil {
+		return nil, err
+	}
+	next := client.(endpoint.Endpoint)
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		response, err := next(ctx, request)
+		done(err)
+		return response, err
+	}, nil
+}
+
+// keyedBalancer adapts our keyed balancers to Go kit for a single request.
+// Each retry moves on to the next preferred instance of the key.
+type keyedBalancer struct {
+	lb      sd.KeyedBalancer
+	key     string
+	attempt int
+}
+
+// Endpoint implements lb.Balancer.
+func (b *keyedBalancer) Endpoint() (endpoint.Endpoint, error) {
+	client, err := b.lb.Client(b.key, b.attempt)
+	if err != nil {
+		return nil, err
+	}
+	b.attempt++
+	return client.(endpoint.Endpoint), nil
+}
@@ -0,0 +1,56 @@
+package factory
+
+import (
+	// stdlib
+	"context"
+	"io"
+	"testing"
+
+	// external
+	"github.com/go-kit/kit/log"
+	kitsd "github.com/go-kit/kit/sd"
+)
+
+func TestNewKeyedClient(t *testing.T) {
+	var (
+		instancer = kitsd.FixedInstancer{"10.0.0.1:8000", "10.0.0.2:8000", "10.0.0.3:8000"}
+		factory   = func(instance string) (interface{}, io.Closer, error) {
+			return instance, nil, nil
+		}
+		key = func(_ context.Context, request interface{}) string {
+			return request.(string)
+		}
+	)
+
+	if pick := NewKeyedClient(instancer, factory, log.NewNopLogger()); pick != nil {
+		t.Fatal("want no keyed client without consistent hashing")
+	}
+
+	pick := NewKeyedClient(instancer, factory, log.NewNopLogger(), WithConsistentHash(key))
+	if pick == nil {
+		t.Fatal("want keyed client with consistent hashing")
+	}
+
+	first, err := pick(context.Background(), "tenant")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		if have, _ := pick(context.Background(), "tenant"); have != first {
+			t.Fatalf("want key to stay on instance %v, have %v", first, have)
+		}
+	}
+
+	// requests without a key are spread over all instances
+	instances := make(map[interface{}]bool)
+	for i := 0; i < 100; i++ {
+		client, err := pick(context.Background(), "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		instances[client] = true
+	}
+	if want, have := len(instancer), len(instances); want != have {
+		t.Errorf("want keyless requests on %d instances, have %d", want, have)
+	}
+}
@@ -2,33 +2,33 @@ package factory
 
 import (
 	// stdlib
+	"context"
 	"io"
 	"time"
 
 	// external
 	"github.com/go-kit/kit/circuitbreaker"
 	"github.com/go-kit/kit/endpoint"
-	"github.com/go-kit/kit/log"
-	"github.com/go-kit/kit/sd"
-	"github.com/go-kit/kit/sd/lb"
+	kitsd "github.com/go-kit/kit/sd"
 	kitoc "github.com/go-kit/kit/tracing/opencensus"
 	kitgrpc "github.com/go-kit/kit/transport/grpc"
 	"github.com/sony/gobreaker"
 	"go.opencensus.io/trace"
 
 	// project
-	"github.com/basvanbeek/opencensus-gokit-example/shared/errormw"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/errcode"
 	"github.com/basvanbeek/opencensus-gokit-example/shared/grpcconn"
 	"github.com/basvanbeek/opencensus-gokit-example/shared/oc"
 )
 
 // CreateGRPCEndpoint wires a QR service Go kit method endpoint
 func CreateGRPCEndpoint(
-	instancer sd.Instancer, hm grpcconn.HostMapper, service string,
+	instancer kitsd.Instancer, hm grpcconn.HostMapper, service string,
 	middleware endpoint.Middleware, method string, reply interface{},
-	enc kitgrpc.EncodeRequestFunc, dec kitgrpc.DecodeResponseFunc,
-	decError endpoint.Middleware,
+	enc kitgrpc.EncodeRequestFunc, dec kitgrpc.DecodeResponseFunc, opts ...Option,
 ) endpoint.Endpoint {
+	o := newOptions(opts)
+
 	// Set our Go kit gRPC client options
 	options := []kitgrpc.ClientOption{
 		kitoc.GRPCClientTrace(), // OpenCensus Go kit gRPC client tracing
@@ -48,10 +48,9 @@ func CreateGRPCEndpoint(
 			conn, service, method, enc, dec, reply, options...,
 		).Endpoint()
 
-		if decError != nil {
-			// we have a custom gRPC error router
-			clientEndpoint = decError(clientEndpoint)
-		}
+		// decode gRPC status errors into our service errors and keep
+		// business errors away from the circuit breaker and retry logic
+		clientEndpoint = errcode.Wrap()(decodeGRPCError(clientEndpoint))
 
 		// configure circuit breaker
 		cb := circuitbreaker.Gobreaker(
@@ -76,12 +75,6 @@ func CreateGRPCEndpoint(
 		return clientEndpoint, closer, nil
 	}
 
-	// endpointer manages list of available endpoints servicing our method
-	endpointer := sd.NewEndpointer(instancer, factory, log.NewNopLogger())
-
-	// balancer can do a round robin pick from the endpointer list
-	balancer := lb.NewRoundRobin(endpointer)
-
 	// retry uses balancer for executing a method call with retry and timeout
 	// logic so client consumer does not have to think about it.
 	var (
@@ -91,14 +84,25 @@ func CreateGRPCEndpoint(
 
 	// retry uses balancer for executing a method call with retry and
 	// timeout logic so client consumer does not have to think about it.
-	endpoint := lb.Retry(count, timeout, balancer)
+	endpoint := newRetry(o, instancer, factory, count, timeout)
 
 	// wrap our retries in an annotated parent span
-	endpoint = oc.RetryEndpoint(method, oc.RoundRobin, count, timeout)(endpoint)
+	endpoint = oc.RetryEndpoint(method, o.balancer, count, timeout)(endpoint)
 
 	// unwrap business logic errors
-	endpoint = errormw.UnwrapError(log.NewNopLogger())(endpoint)
+	endpoint = errcode.Unwrap()(endpoint)
 
 	// return our endpoint
 	return endpoint
 }
+
+// decodeGRPCError converts gRPC status errors into service errors.
+func decodeGRPCError(next endpoint.Endpoint) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		response, err := next(ctx, request)
+		if err != nil {
+			return nil, errcode.FromGRPC(err)
+		}
+		return response, nil
+	}
+}
@@ -14,9 +14,7 @@ import (
 	// external
 	"github.com/go-kit/kit/circuitbreaker"
 	"github.com/go-kit/kit/endpoint"
-	"github.com/go-kit/kit/log"
-	"github.com/go-kit/kit/sd"
-	"github.com/go-kit/kit/sd/lb"
+	kitsd "github.com/go-kit/kit/sd"
 	kitoc "github.com/go-kit/kit/tracing/opencensus"
 	kithttp "github.com/go-kit/kit/transport/http"
 	"github.com/gorilla/mux"
@@ -24,19 +22,27 @@ import (
 	"go.opencensus.io/trace"
 
 	// project
-	"github.com/basvanbeek/opencensus-gokit-example/shared/errormw"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/errcode"
 	"github.com/basvanbeek/opencensus-gokit-example/shared/oc"
 )
 
-// CreateHTTPEndpoint creates a Go kit client endpoint
+// CreateHTTPEndpoint creates a Go kit client endpoint balancing requests over
+// the instances found by instancer.
 func CreateHTTPEndpoint(
-	instancer sd.Instancer, middleware endpoint.Middleware, operationName string,
+	instancer kitsd.Instancer, middleware endpoint.Middleware, operationName string,
 	encodeRequest kithttp.EncodeRequestFunc,
-	decodeResponse kithttp.DecodeResponseFunc,
+	decodeResponse kithttp.DecodeResponseFunc, opts ...Option,
 ) endpoint.Endpoint {
+	o := newOptions(opts)
+
 	options := []kithttp.ClientOption{
 		kitoc.HTTPClientTrace(), // OpenCensus HTTP Client transport tracing
 	}
+	if o.tls != nil {
+		options = append(options, kithttp.SetClient(
+			&http.Client{Transport: o.tls.HTTPTransport()},
+		))
+	}
 
 	// factory is called each time a new instance is received from service
 	// discovery. it will create a new Go kit client endpoint which will be
@@ -55,6 +61,9 @@ func CreateHTTPEndpoint(
 			"", baseURL, encodeRequest, decodeResponse, options...,
 		).Endpoint()
 
+		// keep business errors away from the circuit breaker and retry logic
+		clientEndpoint = errcode.Wrap()(clientEndpoint)
+
 		// configure per instance circuit breaker middleware
 		cb := circuitbreaker.Gobreaker(
 			gobreaker.NewCircuitBreaker(gobreaker.Settings{
@@ -78,12 +87,6 @@ func CreateHTTPEndpoint(
 		return clientEndpoint, nil, nil
 	}
 
-	// endpoints manages the list of available endpoints servicing our method
-	endpoints := sd.NewEndpointer(instancer, factory, log.NewNopLogger())
-
-	// balancer can do a random pick from the endpoint list
-	balancer := lb.NewRandom(endpoints, time.Now().UnixNano())
-
 	var (
 		count   = 3
 		timeout = 5 * time.Second
@@ -91,13 +94,13 @@ func CreateHTTPEndpoint(
 
 	// retry uses balancer for executing a method call with retry and
 	// timeout logic so client consumer does not have to think about it.
-	endpoint := lb.Retry(count, timeout, balancer)
+	endpoint := newRetry(o, instancer, factory, count, timeout)
 
 	// wrap our retries in an annotated parent span
-	endpoint = oc.RetryEndpoint(operationName, oc.Random, count, timeout)(endpoint)
+	endpoint = oc.RetryEndpoint(operationName, o.balancer, count, timeout)(endpoint)
 
 	// unwrap business logic errors
-	endpoint = errormw.UnwrapError(log.NewNopLogger())(endpoint)
+	endpoint = errcode.Unwrap()(endpoint)
 
 	// return our endpoint
 	return endpoint
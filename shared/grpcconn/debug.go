@@ -0,0 +1,96 @@
+package grpcconn
+
+import (
+	// stdlib
+	"html/template"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DebugPath is the path our debug page is served at by the zpages handler.
+const DebugPath = "/grpcz"
+
+// mappers holds all host mappers of the process for our debug page.
+var mappers struct {
+	sync.Mutex
+	m []*hostMapper
+}
+
+// ConnState is the state of a gRPC client connection held by a HostMapper.
+type ConnState struct {
+	Instance string
+	State    string
+	Since    time.Time
+	Refs     int
+	Failures int
+}
+
+// ConnStates returns the states of the connections held by all host mappers.
+func ConnStates() []ConnState {
+	mappers.Lock()
+	defer mappers.Unlock()
+
+	var states []ConnState
+	for _, h := range mappers.m {
+		h.mtx.Lock()
+		for _, c := range h.host {
+			c.mtx.Lock()
+			states = append(states, ConnState{
+				Instance: c.instance,
+				State:    c.state.String(),
+				Since:    c.since,
+				Refs:     c.refs,
+				Failures: c.failures,
+			})
+			c.mtx.Unlock()
+		}
+		h.mtx.Unlock()
+	}
+	return states
+}
+
+var debugPage = template.Must(template.New("grpcz").Parse(`<!DOCTYPE html>
+<html>
+<head><title>gRPC connections</title></head>
+<body>
+<h1>gRPC connections</h1>
+{{if .}}
+<table border="1" cellpadding="4" cellspacing="0">
+<tr>
+<th>Instance</th><th>State</th><th>Since</th><th>References</th>
+<th>Failures</th>
+</tr>
+{{range .}}
+<tr>
+<td>{{.Instance}}</td>
+<td>{{if eq .State "READY"}}{{.State}}{{else}}<b>{{.State}}</b>{{end}}</td>
+<td>{{.Since.Format "2006-01-02 15:04:05"}}</td>
+<td>{{.Refs}}</td>
+<td>{{.Failures}}</td>
+</tr>
+{{end}}
+</table>
+{{else}}
+<p>No gRPC connections.</p>
+{{end}}
+</body>
+</html>
+`))
+
+// DebugHandler serves a page holding the state of all gRPC client connections
+// held by our host mappers.
+func DebugHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		states := ConnStates()
+		sort.Slice(states, func(i, j int) bool {
+			return states[i].Instance < states[j].Instance
+		})
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := debugPage.Execute(w, states); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
@@ -2,70 +2,159 @@ package grpcconn
 
 import (
 	// stdlib
+	"context"
 	"io"
 	"sync"
+	"time"
 
 	// external
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/connectivity"
 )
 
+// connectParams are the default reconnection settings of our connections.
+// Failed connections are retried with exponential backoff, capped at 10
+// seconds so recovered instances are picked up quickly.
+var connectParams = grpc.ConnectParams{
+	Backoff: backoff.Config{
+		BaseDelay:  time.Second,
+		Multiplier: 1.6,
+		Jitter:     0.2,
+		MaxDelay:   10 * time.Second,
+	},
+	MinConnectTimeout: 5 * time.Second,
+}
+
 // HostMapper manages a map of discovered instances with accompanying gRPC
-// client connections.
+// client connections. Connections are shared by all users of an instance and
+// reference counted: each Get must be paired with closing the returned
+// io.Closer, the connection is closed once its last user is done.
 type HostMapper interface {
 	Get(instance string) (*grpc.ClientConn, io.Closer, error)
 }
 
-// NewHostMapper initializes and returns a HostMapper for a gRPC service.
+// NewHostMapper initializes and returns a HostMapper for a gRPC service. The
+// dial options override our default reconnection backoff.
 func NewHostMapper(dialOptions ...grpc.DialOption) HostMapper {
-	return &hostMapper{
-		host:        make(map[string]*grpc.ClientConn),
-		DialOptions: dialOptions,
+	h := &hostMapper{
+		host: make(map[string]*conn),
+		DialOptions: append(
+			[]grpc.DialOption{grpc.WithConnectParams(connectParams)},
+			dialOptions...,
+		),
 	}
+	mappers.Lock()
+	mappers.m = append(mappers.m, h)
+	mappers.Unlock()
+	return h
 }
 
 // hostMapper implements HostMapper
 type hostMapper struct {
 	mtx         sync.Mutex
-	host        map[string]*grpc.ClientConn
+	host        map[string]*conn
 	DialOptions []grpc.DialOption
 }
 
+// conn is a reference counted gRPC client connection.
+type conn struct {
+	instance string
+	cc       *grpc.ClientConn
+	refs     int // guarded by the mtx of our hostMapper
+	cancel   context.CancelFunc
+
+	mtx      sync.Mutex
+	state    connectivity.State
+	since    time.Time
+	failures int
+}
+
 // Get a gRPC client connection for provided instance.
 func (h *hostMapper) Get(instance string) (*grpc.ClientConn, io.Closer, error) {
 	h.mtx.Lock()
 	defer h.mtx.Unlock()
 
-	if conn := h.host[instance]; conn != nil {
-		return conn, &closer{h, instance}, nil
+	if c := h.host[instance]; c != nil {
+		c.refs++
+		return c.cc, &closer{hm: h, c: c}, nil
 	}
 
-	conn, err := grpc.Dial(instance, h.DialOptions...)
+	cc, err := grpc.Dial(instance, h.DialOptions...)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	h.host[instance] = conn
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &conn{
+		instance: instance,
+		cc:       cc,
+		refs:     1,
+		cancel:   cancel,
+		since:    time.Now(),
+	}
+	go c.watch(ctx)
+	h.host[instance] = c
 
-	return conn, &closer{h, instance}, nil
+	return cc, &closer{hm: h, c: c}, nil
 }
 
-// remove closes a gRPC client connection and removes it from the map.
-func (h *hostMapper) remove(instance string) {
+// release drops a reference to c, closing it and removing it from the map
+// once unused.
+func (h *hostMapper) release(c *conn) {
 	h.mtx.Lock()
-	if conn := h.host[instance]; conn != nil {
-		defer conn.Close()
-		delete(h.host, instance)
+	c.refs--
+	if c.refs > 0 {
+		h.mtx.Unlock()
+		return
+	}
+	if h.host[c.instance] == c {
+		delete(h.host, c.instance)
 	}
 	h.mtx.Unlock()
+
+	c.cancel()
+	c.cc.Close()
 }
 
+// watch tracks the state of the connection until ctx is done. Connections
+// falling idle, e.g. after losing their instance, are reconnected right away
+// instead of on their next request, so they are ready once the instance
+// recovers.
+func (c *conn) watch(ctx context.Context) {
+	state := c.cc.GetState()
+	c.mtx.Lock()
+	c.state = state
+	c.mtx.Unlock()
+	for {
+		if !c.cc.WaitForStateChange(ctx, state) {
+			return
+		}
+		state = c.cc.GetState()
+
+		c.mtx.Lock()
+		c.state, c.since = state, time.Now()
+		if state == connectivity.TransientFailure {
+			c.failures++
+		}
+		c.mtx.Unlock()
+
+		if state == connectivity.Idle {
+			c.cc.Connect()
+		}
+	}
+}
+
+// closer releases a reference to a connection. Closing it more than once has
+// no effect.
 type closer struct {
-	hm       *hostMapper
-	instance string
+	hm   *hostMapper
+	c    *conn
+	once sync.Once
 }
 
 // Close implements io.Closer
 func (c *closer) Close() error {
-	c.hm.remove(c.instance)
+	c.once.Do(func() { c.hm.release(c.c) })
 	return nil
 }
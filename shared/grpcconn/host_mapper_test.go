@@ -0,0 +1,83 @@
+package grpcconn
+
+import (
+	// stdlib
+	"testing"
+
+	// external
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func TestHostMapperRefs(t *testing.T) {
+	const instance = "127.0.0.1:1"
+	hm := NewHostMapper(grpc.WithTransportCredentials(insecure.NewCredentials())).(*hostMapper)
+
+	cc1, closer1, err := hm.Get(instance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cc2, closer2, err := hm.Get(instance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cc1 != cc2 {
+		t.Fatal("want connection shared by the users of an instance")
+	}
+	other, otherCloser, err := hm.Get("127.0.0.1:2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer otherCloser.Close()
+	if other == cc1 {
+		t.Fatal("want connection per instance")
+	}
+
+	// closing the first user more than once keeps the connection of the
+	// second user
+	closer1.Close()
+	closer1.Close()
+	if want, have := 1, refs(hm, instance); want != have {
+		t.Fatalf("want %d reference, have %d", want, have)
+	}
+	if cc1.GetState() == connectivity.Shutdown {
+		t.Fatal("want connection kept open for its last user")
+	}
+
+	// the last user closes the connection
+	closer2.Close()
+	if want, have := 0, refs(hm, instance); want != have {
+		t.Fatalf("want %d references, have %d", want, have)
+	}
+	if want, have := connectivity.Shutdown, cc1.GetState(); want != have {
+		t.Fatalf("want connection state %s, have %s", want, have)
+	}
+	closer2.Close()
+	if other.GetState() == connectivity.Shutdown {
+		t.Fatal("want connections of other instances kept open")
+	}
+
+	// new users get a new connection
+	cc3, closer3, err := hm.Get(instance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closer3.Close()
+	if cc3 == cc1 {
+		t.Error("want closed connection replaced")
+	}
+	if want, have := 1, refs(hm, instance); want != have {
+		t.Errorf("want %d reference, have %d", want, have)
+	}
+}
+
+// refs returns the references to the connection of instance.
+func refs(hm *hostMapper, instance string) int {
+	hm.mtx.Lock()
+	defer hm.mtx.Unlock()
+	if c := hm.host[instance]; c != nil {
+		return c.refs
+	}
+	return 0
+}
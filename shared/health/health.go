@@ -0,0 +1,102 @@
+// Package health implements the health endpoints served by our services and
+// the matching health checkers used by our clients to eject unhealthy
+// instances. HTTP and Twirp services serve a /healthz endpoint, gRPC services
+// implement the gRPC health checking protocol.
+package health
+
+import (
+	// stdlib
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	// external
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/shared/grpcconn"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/sd"
+)
+
+// Path of our HTTP health endpoint.
+const Path = "/healthz"
+
+// Default health check settings used by our clients.
+const (
+	Interval = 5 * time.Second
+	Timeout  = time.Second
+)
+
+// Handler serves our HTTP health endpoint. It replies as long as the service
+// is able to handle requests.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte("ok\n"))
+	})
+}
+
+// RegisterGRPC registers the gRPC health service on server, reporting the
+// provided services as serving. The returned health server can be used to
+// change the reported status, e.g. on shutdown.
+func RegisterGRPC(server *grpc.Server, services ...string) *grpchealth.Server {
+	hs := grpchealth.NewServer()
+	for _, service := range services {
+		hs.SetServingStatus(service, healthpb.HealthCheckResponse_SERVING)
+	}
+	healthpb.RegisterHealthServer(server, hs)
+	return hs
+}
+
+// HTTPCheck returns a HealthChecker requesting the health endpoint of HTTP and
+// Twirp instances. A nil client uses http.DefaultClient.
+func HTTPCheck(client *http.Client) sd.HealthChecker {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return func(ctx context.Context, instance string) error {
+		req, err := http.NewRequest("GET", strings.TrimSuffix(instance, "/")+Path, nil)
+		if err != nil {
+			return err
+		}
+		res, err := client.Do(req.WithContext(ctx))
+		if err != nil {
+			return err
+		}
+		res.Body.Close()
+		if res.StatusCode < 200 || res.StatusCode > 299 {
+			return fmt.Errorf("health check returned %s", res.Status)
+		}
+		return nil
+	}
+}
+
+// GRPCCheck returns a HealthChecker using the gRPC health checking protocol
+// for service. Connections are obtained from hm, so health checks share them
+// with the client endpoints.
+func GRPCCheck(hm grpcconn.HostMapper, service string) sd.HealthChecker {
+	return func(ctx context.Context, instance string) error {
+		// releasing our reference leaves the connection open for as long as
+		// the client endpoints of instance use it
+		conn, closer, err := hm.Get(instance)
+		if err != nil {
+			return err
+		}
+		defer closer.Close()
+
+		res, err := healthpb.NewHealthClient(conn).Check(
+			ctx, &healthpb.HealthCheckRequest{Service: service},
+		)
+		if err != nil {
+			return err
+		}
+		if res.Status != healthpb.HealthCheckResponse_SERVING {
+			return fmt.Errorf("health check returned %s", res.Status)
+		}
+		return nil
+	}
+}
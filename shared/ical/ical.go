@@ -0,0 +1,234 @@
+// Package ical implements a minimal RFC 5545 iCalendar encoder for
+// publishing events as a calendar feed.
+package ical
+
+import (
+	// stdlib
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ContentType of iCalendar payloads.
+const ContentType = "text/calendar; charset=utf-8"
+
+const (
+	dateTimeLocal = "20060102T150405"
+	dateTimeUTC   = "20060102T150405Z"
+	maxLineOctets = 75
+)
+
+// Calendar holds the details of a calendar feed.
+type Calendar struct {
+	ProdID string
+	Name   string
+	Events []Event
+}
+
+// Event holds the details of a single VEVENT. Events without a Timezone or
+// with an unknown Timezone are published in UTC. Start is required, End is
+// optional.
+type Event struct {
+	UID      string
+	Summary  string
+	Start    time.Time
+	End      time.Time
+	Timezone string
+}
+
+// Encode writes the calendar to w. DTSTAMP properties are set to now.
+func (c Calendar) Encode(w io.Writer, now time.Time) error {
+	var (
+		bw        = bufio.NewWriter(w)
+		locations = make(map[string]*time.Location)
+		ranges    = make(map[string][2]time.Time)
+	)
+
+	// find the time zones used and the period they need to cover
+	for _, evt := range c.Events {
+		loc := location(evt.Timezone)
+		if loc == nil {
+			continue
+		}
+		end := evt.End
+		if end.IsZero() {
+			end = evt.Start
+		}
+		r, ok := ranges[evt.Timezone]
+		if !ok || evt.Start.Before(r[0]) {
+			r[0] = evt.Start
+		}
+		if !ok || end.After(r[1]) {
+			r[1] = end
+		}
+		ranges[evt.Timezone] = r
+		locations[evt.Timezone] = loc
+	}
+	zones := make([]string, 0, len(locations))
+	for name := range locations {
+		zones = append(zones, name)
+	}
+	sort.Strings(zones)
+
+	writeLine(bw, "BEGIN:VCALENDAR")
+	writeLine(bw, "VERSION:2.0")
+	writeLine(bw, "PRODID:"+escape(c.ProdID))
+	writeLine(bw, "CALSCALE:GREGORIAN")
+	writeLine(bw, "METHOD:PUBLISH")
+	if c.Name != "" {
+		writeLine(bw, "X-WR-CALNAME:"+escape(c.Name))
+	}
+	for _, name := range zones {
+		writeTimezone(bw, name, locations[name], ranges[name][0], ranges[name][1])
+	}
+	for _, evt := range c.Events {
+		loc := location(evt.Timezone)
+		writeLine(bw, "BEGIN:VEVENT")
+		writeLine(bw, "UID:"+escape(evt.UID))
+		writeLine(bw, "DTSTAMP:"+now.UTC().Format(dateTimeUTC))
+		writeLine(bw, "DTSTART"+formatTime(evt.Start, evt.Timezone, loc))
+		if !evt.End.IsZero() {
+			writeLine(bw, "DTEND"+formatTime(evt.End, evt.Timezone, loc))
+		}
+		writeLine(bw, "SUMMARY:"+escape(evt.Summary))
+		writeLine(bw, "END:VEVENT")
+	}
+	writeLine(bw, "END:VCALENDAR")
+
+	return bw.Flush()
+}
+
+// location returns the time zone to publish in or nil for UTC.
+func location(name string) *time.Location {
+	if name == "" || name == "UTC" {
+		return nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil
+	}
+	return loc
+}
+
+// formatTime returns the parameters and value of a DATE-TIME property.
+func formatTime(t time.Time, tzid string, loc *time.Location) string {
+	if loc == nil {
+		return ":" + t.UTC().Format(dateTimeUTC)
+	}
+	return ";TZID=" + tzid + ":" + t.In(loc).Format(dateTimeLocal)
+}
+
+// writeTimezone writes a VTIMEZONE holding all UTC offset transitions of loc
+// between from and to. Explicit transitions keep the definition correct even
+// when a zone's rules changed over the years.
+func writeTimezone(w *bufio.Writer, name string, loc *time.Location, from, to time.Time) {
+	writeLine(w, "BEGIN:VTIMEZONE")
+	writeLine(w, "TZID:"+name)
+
+	// the first observance covers the start of our period
+	start := from.Add(-24 * time.Hour).In(loc)
+	_, offset := start.Zone()
+	writeObservance(w, start, offset)
+
+	end := to.Add(24 * time.Hour)
+	for t := start; ; {
+		next := nextTransition(t, loc)
+		if next.IsZero() || next.After(end) {
+			break
+		}
+		writeObservance(w, next, offset)
+		_, offset = next.Zone()
+		t = next
+	}
+
+	writeLine(w, "END:VTIMEZONE")
+}
+
+func writeObservance(w *bufio.Writer, t time.Time, offsetFrom int) {
+	abbr, offset := t.Zone()
+	kind := "STANDARD"
+	if t.IsDST() {
+		kind = "DAYLIGHT"
+	}
+	writeLine(w, "BEGIN:"+kind)
+	// DTSTART is the local time of the onset expressed in the prior offset
+	writeLine(w, "DTSTART:"+t.UTC().Add(time.Duration(offsetFrom)*time.Second).Format(dateTimeLocal))
+	writeLine(w, "TZOFFSETFROM:"+formatOffset(offsetFrom))
+	writeLine(w, "TZOFFSETTO:"+formatOffset(offset))
+	writeLine(w, "TZNAME:"+abbr)
+	writeLine(w, "END:"+kind)
+}
+
+// nextTransition returns the first moment after t the UTC offset of loc
+// changes, searching up to a year ahead. It returns the zero time if there is
+// none.
+func nextTransition(t time.Time, loc *time.Location) time.Time {
+	_, offset := t.In(loc).Zone()
+	isDST := t.In(loc).IsDST()
+	changed := func(c time.Time) bool {
+		_, o := c.In(loc).Zone()
+		return o != offset || c.In(loc).IsDST() != isDST
+	}
+
+	// find the day of the transition, then narrow it down to the second
+	lo := t.Unix()
+	for day := int64(1); day <= 366; day++ {
+		hi := t.Unix() + day*24*60*60
+		if !changed(time.Unix(hi, 0)) {
+			lo = hi
+			continue
+		}
+		for hi-lo > 1 {
+			mid := lo + (hi-lo)/2
+			if changed(time.Unix(mid, 0)) {
+				hi = mid
+			} else {
+				lo = mid
+			}
+		}
+		return time.Unix(hi, 0).In(loc)
+	}
+	return time.Time{}
+}
+
+func formatOffset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign = "-"
+		seconds = -seconds
+	}
+	s := fmt.Sprintf("%s%02d%02d", sign, seconds/3600, seconds%3600/60)
+	if seconds%60 != 0 {
+		s += fmt.Sprintf("%02d", seconds%60)
+	}
+	return s
+}
+
+// escape escapes TEXT values as described in RFC 5545 section 3.3.11.
+func escape(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`,
+	).Replace(s)
+}
+
+// writeLine writes a content line, folding it at 75 octets without splitting
+// UTF-8 sequences.
+func writeLine(w *bufio.Writer, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		w.WriteString(line[:cut])
+		w.WriteString("\r\n ")
+		line = line[cut:]
+		// continuation lines start with a space which counts as an octet
+		limit = maxLineOctets - 1
+	}
+	w.WriteString(line)
+	w.WriteString("\r\n")
+}
@@ -0,0 +1,193 @@
+package ical
+
+import (
+	// stdlib
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+// fold returns line as written by writeLine.
+func fold(line string) string {
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	writeLine(w, line)
+	w.Flush()
+	return buf.String()
+}
+
+func TestWriteLine(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		line string
+		want string
+	}{
+		{name: "short", line: "SUMMARY:Meetup", want: "SUMMARY:Meetup\r\n"},
+		{
+			name: "75 octets",
+			line: strings.Repeat("a", 75),
+			want: strings.Repeat("a", 75) + "\r\n",
+		},
+		{
+			name: "76 octets",
+			line: strings.Repeat("a", 76),
+			want: strings.Repeat("a", 75) + "\r\n a\r\n",
+		},
+		{
+			// continuation lines hold 74 octets next to their space
+			name: "two continuations",
+			line: strings.Repeat("a", 75+74+1),
+			want: strings.Repeat("a", 75) + "\r\n " + strings.Repeat("a", 74) + "\r\n a\r\n",
+		},
+		{
+			// the 2 octet rune at octets 75 and 76 moves to the next line
+			name: "multi-byte rune at the fold",
+			line: strings.Repeat("a", 74) + "é" + "b",
+			want: strings.Repeat("a", 74) + "\r\n éb\r\n",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if want, have := tc.want, fold(tc.line); want != have {
+				t.Errorf("want %q, have %q", want, have)
+			}
+		})
+	}
+}
+
+func TestWriteLineMultiByte(t *testing.T) {
+	for _, r := range []string{"é", "€", "😀"} {
+		line := "SUMMARY:" + strings.Repeat(r, 100)
+		folded := fold(line)
+
+		lines := strings.Split(strings.TrimSuffix(folded, "\r\n"), "\r\n")
+		for i, l := range lines {
+			if len(l) > maxLineOctets {
+				t.Errorf("%s: want at most %d octets, have %d in line %d", r, maxLineOctets, len(l), i)
+			}
+			if !utf8.ValidString(l) {
+				t.Errorf("%s: want runes kept whole, have %q in line %d", r, l, i)
+			}
+			if i > 0 && !strings.HasPrefix(l, " ") {
+				t.Errorf("%s: want continuation line %d to start with a space", r, i)
+			}
+		}
+		if want, have := line, strings.Replace(strings.TrimSuffix(folded, "\r\n"), "\r\n ", "", -1); want != have {
+			t.Errorf("%s: want unfolded line %q, have %q", r, want, have)
+		}
+	}
+}
+
+func TestEscape(t *testing.T) {
+	for _, tc := range []struct {
+		in, want string
+	}{
+		{`Meetup`, `Meetup`},
+		{`Talks; Drinks`, `Talks\; Drinks`},
+		{`Amsterdam, NL`, `Amsterdam\, NL`},
+		{`C:\Events`, `C:\\Events`},
+		{"line 1\nline 2", `line 1\nline 2`},
+		{"line 1\r\nline 2", `line 1\nline 2`},
+		{"line 1\rline 2", `line 1\nline 2`},
+		{`\;`, `\\\;`},
+	} {
+		if want, have := tc.want, escape(tc.in); want != have {
+			t.Errorf("escape(%q): want %q, have %q", tc.in, want, have)
+		}
+	}
+}
+
+// unfold returns the content lines of an encoded calendar.
+func unfold(t *testing.T, c Calendar, now time.Time) []string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := c.Encode(&buf, now); err != nil {
+		t.Fatal(err)
+	}
+	s := strings.Replace(buf.String(), "\r\n ", "", -1)
+	return strings.Split(strings.TrimSuffix(s, "\r\n"), "\r\n")
+}
+
+// contains reports if lines holds want as a consecutive sequence.
+func contains(lines, want []string) bool {
+	for i := 0; i+len(want) <= len(lines); i++ {
+		match := true
+		for j := range want {
+			if lines[i+j] != want[j] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+func TestEncodeTimezone(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Amsterdam")
+	if err != nil {
+		t.Skipf("time zone database unavailable: %v", err)
+	}
+	var (
+		now = time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+		// the weekend daylight saving time starts in 2030
+		start = time.Date(2030, 3, 30, 10, 0, 0, 0, loc)
+		end   = time.Date(2030, 3, 31, 10, 0, 0, 0, loc)
+	)
+	lines := unfold(t, Calendar{
+		ProdID: "-//test//EN",
+		Events: []Event{
+			{UID: "1@test", Summary: "Weekend", Start: start, End: end, Timezone: "Europe/Amsterdam"},
+			{UID: "2@test", Summary: "Call", Start: start, Timezone: "UTC"},
+			{UID: "3@test", Summary: "Elsewhere", Start: start, Timezone: "Nowhere/Special"},
+		},
+	}, now)
+
+	for _, want := range [][]string{
+		{
+			"BEGIN:VTIMEZONE",
+			"TZID:Europe/Amsterdam",
+			"BEGIN:STANDARD",
+			"DTSTART:20300329T100000",
+			"TZOFFSETFROM:+0100",
+			"TZOFFSETTO:+0100",
+			"TZNAME:CET",
+			"END:STANDARD",
+			"BEGIN:DAYLIGHT",
+			"DTSTART:20300331T020000",
+			"TZOFFSETFROM:+0100",
+			"TZOFFSETTO:+0200",
+			"TZNAME:CEST",
+			"END:DAYLIGHT",
+			"END:VTIMEZONE",
+		},
+		{
+			"UID:1@test",
+			"DTSTAMP:20300101T120000Z",
+			"DTSTART;TZID=Europe/Amsterdam:20300330T100000",
+			"DTEND;TZID=Europe/Amsterdam:20300331T100000",
+		},
+		// UTC and unknown time zones are published in UTC
+		{"UID:2@test", "DTSTAMP:20300101T120000Z", "DTSTART:20300330T090000Z", "SUMMARY:Call"},
+		{"UID:3@test", "DTSTAMP:20300101T120000Z", "DTSTART:20300330T090000Z", "SUMMARY:Elsewhere"},
+	} {
+		if !contains(lines, want) {
+			t.Errorf("want lines\n%s\nin\n%s", strings.Join(want, "\n"), strings.Join(lines, "\n"))
+		}
+	}
+
+	// only the time zones in use are defined
+	var zones int
+	for _, l := range lines {
+		if l == "BEGIN:VTIMEZONE" {
+			zones++
+		}
+	}
+	if want, have := 1, zones; want != have {
+		t.Errorf("want %d time zone, have %d", want, have)
+	}
+}
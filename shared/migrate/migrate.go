@@ -0,0 +1,449 @@
+// Package migrate provides versioned schema migrations with optional down
+// migrations for our SQL repositories. The version of each schema is tracked
+// by name in the schema_version table, so multiple schemas can share a single
+// database. Schemas migrated before their version was tracked are adopted by
+// the tables and columns their migrations created.
+package migrate
+
+import (
+	// stdlib
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/jmoiron/sqlx"
+)
+
+// Dialect identifies the SQL dialect of the database holding the schema.
+type Dialect int
+
+// Supported Dialects
+const (
+	SQLite Dialect = iota
+	Postgres
+)
+
+// Latest targets the most recent schema version.
+const Latest = -1
+
+// Common Errors
+var (
+	ErrUnknownVersion = errors.New("unknown schema version")
+	ErrIrreversible   = errors.New("migration has no down migration")
+	ErrConcurrent     = errors.New("schema version changed concurrently")
+	ErrNotEmpty       = errors.New("untracked schema is not empty")
+)
+
+// Migration holds the changes of a single schema version. Down is optional.
+// Creates names the table, or table.column, added by the migration. It is
+// used to detect the version of untracked schemas.
+type Migration struct {
+	Version     int
+	Description string
+	Creates     string
+	Up          func(tx *sqlx.Tx) error
+	Down        func(tx *sqlx.Tx) error
+}
+
+// Schema holds the migrations of a named schema, ordered by version starting
+// at version 1.
+type Schema struct {
+	Name       string
+	Migrations []Migration
+}
+
+// Latest returns the most recent version of the schema.
+func (s Schema) Latest() int {
+	return len(s.Migrations)
+}
+
+// Step is a single migration to apply. Revert steps run the down migration.
+type Step struct {
+	Migration
+	Revert bool
+}
+
+// String implements fmt.Stringer
+func (s Step) String() string {
+	direction := "up"
+	if s.Revert {
+		direction = "down"
+	}
+	return fmt.Sprintf("%s %d: %s", direction, s.Version, s.Description)
+}
+
+// Migrator inspects and migrates a schema.
+type Migrator struct {
+	db      *sqlx.DB
+	dialect Dialect
+	schema  Schema
+	logger  log.Logger
+}
+
+// New returns a new Migrator for the schema stored in db.
+func New(db *sqlx.DB, dialect Dialect, schema Schema, logger log.Logger) *Migrator {
+	return &Migrator{
+		db:      db,
+		dialect: dialect,
+		schema:  schema,
+		logger:  log.With(logger, "schema", schema.Name),
+	}
+}
+
+// Up applies all pending migrations of the schema. It is used by our
+// repositories on start up. A database holding a newer schema version than
+// known is left untouched.
+func Up(
+	ctx context.Context, db *sqlx.DB, dialect Dialect, schema Schema,
+	logger log.Logger,
+) error {
+	m := New(db, dialect, schema, logger)
+
+	current, err := m.Version(ctx)
+	if err != nil {
+		return err
+	}
+	if current > schema.Latest() {
+		level.Warn(m.logger).Log(
+			"msg", "database schema is newer than supported",
+			"version", current, "latest", schema.Latest(),
+		)
+		return nil
+	}
+	_, err = m.Migrate(ctx, Latest)
+	return err
+}
+
+// Version returns the current schema version, 0 if the schema was never
+// migrated. The version of an untracked schema is detected from its tables.
+// It does not modify the database.
+func (m *Migrator) Version(ctx context.Context) (int, error) {
+	var query string
+	switch m.dialect {
+	case Postgres:
+		query = `SELECT count(*) FROM information_schema.tables
+		WHERE table_schema = current_schema() AND table_name = 'schema_version'`
+	default:
+		query = `SELECT count(*) FROM sqlite_master
+		WHERE type = 'table' AND name = 'schema_version'`
+	}
+	var found int
+	if err := m.db.QueryRowContext(ctx, query).Scan(&found); err != nil {
+		return 0, err
+	}
+	if found == 0 {
+		return m.detect(ctx, m.db)
+	}
+
+	version, tracked, err := m.version(ctx, m.db)
+	if err != nil || tracked {
+		return version, err
+	}
+	return m.detect(ctx, m.db)
+}
+
+// Plan returns the steps needed to migrate to the target version without
+// applying them. Use Latest to target the most recent version.
+func (m *Migrator) Plan(ctx context.Context, target int) ([]Step, error) {
+	for idx, migration := range m.schema.Migrations {
+		if migration.Version != idx+1 {
+			return nil, fmt.Errorf(
+				"schema %s: migration %d has version %d",
+				m.schema.Name, idx+1, migration.Version,
+			)
+		}
+	}
+
+	current, err := m.Version(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if target == Latest {
+		target = m.schema.Latest()
+	}
+	if target < 0 || target > m.schema.Latest() || current > m.schema.Latest() {
+		return nil, ErrUnknownVersion
+	}
+
+	var steps []Step
+	for version := current + 1; version <= target; version++ {
+		steps = append(steps, Step{Migration: m.schema.Migrations[version-1]})
+	}
+	for version := current; version > target; version-- {
+		migration := m.schema.Migrations[version-1]
+		if migration.Down == nil {
+			return nil, ErrIrreversible
+		}
+		steps = append(steps, Step{Migration: migration, Revert: true})
+	}
+
+	return steps, nil
+}
+
+// Migrate migrates the schema to the target version and returns the applied
+// steps. Each step runs in its own transaction. Use Latest to target the most
+// recent version.
+func (m *Migrator) Migrate(ctx context.Context, target int) ([]Step, error) {
+	if err := m.createTable(ctx); err != nil {
+		return nil, err
+	}
+	if err := m.adopt(ctx); err != nil {
+		return nil, err
+	}
+	steps, err := m.Plan(ctx, target)
+	if err != nil || len(steps) == 0 {
+		return nil, err
+	}
+
+	var applied []Step
+	for _, step := range steps {
+		ok, err := m.apply(ctx, step)
+		if err != nil {
+			return applied, fmt.Errorf("%s: %v", step, err)
+		}
+		if ok {
+			level.Info(m.logger).Log("msg", "migrated", "step", step)
+			applied = append(applied, step)
+		}
+	}
+
+	return applied, nil
+}
+
+// Force sets the schema version without running any migrations. It is used
+// to adopt databases migrated by other means.
+func (m *Migrator) Force(ctx context.Context, version int) error {
+	if version < 0 || version > m.schema.Latest() {
+		return ErrUnknownVersion
+	}
+	if err := m.createTable(ctx); err != nil {
+		return err
+	}
+
+	tx, err := m.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = m.setVersion(ctx, tx, version); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// apply runs the step and updates the schema version within a single
+// transaction. It returns false if the step was already applied concurrently.
+func (m *Migrator) apply(ctx context.Context, step Step) (bool, error) {
+	tx, err := m.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	if m.dialect == Postgres {
+		// serialize migrations of concurrently starting instances
+		if _, err = tx.ExecContext(
+			ctx, `LOCK TABLE schema_version IN SHARE ROW EXCLUSIVE MODE`,
+		); err != nil {
+			return false, err
+		}
+	}
+	current, _, err := m.version(ctx, tx)
+	if err != nil {
+		return false, err
+	}
+
+	fn, from, to := step.Up, step.Version-1, step.Version
+	if step.Revert {
+		fn, from, to = step.Down, step.Version, step.Version-1
+		if current < from {
+			return false, nil
+		}
+	} else if current > from {
+		return false, nil
+	}
+	if current != from {
+		return false, ErrConcurrent
+	}
+	if from == 0 {
+		// never create a schema on top of existing tables
+		detected, err := m.detect(ctx, tx)
+		if err != nil {
+			return false, err
+		}
+		if detected > 0 {
+			return false, ErrNotEmpty
+		}
+	}
+
+	if err = fn(tx); err != nil {
+		return false, err
+	}
+	if err = m.setVersion(ctx, tx, to); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+func (m *Migrator) createTable(ctx context.Context) error {
+	_, err := m.db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_version (
+			name TEXT NOT NULL, version INTEGER NOT NULL, PRIMARY KEY (name)
+		)`,
+	)
+	return err
+}
+
+// adopt records the detected version of a schema migrated before its version
+// was tracked.
+func (m *Migrator) adopt(ctx context.Context) error {
+	tx, err := m.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if m.dialect == Postgres {
+		// serialize adoption by concurrently starting instances
+		if _, err = tx.ExecContext(
+			ctx, `LOCK TABLE schema_version IN SHARE ROW EXCLUSIVE MODE`,
+		); err != nil {
+			return err
+		}
+	}
+	if _, tracked, err := m.version(ctx, tx); err != nil || tracked {
+		return err
+	}
+	version, err := m.detect(ctx, tx)
+	if err != nil || version == 0 {
+		return err
+	}
+	if err = m.setVersion(ctx, tx, version); err != nil {
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+	level.Info(m.logger).Log("msg", "adopted untracked schema", "version", version)
+	return nil
+}
+
+// detect returns the version of an untracked schema: the last of the
+// consecutive migrations, starting at version 1, whose table or column
+// exists. It fails if a later migration left its table or column too, as the
+// schema can't be adopted safely.
+func (m *Migrator) detect(ctx context.Context, q sqlx.QueryerContext) (int, error) {
+	var version int
+	for _, migration := range m.schema.Migrations {
+		if migration.Creates == "" {
+			break
+		}
+		ok, err := m.exists(ctx, q, migration.Creates)
+		if err != nil {
+			return 0, err
+		}
+		if !ok {
+			break
+		}
+		version = migration.Version
+	}
+	for _, migration := range m.schema.Migrations[version:] {
+		if migration.Creates == "" {
+			continue
+		}
+		ok, err := m.exists(ctx, q, migration.Creates)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			return 0, fmt.Errorf(
+				"%v: %s exists without version %d", ErrNotEmpty,
+				migration.Creates, version+1,
+			)
+		}
+	}
+	return version, nil
+}
+
+// exists reports whether the table, or table.column, exists.
+func (m *Migrator) exists(ctx context.Context, q sqlx.QueryerContext, name string) (bool, error) {
+	var (
+		table, column = name, ""
+		query         string
+		args          []interface{}
+	)
+	if idx := strings.IndexByte(name, '.'); idx >= 0 {
+		table, column = name[:idx], name[idx+1:]
+	}
+	switch {
+	case m.dialect == Postgres && column == "":
+		query = `SELECT count(*) FROM information_schema.tables
+		WHERE table_schema = current_schema() AND table_name = $1`
+		args = []interface{}{table}
+	case m.dialect == Postgres:
+		query = `SELECT count(*) FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = $1
+		AND column_name = $2`
+		args = []interface{}{table, column}
+	case column == "":
+		query = `SELECT count(*) FROM sqlite_master
+		WHERE type = 'table' AND name = ?`
+		args = []interface{}{table}
+	default:
+		query = `SELECT count(*) FROM pragma_table_info(?) WHERE name = ?`
+		args = []interface{}{table, column}
+	}
+	var found int
+	if err := q.QueryRowxContext(ctx, query, args...).Scan(&found); err != nil {
+		return false, err
+	}
+	return found > 0, nil
+}
+
+// version returns the tracked schema version and whether it is tracked.
+func (m *Migrator) version(ctx context.Context, q sqlx.QueryerContext) (int, bool, error) {
+	var version int
+	err := q.QueryRowxContext(
+		ctx,
+		m.rebind(`SELECT version FROM schema_version WHERE name = ?`),
+		m.schema.Name,
+	).Scan(&version)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	return version, err == nil, err
+}
+
+func (m *Migrator) setVersion(ctx context.Context, tx *sqlx.Tx, version int) error {
+	res, err := tx.ExecContext(
+		ctx,
+		m.rebind(`UPDATE schema_version SET version = ? WHERE name = ?`),
+		version, m.schema.Name,
+	)
+	if err != nil {
+		return err
+	}
+	if cnt, err := res.RowsAffected(); err != nil || cnt > 0 {
+		return err
+	}
+	_, err = tx.ExecContext(
+		ctx,
+		m.rebind(`INSERT INTO schema_version (name, version) VALUES (?, ?)`),
+		m.schema.Name, version,
+	)
+	return err
+}
+
+// rebind returns the query using the placeholders of our dialect.
+func (m *Migrator) rebind(query string) string {
+	if m.dialect == Postgres {
+		return sqlx.Rebind(sqlx.DOLLAR, query)
+	}
+	return query
+}
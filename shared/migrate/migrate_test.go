@@ -0,0 +1,135 @@
+package migrate
+
+import (
+	// stdlib
+	"context"
+	"strings"
+	"testing"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+var testSchema = Schema{
+	Name: "test",
+	Migrations: []Migration{
+		{
+			Version: 1, Description: "add item table",
+			Creates: "item", Up: exec(`CREATE TABLE item (id INTEGER PRIMARY KEY)`),
+		},
+		{
+			Version: 2, Description: "add item names",
+			Creates: "item.name", Up: exec(`ALTER TABLE item ADD COLUMN name TEXT`),
+		},
+		{
+			Version: 3, Description: "add tag table",
+			Creates: "tag", Up: exec(`CREATE TABLE tag (id INTEGER PRIMARY KEY)`),
+		},
+	},
+}
+
+func exec(query string) func(tx *sqlx.Tx) error {
+	return func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(query)
+		return err
+	}
+}
+
+func TestUpAdoptsUntrackedSchema(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		existing []string
+		adopted  int
+		err      string
+	}{
+		{name: "empty"},
+		{
+			name:     "version 1",
+			existing: []string{`CREATE TABLE item (id INTEGER PRIMARY KEY)`},
+			adopted:  1,
+		},
+		{
+			name: "version 2",
+			existing: []string{
+				`CREATE TABLE item (id INTEGER PRIMARY KEY, name TEXT)`,
+			},
+			adopted: 2,
+		},
+		{
+			name: "version 3",
+			existing: []string{
+				`CREATE TABLE item (id INTEGER PRIMARY KEY, name TEXT)`,
+				`CREATE TABLE tag (id INTEGER PRIMARY KEY)`,
+			},
+			adopted: 3,
+		},
+		{
+			name: "inconsistent",
+			existing: []string{
+				`CREATE TABLE item (id INTEGER PRIMARY KEY)`,
+				`CREATE TABLE tag (id INTEGER PRIMARY KEY)`,
+			},
+			err: ErrNotEmpty.Error(),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			db := sqlx.MustOpen("sqlite3", ":memory:")
+			defer db.Close()
+			db.SetMaxOpenConns(1)
+
+			for _, query := range tc.existing {
+				db.MustExec(query)
+			}
+
+			m := New(db, SQLite, testSchema, log.NewNopLogger())
+			version, err := m.Version(ctx)
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("want error %q, have %v", tc.err, err)
+				}
+				if err = Up(ctx, db, SQLite, testSchema, log.NewNopLogger()); err == nil {
+					t.Fatal("want migrations to refuse the schema")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if version != tc.adopted {
+				t.Errorf("want detected version %d, have %d", tc.adopted, version)
+			}
+
+			if err = Up(ctx, db, SQLite, testSchema, log.NewNopLogger()); err != nil {
+				t.Fatal(err)
+			}
+			current, tracked, err := m.version(ctx, db)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !tracked || current != testSchema.Latest() {
+				t.Errorf("want tracked version %d, have %d (tracked %t)", testSchema.Latest(), current, tracked)
+			}
+		})
+	}
+}
+
+func TestApplyRefusesNonEmptySchema(t *testing.T) {
+	ctx := context.Background()
+	db := sqlx.MustOpen("sqlite3", ":memory:")
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	m := New(db, SQLite, testSchema, log.NewNopLogger())
+	if err := m.createTable(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// tables appearing after the schema was found empty
+	db.MustExec(`CREATE TABLE item (id INTEGER PRIMARY KEY)`)
+
+	if _, err := m.apply(ctx, Step{Migration: testSchema.Migrations[0]}); err != ErrNotEmpty {
+		t.Errorf("want error %v, have %v", ErrNotEmpty, err)
+	}
+}
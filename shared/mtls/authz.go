@@ -0,0 +1,188 @@
+package mtls
+
+import (
+	// stdlib
+	"context"
+	"net/http"
+	"strings"
+
+	// external
+	"github.com/go-kit/kit/endpoint"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/shared/errcode"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/health"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/problem"
+)
+
+// grpcHealthPrefix is the method prefix of the gRPC health checking protocol,
+// which is served to any caller.
+const grpcHealthPrefix = "/grpc.health.v1.Health/"
+
+// Authorization error descriptions
+const (
+	ErrorUnauthenticated  = "caller presented no service identity"
+	ErrorPermissionDenied = "caller is not authorized"
+)
+
+// Authorization errors
+var (
+	ErrUnauthenticated  = errcode.New("mtls.unauthenticated", errcode.Unauthenticated, ErrorUnauthenticated)
+	ErrPermissionDenied = errcode.New("mtls.permission_denied", errcode.PermissionDenied, ErrorPermissionDenied)
+)
+
+// Policy authorizes callers by their identity. A nil Policy allows any
+// caller, including callers without a certificate.
+type Policy func(id Identity) bool
+
+// AllowServices returns a Policy allowing the provided services.
+func AllowServices(services ...string) Policy {
+	allowed := make(map[string]bool, len(services))
+	for _, service := range services {
+		allowed[service] = true
+	}
+	return func(id Identity) bool {
+		return allowed[id.Service]
+	}
+}
+
+// authorize checks the caller identity id, ok reports if the caller presented
+// one.
+func (p Policy) authorize(id Identity, ok bool) error {
+	if p == nil {
+		return nil
+	}
+	if !ok {
+		return ErrUnauthenticated
+	}
+	if !p(id) {
+		return ErrPermissionDenied
+	}
+	return nil
+}
+
+// GRPCServerOptions returns the options of our gRPC servers, serving TLS and
+// authorizing callers by policy. The identity of callers is added to the
+// request context. Health checks are served to any caller. A nil Source
+// returns no options.
+func (s *Source) GRPCServerOptions(policy Policy) []grpc.ServerOption {
+	if s == nil {
+		return nil
+	}
+	return []grpc.ServerOption{
+		grpc.Creds(credentials.NewTLS(s.ServerConfig())),
+		grpc.ChainUnaryInterceptor(func(
+			ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+			handler grpc.UnaryHandler,
+		) (interface{}, error) {
+			ctx, err := s.grpcAuthorize(ctx, info.FullMethod, policy)
+			if err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(func(
+			srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo,
+			handler grpc.StreamHandler,
+		) error {
+			ctx, err := s.grpcAuthorize(ss.Context(), info.FullMethod, policy)
+			if err != nil {
+				return err
+			}
+			return handler(srv, serverStream{ServerStream: ss, ctx: ctx})
+		}),
+	}
+}
+
+// grpcAuthorize authorizes the caller of method and returns ctx holding its
+// identity.
+func (s *Source) grpcAuthorize(
+	ctx context.Context, method string, policy Policy,
+) (context.Context, error) {
+	id, ok := s.grpcIdentity(ctx)
+	if ok {
+		ctx = NewContext(ctx, id)
+	}
+	if strings.HasPrefix(method, grpcHealthPrefix) {
+		return ctx, nil
+	}
+	if err := policy.authorize(id, ok); err != nil {
+		return nil, errcode.GRPCError(err)
+	}
+	return ctx, nil
+}
+
+// grpcIdentity returns the identity of the verified peer certificate of the
+// gRPC request in ctx.
+func (s *Source) grpcIdentity(ctx context.Context) (Identity, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return Identity{}, false
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.PeerCertificates) == 0 {
+		return Identity{}, false
+	}
+	return identityOf(info.State.PeerCertificates[0], s.trustDomain)
+}
+
+// serverStream overrides the context of a gRPC server stream.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context implements grpc.ServerStream.
+func (s serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// HTTPHandler returns next authorizing callers by policy. The identity of
+// callers is added to the request context. The health endpoint is served to
+// any caller. A nil Source returns next as is.
+func (s *Source) HTTPHandler(policy Policy, next http.Handler) http.Handler {
+	if s == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var (
+			id Identity
+			ok bool
+		)
+		if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+			id, ok = identityOf(r.TLS.PeerCertificates[0], s.trustDomain)
+		}
+		if ok {
+			r = r.WithContext(NewContext(r.Context(), id))
+		}
+		if r.URL.Path != health.Path {
+			if err := policy.authorize(id, ok); err != nil {
+				problem.EncodeError(r.Context(), err, w)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// EndpointMiddleware returns a Go kit endpoint middleware authorizing callers
+// by policy, for endpoints needing a stricter policy than their server. Unlike
+// our servers it requires callers to present an identity, so the endpoint is
+// not served at all without mutual TLS.
+func EndpointMiddleware(policy Policy) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			id, ok := FromContext(ctx)
+			if !ok {
+				return nil, ErrUnauthenticated
+			}
+			if err := policy.authorize(id, ok); err != nil {
+				return nil, err
+			}
+			return next(ctx, request)
+		}
+	}
+}
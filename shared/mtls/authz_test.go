@@ -0,0 +1,212 @@
+package mtls
+
+import (
+	// stdlib
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	// external
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/shared/errcode"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/health"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/problem"
+)
+
+// anonymous is the TLS configuration of callers without a certificate.
+var anonymous = &tls.Config{InsecureSkipVerify: true}
+
+func TestHTTPHandlerPolicy(t *testing.T) {
+	dir, remove := tempDir(t)
+	defer remove()
+
+	ca, err := NewDevCA("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := newSource(t, ca, dir, "device")
+	defer server.Close()
+	frontend := newSource(t, ca, dir, "frontend")
+	defer frontend.Close()
+	qr := newSource(t, ca, dir, "qr")
+	defer qr.Close()
+
+	srv := newServer(server, AllowServices("frontend"))
+	defer srv.Close()
+
+	for _, tc := range []struct {
+		name      string
+		transport http.RoundTripper
+		path      string
+		status    int
+	}{
+		{name: "allowed", transport: frontend.HTTPTransport(), path: "/", status: http.StatusOK},
+		{name: "denied", transport: qr.HTTPTransport(), path: "/", status: http.StatusForbidden},
+		{
+			name:      "no certificate",
+			transport: &http.Transport{TLSClientConfig: anonymous},
+			path:      "/",
+			status:    http.StatusUnauthorized,
+		},
+		{
+			name:      "health without certificate",
+			transport: &http.Transport{TLSClientConfig: anonymous},
+			path:      health.Path,
+			status:    http.StatusOK,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			client := &http.Client{Transport: tc.transport}
+			res, err := client.Get("https://" + srv.Listener.Addr().String() + tc.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer res.Body.Close()
+			if want, have := tc.status, res.StatusCode; want != have {
+				t.Fatalf("want status %d, have %d", want, have)
+			}
+			if tc.status == http.StatusOK {
+				return
+			}
+			if want, have := problem.ContentType, res.Header.Get("Content-Type"); want != have {
+				t.Errorf("want content type %s, have %s", want, have)
+			}
+		})
+	}
+}
+
+// identityService replies to any gRPC method with the identity of the caller
+// in the Service field of a health check request.
+func identityService(_ interface{}, stream grpc.ServerStream) error {
+	var req healthpb.HealthCheckRequest
+	if err := stream.RecvMsg(&req); err != nil {
+		return err
+	}
+	id, _ := FromContext(stream.Context())
+	return stream.SendMsg(&healthpb.HealthCheckRequest{Service: id.String()})
+}
+
+func TestGRPCServerOptionsPolicy(t *testing.T) {
+	dir, remove := tempDir(t)
+	defer remove()
+
+	ca, err := NewDevCA("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := newSource(t, ca, dir, "device")
+	defer server.Close()
+	frontend := newSource(t, ca, dir, "frontend")
+	defer frontend.Close()
+	qr := newSource(t, ca, dir, "qr")
+	defer qr.Close()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	grpcServer := grpc.NewServer(append(
+		server.GRPCServerOptions(AllowServices("frontend")),
+		grpc.UnknownServiceHandler(identityService),
+	)...)
+	health.RegisterGRPC(grpcServer, "pb.Device")
+	go grpcServer.Serve(listener)
+	defer grpcServer.Stop()
+
+	for _, tc := range []struct {
+		name   string
+		dial   grpc.DialOption
+		method string
+		code   codes.Code
+		err    error
+	}{
+		{name: "allowed", dial: frontend.GRPCDialOption(), method: "/pb.Device/Unlock", code: codes.OK},
+		{
+			name:   "denied",
+			dial:   qr.GRPCDialOption(),
+			method: "/pb.Device/Unlock",
+			code:   codes.PermissionDenied,
+			err:    ErrPermissionDenied,
+		},
+		{
+			name:   "no certificate",
+			dial:   grpc.WithTransportCredentials(credentials.NewTLS(anonymous)),
+			method: "/pb.Device/Unlock",
+			code:   codes.Unauthenticated,
+			err:    ErrUnauthenticated,
+		},
+		{
+			name:   "health without certificate",
+			dial:   grpc.WithTransportCredentials(credentials.NewTLS(anonymous)),
+			method: "/grpc.health.v1.Health/Check",
+			code:   codes.OK,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			conn, err := grpc.DialContext(ctx, listener.Addr().String(), tc.dial)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+
+			var reply healthpb.HealthCheckRequest
+			err = conn.Invoke(ctx, tc.method, &healthpb.HealthCheckRequest{Service: "pb.Device"}, &reply)
+			if want, have := tc.code, status.Code(err); want != have {
+				t.Fatalf("want code %s, have %s (%v)", want, have, err)
+			}
+			if tc.err != nil {
+				if want, have := tc.err, errcode.FromGRPC(err); want != have {
+					t.Errorf("want error %v, have %v", want, have)
+				}
+			}
+			if tc.name == "allowed" {
+				if want, have := "spiffe://ocg.local/frontend", reply.Service; want != have {
+					t.Errorf("want caller identity %s, have %s", want, have)
+				}
+			}
+		})
+	}
+}
+
+func TestEndpointMiddleware(t *testing.T) {
+	next := func(context.Context, interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+	authorized := EndpointMiddleware(AllowServices("frontend"))(next)
+
+	for _, tc := range []struct {
+		name string
+		ctx  context.Context
+		err  error
+	}{
+		{name: "allowed", ctx: NewContext(context.Background(), Identity{"ocg.local", "frontend"})},
+		{
+			name: "denied",
+			ctx:  NewContext(context.Background(), Identity{"ocg.local", "qr"}),
+			err:  ErrPermissionDenied,
+		},
+		{name: "no identity", ctx: context.Background(), err: ErrUnauthenticated},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := authorized(tc.ctx, nil)
+			if want, have := tc.err, err; want != have {
+				t.Fatalf("want error %v, have %v", want, have)
+			}
+			if tc.err == nil && res != "ok" {
+				t.Errorf("want endpoint called, have %v", res)
+			}
+		})
+	}
+}
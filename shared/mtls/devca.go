@@ -0,0 +1,152 @@
+package mtls
+
+import (
+	// stdlib
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// devValidity is the validity of the certificates issued by our development
+// CA.
+const devValidity = 30 * 24 * time.Hour
+
+// DevCA is a certificate authority for local development and tests, issuing
+// service certificates holding the SPIFFE ID of the service. Its key only
+// lives in memory, so a new CA is needed to issue certificates for services
+// added later.
+type DevCA struct {
+	trustDomain string
+	cert        *x509.Certificate
+	certPEM     []byte
+	key         *ecdsa.PrivateKey
+}
+
+// NewDevCA returns a new development CA for trustDomain. An empty trust domain
+// uses DefaultTrustDomain.
+func NewDevCA(trustDomain string) (*DevCA, error) {
+	if trustDomain == "" {
+		trustDomain = DefaultTrustDomain
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	serial, err := serialNumber()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: trustDomain + " development CA"},
+		NotBefore:             now.Add(-time.Minute),
+		NotAfter:              now.Add(devValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	return &DevCA{
+		trustDomain: trustDomain,
+		cert:        cert,
+		certPEM:     pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		key:         key,
+	}, nil
+}
+
+// CertPEM returns the PEM encoded CA certificate.
+func (ca *DevCA) CertPEM() []byte {
+	return ca.certPEM
+}
+
+// Issue returns a PEM encoded certificate and key identifying service. The
+// certificate serves as both server and client certificate and is also valid
+// for localhost.
+func (ca *DevCA) Issue(service string) (certPEM, keyPEM []byte, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := serialNumber()
+	if err != nil {
+		return nil, nil, err
+	}
+	id := Identity{TrustDomain: ca.trustDomain, Service: service}
+	uri, err := url.Parse(id.String())
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: service},
+		NotBefore:    now.Add(-time.Minute),
+		NotAfter:     now.Add(devValidity),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{
+			x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth,
+		},
+		URIs:        []*url.URL{uri},
+		DNSNames:    []string{"localhost"},
+		IPAddresses: []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM, nil
+}
+
+// WriteFiles writes the CA certificate to dir/ca.pem and issues a certificate
+// and key for each of services, written to dir/<service>.pem and
+// dir/<service>-key.pem.
+func (ca *DevCA) WriteFiles(dir string, services ...string) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "ca.pem"), ca.certPEM, 0644); err != nil {
+		return err
+	}
+	for _, service := range services {
+		certPEM, keyPEM, err := ca.Issue(service)
+		if err != nil {
+			return err
+		}
+		if err = ioutil.WriteFile(filepath.Join(dir, service+".pem"), certPEM, 0644); err != nil {
+			return err
+		}
+		if err = ioutil.WriteFile(filepath.Join(dir, service+"-key.pem"), keyPEM, 0600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// serialNumber returns a random certificate serial number.
+func serialNumber() (*big.Int, error) {
+	return rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+}
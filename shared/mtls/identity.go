@@ -0,0 +1,55 @@
+package mtls
+
+import (
+	// stdlib
+	"context"
+	"crypto/x509"
+	"strings"
+)
+
+// Identity is the SPIFFE-style identity of a service,
+// spiffe://<trust domain>/<service>.
+type Identity struct {
+	TrustDomain string
+	Service     string
+}
+
+// String returns the SPIFFE ID of the identity.
+func (id Identity) String() string {
+	if id.Service == "" {
+		return ""
+	}
+	return "spiffe://" + id.TrustDomain + "/" + id.Service
+}
+
+// identityOf returns the identity held by the URI SANs of cert within
+// trustDomain.
+func identityOf(cert *x509.Certificate, trustDomain string) (Identity, bool) {
+	for _, uri := range cert.URIs {
+		if uri.Scheme != "spiffe" || uri.Host != trustDomain {
+			continue
+		}
+		service := strings.TrimPrefix(uri.Path, "/")
+		if service == "" {
+			continue
+		}
+		return Identity{TrustDomain: uri.Host, Service: service}, true
+	}
+	return Identity{}, false
+}
+
+type contextKey int
+
+const identityContextKey contextKey = iota
+
+// NewContext returns a context holding the identity of the caller.
+func NewContext(ctx context.Context, id Identity) context.Context {
+	return context.WithValue(ctx, identityContextKey, id)
+}
+
+// FromContext returns the identity of the caller held by ctx. Our servers add
+// it to the context of requests made by callers presenting a certificate.
+func FromContext(ctx context.Context) (Identity, bool) {
+	id, ok := ctx.Value(identityContextKey).(Identity)
+	return id, ok
+}
@@ -0,0 +1,347 @@
+// Package mtls implements mutual TLS between our services. Each service holds
+// a certificate issued by our CA, identifying it by a SPIFFE ID such as
+// spiffe://ocg.local/device. Servers extract the identity of their callers
+// into the request context and authorize them by service, clients verify the
+// instances they call hold an identity of our trust domain.
+//
+// A nil *Source disables TLS: servers and clients fall back to plaintext and
+// callers are neither authenticated nor authorized.
+package mtls
+
+import (
+	// stdlib
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// DefaultTrustDomain is the trust domain of our service identities if
+// OCG_TLS_TRUST_DOMAIN is not set.
+const DefaultTrustDomain = "ocg.local"
+
+// pollInterval is the interval at which a Source checks its files for
+// rotation.
+const pollInterval = 5 * time.Second
+
+// Source errors
+const (
+	ErrorInvalidConfig = "invalid TLS configuration"
+	ErrorNoIdentity    = "certificate holds no identity of our trust domain"
+)
+
+// Source errors
+var (
+	ErrInvalidConfig = errors.New(ErrorInvalidConfig)
+	ErrNoIdentity    = errors.New(ErrorNoIdentity)
+)
+
+// Source provides the certificate, key and CA of our service, loaded from PEM
+// files. The files are watched for changes until the Source is closed, so
+// rotated certificates are used for new connections without a restart.
+type Source struct {
+	certFile    string
+	keyFile     string
+	caFile      string
+	trustDomain string
+	logger      log.Logger
+	transport   http.RoundTripper
+	done        chan struct{}
+	closeOnce   sync.Once
+
+	material atomic.Value // *material
+	stamps   [3]fileStamp // only accessed by load
+}
+
+// material holds the currently loaded certificate, key and CA.
+type material struct {
+	cert *tls.Certificate
+	pool *x509.CertPool
+	id   Identity
+}
+
+// fileStamp identifies a version of a file.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// New returns a Source loading our certificate and key from certFile and
+// keyFile, trusting the CA certificates in caFile. It fails if the files can't
+// be loaded or the certificate holds no identity of trustDomain.
+func New(certFile, keyFile, caFile, trustDomain string, logger log.Logger) (*Source, error) {
+	if certFile == "" || keyFile == "" || caFile == "" {
+		return nil, fmt.Errorf("%v: certificate, key and CA files are required", ErrInvalidConfig)
+	}
+	if trustDomain == "" {
+		trustDomain = DefaultTrustDomain
+	}
+	s := &Source{
+		certFile:    certFile,
+		keyFile:     keyFile,
+		caFile:      caFile,
+		trustDomain: trustDomain,
+		logger:      logger,
+		done:        make(chan struct{}),
+	}
+	if _, err := s.load(); err != nil {
+		return nil, err
+	}
+	s.transport = newTransport(s)
+	go s.watch()
+	return s, nil
+}
+
+// FromEnv returns a Source configured by OCG_TLS_CERT, OCG_TLS_KEY, OCG_TLS_CA
+// and OCG_TLS_TRUST_DOMAIN. If none of the files are set it returns nil,
+// leaving our service on plaintext.
+func FromEnv(logger log.Logger) (*Source, error) {
+	var (
+		certFile = os.Getenv("OCG_TLS_CERT")
+		keyFile  = os.Getenv("OCG_TLS_KEY")
+		caFile   = os.Getenv("OCG_TLS_CA")
+	)
+	if certFile == "" && keyFile == "" && caFile == "" {
+		return nil, nil
+	}
+	return New(certFile, keyFile, caFile, os.Getenv("OCG_TLS_TRUST_DOMAIN"), logger)
+}
+
+// Identity returns the identity of our own certificate. A nil Source has no
+// identity.
+func (s *Source) Identity() Identity {
+	if s == nil {
+		return Identity{}
+	}
+	return s.current().id
+}
+
+// load reads the files if any of them changed since the last load and reports
+// if they did. Changes are only applied if all files are valid, e.g. a
+// certificate rotated ahead of its key is picked up once the key follows.
+func (s *Source) load() (bool, error) {
+	var (
+		stamps  [3]fileStamp
+		changed bool
+	)
+	for i, path := range []string{s.certFile, s.keyFile, s.caFile} {
+		info, err := os.Stat(path)
+		if err != nil {
+			return false, err
+		}
+		stamps[i] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+		changed = changed || stamps[i] != s.stamps[i]
+	}
+	if !changed {
+		return false, nil
+	}
+	// remember the files even if they can't be loaded, so we only try again
+	// once they change
+	s.stamps = stamps
+
+	cert, err := tls.LoadX509KeyPair(s.certFile, s.keyFile)
+	if err != nil {
+		return false, err
+	}
+	if cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0]); err != nil {
+		return false, err
+	}
+	id, ok := identityOf(cert.Leaf, s.trustDomain)
+	if !ok {
+		return false, fmt.Errorf("%v: %q", ErrNoIdentity, s.certFile)
+	}
+
+	b, err := ioutil.ReadFile(s.caFile)
+	if err != nil {
+		return false, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(b) {
+		return false, fmt.Errorf("%v: no CA certificates in %q", ErrInvalidConfig, s.caFile)
+	}
+
+	s.material.Store(&material{cert: &cert, pool: pool, id: id})
+	return true, nil
+}
+
+// watch polls the files for rotation. Rotations which can't be loaded are
+// logged, leaving the last loaded certificate in place.
+func (s *Source) watch() {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+		}
+		changed, err := s.load()
+		if err != nil {
+			s.logger.Log("cert", s.certFile, "err", err)
+			continue
+		}
+		if changed {
+			m := s.current()
+			s.logger.Log(
+				"cert", s.certFile, "identity", m.id.String(),
+				"expires", m.cert.Leaf.NotAfter, "action", "reload",
+			)
+		}
+	}
+}
+
+// Close stops watching the files for rotation, connections keep using the last
+// loaded certificate. Closing a nil Source is a no-op.
+func (s *Source) Close() error {
+	if s == nil {
+		return nil
+	}
+	s.closeOnce.Do(func() { close(s.done) })
+	return nil
+}
+
+func (s *Source) current() *material {
+	return s.material.Load().(*material)
+}
+
+// certificate returns our current certificate.
+func (s *Source) certificate() *tls.Certificate {
+	return s.current().cert
+}
+
+// verify verifies the peer certificate chain in rawCerts against our current
+// CA for usage and returns the identity of the peer.
+func (s *Source) verify(rawCerts [][]byte, usage x509.ExtKeyUsage) (Identity, error) {
+	if len(rawCerts) == 0 {
+		return Identity{}, errors.New("no peer certificate")
+	}
+	certs := make([]*x509.Certificate, 0, len(rawCerts))
+	for _, raw := range rawCerts {
+		cert, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return Identity{}, err
+		}
+		certs = append(certs, cert)
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	if _, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:         s.current().pool,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{usage},
+	}); err != nil {
+		return Identity{}, err
+	}
+	id, ok := identityOf(certs[0], s.trustDomain)
+	if !ok {
+		return Identity{}, ErrNoIdentity
+	}
+	return id, nil
+}
+
+// ServerConfig returns the TLS configuration of our servers. Client
+// certificates are requested and verified if presented, callers without a
+// certificate are rejected by our authorization middlewares instead of the
+// handshake, keeping health endpoints reachable. A nil Source returns nil.
+func (s *Source) ServerConfig() *tls.Config {
+	if s == nil {
+		return nil
+	}
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ClientAuth: tls.RequestClientCert,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return s.certificate(), nil
+		},
+		VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			if len(rawCerts) == 0 {
+				return nil
+			}
+			_, err := s.verify(rawCerts, x509.ExtKeyUsageClientAuth)
+			return err
+		},
+	}
+}
+
+// ClientConfig returns the TLS configuration of our clients. As our instances
+// are discovered by address, servers are verified by the identity in their
+// certificate instead of their host name. A nil Source returns nil.
+func (s *Source) ClientConfig() *tls.Config {
+	if s == nil {
+		return nil
+	}
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// the chain and identity are verified by VerifyPeerCertificate
+		InsecureSkipVerify: true,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return s.certificate(), nil
+		},
+		VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			_, err := s.verify(rawCerts, x509.ExtKeyUsageServerAuth)
+			return err
+		},
+	}
+}
+
+// Listener returns l serving TLS. A nil Source returns l as is.
+func (s *Source) Listener(l net.Listener) net.Listener {
+	if s == nil {
+		return l
+	}
+	return tls.NewListener(l, s.ServerConfig())
+}
+
+// GRPCDialOption returns the transport security of our gRPC clients. A nil
+// Source dials insecure.
+func (s *Source) GRPCDialOption() grpc.DialOption {
+	if s == nil {
+		return grpc.WithInsecure()
+	}
+	return grpc.WithTransportCredentials(credentials.NewTLS(s.ClientConfig()))
+}
+
+// HTTPTransport returns the transport of our HTTP and Twirp clients. Our
+// registries announce HTTP instances by http:// URLs, the transport upgrades
+// their requests to https. A nil Source returns http.DefaultTransport.
+func (s *Source) HTTPTransport() http.RoundTripper {
+	if s == nil {
+		return http.DefaultTransport
+	}
+	return s.transport
+}
+
+// transport upgrades plaintext requests to TLS.
+type transport struct {
+	next http.RoundTripper
+}
+
+func newTransport(s *Source) http.RoundTripper {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.TLSClientConfig = s.ClientConfig()
+	return transport{next: t}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme == "http" {
+		req = req.Clone(req.Context())
+		req.URL.Scheme = "https"
+	}
+	return t.next.RoundTrip(req)
+}
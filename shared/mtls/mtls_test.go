@@ -0,0 +1,291 @@
+package mtls
+
+import (
+	// stdlib
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	stdlog "log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	// external
+	"github.com/go-kit/kit/log"
+)
+
+// newSource returns a Source holding a certificate of service issued by ca,
+// written to dir.
+func newSource(t *testing.T, ca *DevCA, dir, service string) *Source {
+	t.Helper()
+	if err := ca.WriteFiles(dir, service); err != nil {
+		t.Fatal(err)
+	}
+	s, err := New(
+		filepath.Join(dir, service+".pem"), filepath.Join(dir, service+"-key.pem"),
+		filepath.Join(dir, "ca.pem"), ca.trustDomain, log.NewNopLogger(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// tempDir returns a temporary directory and a func removing it.
+func tempDir(t *testing.T) (string, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "mtls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+// newServer returns a server of src replying with the identity of the caller.
+// It serves TLS on an http:// URL like our services.
+func newServer(src *Source, policy Policy) *httptest.Server {
+	srv := httptest.NewUnstartedServer(src.HTTPHandler(policy, http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			id, _ := FromContext(r.Context())
+			w.Write([]byte(id.String()))
+		},
+	)))
+	// rejected handshakes are expected
+	srv.Config.ErrorLog = stdlog.New(ioutil.Discard, "", 0)
+	srv.Listener = src.Listener(srv.Listener)
+	srv.Start()
+	return srv
+}
+
+// get requests path from srv using client, returning the status and body.
+func get(t *testing.T, client *http.Client, srv *httptest.Server, path string) (int, string) {
+	t.Helper()
+	res, err := client.Get(srv.URL + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res.StatusCode, string(body)
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir, remove := tempDir(t)
+	defer remove()
+
+	ca, err := NewDevCA("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := newSource(t, ca, dir, "device")
+	defer server.Close()
+	client := newSource(t, ca, dir, "frontend")
+	defer client.Close()
+
+	if want, have := "spiffe://ocg.local/device", server.Identity().String(); want != have {
+		t.Errorf("want server identity %s, have %s", want, have)
+	}
+
+	srv := newServer(server, nil)
+	defer srv.Close()
+
+	status, body := get(t, &http.Client{Transport: client.HTTPTransport()}, srv, "/")
+	if want, have := http.StatusOK, status; want != have {
+		t.Fatalf("want status %d, have %d", want, have)
+	}
+	if want, have := "spiffe://ocg.local/frontend", body; want != have {
+		t.Errorf("want caller identity %s, have %s", want, have)
+	}
+}
+
+func TestUntrustedPeers(t *testing.T) {
+	dir, remove := tempDir(t)
+	defer remove()
+	otherDir, removeOther := tempDir(t)
+	defer removeOther()
+
+	ca, err := NewDevCA("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherCA, err := NewDevCA("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := newSource(t, ca, dir, "device")
+	defer server.Close()
+	other := newSource(t, otherCA, otherDir, "frontend")
+	defer other.Close()
+
+	srv := newServer(server, nil)
+	defer srv.Close()
+
+	// servers reject client certificates of another CA in the handshake
+	certPEM, keyPEM, err := otherCA.Issue("frontend")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &tls.Config{
+		InsecureSkipVerify: true,
+		Certificates:       []tls.Certificate{cert},
+	}
+	conn, err := tls.Dial("tcp", srv.Listener.Addr().String(), config)
+	if err == nil {
+		// TLS 1.3 clients learn about rejected certificates on first read
+		_, err = conn.Read(make([]byte, 1))
+		conn.Close()
+	}
+	if err == nil {
+		t.Error("want client certificate of another CA rejected")
+	}
+
+	// clients reject servers of another CA
+	client := &http.Client{Transport: other.HTTPTransport()}
+	if res, err := client.Get(srv.URL); err == nil {
+		res.Body.Close()
+		t.Error("want server certificate of another CA rejected")
+	}
+}
+
+func TestIdentityOf(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		uris []string
+		want Identity
+		ok   bool
+	}{
+		{name: "service", uris: []string{"spiffe://ocg.local/device"}, want: Identity{"ocg.local", "device"}, ok: true},
+		{name: "no uris"},
+		{name: "other trust domain", uris: []string{"spiffe://example.com/device"}},
+		{name: "other scheme", uris: []string{"https://ocg.local/device"}},
+		{name: "no service", uris: []string{"spiffe://ocg.local/"}},
+		{
+			name: "first of trust domain",
+			uris: []string{"spiffe://example.com/qr", "spiffe://ocg.local/event"},
+			want: Identity{"ocg.local", "event"},
+			ok:   true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cert := &x509.Certificate{}
+			for _, raw := range tc.uris {
+				uri, err := url.Parse(raw)
+				if err != nil {
+					t.Fatal(err)
+				}
+				cert.URIs = append(cert.URIs, uri)
+			}
+			id, ok := identityOf(cert, "ocg.local")
+			if want, have := tc.ok, ok; want != have {
+				t.Fatalf("want ok %t, have %t", want, have)
+			}
+			if want, have := tc.want, id; want != have {
+				t.Errorf("want identity %+v, have %+v", want, have)
+			}
+		})
+	}
+}
+
+func TestReload(t *testing.T) {
+	dir, remove := tempDir(t)
+	defer remove()
+
+	ca, err := NewDevCA("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// stop watching the files, we reload them ourselves
+	server := newSource(t, ca, dir, "device")
+	server.Close()
+	client := newSource(t, ca, dir, "frontend")
+	defer client.Close()
+
+	srv := newServer(server, nil)
+	defer srv.Close()
+
+	// rotate the certificate ahead of its key, which must be ignored until
+	// the key follows
+	certPEM, keyPEM, err := ca.Issue("event")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rotate := func(file string, b []byte, at time.Time) {
+		path := filepath.Join(dir, file)
+		if err := ioutil.WriteFile(path, b, 0600); err != nil {
+			t.Fatal(err)
+		}
+		// file systems may not tell apart writes within their time resolution
+		if err := os.Chtimes(path, at, at); err != nil {
+			t.Fatal(err)
+		}
+	}
+	now := time.Now()
+	rotate("device.pem", certPEM, now.Add(time.Minute))
+	if changed, err := server.load(); err == nil || changed {
+		t.Fatalf("want mismatched key rejected, have changed %t, err %v", changed, err)
+	}
+	if want, have := "device", server.Identity().Service; want != have {
+		t.Fatalf("want identity %s kept, have %s", want, have)
+	}
+
+	rotate("device-key.pem", keyPEM, now.Add(time.Minute))
+	if changed, err := server.load(); err != nil || !changed {
+		t.Fatalf("want reload, have changed %t, err %v", changed, err)
+	}
+	if want, have := "event", server.Identity().Service; want != have {
+		t.Errorf("want identity %s, have %s", want, have)
+	}
+
+	// new connections use the rotated certificate
+	conn, err := tls.Dial("tcp", srv.Listener.Addr().String(), client.ClientConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	presented, _ := identityOf(conn.ConnectionState().PeerCertificates[0], ca.trustDomain)
+	conn.Close()
+	if want, have := "event", presented.Service; want != have {
+		t.Errorf("want server to present %s, have %s", want, have)
+	}
+
+	if changed, err := server.load(); err != nil || changed {
+		t.Errorf("want unchanged files skipped, have changed %t, err %v", changed, err)
+	}
+}
+
+func TestClose(t *testing.T) {
+	dir, remove := tempDir(t)
+	defer remove()
+
+	ca, err := NewDevCA("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := newSource(t, ca, dir, "device")
+	if err = s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-s.done:
+	default:
+		t.Error("want watcher stopped")
+	}
+	if err = s.Close(); err != nil {
+		t.Errorf("want second close to succeed, have %v", err)
+	}
+
+	var nilSource *Source
+	if err = nilSource.Close(); err != nil {
+		t.Errorf("want nil source close to succeed, have %v", err)
+	}
+}
@@ -15,8 +15,11 @@ type BalancerType string
 
 // BalancerTypes
 const (
-	Random     BalancerType = "random"
-	RoundRobin BalancerType = "round robin"
+	Random            BalancerType = "random"
+	RoundRobin        BalancerType = "round robin"
+	LeastOutstanding  BalancerType = "least outstanding requests"
+	PowerOfTwoChoices BalancerType = "power of two choices"
+	ConsistentHash    BalancerType = "consistent hash"
 )
 
 // ClientEndpoint adds our Endpoint Tracing middleware to the existing client
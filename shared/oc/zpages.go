@@ -12,20 +12,28 @@ import (
 	"go.opencensus.io/zpages"
 
 	// project
+	"github.com/basvanbeek/opencensus-gokit-example/shared/grpcconn"
 	"github.com/basvanbeek/opencensus-gokit-example/shared/network"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/sd"
 )
 
-// ZPages handling setup
+// ZPages handling setup, next to the OpenCensus pages it serves the health
+// state of our discovered instances and the state of our gRPC connections.
 func ZPages(g run.Group, logger log.Logger) {
 	var (
 		bindIP, _   = network.HostIP()
 		listener, _ = net.Listen("tcp", bindIP+":0") // dynamic port assignment
 		addr        = listener.Addr().String()
+		router      = http.NewServeMux()
 	)
 
+	router.Handle(sd.DebugPath, sd.DebugHandler())
+	router.Handle(grpcconn.DebugPath, grpcconn.DebugHandler())
+	router.Handle("/", zpages.Handler)
+
 	g.Add(func() error {
 		level.Info(logger).Log("msg", "zpages started", "addr", "http://"+addr)
-		return http.Serve(listener, zpages.Handler)
+		return http.Serve(listener, router)
 	}, func(error) {
 		listener.Close()
 	})
@@ -0,0 +1,186 @@
+// Package problem implements a JSON error envelope for our HTTP services
+// following RFC 7807 (application/problem+json). Each error response holds the
+// errcode of the error next to the human readable description, the trace ID
+// of the failed request and optional field level details.
+package problem
+
+import (
+	// stdlib
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	// external
+	"go.opencensus.io/trace"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/shared/errcode"
+)
+
+// ContentType is the media type of problem responses.
+const ContentType = "application/problem+json"
+
+// maxBodySize limits the size of error bodies read by DecodeError.
+const maxBodySize = 64 << 10
+
+// Generic error descriptions
+const (
+	ErrorInvalidRequest = "invalid request"
+	ErrorNotFound       = "route not found"
+	ErrorRateLimited    = "rate limit exceeded"
+)
+
+// Generic errors
+var (
+	ErrInvalidRequest = errcode.New("http.invalid_request", errcode.InvalidArgument, ErrorInvalidRequest)
+	ErrNotFound       = errcode.New("http.not_found", errcode.NotFound, ErrorNotFound)
+	ErrRateLimited    = errcode.New("http.rate_limited", errcode.ResourceExhausted, ErrorRateLimited)
+)
+
+// Problem holds the details of an error response.
+type Problem struct {
+	// Type is left empty, which RFC 7807 defines as "about:blank", Title
+	// then holds the description of the HTTP status.
+	Type    string       `json:"type,omitempty"`
+	Title   string       `json:"title"`
+	Status  int          `json:"status"`
+	Code    string       `json:"code"`
+	Detail  string       `json:"detail,omitempty"`
+	TraceID string       `json:"trace_id,omitempty"`
+	Fields  []FieldError `json:"fields,omitempty"`
+}
+
+// FieldError holds the problem with a single request field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// FieldsError annotates an error with field level details.
+type FieldsError struct {
+	Err    error
+	Fields []FieldError
+}
+
+// InvalidField returns ErrInvalidRequest annotated with the error of field.
+func InvalidField(field string, err error) error {
+	return FieldsError{
+		Err:    ErrInvalidRequest,
+		Fields: []FieldError{{Field: field, Message: err.Error()}},
+	}
+}
+
+// Error implements error.
+func (e FieldsError) Error() string {
+	details := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		details = append(details, f.Field+": "+f.Message)
+	}
+	return e.Err.Error() + ": " + strings.Join(details, ", ")
+}
+
+// Unwrap returns the annotated error.
+func (e FieldsError) Unwrap() error {
+	return e.Err
+}
+
+// FromError returns the Problem describing err.
+func FromError(ctx context.Context, err error) *Problem {
+	var (
+		e      = errcode.As(err)
+		status = e.Kind().HTTPStatus()
+		p      = &Problem{
+			Title:   http.StatusText(status),
+			Status:  status,
+			Code:    e.Code(),
+			Detail:  err.Error(),
+			TraceID: traceID(ctx),
+		}
+	)
+	if f, ok := err.(FieldsError); ok {
+		p.Fields = f.Fields
+	}
+	return p
+}
+
+// Err returns the service error identified by the code of p.
+func (p *Problem) Err() error {
+	err := errcode.Decode(p.Code, errcode.HTTPKind(p.Status), p.Detail)
+	if len(p.Fields) > 0 {
+		return FieldsError{Err: err, Fields: p.Fields}
+	}
+	return err
+}
+
+// EncodeError writes err as Problem. It can be used as Go kit
+// ServerErrorEncoder.
+func EncodeError(ctx context.Context, err error, w http.ResponseWriter) {
+	Write(w, FromError(ctx, err))
+}
+
+// Write writes p as response.
+func Write(w http.ResponseWriter, p *Problem) {
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
+}
+
+// Error replies to the request with a Problem describing err. It replaces
+// http.Error for handlers outside of Go kit.
+func Error(w http.ResponseWriter, r *http.Request, err error) {
+	Write(w, FromError(r.Context(), err))
+}
+
+// NotFoundHandler replies to requests of unknown routes.
+func NotFoundHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Error(w, r, ErrNotFound)
+	})
+}
+
+// MethodNotAllowedHandler replies to requests of known routes using an
+// unsupported method.
+func MethodNotAllowedHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p := FromError(r.Context(), ErrInvalidRequest)
+		p.Title = http.StatusText(http.StatusMethodNotAllowed)
+		p.Status = http.StatusMethodNotAllowed
+		p.Detail = "method not allowed"
+		Write(w, p)
+	})
+}
+
+// DecodeError decodes the error response r and returns the service error it
+// describes. Responses of servers or proxies not returning a Problem are
+// converted using their status and body.
+func DecodeError(r *http.Response) error {
+	b, err := ioutil.ReadAll(io.LimitReader(r.Body, maxBodySize))
+	if err != nil {
+		return err
+	}
+
+	var p Problem
+	if strings.HasPrefix(r.Header.Get("Content-Type"), ContentType) &&
+		json.Unmarshal(b, &p) == nil && p.Code != "" {
+		if p.Status == 0 {
+			p.Status = r.StatusCode
+		}
+		return p.Err()
+	}
+
+	return errcode.Decode(
+		"", errcode.HTTPKind(r.StatusCode), strings.TrimSpace(string(b)),
+	)
+}
+
+// traceID returns the trace ID of the span found in ctx.
+func traceID(ctx context.Context) string {
+	if span := trace.FromContext(ctx); span != nil {
+		return span.SpanContext().TraceID.String()
+	}
+	return ""
+}
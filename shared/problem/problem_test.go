@@ -0,0 +1,209 @@
+package problem
+
+import (
+	// stdlib
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	// external
+	"go.opencensus.io/trace"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/shared/errcode"
+)
+
+var errTestConflict = errcode.New("problem_test.conflict", errcode.AlreadyExists, "event exists")
+
+// encode returns the response of EncodeError for err.
+func encode(ctx context.Context, err error) *http.Response {
+	w := httptest.NewRecorder()
+	EncodeError(ctx, err, w)
+	return w.Result()
+}
+
+func TestEncodeError(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{name: "registered", err: errTestConflict, status: http.StatusConflict, code: "problem_test.conflict"},
+		{name: "wrapped", err: fmt.Errorf("create: %w", errTestConflict), status: http.StatusConflict, code: "problem_test.conflict"},
+		{name: "rate limited", err: ErrRateLimited, status: http.StatusTooManyRequests, code: "http.rate_limited"},
+		{name: "plain", err: errors.New("database closed"), status: http.StatusInternalServerError, code: errcode.CodeInternal},
+	} {
+		res := encode(context.Background(), tc.err)
+		if want, have := tc.status, res.StatusCode; want != have {
+			t.Errorf("%s: want status %d, have %d", tc.name, want, have)
+		}
+		if want, have := ContentType, res.Header.Get("Content-Type"); want != have {
+			t.Errorf("%s: want content type %s, have %s", tc.name, want, have)
+		}
+		if want, have := "nosniff", res.Header.Get("X-Content-Type-Options"); want != have {
+			t.Errorf("%s: want X-Content-Type-Options %s, have %s", tc.name, want, have)
+		}
+
+		var p Problem
+		if err := json.NewDecoder(res.Body).Decode(&p); err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		want := Problem{
+			Title:  http.StatusText(tc.status),
+			Status: tc.status,
+			Code:   tc.code,
+			Detail: tc.err.Error(),
+		}
+		if !reflect.DeepEqual(want, p) {
+			t.Errorf("%s: want %+v, have %+v", tc.name, want, p)
+		}
+	}
+}
+
+func TestEncodeErrorTraceID(t *testing.T) {
+	ctx, span := trace.StartSpan(context.Background(), "test", trace.WithSampler(trace.AlwaysSample()))
+	defer span.End()
+
+	var p Problem
+	if err := json.NewDecoder(encode(ctx, errTestConflict).Body).Decode(&p); err != nil {
+		t.Fatal(err)
+	}
+	if want, have := span.SpanContext().TraceID.String(), p.TraceID; want != have {
+		t.Errorf("want trace ID %s, have %s", want, have)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, err := range []error{errTestConflict, ErrInvalidRequest, ErrNotFound, ErrRateLimited} {
+		if want, have := err, DecodeError(encode(context.Background(), err)); want != have {
+			t.Errorf("want %v, have %v", want, have)
+		}
+	}
+
+	// field details survive the round trip
+	err := InvalidField("starts_at", errors.New("must be before ends_at"))
+	have := DecodeError(encode(context.Background(), err))
+	if !reflect.DeepEqual(err, have) {
+		t.Errorf("want %+v, have %+v", err, have)
+	}
+	if !errors.Is(have, ErrInvalidRequest) {
+		t.Errorf("want %v in chain of %v", ErrInvalidRequest, have)
+	}
+}
+
+func TestDecodeError(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		status      int
+		contentType string
+		body        string
+		want        errcode.Error
+	}{
+		{
+			name:        "unknown code",
+			status:      http.StatusConflict,
+			contentType: ContentType,
+			body:        `{"title":"Conflict","status":409,"code":"problem_test.unknown","detail":"newer error"}`,
+			want:        *errcode.Decode("problem_test.unknown", errcode.AlreadyExists, "newer error").(*errcode.Error),
+		},
+		{
+			name:        "status from response",
+			status:      http.StatusNotFound,
+			contentType: ContentType + "; charset=utf-8",
+			body:        `{"code":"problem_test.unknown","detail":"gone"}`,
+			want:        *errcode.Decode("problem_test.unknown", errcode.NotFound, "gone").(*errcode.Error),
+		},
+		{
+			name:        "proxy error page",
+			status:      http.StatusBadGateway,
+			contentType: "text/html",
+			body:        "<html>bad gateway</html>\n",
+			want:        *errcode.Decode("", errcode.Internal, "<html>bad gateway</html>").(*errcode.Error),
+		},
+		{
+			name:   "plain text",
+			status: http.StatusServiceUnavailable,
+			body:   "upstream connect error\n",
+			want:   *errcode.Decode("", errcode.Unavailable, "upstream connect error").(*errcode.Error),
+		},
+		{
+			name:        "json without problem content type",
+			status:      http.StatusConflict,
+			contentType: "application/json",
+			body:        `{"code":"problem_test.conflict"}`,
+			want:        *errcode.Decode("", errcode.AlreadyExists, `{"code":"problem_test.conflict"}`).(*errcode.Error),
+		},
+		{
+			name:        "problem without code",
+			status:      http.StatusMethodNotAllowed,
+			contentType: ContentType,
+			body:        `{"title":"Method Not Allowed","status":405}`,
+			want:        *errcode.Decode("", errcode.InvalidArgument, `{"title":"Method Not Allowed","status":405}`).(*errcode.Error),
+		},
+		{
+			name:        "malformed problem",
+			status:      http.StatusInternalServerError,
+			contentType: ContentType,
+			body:        `{"code":`,
+			want:        *errcode.Decode("", errcode.Internal, `{"code":`).(*errcode.Error),
+		},
+	} {
+		res := &http.Response{
+			StatusCode: tc.status,
+			Header:     http.Header{},
+			Body:       http.NoBody,
+		}
+		if tc.contentType != "" {
+			res.Header.Set("Content-Type", tc.contentType)
+		}
+		if tc.body != "" {
+			w := httptest.NewRecorder()
+			w.WriteString(tc.body)
+			res.Body = w.Result().Body
+		}
+		if have := *errcode.As(DecodeError(res)); tc.want != have {
+			t.Errorf("%s: want %+v, have %+v", tc.name, tc.want, have)
+		}
+	}
+}
+
+func TestDecodeErrorBodySize(t *testing.T) {
+	w := httptest.NewRecorder()
+	w.WriteHeader(http.StatusBadGateway)
+	w.WriteString(strings.Repeat("x", 2*maxBodySize))
+	if want, have := maxBodySize, len(DecodeError(w.Result()).Error()); want != have {
+		t.Errorf("want error message of %d bytes, have %d", want, have)
+	}
+}
+
+func TestHandlers(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		handler http.Handler
+		status  int
+		want    error
+	}{
+		{name: "not found", handler: NotFoundHandler(), status: http.StatusNotFound, want: ErrNotFound},
+		{name: "method not allowed", handler: MethodNotAllowedHandler(), status: http.StatusMethodNotAllowed, want: ErrInvalidRequest},
+	} {
+		w := httptest.NewRecorder()
+		tc.handler.ServeHTTP(w, httptest.NewRequest("GET", "/events", nil))
+		res := w.Result()
+		if want, have := tc.status, res.StatusCode; want != have {
+			t.Errorf("%s: want status %d, have %d", tc.name, want, have)
+		}
+		if want, have := ContentType, res.Header.Get("Content-Type"); want != have {
+			t.Errorf("%s: want content type %s, have %s", tc.name, want, have)
+		}
+		if want, have := tc.want, DecodeError(res); want != have {
+			t.Errorf("%s: want %v, have %v", tc.name, want, have)
+		}
+	}
+}
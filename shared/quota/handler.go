@@ -0,0 +1,88 @@
+package quota
+
+import (
+	// stdlib
+	"encoding/json"
+	"net/http"
+	"time"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/kevinburke/go.uuid"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/shared/problem"
+)
+
+// reportDays is the amount of days reported if no from day is requested.
+const reportDays = 30
+
+// Report holds the usage report served by the usage report handler.
+type Report struct {
+	From   string  `json:"from"`
+	To     string  `json:"to"`
+	Limits Limits  `json:"limits"`
+	Usage  []Usage `json:"usage"`
+}
+
+// NewHandler returns an http.Handler serving the usage metered by meter per
+// tenant per day on GET requests. The optional tenant_id query parameter
+// restricts the report to a single tenant, the optional from and to query
+// parameters select the reported days, defaulting to the last 30 days.
+func NewHandler(meter *Meter, logger log.Logger) http.Handler {
+	logger = log.With(logger, "handler", "quota")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			problem.MethodNotAllowedHandler().ServeHTTP(w, r)
+			return
+		}
+
+		var (
+			err      error
+			query    = r.URL.Query()
+			tenantID = uuid.Nil
+			to       = time.Now().UTC()
+			from     = to.AddDate(0, 0, 1-reportDays)
+		)
+		if s := query.Get("tenant_id"); s != "" {
+			if tenantID, err = uuid.FromString(s); err != nil {
+				problem.Error(w, r, problem.InvalidField("tenant_id", err))
+				return
+			}
+		}
+		if s := query.Get("to"); s != "" {
+			if to, err = time.Parse(DayFormat, s); err != nil {
+				problem.Error(w, r, problem.InvalidField("to", err))
+				return
+			}
+			from = to.AddDate(0, 0, 1-reportDays)
+		}
+		if s := query.Get("from"); s != "" {
+			if from, err = time.Parse(DayFormat, s); err != nil {
+				problem.Error(w, r, problem.InvalidField("from", err))
+				return
+			}
+		}
+
+		usage, err := meter.Usage(r.Context(), tenantID, from, to)
+		if err != nil {
+			level.Error(logger).Log("err", err)
+			problem.Error(w, r, err)
+			return
+		}
+		if usage == nil {
+			usage = []Usage{}
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		json.NewEncoder(w).Encode(Report{
+			From:   from.Format(DayFormat),
+			To:     to.Format(DayFormat),
+			Limits: meter.Limits(),
+			Usage:  usage,
+		})
+	})
+}
@@ -0,0 +1,78 @@
+package quota
+
+import (
+	// stdlib
+	"context"
+	"sync"
+
+	// external
+	"github.com/kevinburke/go.uuid"
+)
+
+type usageKey struct {
+	tenantID uuid.UUID
+	day      string
+}
+
+// MemoryStore is a Store holding usage in memory. Usage is lost on restart
+// and not shared between instances.
+type MemoryStore struct {
+	mtx   sync.Mutex
+	usage map[usageKey]map[Resource]int64
+}
+
+// NewMemoryStore returns a new MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{usage: make(map[usageKey]map[Resource]int64)}
+}
+
+// Add implements Store.
+func (s *MemoryStore) Add(
+	_ context.Context, tenantID uuid.UUID, resource Resource, day string,
+	n, limit int64,
+) (bool, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	key := usageKey{tenantID: tenantID, day: day}
+	counts, ok := s.usage[key]
+	if !ok {
+		counts = make(map[Resource]int64)
+		s.usage[key] = counts
+	}
+
+	if limit > 0 && n >= 0 {
+		if (n == 0 && counts[resource] >= limit) || counts[resource]+n > limit {
+			return false, nil
+		}
+	}
+	if counts[resource] += n; counts[resource] < 0 {
+		counts[resource] = 0
+	}
+	return true, nil
+}
+
+// Usage implements Store.
+func (s *MemoryStore) Usage(
+	_ context.Context, tenantID uuid.UUID, from, to string,
+) ([]Usage, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	var usage []Usage
+	for key, counts := range s.usage {
+		if !uuid.Equal(tenantID, uuid.Nil) && !uuid.Equal(key.tenantID, tenantID) {
+			continue
+		}
+		if key.day < from || key.day > to {
+			continue
+		}
+		u := Usage{TenantID: key.tenantID, Day: key.day, Counts: make(map[Resource]int64, len(counts))}
+		for resource, count := range counts {
+			u.Counts[resource] = count
+		}
+		usage = append(usage, u)
+	}
+	sortUsage(usage)
+	return usage, nil
+}
@@ -0,0 +1,79 @@
+package quota
+
+import (
+	// stdlib
+	"context"
+
+	// external
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/kevinburke/go.uuid"
+)
+
+// Rule describes how an endpoint uses a metered resource.
+type Rule struct {
+	Resource Resource
+	// Request returns the tenant and the amount of resources requested.
+	// Requests of uuid.Nil tenants are not metered. Errors are returned to
+	// the caller without calling the endpoint.
+	Request func(ctx context.Context, request interface{}) (uuid.UUID, int64, error)
+	// Response optionally returns the amount of resources used by a
+	// successful request, for endpoints of which the amount isn't known up
+	// front. Such requests are allowed while the tenant is below its limit
+	// and may take the tenant's usage over it.
+	Response func(response interface{}) int64
+	// Attempts meters failed requests as well, e.g. for unlock attempts.
+	// Otherwise resources of failed requests are returned.
+	Attempts bool
+}
+
+// Middleware enforces the rule on an endpoint, returning exceeded if the
+// tenant's daily limit is exceeded. Failing to meter is logged but does not
+// fail the request.
+func Middleware(meter *Meter, rule Rule, exceeded error, logger log.Logger) endpoint.Middleware {
+	logger = log.With(logger, "middleware", "quota", "resource", rule.Resource)
+
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			tenantID, n, err := rule.Request(ctx, request)
+			if err != nil {
+				return nil, err
+			}
+			if uuid.Equal(tenantID, uuid.Nil) || (n == 0 && rule.Response == nil) {
+				return next(ctx, request)
+			}
+
+			day := today()
+			switch err = meter.take(ctx, tenantID, rule.Resource, day, n); err {
+			case nil:
+			case ErrQuotaExceeded:
+				level.Warn(logger).Log("tenant", tenantID, "requested", n, "err", err)
+				return nil, exceeded
+			default:
+				level.Error(logger).Log("tenant", tenantID, "err", err)
+				return next(ctx, request)
+			}
+
+			response, err := next(ctx, request)
+
+			failed := err != nil
+			if f, ok := response.(endpoint.Failer); ok && f.Failed() != nil {
+				failed = true
+			}
+
+			var used int64
+			switch {
+			case failed && !rule.Attempts:
+				used = -n
+			case !failed && rule.Response != nil:
+				used = rule.Response(response) - n
+			}
+			if mErr := meter.record(ctx, tenantID, rule.Resource, day, used); mErr != nil {
+				level.Error(logger).Log("tenant", tenantID, "err", mErr)
+			}
+
+			return response, err
+		}
+	}
+}
@@ -0,0 +1,166 @@
+// Package quota implements per tenant daily quotas and usage metering. Quotas
+// are enforced by endpoint middleware metering the resources used by each
+// request.
+package quota
+
+import (
+	// stdlib
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	// external
+	"github.com/kevinburke/go.uuid"
+)
+
+// DayFormat is the format of the days usage is metered by. Days are in UTC.
+const DayFormat = "2006-01-02"
+
+// Resource identifies a metered resource.
+type Resource string
+
+// Metered resources
+const (
+	Events         Resource = "events"
+	Devices        Resource = "devices"
+	QRGenerations  Resource = "qr_generations"
+	UnlockAttempts Resource = "unlock_attempts"
+)
+
+// Quota Error descriptions
+const (
+	ErrorQuotaExceeded = "quota exceeded"
+	ErrorInvalidLimits = "invalid quota limits"
+)
+
+// Quota Errors
+var (
+	ErrQuotaExceeded = errors.New(ErrorQuotaExceeded)
+	ErrInvalidLimits = errors.New(ErrorInvalidLimits)
+)
+
+// Limits holds the daily limit per resource for each tenant. Resources
+// without a limit, or with a limit below 1, are metered but not limited.
+type Limits map[Resource]int64
+
+// DefaultLimits holds the daily limits used if none are configured.
+var DefaultLimits = Limits{
+	Events:         1000,
+	Devices:        1000,
+	QRGenerations:  500,
+	UnlockAttempts: 100,
+}
+
+// ParseLimits parses a comma separated list of resource=limit pairs, e.g.
+// "events=100,unlock_attempts=10", overriding the DefaultLimits. A limit of 0
+// disables the limit of the resource.
+func ParseLimits(s string) (Limits, error) {
+	limits := make(Limits, len(DefaultLimits))
+	for resource, limit := range DefaultLimits {
+		limits[resource] = limit
+	}
+	if s = strings.TrimSpace(s); s == "" {
+		return limits, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		kv := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("%v: %q", ErrInvalidLimits, pair)
+		}
+		resource := Resource(strings.TrimSpace(kv[0]))
+		if _, ok := DefaultLimits[resource]; !ok {
+			return nil, fmt.Errorf("%v: unknown resource %q", ErrInvalidLimits, resource)
+		}
+		limit, err := strconv.ParseInt(strings.TrimSpace(kv[1]), 10, 64)
+		if err != nil || limit < 0 {
+			return nil, fmt.Errorf("%v: %q", ErrInvalidLimits, pair)
+		}
+		limits[resource] = limit
+	}
+	return limits, nil
+}
+
+// Usage holds the metered usage of a tenant on a single day.
+type Usage struct {
+	TenantID uuid.UUID          `json:"tenant_id"`
+	Day      string             `json:"day"`
+	Counts   map[Resource]int64 `json:"counts"`
+}
+
+// Store holds metered usage.
+type Store interface {
+	// Add adds n to the usage of the resource by the tenant on day, unless
+	// the result would exceed limit. Reservations of 0 resources only check
+	// the usage is below limit. A limit below 1 means no limit. Negative
+	// amounts are used to return resources.
+	Add(ctx context.Context, tenantID uuid.UUID, resource Resource, day string, n, limit int64) (bool, error)
+	// Usage returns the usage of the tenant, or of all tenants if tenantID is
+	// uuid.Nil, from and to day inclusive ordered by day and tenant.
+	Usage(ctx context.Context, tenantID uuid.UUID, from, to string) ([]Usage, error)
+}
+
+// Meter enforces the limits on the usage of the tenants.
+type Meter struct {
+	store  Store
+	limits Limits
+}
+
+// NewMeter returns a Meter enforcing limits using store.
+func NewMeter(store Store, limits Limits) *Meter {
+	return &Meter{store: store, limits: limits}
+}
+
+// Limits returns the limits enforced by the Meter.
+func (m *Meter) Limits() Limits {
+	return m.limits
+}
+
+// Take meters n resources used by the tenant today. It returns
+// ErrQuotaExceeded if this would exceed the tenant's daily limit.
+func (m *Meter) Take(ctx context.Context, tenantID uuid.UUID, resource Resource, n int64) error {
+	return m.take(ctx, tenantID, resource, today(), n)
+}
+
+// Usage returns the usage of the tenant, or of all tenants if tenantID is
+// uuid.Nil, for the days from and to inclusive.
+func (m *Meter) Usage(ctx context.Context, tenantID uuid.UUID, from, to time.Time) ([]Usage, error) {
+	return m.store.Usage(ctx, tenantID, from.UTC().Format(DayFormat), to.UTC().Format(DayFormat))
+}
+
+func (m *Meter) take(ctx context.Context, tenantID uuid.UUID, resource Resource, day string, n int64) error {
+	ok, err := m.store.Add(ctx, tenantID, resource, day, n, m.limits[resource])
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrQuotaExceeded
+	}
+	return nil
+}
+
+// record meters n resources regardless of the limit.
+func (m *Meter) record(ctx context.Context, tenantID uuid.UUID, resource Resource, day string, n int64) error {
+	if n == 0 {
+		return nil
+	}
+	_, err := m.store.Add(ctx, tenantID, resource, day, n, 0)
+	return err
+}
+
+func today() string {
+	return time.Now().UTC().Format(DayFormat)
+}
+
+// sortUsage orders usage by day and tenant.
+func sortUsage(usage []Usage) {
+	sort.Slice(usage, func(i, j int) bool {
+		if usage[i].Day != usage[j].Day {
+			return usage[i].Day < usage[j].Day
+		}
+		return usage[i].TenantID.String() < usage[j].TenantID.String()
+	})
+}
@@ -0,0 +1,40 @@
+package sqlite
+
+import (
+	// external
+	"github.com/jmoiron/sqlx"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/shared/migrate"
+)
+
+// Schema holds the quota schema migrations.
+var Schema = migrate.Schema{
+	Name: "ocgokitexample.quota",
+	Migrations: []migrate.Migration{
+		{
+			Version: 1, Description: "add quota usage",
+			Creates: "quota_usage", Up: v1,
+		},
+	},
+}
+
+func v1(tx *sqlx.Tx) (err error) {
+	// add quota usage table, day holds the quota.DayFormat formatted day
+	if _, err = tx.Exec(`
+		CREATE TABLE quota_usage (
+			tenant_id BLOB NOT NULL, day TEXT NOT NULL, resource TEXT NOT NULL,
+			count INTEGER NOT NULL, PRIMARY KEY(tenant_id, day, resource)
+		) WITHOUT ROWID;`,
+	); err != nil {
+		return
+	}
+
+	if _, err = tx.Exec(
+		`CREATE INDEX idx_quota_usage_day ON quota_usage (day, tenant_id);`,
+	); err != nil {
+		return
+	}
+
+	return
+}
@@ -0,0 +1,111 @@
+// Package sqlite implements a quota.Store in a SQLite database, allowing
+// instances on the same host to share their metered usage.
+package sqlite
+
+import (
+	// stdlib
+	"context"
+	"database/sql"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/jmoiron/sqlx"
+	"github.com/kevinburke/go.uuid"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/shared/migrate"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/quota"
+)
+
+type sqlite struct {
+	db *sqlx.DB
+}
+
+// New returns a new quota.Store backed by SQLite.
+func New(db *sqlx.DB, logger log.Logger) (quota.Store, error) {
+	// run our embedded database migrations
+	if err := migrate.Up(
+		context.Background(), db, migrate.SQLite, Schema, logger,
+	); err != nil {
+		return nil, err
+	}
+
+	return &sqlite{db: db}, nil
+}
+
+// Add implements quota.Store. The limit is checked and the usage updated in a
+// single statement so concurrent instances don't need a transaction.
+func (s *sqlite) Add(
+	ctx context.Context, tenantID uuid.UUID, resource quota.Resource,
+	day string, n, limit int64,
+) (bool, error) {
+	if _, err := s.db.ExecContext(ctx, `
+		INSERT INTO quota_usage (tenant_id, day, resource, count)
+		VALUES (?, ?, ?, 0) ON CONFLICT DO NOTHING`,
+		tenantID.Bytes(), day, string(resource),
+	); err != nil {
+		return false, err
+	}
+
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE quota_usage SET count = max(count + :n, 0)
+		WHERE tenant_id = :tenant_id AND day = :day AND resource = :resource
+		AND (
+			:limit < 1 OR :n < 0 OR
+			(:n = 0 AND count < :limit) OR (:n > 0 AND count + :n <= :limit)
+		)`,
+		sql.Named("tenant_id", tenantID.Bytes()), sql.Named("day", day),
+		sql.Named("resource", string(resource)), sql.Named("n", n),
+		sql.Named("limit", limit),
+	)
+	if err != nil {
+		return false, err
+	}
+	cnt, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return cnt > 0, nil
+}
+
+// Usage implements quota.Store.
+func (s *sqlite) Usage(
+	ctx context.Context, tenantID uuid.UUID, from, to string,
+) ([]quota.Usage, error) {
+	query := `SELECT tenant_id, day, resource, count FROM quota_usage
+		WHERE day >= ? AND day <= ?`
+	args := []interface{}{from, to}
+	if !uuid.Equal(tenantID, uuid.Nil) {
+		query += ` AND tenant_id = ?`
+		args = append(args, tenantID.Bytes())
+	}
+	query += ` ORDER BY day, tenant_id`
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var usage []quota.Usage
+	for rows.Next() {
+		var (
+			id       []byte
+			day      string
+			resource string
+			count    int64
+		)
+		if err = rows.Scan(&id, &day, &resource, &count); err != nil {
+			return nil, err
+		}
+		tenantID := uuid.FromBytesOrNil(id)
+		if last := len(usage) - 1; last < 0 || usage[last].Day != day ||
+			!uuid.Equal(usage[last].TenantID, tenantID) {
+			usage = append(usage, quota.Usage{
+				TenantID: tenantID, Day: day, Counts: make(map[quota.Resource]int64),
+			})
+		}
+		usage[len(usage)-1].Counts[quota.Resource(resource)] = count
+	}
+	return usage, rows.Err()
+}
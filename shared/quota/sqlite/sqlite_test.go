@@ -0,0 +1,103 @@
+package sqlite
+
+import (
+	// stdlib
+	"context"
+	"reflect"
+	"testing"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/jmoiron/sqlx"
+	"github.com/kevinburke/go.uuid"
+	_ "github.com/mattn/go-sqlite3"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/shared/quota"
+)
+
+// TestMatchesMemoryStore checks the SQLite store meters like the MemoryStore.
+func TestMatchesMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	db := sqlx.MustOpen("sqlite3", ":memory:")
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	store, err := New(db, log.NewNopLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	memory := quota.NewMemoryStore()
+
+	var (
+		tenantA = uuid.NewV4()
+		tenantB = uuid.NewV4()
+	)
+	for i, op := range []struct {
+		tenantID uuid.UUID
+		resource quota.Resource
+		day      string
+		n, limit int64
+	}{
+		{tenantA, quota.Events, "2019-01-01", 2, 3},
+		{tenantA, quota.Events, "2019-01-01", 2, 3},  // exceeds the limit
+		{tenantA, quota.Events, "2019-01-01", 1, 3},  // reaches the limit
+		{tenantA, quota.Events, "2019-01-01", 0, 3},  // checks the limit
+		{tenantA, quota.Events, "2019-01-01", -1, 3}, // returns a resource
+		{tenantA, quota.Events, "2019-01-01", 0, 3},
+		{tenantA, quota.Devices, "2019-01-01", 5, 0}, // unlimited
+		{tenantA, quota.Devices, "2019-01-01", -9, 0},
+		{tenantB, quota.Events, "2019-01-01", 4, 3}, // exceeds on first use
+		{tenantB, quota.Events, "2019-01-02", 1, 3},
+		{tenantA, quota.Events, "2019-01-03", 1, 3},
+	} {
+		want, err := memory.Add(ctx, op.tenantID, op.resource, op.day, op.n, op.limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		have, err := store.Add(ctx, op.tenantID, op.resource, op.day, op.n, op.limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want != have {
+			t.Errorf("operation %d: want allowed %t, have %t", i, want, have)
+		}
+	}
+
+	for _, tc := range []struct {
+		name     string
+		tenantID uuid.UUID
+		from, to string
+	}{
+		{"all tenants", uuid.Nil, "2019-01-01", "2019-01-03"},
+		{"single tenant", tenantA, "2019-01-01", "2019-01-03"},
+		{"single day", uuid.Nil, "2019-01-02", "2019-01-02"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			want, err := memory.Usage(ctx, tc.tenantID, tc.from, tc.to)
+			if err != nil {
+				t.Fatal(err)
+			}
+			have, err := store.Usage(ctx, tc.tenantID, tc.from, tc.to)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(withoutZero(want), withoutZero(have)) {
+				t.Errorf("want usage %+v, have %+v", want, have)
+			}
+		})
+	}
+}
+
+// withoutZero drops zero counts, as a rejected first reservation records an
+// empty day in memory and a zero count in SQLite.
+func withoutZero(usage []quota.Usage) []quota.Usage {
+	for _, u := range usage {
+		for resource, count := range u.Counts {
+			if count == 0 {
+				delete(u.Counts, resource)
+			}
+		}
+	}
+	return usage
+}
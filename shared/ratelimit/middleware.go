@@ -0,0 +1,120 @@
+package ratelimit
+
+import (
+	// stdlib
+	"net"
+	"net/http"
+	"strconv"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/gorilla/mux"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/shared/problem"
+)
+
+// Tags of our rejection measure
+var (
+	KeyRoute = tag.MustNewKey("route")
+	KeyScope = tag.MustNewKey("scope")
+)
+
+// MeasureRejections counts the requests rejected by rate limiting.
+var MeasureRejections = stats.Int64(
+	"http/ratelimit/rejections", "Requests rejected by rate limiting", stats.UnitDimensionless,
+)
+
+// RejectionsView counts the rejected requests per route and scope.
+var RejectionsView = &view.View{
+	Name:        "http/ratelimit/rejections",
+	Description: "Count of requests rejected by rate limiting",
+	Measure:     MeasureRejections,
+	Aggregation: view.Count(),
+	TagKeys:     []tag.Key{KeyRoute, KeyScope},
+}
+
+// TenantFunc returns the tenant of a request, or an empty string if unknown.
+type TenantFunc func(r *http.Request) string
+
+// Middleware rate limits requests per route, per tenant and per client IP
+// using the limits in config. Routes are identified by their mux route name,
+// so the middleware needs to be added to the router with Router.Use. Responses
+// carry RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers of
+// the most restrictive limit. Rejected requests receive 429 Too Many Requests
+// with a Retry-After header. Failing to reach the store is logged and does not
+// fail the request.
+func Middleware(store Store, config Config, tenant TenantFunc, logger log.Logger) mux.MiddlewareFunc {
+	logger = log.With(logger, "middleware", "ratelimit")
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var route string
+			if current := mux.CurrentRoute(r); current != nil {
+				route = current.GetName()
+			}
+
+			keys := map[Scope]string{
+				ScopeIP:     clientIP(r),
+				ScopeTenant: tenant(r),
+			}
+
+			var (
+				header   *Result
+				rejected *Result
+			)
+			for _, scope := range []Scope{ScopeIP, ScopeTenant} {
+				limit := config.Limit(route, scope)
+				if keys[scope] == "" || limit.Unlimited() {
+					continue
+				}
+				res, err := store.Take(r.Context(), string(scope)+"/"+route+"/"+keys[scope], limit)
+				if err != nil {
+					level.Error(logger).Log("route", route, "scope", scope, "err", err)
+					continue
+				}
+				if header == nil || res.Remaining < header.Remaining {
+					header = &res
+				}
+				if !res.Allowed {
+					stats.RecordWithTags(r.Context(), []tag.Mutator{
+						tag.Upsert(KeyRoute, route),
+						tag.Upsert(KeyScope, string(scope)),
+					}, MeasureRejections.M(1))
+					level.Debug(logger).Log("route", route, "scope", scope, "key", keys[scope], "msg", "rejected")
+					if rejected == nil || res.RetryAfter > rejected.RetryAfter {
+						rejected = &res
+					}
+				}
+			}
+
+			if header != nil {
+				h := w.Header()
+				h.Set("RateLimit-Limit", strconv.Itoa(header.Limit))
+				h.Set("RateLimit-Remaining", strconv.Itoa(header.Remaining))
+				h.Set("RateLimit-Reset", strconv.Itoa(int(header.Reset.Seconds())))
+			}
+			if rejected != nil {
+				w.Header().Set("Retry-After", strconv.Itoa(int(rejected.RetryAfter.Seconds())))
+				problem.Error(w, r, problem.ErrRateLimited)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// clientIP returns the IP address of the client. Forwarding headers are not
+// trusted.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
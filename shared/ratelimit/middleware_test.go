@@ -0,0 +1,128 @@
+package ratelimit
+
+import (
+	// stdlib
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/shared/problem"
+)
+
+// newRouter returns a router rate limited by config, taking the tenant from
+// the X-Tenant header.
+func newRouter(config Config) http.Handler {
+	router := mux.NewRouter()
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	router.Methods("GET").Path("/events").Handler(ok).Name("event_list")
+	router.Methods("POST").Path("/login").Handler(ok).Name("login")
+	router.Use(Middleware(NewMemoryStore(), config, func(r *http.Request) string {
+		return r.Header.Get("X-Tenant")
+	}, log.NewNopLogger()))
+	return router
+}
+
+func request(h http.Handler, method, path, ip, tenant string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, nil)
+	r.RemoteAddr = ip + ":51234"
+	if tenant != "" {
+		r.Header.Set("X-Tenant", tenant)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestMiddlewareHeaders(t *testing.T) {
+	h := newRouter(Config{
+		string(ScopeTenant): {Rate: 1, Burst: 2},
+		string(ScopeIP):     {Rate: 1, Burst: 10},
+	})
+
+	// the headers describe the most restrictive limit
+	w := request(h, "GET", "/events", "10.0.0.1", "t1")
+	if want, have := http.StatusOK, w.Code; want != have {
+		t.Fatalf("want status %d, have %d", want, have)
+	}
+	for header, want := range map[string]string{
+		"RateLimit-Limit":     "2",
+		"RateLimit-Remaining": "1",
+		"RateLimit-Reset":     "1",
+		"Retry-After":         "",
+	} {
+		if have := w.Header().Get(header); want != have {
+			t.Errorf("%s: want %q, have %q", header, want, have)
+		}
+	}
+
+	request(h, "GET", "/events", "10.0.0.1", "t1")
+	w = request(h, "GET", "/events", "10.0.0.1", "t1")
+	if want, have := http.StatusTooManyRequests, w.Code; want != have {
+		t.Fatalf("want status %d, have %d", want, have)
+	}
+	for header, want := range map[string]string{
+		"RateLimit-Limit":     "2",
+		"RateLimit-Remaining": "0",
+		"Retry-After":         "1",
+		"Content-Type":        problem.ContentType,
+	} {
+		if have := w.Header().Get(header); want != have {
+			t.Errorf("%s: want %q, have %q", header, want, have)
+		}
+	}
+}
+
+func TestMiddlewareKeys(t *testing.T) {
+	h := newRouter(Config{
+		string(ScopeTenant):            {Rate: 0.001, Burst: 1},
+		string(ScopeIP):                {Rate: 0.001, Burst: 2},
+		"login." + string(ScopeIP):     {Rate: 0.001, Burst: 1},
+		"login." + string(ScopeTenant): {},
+	})
+
+	for _, tc := range []struct {
+		name   string
+		method string
+		path   string
+		ip     string
+		tenant string
+		status int
+	}{
+		{name: "first of tenant", method: "GET", path: "/events", ip: "10.0.0.1", tenant: "t1", status: http.StatusOK},
+		{name: "tenant exhausted", method: "GET", path: "/events", ip: "10.0.0.2", tenant: "t1", status: http.StatusTooManyRequests},
+		{name: "other tenant", method: "GET", path: "/events", ip: "10.0.0.1", tenant: "t2", status: http.StatusOK},
+		// the tenant and IP buckets are taken from even if another rejects
+		{name: "ip exhausted", method: "GET", path: "/events", ip: "10.0.0.1", tenant: "t3", status: http.StatusTooManyRequests},
+		{name: "other route", method: "POST", path: "/login", ip: "10.0.0.1", status: http.StatusOK},
+		{name: "route limit", method: "POST", path: "/login", ip: "10.0.0.1", status: http.StatusTooManyRequests},
+		{name: "other ip", method: "POST", path: "/login", ip: "10.0.0.3", status: http.StatusOK},
+		// the login route is not limited per tenant
+		{name: "unlimited scope", method: "POST", path: "/login", ip: "10.0.0.4", tenant: "t1", status: http.StatusOK},
+		{name: "unlimited scope again", method: "POST", path: "/login", ip: "10.0.0.5", tenant: "t1", status: http.StatusOK},
+	} {
+		if want, have := tc.status, request(h, tc.method, tc.path, tc.ip, tc.tenant).Code; want != have {
+			t.Fatalf("%s: want status %d, have %d", tc.name, want, have)
+		}
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	for addr, want := range map[string]string{
+		"10.0.0.1:51234":    "10.0.0.1",
+		"[2001:db8::1]:443": "2001:db8::1",
+		"10.0.0.1":          "10.0.0.1",
+	} {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.RemoteAddr = addr
+		// forwarding headers are not trusted
+		r.Header.Set("X-Forwarded-For", "192.0.2.1")
+		if have := clientIP(r); want != have {
+			t.Errorf("%s: want %s, have %s", addr, want, have)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+// Package ratelimit implements token bucket rate limiting of HTTP routes per
+// tenant and per client IP. Buckets are kept in a pluggable Store, allowing
+// them to be shared between instances.
+package ratelimit
+
+import (
+	// stdlib
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Scope identifies who a limit applies to.
+type Scope string
+
+// Available Scopes
+const (
+	ScopeTenant Scope = "tenant"
+	ScopeIP     Scope = "ip"
+)
+
+// ErrInvalidConfig is returned for unparsable rate limit configurations.
+var ErrInvalidConfig = errors.New("invalid rate limit configuration")
+
+// Limit describes a token bucket. Rate tokens are added per second up to a
+// maximum of Burst tokens. A zero Limit means no limit.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Unlimited returns true if the Limit does not limit requests.
+func (l Limit) Unlimited() bool {
+	return l.Rate <= 0 || l.Burst <= 0
+}
+
+// Result returns the Result of taking a token from a bucket of this Limit,
+// holding tokens after the take.
+func (l Limit) Result(tokens float64, allowed bool) Result {
+	res := Result{
+		Allowed:   allowed,
+		Limit:     l.Burst,
+		Remaining: int(math.Max(0, math.Floor(tokens))),
+		Reset:     seconds((float64(l.Burst) - tokens) / l.Rate),
+	}
+	if !allowed {
+		res.RetryAfter = seconds((1 - tokens) / l.Rate)
+	}
+	return res
+}
+
+// Result holds the outcome of taking a token from a bucket.
+type Result struct {
+	Allowed bool
+	// Limit holds the bucket size.
+	Limit int
+	// Remaining holds the tokens left in the bucket.
+	Remaining int
+	// Reset holds the time until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter holds the time until a token is available if the request
+	// was not allowed.
+	RetryAfter time.Duration
+}
+
+// Store holds token buckets.
+type Store interface {
+	// Take takes a token from the bucket identified by key, creating a full
+	// bucket if needed.
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// Config holds limits keyed by scope, e.g. "tenant", applying to each route,
+// or by route name and scope, e.g. "event_import.tenant", applying to a
+// single route.
+type Config map[string]Limit
+
+// DefaultConfig holds the limits used if none are configured.
+var DefaultConfig = Config{
+	string(ScopeTenant):                   {Rate: 20, Burst: 40},
+	string(ScopeIP):                       {Rate: 50, Burst: 100},
+	"event_import." + string(ScopeTenant): {Rate: 1.0 / 6, Burst: 5},
+	"login." + string(ScopeIP):            {Rate: 1, Burst: 10},
+}
+
+// Limit returns the limit of the scope for the route.
+func (c Config) Limit(route string, scope Scope) Limit {
+	if l, ok := c[route+"."+string(scope)]; ok {
+		return l
+	}
+	return c[string(scope)]
+}
+
+// ParseConfig parses a comma separated list of limits overriding the
+// DefaultConfig. Limits are formatted as [route.]scope=count/unit[:burst],
+// e.g. "tenant=100/m,event_import.tenant=10/h:2" where unit is one of s, m or
+// h and burst defaults to count. A limit of 0 disables the limit.
+func ParseConfig(s string) (Config, error) {
+	config := make(Config, len(DefaultConfig))
+	for key, limit := range DefaultConfig {
+		config[key] = limit
+	}
+	if s = strings.TrimSpace(s); s == "" {
+		return config, nil
+	}
+	for _, entry := range strings.Split(s, ",") {
+		kv := strings.SplitN(strings.TrimSpace(entry), "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("%v: %q", ErrInvalidConfig, entry)
+		}
+		key := strings.TrimSpace(kv[0])
+		switch Scope(key[strings.LastIndex(key, ".")+1:]) {
+		case ScopeTenant, ScopeIP:
+		default:
+			return nil, fmt.Errorf("%v: unknown scope in %q", ErrInvalidConfig, entry)
+		}
+		limit, err := parseLimit(strings.TrimSpace(kv[1]))
+		if err != nil {
+			return nil, fmt.Errorf("%v: %q", ErrInvalidConfig, entry)
+		}
+		config[key] = limit
+	}
+	return config, nil
+}
+
+func parseLimit(s string) (Limit, error) {
+	if s == "0" {
+		return Limit{}, nil
+	}
+	var burst string
+	if idx := strings.Index(s, ":"); idx >= 0 {
+		s, burst = s[:idx], s[idx+1:]
+	}
+	parts := strings.SplitN(s, "/", 2)
+	if len(parts) != 2 {
+		return Limit{}, ErrInvalidConfig
+	}
+	count, err := strconv.Atoi(parts[0])
+	if err != nil || count < 1 {
+		return Limit{}, ErrInvalidConfig
+	}
+	var unit time.Duration
+	switch parts[1] {
+	case "s":
+		unit = time.Second
+	case "m":
+		unit = time.Minute
+	case "h":
+		unit = time.Hour
+	default:
+		return Limit{}, ErrInvalidConfig
+	}
+	limit := Limit{Rate: float64(count) / unit.Seconds(), Burst: count}
+	if burst != "" {
+		if limit.Burst, err = strconv.Atoi(burst); err != nil || limit.Burst < 1 {
+			return Limit{}, ErrInvalidConfig
+		}
+	}
+	return limit, nil
+}
+
+// seconds rounds d seconds up to whole seconds as used by the rate limit
+// headers.
+func seconds(d float64) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	return time.Duration(math.Ceil(d)) * time.Second
+}
@@ -0,0 +1,149 @@
+package ratelimit
+
+import (
+	// stdlib
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRefill(t *testing.T) {
+	limit := Limit{Rate: 2, Burst: 10}
+	for _, tc := range []struct {
+		tokens  float64
+		elapsed time.Duration
+		want    float64
+	}{
+		{tokens: 0, elapsed: 0, want: 0},
+		{tokens: 0, elapsed: 500 * time.Millisecond, want: 1},
+		{tokens: 3, elapsed: 2 * time.Second, want: 7},
+		// buckets don't fill beyond their burst
+		{tokens: 9, elapsed: time.Minute, want: 10},
+	} {
+		if want, have := tc.want, refill(tc.tokens, tc.elapsed, limit); want != have {
+			t.Errorf("refill(%f, %s): want %f, have %f", tc.tokens, tc.elapsed, want, have)
+		}
+	}
+}
+
+func TestLimitResult(t *testing.T) {
+	limit := Limit{Rate: 0.5, Burst: 4}
+	for _, tc := range []struct {
+		name    string
+		tokens  float64
+		allowed bool
+		want    Result
+	}{
+		{
+			name: "full", tokens: 3, allowed: true,
+			want: Result{Allowed: true, Limit: 4, Remaining: 3, Reset: 2 * time.Second},
+		},
+		{
+			name: "partial token", tokens: 1.5, allowed: true,
+			want: Result{Allowed: true, Limit: 4, Remaining: 1, Reset: 5 * time.Second},
+		},
+		{
+			name: "rejected", tokens: 0.25, allowed: false,
+			want: Result{Limit: 4, Remaining: 0, Reset: 8 * time.Second, RetryAfter: 2 * time.Second},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if want, have := tc.want, limit.Result(tc.tokens, tc.allowed); want != have {
+				t.Errorf("want %+v, have %+v", want, have)
+			}
+		})
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		store = NewMemoryStore()
+		limit = Limit{Rate: 1, Burst: 3}
+	)
+
+	// new buckets are full
+	for i := 2; i >= 0; i-- {
+		res, err := store.Take(ctx, "a", limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !res.Allowed {
+			t.Fatalf("want take %d allowed", 3-i)
+		}
+		if want, have := i, res.Remaining; want != have {
+			t.Errorf("want %d remaining, have %d", want, have)
+		}
+	}
+	res, err := store.Take(ctx, "a", limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Allowed {
+		t.Fatal("want empty bucket rejected")
+	}
+	if want, have := time.Second, res.RetryAfter; want != have {
+		t.Errorf("want retry after %s, have %s", want, have)
+	}
+
+	// buckets are separated by key
+	if res, _ = store.Take(ctx, "b", limit); !res.Allowed {
+		t.Error("want other bucket allowed")
+	}
+
+	// buckets refill at their rate
+	store.mtx.Lock()
+	store.buckets["a"].updated = store.buckets["a"].updated.Add(-2 * time.Second)
+	store.mtx.Unlock()
+	for i := 0; i < 2; i++ {
+		if res, _ = store.Take(ctx, "a", limit); !res.Allowed {
+			t.Fatalf("want take %d of refilled tokens allowed", i+1)
+		}
+	}
+	if res, _ = store.Take(ctx, "a", limit); res.Allowed {
+		t.Error("want bucket empty again")
+	}
+
+	// full buckets are swept
+	store.mtx.Lock()
+	store.buckets["b"].updated = store.buckets["b"].updated.Add(-time.Hour)
+	store.lastSweep = store.lastSweep.Add(-sweepInterval)
+	store.mtx.Unlock()
+	store.Take(ctx, "c", limit)
+	store.mtx.Lock()
+	_, swept := store.buckets["b"]
+	store.mtx.Unlock()
+	if swept {
+		t.Error("want full bucket swept")
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	config, err := ParseConfig("tenant=100/m, event_import.tenant=10/h:2, login.ip=0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		route string
+		scope Scope
+		want  Limit
+	}{
+		{route: "event_get", scope: ScopeTenant, want: Limit{Rate: 100.0 / 60, Burst: 100}},
+		{route: "event_import", scope: ScopeTenant, want: Limit{Rate: 10.0 / 3600, Burst: 2}},
+		{route: "event_get", scope: ScopeIP, want: DefaultConfig[string(ScopeIP)]},
+		{route: "login", scope: ScopeIP, want: Limit{}},
+	} {
+		if want, have := tc.want, config.Limit(tc.route, tc.scope); want != have {
+			t.Errorf("%s.%s: want %+v, have %+v", tc.route, tc.scope, want, have)
+		}
+	}
+	if !config.Limit("login", ScopeIP).Unlimited() {
+		t.Error("want disabled limit unlimited")
+	}
+
+	for _, s := range []string{"tenant", "user=1/s", "tenant=1/d", "tenant=0/s", "tenant=1/s:0", "tenant=x/s"} {
+		if _, err = ParseConfig(s); err == nil {
+			t.Errorf("%q: want error", s)
+		}
+	}
+}
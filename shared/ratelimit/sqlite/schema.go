@@ -0,0 +1,36 @@
+package sqlite
+
+import (
+	// external
+	"github.com/jmoiron/sqlx"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/shared/migrate"
+)
+
+// Schema holds the rate limit schema migrations.
+var Schema = migrate.Schema{
+	Name: "ocgokitexample.ratelimit",
+	Migrations: []migrate.Migration{
+		{
+			Version: 1, Description: "add rate limit buckets",
+			Creates: "ratelimit_bucket", Up: v1,
+		},
+	},
+}
+
+func v1(tx *sqlx.Tx) (err error) {
+	// add rate limit bucket table, updated_at holds fractional unix seconds and
+	// allowed the outcome of the last take
+	if _, err = tx.Exec(`
+		CREATE TABLE ratelimit_bucket (
+			key TEXT NOT NULL, tokens REAL NOT NULL, burst INTEGER NOT NULL,
+			rate REAL NOT NULL, updated_at REAL NOT NULL,
+			allowed INTEGER NOT NULL, PRIMARY KEY(key)
+		) WITHOUT ROWID;`,
+	); err != nil {
+		return
+	}
+
+	return
+}
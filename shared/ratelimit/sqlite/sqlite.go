@@ -0,0 +1,97 @@
+// Package sqlite implements a ratelimit.Store in a SQLite database, allowing
+// instances on the same host to share their token buckets.
+package sqlite
+
+import (
+	// stdlib
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/jmoiron/sqlx"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/shared/migrate"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/ratelimit"
+)
+
+// sweepInterval is the interval at which full buckets are removed.
+const sweepInterval = time.Minute
+
+type sqlite struct {
+	db     *sqlx.DB
+	logger log.Logger
+
+	mtx       sync.Mutex
+	lastSweep time.Time
+}
+
+// New returns a new ratelimit.Store backed by SQLite.
+func New(db *sqlx.DB, logger log.Logger) (ratelimit.Store, error) {
+	// run our embedded database migrations
+	if err := migrate.Up(
+		context.Background(), db, migrate.SQLite, Schema, logger,
+	); err != nil {
+		return nil, err
+	}
+
+	return &sqlite{
+		db:        db,
+		logger:    log.With(logger, "store", "sqlite"),
+		lastSweep: time.Now(),
+	}, nil
+}
+
+// Take implements ratelimit.Store. The bucket is refilled and taken from in a
+// single statement so concurrent instances don't need a transaction.
+func (s *sqlite) Take(
+	ctx context.Context, key string, limit ratelimit.Limit,
+) (ratelimit.Result, error) {
+	now := float64(time.Now().UnixNano()) / float64(time.Second)
+
+	var (
+		tokens  float64
+		allowed bool
+	)
+	// SET expressions see the bucket as it was before the update
+	if err := s.db.QueryRowContext(ctx, `
+		INSERT INTO ratelimit_bucket (key, tokens, burst, rate, updated_at, allowed)
+		VALUES (:key, :burst - 1, :burst, :rate, :now, :burst >= 1)
+		ON CONFLICT (key) DO UPDATE SET
+			tokens = min(:burst, tokens + (:now - updated_at) * :rate) -
+				(min(:burst, tokens + (:now - updated_at) * :rate) >= 1),
+			allowed = min(:burst, tokens + (:now - updated_at) * :rate) >= 1,
+			burst = :burst, rate = :rate, updated_at = :now
+		RETURNING tokens, allowed`,
+		sql.Named("key", key), sql.Named("burst", limit.Burst),
+		sql.Named("rate", limit.Rate), sql.Named("now", now),
+	).Scan(&tokens, &allowed); err != nil {
+		return ratelimit.Result{}, err
+	}
+
+	s.sweep(ctx, now)
+
+	return limit.Result(tokens, allowed), nil
+}
+
+// sweep removes buckets which are full again at most once per sweepInterval.
+func (s *sqlite) sweep(ctx context.Context, now float64) {
+	s.mtx.Lock()
+	if time.Since(s.lastSweep) < sweepInterval {
+		s.mtx.Unlock()
+		return
+	}
+	s.lastSweep = time.Now()
+	s.mtx.Unlock()
+
+	if _, err := s.db.ExecContext(ctx,
+		`DELETE FROM ratelimit_bucket WHERE tokens + (? - updated_at) * rate >= burst`,
+		now,
+	); err != nil {
+		level.Warn(s.logger).Log("method", "sweep", "err", err)
+	}
+}
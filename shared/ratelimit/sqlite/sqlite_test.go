@@ -0,0 +1,115 @@
+package sqlite
+
+import (
+	// stdlib
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/shared/ratelimit"
+)
+
+// open returns a store of its own on the SQLite database at path, like an
+// instance sharing the database.
+func open(t *testing.T, path string) (*sqlite, func()) {
+	t.Helper()
+	db, err := sqlx.Open("sqlite3", path+"?_journal_mode=WAL&_busy_timeout=5000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := New(db, log.NewNopLogger())
+	if err != nil {
+		db.Close()
+		t.Fatal(err)
+	}
+	return store.(*sqlite), func() { db.Close() }
+}
+
+func TestSharedBuckets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ratelimit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var (
+		ctx   = context.Background()
+		path  = filepath.Join(dir, "ratelimit.db")
+		limit = ratelimit.Limit{Rate: 1, Burst: 2}
+	)
+	s1, close1 := open(t, path)
+	defer close1()
+	s2, close2 := open(t, path)
+	defer close2()
+
+	// instances take from the same bucket
+	for i, store := range []*sqlite{s1, s2} {
+		res, err := store.Take(ctx, "tenant/event_list/t1", limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !res.Allowed {
+			t.Fatalf("want take %d allowed", i+1)
+		}
+		if want, have := 1-i, res.Remaining; want != have {
+			t.Errorf("want %d remaining, have %d", want, have)
+		}
+	}
+	res, err := s1.Take(ctx, "tenant/event_list/t1", limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Allowed {
+		t.Fatal("want empty bucket rejected")
+	}
+	if want, have := time.Second, res.RetryAfter; want != have {
+		t.Errorf("want retry after %s, have %s", want, have)
+	}
+	if res, _ = s2.Take(ctx, "tenant/event_list/t2", limit); !res.Allowed {
+		t.Error("want other bucket allowed")
+	}
+
+	// buckets refill at their rate
+	if _, err = s1.db.Exec(
+		`UPDATE ratelimit_bucket SET updated_at = updated_at - 1 WHERE key = ?`,
+		"tenant/event_list/t1",
+	); err != nil {
+		t.Fatal(err)
+	}
+	if res, _ = s2.Take(ctx, "tenant/event_list/t1", limit); !res.Allowed {
+		t.Error("want refilled token allowed")
+	}
+	if res, _ = s1.Take(ctx, "tenant/event_list/t1", limit); res.Allowed {
+		t.Error("want bucket empty again")
+	}
+
+	// full buckets are swept
+	if _, err = s1.db.Exec(
+		`UPDATE ratelimit_bucket SET updated_at = updated_at - 3600 WHERE key = ?`,
+		"tenant/event_list/t2",
+	); err != nil {
+		t.Fatal(err)
+	}
+	s1.mtx.Lock()
+	s1.lastSweep = s1.lastSweep.Add(-sweepInterval)
+	s1.mtx.Unlock()
+	if _, err = s1.Take(ctx, "tenant/event_list/t3", limit); err != nil {
+		t.Fatal(err)
+	}
+	var n int
+	if err = s1.db.Get(&n, `SELECT COUNT(*) FROM ratelimit_bucket WHERE key = ?`, "tenant/event_list/t2"); err != nil {
+		t.Fatal(err)
+	}
+	if want, have := 0, n; want != have {
+		t.Errorf("want full bucket swept, have %d", have)
+	}
+}
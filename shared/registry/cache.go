@@ -0,0 +1,73 @@
+package registry
+
+import (
+	// stdlib
+	"reflect"
+	"sort"
+	"sync"
+
+	// external
+	"github.com/go-kit/kit/sd"
+)
+
+// cache holds the last known instances of a service and broadcasts changes to
+// its registered channels. It is the base of our polling instancers.
+type cache struct {
+	mtx   sync.Mutex
+	state sd.Event
+	chans map[chan<- sd.Event]struct{}
+	quit  chan struct{}
+	once  sync.Once
+}
+
+func newCache() *cache {
+	return &cache{
+		chans: make(map[chan<- sd.Event]struct{}),
+		quit:  make(chan struct{}),
+	}
+}
+
+// update stores event and broadcasts it if it differs from the last known
+// state.
+func (c *cache) update(event sd.Event) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	sort.Strings(event.Instances)
+	if reflect.DeepEqual(c.state, event) {
+		return
+	}
+	c.state = event
+	for ch := range c.chans {
+		ch <- copyEvent(event)
+	}
+}
+
+// Register implements sd.Instancer.
+func (c *cache) Register(ch chan<- sd.Event) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.chans[ch] = struct{}{}
+	// always push the current state to new channels
+	ch <- copyEvent(c.state)
+}
+
+// Deregister implements sd.Instancer.
+func (c *cache) Deregister(ch chan<- sd.Event) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	delete(c.chans, ch)
+}
+
+// Stop implements Instancer.
+func (c *cache) Stop() {
+	c.once.Do(func() { close(c.quit) })
+}
+
+func copyEvent(event sd.Event) sd.Event {
+	instances := make([]string, len(event.Instances))
+	copy(instances, event.Instances)
+	return sd.Event{Instances: instances, Err: event.Err}
+}
@@ -0,0 +1,422 @@
+package registry
+
+import (
+	// stdlib
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/sd"
+)
+
+const (
+	// consulHeartbeat and consulTTL match the TTL semantics of our etcd
+	// registrations: instances heartbeat every 3 seconds and are considered
+	// gone after missing their heartbeats for 10 seconds.
+	consulHeartbeat = 3 * time.Second
+	consulTTL       = 10 * time.Second
+
+	// consulDeregisterAfter removes instances which stayed critical for a
+	// minute, the minimum supported by Consul.
+	consulDeregisterAfter = time.Minute
+
+	// consulWait is the maximum duration of our blocking queries.
+	consulWait = 30 * time.Second
+
+	// consulRetry is the delay before retrying a failed query.
+	consulRetry = time.Second
+
+	// consulTimeout bounds the duration of our other agent requests.
+	consulTimeout = 5 * time.Second
+)
+
+// consulRegistry registers and discovers our instances using the HTTP API of
+// a Consul agent. Our services are registered using their name as Consul
+// service name and their transport as tag, so /services/device/grpc becomes
+// service "device" tagged "grpc". Instance metadata is stored as Consul service
+// metadata. Only instances passing their TTL health check are discovered.
+//
+// we talk to the agent HTTP API directly, keeping the Consul API client and
+// its dependencies out of our builds.
+type consulRegistry struct {
+	addr   string
+	client *http.Client
+	logger log.Logger
+}
+
+// consulService is the service registration of the Consul agent API.
+type consulService struct {
+	ID      string            `json:"ID"`
+	Name    string            `json:"Name"`
+	Tags    []string          `json:"Tags"`
+	Address string            `json:"Address"`
+	Port    int               `json:"Port"`
+	Meta    map[string]string `json:"Meta,omitempty"`
+	Check   *consulCheck      `json:"Check,omitempty"`
+}
+
+// consulCheck is the check registration of the Consul agent API.
+type consulCheck struct {
+	CheckID                        string `json:"CheckID"`
+	TTL                            string `json:"TTL"`
+	DeregisterCriticalServiceAfter string `json:"DeregisterCriticalServiceAfter"`
+}
+
+// consulEntry is a service entry of the Consul health API.
+type consulEntry struct {
+	Node struct {
+		Address string `json:"Address"`
+	} `json:"Node"`
+	Service struct {
+		ID      string            `json:"ID"`
+		Address string            `json:"Address"`
+		Port    int               `json:"Port"`
+		Meta    map[string]string `json:"Meta"`
+	} `json:"Service"`
+}
+
+// newConsul returns a Consul registry for the agent at addr, defaulting to
+// the local agent.
+func newConsul(addr string, logger log.Logger) (Registry, error) {
+	if addr = strings.TrimSpace(addr); addr == "" {
+		addr = "localhost:8500"
+	}
+	if !strings.Contains(addr, "://") {
+		addr = "http://" + addr
+	}
+	if _, err := url.Parse(addr); err != nil {
+		return nil, fmt.Errorf("%v: %v", ErrInvalidConfig, err)
+	}
+	return &consulRegistry{
+		addr:   strings.TrimSuffix(addr, "/"),
+		client: &http.Client{},
+		logger: logger,
+	}, nil
+}
+
+// do performs an agent API request, decoding the response into v if set. It
+// returns the Consul index of the response.
+func (r *consulRegistry) do(
+	ctx context.Context, method, path string, body, v interface{},
+) (uint64, error) {
+	var rd io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return 0, err
+		}
+		rd = bytes.NewReader(b)
+	}
+	req, err := http.NewRequest(method, r.addr+path, rd)
+	if err != nil {
+		return 0, err
+	}
+	res, err := r.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		b, _ := ioutil.ReadAll(io.LimitReader(res.Body, 512))
+		return 0, fmt.Errorf(
+			"consul: %s %s: %s: %s",
+			method, path, res.Status, strings.TrimSpace(string(b)),
+		)
+	}
+	index, _ := strconv.ParseUint(res.Header.Get("X-Consul-Index"), 10, 64)
+	if v != nil {
+		err = json.NewDecoder(res.Body).Decode(v)
+	}
+	return index, err
+}
+
+// Registrar implements Registry.
+func (r *consulRegistry) Registrar(
+	service, transport, instance, addr string,
+) sd.Registrar {
+	id := service + "-" + transport + "-" + instance
+	return &consulRegistrar{
+		r: r,
+		service: consulService{
+			ID:   id,
+			Name: service,
+			Tags: []string{transport},
+			Check: &consulCheck{
+				CheckID:                        "service:" + id,
+				TTL:                            consulTTL.String(),
+				DeregisterCriticalServiceAfter: consulDeregisterAfter.String(),
+			},
+		},
+		addr:   addr,
+		logger: log.With(r.logger, "consul", id),
+	}
+}
+
+// Instancer implements Registry.
+func (r *consulRegistry) Instancer(service, transport string) (Instancer, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	i := &consulInstancer{
+		cache:     newCache(),
+		cancel:    cancel,
+		r:         r,
+		service:   service,
+		transport: transport,
+	}
+
+	// like our etcd instancer we start with the current state, errors are
+	// reported to our subscribers
+	index, event := i.query(ctx, 0)
+	i.cache.update(event)
+	go i.loop(ctx, index)
+	return i, nil
+}
+
+// consulRegistrar registers a service instance with the Consul agent and
+// passes its TTL check until deregistered.
+type consulRegistrar struct {
+	r       *consulRegistry
+	service consulService
+	addr    string
+	logger  log.Logger
+
+	mtx  sync.Mutex
+	quit chan struct{}
+}
+
+// Register implements sd.Registrar.
+func (c *consulRegistrar) Register() {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if c.quit != nil {
+		return // already registered
+	}
+	if err := c.register(); err != nil {
+		c.logger.Log("action", "register", "err", err)
+	} else {
+		c.logger.Log("action", "register")
+	}
+	c.quit = make(chan struct{})
+	go c.loop(c.quit)
+}
+
+// Deregister implements sd.Registrar.
+func (c *consulRegistrar) Deregister() {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if c.quit == nil {
+		return // not registered
+	}
+	close(c.quit)
+	c.quit = nil
+
+	ctx, cancel := context.WithTimeout(context.Background(), consulTimeout)
+	defer cancel()
+	_, err := c.r.do(
+		ctx, "PUT", "/v1/agent/service/deregister/"+url.PathEscape(c.service.ID),
+		nil, nil,
+	)
+	if err != nil {
+		c.logger.Log("action", "deregister", "err", err)
+		return
+	}
+	c.logger.Log("action", "deregister")
+}
+
+// register registers our instance and passes its check right away, so it is
+// discovered without waiting for the first heartbeat.
+func (c *consulRegistrar) register() error {
+	addr, meta, err := splitMeta(c.addr)
+	if err != nil {
+		return err
+	}
+	host, port, err := splitAddr(addr)
+	if err != nil {
+		return err
+	}
+	service := c.service
+	service.Address, service.Port, service.Meta = host, port, meta
+
+	ctx, cancel := context.WithTimeout(context.Background(), consulTimeout)
+	defer cancel()
+	if _, err = c.r.do(ctx, "PUT", "/v1/agent/service/register", service, nil); err != nil {
+		return err
+	}
+	return c.pass()
+}
+
+// pass passes the TTL check of our instance.
+func (c *consulRegistrar) pass() error {
+	ctx, cancel := context.WithTimeout(context.Background(), consulTimeout)
+	defer cancel()
+	_, err := c.r.do(
+		ctx, "PUT", "/v1/agent/check/pass/"+url.PathEscape(c.service.Check.CheckID),
+		nil, nil,
+	)
+	return err
+}
+
+// loop heartbeats until quit is closed. If a heartbeat fails, e.g. because
+// the agent restarted and lost our registration, we register again.
+func (c *consulRegistrar) loop(quit chan struct{}) {
+	ticker := time.NewTicker(consulHeartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := c.pass(); err == nil {
+				continue
+			}
+			select {
+			case <-quit:
+				return
+			default:
+			}
+			if err := c.register(); err != nil {
+				c.logger.Log("action", "heartbeat", "err", err)
+			}
+		case <-quit:
+			return
+		}
+	}
+}
+
+// consulInstancer yields the passing instances of a service using blocking
+// queries.
+type consulInstancer struct {
+	*cache
+	cancel    context.CancelFunc
+	r         *consulRegistry
+	service   string
+	transport string
+}
+
+// Stop implements Instancer.
+func (i *consulInstancer) Stop() {
+	i.cancel()
+	i.cache.Stop()
+}
+
+func (i *consulInstancer) loop(ctx context.Context, index uint64) {
+	for {
+		next, event := i.query(ctx, index)
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+		if event.Err != nil {
+			i.cache.update(event)
+			select {
+			case <-time.After(consulRetry):
+			case <-ctx.Done():
+				return
+			}
+			continue
+		}
+		i.cache.update(event)
+		// reset if the index goes backwards, e.g. after a Consul restart
+		if next < index {
+			next = 0
+		}
+		index = next
+	}
+}
+
+// query returns the passing instances of our service, blocking until they
+// changed since index if set.
+func (i *consulInstancer) query(ctx context.Context, index uint64) (uint64, sd.Event) {
+	params := url.Values{}
+	params.Set("tag", i.transport)
+	params.Set("passing", "1")
+	if index > 0 {
+		params.Set("index", strconv.FormatUint(index, 10))
+		params.Set("wait", consulWait.String())
+	} else {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, consulTimeout)
+		defer cancel()
+	}
+
+	var entries []consulEntry
+	next, err := i.r.do(
+		ctx, "GET",
+		"/v1/health/service/"+url.PathEscape(i.service)+"?"+params.Encode(),
+		nil, &entries,
+	)
+	if err != nil {
+		if ctx.Err() == nil {
+			i.r.logger.Log("service", i.service, "transport", i.transport, "err", err)
+		}
+		return index, sd.Event{Err: err}
+	}
+
+	instances := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		host := entry.Service.Address
+		if host == "" {
+			host = entry.Node.Address
+		}
+		instance := schemes[i.transport] + net.JoinHostPort(
+			host, strconv.Itoa(entry.Service.Port),
+		)
+		if len(entry.Service.Meta) > 0 {
+			values := url.Values{}
+			for k, v := range entry.Service.Meta {
+				values.Set(k, v)
+			}
+			instance += "#" + values.Encode()
+		}
+		instances = append(instances, instance)
+	}
+	return next, sd.Event{Instances: instances}
+}
+
+// splitMeta splits an instance address from the metadata it carries.
+func splitMeta(addr string) (string, map[string]string, error) {
+	idx := strings.LastIndexByte(addr, '#')
+	if idx < 0 {
+		return addr, nil, nil
+	}
+	values, err := url.ParseQuery(addr[idx+1:])
+	if err != nil {
+		return "", nil, err
+	}
+	meta := make(map[string]string, len(values))
+	for k := range values {
+		meta[k] = values.Get(k)
+	}
+	return addr[:idx], meta, nil
+}
+
+// splitAddr splits an instance address, optionally prefixed by a URL scheme,
+// into its host and port.
+func splitAddr(addr string) (string, int, error) {
+	if idx := strings.Index(addr, "://"); idx >= 0 {
+		addr = addr[idx+3:]
+	}
+	host, p, err := net.SplitHostPort(strings.TrimSuffix(addr, "/"))
+	if err != nil {
+		return "", 0, err
+	}
+	port, err := strconv.Atoi(p)
+	if err != nil {
+		return "", 0, err
+	}
+	return host, port, nil
+}
@@ -0,0 +1,99 @@
+package registry_test
+
+import (
+	// stdlib
+	"context"
+	"testing"
+	"time"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/kevinburke/go.uuid"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/shared/registry"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/registry/consultest"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/registry/registrytest"
+)
+
+func TestConsulConformance(t *testing.T) {
+	agent := consultest.NewAgent()
+	defer agent.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// registry logging is silenced as the checks register and deregister
+	// continuously
+	r, err := registry.New(ctx, "consul://"+agent.Listener.Addr().String(), log.NewNopLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	registrytest.Run(t, r)
+}
+
+// TestConsulAgent runs the conformance checks against the Consul agent in
+// OCG_TEST_CONSUL, by default localhost:8500. The checks register their own
+// uniquely named services, so they can run against a shared agent.
+func TestConsulAgent(t *testing.T) {
+	addr := reachable(t, "OCG_TEST_CONSUL", "127.0.0.1:8500")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	r, err := registry.New(ctx, "consul://"+addr, log.NewNopLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	registrytest.Run(t, r)
+}
+
+// TestConsulTTL checks instances missing their heartbeats drop out of our
+// Consul instancers once their TTL expires, and return once their heartbeats
+// do.
+func TestConsulTTL(t *testing.T) {
+	agent := consultest.NewAgent()
+	defer agent.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	r, err := registry.New(ctx, "consul://"+agent.Listener.Addr().String(), log.NewNopLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	service := registrytest.Service()
+	instancer, err := r.Instancer(service, "grpc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer instancer.Stop()
+
+	registrar := r.Registrar(service, "grpc", uuid.NewV4().String(), "127.0.0.1:9100")
+	registrar.Register()
+	defer registrar.Deregister()
+	if err = registrytest.Await(ctx, instancer, "127.0.0.1:9100"); err != nil {
+		t.Fatal(err)
+	}
+
+	// heartbeats keep the instance passing beyond its initial TTL
+	agent.Advance(5 * time.Second)
+	time.Sleep(4 * time.Second)
+	agent.Advance(5 * time.Second)
+	if err = registrytest.Await(ctx, instancer, "127.0.0.1:9100"); err != nil {
+		t.Fatalf("while heartbeating: %v", err)
+	}
+
+	// without heartbeats the instance expires
+	agent.DropHeartbeats(true)
+	agent.Advance(11 * time.Second)
+	if err = registrytest.Await(ctx, instancer); err != nil {
+		t.Fatalf("after missing heartbeats: %v", err)
+	}
+
+	// and returns once its heartbeats do
+	agent.DropHeartbeats(false)
+	if err = registrytest.Await(ctx, instancer, "127.0.0.1:9100"); err != nil {
+		t.Fatalf("after resuming heartbeats: %v", err)
+	}
+}
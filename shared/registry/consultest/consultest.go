@@ -0,0 +1,287 @@
+// Package consultest implements a fake Consul agent serving the parts of the
+// agent HTTP API used by our Consul registry: service registration with TTL
+// checks and blocking health queries. It allows checking our Consul registry
+// without running Consul. Its clock can be advanced to expire TTL checks.
+package consultest
+
+import (
+	// stdlib
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Check statuses as reported by Consul.
+const (
+	StatusPassing  = "passing"
+	StatusCritical = "critical"
+)
+
+// Agent is a fake Consul agent. Its API is served at URL.
+type Agent struct {
+	*httptest.Server
+
+	mtx      sync.Mutex
+	services map[string]*service
+	index    uint64
+	changed  chan struct{}
+	offset   time.Duration
+	dropping bool
+}
+
+type service struct {
+	ID      string
+	Name    string
+	Tags    []string
+	Address string
+	Port    int
+	Meta    map[string]string
+	CheckID string
+	TTL     time.Duration
+	Expires time.Time
+	Status  string
+}
+
+// NewAgent starts a fake Consul agent. Close it when done.
+func NewAgent() *Agent {
+	a := &Agent{
+		services: make(map[string]*service),
+		index:    1,
+		changed:  make(chan struct{}),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/agent/service/register", a.register)
+	mux.HandleFunc("/v1/agent/service/deregister/", a.deregister)
+	mux.HandleFunc("/v1/agent/check/pass/", a.pass)
+	mux.HandleFunc("/v1/health/service/", a.health)
+	a.Server = httptest.NewServer(mux)
+	return a
+}
+
+// Advance moves the clock of the agent forward, expiring the TTL checks not
+// passed within d.
+func (a *Agent) Advance(d time.Duration) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	a.offset += d
+	a.expire()
+}
+
+// DropHeartbeats makes the agent ignore check passes, as if our instances
+// stopped heartbeating.
+func (a *Agent) DropHeartbeats(drop bool) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	a.dropping = drop
+}
+
+// Status returns the check status of the service instance with id, or an
+// empty string if it is not registered.
+func (a *Agent) Status(id string) string {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	a.expire()
+	if s, ok := a.services[id]; ok {
+		return s.Status
+	}
+	return ""
+}
+
+func (a *Agent) now() time.Time {
+	return time.Now().Add(a.offset)
+}
+
+// bump notifies blocking queries of a change. It must be called with mtx
+// held.
+func (a *Agent) bump() {
+	a.index++
+	close(a.changed)
+	a.changed = make(chan struct{})
+}
+
+// expire turns expired TTL checks critical. It must be called with mtx held.
+func (a *Agent) expire() {
+	now := a.now()
+	for _, s := range a.services {
+		if s.Status == StatusPassing && now.After(s.Expires) {
+			s.Status = StatusCritical
+			a.bump()
+		}
+	}
+}
+
+func (a *Agent) register(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "PUT" {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var reg struct {
+		ID      string
+		Name    string
+		Tags    []string
+		Address string
+		Port    int
+		Meta    map[string]string
+		Check   *struct {
+			CheckID string
+			TTL     string
+		}
+	}
+	if err := json.NewDecoder(r.Body).Decode(&reg); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if reg.Name == "" {
+		http.Error(w, "missing service name", http.StatusBadRequest)
+		return
+	}
+	if reg.ID == "" {
+		reg.ID = reg.Name
+	}
+	s := &service{
+		ID: reg.ID, Name: reg.Name, Tags: reg.Tags,
+		Address: reg.Address, Port: reg.Port, Meta: reg.Meta, Status: StatusPassing,
+	}
+	if reg.Check != nil {
+		ttl, err := time.ParseDuration(reg.Check.TTL)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.CheckID, s.TTL, s.Status = reg.Check.CheckID, ttl, StatusCritical
+		if s.CheckID == "" {
+			s.CheckID = "service:" + reg.ID
+		}
+	}
+
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	a.services[s.ID] = s
+	a.bump()
+}
+
+func (a *Agent) deregister(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "PUT" {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	id := strings.TrimPrefix(r.URL.Path, "/v1/agent/service/deregister/")
+
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	if _, ok := a.services[id]; !ok {
+		http.Error(w, "unknown service ID "+id, http.StatusNotFound)
+		return
+	}
+	delete(a.services, id)
+	a.bump()
+}
+
+func (a *Agent) pass(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "PUT" {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	checkID := strings.TrimPrefix(r.URL.Path, "/v1/agent/check/pass/")
+
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	for _, s := range a.services {
+		if s.CheckID != checkID {
+			continue
+		}
+		if a.dropping {
+			return
+		}
+		s.Expires = a.now().Add(s.TTL)
+		if s.Status != StatusPassing {
+			s.Status = StatusPassing
+			a.bump()
+		}
+		return
+	}
+	http.Error(w, "unknown check ID "+checkID, http.StatusNotFound)
+}
+
+func (a *Agent) health(w http.ResponseWriter, r *http.Request) {
+	var (
+		name    = strings.TrimPrefix(r.URL.Path, "/v1/health/service/")
+		query   = r.URL.Query()
+		tag     = query.Get("tag")
+		passing = query.Get("passing") != ""
+	)
+	index, _ := strconv.ParseUint(query.Get("index"), 10, 64)
+	wait, err := time.ParseDuration(query.Get("wait"))
+	if err != nil {
+		wait = 5 * time.Minute
+	}
+
+	a.mtx.Lock()
+	a.expire()
+	// blocking query: wait for a change past index
+	if index > 0 && index >= a.index {
+		changed := a.changed
+		a.mtx.Unlock()
+		select {
+		case <-changed:
+		case <-time.After(wait):
+		case <-r.Context().Done():
+			return
+		}
+		a.mtx.Lock()
+	}
+
+	type entry struct {
+		Node    struct{ Address string }
+		Service struct {
+			ID      string
+			Service string
+			Tags    []string
+			Address string
+			Port    int
+			Meta    map[string]string
+		}
+		Checks []struct{ Status string }
+	}
+	entries := make([]entry, 0)
+	for _, s := range a.services {
+		if s.Name != name || (tag != "" && !contains(s.Tags, tag)) {
+			continue
+		}
+		if passing && s.Status != StatusPassing {
+			continue
+		}
+		var e entry
+		e.Node.Address = "127.0.0.1"
+		e.Service.ID, e.Service.Service, e.Service.Tags = s.ID, s.Name, s.Tags
+		e.Service.Address, e.Service.Port, e.Service.Meta = s.Address, s.Port, s.Meta
+		e.Checks = []struct{ Status string }{{Status: s.Status}}
+		entries = append(entries, e)
+	}
+	index = a.index
+	a.mtx.Unlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Service.ID < entries[j].Service.ID
+	})
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Consul-Index", strconv.FormatUint(index, 10))
+	json.NewEncoder(w).Encode(entries)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
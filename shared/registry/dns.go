@@ -0,0 +1,122 @@
+package registry
+
+import (
+	// stdlib
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/sd"
+)
+
+const (
+	// dnsRefresh is the interval at which our SRV records are resolved.
+	dnsRefresh = 5 * time.Second
+
+	// dnsTimeout bounds the duration of a single SRV lookup.
+	dnsTimeout = 2 * time.Second
+)
+
+// schemes holds the URL schemes our HTTP based transports expect in front of
+// the addresses found in SRV records.
+var schemes = map[string]string{
+	"http":  "http://",
+	"twirp": "http://",
+}
+
+// dnsRegistry discovers instances using DNS SRV records named
+// _<transport>._tcp.<service>.<domain>, e.g. _grpc._tcp.device.example.local.
+// Priorities and weights are ignored.
+type dnsRegistry struct {
+	domain   string
+	resolver *net.Resolver
+	logger   log.Logger
+}
+
+// newDNS returns a DNS SRV registry for a configuration like "example.local"
+// or "//10.0.0.53:53/example.local" to use a specific resolver.
+func newDNS(config string, logger log.Logger) (Registry, error) {
+	r := &dnsRegistry{
+		domain:   strings.Trim(config, "."),
+		resolver: net.DefaultResolver,
+		logger:   logger,
+	}
+	if strings.HasPrefix(config, "//") {
+		parts := strings.SplitN(config[2:], "/", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%v: %q", ErrInvalidConfig, "dns:"+config)
+		}
+		server := parts[0]
+		if _, _, err := net.SplitHostPort(server); err != nil {
+			server = net.JoinHostPort(server, "53")
+		}
+		r.domain = strings.Trim(parts[1], ".")
+		r.resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, server)
+			},
+		}
+	}
+	if r.domain == "" {
+		return nil, fmt.Errorf("%v: %q", ErrInvalidConfig, "dns:"+config)
+	}
+	return r, nil
+}
+
+// Registrar implements Registry.
+func (r *dnsRegistry) Registrar(_, _, _, _ string) sd.Registrar {
+	return nopRegistrar{logger: r.logger}
+}
+
+// Instancer implements Registry.
+func (r *dnsRegistry) Instancer(service, transport string) (Instancer, error) {
+	var (
+		name = fmt.Sprintf("_%s._tcp.%s.%s", transport, service, r.domain)
+		c    = newCache()
+	)
+	c.update(r.resolve(name, schemes[transport]))
+
+	go func() {
+		ticker := time.NewTicker(dnsRefresh)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				c.update(r.resolve(name, schemes[transport]))
+			case <-c.quit:
+				return
+			}
+		}
+	}()
+	return c, nil
+}
+
+// resolve looks up the SRV records of name and returns the found instances.
+func (r *dnsRegistry) resolve(name, scheme string) sd.Event {
+	ctx, cancel := context.WithTimeout(context.Background(), dnsTimeout)
+	defer cancel()
+
+	_, records, err := r.resolver.LookupSRV(ctx, "", "", name)
+	if err != nil {
+		r.logger.Log("name", name, "err", err)
+		return sd.Event{Err: err}
+	}
+	instances := make([]string, 0, len(records))
+	for _, record := range records {
+		if record.Port == 0 {
+			continue
+		}
+		instances = append(instances, scheme+net.JoinHostPort(
+			strings.TrimSuffix(record.Target, "."),
+			strconv.Itoa(int(record.Port)),
+		))
+	}
+	return sd.Event{Instances: instances}
+}
@@ -0,0 +1,66 @@
+package registry
+
+import (
+	// stdlib
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/sd"
+	"github.com/go-kit/kit/sd/etcd"
+)
+
+// etcdRegistry registers and discovers our instances using etcd. Instances are
+// stored under /services/<service>/<transport>/<instance>/.
+type etcdRegistry struct {
+	client etcd.Client
+	logger log.Logger
+}
+
+// newEtcd returns an etcd registry for the comma separated etcd hosts.
+//
+// we could have used the v3 client but then we must vendor or suffer the
+// following issue originating from gRPC init:
+// panic: http: multiple registrations for /debug/requests
+func newEtcd(ctx context.Context, hosts string, logger log.Logger) (Registry, error) {
+	var machines []string
+	for _, host := range strings.Split(hosts, ",") {
+		if host = strings.TrimSpace(host); host == "" {
+			continue
+		}
+		if !strings.Contains(host, "://") {
+			host = "http://" + host
+		}
+		machines = append(machines, host)
+	}
+	if len(machines) == 0 {
+		return nil, fmt.Errorf("%v: no etcd hosts", ErrInvalidConfig)
+	}
+
+	client, err := etcd.NewClient(ctx, machines, etcd.ClientOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return &etcdRegistry{client: client, logger: logger}, nil
+}
+
+// Registrar implements Registry.
+func (r *etcdRegistry) Registrar(
+	service, transport, instance, addr string,
+) sd.Registrar {
+	return etcd.NewRegistrar(r.client, etcd.Service{
+		Key:   fmt.Sprintf("/services/%s/%s/%s/", service, transport, instance),
+		Value: addr,
+		TTL:   etcd.NewTTLOption(3*time.Second, 10*time.Second),
+	}, r.logger)
+}
+
+// Instancer implements Registry.
+func (r *etcdRegistry) Instancer(service, transport string) (Instancer, error) {
+	return etcd.NewInstancer(
+		r.client, "/services/"+service+"/"+transport, r.logger,
+	)
+}
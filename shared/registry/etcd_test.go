@@ -0,0 +1,29 @@
+package registry_test
+
+import (
+	// stdlib
+	"context"
+	"testing"
+
+	// external
+	"github.com/go-kit/kit/log"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/shared/registry"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/registry/registrytest"
+)
+
+// TestEtcdConformance runs the conformance checks against the etcd server in
+// OCG_TEST_ETCD, by default localhost:2379.
+func TestEtcdConformance(t *testing.T) {
+	addr := reachable(t, "OCG_TEST_ETCD", "127.0.0.1:2379")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	r, err := registry.New(ctx, "etcd://"+addr, log.NewNopLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	registrytest.Run(t, r)
+}
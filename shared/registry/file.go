@@ -0,0 +1,143 @@
+package registry
+
+import (
+	// stdlib
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/sd"
+	"gopkg.in/yaml.v2"
+)
+
+// pollInterval is the interval at which our file registry checks its file for
+// changes.
+const pollInterval = time.Second
+
+// fileRegistry yields the instances listed in a JSON or YAML file, mapping
+// service/transport names to instance addresses, e.g.:
+//
+//	device/grpc:
+//	  - 10.0.0.1:9000
+//	event/twirp:
+//	  - http://10.0.0.1:8000
+//
+// The file is watched for changes for the lifetime of the process.
+type fileRegistry struct {
+	path   string
+	logger log.Logger
+
+	mtx       sync.Mutex
+	modTime   time.Time
+	size      int64
+	instances map[string][]string
+	caches    map[*cache]string
+}
+
+// newFile returns a file registry for path, failing if the file can't be
+// loaded.
+func newFile(path string, logger log.Logger) (Registry, error) {
+	r := &fileRegistry{
+		path:   path,
+		logger: logger,
+		caches: make(map[*cache]string),
+	}
+	if _, err := r.load(); err != nil {
+		return nil, err
+	}
+	go r.watch()
+	return r, nil
+}
+
+// load reads the file if it changed since the last load and reports if it
+// did.
+func (r *fileRegistry) load() (bool, error) {
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return false, err
+	}
+
+	r.mtx.Lock()
+	unchanged := info.ModTime().Equal(r.modTime) && info.Size() == r.size
+	r.mtx.Unlock()
+	if unchanged {
+		return false, nil
+	}
+
+	b, err := ioutil.ReadFile(r.path)
+	if err != nil {
+		return false, err
+	}
+	// remember the file even if it can't be parsed, so we only try again
+	// once it changes
+	r.mtx.Lock()
+	r.modTime, r.size = info.ModTime(), info.Size()
+	r.mtx.Unlock()
+
+	instances := make(map[string][]string)
+	switch filepath.Ext(r.path) {
+	case ".json":
+		err = json.Unmarshal(b, &instances)
+	default:
+		err = yaml.Unmarshal(b, &instances)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	r.mtx.Lock()
+	r.instances = instances
+	r.mtx.Unlock()
+	return true, nil
+}
+
+// watch polls the file for changes and updates our instancers. Changes which
+// can't be loaded are logged, leaving the last known instances in place.
+func (r *fileRegistry) watch() {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		changed, err := r.load()
+		if err != nil {
+			r.logger.Log("path", r.path, "err", err)
+			continue
+		}
+		if !changed {
+			continue
+		}
+
+		r.mtx.Lock()
+		for c, name := range r.caches {
+			select {
+			case <-c.quit:
+				delete(r.caches, c)
+			default:
+				c.update(sd.Event{Instances: r.instances[name]})
+			}
+		}
+		r.mtx.Unlock()
+	}
+}
+
+// Registrar implements Registry.
+func (r *fileRegistry) Registrar(_, _, _, _ string) sd.Registrar {
+	return nopRegistrar{logger: r.logger}
+}
+
+// Instancer implements Registry. Services missing from the file yield no
+// instances until they are added.
+func (r *fileRegistry) Instancer(service, transport string) (Instancer, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	c := newCache()
+	c.update(sd.Event{Instances: r.instances[key(service, transport)]})
+	r.caches[c] = key(service, transport)
+	return c, nil
+}
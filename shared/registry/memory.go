@@ -0,0 +1,187 @@
+package registry
+
+import (
+	// stdlib
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/sd"
+)
+
+// memoryStore holds the registered instances using the key layout of our etcd
+// registry, /services/<service>/<transport>/<instance>/, and notifies the
+// instancers watching them of changes.
+type memoryStore struct {
+	mtx        sync.Mutex
+	entries    map[string]string
+	instancers map[*memoryInstancer]struct{}
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{
+		entries:    make(map[string]string),
+		instancers: make(map[*memoryInstancer]struct{}),
+	}
+}
+
+// memoryRegistry registers and discovers our instances in memory, allowing
+// our services, clients and tests to run in a single process without external
+// dependencies.
+type memoryRegistry struct {
+	store  *memoryStore
+	logger log.Logger
+}
+
+// NewMemory returns an empty in-memory registry. Registrations are only visible
+// to the instancers of the returned registry, so all services and clients of a
+// process need to share it.
+func NewMemory(logger log.Logger) Registry {
+	return &memoryRegistry{store: newMemoryStore(), logger: logger}
+}
+
+// Registrar implements Registry.
+func (r *memoryRegistry) Registrar(
+	service, transport, instance, addr string,
+) sd.Registrar {
+	return &memoryRegistrar{
+		store:  r.store,
+		key:    fmt.Sprintf("/services/%s/%s/%s/", service, transport, instance),
+		value:  addr,
+		logger: r.logger,
+	}
+}
+
+// Instancer implements Registry.
+func (r *memoryRegistry) Instancer(service, transport string) (Instancer, error) {
+	i := &memoryInstancer{
+		cache:   newCache(),
+		store:   r.store,
+		prefix:  "/services/" + service + "/" + transport + "/",
+		changed: make(chan struct{}, 1),
+	}
+	i.update(sd.Event{Instances: r.store.watch(i)})
+	go i.loop()
+	return i, nil
+}
+
+// set stores or removes the value of key and notifies the instancers watching
+// it.
+func (s *memoryStore) set(key, value string, remove bool) {
+	s.mtx.Lock()
+	if remove {
+		delete(s.entries, key)
+	} else {
+		s.entries[key] = value
+	}
+	var watching []*memoryInstancer
+	for i := range s.instancers {
+		if strings.HasPrefix(key, i.prefix) {
+			watching = append(watching, i)
+		}
+	}
+	s.mtx.Unlock()
+
+	for _, i := range watching {
+		i.notify()
+	}
+}
+
+// watch adds i to the instancers notified of changes and returns the current
+// instances under its prefix.
+func (s *memoryStore) watch(i *memoryInstancer) []string {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.instancers[i] = struct{}{}
+	return s.instances(i.prefix)
+}
+
+// unwatch removes i from the instancers notified of changes.
+func (s *memoryStore) unwatch(i *memoryInstancer) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	delete(s.instancers, i)
+}
+
+// current returns the instances stored under prefix.
+func (s *memoryStore) current(prefix string) []string {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	return s.instances(prefix)
+}
+
+// instances returns the values stored under prefix. It must be called with
+// mtx held.
+func (s *memoryStore) instances(prefix string) []string {
+	instances := make([]string, 0)
+	for key, value := range s.entries {
+		if strings.HasPrefix(key, prefix) {
+			instances = append(instances, value)
+		}
+	}
+	sort.Strings(instances)
+	return instances
+}
+
+// memoryInstancer watches the instances under prefix. Its subscribers are
+// updated from its own goroutine, so subscribers slow to receive don't hold up
+// registrations or other instancers.
+type memoryInstancer struct {
+	*cache
+	store   *memoryStore
+	prefix  string
+	changed chan struct{}
+}
+
+// notify signals a change to the instancer, coalescing with changes it has yet
+// to pick up.
+func (i *memoryInstancer) notify() {
+	select {
+	case i.changed <- struct{}{}:
+	default:
+	}
+}
+
+// loop updates the subscribers with the current instances after each change.
+func (i *memoryInstancer) loop() {
+	for {
+		select {
+		case <-i.changed:
+			i.update(sd.Event{Instances: i.store.current(i.prefix)})
+		case <-i.quit:
+			return
+		}
+	}
+}
+
+// Stop implements Instancer.
+func (i *memoryInstancer) Stop() {
+	i.store.unwatch(i)
+	i.cache.Stop()
+}
+
+// memoryRegistrar registers a service instance in a memory store.
+type memoryRegistrar struct {
+	store  *memoryStore
+	key    string
+	value  string
+	logger log.Logger
+}
+
+// Register implements sd.Registrar.
+func (r *memoryRegistrar) Register() {
+	r.store.set(r.key, r.value, false)
+	r.logger.Log("key", r.key, "value", r.value, "action", "register")
+}
+
+// Deregister implements sd.Registrar.
+func (r *memoryRegistrar) Deregister() {
+	r.store.set(r.key, "", true)
+	r.logger.Log("key", r.key, "action", "deregister")
+}
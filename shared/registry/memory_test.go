@@ -0,0 +1,60 @@
+package registry_test
+
+import (
+	// stdlib
+	"context"
+	"testing"
+	"time"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/kevinburke/go.uuid"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/shared/registry"
+	"github.com/basvanbeek/opencensus-gokit-example/shared/registry/registrytest"
+)
+
+func TestMemoryConformance(t *testing.T) {
+	registrytest.Run(t, registry.NewMemory(log.NewNopLogger()))
+}
+
+// TestMemoryNotConfigurable checks standalone services can't be configured
+// with an in-memory registry, which other processes can't discover them from.
+func TestMemoryNotConfigurable(t *testing.T) {
+	if _, err := registry.New(context.Background(), "memory:", log.NewNopLogger()); err == nil {
+		t.Error("want in-memory registry configuration rejected")
+	}
+}
+
+// TestMemoryIsolated checks in-memory registries don't share registrations.
+func TestMemoryIsolated(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var (
+		service  = registrytest.Service()
+		shared   = registry.NewMemory(log.NewNopLogger())
+		isolated = registry.NewMemory(log.NewNopLogger())
+	)
+	instancer, err := shared.Instancer(service, "http")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer instancer.Stop()
+	other, err := isolated.Instancer(service, "http")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Stop()
+
+	registrar := shared.Registrar(service, "http", uuid.NewV4().String(), "http://127.0.0.1:9200")
+	registrar.Register()
+	defer registrar.Deregister()
+	if err = registrytest.Await(ctx, instancer, "http://127.0.0.1:9200"); err != nil {
+		t.Fatal(err)
+	}
+	if err = registrytest.Await(ctx, other); err != nil {
+		t.Error(err)
+	}
+}
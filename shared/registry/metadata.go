@@ -0,0 +1,82 @@
+package registry
+
+import (
+	// stdlib
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/shared/sd"
+)
+
+// Version and Commit identify the build of our services. They are set at build
+// time using -ldflags, e.g.
+//
+//	-X github.com/basvanbeek/opencensus-gokit-example/shared/registry.Version=1.3.0
+//
+// and can be overridden by OCG_VERSION and OCG_COMMIT.
+var (
+	Version string
+	Commit  string
+)
+
+// LocalMetadata returns the metadata announced with the instances of our
+// service, serving transports. The zone and weight of the instance are taken
+// from OCG_ZONE and OCG_WEIGHT.
+func LocalMetadata(transports ...string) (sd.Metadata, error) {
+	md := sd.Metadata{
+		Version:    Version,
+		Commit:     Commit,
+		Zone:       os.Getenv("OCG_ZONE"),
+		Transports: transports,
+	}
+	if v := os.Getenv("OCG_VERSION"); v != "" {
+		md.Version = v
+	}
+	if c := os.Getenv("OCG_COMMIT"); c != "" {
+		md.Commit = c
+	}
+	if w := os.Getenv("OCG_WEIGHT"); w != "" {
+		weight, err := strconv.Atoi(w)
+		if err != nil || weight <= 0 {
+			return sd.Metadata{}, fmt.Errorf("%v: OCG_WEIGHT %q", ErrInvalidConfig, w)
+		}
+		md.Weight = weight
+	}
+	return md, nil
+}
+
+// Routing returns the routing options of our clients. Instances in our
+// OCG_ZONE are preferred and requests are split over versions as configured by
+// OCG_VERSION_SPLIT in percentages, e.g. "1.3.0=5" sends 5% of the requests to
+// instances of version 1.3.0 and the remainder to the other versions.
+func Routing() ([]sd.Option, error) {
+	options := []sd.Option{sd.PreferZone(os.Getenv("OCG_ZONE"))}
+
+	config := strings.TrimSpace(os.Getenv("OCG_VERSION_SPLIT"))
+	if config == "" {
+		return options, nil
+	}
+	var (
+		shares = make(map[string]float64)
+		total  float64
+	)
+	for _, entry := range strings.Split(config, ",") {
+		kv := strings.SplitN(entry, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return nil, fmt.Errorf("%v: OCG_VERSION_SPLIT %q", ErrInvalidConfig, entry)
+		}
+		percent, err := strconv.ParseFloat(strings.TrimSpace(kv[1]), 64)
+		if err != nil || percent < 0 {
+			return nil, fmt.Errorf("%v: OCG_VERSION_SPLIT %q", ErrInvalidConfig, entry)
+		}
+		shares[strings.TrimSpace(kv[0])] = percent / 100
+		total += percent
+	}
+	if total > 100 {
+		return nil, fmt.Errorf("%v: OCG_VERSION_SPLIT %q exceeds 100%%", ErrInvalidConfig, config)
+	}
+	return append(options, sd.VersionSplit(shares)), nil
+}
@@ -0,0 +1,135 @@
+// Package registry implements the service registries our services register
+// their instances with and discover the instances of other services from. The
+// registry in use is selected by configuration, allowing our services to run
+// with etcd, Consul, a static list of instances, a watched file or DNS SRV
+// records. Services sharing a process, e.g. in tests, can share an in-memory
+// registry.
+package registry
+
+import (
+	// stdlib
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/sd"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/shared/network"
+)
+
+// DefaultConfig is used if no registry configuration is provided.
+const DefaultConfig = "etcd://localhost:2379"
+
+// Error messages returned by our registries.
+const (
+	ErrorInvalidConfig  = "invalid registry configuration"
+	ErrorUnknownService = "service not found in registry"
+)
+
+// Errors returned by our registries.
+var (
+	ErrInvalidConfig  = errors.New(ErrorInvalidConfig)
+	ErrUnknownService = errors.New(ErrorUnknownService)
+)
+
+// Registry registers the instances of our services and discovers the instances
+// of the services they depend on.
+type Registry interface {
+	// Registrar returns a registrar announcing instance of service, reachable
+	// at addr using transport. The addr may carry the metadata of the
+	// instance, as formatted by FormatInstance of our sd package.
+	Registrar(service, transport, instance, addr string) sd.Registrar
+
+	// Instancer returns an Instancer yielding the addresses of the instances
+	// of service reachable using transport, including their metadata.
+	Instancer(service, transport string) (Instancer, error)
+}
+
+// Instancer yields the addresses of service instances and needs to be stopped
+// once no longer used.
+type Instancer interface {
+	sd.Instancer
+	Stop()
+}
+
+// New returns the registry described by config. Supported configurations:
+//
+//	etcd://host:port[,host:port]	etcd cluster, the default
+//	consul://host:port	Consul agent, defaulting to localhost:8500
+//	static:service/transport=addr[,addr][;...]	fixed instances
+//	file:path	JSON or YAML file holding the instances, watched for changes
+//	dns:domain	DNS SRV records named _transport._tcp.service.domain
+//	dns://resolver:port/domain	DNS SRV records using a specific resolver
+//
+// Only etcd and Consul support registration, using the other registries our
+// service instances are expected to be announced by their environment. The
+// in-memory registry is only of use to services sharing a process, so it is
+// not available by configuration, see NewMemory.
+func New(ctx context.Context, config string, logger log.Logger) (Registry, error) {
+	if config = strings.TrimSpace(config); config == "" {
+		config = DefaultConfig
+	}
+	idx := strings.Index(config, ":")
+	if idx < 0 {
+		return nil, fmt.Errorf("%v: %q", ErrInvalidConfig, config)
+	}
+	scheme, rest := config[:idx], config[idx+1:]
+	switch scheme {
+	case "etcd":
+		return newEtcd(ctx, strings.TrimPrefix(rest, "//"), logger)
+	case "consul":
+		return newConsul(strings.TrimPrefix(rest, "//"), logger)
+	case "static":
+		return newStatic(rest, logger)
+	case "file":
+		return newFile(strings.TrimPrefix(rest, "//"), logger)
+	case "dns":
+		return newDNS(rest, logger)
+	default:
+		return nil, fmt.Errorf("%v: unknown registry %q", ErrInvalidConfig, scheme)
+	}
+}
+
+// Listen returns a listener for the transport of service. The listen address
+// is taken from the OCG_<SERVICE>_<TRANSPORT>_ADDR environment variable, e.g.
+// OCG_DEVICE_GRPC_ADDR, as registries without registration need to know where
+// to find our instances. It defaults to a dynamic port on our host IP.
+func Listen(service, transport string) (net.Listener, error) {
+	addr := os.Getenv(strings.ToUpper("OCG_" + service + "_" + transport + "_ADDR"))
+	if addr == "" {
+		bindIP, err := network.HostIP()
+		if err != nil {
+			return nil, err
+		}
+		addr = bindIP + ":0" // dynamic port assignment
+	}
+	return net.Listen("tcp", addr)
+}
+
+// key returns the name of the instances of service using transport as used in
+// our static and file configurations.
+func key(service, transport string) string {
+	return service + "/" + transport
+}
+
+// nopRegistrar is used by registries relying on their environment to announce
+// our service instances.
+type nopRegistrar struct {
+	logger log.Logger
+}
+
+// Register implements sd.Registrar.
+func (r nopRegistrar) Register() {
+	r.logger.Log("action", "register", "msg", "registration managed externally")
+}
+
+// Deregister implements sd.Registrar.
+func (r nopRegistrar) Deregister() {
+	r.logger.Log("action", "deregister", "msg", "registration managed externally")
+}
@@ -0,0 +1,23 @@
+package registry_test
+
+import (
+	// stdlib
+	"net"
+	"os"
+	"testing"
+	"time"
+)
+
+// reachable returns the address found in the environment variable env, or
+// addr if it is not set. It skips t if nothing listens on the address.
+func reachable(t *testing.T, env, addr string) string {
+	if v := os.Getenv(env); v != "" {
+		addr = v
+	}
+	conn, err := net.DialTimeout("tcp", addr, time.Second)
+	if err != nil {
+		t.Skipf("no agent reachable at %s, set %s: %v", addr, env, err)
+	}
+	conn.Close()
+	return addr
+}
@@ -0,0 +1,252 @@
+// Package registrytest holds the conformance checks every Registry supporting
+// registration must pass. Each check registers freshly named services so the
+// checks can run against a shared registry.
+package registrytest
+
+import (
+	// stdlib
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	// external
+	"github.com/go-kit/kit/sd"
+	"github.com/kevinburke/go.uuid"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/shared/registry"
+)
+
+// Timeout is the time a registry gets to reflect a change in its instancers.
+const Timeout = 5 * time.Second
+
+// Check is a single named conformance check.
+type Check struct {
+	Name string
+	Run  func(ctx context.Context, r registry.Registry) error
+}
+
+// Checks holds our Registry conformance checks.
+var Checks = []Check{
+	{"register and deregister", registerAndDeregister},
+	{"multiple instances", multipleInstances},
+	{"transport isolation", transportIsolation},
+	{"watch", watch},
+	{"idempotent registration", idempotent},
+	{"metadata", metadata},
+}
+
+// Run runs all checks against the registry as subtests of t.
+func Run(t *testing.T, r registry.Registry) {
+	for _, check := range Checks {
+		check := check
+		t.Run(check.Name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			if err := check.Run(ctx, r); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// Service returns a fresh service name.
+func Service() string {
+	return "check-" + uuid.NewV4().String()[:8]
+}
+
+// Await waits until instancer yields the instances in want.
+func Await(ctx context.Context, instancer sd.Instancer, want ...string) error {
+	ctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+
+	sort.Strings(want)
+	if want == nil {
+		want = []string{}
+	}
+	ch := make(chan sd.Event, 1)
+	instancer.Register(ch)
+	defer func() {
+		// keep receiving while deregistering as instancers push their
+		// updates while holding their lock
+		go func() {
+			for range ch {
+			}
+		}()
+		instancer.Deregister(ch)
+		close(ch)
+	}()
+
+	var have sd.Event
+	for {
+		select {
+		case have = <-ch:
+			instances := append([]string{}, have.Instances...)
+			sort.Strings(instances)
+			if have.Err == nil && reflect.DeepEqual(instances, want) {
+				return nil
+			}
+		case <-ctx.Done():
+			return fmt.Errorf(
+				"want instances %v, have %v (err: %v)", want, have.Instances, have.Err,
+			)
+		}
+	}
+}
+
+func registerAndDeregister(ctx context.Context, r registry.Registry) error {
+	service := Service()
+	instancer, err := r.Instancer(service, "grpc")
+	if err != nil {
+		return err
+	}
+	defer instancer.Stop()
+	if err = Await(ctx, instancer); err != nil {
+		return fmt.Errorf("before registration: %v", err)
+	}
+
+	registrar := r.Registrar(service, "grpc", uuid.NewV4().String(), "127.0.0.1:9000")
+	registrar.Register()
+	if err = Await(ctx, instancer, "127.0.0.1:9000"); err != nil {
+		registrar.Deregister()
+		return fmt.Errorf("after registration: %v", err)
+	}
+
+	registrar.Deregister()
+	if err = Await(ctx, instancer); err != nil {
+		return fmt.Errorf("after deregistration: %v", err)
+	}
+	return nil
+}
+
+func multipleInstances(ctx context.Context, r registry.Registry) error {
+	service := Service()
+	instancer, err := r.Instancer(service, "http")
+	if err != nil {
+		return err
+	}
+	defer instancer.Stop()
+
+	var (
+		a = r.Registrar(service, "http", uuid.NewV4().String(), "http://127.0.0.1:9001")
+		b = r.Registrar(service, "http", uuid.NewV4().String(), "http://127.0.0.1:9002")
+	)
+	a.Register()
+	defer a.Deregister()
+	b.Register()
+	if err = Await(ctx, instancer, "http://127.0.0.1:9001", "http://127.0.0.1:9002"); err != nil {
+		b.Deregister()
+		return err
+	}
+
+	b.Deregister()
+	if err = Await(ctx, instancer, "http://127.0.0.1:9001"); err != nil {
+		return fmt.Errorf("after deregistering one instance: %v", err)
+	}
+	return nil
+}
+
+func transportIsolation(ctx context.Context, r registry.Registry) error {
+	service := Service()
+	grpcInstancer, err := r.Instancer(service, "grpc")
+	if err != nil {
+		return err
+	}
+	defer grpcInstancer.Stop()
+	httpInstancer, err := r.Instancer(service, "http")
+	if err != nil {
+		return err
+	}
+	defer httpInstancer.Stop()
+	otherInstancer, err := r.Instancer(Service(), "http")
+	if err != nil {
+		return err
+	}
+	defer otherInstancer.Stop()
+
+	registrar := r.Registrar(service, "http", uuid.NewV4().String(), "http://127.0.0.1:9003")
+	registrar.Register()
+	defer registrar.Deregister()
+	if err = Await(ctx, httpInstancer, "http://127.0.0.1:9003"); err != nil {
+		return err
+	}
+	if err = Await(ctx, grpcInstancer); err != nil {
+		return fmt.Errorf("other transport: %v", err)
+	}
+	if err = Await(ctx, otherInstancer); err != nil {
+		return fmt.Errorf("other service: %v", err)
+	}
+	return nil
+}
+
+// watch checks changes are pushed to subscribed channels without
+// resubscribing.
+func watch(ctx context.Context, r registry.Registry) error {
+	service := Service()
+	instancer, err := r.Instancer(service, "twirp")
+	if err != nil {
+		return err
+	}
+	defer instancer.Stop()
+
+	ch := make(chan sd.Event, 16)
+	instancer.Register(ch)
+	defer instancer.Deregister(ch)
+
+	registrar := r.Registrar(service, "twirp", uuid.NewV4().String(), "http://127.0.0.1:9004")
+	registrar.Register()
+	defer registrar.Deregister()
+
+	timeout := time.After(Timeout)
+	for {
+		select {
+		case event := <-ch:
+			if len(event.Instances) == 1 && event.Instances[0] == "http://127.0.0.1:9004" {
+				return nil
+			}
+		case <-timeout:
+			return fmt.Errorf("registration not pushed within %s", Timeout)
+		}
+	}
+}
+
+func idempotent(ctx context.Context, r registry.Registry) error {
+	service := Service()
+	instancer, err := r.Instancer(service, "grpc")
+	if err != nil {
+		return err
+	}
+	defer instancer.Stop()
+
+	registrar := r.Registrar(service, "grpc", uuid.NewV4().String(), "127.0.0.1:9005")
+	registrar.Register()
+	registrar.Register()
+	if err = Await(ctx, instancer, "127.0.0.1:9005"); err != nil {
+		registrar.Deregister()
+		return err
+	}
+	registrar.Deregister()
+	registrar.Deregister()
+	return Await(ctx, instancer)
+}
+
+// metadata checks instance metadata is discovered as registered.
+func metadata(ctx context.Context, r registry.Registry) error {
+	service := Service()
+	instancer, err := r.Instancer(service, "grpc")
+	if err != nil {
+		return err
+	}
+	defer instancer.Stop()
+
+	// formatted like sd.FormatInstance does, with sorted keys
+	addr := "127.0.0.1:9006#transports=grpc%2Chttp&version=1.3.0&weight=50&zone=eu-west-1a"
+	registrar := r.Registrar(service, "grpc", uuid.NewV4().String(), addr)
+	registrar.Register()
+	defer registrar.Deregister()
+	return Await(ctx, instancer, addr)
+}
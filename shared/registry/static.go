@@ -0,0 +1,64 @@
+package registry
+
+import (
+	// stdlib
+	"fmt"
+	"strings"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/sd"
+)
+
+// staticRegistry yields a fixed set of instances per service and transport.
+type staticRegistry struct {
+	instances map[string][]string
+	logger    log.Logger
+}
+
+// newStatic returns a static registry for a configuration like
+// "device/grpc=10.0.0.1:9000,10.0.0.2:9000;event/twirp=http://10.0.0.1:8000".
+func newStatic(config string, logger log.Logger) (Registry, error) {
+	instances, err := parseStatic(config)
+	if err != nil {
+		return nil, err
+	}
+	return &staticRegistry{instances: instances, logger: logger}, nil
+}
+
+// parseStatic parses the instances of a static configuration.
+func parseStatic(config string) (map[string][]string, error) {
+	instances := make(map[string][]string)
+	for _, entry := range strings.Split(config, ";") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		kv := strings.SplitN(entry, "=", 2)
+		if len(kv) != 2 || strings.Count(kv[0], "/") != 1 {
+			return nil, fmt.Errorf("%v: %q", ErrInvalidConfig, entry)
+		}
+		name := strings.TrimSpace(kv[0])
+		for _, addr := range strings.Split(kv[1], ",") {
+			if addr = strings.TrimSpace(addr); addr != "" {
+				instances[name] = append(instances[name], addr)
+			}
+		}
+	}
+	return instances, nil
+}
+
+// Registrar implements Registry.
+func (r *staticRegistry) Registrar(_, _, _, _ string) sd.Registrar {
+	return nopRegistrar{logger: r.logger}
+}
+
+// Instancer implements Registry.
+func (r *staticRegistry) Instancer(service, transport string) (Instancer, error) {
+	instances, ok := r.instances[key(service, transport)]
+	if !ok {
+		return nil, fmt.Errorf("%v: %s", ErrUnknownService, key(service, transport))
+	}
+	c := newCache()
+	c.update(sd.Event{Instances: instances})
+	return c, nil
+}
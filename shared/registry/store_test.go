@@ -0,0 +1,56 @@
+package registry
+
+import (
+	// stdlib
+	"testing"
+	"time"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/sd"
+)
+
+// TestMemorySlowSubscriber checks subscribers not receiving their events
+// don't hold up registrations.
+func TestMemorySlowSubscriber(t *testing.T) {
+	r := NewMemory(log.NewNopLogger())
+	instancer, err := r.Instancer("device", "http")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer instancer.Stop()
+
+	// the channel is filled by the initial event and never read
+	instancer.Register(make(chan sd.Event, 1))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 10; i++ {
+			registrar := r.Registrar("device", "http", "instance", "http://127.0.0.1:9200")
+			registrar.Register()
+			registrar.Deregister()
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("registrations blocked by subscriber")
+	}
+}
+
+// TestMemoryStop checks stopped instancers are no longer notified.
+func TestMemoryStop(t *testing.T) {
+	r := NewMemory(log.NewNopLogger()).(*memoryRegistry)
+	instancer, err := r.Instancer("device", "http")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, have := 1, len(r.store.instancers); want != have {
+		t.Fatalf("want %d instancers, have %d", want, have)
+	}
+	instancer.Stop()
+	if want, have := 0, len(r.store.instancers); want != have {
+		t.Errorf("want %d instancers, have %d", want, have)
+	}
+}
@@ -10,5 +10,18 @@ type Balancer interface {
 	Client() (interface{}, error)
 }
 
+// LoadBalancer yields client instances according to their load. Callers must
+// report the completion of each request dispatched to a yielded client
+// instance, so the LoadBalancer can track the in-flight requests and latency
+// of its client instances.
+type LoadBalancer interface {
+	Client() (interface{}, Done, error)
+}
+
+// Done reports the completion of a request and must be called exactly once.
+// The error should only be set if the client instance failed to handle the
+// request, business errors are to be reported as success.
+type Done func(err error)
+
 // ErrNoClients is returned when no qualifying client instances are available.
 var ErrNoClients = errors.New("no client instance available")
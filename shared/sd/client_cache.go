@@ -21,6 +21,7 @@ type clientInstancerCache struct {
 	factory            Factory
 	cache              map[string]clientInstanceCloser
 	err                error
+	instances          []string
 	clientInstances    []interface{}
 	logger             log.Logger
 	invalidateDeadline time.Time
@@ -28,7 +29,9 @@ type clientInstancerCache struct {
 }
 
 type clientInstanceCloser struct {
-	ci interface{}
+	ci     interface{}
+	md     Metadata
+	health *healthMonitor
 	io.Closer
 }
 
@@ -75,52 +78,121 @@ func (c *clientInstancerCache) Update(event sd.Event) {
 }
 
 func (c *clientInstancerCache) updateCache(instances []string) {
+	// Split the instance strings into addresses and metadata, an address
+	// announced more than once keeps its last metadata.
+	var (
+		addrs    = make([]string, 0, len(instances))
+		metadata = make(map[string]Metadata, len(instances))
+	)
+	for _, instance := range instances {
+		addr, md := ParseInstance(instance)
+		if _, ok := metadata[addr]; !ok {
+			addrs = append(addrs, addr)
+		}
+		metadata[addr] = md
+	}
+
 	// Deterministic order (for later).
-	sort.Strings(instances)
+	sort.Strings(addrs)
 
 	// Produce the current set of services.
-	cache := make(map[string]clientInstanceCloser, len(instances))
-	for _, instance := range instances {
+	cache := make(map[string]clientInstanceCloser, len(addrs))
+	for _, addr := range addrs {
+		md := metadata[addr]
+
 		// If it already exists, just copy it over.
-		if sc, ok := c.cache[instance]; ok {
-			cache[instance] = sc
-			delete(c.cache, instance)
+		if sc, ok := c.cache[addr]; ok {
+			if !sc.md.equal(md) {
+				sc.md = md
+				if s, ok := sc.ci.(metadataSetter); ok {
+					s.setMetadata(md)
+				}
+			}
+			cache[addr] = sc
+			delete(c.cache, addr)
 			continue
 		}
 
 		// If it doesn't exist, create it.
-		service, closer, err := c.factory(instance)
+		service, closer, err := c.factory(addr)
 		if err != nil {
-			c.logger.Log("instance", instance, "err", err)
+			c.logger.Log("instance", addr, "err", err)
 			continue
 		}
-		cache[instance] = clientInstanceCloser{service, closer}
+		if s, ok := service.(metadataSetter); ok {
+			s.setMetadata(md)
+		}
+		sc := clientInstanceCloser{ci: service, md: md, Closer: closer}
+		if hc := c.options.healthCheck; hc != nil {
+			sc.health = subscribeHealth(*hc, addr, c)
+		}
+		cache[addr] = sc
 	}
 
 	// Close any leftover clientInstances.
 	for _, sc := range c.cache {
+		if sc.health != nil {
+			sc.health.unsubscribe(c)
+		}
 		if sc.Closer != nil {
 			sc.Closer.Close()
 		}
 	}
 
-	// Populate the slice of clientInstances.
-	clientInstances := make([]interface{}, 0, len(cache))
-	for _, instance := range instances {
+	// Swap and trigger GC for old copies.
+	c.instances = addrs
+	c.cache = cache
+	c.populate()
+}
+
+// populate fills the slice of clientInstances, leaving out filtered and
+// unhealthy instances. If a zone is preferred and any of its instances is
+// healthy, only those are returned. If all instances are unhealthy, all of them
+// are returned as ejecting them would only turn degraded service into no
+// service at all.
+func (c *clientInstancerCache) populate() {
+	var (
+		clientInstances = make([]interface{}, 0, len(c.cache))
+		unhealthy       = make([]interface{}, 0)
+		local           = make([]interface{}, 0)
+	)
+	for _, instance := range c.instances {
 		// A bad factory may mean an instance is not present.
-		if _, ok := cache[instance]; !ok {
+		sc, ok := c.cache[instance]
+		if !ok {
 			continue
 		}
-		clientInstances = append(clientInstances, cache[instance].ci)
+		if c.options.filter != nil && !c.options.filter(sc.md) {
+			continue
+		}
+		if sc.health != nil && !sc.health.isHealthy() {
+			unhealthy = append(unhealthy, sc.ci)
+			continue
+		}
+		clientInstances = append(clientInstances, sc.ci)
+		if c.options.zone != "" && sc.md.Zone == c.options.zone {
+			local = append(local, sc.ci)
+		}
+	}
+	switch {
+	case len(local) > 0:
+		clientInstances = local
+	case len(clientInstances) == 0:
+		clientInstances = unhealthy
 	}
-
-	// Swap and trigger GC for old copies.
 	c.clientInstances = clientInstances
-	c.cache = cache
+}
+
+// healthChanged is invoked by the health monitors of our instances when their
+// health changes.
+func (c *clientInstancerCache) healthChanged() {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.populate()
 }
 
 // Clients yields the current set of (presumably identical) clientInstances,
-// ordered lexicographically by the corresponding instance string.
+// ordered lexicographically by the corresponding instance address.
 func (c *clientInstancerCache) Clients() ([]interface{}, error) {
 	// in the steady state we're going to have many goroutines calling Clients()
 	// concurrently, so to minimize contention we use a shared R-lock.
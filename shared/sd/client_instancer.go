@@ -66,6 +66,11 @@ func InvalidateOnError(timeout time.Duration) Option {
 type clientInstancerOptions struct {
 	invalidateOnError bool
 	invalidateTimeout time.Duration
+	healthCheck       *healthCheck
+	outlierDetection  *OutlierSettings
+	filter            func(md Metadata) bool
+	zone              string
+	versionSplit      versionSplit
 }
 
 // DefaultClientInstancer implements an ClientInstancer interface.
@@ -0,0 +1,62 @@
+package sd
+
+import (
+	// stdlib
+	"html/template"
+	"net/http"
+	"sort"
+)
+
+// DebugPath is the path our debug page is served at by the zpages handler.
+const DebugPath = "/sdz"
+
+var debugPage = template.Must(template.New("sdz").Parse(`<!DOCTYPE html>
+<html>
+<head><title>Service discovery health</title></head>
+<body>
+<h1>Service discovery health</h1>
+{{if .}}
+<table border="1" cellpadding="4" cellspacing="0">
+<tr>
+<th>Service</th><th>Instance</th><th>State</th><th>Since</th><th>Checks</th>
+<th>Failures</th><th>Last check</th><th>Last error</th><th>Subscribers</th>
+</tr>
+{{range .}}
+<tr>
+<td>{{.Name}}</td>
+<td>{{.Instance}}</td>
+<td>{{if .Healthy}}healthy{{else}}<b>ejected</b>{{end}}</td>
+<td>{{.Since.Format "2006-01-02 15:04:05"}}</td>
+<td>{{.Checks}}</td>
+<td>{{.Failures}}</td>
+<td>{{if .Checks}}{{.LastCheck.Format "2006-01-02 15:04:05"}}{{end}}</td>
+<td>{{if .LastError}}{{.LastError}}{{end}}</td>
+<td>{{.Subscribers}}</td>
+</tr>
+{{end}}
+</table>
+{{else}}
+<p>No health checked instances.</p>
+{{end}}
+</body>
+</html>
+`))
+
+// DebugHandler serves a page holding the health state of all health checked
+// instances.
+func DebugHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		states := HealthStates()
+		sort.Slice(states, func(i, j int) bool {
+			if states[i].Name != states[j].Name {
+				return states[i].Name < states[j].Name
+			}
+			return states[i].Instance < states[j].Instance
+		})
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := debugPage.Execute(w, states); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
@@ -0,0 +1,223 @@
+package sd
+
+import (
+	// stdlib
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// unhealthyThreshold is the amount of consecutive failed health checks
+	// after which an instance is ejected.
+	unhealthyThreshold = 2
+
+	// healthyThreshold is the amount of consecutive successful health checks
+	// after which an ejected instance is restored.
+	healthyThreshold = 2
+)
+
+// HealthChecker checks the health of an instance, returning an error if the
+// instance is unhealthy.
+type HealthChecker func(ctx context.Context, instance string) error
+
+// HealthCheck returns an Option which health checks each client instance every
+// interval. Instances failing their health checks are ejected from Clients()
+// until they recover. The name identifies the checked service on the debug
+// page, client instancers using the same name share the health checks of their
+// instances.
+func HealthCheck(
+	name string, check HealthChecker, interval, timeout time.Duration,
+) Option {
+	return func(opts *clientInstancerOptions) {
+		opts.healthCheck = &healthCheck{
+			name:     name,
+			check:    check,
+			interval: interval,
+			timeout:  timeout,
+		}
+	}
+}
+
+type healthCheck struct {
+	name     string
+	check    HealthChecker
+	interval time.Duration
+	timeout  time.Duration
+}
+
+// healthKey identifies a monitored instance.
+type healthKey struct {
+	name     string
+	instance string
+}
+
+// monitors holds the health monitors of all health checked instances.
+var monitors = struct {
+	sync.Mutex
+	m map[healthKey]*healthMonitor
+}{m: make(map[healthKey]*healthMonitor)}
+
+// healthMonitor periodically checks the health of an instance and notifies its
+// subscribers of changes.
+type healthMonitor struct {
+	key         healthKey
+	hc          healthCheck
+	quit        chan struct{}
+	mtx         sync.Mutex
+	subscribers map[*clientInstancerCache]struct{}
+	healthy     bool
+	failures    int
+	successes   int
+	checks      int64
+	lastCheck   time.Time
+	lastErr     error
+	changed     time.Time
+}
+
+// HealthState holds the health state of an instance.
+type HealthState struct {
+	Name        string
+	Instance    string
+	Healthy     bool
+	Checks      int64
+	Failures    int
+	LastCheck   time.Time
+	LastError   error
+	Since       time.Time
+	Subscribers int
+}
+
+// HealthStates returns the health states of all health checked instances.
+func HealthStates() []HealthState {
+	monitors.Lock()
+	defer monitors.Unlock()
+
+	states := make([]HealthState, 0, len(monitors.m))
+	for _, m := range monitors.m {
+		m.mtx.Lock()
+		states = append(states, HealthState{
+			Name:        m.key.name,
+			Instance:    m.key.instance,
+			Healthy:     m.healthy,
+			Checks:      m.checks,
+			Failures:    m.failures,
+			LastCheck:   m.lastCheck,
+			LastError:   m.lastErr,
+			Since:       m.changed,
+			Subscribers: len(m.subscribers),
+		})
+		m.mtx.Unlock()
+	}
+	return states
+}
+
+// subscribeHealth returns the health monitor of instance, starting it if the
+// instance is not monitored yet. Instances are considered healthy until
+// proven otherwise.
+func subscribeHealth(
+	hc healthCheck, instance string, c *clientInstancerCache,
+) *healthMonitor {
+	monitors.Lock()
+	defer monitors.Unlock()
+
+	key := healthKey{name: hc.name, instance: instance}
+	m, ok := monitors.m[key]
+	if !ok {
+		m = &healthMonitor{
+			key:         key,
+			hc:          hc,
+			quit:        make(chan struct{}),
+			subscribers: make(map[*clientInstancerCache]struct{}),
+			healthy:     true,
+			changed:     time.Now(),
+		}
+		monitors.m[key] = m
+		go m.run()
+	}
+	m.mtx.Lock()
+	m.subscribers[c] = struct{}{}
+	m.mtx.Unlock()
+	return m
+}
+
+// unsubscribe removes c from the subscribers, stopping the monitor once it
+// has no subscribers left.
+func (m *healthMonitor) unsubscribe(c *clientInstancerCache) {
+	monitors.Lock()
+	defer monitors.Unlock()
+
+	m.mtx.Lock()
+	delete(m.subscribers, c)
+	last := len(m.subscribers) == 0
+	m.mtx.Unlock()
+
+	if last {
+		delete(monitors.m, m.key)
+		close(m.quit)
+	}
+}
+
+// isHealthy returns the current health of the instance.
+func (m *healthMonitor) isHealthy() bool {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	return m.healthy
+}
+
+func (m *healthMonitor) run() {
+	ticker := time.NewTicker(m.hc.interval)
+	defer ticker.Stop()
+
+	for {
+		m.probe()
+		select {
+		case <-ticker.C:
+		case <-m.quit:
+			return
+		}
+	}
+}
+
+// probe runs a single health check and notifies the subscribers if the health
+// of the instance changed.
+func (m *healthMonitor) probe() {
+	ctx, cancel := context.WithTimeout(context.Background(), m.hc.timeout)
+	err := m.hc.check(ctx, m.key.instance)
+	cancel()
+
+	m.mtx.Lock()
+	m.checks++
+	m.lastCheck = time.Now()
+	m.lastErr = err
+	if err != nil {
+		m.failures++
+		m.successes = 0
+	} else {
+		m.successes++
+		m.failures = 0
+	}
+	healthy := m.healthy
+	switch {
+	case healthy && m.failures >= unhealthyThreshold:
+		healthy = false
+	case !healthy && m.successes >= healthyThreshold:
+		healthy = true
+	}
+	if healthy == m.healthy {
+		m.mtx.Unlock()
+		return
+	}
+	m.healthy = healthy
+	m.changed = m.lastCheck
+	subscribers := make([]*clientInstancerCache, 0, len(m.subscribers))
+	for c := range m.subscribers {
+		subscribers = append(subscribers, c)
+	}
+	m.mtx.Unlock()
+
+	// notify outside of our lock, subscribers call back into isHealthy
+	for _, c := range subscribers {
+		c.healthChanged()
+	}
+}
@@ -0,0 +1,204 @@
+package sd
+
+import (
+	// stdlib
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/sd"
+)
+
+// fakeHealth fails the health checks of the instances marked down.
+type fakeHealth struct {
+	mtx  sync.Mutex
+	down map[string]bool
+}
+
+func (f *fakeHealth) set(instance string, down bool) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.down[instance] = down
+}
+
+func (f *fakeHealth) check(_ context.Context, instance string) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	if f.down[instance] {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+// newHealthCache returns a cache health checking its instances with f. The
+// checks run once when an instance is added, tests run later checks with
+// probe.
+func newHealthCache(t *testing.T, f *fakeHealth, instances ...string) *clientInstancerCache {
+	t.Helper()
+	c := newClientInstancerCache(nameFactory, log.NewNopLogger(), clientInstancerOptions{
+		healthCheck: &healthCheck{
+			name:     t.Name(),
+			check:    f.check,
+			interval: time.Hour,
+			timeout:  time.Second,
+		},
+	})
+	c.Update(sd.Event{Instances: instances})
+	for _, instance := range instances {
+		waitChecks(t, c.cache[instance].health, 1)
+	}
+	return c
+}
+
+// waitChecks waits for m to have run n health checks.
+func waitChecks(t *testing.T, m *healthMonitor, n int64) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		m.mtx.Lock()
+		checks := m.checks
+		m.mtx.Unlock()
+		if checks >= n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("want %d health checks, have %d", n, checks)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func clients(t *testing.T, c *clientInstancerCache) []interface{} {
+	t.Helper()
+	clients, err := c.Clients()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return clients
+}
+
+func TestHealthEjectRestore(t *testing.T) {
+	f := &fakeHealth{down: make(map[string]bool)}
+	c := newHealthCache(t, f, "a", "b")
+	defer c.Update(sd.Event{})
+	a := c.cache["a"].health
+
+	all := []interface{}{"a", "b"}
+	if want, have := all, clients(t, c); !reflect.DeepEqual(want, have) {
+		t.Fatalf("want %v, have %v", want, have)
+	}
+
+	// instances are ejected after unhealthyThreshold consecutive failures
+	f.set("a", true)
+	for i := 1; i <= unhealthyThreshold; i++ {
+		want := all
+		if i == unhealthyThreshold {
+			want = []interface{}{"b"}
+		}
+		a.probe()
+		if have := clients(t, c); !reflect.DeepEqual(want, have) {
+			t.Fatalf("after %d failed checks: want %v, have %v", i, want, have)
+		}
+	}
+
+	// ejected instances are restored after healthyThreshold consecutive
+	// successes
+	f.set("a", false)
+	for i := 1; i <= healthyThreshold; i++ {
+		want := []interface{}{"b"}
+		if i == healthyThreshold {
+			want = all
+		}
+		a.probe()
+		if have := clients(t, c); !reflect.DeepEqual(want, have) {
+			t.Fatalf("after %d successful checks: want %v, have %v", i, want, have)
+		}
+	}
+
+	// a success resets the failures, so interleaved failures don't eject
+	f.set("a", true)
+	a.probe()
+	f.set("a", false)
+	a.probe()
+	f.set("a", true)
+	a.probe()
+	if want, have := all, clients(t, c); !reflect.DeepEqual(want, have) {
+		t.Errorf("want %v, have %v", want, have)
+	}
+}
+
+func TestHealthAllUnhealthy(t *testing.T) {
+	f := &fakeHealth{down: make(map[string]bool)}
+	c := newHealthCache(t, f, "a", "b")
+	defer c.Update(sd.Event{})
+
+	// ejecting all instances would turn degraded service into no service
+	f.set("a", true)
+	f.set("b", true)
+	for i := 0; i < unhealthyThreshold; i++ {
+		c.cache["a"].health.probe()
+		c.cache["b"].health.probe()
+	}
+	if c.cache["a"].health.isHealthy() || c.cache["b"].health.isHealthy() {
+		t.Fatal("want instances unhealthy")
+	}
+	if want, have := []interface{}{"a", "b"}, clients(t, c); !reflect.DeepEqual(want, have) {
+		t.Errorf("want %v, have %v", want, have)
+	}
+}
+
+func TestHealthMonitorShared(t *testing.T) {
+	f := &fakeHealth{down: make(map[string]bool)}
+	c1 := newHealthCache(t, f, "a", "b")
+	c2 := newClientInstancerCache(nameFactory, log.NewNopLogger(), c1.options)
+	c2.Update(sd.Event{Instances: []string{"a", "b"}})
+	defer c2.Update(sd.Event{})
+
+	// client instancers of the same name share the monitor of an instance
+	m := c1.cache["a"].health
+	if m != c2.cache["a"].health {
+		t.Fatal("want health monitor shared")
+	}
+	f.set("a", true)
+	for i := 0; i < unhealthyThreshold; i++ {
+		m.probe()
+	}
+	// both subscribers are notified
+	for _, c := range []*clientInstancerCache{c1, c2} {
+		if want, have := []interface{}{"b"}, clients(t, c); !reflect.DeepEqual(want, have) {
+			t.Errorf("want %v, have %v", want, have)
+		}
+	}
+
+	// the monitor stops once its last subscriber is gone
+	monitored := func() bool {
+		monitors.Lock()
+		defer monitors.Unlock()
+		_, ok := monitors.m[m.key]
+		return ok
+	}
+	c1.Update(sd.Event{Instances: []string{"b"}})
+	if !monitored() {
+		t.Fatal("want monitor kept for remaining subscriber")
+	}
+	select {
+	case <-m.quit:
+		t.Fatal("want monitor running")
+	default:
+	}
+	c2.Update(sd.Event{Instances: []string{"b"}})
+	if monitored() {
+		t.Error("want monitor removed")
+	}
+	select {
+	case <-m.quit:
+	default:
+		t.Error("want monitor stopped")
+	}
+	c1.Update(sd.Event{})
+}
@@ -0,0 +1,55 @@
+package sd
+
+import (
+	// stdlib
+	"sync/atomic"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/sd"
+)
+
+// NewLeastOutstanding returns a load balancer that returns the client instance
+// with the fewest in-flight requests relative to its weight. Ties are broken by
+// latency EWMA. The load balancer subscribes to src and creates its client
+// instances using f.
+func NewLeastOutstanding(
+	src sd.Instancer, f Factory, logger log.Logger, options ...Option,
+) LoadBalancer {
+	return &leastOutstanding{
+		s: newLoadInstancer(src, f, logger, options...),
+	}
+}
+
+type leastOutstanding struct {
+	s *DefaultClientInstancer
+	c uint64
+}
+
+func (lo *leastOutstanding) Client() (interface{}, Done, error) {
+	loads, err := loads(lo.s)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// rotate our starting point so equally loaded client instances take
+	// turns
+	var (
+		offset = int((atomic.AddUint64(&lo.c, 1) - 1) % uint64(len(loads)))
+		best   = loads[offset]
+	)
+	for i := 1; i < len(loads); i++ {
+		l := loads[(offset+i)%len(loads)]
+		// count the request to dispatch, so idle client instances are
+		// compared by weight
+		lp := float64(l.pending()+1) / l.weight()
+		bp := float64(best.pending()+1) / best.weight()
+		switch {
+		case lp < bp:
+			best = l
+		case lp == bp && l.cost() < best.cost():
+			best = l
+		}
+	}
+	return best.client, best.start(), nil
+}
@@ -0,0 +1,212 @@
+package sd
+
+import (
+	// stdlib
+	"io"
+	"math"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/sd"
+)
+
+const (
+	// decayTime is the time constant of our latency EWMA. Latency peaks are
+	// taken over immediately and decay over time, also while a client instance
+	// receives no traffic, so slow client instances get probed again.
+	decayTime = 10 * time.Second
+
+	// failurePenalty is the minimum latency recorded for failed requests, so
+	// fast failing client instances don't attract traffic.
+	failurePenalty = time.Second
+
+	// probePenalty is the cost of a client instance having requests in flight
+	// without any latency recorded yet, so new client instances receive a
+	// single probe request until they have proven themselves.
+	probePenalty = float64(time.Minute)
+)
+
+// load tracks the in-flight requests and latency of a client instance.
+type load struct {
+	client       interface{}
+	instance     string
+	inflight     int64
+	ejectedUntil int64 // unix nanoseconds
+	outliers     *outlierDetector
+	md           atomic.Value // Metadata
+
+	mtx     sync.Mutex
+	ewma    float64 // latency in nanoseconds
+	stamp   time.Time
+	outlier outlierStats
+}
+
+// newLoadInstancer returns a ClientInstancer yielding the client instances
+// created by f wrapped in a load.
+func newLoadInstancer(
+	src sd.Instancer, f Factory, logger log.Logger, options ...Option,
+) *DefaultClientInstancer {
+	var opts clientInstancerOptions
+	for _, opt := range options {
+		opt(&opts)
+	}
+	var outliers *outlierDetector
+	if opts.outlierDetection != nil {
+		outliers = &outlierDetector{
+			settings: *opts.outlierDetection,
+			logger:   logger,
+		}
+	}
+
+	tracked := func(instance string) (interface{}, io.Closer, error) {
+		client, closer, err := f(instance)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &load{
+			client:   client,
+			instance: instance,
+			outliers: outliers,
+			stamp:    time.Now(),
+		}, closer, nil
+	}
+	s := NewClientInstancer(src, tracked, logger, options...)
+	if outliers != nil {
+		outliers.s = s
+	}
+	return s
+}
+
+// loads returns the current client instances of s, leaving out ejected
+// outliers. If all client instances are ejected, all of them are returned. If
+// the traffic is split by version, only the client instances of the version
+// picked for the request are returned.
+func loads(s *DefaultClientInstancer) ([]*load, error) {
+	clients, err := s.Clients()
+	if err != nil {
+		return nil, err
+	}
+	if len(clients) <= 0 {
+		return nil, ErrNoClients
+	}
+	var (
+		now   = time.Now()
+		loads = make([]*load, 0, len(clients))
+	)
+	for _, client := range clients {
+		if l := client.(*load); !l.ejected(now) {
+			loads = append(loads, l)
+		}
+	}
+	if len(loads) == 0 {
+		for _, client := range clients {
+			loads = append(loads, client.(*load))
+		}
+	}
+	if split := s.cache.options.versionSplit; len(split) > 0 {
+		loads = split.pick(loads, rand.Float64())
+	}
+	return loads, nil
+}
+
+// setMetadata implements metadataSetter.
+func (l *load) setMetadata(md Metadata) {
+	l.md.Store(md)
+}
+
+// metadata returns the metadata of the client instance.
+func (l *load) metadata() Metadata {
+	md, _ := l.md.Load().(Metadata)
+	return md
+}
+
+// weight returns the weight of the client instance.
+func (l *load) weight() float64 {
+	return l.metadata().weight()
+}
+
+// ejected returns if the client instance is ejected as an outlier.
+func (l *load) ejected(now time.Time) bool {
+	return now.UnixNano() < atomic.LoadInt64(&l.ejectedUntil)
+}
+
+// pending returns the amount of in-flight requests.
+func (l *load) pending() int64 {
+	return atomic.LoadInt64(&l.inflight)
+}
+
+// cost returns the decayed latency EWMA weighted by the in-flight requests.
+func (l *load) cost() float64 {
+	l.mtx.Lock()
+	ewma := l.observe(time.Now(), 0)
+	l.mtx.Unlock()
+
+	pending := l.pending()
+	if ewma == 0 && pending > 0 {
+		return probePenalty
+	}
+	return ewma * float64(pending+1)
+}
+
+// start registers a request dispatched to the client instance and returns the
+// function reporting its completion.
+func (l *load) start() Done {
+	atomic.AddInt64(&l.inflight, 1)
+	begin := time.Now()
+	return func(err error) {
+		var (
+			now     = time.Now()
+			latency = now.Sub(begin)
+			took    = latency
+		)
+		if err != nil && took < failurePenalty {
+			took = failurePenalty
+		}
+		l.mtx.Lock()
+		l.observe(now, float64(took))
+		consecutive := l.outliers != nil && l.record(latency, err)
+		l.mtx.Unlock()
+		atomic.AddInt64(&l.inflight, -1)
+
+		if l.outliers != nil {
+			l.outliers.report(l, now, consecutive)
+		}
+	}
+}
+
+// record adds a request outcome to the outlier stats and returns if the
+// consecutive failures threshold has been reached. It must be called with mtx
+// held.
+func (l *load) record(latency time.Duration, err error) bool {
+	l.outlier.requests++
+	l.outlier.latency += latency
+	if err == nil {
+		l.outlier.consecutive = 0
+		return false
+	}
+	l.outlier.failures++
+	l.outlier.consecutive++
+	return l.outlier.consecutive >= l.outliers.settings.ConsecutiveFailures
+}
+
+// observe adds a latency sample to the EWMA and returns the result. It must be
+// called with mtx held.
+func (l *load) observe(now time.Time, latency float64) float64 {
+	elapsed := now.Sub(l.stamp)
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	l.stamp = now
+
+	if latency > l.ewma {
+		l.ewma = latency
+	} else {
+		w := math.Exp(-float64(elapsed) / float64(decayTime))
+		l.ewma = l.ewma*w + latency*(1-w)
+	}
+	return l.ewma
+}
@@ -0,0 +1,245 @@
+package sd
+
+import (
+	// stdlib
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/sd"
+)
+
+// nameFactory returns the instance address as client instance.
+func nameFactory(instance string) (interface{}, io.Closer, error) {
+	return instance, nil, nil
+}
+
+// waitClients waits for s to yield n client instances and returns their
+// loads by instance address.
+func waitClients(t *testing.T, s *DefaultClientInstancer, n int) map[string]*load {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		clients, err := s.Clients()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(clients) == n {
+			loads := make(map[string]*load, n)
+			for _, c := range clients {
+				l := c.(*load)
+				loads[l.instance] = l
+			}
+			return loads
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("want %d client instances, have %d", n, len(clients))
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestLeastOutstandingPending(t *testing.T) {
+	lb := NewLeastOutstanding(
+		sd.FixedInstancer{"a", "b", "c"}, nameFactory, log.NewNopLogger(),
+	).(*leastOutstanding)
+	defer lb.s.Close()
+	loads := waitClients(t, lb.s, 3)
+
+	// requests in flight steer new requests to idle client instances
+	dispatched := make(map[interface{}]Done)
+	for i := 0; i < 3; i++ {
+		client, done, err := lb.Client()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := dispatched[client]; ok {
+			t.Fatalf("want idle client instance, have %s again", client)
+		}
+		dispatched[client] = done
+	}
+	for instance, l := range loads {
+		if want, have := int64(1), l.pending(); want != have {
+			t.Errorf("%s: want %d pending, have %d", instance, want, have)
+		}
+	}
+
+	// completing a request frees its client instance
+	dispatched["b"](nil)
+	if want, have := int64(0), loads["b"].pending(); want != have {
+		t.Errorf("want %d pending, have %d", want, have)
+	}
+	client, done, err := lb.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, have := "b", client; want != have {
+		t.Errorf("want client instance %s, have %s", want, have)
+	}
+	done(nil)
+	dispatched["a"](nil)
+	dispatched["c"](nil)
+	for instance, l := range loads {
+		if want, have := int64(0), l.pending(); want != have {
+			t.Errorf("%s: want %d pending, have %d", instance, want, have)
+		}
+	}
+}
+
+func TestLeastOutstandingWeight(t *testing.T) {
+	lb := NewLeastOutstanding(
+		sd.FixedInstancer{"a#weight=300", "b"}, nameFactory, log.NewNopLogger(),
+	).(*leastOutstanding)
+	defer lb.s.Close()
+	waitClients(t, lb.s, 2)
+
+	// in-flight requests are compared relative to the weights, so a client
+	// instance of weight 300 takes three requests for each of weight 100
+	count := make(map[interface{}]int)
+	for i := 0; i < 4; i++ {
+		client, _, err := lb.Client()
+		if err != nil {
+			t.Fatal(err)
+		}
+		count[client]++
+	}
+	if want, have := 3, count["a"]; want != have {
+		t.Errorf("want %d requests on a, have %d", want, have)
+	}
+	if want, have := 1, count["b"]; want != have {
+		t.Errorf("want %d requests on b, have %d", want, have)
+	}
+}
+
+func TestLeastOutstandingLatency(t *testing.T) {
+	lb := NewLeastOutstanding(
+		sd.FixedInstancer{"a", "b"}, nameFactory, log.NewNopLogger(),
+	).(*leastOutstanding)
+	defer lb.s.Close()
+	loads := waitClients(t, lb.s, 2)
+
+	// failed requests count as taking at least failurePenalty
+	loads["a"].start()(errors.New("connection refused"))
+	loads["b"].start()(nil)
+	if have := loads["a"].cost(); have < float64(failurePenalty)/2 {
+		t.Errorf("want failure penalty cost, have %s", time.Duration(have))
+	}
+
+	// equally loaded client instances are compared by latency, whichever
+	// instance the rotation starts at
+	for i := 0; i < 4; i++ {
+		client, done, err := lb.Client()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want, have := "b", client; want != have {
+			t.Errorf("want client instance %s, have %s", want, have)
+		}
+		done(nil)
+	}
+}
+
+func TestLoadCost(t *testing.T) {
+	l := &load{stamp: time.Now()}
+
+	// new client instances are probed with a single request
+	if want, have := 0.0, l.cost(); want != have {
+		t.Errorf("want idle cost %f, have %f", want, have)
+	}
+	done := l.start()
+	if want, have := probePenalty, l.cost(); want != have {
+		t.Errorf("want probe cost %f, have %f", want, have)
+	}
+	done(nil)
+
+	// latency peaks are taken over immediately and decay over time
+	now := time.Now()
+	l.mtx.Lock()
+	l.ewma, l.stamp = 0, now
+	if want, have := float64(time.Second), l.observe(now, float64(time.Second)); want != have {
+		t.Errorf("want peak %f, have %f", want, have)
+	}
+	decayed := l.observe(now.Add(decayTime), 0)
+	l.mtx.Unlock()
+	if want, have := float64(time.Second)/2.718281828, decayed; have < want*0.99 || have > want*1.01 {
+		t.Errorf("want decayed latency %f, have %f", want, have)
+	}
+
+	// in-flight requests weigh the latency
+	l.mtx.Lock()
+	l.ewma, l.stamp = float64(time.Second), time.Now()
+	l.mtx.Unlock()
+	l.start()
+	l.start()
+	if have := l.cost(); have < 2.9*float64(time.Second) || have > 3*float64(time.Second) {
+		t.Errorf("want cost of 3 seconds, have %s", time.Duration(have))
+	}
+}
+
+func TestPowerOfTwoChoices(t *testing.T) {
+	lb := NewPowerOfTwoChoices(
+		sd.FixedInstancer{"a", "b"}, nameFactory, 1, log.NewNopLogger(),
+	).(*powerOfTwoChoices)
+	defer lb.s.Close()
+	loads := waitClients(t, lb.s, 2)
+
+	// with two client instances both are picked, the cheapest one wins
+	loads["a"].start()(errors.New("connection refused"))
+	for i := 0; i < 10; i++ {
+		client, done, err := lb.Client()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want, have := "b", client; want != have {
+			t.Fatalf("want client instance %s, have %s", want, have)
+		}
+		done(nil)
+	}
+	for instance, l := range loads {
+		if want, have := int64(0), l.pending(); want != have {
+			t.Errorf("%s: want %d pending, have %d", instance, want, have)
+		}
+	}
+}
+
+func TestPickWeighted(t *testing.T) {
+	loads := make([]*load, 3)
+	for i, weight := range []int{100, 200, 100} {
+		loads[i] = &load{}
+		loads[i].setMetadata(Metadata{Weight: weight})
+	}
+	for _, tc := range []struct {
+		skip int
+		r    float64
+		want int
+	}{
+		{skip: -1, r: 0, want: 0},
+		{skip: -1, r: 99, want: 0},
+		{skip: -1, r: 100, want: 1},
+		{skip: -1, r: 299, want: 1},
+		{skip: -1, r: 300, want: 2},
+		{skip: 1, r: 100, want: 2},
+		{skip: 0, r: 0, want: 1},
+		// rounding errors past the total weight yield the last instance
+		{skip: -1, r: 400, want: 2},
+		{skip: 2, r: 300, want: 1},
+	} {
+		if want, have := tc.want, pickWeighted(loads, tc.skip, tc.r); want != have {
+			t.Errorf("skip %d, r %f: want %d, have %d", tc.skip, tc.r, want, have)
+		}
+	}
+}
+
+func TestLoadBalancerNoClients(t *testing.T) {
+	for name, lb := range map[string]LoadBalancer{
+		"least outstanding": NewLeastOutstanding(sd.FixedInstancer{}, nameFactory, log.NewNopLogger()),
+		"power of two":      NewPowerOfTwoChoices(sd.FixedInstancer{}, nameFactory, 1, log.NewNopLogger()),
+	} {
+		if _, _, err := lb.Client(); err != ErrNoClients {
+			t.Errorf("%s: want %v, have %v", name, ErrNoClients, err)
+		}
+	}
+}
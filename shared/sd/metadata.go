@@ -0,0 +1,205 @@
+package sd
+
+import (
+	// stdlib
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultWeight is the weight of instances not announcing a weight.
+const DefaultWeight = 100
+
+// Metadata describes a service instance. Our registries carry it in the
+// instance strings they yield, following the instance address after a '#',
+// e.g. "10.0.0.1:9000#version=1.3.0&zone=eu-west-1a&weight=100". Factories and
+// health checks receive the instance address without metadata.
+type Metadata struct {
+	// Version of the service build running the instance.
+	Version string
+	// Commit the service build was made from.
+	Commit string
+	// Zone the instance runs in, e.g. its cloud availability zone.
+	Zone string
+	// Weight of the instance relative to the other instances, zero means
+	// DefaultWeight. Load balancers tracking load send traffic to instances in
+	// proportion to their weight.
+	Weight int
+	// Transports served by the instance.
+	Transports []string
+}
+
+// ParseInstance splits an instance string into its address and metadata.
+// Instance strings without metadata yield empty Metadata, malformed metadata
+// is ignored.
+func ParseInstance(instance string) (string, Metadata) {
+	idx := strings.LastIndexByte(instance, '#')
+	if idx < 0 {
+		return instance, Metadata{}
+	}
+	addr := instance[:idx]
+	values, err := url.ParseQuery(instance[idx+1:])
+	if err != nil {
+		return addr, Metadata{}
+	}
+	md := Metadata{
+		Version: values.Get("version"),
+		Commit:  values.Get("commit"),
+		Zone:    values.Get("zone"),
+	}
+	md.Weight, _ = strconv.Atoi(values.Get("weight"))
+	if t := values.Get("transports"); t != "" {
+		md.Transports = strings.Split(t, ",")
+	}
+	return addr, md
+}
+
+// FormatInstance returns the instance string announcing addr with md. Empty
+// metadata yields addr as is.
+func FormatInstance(addr string, md Metadata) string {
+	values := url.Values{}
+	if md.Version != "" {
+		values.Set("version", md.Version)
+	}
+	if md.Commit != "" {
+		values.Set("commit", md.Commit)
+	}
+	if md.Zone != "" {
+		values.Set("zone", md.Zone)
+	}
+	if md.Weight > 0 {
+		values.Set("weight", strconv.Itoa(md.Weight))
+	}
+	if len(md.Transports) > 0 {
+		values.Set("transports", strings.Join(md.Transports, ","))
+	}
+	if len(values) == 0 {
+		return addr
+	}
+	return addr + "#" + values.Encode()
+}
+
+// weight returns the weight of the instance, defaulting to DefaultWeight.
+func (md Metadata) weight() float64 {
+	if md.Weight <= 0 {
+		return DefaultWeight
+	}
+	return float64(md.Weight)
+}
+
+// equal reports whether md and o describe the same instance.
+func (md Metadata) equal(o Metadata) bool {
+	if md.Version != o.Version || md.Commit != o.Commit || md.Zone != o.Zone ||
+		md.Weight != o.Weight || len(md.Transports) != len(o.Transports) {
+		return false
+	}
+	for i := range md.Transports {
+		if md.Transports[i] != o.Transports[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// metadataSetter is implemented by client instances wrapped by our balancers
+// to track the metadata of their instance, which may change while the instance
+// stays.
+type metadataSetter interface {
+	setMetadata(md Metadata)
+}
+
+// Filter returns an Option which only yields the client instances whose
+// metadata satisfies f, e.g. to only use instances supporting a capability.
+// Unlike instances failing their health checks, filtered instances are left
+// out even if no instance remains. Multiple filters must all be satisfied.
+func Filter(f func(md Metadata) bool) Option {
+	return func(opts *clientInstancerOptions) {
+		if prev := opts.filter; prev != nil {
+			opts.filter = func(md Metadata) bool { return prev(md) && f(md) }
+			return
+		}
+		opts.filter = f
+	}
+}
+
+// PreferZone returns an Option which only yields the client instances in zone,
+// as long as any of them is healthy, keeping traffic within the zone. An empty
+// zone disables the preference.
+func PreferZone(zone string) Option {
+	return func(opts *clientInstancerOptions) {
+		opts.zone = zone
+	}
+}
+
+// VersionSplit returns an Option splitting the traffic of our load balancers
+// over the versions of the instances, e.g. {"1.3.0": 0.05} sends 5% of the
+// requests to instances of version 1.3.0 and the remainder to instances of
+// other versions. The share of a version without available instances goes to
+// the remaining versions. VersionSplit is ignored by balancers not tracking
+// load.
+func VersionSplit(shares map[string]float64) Option {
+	return func(opts *clientInstancerOptions) {
+		opts.versionSplit = make(versionSplit, len(shares))
+		for version, share := range shares {
+			if share > 0 {
+				opts.versionSplit[version] = share
+			}
+		}
+	}
+}
+
+// versionSplit holds the traffic shares by version.
+type versionSplit map[string]float64
+
+// pick returns the client instances of the version picked by r, which must be
+// in [0, 1).
+func (s versionSplit) pick(loads []*load, r float64) []*load {
+	var (
+		groups   = make(map[string][]*load)
+		versions []string
+	)
+	for _, l := range loads {
+		version := l.metadata().Version
+		if _, ok := s[version]; !ok {
+			version = "" // other versions share the remainder
+		}
+		if _, ok := groups[version]; !ok {
+			versions = append(versions, version)
+		}
+		groups[version] = append(groups[version], l)
+	}
+	if len(versions) == 1 {
+		return loads
+	}
+	sort.Strings(versions)
+
+	shares := make([]float64, len(versions))
+	var total float64
+	for i, version := range versions {
+		if version == "" {
+			shares[i] = 1
+			for _, share := range s {
+				shares[i] -= share
+			}
+			if shares[i] < 0 {
+				shares[i] = 0
+			}
+		} else {
+			shares[i] = s[version]
+		}
+		total += shares[i]
+	}
+	if total <= 0 {
+		return loads
+	}
+
+	r *= total
+	for i, version := range versions {
+		if r < shares[i] {
+			return groups[version]
+		}
+		r -= shares[i]
+	}
+	return groups[versions[len(versions)-1]]
+}
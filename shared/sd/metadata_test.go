@@ -0,0 +1,199 @@
+package sd
+
+import (
+	// stdlib
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/sd"
+)
+
+func TestInstanceMetadata(t *testing.T) {
+	md := Metadata{
+		Version:    "1.3.0",
+		Commit:     "abc123",
+		Zone:       "eu-west-1a",
+		Weight:     200,
+		Transports: []string{"grpc", "http"},
+	}
+	addr, have := ParseInstance(FormatInstance("10.0.0.1:9000", md))
+	if want, have := "10.0.0.1:9000", addr; want != have {
+		t.Errorf("want address %s, have %s", want, have)
+	}
+	if !md.equal(have) {
+		t.Errorf("want metadata %+v, have %+v", md, have)
+	}
+
+	if want, have := "http://10.0.0.1:9000", FormatInstance("http://10.0.0.1:9000", Metadata{}); want != have {
+		t.Errorf("want instance %s, have %s", want, have)
+	}
+	if _, md := ParseInstance("10.0.0.1:9000#%zz"); !md.equal(Metadata{}) {
+		t.Errorf("want malformed metadata ignored, have %+v", md)
+	}
+}
+
+// versionLoads returns loads of the provided versions.
+func versionLoads(versions ...string) []*load {
+	loads := make([]*load, len(versions))
+	for i, version := range versions {
+		loads[i] = &load{instance: version}
+		loads[i].setMetadata(Metadata{Version: version})
+	}
+	return loads
+}
+
+func TestVersionSplit(t *testing.T) {
+	const n = 100000
+	var (
+		split  = versionSplit{"1.3.0": 0.05}
+		loads  = versionLoads("1.2.0", "1.2.1", "1.3.0")
+		r      = rand.New(rand.NewSource(1))
+		canary int
+	)
+	for i := 0; i < n; i++ {
+		picked := split.pick(loads, r.Float64())
+		switch len(picked) {
+		case 1:
+			if want, have := "1.3.0", picked[0].instance; want != have {
+				t.Fatalf("want version %s, have %s", want, have)
+			}
+			canary++
+		case 2:
+			// other versions share the remainder
+		default:
+			t.Fatalf("want a single version picked, have %d instances", len(picked))
+		}
+	}
+	// 5% of the requests, within well over 5 standard deviations (~69)
+	if share := float64(canary) / n; share < 0.045 || share > 0.055 {
+		t.Errorf("want 5%% of the requests on 1.3.0, have %.2f%%", share*100)
+	}
+}
+
+func TestVersionSplitUnavailable(t *testing.T) {
+	// the share of a version without instances goes to the other versions
+	loads := versionLoads("1.2.0", "1.2.1")
+	for _, r := range []float64{0, 0.04, 0.5, 0.99} {
+		if want, have := loads, (versionSplit{"1.3.0": 0.05}).pick(loads, r); !reflect.DeepEqual(want, have) {
+			t.Errorf("r %f: want all instances, have %d", r, len(have))
+		}
+	}
+
+	// without other versions the split versions take all requests
+	loads = versionLoads("1.3.0", "1.4.0")
+	split := versionSplit{"1.3.0": 0.05, "1.4.0": 0.15}
+	for r, want := range map[float64]string{0: "1.3.0", 0.24: "1.3.0", 0.26: "1.4.0", 0.99: "1.4.0"} {
+		if have := split.pick(loads, r); len(have) != 1 || have[0].instance != want {
+			t.Errorf("r %f: want version %s", r, want)
+		}
+	}
+}
+
+func TestVersionSplitBalancer(t *testing.T) {
+	const n = 20000
+	lb := NewLeastOutstanding(
+		sd.FixedInstancer{
+			"10.0.0.1:9000#version=1.2.0",
+			"10.0.0.2:9000#version=1.2.0",
+			"10.0.0.3:9000#version=1.3.0",
+		},
+		nameFactory, log.NewNopLogger(), VersionSplit(map[string]float64{"1.3.0": 0.05}),
+	).(*leastOutstanding)
+	defer lb.s.Close()
+	waitClients(t, lb.s, 3)
+
+	var canary int
+	for i := 0; i < n; i++ {
+		client, done, err := lb.Client()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if client == "10.0.0.3:9000" {
+			canary++
+		}
+		done(nil)
+	}
+	// 5% of the requests, within well over 5 standard deviations (~31)
+	if share := float64(canary) / n; share < 0.04 || share > 0.06 {
+		t.Errorf("want 5%% of the requests on 1.3.0, have %.2f%%", share*100)
+	}
+}
+
+func TestPreferZone(t *testing.T) {
+	f := &fakeHealth{down: make(map[string]bool)}
+	c := newClientInstancerCache(nameFactory, log.NewNopLogger(), clientInstancerOptions{
+		healthCheck: &healthCheck{name: t.Name(), check: f.check, interval: time.Hour, timeout: time.Second},
+		zone:        "eu-west-1a",
+	})
+	c.Update(sd.Event{Instances: []string{
+		"a#zone=eu-west-1a",
+		"b#zone=eu-west-1b",
+		"c#zone=eu-west-1a",
+		"d",
+	}})
+	defer c.Update(sd.Event{})
+	for _, instance := range []string{"a", "b", "c", "d"} {
+		waitChecks(t, c.cache[instance].health, 1)
+	}
+
+	// traffic stays within the zone
+	if want, have := []interface{}{"a", "c"}, clients(t, c); !reflect.DeepEqual(want, have) {
+		t.Fatalf("want %v, have %v", want, have)
+	}
+
+	// while any instance in the zone is healthy
+	f.set("a", true)
+	for i := 0; i < unhealthyThreshold; i++ {
+		c.cache["a"].health.probe()
+	}
+	if want, have := []interface{}{"c"}, clients(t, c); !reflect.DeepEqual(want, have) {
+		t.Fatalf("want %v, have %v", want, have)
+	}
+	f.set("c", true)
+	for i := 0; i < unhealthyThreshold; i++ {
+		c.cache["c"].health.probe()
+	}
+	if want, have := []interface{}{"b", "d"}, clients(t, c); !reflect.DeepEqual(want, have) {
+		t.Fatalf("want healthy instances of other zones %v, have %v", want, have)
+	}
+
+	// the zone is preferred again once it recovers
+	f.set("c", false)
+	for i := 0; i < healthyThreshold; i++ {
+		c.cache["c"].health.probe()
+	}
+	if want, have := []interface{}{"c"}, clients(t, c); !reflect.DeepEqual(want, have) {
+		t.Errorf("want %v, have %v", want, have)
+	}
+
+	// instances without a zone match no zone
+	c.Update(sd.Event{Instances: []string{"b#zone=eu-west-1b", "d"}})
+	if want, have := []interface{}{"b", "d"}, clients(t, c); !reflect.DeepEqual(want, have) {
+		t.Errorf("want %v, have %v", want, have)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	var opts clientInstancerOptions
+	Filter(func(md Metadata) bool { return md.Version != "" })(&opts)
+	Filter(func(md Metadata) bool { return md.Zone == "eu-west-1a" })(&opts)
+	c := newClientInstancerCache(nameFactory, log.NewNopLogger(), opts)
+	c.Update(sd.Event{Instances: []string{
+		"a#version=1.2.0&zone=eu-west-1a",
+		"b#version=1.2.0&zone=eu-west-1b",
+		"c#zone=eu-west-1a",
+	}})
+	if want, have := []interface{}{"a"}, clients(t, c); !reflect.DeepEqual(want, have) {
+		t.Errorf("want %v, have %v", want, have)
+	}
+
+	// filtered instances stay out even if none remains
+	c.Update(sd.Event{Instances: []string{"c#zone=eu-west-1a"}})
+	if want, have := 0, len(clients(t, c)); want != have {
+		t.Errorf("want %d client instances, have %d", want, have)
+	}
+}
@@ -0,0 +1,221 @@
+package sd
+
+import (
+	// stdlib
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	// external
+	"github.com/go-kit/kit/log"
+)
+
+// OutlierSettings configures outlier detection. Zero values are replaced by
+// their defaults.
+type OutlierSettings struct {
+	// Interval between evaluations of the failure rate and latency of the
+	// client instances. Defaults to 10 seconds.
+	Interval time.Duration
+
+	// ConsecutiveFailures after which a client instance is ejected right away.
+	// Defaults to 3.
+	ConsecutiveFailures int
+
+	// FailureRate over an interval above which a client instance is ejected.
+	// Defaults to 0.5.
+	FailureRate float64
+
+	// LatencyFactor by which the mean latency of a client instance over an
+	// interval needs to exceed the median of all client instances for it to
+	// be ejected. Latency outliers are only detected if at least 3 client
+	// instances handled MinimumRequests. Defaults to 5.
+	LatencyFactor float64
+
+	// MinimumRequests a client instance needs to handle over an interval for
+	// its failure rate and latency to be evaluated. Defaults to 10.
+	MinimumRequests int
+
+	// BaseEjectionTime is the ejection time of a client instance, multiplied
+	// by the amount of times it got ejected recently. Defaults to 30 seconds.
+	BaseEjectionTime time.Duration
+
+	// MaxEjectionTime caps the ejection time. Defaults to 5 minutes.
+	MaxEjectionTime time.Duration
+
+	// MaxEjectionPercent of the client instances that can be ejected at the
+	// same time. Defaults to 50.
+	MaxEjectionPercent int
+}
+
+// OutlierDetection returns an Option which passively tracks the failures and
+// latency of each client instance as reported to our load balancers. Client
+// instances standing out are ejected from the load balancer for a while, so
+// retries are not wasted on them. Outlier detection is ignored by balancers
+// not tracking the completion of their requests.
+func OutlierDetection(settings OutlierSettings) Option {
+	if settings.Interval <= 0 {
+		settings.Interval = 10 * time.Second
+	}
+	if settings.ConsecutiveFailures <= 0 {
+		settings.ConsecutiveFailures = 3
+	}
+	if settings.FailureRate <= 0 {
+		settings.FailureRate = 0.5
+	}
+	if settings.LatencyFactor <= 0 {
+		settings.LatencyFactor = 5
+	}
+	if settings.MinimumRequests <= 0 {
+		settings.MinimumRequests = 10
+	}
+	if settings.BaseEjectionTime <= 0 {
+		settings.BaseEjectionTime = 30 * time.Second
+	}
+	if settings.MaxEjectionTime <= 0 {
+		settings.MaxEjectionTime = 5 * time.Minute
+	}
+	if settings.MaxEjectionPercent <= 0 {
+		settings.MaxEjectionPercent = 50
+	}
+	return func(opts *clientInstancerOptions) {
+		opts.outlierDetection = &settings
+	}
+}
+
+// outlierStats holds the outlier detection state of a client instance. It is
+// guarded by the mutex of its load.
+type outlierStats struct {
+	requests    int
+	failures    int
+	latency     time.Duration
+	consecutive int
+	ejections   int
+	ejected     bool // ejected during the current interval
+}
+
+// outlierDetector ejects the outliers of the client instances yielded by s.
+type outlierDetector struct {
+	settings OutlierSettings
+	s        ClientInstancer
+	logger   log.Logger
+	next     int64 // next evaluation in unix nanoseconds
+	mtx      sync.Mutex
+}
+
+// report is invoked by l after each completed request. consecutive is set if
+// l reached the consecutive failures threshold.
+func (d *outlierDetector) report(l *load, now time.Time, consecutive bool) {
+	if !consecutive && now.UnixNano() < atomic.LoadInt64(&d.next) {
+		return
+	}
+
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	clients, err := d.s.Clients()
+	if err != nil {
+		return
+	}
+	loads := make([]*load, len(clients))
+	for idx, client := range clients {
+		loads[idx] = client.(*load)
+	}
+
+	if consecutive {
+		d.eject(loads, l, now, "consecutive failures")
+	}
+	if now.UnixNano() >= atomic.LoadInt64(&d.next) {
+		d.evaluate(loads, now)
+		atomic.StoreInt64(&d.next, now.Add(d.settings.Interval).UnixNano())
+	}
+}
+
+// evaluate ejects the client instances whose failure rate or latency over the
+// last interval stands out and starts a new interval. It must be called with
+// mtx held.
+func (d *outlierDetector) evaluate(loads []*load, now time.Time) {
+	type sample struct {
+		l       *load
+		rate    float64
+		latency time.Duration
+	}
+	samples := make([]sample, 0, len(loads))
+	for _, l := range loads {
+		l.mtx.Lock()
+		o := &l.outlier
+		if o.requests >= d.settings.MinimumRequests && !l.ejected(now) {
+			samples = append(samples, sample{
+				l:       l,
+				rate:    float64(o.failures) / float64(o.requests),
+				latency: o.latency / time.Duration(o.requests),
+			})
+		}
+		o.requests, o.failures, o.latency = 0, 0, 0
+		l.mtx.Unlock()
+	}
+
+	for _, s := range samples {
+		if s.rate > d.settings.FailureRate {
+			d.eject(loads, s.l, now, "failure rate")
+		}
+	}
+	if len(samples) >= 3 {
+		sort.Slice(samples, func(i, j int) bool {
+			return samples[i].latency < samples[j].latency
+		})
+		var (
+			median    = samples[len(samples)/2].latency
+			threshold = time.Duration(float64(median) * d.settings.LatencyFactor)
+		)
+		// eject the slowest client instances first
+		for i := len(samples) - 1; i >= 0 && samples[i].latency > threshold; i-- {
+			d.eject(loads, samples[i].l, now, "latency")
+		}
+	}
+
+	// client instances making it through an interval without ejection are
+	// forgiven one of their past ejections
+	for _, l := range loads {
+		l.mtx.Lock()
+		if o := &l.outlier; !o.ejected && o.ejections > 0 && !l.ejected(now) {
+			o.ejections--
+		}
+		l.outlier.ejected = false
+		l.mtx.Unlock()
+	}
+}
+
+// eject ejects l unless it is ejected already or ejecting it would exceed the
+// maximum ejection percentage. It must be called with mtx held.
+func (d *outlierDetector) eject(
+	loads []*load, l *load, now time.Time, reason string,
+) {
+	if l.ejected(now) {
+		return
+	}
+	var ejected int
+	for _, other := range loads {
+		if other.ejected(now) {
+			ejected++
+		}
+	}
+	if (ejected+1)*100 > len(loads)*d.settings.MaxEjectionPercent {
+		return
+	}
+
+	l.mtx.Lock()
+	l.outlier.ejections++
+	l.outlier.consecutive = 0
+	l.outlier.ejected = true
+	duration := d.settings.BaseEjectionTime * time.Duration(l.outlier.ejections)
+	l.mtx.Unlock()
+	if duration > d.settings.MaxEjectionTime {
+		duration = d.settings.MaxEjectionTime
+	}
+	atomic.StoreInt64(&l.ejectedUntil, now.Add(duration).UnixNano())
+
+	d.logger.Log(
+		"instance", l.instance, "outlier", reason, "ejected", duration,
+	)
+}
@@ -0,0 +1,54 @@
+package sd
+
+import (
+	// stdlib
+	"fmt"
+	"testing"
+	"time"
+
+	// external
+	"github.com/go-kit/kit/log"
+)
+
+func TestEjectMaxEjectionPercent(t *testing.T) {
+	for _, tc := range []struct {
+		instances, percent, ejected int
+	}{
+		{instances: 1, percent: 50, ejected: 0},
+		{instances: 2, percent: 50, ejected: 1},
+		{instances: 3, percent: 50, ejected: 1},
+		{instances: 4, percent: 50, ejected: 2},
+		{instances: 10, percent: 10, ejected: 1},
+		{instances: 3, percent: 100, ejected: 3},
+	} {
+		t.Run(fmt.Sprintf("%d instances %d%%", tc.instances, tc.percent), func(t *testing.T) {
+			d := &outlierDetector{
+				settings: OutlierSettings{
+					BaseEjectionTime:   time.Minute,
+					MaxEjectionTime:    time.Minute,
+					MaxEjectionPercent: tc.percent,
+				},
+				logger: log.NewNopLogger(),
+			}
+			loads := make([]*load, tc.instances)
+			for i := range loads {
+				loads[i] = &load{instance: fmt.Sprintf("10.0.0.%d:8000", i)}
+			}
+
+			now := time.Now()
+			for _, l := range loads {
+				d.eject(loads, l, now, "test")
+			}
+
+			var ejected int
+			for _, l := range loads {
+				if l.ejected(now) {
+					ejected++
+				}
+			}
+			if ejected != tc.ejected {
+				t.Errorf("want %d ejected instances, have %d", tc.ejected, ejected)
+			}
+		})
+	}
+}
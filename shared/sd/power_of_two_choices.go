@@ -0,0 +1,76 @@
+package sd
+
+import (
+	// stdlib
+	"math/rand"
+	"sync"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/sd"
+)
+
+// NewPowerOfTwoChoices returns a load balancer that picks two client instances
+// at random and returns the one with the lowest cost, being its latency EWMA
+// weighted by its in-flight requests. Client instances are picked in proportion
+// to their weight. The load balancer subscribes to src and creates its client
+// instances using f.
+func NewPowerOfTwoChoices(
+	src sd.Instancer, f Factory, seed int64, logger log.Logger,
+	options ...Option,
+) LoadBalancer {
+	return &powerOfTwoChoices{
+		s: newLoadInstancer(src, f, logger, options...),
+		r: rand.New(rand.NewSource(seed)),
+	}
+}
+
+type powerOfTwoChoices struct {
+	s   *DefaultClientInstancer
+	mtx sync.Mutex
+	r   *rand.Rand
+}
+
+func (p *powerOfTwoChoices) Client() (interface{}, Done, error) {
+	loads, err := loads(p.s)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(loads) == 1 {
+		return loads[0].client, loads[0].start(), nil
+	}
+
+	// pick two distinct client instances by weight
+	var total float64
+	for _, l := range loads {
+		total += l.weight()
+	}
+	p.mtx.Lock()
+	ra, rb := p.r.Float64(), p.r.Float64()
+	p.mtx.Unlock()
+	a := pickWeighted(loads, -1, ra*total)
+	b := pickWeighted(loads, a, rb*(total-loads[a].weight()))
+
+	best := loads[a]
+	if loads[b].cost() < best.cost() {
+		best = loads[b]
+	}
+	return best.client, best.start(), nil
+}
+
+// pickWeighted returns the index of the client instance at r in the cumulative
+// weights of loads, skipping the client instance at skip.
+func pickWeighted(loads []*load, skip int, r float64) int {
+	last := -1
+	for i, l := range loads {
+		if i == skip {
+			continue
+		}
+		if r -= l.weight(); r < 0 {
+			return i
+		}
+		last = i
+	}
+	// guard against rounding errors
+	return last
+}
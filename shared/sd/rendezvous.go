@@ -0,0 +1,124 @@
+package sd
+
+import (
+	// stdlib
+	"context"
+	"hash/fnv"
+	"io"
+	"sort"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/sd"
+)
+
+// KeyedBalancer yields client instances by key. A key keeps yielding the same
+// client instance for as long as that instance is available.
+type KeyedBalancer interface {
+	// Client returns the client instance for key. Retries pass their attempt
+	// number, starting at 0, to get the next preferred client instances for
+	// key in order.
+	Client(key string, attempt int) (interface{}, error)
+}
+
+type contextKey int
+
+const hashKeyContextKey contextKey = iota
+
+// NewKeyContext returns a copy of ctx holding the key for consistent hashing,
+// e.g. the tenant ID of the request.
+func NewKeyContext(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, hashKeyContextKey, key)
+}
+
+// KeyFromContext returns the key for consistent hashing found in ctx or an
+// empty string if ctx holds no key.
+func KeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(hashKeyContextKey).(string)
+	return key
+}
+
+// NewRendezvous returns a consistent hashing load balancer using rendezvous
+// hashing. Each key prefers the client instances in order of their hash
+// combined with the key, so adding or removing an instance only moves the keys
+// preferring that instance. The load balancer subscribes to src and creates its
+// client instances using f.
+func NewRendezvous(
+	src sd.Instancer, f Factory, logger log.Logger, options ...Option,
+) KeyedBalancer {
+	named := func(instance string) (interface{}, io.Closer, error) {
+		client, closer, err := f(instance)
+		if err != nil {
+			return nil, nil, err
+		}
+		return namedClient{instance: instance, client: client}, closer, nil
+	}
+	return &rendezvous{
+		s: NewClientInstancer(src, named, logger, options...),
+	}
+}
+
+// namedClient holds a client instance with its instance string, on which our
+// hashes are based.
+type namedClient struct {
+	instance string
+	client   interface{}
+}
+
+type rendezvous struct {
+	s ClientInstancer
+}
+
+func (r *rendezvous) Client(key string, attempt int) (interface{}, error) {
+	clients, err := r.s.Clients()
+	if err != nil {
+		return nil, err
+	}
+	if len(clients) <= 0 {
+		return nil, ErrNoClients
+	}
+
+	if attempt == 0 {
+		// fast path, no need to rank all client instances
+		var (
+			best      namedClient
+			bestScore uint64
+		)
+		for idx, c := range clients {
+			nc := c.(namedClient)
+			if score := hashScore(key, nc.instance); idx == 0 || score > bestScore {
+				best, bestScore = nc, score
+			}
+		}
+		return best.client, nil
+	}
+
+	ranked := make([]namedClient, len(clients))
+	scores := make(map[string]uint64, len(clients))
+	for idx, c := range clients {
+		ranked[idx] = c.(namedClient)
+		scores[ranked[idx].instance] = hashScore(key, ranked[idx].instance)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		return scores[ranked[i].instance] > scores[ranked[j].instance]
+	})
+	return ranked[attempt%len(ranked)].client, nil
+}
+
+// hashScore returns the rendezvous hash of key for instance.
+func hashScore(key, instance string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	h.Write([]byte{0})
+	h.Write([]byte(instance))
+
+	// FNV-1a spreads similar inputs poorly, finalize with the SplitMix64
+	// mixer
+	x := h.Sum64()
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
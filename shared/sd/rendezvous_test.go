@@ -0,0 +1,143 @@
+package sd
+
+import (
+	// stdlib
+	"context"
+	"fmt"
+	"testing"
+)
+
+// newRendezvous returns a rendezvous balancer over the fixed instances.
+func newRendezvous(instances ...string) *rendezvous {
+	clients := make(FixedClientInstancer, len(instances))
+	for i, instance := range instances {
+		clients[i] = namedClient{instance: instance, client: instance}
+	}
+	return &rendezvous{s: clients}
+}
+
+// assign returns the client instance of each key at attempt.
+func assign(t *testing.T, r *rendezvous, keys []string, attempt int) map[string]interface{} {
+	t.Helper()
+	assigned := make(map[string]interface{}, len(keys))
+	for _, key := range keys {
+		client, err := r.Client(key, attempt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assigned[key] = client
+	}
+	return assigned
+}
+
+func TestRendezvousStability(t *testing.T) {
+	keys := make([]string, 1000)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("tenant-%d", i)
+	}
+	var (
+		before = newRendezvous("10.0.0.1:8000", "10.0.0.2:8000", "10.0.0.3:8000", "10.0.0.4:8000")
+		added  = newRendezvous(
+			"10.0.0.1:8000", "10.0.0.2:8000", "10.0.0.3:8000", "10.0.0.4:8000", "10.0.0.5:8000",
+		)
+		removed = newRendezvous("10.0.0.1:8000", "10.0.0.3:8000", "10.0.0.4:8000")
+	)
+	assigned := assign(t, before, keys, 0)
+	fallback := assign(t, before, keys, 1)
+
+	// adding an instance only moves keys to the new instance
+	var moved int
+	for key, client := range assign(t, added, keys, 0) {
+		if client == assigned[key] {
+			continue
+		}
+		if want, have := "10.0.0.5:8000", client; want != have {
+			t.Fatalf("%s: want key kept or moved to %s, have %s", key, want, have)
+		}
+		moved++
+	}
+	// the new instance takes its share of about 1/5 of the keys
+	if moved < 150 || moved > 250 {
+		t.Errorf("want about 200 keys moved, have %d", moved)
+	}
+
+	// removing an instance only moves its keys, to their next preference
+	for key, client := range assign(t, removed, keys, 0) {
+		if assigned[key] == "10.0.0.2:8000" {
+			if want, have := fallback[key], client; want != have {
+				t.Errorf("%s: want key moved to %s, have %s", key, want, have)
+			}
+			continue
+		}
+		if want, have := assigned[key], client; want != have {
+			t.Errorf("%s: want key kept on %s, have %s", key, want, have)
+		}
+	}
+
+	// the order of the instances is irrelevant
+	reordered := newRendezvous("10.0.0.4:8000", "10.0.0.2:8000", "10.0.0.1:8000", "10.0.0.3:8000")
+	for key, client := range assign(t, reordered, keys, 0) {
+		if want, have := assigned[key], client; want != have {
+			t.Fatalf("%s: want %s, have %s", key, want, have)
+		}
+	}
+}
+
+func TestRendezvousAttempts(t *testing.T) {
+	var (
+		instances = []string{"10.0.0.1:8000", "10.0.0.2:8000", "10.0.0.3:8000", "10.0.0.4:8000"}
+		r         = newRendezvous(instances...)
+	)
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("tenant-%d", i)
+
+		// retries visit each instance once, in order of preference
+		var (
+			order = make([]interface{}, 0, len(instances))
+			seen  = make(map[interface{}]bool)
+		)
+		for attempt := 0; attempt < len(instances); attempt++ {
+			client, err := r.Client(key, attempt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if seen[client] {
+				t.Fatalf("%s: want attempt %d on a new instance, have %s again", key, attempt, client)
+			}
+			seen[client] = true
+			order = append(order, client)
+		}
+		for attempt := 1; attempt < len(order); attempt++ {
+			prev, _ := order[attempt-1].(string)
+			next, _ := order[attempt].(string)
+			if hashScore(key, prev) < hashScore(key, next) {
+				t.Fatalf("%s: want attempts in order of preference, have %v", key, order)
+			}
+		}
+
+		// attempts past the last instance start over
+		client, err := r.Client(key, len(instances))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want, have := order[0], client; want != have {
+			t.Errorf("%s: want attempt %d on %s, have %s", key, len(instances), want, have)
+		}
+	}
+}
+
+func TestRendezvousNoClients(t *testing.T) {
+	if _, err := newRendezvous().Client("tenant", 0); err != ErrNoClients {
+		t.Errorf("want %v, have %v", ErrNoClients, err)
+	}
+}
+
+func TestKeyContext(t *testing.T) {
+	if want, have := "", KeyFromContext(context.Background()); want != have {
+		t.Errorf("want key %q, have %q", want, have)
+	}
+	ctx := NewKeyContext(context.Background(), "tenant")
+	if want, have := "tenant", KeyFromContext(ctx); want != have {
+		t.Errorf("want key %q, have %q", want, have)
+	}
+}
@@ -0,0 +1,26 @@
+package sqlitebackup
+
+import (
+	// stdlib
+	"net/http"
+	"os"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"go.opencensus.io/stats/view"
+)
+
+// Handle adds our admin endpoint creating online backups of the SQLite
+// database file source to router and registers the views of our backup
+// measures. Backups are written to OCG_BACKUP_DIR, by default backups.
+func Handle(router *http.ServeMux, source string, logger log.Logger) error {
+	backupDir := os.Getenv("OCG_BACKUP_DIR")
+	if backupDir == "" {
+		backupDir = "backups"
+	}
+	if err := view.Register(DurationView, SizeView); err != nil {
+		return err
+	}
+	router.Handle("/admin/backup", NewHandler(source, backupDir, logger))
+	return nil
+}
@@ -0,0 +1,43 @@
+package sqlitebackup
+
+import (
+	// stdlib
+	"encoding/json"
+	"net/http"
+
+	// external
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+
+	// project
+	"github.com/basvanbeek/opencensus-gokit-example/shared/problem"
+)
+
+// NewHandler returns an admin http.Handler creating an online backup of the
+// SQLite database file source in dir on each POST request. It responds with
+// the JSON encoded Result.
+func NewHandler(source, dir string, logger log.Logger) http.Handler {
+	logger = log.With(logger, "handler", "sqlitebackup", "db", source)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			problem.MethodNotAllowedHandler().ServeHTTP(w, r)
+			return
+		}
+
+		result, err := Backup(r.Context(), source, dir)
+		if err != nil {
+			level.Error(logger).Log("err", err)
+			problem.Error(w, r, err)
+			return
+		}
+		level.Info(logger).Log(
+			"msg", "backup created", "path", result.Path, "size", result.Size,
+			"duration", result.Duration,
+		)
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		json.NewEncoder(w).Encode(result)
+	})
+}
@@ -0,0 +1,207 @@
+// Package sqlitebackup implements online backups and restores of SQLite
+// databases using the SQLite backup API, which copies a consistent snapshot
+// while the database remains in use.
+package sqlitebackup
+
+import (
+	// stdlib
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	// external
+	"github.com/mattn/go-sqlite3"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+)
+
+// pagesPerStep is the number of pages copied per backup step. Between steps
+// other connections can write to the source database.
+const pagesPerStep = 256
+
+// Common Errors
+var (
+	ErrIntegrity = errors.New("database failed integrity check")
+	ErrExists    = errors.New("backup file already exists")
+)
+
+// Measures recorded for each backup
+var (
+	MeasureDuration = stats.Float64(
+		"sqlite/backup/duration", "Duration of SQLite online backups", stats.UnitMilliseconds,
+	)
+	MeasureSize = stats.Int64(
+		"sqlite/backup/size", "Size of SQLite online backups", stats.UnitBytes,
+	)
+)
+
+// Views of our backup measures
+var (
+	DurationView = &view.View{
+		Name:        "sqlite/backup/duration",
+		Description: "Distribution of SQLite online backup durations",
+		Measure:     MeasureDuration,
+		Aggregation: view.Distribution(10, 50, 100, 500, 1000, 5000, 10000, 60000),
+	}
+	SizeView = &view.View{
+		Name:        "sqlite/backup/size",
+		Description: "Size of the last SQLite online backup",
+		Measure:     MeasureSize,
+		Aggregation: view.LastValue(),
+	}
+)
+
+// Result holds the details of a completed backup.
+type Result struct {
+	Path     string        `json:"path"`
+	Size     int64         `json:"size"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Backup creates a verified online backup of the SQLite database file in dir.
+// The backup file is named after the source file, suffixed by the UTC time of
+// the backup, e.g. event-20190101T120000Z.db.
+func Backup(ctx context.Context, source, dir string) (*Result, error) {
+	var (
+		start = time.Now()
+		base  = strings.TrimSuffix(filepath.Base(source), filepath.Ext(source))
+		path  = filepath.Join(dir, base+"-"+start.UTC().Format("20060102T150405Z")+".db")
+	)
+	if _, err := os.Stat(source); err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(path); err == nil {
+		return nil, ErrExists
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	if err := copyDatabase(ctx, source, path); err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+	if err := Verify(ctx, path); err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	result := &Result{Path: path, Size: info.Size(), Duration: time.Since(start)}
+	stats.Record(ctx,
+		MeasureDuration.M(float64(result.Duration)/float64(time.Millisecond)),
+		MeasureSize.M(result.Size),
+	)
+
+	return result, nil
+}
+
+// Restore replaces the SQLite database file target with the backup after
+// verifying its integrity. The database must not be in use by a service.
+func Restore(ctx context.Context, backup, target string) error {
+	if _, err := os.Stat(backup); err != nil {
+		return err
+	}
+	if err := Verify(ctx, backup); err != nil {
+		return err
+	}
+	// the backup holds all committed data, so stale write-ahead log files
+	// must not be replayed on top of it
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err := os.Remove(target + suffix); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := copyDatabase(ctx, backup, target); err != nil {
+		return err
+	}
+	return Verify(ctx, target)
+}
+
+// Verify runs the SQLite integrity check on the database file.
+func Verify(ctx context.Context, path string) error {
+	db, err := sql.Open("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var result string
+	if err = db.QueryRowContext(ctx, `PRAGMA integrity_check`).Scan(&result); err != nil {
+		return err
+	}
+	if result != "ok" {
+		return fmt.Errorf("%v: %s", ErrIntegrity, result)
+	}
+	return nil
+}
+
+// copyDatabase copies the source database into the destination database using
+// the SQLite backup API.
+func copyDatabase(ctx context.Context, source, destination string) error {
+	// we use the plain sqlite3 driver as the backup API needs the underlying
+	// SQLite connections
+	srcDB, err := sql.Open("sqlite3", source)
+	if err != nil {
+		return err
+	}
+	defer srcDB.Close()
+	dstDB, err := sql.Open("sqlite3", destination)
+	if err != nil {
+		return err
+	}
+	defer dstDB.Close()
+
+	srcConn, err := srcDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer srcConn.Close()
+	dstConn, err := dstDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer dstConn.Close()
+
+	return dstConn.Raw(func(dst interface{}) error {
+		return srcConn.Raw(func(src interface{}) error {
+			dstSQLite, ok := dst.(*sqlite3.SQLiteConn)
+			if !ok {
+				return fmt.Errorf("unexpected driver connection %T", dst)
+			}
+			srcSQLite, ok := src.(*sqlite3.SQLiteConn)
+			if !ok {
+				return fmt.Errorf("unexpected driver connection %T", src)
+			}
+
+			backup, err := dstSQLite.Backup("main", srcSQLite, "main")
+			if err != nil {
+				return err
+			}
+			for {
+				done, err := backup.Step(pagesPerStep)
+				if err != nil {
+					backup.Finish()
+					return err
+				}
+				if done {
+					return backup.Finish()
+				}
+				select {
+				case <-ctx.Done():
+					backup.Finish()
+					return ctx.Err()
+				case <-time.After(time.Millisecond):
+				}
+			}
+		})
+	})
+}
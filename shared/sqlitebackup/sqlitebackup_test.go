@@ -0,0 +1,147 @@
+package sqlitebackup
+
+import (
+	// stdlib
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	// external
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// openWAL opens the SQLite database at path in WAL mode like our services.
+func openWAL(t *testing.T, path string) *sqlx.DB {
+	t.Helper()
+	db, err := sqlx.Open("sqlite3", path+"?_journal_mode=WAL")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func count(t *testing.T, db *sqlx.DB) int {
+	t.Helper()
+	var n int
+	if err := db.Get(&n, `SELECT COUNT(*) FROM item`); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestBackupRestore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sqlitebackup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var (
+		ctx    = context.Background()
+		source = filepath.Join(dir, "event.db")
+	)
+	db := openWAL(t, source)
+	defer db.Close()
+	if _, err = db.Exec(`CREATE TABLE item (id INTEGER PRIMARY KEY, name TEXT)`); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 1000; i++ {
+		if _, err = db.Exec(`INSERT INTO item (name) VALUES (?)`, strings.Repeat("x", 100)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the committed rows live in the write-ahead log until checkpointed
+	if _, err = os.Stat(source + "-wal"); err != nil {
+		t.Fatalf("want write-ahead log, have %v", err)
+	}
+
+	// back up while the database is open
+	result, err := Backup(ctx, source, filepath.Join(dir, "backups"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, have := "event-", filepath.Base(result.Path); !strings.HasPrefix(have, want) {
+		t.Errorf("want backup named %s..., have %s", want, have)
+	}
+	info, err := os.Stat(result.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, have := info.Size(), result.Size; want != have {
+		t.Errorf("want size %d, have %d", want, have)
+	}
+	if err = Verify(ctx, result.Path); err != nil {
+		t.Fatal(err)
+	}
+	backup := openWAL(t, result.Path)
+	if want, have := 1000, count(t, backup); want != have {
+		t.Errorf("want %d rows backed up, have %d", want, have)
+	}
+	backup.Close()
+
+	// changes after the backup are undone by the restore, including those
+	// still in the write-ahead log
+	if _, err = db.Exec(`DELETE FROM item WHERE id > 10`); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+	if err = Restore(ctx, result.Path, source); err != nil {
+		t.Fatal(err)
+	}
+	restored := openWAL(t, source)
+	defer restored.Close()
+	if want, have := 1000, count(t, restored); want != have {
+		t.Errorf("want %d rows restored, have %d", want, have)
+	}
+}
+
+func TestVerifyCorrupt(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sqlitebackup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var (
+		ctx    = context.Background()
+		source = filepath.Join(dir, "device.db")
+		target = filepath.Join(dir, "target.db")
+	)
+	db := openWAL(t, source)
+	if _, err = db.Exec(`CREATE TABLE item (id INTEGER PRIMARY KEY, name TEXT)`); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 1000; i++ {
+		if _, err = db.Exec(`INSERT INTO item (name) VALUES (?)`, strings.Repeat("x", 100)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.Close()
+
+	// overwrite a page of the table
+	f, err := os.OpenFile(source, os.O_RDWR, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = f.WriteAt([]byte(strings.Repeat("\xff", 4096)), 8192); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	if err = Verify(ctx, source); err == nil {
+		t.Fatal("want corrupt database rejected")
+	}
+	if err = Restore(ctx, source, target); err == nil {
+		t.Error("want restore of corrupt backup refused")
+	}
+	if _, err = os.Stat(target); !os.IsNotExist(err) {
+		t.Errorf("want target untouched, have %v", err)
+	}
+	if err = Restore(ctx, filepath.Join(dir, "missing.db"), target); !os.IsNotExist(err) {
+		t.Errorf("want missing backup reported, have %v", err)
+	}
+}